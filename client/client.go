@@ -43,9 +43,12 @@
 // # Consumers
 //
 // Provided that the pipeline does not contain processors that would discard or
-// rewrite the context, such as the batch processor, processors and exporters
-// have access to the client.Info via client.FromContext. Among other usages,
-// this data can be used to:
+// rewrite the context, processors and exporters have access to the client.Info
+// via client.FromContext. The batch processor forms batches from the data of
+// many clients: it only propagates the client.Info when configured with
+// metadata_keys, in which case each batch carries the values of these keys,
+// from the client.Metadata or the client.AuthData. Among other usages, this
+// data can be used to:
 //
 // - annotate data points with authentication data (username, tenant, ...)
 //
@@ -4,10 +4,16 @@ package main
 
 import (
 	"go.opentelemetry.io/collector/component"
+	fileexporter "go.opentelemetry.io/collector/exporter/fileexporter"
 	loggingexporter "go.opentelemetry.io/collector/exporter/loggingexporter"
 	otlpexporter "go.opentelemetry.io/collector/exporter/otlpexporter"
 	otlphttpexporter "go.opentelemetry.io/collector/exporter/otlphttpexporter"
 	ballastextension "go.opentelemetry.io/collector/extension/ballastextension"
+	filestorageextension "go.opentelemetry.io/collector/extension/filestorageextension"
+	leaderelectionextension "go.opentelemetry.io/collector/extension/leaderelectionextension"
+	memorylimiterextension "go.opentelemetry.io/collector/extension/memorylimiterextension"
+	oidcclientauthextension "go.opentelemetry.io/collector/extension/oidcclientauthextension"
+	remotetapextension "go.opentelemetry.io/collector/extension/remotetapextension"
 	zpagesextension "go.opentelemetry.io/collector/extension/zpagesextension"
 	batchprocessor "go.opentelemetry.io/collector/processor/batchprocessor"
 	memorylimiterprocessor "go.opentelemetry.io/collector/processor/memorylimiterprocessor"
@@ -20,6 +26,11 @@ func components() (component.Factories, error) {
 
 	factories.Extensions, err = component.MakeExtensionFactoryMap(
 		ballastextension.NewFactory(),
+		filestorageextension.NewFactory(),
+		leaderelectionextension.NewFactory(),
+		memorylimiterextension.NewFactory(),
+		oidcclientauthextension.NewFactory(),
+		remotetapextension.NewFactory(),
 		zpagesextension.NewFactory(),
 	)
 	if err != nil {
@@ -34,6 +45,7 @@ func components() (component.Factories, error) {
 	}
 
 	factories.Exporters, err = component.MakeExporterFactoryMap(
+		fileexporter.NewFactory(),
 		loggingexporter.NewFactory(),
 		otlpexporter.NewFactory(),
 		otlphttpexporter.NewFactory(),
@@ -19,6 +19,7 @@ import (
 	"errors"
 
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/confmap"
 )
 
 var (
@@ -170,6 +171,17 @@ type Factory interface {
 	unexportedFactoryFunc()
 }
 
+// ConfigSchemaProvider is an optional interface implemented by the factories publishing the schema of
+// the configuration of their components. The configuration sections of the components are validated
+// against it before being unmarshaled, with errors qualified by the configuration keys, e.g.
+// "exporters::otlp::endpoint must be a string".
+//
+// Notice: This API is experimental.
+type ConfigSchemaProvider interface {
+	// ConfigSchema returns the schema of the configuration section of a component, nil if none.
+	ConfigSchema() *confmap.Schema
+}
+
 type baseFactory struct {
 	cfgType   config.Type
 	stability map[config.DataType]StabilityLevel
@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configbytes // import "go.opentelemetry.io/collector/config/configbytes"
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes. In the configuration it can be set as an integer number
+// of bytes, or as a string with a decimal (KB, MB, GB, TB) or binary (KiB, MiB, GiB, TiB)
+// unit, e.g. "512MiB" or "1.5GB". Units are case-insensitive.
+type ByteSize int64
+
+const (
+	Byte ByteSize = 1
+
+	Kilobyte = 1000 * Byte
+	Megabyte = 1000 * Kilobyte
+	Gigabyte = 1000 * Megabyte
+	Terabyte = 1000 * Gigabyte
+
+	Kibibyte = 1024 * Byte
+	Mebibyte = 1024 * Kibibyte
+	Gibibyte = 1024 * Mebibyte
+	Tebibyte = 1024 * Gibibyte
+)
+
+var units = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"kb":  Kilobyte,
+	"mb":  Megabyte,
+	"gb":  Gigabyte,
+	"tb":  Terabyte,
+	"kib": Kibibyte,
+	"mib": Mebibyte,
+	"gib": Gibibyte,
+	"tib": Tebibyte,
+}
+
+// Parse parses a string representation of a ByteSize, such as "1024", "512MiB" or "1.5 GB".
+// Fractional values are accepted only when they amount to a whole number of bytes.
+func Parse(s string) (ByteSize, error) {
+	str := strings.TrimSpace(s)
+	idx := strings.IndexFunc(str, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if idx == -1 {
+		idx = len(str)
+	}
+	num, unitStr := str[:idx], strings.ToLower(strings.TrimSpace(str[idx:]))
+	if num == "" {
+		return 0, fmt.Errorf("invalid byte size %q: missing number", s)
+	}
+	unit, ok := units[unitStr]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q: unknown unit %q, supported units are B, KB, MB, GB, TB, KiB, MiB, GiB, TiB", s, str[idx:])
+	}
+	val, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q: %w", s, err)
+	}
+	size := val * float64(unit)
+	// float64(math.MaxInt64) rounds up to 2^63, which does not fit in an int64.
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid byte size %q: overflows int64", s)
+	}
+	if size != math.Trunc(size) {
+		return 0, fmt.Errorf("invalid byte size %q: not a whole number of bytes", s)
+	}
+	return ByteSize(size), nil
+}
+
+// UnmarshalText unmarshals a ByteSize from its string representation, see Parse.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// String returns the size in the largest binary unit that represents it exactly, e.g. "512MiB".
+func (b ByteSize) String() string {
+	for _, u := range []struct {
+		name string
+		size ByteSize
+	}{{"TiB", Tebibyte}, {"GiB", Gibibyte}, {"MiB", Mebibyte}, {"KiB", Kibibyte}} {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configbytes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+	}{
+		{in: "0", want: 0},
+		{in: "1024", want: 1024},
+		{in: "100B", want: 100},
+		{in: "512MiB", want: 512 * Mebibyte},
+		{in: "512mib", want: 512 * Mebibyte},
+		{in: "1.5GB", want: 1500 * Megabyte},
+		{in: " 2 KiB ", want: 2048},
+		{in: "1TB", want: Terabyte},
+		{in: "3tib", want: 3 * Tebibyte},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr string
+	}{
+		{in: "", wantErr: `invalid byte size "": missing number`},
+		{in: "MiB", wantErr: `invalid byte size "MiB": missing number`},
+		{in: "-1", wantErr: `invalid byte size "-1": missing number`},
+		{in: "10XB", wantErr: `invalid byte size "10XB": unknown unit "XB", supported units are B, KB, MB, GB, TB, KiB, MiB, GiB, TiB`},
+		{in: "1.2.3MB", wantErr: `invalid byte size "1.2.3MB": strconv.ParseFloat: parsing "1.2.3": invalid syntax`},
+		{in: "10000000TiB", wantErr: `invalid byte size "10000000TiB": overflows int64`},
+		{in: "9223372036854775808", wantErr: `invalid byte size "9223372036854775808": overflows int64`},
+		{in: "8388608TiB", wantErr: `invalid byte size "8388608TiB": overflows int64`},
+		{in: "1.5B", wantErr: `invalid byte size "1.5B": not a whole number of bytes`},
+		{in: "0.1KiB", wantErr: `invalid byte size "0.1KiB": not a whole number of bytes`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestUnmarshalText(t *testing.T) {
+	var b ByteSize
+	require.NoError(t, b.UnmarshalText([]byte("4KiB")))
+	assert.Equal(t, 4*Kibibyte, b)
+	assert.Error(t, b.UnmarshalText([]byte("4 kilobytes")))
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "0B", ByteSize(0).String())
+	assert.Equal(t, "100B", ByteSize(100).String())
+	assert.Equal(t, "4KiB", (4 * Kibibyte).String())
+	assert.Equal(t, "512MiB", (512 * Mebibyte).String())
+	assert.Equal(t, "1500000000B", (1500 * Megabyte).String())
+	assert.Equal(t, "2TiB", (2 * Tebibyte).String())
+}
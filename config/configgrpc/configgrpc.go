@@ -35,11 +35,14 @@ import (
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/tap"
 
 	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configbytes"
 	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/config/configmiddleware"
 	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/config/configtls"
 )
@@ -77,11 +80,11 @@ type GRPCClientSettings struct {
 
 	// ReadBufferSize for gRPC client. See grpc.WithReadBufferSize.
 	// (https://godoc.org/google.golang.org/grpc#WithReadBufferSize).
-	ReadBufferSize int `mapstructure:"read_buffer_size"`
+	ReadBufferSize configbytes.ByteSize `mapstructure:"read_buffer_size"`
 
 	// WriteBufferSize for gRPC gRPC. See grpc.WithWriteBufferSize.
 	// (https://godoc.org/google.golang.org/grpc#WithWriteBufferSize).
-	WriteBufferSize int `mapstructure:"write_buffer_size"`
+	WriteBufferSize configbytes.ByteSize `mapstructure:"write_buffer_size"`
 
 	// WaitForReady parameter configures client to wait for ready state before sending data.
 	// (https://github.com/grpc/grpc/blob/master/doc/wait-for-ready.md)
@@ -141,11 +144,11 @@ type GRPCServerSettings struct {
 
 	// ReadBufferSize for gRPC server. See grpc.ReadBufferSize.
 	// (https://godoc.org/google.golang.org/grpc#ReadBufferSize).
-	ReadBufferSize int `mapstructure:"read_buffer_size"`
+	ReadBufferSize configbytes.ByteSize `mapstructure:"read_buffer_size"`
 
 	// WriteBufferSize for gRPC server. See grpc.WriteBufferSize.
 	// (https://godoc.org/google.golang.org/grpc#WriteBufferSize).
-	WriteBufferSize int `mapstructure:"write_buffer_size"`
+	WriteBufferSize configbytes.ByteSize `mapstructure:"write_buffer_size"`
 
 	// Keepalive anchor for all the settings related to keepalive.
 	Keepalive *KeepaliveServerConfig `mapstructure:"keepalive"`
@@ -153,9 +156,16 @@ type GRPCServerSettings struct {
 	// Auth for this receiver
 	Auth *configauth.Authentication `mapstructure:"auth"`
 
+	// Middlewares for this receiver, invoked in order after Auth.
+	Middlewares []configmiddleware.Middleware `mapstructure:"middlewares"`
+
 	// Include propagates the incoming connection's metadata to downstream consumers.
 	// Experimental: *NOTE* this option is subject to change or removal in the future.
 	IncludeMetadata bool `mapstructure:"include_metadata"`
+
+	// DrainTimeout is the maximum amount of time given to the in-flight RPCs to complete when
+	// the server is shut down via ShutdownServer. Zero means only the shutdown context bounds it.
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
 }
 
 // SanitizedEndpoint strips the prefix of either http:// or https:// from configgrpc.GRPCClientSettings.Endpoint.
@@ -202,11 +212,11 @@ func (gcs *GRPCClientSettings) ToDialOptions(host component.Host, settings compo
 	opts = append(opts, grpc.WithTransportCredentials(cred))
 
 	if gcs.ReadBufferSize > 0 {
-		opts = append(opts, grpc.WithReadBufferSize(gcs.ReadBufferSize))
+		opts = append(opts, grpc.WithReadBufferSize(int(gcs.ReadBufferSize)))
 	}
 
 	if gcs.WriteBufferSize > 0 {
-		opts = append(opts, grpc.WithWriteBufferSize(gcs.WriteBufferSize))
+		opts = append(opts, grpc.WithWriteBufferSize(int(gcs.WriteBufferSize)))
 	}
 
 	if gcs.Keepalive != nil {
@@ -292,11 +302,11 @@ func (gss *GRPCServerSettings) ToServerOption(host component.Host, settings comp
 	}
 
 	if gss.ReadBufferSize > 0 {
-		opts = append(opts, grpc.ReadBufferSize(gss.ReadBufferSize))
+		opts = append(opts, grpc.ReadBufferSize(int(gss.ReadBufferSize)))
 	}
 
 	if gss.WriteBufferSize > 0 {
-		opts = append(opts, grpc.WriteBufferSize(gss.WriteBufferSize))
+		opts = append(opts, grpc.WriteBufferSize(int(gss.WriteBufferSize)))
 	}
 
 	// The default values referenced in the GRPC docs are set within the server, so this code doesn't need
@@ -329,6 +339,7 @@ func (gss *GRPCServerSettings) ToServerOption(host component.Host, settings comp
 
 	var uInterceptors []grpc.UnaryServerInterceptor
 	var sInterceptors []grpc.StreamServerInterceptor
+	var inTapHandles []tap.ServerInHandle
 
 	if gss.Auth != nil {
 		authenticator, err := gss.Auth.GetServerAuthenticator(host.GetExtensions())
@@ -359,11 +370,86 @@ func (gss *GRPCServerSettings) ToServerOption(host component.Host, settings comp
 	uInterceptors = append(uInterceptors, enhanceWithClientInformation(gss.IncludeMetadata))
 	sInterceptors = append(sInterceptors, enhanceStreamWithClientInformation(gss.IncludeMetadata))
 
+	for _, m := range gss.Middlewares {
+		middleware, err := m.GetServerMiddleware(host.GetExtensions())
+		if err != nil {
+			return nil, err
+		}
+
+		uInterceptor, err := middleware.GetUnaryServerInterceptor()
+		if err != nil {
+			return nil, err
+		}
+		if uInterceptor != nil {
+			uInterceptors = append(uInterceptors, uInterceptor)
+		}
+
+		sInterceptor, err := middleware.GetStreamServerInterceptor()
+		if err != nil {
+			return nil, err
+		}
+		if sInterceptor != nil {
+			sInterceptors = append(sInterceptors, sInterceptor)
+		}
+
+		if tapHandler, ok := middleware.(configmiddleware.ServerInTapHandler); ok {
+			inTapHandle, err := tapHandler.GetInTapHandle()
+			if err != nil {
+				return nil, err
+			}
+			if inTapHandle != nil {
+				inTapHandles = append(inTapHandles, inTapHandle)
+			}
+		}
+	}
+
 	opts = append(opts, grpc.ChainUnaryInterceptor(uInterceptors...), grpc.ChainStreamInterceptor(sInterceptors...))
+	if len(inTapHandles) > 0 {
+		opts = append(opts, grpc.InTapHandle(chainInTapHandles(inTapHandles)))
+	}
 
 	return opts, nil
 }
 
+// chainInTapHandles returns a handle running the given ones in order, since a gRPC server
+// accepts a single one. The first error stops the chain.
+func chainInTapHandles(handles []tap.ServerInHandle) tap.ServerInHandle {
+	return func(ctx context.Context, info *tap.Info) (context.Context, error) {
+		var err error
+		for _, handle := range handles {
+			if ctx, err = handle(ctx, info); err != nil {
+				return nil, err
+			}
+		}
+		return ctx, nil
+	}
+}
+
+// ShutdownServer gracefully stops the server created with the options returned by ToServerOption:
+// it stops accepting new connections, sends GOAWAY on the existing ones and waits for the
+// in-flight RPCs to complete. Once the DrainTimeout elapses or the context is done, the server
+// is stopped and the remaining RPCs are cancelled.
+func (gss *GRPCServerSettings) ShutdownServer(ctx context.Context, server *grpc.Server) {
+	if gss.DrainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, gss.DrainTimeout)
+		defer cancel()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+		<-stopped
+	}
+}
+
 // getGRPCCompressionName returns compression name registered in grpc.
 func getGRPCCompressionName(compressionType configcompression.CompressionType) (string, error) {
 	switch compressionType {
@@ -27,8 +27,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
 
 	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
@@ -36,6 +40,7 @@ import (
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configauth"
 	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/config/configmiddleware"
 	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/obsreport/obsreporttest"
@@ -861,6 +866,174 @@ func TestClientInfoInterceptors(t *testing.T) {
 	}
 }
 
+func TestServerMiddleware(t *testing.T) {
+	var calls []string
+	var clientAddr string
+	host := &mockHost{
+		ext: map[config.ComponentID]component.Extension{
+			config.NewComponentID("first"): configmiddleware.NewServerMiddleware(
+				configmiddleware.WithUnaryServerInterceptor(func() (grpc.UnaryServerInterceptor, error) {
+					return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+						calls = append(calls, "first")
+						if cl := client.FromContext(ctx); cl.Addr != nil {
+							clientAddr = cl.Addr.String()
+						}
+						return handler(ctx, req)
+					}, nil
+				}),
+			),
+			config.NewComponentID("second"): configmiddleware.NewServerMiddleware(
+				configmiddleware.WithUnaryServerInterceptor(func() (grpc.UnaryServerInterceptor, error) {
+					return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+						calls = append(calls, "second")
+						return handler(ctx, req)
+					}, nil
+				}),
+			),
+		},
+	}
+
+	gss := &GRPCServerSettings{
+		NetAddr: confignet.NetAddr{
+			Endpoint:  "localhost:0",
+			Transport: "tcp",
+		},
+		Middlewares: []configmiddleware.Middleware{
+			{MiddlewareID: config.NewComponentID("first")},
+			{MiddlewareID: config.NewComponentID("second")},
+		},
+	}
+	opts, err := gss.ToServerOption(host, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	srv := grpc.NewServer(opts...)
+	ptraceotlp.RegisterServer(srv, &grpcTraceServer{})
+	defer srv.Stop()
+
+	l, err := gss.ToListener()
+	require.NoError(t, err)
+	go func() {
+		_ = srv.Serve(l)
+	}()
+
+	gcs := &GRPCClientSettings{
+		Endpoint: l.Addr().String(),
+		TLSSetting: configtls.TLSClientSetting{
+			Insecure: true,
+		},
+	}
+	clientOpts, err := gcs.ToDialOptions(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	grpcClientConn, err := grpc.Dial(gcs.Endpoint, clientOpts...)
+	require.NoError(t, err)
+	defer grpcClientConn.Close()
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelFunc()
+	_, err = ptraceotlp.NewClient(grpcClientConn).Export(ctx, ptraceotlp.NewRequest())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"first", "second"}, calls)
+	assert.Contains(t, clientAddr, "127.0.0.1")
+}
+
+func TestServerMiddlewareInTapHandle(t *testing.T) {
+	var calls []string
+	refuse := true
+	host := &mockHost{
+		ext: map[config.ComponentID]component.Extension{
+			config.NewComponentID("first"): configmiddleware.NewServerMiddleware(
+				configmiddleware.WithInTapHandle(func() (tap.ServerInHandle, error) {
+					return func(ctx context.Context, info *tap.Info) (context.Context, error) {
+						calls = append(calls, "first "+info.FullMethodName)
+						return ctx, nil
+					}, nil
+				}),
+			),
+			config.NewComponentID("second"): configmiddleware.NewServerMiddleware(
+				configmiddleware.WithInTapHandle(func() (tap.ServerInHandle, error) {
+					return func(ctx context.Context, info *tap.Info) (context.Context, error) {
+						calls = append(calls, "second")
+						if refuse {
+							return nil, status.Error(codes.Unavailable, "refused")
+						}
+						return ctx, nil
+					}, nil
+				}),
+				configmiddleware.WithUnaryServerInterceptor(func() (grpc.UnaryServerInterceptor, error) {
+					return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+						calls = append(calls, "interceptor")
+						return handler(ctx, req)
+					}, nil
+				}),
+			),
+		},
+	}
+
+	gss := &GRPCServerSettings{
+		NetAddr: confignet.NetAddr{
+			Endpoint:  "localhost:0",
+			Transport: "tcp",
+		},
+		Middlewares: []configmiddleware.Middleware{
+			{MiddlewareID: config.NewComponentID("first")},
+			{MiddlewareID: config.NewComponentID("second")},
+		},
+	}
+	opts, err := gss.ToServerOption(host, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	srv := grpc.NewServer(opts...)
+	ptraceotlp.RegisterServer(srv, &grpcTraceServer{})
+	defer srv.Stop()
+
+	l, err := gss.ToListener()
+	require.NoError(t, err)
+	go func() {
+		_ = srv.Serve(l)
+	}()
+
+	grpcClientConn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer grpcClientConn.Close()
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelFunc()
+	// Refused before the request is decoded, the interceptors are not invoked.
+	_, err = ptraceotlp.NewClient(grpcClientConn).Export(ctx, ptraceotlp.NewRequest())
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, []string{"first /opentelemetry.proto.collector.trace.v1.TraceService/Export", "second"}, calls)
+
+	calls = nil
+	refuse = false
+	_, err = ptraceotlp.NewClient(grpcClientConn).Export(ctx, ptraceotlp.NewRequest())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first /opentelemetry.proto.collector.trace.v1.TraceService/Export", "second", "interceptor"}, calls)
+}
+
+func TestServerMiddlewareErrors(t *testing.T) {
+	errInterceptor := errors.New("interceptor failure")
+	host := &mockHost{
+		ext: map[config.ComponentID]component.Extension{
+			config.NewComponentID("failing"): configmiddleware.NewServerMiddleware(
+				configmiddleware.WithStreamServerInterceptor(func() (grpc.StreamServerInterceptor, error) {
+					return nil, errInterceptor
+				}),
+			),
+		},
+	}
+
+	gss := &GRPCServerSettings{
+		Middlewares: []configmiddleware.Middleware{{MiddlewareID: config.NewComponentID("failing")}},
+	}
+	_, err := gss.ToServerOption(host, componenttest.NewNopTelemetrySettings())
+	assert.ErrorIs(t, err, errInterceptor)
+
+	gss = &GRPCServerSettings{
+		Middlewares: []configmiddleware.Middleware{{MiddlewareID: config.NewComponentID("non-existing")}},
+	}
+	_, err = gss.ToServerOption(host, componenttest.NewNopTelemetrySettings())
+	assert.Error(t, err)
+}
+
 func TestDefaultUnaryInterceptorAuthSucceeded(t *testing.T) {
 	// prepare
 	handlerCalled := false
@@ -1020,6 +1193,94 @@ func (m *mockServerStream) Context() context.Context {
 	return m.ctx
 }
 
+type blockingTraceServer struct {
+	received chan struct{}
+	release  chan struct{}
+}
+
+func (bts *blockingTraceServer) Export(ctx context.Context, _ ptraceotlp.Request) (ptraceotlp.Response, error) {
+	close(bts.received)
+	select {
+	case <-bts.release:
+		return ptraceotlp.NewResponse(), nil
+	case <-ctx.Done():
+		return ptraceotlp.NewResponse(), ctx.Err()
+	}
+}
+
+func TestShutdownServer(t *testing.T) {
+	tests := []struct {
+		name         string
+		drainTimeout time.Duration
+		release      bool
+		wantErr      bool
+	}{
+		{
+			name:         "drained",
+			drainTimeout: 10 * time.Second,
+			release:      true,
+		},
+		{
+			name:         "drain_timeout",
+			drainTimeout: 50 * time.Millisecond,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gss := &GRPCServerSettings{
+				NetAddr: confignet.NetAddr{
+					Endpoint:  "localhost:0",
+					Transport: "tcp",
+				},
+				DrainTimeout: tt.drainTimeout,
+			}
+			ln, err := gss.ToListener()
+			require.NoError(t, err)
+			opts, err := gss.ToServerOption(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
+			require.NoError(t, err)
+			srv := grpc.NewServer(opts...)
+			bts := &blockingTraceServer{received: make(chan struct{}), release: make(chan struct{})}
+			ptraceotlp.RegisterServer(srv, bts)
+			go func() {
+				_ = srv.Serve(ln)
+			}()
+
+			grpcClientConn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			defer grpcClientConn.Close()
+			client := ptraceotlp.NewClient(grpcClientConn)
+
+			exportErr := make(chan error, 1)
+			go func() {
+				_, errResp := client.Export(context.Background(), ptraceotlp.NewRequest(), grpc.WaitForReady(true))
+				exportErr <- errResp
+			}()
+			<-bts.received
+
+			stopped := make(chan struct{})
+			go func() {
+				gss.ShutdownServer(context.Background(), srv)
+				close(stopped)
+			}()
+			if tt.release {
+				close(bts.release)
+			}
+
+			select {
+			case <-stopped:
+			case <-time.After(5 * time.Second):
+				t.Fatal("server was not stopped")
+			}
+			if tt.wantErr {
+				assert.Error(t, <-exportErr)
+			} else {
+				assert.NoError(t, <-exportErr)
+			}
+		})
+	}
+}
+
 type grpcTraceServer struct {
 	recordedContext context.Context
 }
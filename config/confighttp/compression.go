@@ -20,8 +20,11 @@ import (
 	"bytes"
 	"compress/gzip"
 	"compress/zlib"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/golang/snappy"
 	"github.com/klauspost/compress/zstd"
@@ -185,3 +188,178 @@ func newBodyReader(r *http.Request) (io.ReadCloser, error) {
 func defaultErrorHandler(w http.ResponseWriter, _ *http.Request, errMsg string, statusCode int) {
 	http.Error(w, errMsg, statusCode)
 }
+
+// isSupportedResponseCompression returns whether the compression type can be used to encode
+// the responses of a server.
+func isSupportedResponseCompression(compressionType configcompression.CompressionType) bool {
+	switch compressionType {
+	case configcompression.Gzip, configcompression.Deflate, configcompression.Zstd:
+		return true
+	}
+	return false
+}
+
+// httpResponseCompressor compresses the responses written by h using the first of the given
+// compression types that is accepted by the client, as advertised in the "Accept-Encoding" header.
+func httpResponseCompressor(h http.Handler, compressionTypes []configcompression.CompressionType) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		compressionType := negotiateResponseCompression(r.Header.Get("Accept-Encoding"), compressionTypes)
+		if compressionType == "" || r.Method == http.MethodHead {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressResponseWriter{ResponseWriter: w, compressionType: compressionType}
+		defer cw.close()
+		h.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateResponseCompression returns the first of the given compression types that has a
+// non-zero quality value in acceptEncoding, or an empty string if none is acceptable.
+func negotiateResponseCompression(acceptEncoding string, compressionTypes []configcompression.CompressionType) configcompression.CompressionType {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(key) != "q" {
+				continue
+			}
+			var err error
+			if quality, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+				quality = 0
+			}
+		}
+		qualities[strings.ToLower(strings.TrimSpace(coding))] = quality
+	}
+
+	for _, compressionType := range compressionTypes {
+		quality, ok := qualities[string(compressionType)]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > 0 {
+			return compressionType
+		}
+	}
+	return ""
+}
+
+// compressResponseWriter compresses the response body, unless the handler already set
+// its own "Content-Encoding" or the response has no body. The header is sent along with the
+// first bytes of the body, so that "Content-Encoding" is only set on compressed responses.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	compressionType configcompression.CompressionType
+	writer          io.WriteCloser
+	// statusCode is the status set by the handler, zero if not set yet.
+	statusCode  int
+	wroteHeader bool
+	passthrough bool
+}
+
+func (cw *compressResponseWriter) WriteHeader(statusCode int) {
+	if statusCode < http.StatusOK {
+		// Informational responses are sent as is, the final one follows.
+		cw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if cw.statusCode != 0 {
+		return
+	}
+	cw.statusCode = statusCode
+	if cw.Header().Get(headerContentEncoding) != "" || statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+		cw.sendHeader(false)
+	}
+}
+
+// sendHeader sends the header with the status set by the handler, or 200, declaring the
+// compression of the body if compress is true.
+func (cw *compressResponseWriter) sendHeader(compress bool) {
+	if cw.statusCode == 0 {
+		cw.statusCode = http.StatusOK
+	}
+	cw.wroteHeader = true
+	cw.passthrough = !compress
+	if compress {
+		header := cw.Header()
+		header.Set(headerContentEncoding, string(cw.compressionType))
+		// The length of the compressed body is unknown.
+		header.Del("Content-Length")
+	}
+	cw.ResponseWriter.WriteHeader(cw.statusCode)
+}
+
+func (cw *compressResponseWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		if len(b) == 0 {
+			return 0, nil
+		}
+		// Detect the content type on the uncompressed data, net/http would otherwise
+		// sniff the compressed bytes.
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		cw.WriteHeader(http.StatusOK)
+		if !cw.wroteHeader {
+			cw.sendHeader(true)
+		}
+	}
+	if cw.passthrough {
+		return cw.ResponseWriter.Write(b)
+	}
+
+	if cw.writer == nil {
+		writer, err := newResponseCompressWriter(cw.ResponseWriter, cw.compressionType)
+		if err != nil {
+			return 0, err
+		}
+		cw.writer = writer
+	}
+	return cw.writer.Write(b)
+}
+
+// Flush sends any buffered compressed data to the client. The header is sent uncompressed if
+// no body was written yet.
+func (cw *compressResponseWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.sendHeader(false)
+	}
+	if f, ok := cw.writer.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close terminates the compressed body, or sends the header of a response without body
+// set by the handler.
+func (cw *compressResponseWriter) close() {
+	if cw.writer != nil {
+		_ = cw.writer.Close()
+		return
+	}
+	if !cw.wroteHeader && cw.statusCode != 0 {
+		cw.sendHeader(false)
+	}
+}
+
+func newResponseCompressWriter(w io.Writer, compressionType configcompression.CompressionType) (io.WriteCloser, error) {
+	switch compressionType {
+	case configcompression.Gzip:
+		return gzip.NewWriter(w), nil
+	case configcompression.Deflate:
+		return zlib.NewWriter(w), nil
+	case configcompression.Zstd:
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	}
+	return nil, fmt.Errorf("unsupported response compression %q", compressionType)
+}
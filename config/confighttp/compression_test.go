@@ -321,6 +321,150 @@ func TestHTTPContentCompressionRequestBodyCloseError(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestHTTPResponseCompressionHandler(t *testing.T) {
+	testBody := []byte("uncompressed_text")
+	tests := []struct {
+		name             string
+		acceptEncoding   string
+		compressionTypes []configcompression.CompressionType
+		expectedEncoding string
+		decompress       func(io.Reader) (io.Reader, error)
+	}{
+		{
+			name:             "NoAcceptEncoding",
+			compressionTypes: []configcompression.CompressionType{configcompression.Gzip},
+		},
+		{
+			name:             "Gzip",
+			acceptEncoding:   "gzip",
+			compressionTypes: []configcompression.CompressionType{configcompression.Gzip},
+			expectedEncoding: "gzip",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return gzip.NewReader(r)
+			},
+		},
+		{
+			name:             "Deflate",
+			acceptEncoding:   "deflate",
+			compressionTypes: []configcompression.CompressionType{configcompression.Deflate},
+			expectedEncoding: "deflate",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return zlib.NewReader(r)
+			},
+		},
+		{
+			name:             "ZstdPreferredByServer",
+			acceptEncoding:   "gzip, zstd",
+			compressionTypes: []configcompression.CompressionType{configcompression.Zstd, configcompression.Gzip},
+			expectedEncoding: "zstd",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return zstd.NewReader(r)
+			},
+		},
+		{
+			name:             "RejectedByQuality",
+			acceptEncoding:   "zstd;q=0, gzip;q=0.5",
+			compressionTypes: []configcompression.CompressionType{configcompression.Zstd, configcompression.Gzip},
+			expectedEncoding: "gzip",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return gzip.NewReader(r)
+			},
+		},
+		{
+			name:             "Wildcard",
+			acceptEncoding:   "*",
+			compressionTypes: []configcompression.CompressionType{configcompression.Gzip},
+			expectedEncoding: "gzip",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return gzip.NewReader(r)
+			},
+		},
+		{
+			name:             "NotAccepted",
+			acceptEncoding:   "br",
+			compressionTypes: []configcompression.CompressionType{configcompression.Gzip},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := httpResponseCompressor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				_, err := w.Write(testBody)
+				require.NoError(t, err)
+			}), tt.compressionTypes)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.expectedEncoding, rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+
+			var body io.Reader = rec.Body
+			if tt.decompress != nil {
+				var err error
+				body, err = tt.decompress(rec.Body)
+				require.NoError(t, err)
+			}
+			got, err := io.ReadAll(body)
+			require.NoError(t, err)
+			assert.Equal(t, testBody, got)
+		})
+	}
+}
+
+func TestHTTPResponseCompressionPassthrough(t *testing.T) {
+	compressionTypes := []configcompression.CompressionType{configcompression.Gzip}
+
+	// The handler sets its own encoding.
+	handler := httpResponseCompressor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "identity")
+		_, _ = w.Write([]byte("body"))
+	}), compressionTypes)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "identity", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "body", rec.Body.String())
+
+	// Responses without a body are not encoded.
+	handler = httpResponseCompressor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), compressionTypes)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+
+	// Responses with an empty body are not encoded either.
+	handler = httpResponseCompressor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write(nil)
+	}), compressionTypes)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "0", rec.Header().Get("Content-Length"))
+	assert.Empty(t, rec.Body.Bytes())
+
+	// The status set by the handler is sent with the compressed body.
+	handler = httpResponseCompressor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("body"))
+	}), compressionTypes)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+}
+
 func compressGzip(body []byte) (*bytes.Buffer, error) {
 	var buf bytes.Buffer
 
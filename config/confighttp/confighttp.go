@@ -15,8 +15,10 @@
 package confighttp // import "go.opentelemetry.io/collector/config/confighttp"
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"time"
@@ -28,12 +30,26 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configbytes"
 	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/config/configmiddleware"
+	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/config/configtls"
 )
 
 const headerContentEncoding = "Content-Encoding"
 
+// The defaults of the HTTPServerSettings limits, used when they are not set, so that servers are not
+// left exposed to clients that never finish sending their requests, never read the responses, keep
+// idle connections open or send arbitrarily large bodies.
+const (
+	defaultReadHeaderTimeout  = time.Minute
+	defaultReadTimeout        = time.Minute
+	defaultWriteTimeout       = 30 * time.Second
+	defaultIdleTimeout        = time.Minute
+	defaultMaxRequestBodySize = 20 * configbytes.Mebibyte
+)
+
 // HTTPClientSettings defines settings for creating an HTTP client.
 type HTTPClientSettings struct {
 	// The target URL to send data to (e.g.: http://some.url:9411/v1/traces).
@@ -43,10 +59,10 @@ type HTTPClientSettings struct {
 	TLSSetting configtls.TLSClientSetting `mapstructure:"tls"`
 
 	// ReadBufferSize for HTTP client. See http.Transport.ReadBufferSize.
-	ReadBufferSize int `mapstructure:"read_buffer_size"`
+	ReadBufferSize configbytes.ByteSize `mapstructure:"read_buffer_size"`
 
 	// WriteBufferSize for HTTP client. See http.Transport.WriteBufferSize.
-	WriteBufferSize int `mapstructure:"write_buffer_size"`
+	WriteBufferSize configbytes.ByteSize `mapstructure:"write_buffer_size"`
 
 	// Timeout parameter configures `http.Client.Timeout`.
 	Timeout time.Duration `mapstructure:"timeout"`
@@ -108,10 +124,10 @@ func (hcs *HTTPClientSettings) ToClient(host component.Host, settings component.
 		transport.TLSClientConfig = tlsCfg
 	}
 	if hcs.ReadBufferSize > 0 {
-		transport.ReadBufferSize = hcs.ReadBufferSize
+		transport.ReadBufferSize = int(hcs.ReadBufferSize)
 	}
 	if hcs.WriteBufferSize > 0 {
-		transport.WriteBufferSize = hcs.WriteBufferSize
+		transport.WriteBufferSize = int(hcs.WriteBufferSize)
 	}
 
 	if hcs.MaxIdleConns != nil {
@@ -203,6 +219,9 @@ type HTTPServerSettings struct {
 	// Endpoint configures the listening address for the server.
 	Endpoint string `mapstructure:"endpoint"`
 
+	// SocketOptions configures advanced options of the listening socket.
+	SocketOptions confignet.SocketOptions `mapstructure:"socket_options"`
+
 	// TLSSetting struct exposes TLS client configuration.
 	TLSSetting *configtls.TLSServerSetting `mapstructure:"tls"`
 
@@ -212,17 +231,53 @@ type HTTPServerSettings struct {
 	// Auth for this receiver
 	Auth *configauth.Authentication `mapstructure:"auth"`
 
-	// MaxRequestBodySize sets the maximum request body size in bytes
-	MaxRequestBodySize int64 `mapstructure:"max_request_body_size"`
+	// Middlewares for this receiver, invoked in order after Auth.
+	Middlewares []configmiddleware.Middleware `mapstructure:"middlewares"`
+
+	// MaxRequestBodySize sets the maximum request body size in bytes, e.g. "10MiB".
+	// Defaults to 20MiB if not set, a negative value means no limit.
+	MaxRequestBodySize configbytes.ByteSize `mapstructure:"max_request_body_size"`
+
+	// ReadTimeout is the maximum duration for reading the entire request, including the body.
+	// See http.Server.ReadTimeout. Defaults to 1 minute if not set, a negative value means no timeout.
+	ReadTimeout time.Duration `mapstructure:"read_timeout"`
+
+	// ReadHeaderTimeout is the amount of time allowed to read the request headers.
+	// See http.Server.ReadHeaderTimeout. Defaults to 1 minute if not set, a negative value
+	// means the ReadTimeout is used.
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+
+	// WriteTimeout is the maximum duration before timing out writes of the response.
+	// See http.Server.WriteTimeout. Defaults to 30 seconds if not set, a negative value means no timeout.
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+
+	// IdleTimeout is the maximum amount of time to wait for the next request when keep-alives
+	// are enabled. See http.Server.IdleTimeout. Defaults to 1 minute if not set, a negative value
+	// means the ReadTimeout is used.
+	IdleTimeout time.Duration `mapstructure:"idle_timeout"`
+
+	// ResponseCompression lists the compression algorithms the server may use for its responses,
+	// in order of preference. The algorithm is negotiated with the client via the "Accept-Encoding"
+	// request header; responses are not compressed if the list is empty.
+	// Supported values are "gzip", "deflate" and "zstd".
+	ResponseCompression []configcompression.CompressionType `mapstructure:"response_compression"`
 
 	// IncludeMetadata propagates the client metadata from the incoming requests to the downstream consumers
 	// Experimental: *NOTE* this option is subject to change or removal in the future.
 	IncludeMetadata bool `mapstructure:"include_metadata"`
+
+	// DrainTimeout is the maximum amount of time given to the in-flight requests to complete when
+	// the server is shut down via ShutdownServer. Zero means only the shutdown context bounds it.
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
 }
 
 // ToListener creates a net.Listener.
 func (hss *HTTPServerSettings) ToListener() (net.Listener, error) {
-	listener, err := net.Listen("tcp", hss.Endpoint)
+	lc, err := hss.SocketOptions.ListenConfig()
+	if err != nil {
+		return nil, err
+	}
+	listener, err := lc.Listen(context.Background(), "tcp", hss.Endpoint)
 	if err != nil {
 		return nil, err
 	}
@@ -264,13 +319,36 @@ func (hss *HTTPServerSettings) ToServer(host component.Host, settings component.
 		o(serverOpts)
 	}
 
+	for _, ct := range hss.ResponseCompression {
+		if !isSupportedResponseCompression(ct) {
+			return nil, fmt.Errorf("unsupported response compression %q", ct)
+		}
+	}
+
 	handler = httpContentDecompressor(
 		handler,
 		withErrorHandlerForDecompressor(serverOpts.errorHandler),
 	)
 
-	if hss.MaxRequestBodySize > 0 {
-		handler = maxRequestBodySizeInterceptor(handler, hss.MaxRequestBodySize)
+	maxRequestBodySize := hss.MaxRequestBodySize
+	if maxRequestBodySize == 0 {
+		maxRequestBodySize = defaultMaxRequestBodySize
+	}
+	if maxRequestBodySize > 0 {
+		handler = maxRequestBodySizeInterceptor(handler, int64(maxRequestBodySize))
+	}
+
+	// Wrap in reverse order so that the first configured middleware is the outermost one.
+	for i := len(hss.Middlewares) - 1; i >= 0; i-- {
+		middleware, err := hss.Middlewares[i].GetServerMiddleware(host.GetExtensions())
+		if err != nil {
+			return nil, err
+		}
+
+		handler, err = middleware.GetHTTPHandler(handler)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if hss.Auth != nil {
@@ -293,6 +371,10 @@ func (hss *HTTPServerSettings) ToServer(host component.Host, settings component.
 	}
 	// TODO: emit a warning when non-empty CorsHeaders and empty CorsOrigins.
 
+	if len(hss.ResponseCompression) > 0 {
+		handler = httpResponseCompressor(handler, hss.ResponseCompression)
+	}
+
 	// Enable OpenTelemetry observability plugin.
 	// TODO: Consider to use component ID string as prefix for all the operations.
 	handler = otelhttp.NewHandler(
@@ -313,10 +395,46 @@ func (hss *HTTPServerSettings) ToServer(host component.Host, settings component.
 	}
 
 	return &http.Server{
-		Handler: handler,
+		Handler:           handler,
+		ReadTimeout:       timeoutOrDefault(hss.ReadTimeout, defaultReadTimeout),
+		ReadHeaderTimeout: timeoutOrDefault(hss.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		WriteTimeout:      timeoutOrDefault(hss.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       timeoutOrDefault(hss.IdleTimeout, defaultIdleTimeout),
 	}, nil
 }
 
+// timeoutOrDefault returns the timeout if positive, the default if zero, and zero if negative,
+// which http.Server handles as no timeout, or as falling back to the ReadTimeout.
+func timeoutOrDefault(timeout, def time.Duration) time.Duration {
+	switch {
+	case timeout < 0:
+		return 0
+	case timeout == 0:
+		return def
+	}
+	return timeout
+}
+
+// ShutdownServer gracefully shuts down the server created by ToServer: it stops accepting new
+// connections, closes the idle ones and lets the in-flight requests complete, their responses
+// carrying "Connection: close". Once the DrainTimeout elapses or the context is done, the
+// connections still active are closed.
+func (hss *HTTPServerSettings) ShutdownServer(ctx context.Context, server *http.Server) error {
+	if hss.DrainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hss.DrainTimeout)
+		defer cancel()
+	}
+
+	if err := server.Shutdown(ctx); err != nil {
+		if ctx.Err() == nil {
+			return err
+		}
+		return server.Close()
+	}
+	return nil
+}
+
 // CORSSettings configures a receiver for HTTP cross-origin resource sharing (CORS).
 // See the underlying https://github.com/rs/cors package for details.
 type CORSSettings struct {
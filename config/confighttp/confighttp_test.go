@@ -15,6 +15,7 @@
 package confighttp
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -24,6 +25,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -35,6 +37,10 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configbytes"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/config/configmiddleware"
+	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/config/configtls"
 )
 
@@ -383,6 +389,13 @@ func TestHTTPServerSettingsError(t *testing.T) {
 				},
 			},
 		},
+		{
+			err: "^socket buffer sizes must not be negative",
+			settings: HTTPServerSettings{
+				Endpoint:      "localhost:0",
+				SocketOptions: confignet.SocketOptions{ReadBufferSize: -1},
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.err, func(t *testing.T) {
@@ -392,6 +405,28 @@ func TestHTTPServerSettingsError(t *testing.T) {
 	}
 }
 
+func TestHTTPServerSocketOptions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SO_REUSEPORT is not supported on Windows")
+	}
+	hss := &HTTPServerSettings{
+		Endpoint:      "localhost:0",
+		SocketOptions: confignet.SocketOptions{ReusePort: true},
+	}
+	ln1, err := hss.ToListener()
+	require.NoError(t, err)
+	defer ln1.Close()
+
+	// A second listener on the same address only succeeds with SO_REUSEPORT.
+	hss.Endpoint = ln1.Addr().String()
+	ln2, err := hss.ToListener()
+	require.NoError(t, err)
+	assert.NoError(t, ln2.Close())
+
+	_, err = (&HTTPServerSettings{Endpoint: hss.Endpoint}).ToListener()
+	assert.Error(t, err)
+}
+
 func TestHttpReception(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -957,6 +992,239 @@ func TestFailedServerAuth(t *testing.T) {
 	assert.Equal(t, response.Result().Status, fmt.Sprintf("%v %s", http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized)))
 }
 
+func TestServerMiddleware(t *testing.T) {
+	var calls []string
+	newMiddleware := func(name string) configmiddleware.ServerMiddleware {
+		return configmiddleware.NewServerMiddleware(
+			configmiddleware.WithHTTPHandler(func(next http.Handler) (http.Handler, error) {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls = append(calls, name)
+					next.ServeHTTP(w, r)
+				}), nil
+			}),
+		)
+	}
+
+	hss := HTTPServerSettings{
+		Auth: &configauth.Authentication{
+			AuthenticatorID: config.NewComponentID("auth"),
+		},
+		Middlewares: []configmiddleware.Middleware{
+			{MiddlewareID: config.NewComponentID("first")},
+			{MiddlewareID: config.NewComponentID("second")},
+		},
+	}
+
+	host := &mockHost{
+		ext: map[config.ComponentID]component.Extension{
+			config.NewComponentID("auth"): configauth.NewServerAuthenticator(
+				configauth.WithAuthenticate(func(ctx context.Context, headers map[string][]string) (context.Context, error) {
+					calls = append(calls, "auth")
+					return ctx, nil
+				}),
+			),
+			config.NewComponentID("first"):  newMiddleware("first"),
+			config.NewComponentID("second"): newMiddleware("second"),
+		},
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler")
+	})
+
+	srv, err := hss.ToServer(host, componenttest.NewNopTelemetrySettings(), handler)
+	require.NoError(t, err)
+
+	// test
+	srv.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	// verify
+	assert.Equal(t, []string{"auth", "first", "second", "handler"}, calls)
+}
+
+func TestInvalidServerMiddleware(t *testing.T) {
+	hss := HTTPServerSettings{
+		Middlewares: []configmiddleware.Middleware{
+			{MiddlewareID: config.NewComponentID("non-existing")},
+		},
+	}
+
+	srv, err := hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.NewServeMux())
+	require.Error(t, err)
+	require.Nil(t, srv)
+}
+
+func TestServerTimeouts(t *testing.T) {
+	hss := HTTPServerSettings{}
+	srv, err := hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.NewServeMux())
+	require.NoError(t, err)
+	assert.Equal(t, defaultReadHeaderTimeout, srv.ReadHeaderTimeout)
+	assert.Equal(t, defaultReadTimeout, srv.ReadTimeout)
+	assert.Equal(t, defaultWriteTimeout, srv.WriteTimeout)
+	assert.Equal(t, defaultIdleTimeout, srv.IdleTimeout)
+
+	hss = HTTPServerSettings{
+		ReadTimeout:       time.Second,
+		ReadHeaderTimeout: 2 * time.Second,
+		WriteTimeout:      3 * time.Second,
+		IdleTimeout:       4 * time.Second,
+	}
+	srv, err = hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.NewServeMux())
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, srv.ReadTimeout)
+	assert.Equal(t, 2*time.Second, srv.ReadHeaderTimeout)
+	assert.Equal(t, 3*time.Second, srv.WriteTimeout)
+	assert.Equal(t, 4*time.Second, srv.IdleTimeout)
+
+	// Negative values disable the timeouts.
+	hss = HTTPServerSettings{
+		ReadTimeout:       -1,
+		ReadHeaderTimeout: -1,
+		WriteTimeout:      -1,
+		IdleTimeout:       -1,
+	}
+	srv, err = hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.NewServeMux())
+	require.NoError(t, err)
+	assert.Zero(t, srv.ReadTimeout)
+	assert.Zero(t, srv.ReadHeaderTimeout)
+	assert.Zero(t, srv.WriteTimeout)
+	assert.Zero(t, srv.IdleTimeout)
+}
+
+func TestServerMaxRequestBodySize(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     configbytes.ByteSize
+		bodySize int
+		wantErr  bool
+	}{
+		{name: "default", bodySize: int(defaultMaxRequestBodySize)},
+		{name: "default_exceeded", bodySize: int(defaultMaxRequestBodySize) + 1, wantErr: true},
+		{name: "configured", size: 10, bodySize: 10},
+		{name: "configured_exceeded", size: 10, bodySize: 11, wantErr: true},
+		{name: "unlimited", size: -1, bodySize: int(defaultMaxRequestBodySize) + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hss := HTTPServerSettings{MaxRequestBodySize: tt.size}
+			var readErr error
+			srv, err := hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, readErr = io.Copy(io.Discard, r.Body)
+			}))
+			require.NoError(t, err)
+			srv.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(make([]byte, tt.bodySize))))
+			if tt.wantErr {
+				assert.Error(t, readErr)
+			} else {
+				assert.NoError(t, readErr)
+			}
+		})
+	}
+}
+
+func TestShutdownServer(t *testing.T) {
+	tests := []struct {
+		name         string
+		drainTimeout time.Duration
+		release      bool
+	}{
+		{
+			name:         "drained",
+			drainTimeout: 10 * time.Second,
+			release:      true,
+		},
+		{
+			name:         "drain_timeout",
+			drainTimeout: 50 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hss := &HTTPServerSettings{
+				Endpoint:     "localhost:0",
+				DrainTimeout: tt.drainTimeout,
+			}
+			ln, err := hss.ToListener()
+			require.NoError(t, err)
+
+			received := make(chan struct{})
+			release := make(chan struct{})
+			srv, err := hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(),
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					close(received)
+					select {
+					case <-release:
+						w.WriteHeader(http.StatusOK)
+					case <-r.Context().Done():
+					}
+				}))
+			require.NoError(t, err)
+			go func() {
+				_ = srv.Serve(ln)
+			}()
+
+			type result struct {
+				resp *http.Response
+				err  error
+			}
+			results := make(chan result, 1)
+			go func() {
+				resp, errResp := http.Get("http://" + ln.Addr().String())
+				results <- result{resp: resp, err: errResp}
+			}()
+			<-received
+
+			shutdownErr := make(chan error, 1)
+			go func() {
+				shutdownErr <- hss.ShutdownServer(context.Background(), srv)
+			}()
+			if tt.release {
+				// Let the server notice it is shutting down before responding.
+				time.Sleep(50 * time.Millisecond)
+				close(release)
+			}
+
+			select {
+			case err = <-shutdownErr:
+				assert.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("server was not shut down")
+			}
+
+			res := <-results
+			if !tt.release {
+				assert.Error(t, res.err)
+				return
+			}
+			require.NoError(t, res.err)
+			assert.Equal(t, http.StatusOK, res.resp.StatusCode)
+			assert.True(t, res.resp.Close)
+			require.NoError(t, res.resp.Body.Close())
+		})
+	}
+}
+
+func TestServerResponseCompression(t *testing.T) {
+	hss := HTTPServerSettings{
+		ResponseCompression: []configcompression.CompressionType{configcompression.Gzip},
+	}
+	srv, err := hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("response"))
+	}))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+
+	hss.ResponseCompression = []configcompression.CompressionType{configcompression.Snappy}
+	srv, err = hss.ToServer(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings(), http.NewServeMux())
+	assert.EqualError(t, err, `unsupported response compression "snappy"`)
+	assert.Nil(t, srv)
+}
+
 type mockHost struct {
 	component.Host
 	ext map[config.ComponentID]component.Extension
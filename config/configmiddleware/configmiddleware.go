@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmiddleware // import "go.opentelemetry.io/collector/config/configmiddleware"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+)
+
+var (
+	errMiddlewareNotFound  = errors.New("middleware not found")
+	errNotServerMiddleware = errors.New("requested extension is not a server middleware")
+)
+
+// Middleware defines the middleware settings for a server.
+type Middleware struct {
+	// MiddlewareID specifies the name of the extension to use in order to intercept the incoming requests.
+	MiddlewareID config.ComponentID `mapstructure:"id"`
+}
+
+// GetServerMiddleware attempts to select the appropriate ServerMiddleware from the list of extensions,
+// based on the requested extension name. If a middleware is not found, an error is returned.
+func (m Middleware) GetServerMiddleware(extensions map[config.ComponentID]component.Extension) (ServerMiddleware, error) {
+	if ext, found := extensions[m.MiddlewareID]; found {
+		if mw, ok := ext.(ServerMiddleware); ok {
+			return mw, nil
+		}
+		return nil, errNotServerMiddleware
+	}
+
+	return nil, fmt.Errorf("failed to resolve middleware %q: %w", m.MiddlewareID, errMiddlewareNotFound)
+}
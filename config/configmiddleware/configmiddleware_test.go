@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmiddleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+)
+
+type nopExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func TestGetServerMiddleware(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		extension component.Extension
+		expected  error
+	}{
+		{
+			desc:      "obtain server middleware",
+			extension: NewServerMiddleware(),
+			expected:  nil,
+		},
+		{
+			desc:      "not a server middleware",
+			extension: &nopExtension{},
+			expected:  errNotServerMiddleware,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			// prepare
+			cfg := &Middleware{
+				MiddlewareID: config.NewComponentID("mock"),
+			}
+			ext := map[config.ComponentID]component.Extension{
+				config.NewComponentID("mock"): tC.extension,
+			}
+
+			mw, err := cfg.GetServerMiddleware(ext)
+
+			// verify
+			if tC.expected != nil {
+				assert.ErrorIs(t, err, tC.expected)
+				assert.Nil(t, mw)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, mw)
+			}
+		})
+	}
+}
+
+func TestGetServerMiddlewareFails(t *testing.T) {
+	cfg := &Middleware{
+		MiddlewareID: config.NewComponentID("does-not-exist"),
+	}
+
+	mw, err := cfg.GetServerMiddleware(map[config.ComponentID]component.Extension{})
+	assert.ErrorIs(t, err, errMiddlewareNotFound)
+	assert.Nil(t, mw)
+}
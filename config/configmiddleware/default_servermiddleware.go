@@ -0,0 +1,135 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmiddleware // import "go.opentelemetry.io/collector/config/configmiddleware"
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/tap"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+var (
+	_ ServerMiddleware   = (*defaultServerMiddleware)(nil)
+	_ ServerInTapHandler = (*defaultServerMiddleware)(nil)
+)
+
+// Option represents the possible options for NewServerMiddleware.
+type Option func(*defaultServerMiddleware)
+
+type defaultServerMiddleware struct {
+	GetHTTPHandlerFunc
+	GetUnaryServerInterceptorFunc
+	GetStreamServerInterceptorFunc
+	GetInTapHandleFunc
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+// WithHTTPHandler specifies which function to use to wrap the HTTP handlers.
+func WithHTTPHandler(f GetHTTPHandlerFunc) Option {
+	return func(o *defaultServerMiddleware) {
+		o.GetHTTPHandlerFunc = f
+	}
+}
+
+// WithUnaryServerInterceptor specifies which function to use to obtain the gRPC unary server interceptor.
+func WithUnaryServerInterceptor(f GetUnaryServerInterceptorFunc) Option {
+	return func(o *defaultServerMiddleware) {
+		o.GetUnaryServerInterceptorFunc = f
+	}
+}
+
+// WithStreamServerInterceptor specifies which function to use to obtain the gRPC stream server interceptor.
+func WithStreamServerInterceptor(f GetStreamServerInterceptorFunc) Option {
+	return func(o *defaultServerMiddleware) {
+		o.GetStreamServerInterceptorFunc = f
+	}
+}
+
+// WithInTapHandle specifies which function to use to obtain the gRPC server in tap handle.
+func WithInTapHandle(f GetInTapHandleFunc) Option {
+	return func(o *defaultServerMiddleware) {
+		o.GetInTapHandleFunc = f
+	}
+}
+
+// WithStart overrides the default `Start` function for a component.Component.
+// The default always returns nil.
+func WithStart(startFunc component.StartFunc) Option {
+	return func(o *defaultServerMiddleware) {
+		o.StartFunc = startFunc
+	}
+}
+
+// WithShutdown overrides the default `Shutdown` function for a component.Component.
+// The default always returns nil.
+func WithShutdown(shutdownFunc component.ShutdownFunc) Option {
+	return func(o *defaultServerMiddleware) {
+		o.ShutdownFunc = shutdownFunc
+	}
+}
+
+// NewServerMiddleware returns a ServerMiddleware configured with the provided options.
+// By default, the middleware doesn't intercept any request.
+func NewServerMiddleware(options ...Option) ServerMiddleware {
+	sm := &defaultServerMiddleware{
+		GetHTTPHandlerFunc:             func(next http.Handler) (http.Handler, error) { return next, nil },
+		GetUnaryServerInterceptorFunc:  func() (grpc.UnaryServerInterceptor, error) { return nil, nil },
+		GetStreamServerInterceptorFunc: func() (grpc.StreamServerInterceptor, error) { return nil, nil },
+		GetInTapHandleFunc:             func() (tap.ServerInHandle, error) { return nil, nil },
+		StartFunc:                      func(ctx context.Context, host component.Host) error { return nil },
+		ShutdownFunc:                   func(ctx context.Context) error { return nil },
+	}
+
+	for _, op := range options {
+		op(sm)
+	}
+
+	return sm
+}
+
+// GetHTTPHandler wraps the given handler.
+func (m *defaultServerMiddleware) GetHTTPHandler(next http.Handler) (http.Handler, error) {
+	return m.GetHTTPHandlerFunc(next)
+}
+
+// GetUnaryServerInterceptor returns the gRPC unary server interceptor.
+func (m *defaultServerMiddleware) GetUnaryServerInterceptor() (grpc.UnaryServerInterceptor, error) {
+	return m.GetUnaryServerInterceptorFunc()
+}
+
+// GetStreamServerInterceptor returns the gRPC stream server interceptor.
+func (m *defaultServerMiddleware) GetStreamServerInterceptor() (grpc.StreamServerInterceptor, error) {
+	return m.GetStreamServerInterceptorFunc()
+}
+
+// GetInTapHandle returns the gRPC server in tap handle.
+func (m *defaultServerMiddleware) GetInTapHandle() (tap.ServerInHandle, error) {
+	return m.GetInTapHandleFunc()
+}
+
+// Start the component.
+func (m *defaultServerMiddleware) Start(ctx context.Context, host component.Host) error {
+	return m.StartFunc(ctx, host)
+}
+
+// Shutdown stops the component.
+func (m *defaultServerMiddleware) Shutdown(ctx context.Context) error {
+	return m.ShutdownFunc(ctx)
+}
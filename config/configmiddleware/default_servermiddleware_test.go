@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmiddleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/tap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestDefaultServerMiddleware(t *testing.T) {
+	mw := NewServerMiddleware()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler, err := mw.GetHTTPHandler(next)
+	require.NoError(t, err)
+	assert.NotNil(t, handler)
+
+	unary, err := mw.GetUnaryServerInterceptor()
+	assert.NoError(t, err)
+	assert.Nil(t, unary)
+
+	stream, err := mw.GetStreamServerInterceptor()
+	assert.NoError(t, err)
+	assert.Nil(t, stream)
+
+	inTapHandle, err := mw.(ServerInTapHandler).GetInTapHandle()
+	assert.NoError(t, err)
+	assert.Nil(t, inTapHandle)
+
+	assert.NoError(t, mw.Start(context.Background(), componenttest.NewNopHost()))
+	assert.NoError(t, mw.Shutdown(context.Background()))
+}
+
+func TestWithHTTPHandler(t *testing.T) {
+	mw := NewServerMiddleware(WithHTTPHandler(func(next http.Handler) (http.Handler, error) {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Middleware", "called")
+			next.ServeHTTP(w, r)
+		}), nil
+	}))
+
+	nextCalled := false
+	handler, err := mw.GetHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+	}))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.True(t, nextCalled)
+	assert.Equal(t, "called", rec.Header().Get("X-Middleware"))
+}
+
+func TestWithServerInterceptors(t *testing.T) {
+	unaryCalled := false
+	streamCalled := false
+	mw := NewServerMiddleware(
+		WithUnaryServerInterceptor(func() (grpc.UnaryServerInterceptor, error) {
+			return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				unaryCalled = true
+				return handler(ctx, req)
+			}, nil
+		}),
+		WithStreamServerInterceptor(func() (grpc.StreamServerInterceptor, error) {
+			return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				streamCalled = true
+				return handler(srv, ss)
+			}, nil
+		}),
+	)
+
+	unary, err := mw.GetUnaryServerInterceptor()
+	require.NoError(t, err)
+	_, err = unary(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+	assert.NoError(t, err)
+	assert.True(t, unaryCalled)
+
+	stream, err := mw.GetStreamServerInterceptor()
+	require.NoError(t, err)
+	assert.NoError(t, stream(nil, nil, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error { return nil }))
+	assert.True(t, streamCalled)
+}
+
+func TestWithInTapHandle(t *testing.T) {
+	tapCalled := false
+	mw := NewServerMiddleware(WithInTapHandle(func() (tap.ServerInHandle, error) {
+		return func(ctx context.Context, info *tap.Info) (context.Context, error) {
+			tapCalled = true
+			return ctx, nil
+		}, nil
+	}))
+
+	inTapHandle, err := mw.(ServerInTapHandler).GetInTapHandle()
+	require.NoError(t, err)
+	_, err = inTapHandle(context.Background(), &tap.Info{})
+	assert.NoError(t, err)
+	assert.True(t, tapCalled)
+}
+
+func TestWithStartAndShutdown(t *testing.T) {
+	started := false
+	shutdown := false
+	mw := NewServerMiddleware(
+		WithStart(func(context.Context, component.Host) error {
+			started = true
+			return nil
+		}),
+		WithShutdown(func(context.Context) error {
+			shutdown = true
+			return nil
+		}),
+	)
+
+	assert.NoError(t, mw.Start(context.Background(), componenttest.NewNopHost()))
+	assert.True(t, started)
+	assert.NoError(t, mw.Shutdown(context.Background()))
+	assert.True(t, shutdown)
+}
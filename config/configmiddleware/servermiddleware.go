@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configmiddleware // import "go.opentelemetry.io/collector/config/configmiddleware"
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/tap"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// ServerMiddleware is an Extension that can be used as a middleware for the HTTP and gRPC servers
+// created by receivers. Middlewares are invoked after the authentication configured for the server,
+// so the client.Info available from the request context already carries the authentication data.
+type ServerMiddleware interface {
+	component.Extension
+
+	// GetHTTPHandler wraps the given handler. The returned handler must invoke next for the request
+	// to reach the receiver.
+	GetHTTPHandler(next http.Handler) (http.Handler, error)
+
+	// GetUnaryServerInterceptor returns the interceptor to be added to the chain of gRPC unary
+	// interceptors. A nil interceptor is ignored.
+	GetUnaryServerInterceptor() (grpc.UnaryServerInterceptor, error)
+
+	// GetStreamServerInterceptor returns the interceptor to be added to the chain of gRPC stream
+	// interceptors. A nil interceptor is ignored.
+	GetStreamServerInterceptor() (grpc.StreamServerInterceptor, error)
+}
+
+// ServerInTapHandler is optionally implemented by a ServerMiddleware to inspect the gRPC calls
+// before the requests are read and decoded, unlike the interceptors which are invoked once the
+// request is decoded. The handle runs on the connection I/O goroutine, so it must not block.
+type ServerInTapHandler interface {
+	// GetInTapHandle returns the handle to be run when a new gRPC stream is created, before
+	// the middlewares' interceptors. A nil handle is ignored.
+	GetInTapHandle() (tap.ServerInHandle, error)
+}
+
+// GetHTTPHandlerFunc specifies the function that wraps an HTTP handler.
+type GetHTTPHandlerFunc func(next http.Handler) (http.Handler, error)
+
+// GetUnaryServerInterceptorFunc specifies the function that returns a gRPC unary server interceptor.
+type GetUnaryServerInterceptorFunc func() (grpc.UnaryServerInterceptor, error)
+
+// GetStreamServerInterceptorFunc specifies the function that returns a gRPC stream server interceptor.
+type GetStreamServerInterceptorFunc func() (grpc.StreamServerInterceptor, error)
+
+// GetInTapHandleFunc specifies the function that returns a gRPC server in tap handle.
+type GetInTapHandleFunc func() (tap.ServerInHandle, error)
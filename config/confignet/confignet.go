@@ -15,9 +15,57 @@
 package confignet // import "go.opentelemetry.io/collector/config/confignet"
 
 import (
+	"context"
+	"errors"
 	"net"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configbytes"
 )
 
+// SocketOptions configures advanced options applied to listening sockets.
+// Connections accepted by the listener inherit these options.
+type SocketOptions struct {
+	// ReusePort enables SO_REUSEPORT, allowing multiple processes to bind the same address
+	// so that the kernel load balances incoming connections between them.
+	// Only supported on Linux, macOS and BSD platforms.
+	ReusePort bool `mapstructure:"reuse_port"`
+
+	// KeepAlive specifies the keep-alive period for accepted TCP connections.
+	// If zero, keep-alives are enabled with the OS default period if supported.
+	// If negative, keep-alives are disabled.
+	KeepAlive time.Duration `mapstructure:"keep_alive"`
+
+	// ReadBufferSize sets SO_RCVBUF, the size of the socket receive buffer, e.g. "256KiB".
+	// If zero, the OS default is used.
+	ReadBufferSize configbytes.ByteSize `mapstructure:"read_buffer_size"`
+
+	// WriteBufferSize sets SO_SNDBUF, the size of the socket send buffer, e.g. "256KiB".
+	// If zero, the OS default is used.
+	WriteBufferSize configbytes.ByteSize `mapstructure:"write_buffer_size"`
+}
+
+// ListenConfig returns the net.ListenConfig applying the socket options to the listeners it creates,
+// for the servers that don't create their listener via NetAddr or TCPAddr.
+func (so *SocketOptions) ListenConfig() (net.ListenConfig, error) {
+	if so.ReadBufferSize < 0 || so.WriteBufferSize < 0 {
+		return net.ListenConfig{}, errors.New("socket buffer sizes must not be negative")
+	}
+	lc := net.ListenConfig{KeepAlive: so.KeepAlive}
+	if so.ReusePort || so.ReadBufferSize != 0 || so.WriteBufferSize != 0 {
+		lc.Control = so.control
+	}
+	return lc, nil
+}
+
+func (so *SocketOptions) listen(network, address string) (net.Listener, error) {
+	lc, err := so.ListenConfig()
+	if err != nil {
+		return nil, err
+	}
+	return lc.Listen(context.Background(), network, address)
+}
+
 // NetAddr represents a network endpoint address.
 type NetAddr struct {
 	// Endpoint configures the address for this network connection.
@@ -30,6 +78,9 @@ type NetAddr struct {
 	// Transport to use. Known protocols are "tcp", "tcp4" (IPv4-only), "tcp6" (IPv6-only), "udp", "udp4" (IPv4-only),
 	// "udp6" (IPv6-only), "ip", "ip4" (IPv4-only), "ip6" (IPv6-only), "unix", "unixgram" and "unixpacket".
 	Transport string `mapstructure:"transport"`
+
+	// SocketOptions configures advanced options of the listening socket.
+	SocketOptions SocketOptions `mapstructure:"socket_options"`
 }
 
 // Dial equivalent with net.Dial for this address.
@@ -39,7 +90,7 @@ func (na *NetAddr) Dial() (net.Conn, error) {
 
 // Listen equivalent with net.Listen for this address.
 func (na *NetAddr) Listen() (net.Listener, error) {
-	return net.Listen(na.Transport, na.Endpoint)
+	return na.SocketOptions.listen(na.Transport, na.Endpoint)
 }
 
 // TCPAddr represents a TCP endpoint address.
@@ -50,6 +101,9 @@ type TCPAddr struct {
 	// If the host is a literal IPv6 address it must be enclosed in square brackets, as in "[2001:db8::1]:80" or
 	// "[fe80::1%zone]:80". The zone specifies the scope of the literal IPv6 address as defined in RFC 4007.
 	Endpoint string `mapstructure:"endpoint"`
+
+	// SocketOptions configures advanced options of the listening socket.
+	SocketOptions SocketOptions `mapstructure:"socket_options"`
 }
 
 // Dial equivalent with net.Dial for this address.
@@ -59,5 +113,5 @@ func (na *TCPAddr) Dial() (net.Conn, error) {
 
 // Listen equivalent with net.Listen for this address.
 func (na *TCPAddr) Listen() (net.Listener, error) {
-	return net.Listen("tcp", na.Endpoint)
+	return na.SocketOptions.listen("tcp", na.Endpoint)
 }
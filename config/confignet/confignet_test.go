@@ -17,8 +17,10 @@ package confignet
 import (
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNetAddr(t *testing.T) {
@@ -88,3 +90,25 @@ func TestTcpAddr(t *testing.T) {
 	<-done
 	assert.NoError(t, ln.Close())
 }
+
+func TestSocketOptionsNegativeBufferSize(t *testing.T) {
+	nas := &NetAddr{
+		Endpoint:      "localhost:0",
+		Transport:     "tcp",
+		SocketOptions: SocketOptions{WriteBufferSize: -1},
+	}
+	_, err := nas.Listen()
+	assert.Error(t, err)
+}
+
+func TestSocketOptionsListenConfig(t *testing.T) {
+	so := &SocketOptions{KeepAlive: -1}
+	lc, err := so.ListenConfig()
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(-1), lc.KeepAlive)
+	assert.Nil(t, lc.Control)
+
+	so = &SocketOptions{ReadBufferSize: -1}
+	_, err = so.ListenConfig()
+	assert.Error(t, err)
+}
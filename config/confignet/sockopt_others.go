@@ -0,0 +1,27 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package confignet // import "go.opentelemetry.io/collector/config/confignet"
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+)
+
+func (so *SocketOptions) control(_, _ string, _ syscall.RawConn) error {
+	return fmt.Errorf("socket options reuse_port, read_buffer_size and write_buffer_size are not supported on %s", runtime.GOOS)
+}
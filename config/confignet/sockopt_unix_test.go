@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package confignet
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestReusePort(t *testing.T) {
+	nas := &NetAddr{
+		Endpoint:      "localhost:0",
+		Transport:     "tcp",
+		SocketOptions: SocketOptions{ReusePort: true},
+	}
+	ln1, err := nas.Listen()
+	require.NoError(t, err)
+	defer ln1.Close()
+
+	// A second listener on the same address only succeeds with SO_REUSEPORT.
+	nas.Endpoint = ln1.Addr().String()
+	ln2, err := nas.Listen()
+	require.NoError(t, err)
+	assert.NoError(t, ln2.Close())
+
+	// Without SO_REUSEPORT the address is in use.
+	_, err = (&TCPAddr{Endpoint: nas.Endpoint}).Listen()
+	assert.Error(t, err)
+}
+
+func TestSocketBufferSizes(t *testing.T) {
+	nas := &TCPAddr{
+		Endpoint: "localhost:0",
+		SocketOptions: SocketOptions{
+			ReadBufferSize:  64 * 1024,
+			WriteBufferSize: 32 * 1024,
+		},
+	}
+	ln, err := nas.Listen()
+	require.NoError(t, err)
+	defer ln.Close()
+
+	rcvBuf, sndBuf := getBufferSizes(t, ln.(*net.TCPListener))
+	// The kernel may round up (e.g. Linux doubles the value) but never returns less.
+	assert.GreaterOrEqual(t, rcvBuf, 64*1024)
+	assert.GreaterOrEqual(t, sndBuf, 32*1024)
+}
+
+func getBufferSizes(t *testing.T, ln *net.TCPListener) (int, int) {
+	rc, err := ln.SyscallConn()
+	require.NoError(t, err)
+	var rcvBuf, sndBuf int
+	var rcvErr, sndErr error
+	require.NoError(t, rc.Control(func(fd uintptr) {
+		rcvBuf, rcvErr = unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_RCVBUF)
+		sndBuf, sndErr = unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_SNDBUF)
+	}))
+	require.NoError(t, rcvErr)
+	require.NoError(t, sndErr)
+	return rcvBuf, sndBuf
+}
+
+func TestTcpAddrKeepAlive(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		keepAlive time.Duration
+		expected  int
+	}{
+		{name: "default", keepAlive: 0, expected: 1},
+		{name: "disabled", keepAlive: -1, expected: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			nas := &TCPAddr{
+				Endpoint:      "localhost:0",
+				SocketOptions: SocketOptions{KeepAlive: tt.keepAlive},
+			}
+			ln, err := nas.Listen()
+			require.NoError(t, err)
+			defer ln.Close()
+
+			accepted := make(chan net.Conn, 1)
+			go func() {
+				conn, errGo := ln.Accept()
+				assert.NoError(t, errGo)
+				accepted <- conn
+			}()
+
+			conn, err := (&TCPAddr{Endpoint: ln.Addr().String()}).Dial()
+			require.NoError(t, err)
+			defer conn.Close()
+			serverConn := <-accepted
+			require.NotNil(t, serverConn)
+			defer serverConn.Close()
+
+			rc, err := serverConn.(*net.TCPConn).SyscallConn()
+			require.NoError(t, err)
+			var keepAlive int
+			var sockErr error
+			require.NoError(t, rc.Control(func(fd uintptr) {
+				keepAlive, sockErr = unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_KEEPALIVE)
+			}))
+			require.NoError(t, sockErr)
+			// Some platforms report the flag as a non zero value other than 1.
+			assert.Equal(t, tt.expected != 0, keepAlive != 0)
+		})
+	}
+}
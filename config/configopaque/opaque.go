@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configopaque // import "go.opentelemetry.io/collector/config/configopaque"
+
+// redacted replaces the value of a String when it is printed or marshaled.
+const redacted = "[REDACTED]"
+
+// String is a string holding a secret, such as a password or a token. It is unmarshaled from the
+// configuration like a string, but it is redacted when printed or marshaled, e.g. in logs or by the
+// print-config command. Use string(s) to get the secret.
+type String string
+
+// String returns the redacted value, so that the secret is not printed by mistake.
+func (s String) String() string {
+	return redacted
+}
+
+// GoString returns the redacted value, for the %#v verb.
+func (s String) GoString() string {
+	return `"` + redacted + `"`
+}
+
+// MarshalText marshals the redacted value.
+func (s String) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
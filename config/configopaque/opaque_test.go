@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configopaque
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	s := String("secret")
+	assert.Equal(t, "secret", string(s))
+	assert.Equal(t, "[REDACTED]", s.String())
+	assert.Equal(t, "[REDACTED] \"[REDACTED]\"", fmt.Sprintf("%v %#v", s, s))
+
+	out, err := json.Marshal(struct {
+		Token String `json:"token"`
+	}{Token: s})
+	require.NoError(t, err)
+	assert.Equal(t, `{"token":"[REDACTED]"}`, string(out))
+}
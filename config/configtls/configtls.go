@@ -51,6 +51,11 @@ type TLSSetting struct {
 	// ReloadInterval specifies the duration after which the certificate will be reloaded
 	// If not set, it will never be reloaded (optional)
 	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+
+	// ReloadOnChange enables reloading the certificate, the key and the CA files when they change
+	// on disk, e.g. after being rotated by cert-manager. The files are checked at most once per second,
+	// when a new connection is established. (optional, default false)
+	ReloadOnChange bool `mapstructure:"reload_on_change"`
 }
 
 // TLSClientSetting contains TLS configurations that are specific to client
@@ -106,23 +111,43 @@ type certReloader struct {
 	nextReload     time.Time
 	cert           *tls.Certificate
 	lock           sync.RWMutex
+	// changes detects changes to the files, it is nil unless ReloadOnChange is set.
+	changes *changeDetector
 }
 
-func newCertReloader(certFile, keyFile string, reloadInterval time.Duration) (*certReloader, error) {
+func newCertReloader(certFile, keyFile string, reloadInterval time.Duration, reloadOnChange bool) (*certReloader, error) {
 	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
 	if err != nil {
 		return nil, err
 	}
-	return &certReloader{
+	r := &certReloader{
 		CertFile:       certFile,
 		KeyFile:        keyFile,
 		ReloadInterval: reloadInterval,
 		nextReload:     time.Now().Add(reloadInterval),
 		cert:           &cert,
-	}, nil
+	}
+	if reloadOnChange {
+		r.changes = newChangeDetector(certFile, keyFile)
+	}
+	return r, nil
 }
 
 func (r *certReloader) GetCertificate() (*tls.Certificate, error) {
+	if r.changes != nil {
+		// Keep serving the current certificate until the files contain a valid pair.
+		_ = r.changes.reloadIfChanged(func() error {
+			cert, err := tls.LoadX509KeyPair(r.CertFile, r.KeyFile)
+			if err != nil {
+				return err
+			}
+			r.lock.Lock()
+			r.cert = &cert
+			r.lock.Unlock()
+			return nil
+		})
+	}
+
 	now := time.Now()
 	// Read locking here before we do the time comparison
 	// If a reload is in progress this will block and we will skip reloading in the current
@@ -168,7 +193,7 @@ func (c TLSSetting) loadTLSConfig() (*tls.Config, error) {
 	var getClientCertificate func(*tls.CertificateRequestInfo) (*tls.Certificate, error)
 	if c.CertFile != "" && c.KeyFile != "" {
 		var certReloader *certReloader
-		certReloader, err = newCertReloader(c.CertFile, c.KeyFile, c.ReloadInterval)
+		certReloader, err = newCertReloader(c.CertFile, c.KeyFile, c.ReloadInterval, c.ReloadOnChange)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load TLS cert and key: %w", err)
 		}
@@ -219,6 +244,11 @@ func (c TLSClientSetting) LoadTLSConfig() (*tls.Config, error) {
 	}
 	tlsCfg.ServerName = c.ServerName
 	tlsCfg.InsecureSkipVerify = c.InsecureSkipVerify
+	if c.ReloadOnChange && c.CAFile != "" && !c.InsecureSkipVerify {
+		// The verification is done by VerifyConnection, against the latest CA pool.
+		tlsCfg.InsecureSkipVerify = true
+		tlsCfg.VerifyConnection = verifyServerCertificate(newCAReloader(c.CAFile, tlsCfg.RootCAs))
+	}
 	return tlsCfg, nil
 }
 
@@ -235,6 +265,15 @@ func (c TLSServerSetting) LoadTLSConfig() (*tls.Config, error) {
 		}
 		tlsCfg.ClientCAs = certPool
 		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+		if c.ReloadOnChange {
+			clientCAs := newCAReloader(c.ClientCAFile, certPool)
+			tlsCfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+				cfg := tlsCfg.Clone()
+				cfg.GetConfigForClient = nil
+				cfg.ClientCAs = clientCAs.GetCertPool()
+				return cfg, nil
+			}
+		}
 	}
 	return tlsCfg, nil
 }
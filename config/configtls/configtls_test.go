@@ -17,6 +17,7 @@ package configtls
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
 	"io"
 	"os"
 	"path/filepath"
@@ -392,3 +393,161 @@ func TestMinMaxTLSVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestReloadOnChangeCertificate(t *testing.T) {
+	defer func(interval time.Duration) { fileCheckInterval = interval }(fileCheckInterval)
+	fileCheckInterval = 0
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert")
+	keyFile := filepath.Join(dir, "key")
+	copyTestdataFile(t, "client-1.crt", certFile)
+	copyTestdataFile(t, "client-1.key", keyFile)
+
+	options := TLSSetting{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ReloadOnChange: true,
+	}
+	cfg, err := options.loadTLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "example1", certificateDNSName(t, cfg))
+
+	// Rotate the certificate.
+	copyTestdataFile(t, "client-2.crt", certFile)
+	copyTestdataFile(t, "client-2.key", keyFile)
+	touchFiles(t, certFile, keyFile)
+	assert.Equal(t, "example2", certificateDNSName(t, cfg))
+
+	// An invalid certificate is not loaded, the previous one keeps being used.
+	require.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0600))
+	touchFiles(t, certFile)
+	assert.Equal(t, "example2", certificateDNSName(t, cfg))
+}
+
+func TestReloadOnChangeClientCA(t *testing.T) {
+	defer func(interval time.Duration) { fileCheckInterval = interval }(fileCheckInterval)
+	fileCheckInterval = 0
+
+	caFile := filepath.Join(t.TempDir(), "ca")
+	copyTestdataFile(t, "ca-1.crt", caFile)
+
+	options := TLSClientSetting{
+		TLSSetting: TLSSetting{
+			CAFile:         caFile,
+			ReloadOnChange: true,
+		},
+	}
+	cfg, err := options.LoadTLSConfig()
+	require.NoError(t, err)
+	require.NotNil(t, cfg.VerifyConnection)
+	assert.True(t, cfg.InsecureSkipVerify)
+
+	server1 := connectionState(t, "server-1.crt", "example1")
+	server2 := connectionState(t, "server-2.crt", "example2")
+	assert.NoError(t, cfg.VerifyConnection(server1))
+	assert.Error(t, cfg.VerifyConnection(server2))
+	assert.Error(t, cfg.VerifyConnection(connectionState(t, "server-1.crt", "example2")))
+	assert.Error(t, cfg.VerifyConnection(connectionState(t, "server-1.crt", "")))
+
+	// Rotate the CA.
+	copyTestdataFile(t, "ca-2.crt", caFile)
+	touchFiles(t, caFile)
+	assert.Error(t, cfg.VerifyConnection(server1))
+	assert.NoError(t, cfg.VerifyConnection(server2))
+}
+
+func TestReloadOnChangeClientInsecureSkipVerify(t *testing.T) {
+	options := TLSClientSetting{
+		TLSSetting: TLSSetting{
+			CAFile:         filepath.Join("testdata", "ca-1.crt"),
+			ReloadOnChange: true,
+		},
+		InsecureSkipVerify: true,
+	}
+	cfg, err := options.LoadTLSConfig()
+	require.NoError(t, err)
+	assert.Nil(t, cfg.VerifyConnection)
+}
+
+func TestReloadOnChangeServerClientCA(t *testing.T) {
+	defer func(interval time.Duration) { fileCheckInterval = interval }(fileCheckInterval)
+	fileCheckInterval = 0
+
+	clientCAFile := filepath.Join(t.TempDir(), "ca")
+	copyTestdataFile(t, "ca-1.crt", clientCAFile)
+
+	options := TLSServerSetting{
+		TLSSetting: TLSSetting{
+			CertFile:       filepath.Join("testdata", "server-1.crt"),
+			KeyFile:        filepath.Join("testdata", "server-1.key"),
+			ReloadOnChange: true,
+		},
+		ClientCAFile: clientCAFile,
+	}
+	cfg, err := options.LoadTLSConfig()
+	require.NoError(t, err)
+	require.NotNil(t, cfg.GetConfigForClient)
+
+	verifyClient := func(certFile string) error {
+		clientCfg, err := cfg.GetConfigForClient(&tls.ClientHelloInfo{})
+		require.NoError(t, err)
+		assert.Nil(t, clientCfg.GetConfigForClient)
+		assert.Equal(t, tls.RequireAndVerifyClientCert, clientCfg.ClientAuth)
+		_, err = parseTestdataCertificate(t, certFile).Verify(x509.VerifyOptions{
+			Roots:     clientCfg.ClientCAs,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		return err
+	}
+	assert.NoError(t, verifyClient("server-1.crt"))
+	assert.Error(t, verifyClient("server-2.crt"))
+
+	// Rotate the client CA.
+	copyTestdataFile(t, "ca-2.crt", clientCAFile)
+	touchFiles(t, clientCAFile)
+	assert.Error(t, verifyClient("server-1.crt"))
+	assert.NoError(t, verifyClient("server-2.crt"))
+}
+
+func copyTestdataFile(t *testing.T, name string, dst string) {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dst, data, 0600))
+}
+
+// touchFiles moves the modification time of the files forward, so that changes are detected
+// on file systems with a coarse time resolution.
+func touchFiles(t *testing.T, files ...string) {
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		require.NoError(t, err)
+		mtime := fi.ModTime().Add(time.Second)
+		require.NoError(t, os.Chtimes(f, mtime, mtime))
+	}
+}
+
+func certificateDNSName(t *testing.T, cfg *tls.Config) string {
+	cert, err := cfg.GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	pCert, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return pCert.DNSNames[0]
+}
+
+func parseTestdataCertificate(t *testing.T, name string) *x509.Certificate {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return cert
+}
+
+func connectionState(t *testing.T, certFile string, serverName string) tls.ConnectionState {
+	return tls.ConnectionState{
+		ServerName:       serverName,
+		PeerCertificates: []*x509.Certificate{parseTestdataCertificate(t, certFile)},
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configtls // import "go.opentelemetry.io/collector/config/configtls"
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileCheckInterval limits how often the files are checked for changes when ReloadOnChange is set.
+var fileCheckInterval = time.Second
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileState {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: fi.ModTime(), size: fi.Size()}
+}
+
+// changeDetector detects changes to a set of files by comparing their modification time and size.
+// Files are checked lazily, at most once per fileCheckInterval, so no goroutine needs to be managed.
+type changeDetector struct {
+	files     []string
+	states    []fileState
+	nextCheck time.Time
+	lock      sync.Mutex
+}
+
+func newChangeDetector(files ...string) *changeDetector {
+	states := make([]fileState, len(files))
+	for i, f := range files {
+		states[i] = statFile(f)
+	}
+	return &changeDetector{
+		files:     files,
+		states:    states,
+		nextCheck: time.Now().Add(fileCheckInterval),
+	}
+}
+
+// reloadIfChanged calls reload if any of the files changed since the last successful reload.
+// If reload fails, it is retried on the next check even if the files don't change again, so a
+// certificate and key that are not updated atomically are eventually picked up.
+func (d *changeDetector) reloadIfChanged(reload func() error) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := time.Now()
+	if now.Before(d.nextCheck) {
+		return nil
+	}
+	d.nextCheck = now.Add(fileCheckInterval)
+
+	states := make([]fileState, len(d.files))
+	changed := false
+	for i, f := range d.files {
+		states[i] = statFile(f)
+		if states[i] != d.states[i] {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := reload(); err != nil {
+		return err
+	}
+	d.states = states
+	return nil
+}
+
+// caReloader keeps a CA pool up to date with the content of the CA file.
+type caReloader struct {
+	caFile  string
+	pool    *x509.CertPool
+	changes *changeDetector
+	lock    sync.RWMutex
+}
+
+func newCAReloader(caFile string, pool *x509.CertPool) *caReloader {
+	return &caReloader{
+		caFile:  caFile,
+		pool:    pool,
+		changes: newChangeDetector(caFile),
+	}
+}
+
+// GetCertPool returns the current CA pool. The previous pool is kept if the CA file cannot be loaded.
+func (r *caReloader) GetCertPool() *x509.CertPool {
+	_ = r.changes.reloadIfChanged(func() error {
+		pool, err := TLSSetting{}.loadCert(r.caFile)
+		if err != nil {
+			return err
+		}
+		r.lock.Lock()
+		r.pool = pool
+		r.lock.Unlock()
+		return nil
+	})
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.pool
+}
+
+// verifyServerCertificate returns a tls.Config.VerifyConnection function that performs the
+// standard verification of the server certificate chain and host name against the current CA pool.
+// It's used by clients, with InsecureSkipVerify set, since tls.Config.RootCAs can't be updated
+// once the configuration has been handed over to the transport.
+func verifyServerCertificate(r *caReloader) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("tls: server didn't provide a certificate")
+		}
+		// The server name is only known here when it is sent in the SNI extension, which
+		// is not the case for IP addresses: refuse to skip the host name verification.
+		if cs.ServerName == "" {
+			return errors.New("tls: reloading the CA requires the server to be addressed by host name")
+		}
+
+		opts := x509.VerifyOptions{
+			Roots:         r.GetCertPool(),
+			DNSName:       cs.ServerName,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(opts)
+		return err
+	}
+}
@@ -17,6 +17,7 @@ package config // import "go.opentelemetry.io/collector/config"
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"go.opentelemetry.io/collector/service/telemetry"
 )
@@ -25,8 +26,28 @@ var (
 	errMissingExporters        = errors.New("no enabled exporters specified in config")
 	errMissingReceivers        = errors.New("no enabled receivers specified in config")
 	errMissingServicePipelines = errors.New("service must have at least one pipeline")
+
+	errNegativeConfigPollInterval = errors.New("service config_poll_interval must not be negative")
 )
 
+// ComponentValidationError is the error returned by Config.Validate when the configuration of a component is invalid.
+type ComponentValidationError struct {
+	// Kind of the component: "receiver", "exporter", "processor" or "extension".
+	Kind string
+	// ID of the component.
+	ID ComponentID
+	// Err is the error returned by the component configuration Validate.
+	Err error
+}
+
+func (e *ComponentValidationError) Error() string {
+	return fmt.Sprintf("%s %q has invalid configuration: %v", e.Kind, e.ID, e.Err)
+}
+
+func (e *ComponentValidationError) Unwrap() error {
+	return e.Err
+}
+
 // Config defines the configuration for the various elements of collector or agent.
 // Deprecated: [v0.52.0] Use service.Config
 type Config struct {
@@ -62,7 +83,7 @@ func (cfg *Config) Validate() error {
 	// Validate the receiver configuration.
 	for recvID, recvCfg := range cfg.Receivers {
 		if err := recvCfg.Validate(); err != nil {
-			return fmt.Errorf("receiver %q has invalid configuration: %w", recvID, err)
+			return &ComponentValidationError{Kind: "receiver", ID: recvID, Err: err}
 		}
 	}
 
@@ -75,21 +96,21 @@ func (cfg *Config) Validate() error {
 	// Validate the exporter configuration.
 	for expID, expCfg := range cfg.Exporters {
 		if err := expCfg.Validate(); err != nil {
-			return fmt.Errorf("exporter %q has invalid configuration: %w", expID, err)
+			return &ComponentValidationError{Kind: "exporter", ID: expID, Err: err}
 		}
 	}
 
 	// Validate the processor configuration.
 	for procID, procCfg := range cfg.Processors {
 		if err := procCfg.Validate(); err != nil {
-			return fmt.Errorf("processor %q has invalid configuration: %w", procID, err)
+			return &ComponentValidationError{Kind: "processor", ID: procID, Err: err}
 		}
 	}
 
 	// Validate the extension configuration.
 	for extID, extCfg := range cfg.Extensions {
 		if err := extCfg.Validate(); err != nil {
-			return fmt.Errorf("extension %q has invalid configuration: %w", extID, err)
+			return &ComponentValidationError{Kind: "extension", ID: extID, Err: err}
 		}
 	}
 
@@ -147,7 +168,10 @@ func (cfg *Config) validateService() error {
 			}
 		}
 	}
-	return nil
+	if cfg.Service.ConfigPollInterval < 0 {
+		return errNegativeConfigPollInterval
+	}
+	return cfg.Service.Timeouts.validate(cfg)
 }
 
 // Service defines the configurable components of the service.
@@ -161,6 +185,14 @@ type Service struct {
 
 	// Pipelines are the set of data pipelines configured for the service.
 	Pipelines map[ComponentID]*Pipeline `mapstructure:"pipelines"`
+
+	// Timeouts are the timeouts of starting and shutting down the components.
+	Timeouts ServiceTimeouts `mapstructure:"timeouts"`
+
+	// ConfigPollInterval is the default interval at which the remote config providers supporting
+	// watching poll for changes, unless set by the collector settings, e.g. the --config-poll-interval
+	// flag. Zero leaves the provider default.
+	ConfigPollInterval time.Duration `mapstructure:"config_poll_interval"`
 }
 
 // Pipeline defines a single pipeline.
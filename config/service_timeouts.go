@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config // import "go.opentelemetry.io/collector/config"
+
+import (
+	"fmt"
+	"time"
+)
+
+// ComponentTimeouts are the timeouts of starting and shutting down a component.
+type ComponentTimeouts struct {
+	// Start is the maximum time the component is given to start. Zero means no timeout.
+	Start time.Duration `mapstructure:"start"`
+
+	// Shutdown is the maximum time the component is given to shut down. Zero means no timeout.
+	Shutdown time.Duration `mapstructure:"shutdown"`
+}
+
+// ServiceTimeouts configures the timeouts of starting and shutting down the components of the service,
+// so that a single stuck component cannot hang the whole Collector. A component exceeding its start
+// timeout fails the start, while a component exceeding its shutdown timeout is reported as failed and
+// the shutdown proceeds with the other components.
+type ServiceTimeouts struct {
+	// ComponentTimeouts are the default timeouts of all the components.
+	ComponentTimeouts `mapstructure:",squash"`
+
+	// Receivers override the default timeouts by receiver, unset timeouts fall back to the default.
+	Receivers map[ComponentID]ComponentTimeouts `mapstructure:"receivers"`
+
+	// Processors override the default timeouts by processor, unset timeouts fall back to the default.
+	Processors map[ComponentID]ComponentTimeouts `mapstructure:"processors"`
+
+	// Exporters override the default timeouts by exporter, unset timeouts fall back to the default.
+	Exporters map[ComponentID]ComponentTimeouts `mapstructure:"exporters"`
+
+	// Extensions override the default timeouts by extension, unset timeouts fall back to the default.
+	Extensions map[ComponentID]ComponentTimeouts `mapstructure:"extensions"`
+}
+
+// Receiver returns the timeouts of the given receiver.
+func (st ServiceTimeouts) Receiver(id ComponentID) ComponentTimeouts {
+	return st.withDefaults(st.Receivers[id])
+}
+
+// Processor returns the timeouts of the given processor.
+func (st ServiceTimeouts) Processor(id ComponentID) ComponentTimeouts {
+	return st.withDefaults(st.Processors[id])
+}
+
+// Exporter returns the timeouts of the given exporter.
+func (st ServiceTimeouts) Exporter(id ComponentID) ComponentTimeouts {
+	return st.withDefaults(st.Exporters[id])
+}
+
+// Extension returns the timeouts of the given extension.
+func (st ServiceTimeouts) Extension(id ComponentID) ComponentTimeouts {
+	return st.withDefaults(st.Extensions[id])
+}
+
+func (st ServiceTimeouts) withDefaults(ct ComponentTimeouts) ComponentTimeouts {
+	if ct.Start == 0 {
+		ct.Start = st.Start
+	}
+	if ct.Shutdown == 0 {
+		ct.Shutdown = st.Shutdown
+	}
+	return ct
+}
+
+// validate checks that the timeouts are not negative, and only reference configured components.
+func (st ServiceTimeouts) validate(cfg *Config) error {
+	if err := st.ComponentTimeouts.validate(); err != nil {
+		return fmt.Errorf("service timeouts: %v", err)
+	}
+	for id, ct := range st.Receivers {
+		if cfg.Receivers[id] == nil {
+			return fmt.Errorf("service timeouts reference receiver %q which does not exist", id)
+		}
+		if err := ct.validate(); err != nil {
+			return fmt.Errorf("service timeouts of receiver %q: %v", id, err)
+		}
+	}
+	for id, ct := range st.Processors {
+		if cfg.Processors[id] == nil {
+			return fmt.Errorf("service timeouts reference processor %q which does not exist", id)
+		}
+		if err := ct.validate(); err != nil {
+			return fmt.Errorf("service timeouts of processor %q: %v", id, err)
+		}
+	}
+	for id, ct := range st.Exporters {
+		if cfg.Exporters[id] == nil {
+			return fmt.Errorf("service timeouts reference exporter %q which does not exist", id)
+		}
+		if err := ct.validate(); err != nil {
+			return fmt.Errorf("service timeouts of exporter %q: %v", id, err)
+		}
+	}
+	for id, ct := range st.Extensions {
+		if cfg.Extensions[id] == nil {
+			return fmt.Errorf("service timeouts reference extension %q which does not exist", id)
+		}
+		if err := ct.validate(); err != nil {
+			return fmt.Errorf("service timeouts of extension %q: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func (ct ComponentTimeouts) validate() error {
+	if ct.Start < 0 {
+		return fmt.Errorf("start timeout must not be negative, got %v", ct.Start)
+	}
+	if ct.Shutdown < 0 {
+		return fmt.Errorf("shutdown timeout must not be negative, got %v", ct.Shutdown)
+	}
+	return nil
+}
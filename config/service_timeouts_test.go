@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceTimeouts(t *testing.T) {
+	otlp := NewComponentID("otlp")
+	st := ServiceTimeouts{
+		ComponentTimeouts: ComponentTimeouts{Start: time.Minute, Shutdown: 30 * time.Second},
+		Receivers:         map[ComponentID]ComponentTimeouts{otlp: {Start: time.Second}},
+		Processors:        map[ComponentID]ComponentTimeouts{otlp: {Shutdown: 2 * time.Second}},
+		Exporters:         map[ComponentID]ComponentTimeouts{otlp: {Start: 3 * time.Second, Shutdown: 4 * time.Second}},
+	}
+	assert.Equal(t, ComponentTimeouts{Start: time.Second, Shutdown: 30 * time.Second}, st.Receiver(otlp))
+	assert.Equal(t, ComponentTimeouts{Start: time.Minute, Shutdown: 2 * time.Second}, st.Processor(otlp))
+	assert.Equal(t, ComponentTimeouts{Start: 3 * time.Second, Shutdown: 4 * time.Second}, st.Exporter(otlp))
+	assert.Equal(t, ComponentTimeouts{Start: time.Minute, Shutdown: 30 * time.Second}, st.Extension(otlp))
+	// Not overridden.
+	assert.Equal(t, st.ComponentTimeouts, st.Receiver(NewComponentIDWithName("otlp", "2")))
+}
@@ -18,6 +18,8 @@ import (
 	"encoding"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/maps"
@@ -47,6 +49,10 @@ func NewFromStringMap(data map[string]interface{}) *Conf {
 // The confmap.Conf can be unmarshalled into the Collector's config using the "service" package.
 type Conf struct {
 	k *koanf.Koanf
+	// positions holds the positions of the keys in the configuration sources, if known.
+	positions map[string]Position
+	// sources holds where the values of the keys were last set, if known.
+	sources map[string]Source
 }
 
 // AllKeys returns all keys holding a value, regardless of where they are set.
@@ -90,7 +96,12 @@ func (l *Conf) IsSet(key string) bool {
 // Merge merges the input given configuration into the existing config.
 // Note that the given map may be modified.
 func (l *Conf) Merge(in *Conf) error {
-	return l.k.Merge(in.k)
+	if err := l.k.Merge(in.k); err != nil {
+		return err
+	}
+	l.mergePositions(in.positions)
+	l.mergeSources(in.sources)
+	return nil
 }
 
 // Sub returns new Conf instance representing a sub-config of this instance.
@@ -103,7 +114,23 @@ func (l *Conf) Sub(key string) (*Conf, error) {
 	}
 
 	if v, ok := data.(map[string]interface{}); ok {
-		return NewFromStringMap(v), nil
+		sub := NewFromStringMap(v)
+		prefix := key + KeyDelimiter
+		positions := make(map[string]Position)
+		for k, pos := range l.positions {
+			if strings.HasPrefix(k, prefix) {
+				positions[k[len(prefix):]] = pos
+			}
+		}
+		sub.mergePositions(positions)
+		sources := make(map[string]Source)
+		for k, src := range l.sources {
+			if strings.HasPrefix(k, prefix) {
+				sources[k[len(prefix):]] = src
+			}
+		}
+		sub.mergeSources(sources)
+		return sub, nil
 	}
 
 	return nil, fmt.Errorf("unexpected sub-config value kind for key:%s value:%v kind:%v)", key, data, reflect.TypeOf(data).Kind())
@@ -129,12 +156,28 @@ func decoderConfig(result interface{}) *mapstructure.DecoderConfig {
 			expandNilStructPointersHookFunc(),
 			mapstructure.StringToSliceHookFunc(","),
 			mapKeyStringToMapKeyTextUnmarshalerHookFunc(),
-			mapstructure.StringToTimeDurationHookFunc(),
+			stringToTimeDurationHookFunc(),
 			mapstructure.TextUnmarshallerHookFunc(),
 		),
 	}
 }
 
+// stringToTimeDurationHookFunc returns a DecodeHookFuncType that converts strings such as "90s" or "2h30m"
+// to time.Duration. Types implementing encoding.TextUnmarshaler, such as byte sizes accepting "512MiB",
+// are decoded by mapstructure.TextUnmarshallerHookFunc.
+func stringToTimeDurationHookFunc() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeOf(time.Duration(0)) {
+			return data, nil
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(data.(string)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q, expected a number with a unit among ns, us, ms, s, m, h such as \"90s\" or \"2h30m\"", data)
+		}
+		return d, nil
+	}
+}
+
 // In cases where a config has a mapping of something to a struct pointers
 // we want nil values to resolve to a pointer to the zero value of the
 // underlying struct just as we want nil values of a mapping of something
@@ -20,10 +20,13 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
+
+	"go.opentelemetry.io/collector/config/configbytes"
 )
 
 func TestToStringMapFlatten(t *testing.T) {
@@ -218,6 +221,60 @@ func TestMapKeyStringToMapKeyTextUnmarshalerHookFuncErrorUnmarshal(t *testing.T)
 	assert.Error(t, conf.UnmarshalExact(cfg))
 }
 
+type sizeAndDurationConfig struct {
+	Size     configbytes.ByteSize `mapstructure:"size"`
+	Timeout  time.Duration        `mapstructure:"timeout"`
+	Interval time.Duration        `mapstructure:"interval"`
+}
+
+func TestUnmarshalSizeAndDuration(t *testing.T) {
+	conf := NewFromStringMap(map[string]interface{}{
+		"size":     "512MiB",
+		"timeout":  "90s",
+		"interval": "2h30m",
+	})
+	cfg := &sizeAndDurationConfig{}
+	require.NoError(t, conf.UnmarshalExact(cfg))
+	assert.Equal(t, sizeAndDurationConfig{
+		Size:     512 * configbytes.Mebibyte,
+		Timeout:  90 * time.Second,
+		Interval: 2*time.Hour + 30*time.Minute,
+	}, *cfg)
+
+	conf = NewFromStringMap(map[string]interface{}{"size": 1024})
+	require.NoError(t, conf.UnmarshalExact(cfg))
+	assert.Equal(t, configbytes.ByteSize(1024), cfg.Size)
+}
+
+func TestUnmarshalSizeAndDurationErrors(t *testing.T) {
+	conf := NewFromStringMap(map[string]interface{}{"timeout": "90 seconds"})
+	err := conf.UnmarshalExact(&sizeAndDurationConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid duration "90 seconds", expected a number with a unit`)
+
+	conf = NewFromStringMap(map[string]interface{}{"size": "1.5 gigs"})
+	err = conf.UnmarshalExact(&sizeAndDurationConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid byte size "1.5 gigs"`)
+}
+
+func TestKeyCasePreserved(t *testing.T) {
+	conf := NewFromStringMap(map[string]interface{}{
+		"headers": map[string]interface{}{"X-Tenant": "a"},
+	})
+	require.NoError(t, conf.Merge(NewFromStringMap(map[string]interface{}{
+		"headers": map[string]interface{}{"x-tenant": "b"},
+	})))
+	assert.Equal(t, "a", conf.Get("headers::X-Tenant"))
+	assert.Equal(t, "b", conf.Get("headers::x-tenant"))
+
+	cfg := &struct {
+		Headers map[string]string `mapstructure:"headers"`
+	}{}
+	require.NoError(t, conf.UnmarshalExact(cfg))
+	assert.Equal(t, map[string]string{"X-Tenant": "a", "x-tenant": "b"}, cfg.Headers)
+}
+
 // newConfFromFile creates a new Conf by reading the given file.
 func newConfFromFile(t testing.TB, fileName string) map[string]interface{} {
 	content, err := os.ReadFile(filepath.Clean(fileName))
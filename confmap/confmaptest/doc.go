@@ -12,5 +12,6 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package confmaptest helps loading confmap.Conf to test packages implementing using the configuration.
+// Package confmaptest helps loading confmap.Conf to test packages implementing using the configuration,
+// and provides conformance tests for confmap.Provider implementations.
 package confmaptest // import "go.opentelemetry.io/collector/confmap/confmaptest"
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmaptest // import "go.opentelemetry.io/collector/confmap/confmaptest"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const defaultConformanceTimeout = 5 * time.Second
+
+// ProviderConformanceSettings configures the conformance tests run by RunProviderConformance.
+type ProviderConformanceSettings struct {
+	// NewProvider creates the confmap.Provider under test. A new Provider is created for every test.
+	NewProvider func() confmap.Provider
+
+	// ValidURI references a valid configuration that the Provider retrieves successfully.
+	ValidURI string
+
+	// InvalidPayloadURI references a payload that is not a valid configuration, e.g. malformed YAML,
+	// that Retrieve must report as an error. The test is skipped if empty.
+	InvalidPayloadURI string
+
+	// TriggerChange changes the configuration referenced by ValidURI. If set, and the Provider supports
+	// watching, the test checks that the watcher passed to Retrieve is called after the change.
+	TriggerChange func(t *testing.T)
+
+	// Timeout bounds the calls expected to return promptly and the wait for the watcher to be called.
+	// Defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// RunProviderConformance runs, as sub-tests of t, the conformance tests checking that the Provider
+// follows the confmap.Provider contract:
+//   - Its scheme follows the restrictions checked by ValidateProviderScheme.
+//   - It retrieves ValidURI as a confmap.Conf, and rejects the URIs with another scheme and InvalidPayloadURI.
+//   - Retrieve and Shutdown return promptly if the context is cancelled, and remote Providers, as reported
+//     by their capabilities, return an error from Retrieve in that case.
+//   - The watcher is called once the configuration changes, see TriggerChange.
+//   - Shutdown can be called several times.
+func RunProviderConformance(t *testing.T, set ProviderConformanceSettings) {
+	require.NotNil(t, set.NewProvider, "NewProvider is required")
+	require.NotEmpty(t, set.ValidURI, "ValidURI is required")
+	timeout := set.Timeout
+	if timeout == 0 {
+		timeout = defaultConformanceTimeout
+	}
+
+	newProvider := func(t *testing.T) confmap.Provider {
+		p := set.NewProvider()
+		require.NotNil(t, p)
+		return p
+	}
+
+	t.Run("scheme", func(t *testing.T) {
+		assert.NoError(t, ValidateProviderScheme(newProvider(t)))
+	})
+
+	t.Run("retrieve", func(t *testing.T) {
+		p := newProvider(t)
+		ret, err := p.Retrieve(context.Background(), set.ValidURI, nil)
+		require.NoError(t, err)
+		_, err = ret.AsConf()
+		assert.NoError(t, err)
+		assert.NoError(t, ret.Close(context.Background()))
+		assert.NoError(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("unsupported_scheme", func(t *testing.T) {
+		p := newProvider(t)
+		_, err := p.Retrieve(context.Background(), "unsupported"+p.Scheme()+":"+set.ValidURI, nil)
+		assert.Error(t, err)
+		assert.NoError(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("invalid_payload", func(t *testing.T) {
+		if set.InvalidPayloadURI == "" {
+			t.Skip("InvalidPayloadURI is not set")
+		}
+		p := newProvider(t)
+		_, err := p.Retrieve(context.Background(), set.InvalidPayloadURI, nil)
+		assert.Error(t, err)
+		assert.NoError(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("cancelled_context", func(t *testing.T) {
+		p := newProvider(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var err error
+		returnedPromptly(t, timeout, "Retrieve", func() {
+			var ret *confmap.Retrieved
+			if ret, err = p.Retrieve(ctx, set.ValidURI, nil); err == nil {
+				assert.NoError(t, ret.Close(context.Background()))
+			}
+		})
+		if confmap.GetProviderCapabilities(p).IsRemote {
+			assert.Error(t, err, "remote providers must fail to retrieve with a cancelled context")
+		}
+		returnedPromptly(t, timeout, "Shutdown", func() {
+			_ = p.Shutdown(ctx)
+		})
+	})
+
+	t.Run("watcher", func(t *testing.T) {
+		p := newProvider(t)
+		if !confmap.GetProviderCapabilities(p).SupportsWatch {
+			t.Skip("the provider does not support watching")
+		}
+		if set.TriggerChange == nil {
+			t.Skip("TriggerChange is not set")
+		}
+
+		events := make(chan *confmap.ChangeEvent, 1)
+		ret, err := p.Retrieve(context.Background(), set.ValidURI, func(event *confmap.ChangeEvent) {
+			select {
+			case events <- event:
+			default:
+			}
+		})
+		require.NoError(t, err)
+
+		set.TriggerChange(t)
+		select {
+		case event := <-events:
+			assert.NoError(t, event.Error)
+		case <-time.After(timeout):
+			t.Errorf("the watcher was not called within %v after the change", timeout)
+		}
+
+		returnedPromptly(t, timeout, "Retrieved.Close", func() {
+			assert.NoError(t, ret.Close(context.Background()))
+		})
+		assert.NoError(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("shutdown_idempotent", func(t *testing.T) {
+		p := newProvider(t)
+		ret, err := p.Retrieve(context.Background(), set.ValidURI, nil)
+		require.NoError(t, err)
+		assert.NoError(t, ret.Close(context.Background()))
+		assert.NoError(t, p.Shutdown(context.Background()))
+		assert.NoError(t, p.Shutdown(context.Background()))
+	})
+}
+
+// returnedPromptly fails the test if fn does not return within the timeout.
+func returnedPromptly(t *testing.T, timeout time.Duration, name string, fn func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		t.Errorf("%s did not return within %v", name, timeout)
+	}
+}
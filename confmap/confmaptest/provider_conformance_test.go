@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmaptest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// watchingProvider is a remote provider notifying the watcher of the last retrieved value on change.
+type watchingProvider struct {
+	mu      sync.Mutex
+	watcher confmap.WatcherFunc
+}
+
+func (wp *watchingProvider) Retrieve(ctx context.Context, uri string, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(uri, "watch:") {
+		return nil, errors.New("unsupported scheme")
+	}
+	if uri == "watch:invalid" {
+		return nil, errors.New("invalid payload")
+	}
+	wp.mu.Lock()
+	wp.watcher = watcher
+	wp.mu.Unlock()
+	return confmap.NewRetrieved(map[string]interface{}{"key": "value"}, confmap.WithRetrievedClose(func(context.Context) error {
+		wp.mu.Lock()
+		defer wp.mu.Unlock()
+		wp.watcher = nil
+		return nil
+	}))
+}
+
+func (wp *watchingProvider) change() {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	if wp.watcher != nil {
+		wp.watcher(&confmap.ChangeEvent{})
+	}
+}
+
+func (*watchingProvider) Scheme() string {
+	return "watch"
+}
+
+func (*watchingProvider) Capabilities() confmap.ProviderCapabilities {
+	return confmap.ProviderCapabilities{SupportsWatch: true, IsRemote: true}
+}
+
+func (*watchingProvider) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestRunProviderConformance(t *testing.T) {
+	var current *watchingProvider
+	RunProviderConformance(t, ProviderConformanceSettings{
+		NewProvider: func() confmap.Provider {
+			current = &watchingProvider{}
+			return current
+		},
+		ValidURI:          "watch:valid",
+		InvalidPayloadURI: "watch:invalid",
+		TriggerChange: func(t *testing.T) {
+			current.change()
+		},
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoverlayconverter // import "go.opentelemetry.io/collector/confmap/converter/envoverlayconverter"
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// keySeparator separates the keys of the configuration in the names of the environment variables.
+const keySeparator = "__"
+
+type converter struct {
+	prefix string
+}
+
+// New returns a confmap.Converter that merges over the configuration the values of the environment
+// variables whose names start with the given prefix, so that containerized deployments can adjust
+// a configuration, e.g. retrieved from a remote source, without editing it.
+//
+// The rest of the name of the variables is the key of the value, with "__" as key delimiter.
+// Only the names with at least one delimiter are used, since top level keys are sections. The
+// values are set as strings, converted to the type of the config fields when unmarshaled, as for
+// the --set flags. For example, with the "OTELCOL_" prefix:
+//
+//	OTELCOL_exporters__otlp__endpoint=backend:4317
+//	OTELCOL_processors__batch__timeout=2s
+//
+// Nothing is converted if the prefix is empty.
+//
+// Notice: This API is experimental.
+func New(prefix string) confmap.Converter {
+	return &converter{prefix: prefix}
+}
+
+func (c *converter) Convert(_ context.Context, conf *confmap.Conf) error {
+	if c.prefix == "" {
+		return nil
+	}
+
+	overlay := make(map[string]interface{})
+	for _, env := range os.Environ() {
+		name, value, found := strings.Cut(env, "=")
+		if !found || !strings.HasPrefix(name, c.prefix) {
+			continue
+		}
+		key := strings.TrimPrefix(name, c.prefix)
+		if !strings.Contains(key, keySeparator) {
+			continue
+		}
+		for _, part := range strings.Split(key, keySeparator) {
+			if part == "" {
+				return fmt.Errorf("invalid environment variable %q: empty key in %q", name, key)
+			}
+		}
+		overlay[key] = value
+	}
+	if len(overlay) == 0 {
+		return nil
+	}
+	if err := checkConflicts(c.prefix, overlay); err != nil {
+		return err
+	}
+
+	return conf.Merge(confmap.NewFromStringMap(maps.Unflatten(overlay, keySeparator)))
+}
+
+// checkConflicts returns an error if a key of the overlay is both a value and a map of other keys.
+func checkConflicts(prefix string, overlay map[string]interface{}) error {
+	keys := make([]string, 0, len(overlay))
+	for key := range overlay {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, other := range keys {
+			if strings.HasPrefix(other, key+keySeparator) {
+				return fmt.Errorf("conflicting environment variables %q and %q", prefix+key, prefix+other)
+			}
+		}
+	}
+	return nil
+}
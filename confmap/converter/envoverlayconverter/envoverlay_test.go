@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoverlayconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestEnvOverlayConverter(t *testing.T) {
+	t.Setenv("TESTOVERLAY_exporters__otlp__endpoint", "backend:4317")
+	t.Setenv("TESTOVERLAY_processors__batch__send_batch_size", "1024")
+	t.Setenv("TESTOVERLAY_ignored", "top level keys are sections")
+	t.Setenv("OTHER_exporters__otlp__endpoint", "other:4317")
+
+	conf := confmap.NewFromStringMap(map[string]interface{}{
+		"exporters": map[string]interface{}{
+			"otlp": map[string]interface{}{
+				"endpoint":    "localhost:4317",
+				"compression": "gzip",
+			},
+		},
+	})
+	require.NoError(t, New("TESTOVERLAY_").Convert(context.Background(), conf))
+	assert.Equal(t, map[string]interface{}{
+		"exporters": map[string]interface{}{
+			"otlp": map[string]interface{}{
+				"endpoint":    "backend:4317",
+				"compression": "gzip",
+			},
+		},
+		"processors": map[string]interface{}{
+			"batch": map[string]interface{}{"send_batch_size": "1024"},
+		},
+	}, conf.ToStringMap())
+}
+
+func TestEnvOverlayConverterEmptyPrefix(t *testing.T) {
+	t.Setenv("exporters__otlp__endpoint", "backend:4317")
+	conf := confmap.NewFromStringMap(map[string]interface{}{"foo": "bar"})
+	require.NoError(t, New("").Convert(context.Background(), conf))
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, conf.ToStringMap())
+}
+
+func TestEnvOverlayConverterNoVariables(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]interface{}{"foo": "bar"})
+	require.NoError(t, New("TESTOVERLAYNONE_").Convert(context.Background(), conf))
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, conf.ToStringMap())
+}
+
+func TestEnvOverlayConverterErrors(t *testing.T) {
+	t.Setenv("TESTOVERLAYEMPTY_exporters____endpoint", "backend:4317")
+	assert.EqualError(t, New("TESTOVERLAYEMPTY_").Convert(context.Background(), confmap.New()),
+		`invalid environment variable "TESTOVERLAYEMPTY_exporters____endpoint": empty key in "exporters____endpoint"`)
+
+	t.Setenv("TESTOVERLAYCONFLICT_exporters__otlp", "value")
+	t.Setenv("TESTOVERLAYCONFLICT_exporters__otlp__endpoint", "backend:4317")
+	assert.EqualError(t, New("TESTOVERLAYCONFLICT_").Convert(context.Background(), confmap.New()),
+		`conflicting environment variables "TESTOVERLAYCONFLICT_exporters__otlp" and "TESTOVERLAYCONFLICT_exporters__otlp__endpoint"`)
+}
@@ -0,0 +1,246 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package legacyconverter // import "go.opentelemetry.io/collector/confmap/converter/legacyconverter"
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// legacyTLSClientKeys are the TLS client settings set at the root of the gRPC and HTTP client
+// settings before v0.36.0, since moved under "tls".
+var legacyTLSClientKeys = []string{
+	"ca_file",
+	"cert_file",
+	"key_file",
+	"min_version",
+	"max_version",
+	"insecure",
+	"insecure_skip_verify",
+	"server_name_override",
+}
+
+// legacyCORSKeys are the CORS settings of the HTTP servers before v0.41.0, by their current name
+// under "cors".
+var legacyCORSKeys = map[string]string{
+	"cors_allowed_origins": "allowed_origins",
+	"cors_allowed_headers": "allowed_headers",
+}
+
+// tlsClientExporterTypes are the types of the exporters embedding the gRPC or HTTP client settings.
+var tlsClientExporterTypes = map[string]bool{
+	"otlp":     true,
+	"otlphttp": true,
+}
+
+type converter struct {
+	warn func(msg string)
+}
+
+// New returns a confmap.Converter that rewrites the configuration layouts of older releases into the
+// current ones, so that long-lived configuration files keep working after an upgrade:
+//   - "tls_settings" of the receivers is renamed to "tls" (v0.36.0);
+//   - the TLS client settings at the root of the "otlp" and "otlphttp" exporters, e.g. "insecure" or
+//     "ca_file", are moved under "tls" (v0.36.0);
+//   - "cors_allowed_origins" and "cors_allowed_headers" of the receivers are moved under "cors" (v0.41.0);
+//   - "ballast_size_mib" of the "memory_limiter" processors is removed, the "memory_ballast" extension
+//     has to be used instead (v0.39.0);
+//   - the "queued_retry" processors are removed, including from the pipelines, the "sending_queue" and
+//     "retry_on_failure" exporter settings have to be used instead.
+//
+// Every rewrite is reported, as a message describing it, to warn that can be nil. Legacy settings also
+// set in the current layout are removed, the current ones take precedence.
+//
+// Notice: This API is experimental.
+func New(warn func(msg string)) confmap.Converter {
+	return &converter{warn: warn}
+}
+
+func (c *converter) Convert(_ context.Context, conf *confmap.Conf) error {
+	cfg := conf.ToStringMap()
+	changed := false
+	report := func(format string, args ...interface{}) {
+		changed = true
+		if c.warn != nil {
+			c.warn(fmt.Sprintf(format, args...))
+		}
+	}
+
+	forEachComponent(cfg, "receivers", func(path string, _ string, receiver map[string]interface{}) {
+		walkMaps(path, receiver, func(path string, m map[string]interface{}) {
+			renameTLSSettings(path, m, report)
+			moveCORS(path, m, report)
+		})
+	})
+	forEachComponent(cfg, "exporters", func(path string, typ string, exporter map[string]interface{}) {
+		if tlsClientExporterTypes[typ] {
+			moveTLSClientSettings(path, exporter, report)
+		}
+	})
+	forEachComponent(cfg, "processors", func(path string, typ string, processor map[string]interface{}) {
+		if _, ok := processor["ballast_size_mib"]; ok && typ == "memory_limiter" {
+			delete(processor, "ballast_size_mib")
+			report("%s is removed, use the memory_ballast extension instead", joinKey(path, "ballast_size_mib"))
+		}
+	})
+	removeQueuedRetry(cfg, report)
+
+	if !changed {
+		return nil
+	}
+	*conf = *confmap.NewFromStringMap(cfg)
+	return nil
+}
+
+// renameTLSSettings renames the "tls_settings" key of m to "tls".
+func renameTLSSettings(path string, m map[string]interface{}, report func(string, ...interface{})) {
+	legacy, ok := m["tls_settings"]
+	if !ok {
+		return
+	}
+	delete(m, "tls_settings")
+	if _, ok = m["tls"]; ok {
+		report("%s is ignored since %s is set", joinKey(path, "tls_settings"), joinKey(path, "tls"))
+		return
+	}
+	m["tls"] = legacy
+	report("%s is renamed to %s", joinKey(path, "tls_settings"), joinKey(path, "tls"))
+}
+
+// moveCORS moves the "cors_allowed_*" keys of m under "cors".
+func moveCORS(path string, m map[string]interface{}, report func(string, ...interface{})) {
+	for _, legacyKey := range sortedKeys(legacyCORSKeys) {
+		moveUnder(path, m, legacyKey, "cors", legacyCORSKeys[legacyKey], report)
+	}
+}
+
+// moveTLSClientSettings moves the TLS client settings at the root of m under "tls".
+func moveTLSClientSettings(path string, m map[string]interface{}, report func(string, ...interface{})) {
+	for _, key := range legacyTLSClientKeys {
+		moveUnder(path, m, key, "tls", key, report)
+	}
+}
+
+// moveUnder moves the legacyKey of m to the key of its parent sub-map, created if needed.
+func moveUnder(path string, m map[string]interface{}, legacyKey, parent, key string, report func(string, ...interface{})) {
+	val, ok := m[legacyKey]
+	if !ok {
+		return
+	}
+	delete(m, legacyKey)
+	sub, ok := m[parent].(map[string]interface{})
+	if !ok {
+		if m[parent] != nil {
+			report("%s is ignored since %s is set", joinKey(path, legacyKey), joinKey(path, parent))
+			return
+		}
+		sub = make(map[string]interface{})
+		m[parent] = sub
+	}
+	if _, ok = sub[key]; ok {
+		report("%s is ignored since %s is set", joinKey(path, legacyKey), joinKey(path, parent, key))
+		return
+	}
+	sub[key] = val
+	report("%s is moved to %s", joinKey(path, legacyKey), joinKey(path, parent, key))
+}
+
+// removeQueuedRetry removes the "queued_retry" processors, and their references in the pipelines.
+func removeQueuedRetry(cfg map[string]interface{}, report func(string, ...interface{})) {
+	processors, _ := cfg["processors"].(map[string]interface{})
+	removed := make(map[string]bool)
+	for _, id := range sortedKeys(processors) {
+		if componentType(id) == "queued_retry" {
+			delete(processors, id)
+			removed[id] = true
+			report("%s is removed, use the sending_queue and retry_on_failure exporter settings instead", joinKey("processors", id))
+		}
+	}
+	if len(removed) == 0 {
+		return
+	}
+
+	service, _ := cfg["service"].(map[string]interface{})
+	pipelines, _ := service["pipelines"].(map[string]interface{})
+	for _, name := range sortedKeys(pipelines) {
+		pipeline, _ := pipelines[name].(map[string]interface{})
+		ids, ok := pipeline["processors"].([]interface{})
+		if !ok {
+			continue
+		}
+		kept := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			if s, isString := id.(string); isString && removed[s] {
+				report("%s is removed from %s", s, joinKey("service", "pipelines", name, "processors"))
+				continue
+			}
+			kept = append(kept, id)
+		}
+		pipeline["processors"] = kept
+	}
+}
+
+// forEachComponent calls f with every component configuration of the given section, with its key
+// and type, in the order of the component IDs.
+func forEachComponent(cfg map[string]interface{}, section string, f func(path string, typ string, m map[string]interface{})) {
+	components, _ := cfg[section].(map[string]interface{})
+	for _, id := range sortedKeys(components) {
+		if m, ok := components[id].(map[string]interface{}); ok {
+			f(joinKey(section, id), componentType(id), m)
+		}
+	}
+}
+
+// walkMaps calls f with m and all its nested maps, outside of lists, with their keys.
+func walkMaps(path string, m map[string]interface{}, f func(path string, m map[string]interface{})) {
+	f(path, m)
+	for _, k := range sortedKeys(m) {
+		if sub, ok := m[k].(map[string]interface{}); ok {
+			walkMaps(joinKey(path, k), sub, f)
+		}
+	}
+}
+
+// componentType returns the type of the given component ID, formatted as "type[/name]".
+func componentType(id string) string {
+	if idx := strings.IndexByte(id, '/'); idx != -1 {
+		return id[:idx]
+	}
+	return id
+}
+
+func joinKey(parts ...string) string {
+	return strings.Join(parts, confmap.KeyDelimiter)
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch v := m.(type) {
+	case map[string]interface{}:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]string:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package legacyconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLegacyConverter(t *testing.T) {
+	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "legacy.yaml"))
+	require.NoError(t, err)
+	var warnings []string
+	require.NoError(t, New(func(msg string) { warnings = append(warnings, msg) }).Convert(context.Background(), conf))
+
+	expected, err := confmaptest.LoadConf(filepath.Join("testdata", "expected.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, expected.ToStringMap(), conf.ToStringMap())
+	assert.Equal(t, []string{
+		"receivers::otlp::protocols::grpc::tls_settings is renamed to receivers::otlp::protocols::grpc::tls",
+		"receivers::otlp::protocols::http::cors_allowed_headers is moved to receivers::otlp::protocols::http::cors::allowed_headers",
+		"receivers::otlp::protocols::http::cors_allowed_origins is moved to receivers::otlp::protocols::http::cors::allowed_origins",
+		"exporters::otlp::insecure is moved to exporters::otlp::tls::insecure",
+		"exporters::otlphttp::ca_file is ignored since exporters::otlphttp::tls::ca_file is set",
+		"processors::memory_limiter::ballast_size_mib is removed, use the memory_ballast extension instead",
+		"processors::queued_retry is removed, use the sending_queue and retry_on_failure exporter settings instead",
+		"queued_retry is removed from service::pipelines::traces::processors",
+	}, warnings)
+}
+
+func TestLegacyConverterCurrentLayout(t *testing.T) {
+	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "expected.yaml"))
+	require.NoError(t, err)
+	expected := conf.ToStringMap()
+
+	warned := false
+	require.NoError(t, New(func(string) { warned = true }).Convert(context.Background(), conf))
+	assert.False(t, warned)
+	assert.Equal(t, expected, conf.ToStringMap())
+}
+
+func TestLegacyConverterNilWarn(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]interface{}{
+		"exporters": map[string]interface{}{
+			"otlp/backend": map[string]interface{}{"insecure": true},
+			// Only the exporters known to embed the client settings are rewritten.
+			"custom": map[string]interface{}{"insecure": true},
+		},
+		"receivers": map[string]interface{}{
+			"otlp": map[string]interface{}{"tls_settings": nil, "tls": map[string]interface{}{"ca_file": "ca.pem"}},
+		},
+	})
+	require.NoError(t, New(nil).Convert(context.Background(), conf))
+	assert.Equal(t, map[string]interface{}{
+		"exporters": map[string]interface{}{
+			"otlp/backend": map[string]interface{}{"tls": map[string]interface{}{"insecure": true}},
+			"custom":       map[string]interface{}{"insecure": true},
+		},
+		"receivers": map[string]interface{}{
+			"otlp": map[string]interface{}{"tls": map[string]interface{}{"ca_file": "ca.pem"}},
+		},
+	}, conf.ToStringMap())
+}
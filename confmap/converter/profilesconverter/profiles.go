@@ -0,0 +1,100 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profilesconverter // import "go.opentelemetry.io/collector/confmap/converter/profilesconverter"
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// profilesKey is the top level key holding the named configuration profiles.
+const profilesKey = "profiles"
+
+type converter struct {
+	profile string
+}
+
+// New returns a confmap.Converter that activates the given named configuration profile.
+//
+// Profiles are defined under the top level "profiles" key, each of them holding configuration
+// sections that are merged over the rest of the configuration, the shared base, when the profile
+// is activated. Maps are merged while the other values, including lists, are replaced. The
+// "profiles" key is removed from the configuration in all cases, and no profile is activated if
+// the given profile is empty.
+//
+//	receivers:
+//	  otlp:
+//	profiles:
+//	  agent:
+//	    exporters:
+//	      otlp:
+//	        endpoint: gateway:4317
+//	  gateway:
+//	    exporters:
+//	      otlp:
+//	        endpoint: backend:4317
+//
+// Notice: This API is experimental.
+func New(profile string) confmap.Converter {
+	return &converter{profile: profile}
+}
+
+func (c *converter) Convert(_ context.Context, conf *confmap.Conf) error {
+	base := conf.ToStringMap()
+	rawProfiles, found := base[profilesKey]
+	if !found {
+		if c.profile != "" {
+			return fmt.Errorf("profile %q is not defined, the configuration has no %q", c.profile, profilesKey)
+		}
+		return nil
+	}
+	delete(base, profilesKey)
+
+	profiles, ok := rawProfiles.(map[string]interface{})
+	if !ok && rawProfiles != nil {
+		return fmt.Errorf("%q must be a map of named profiles", profilesKey)
+	}
+
+	merged := confmap.NewFromStringMap(base)
+	if c.profile != "" {
+		rawProfile, found := profiles[c.profile]
+		if !found {
+			return fmt.Errorf("profile %q is not defined, available profiles: [%s]", c.profile, strings.Join(profileNames(profiles), ", "))
+		}
+		profile, ok := rawProfile.(map[string]interface{})
+		if !ok && rawProfile != nil {
+			return fmt.Errorf("profile %q must be a map of configuration sections", c.profile)
+		}
+		if err := merged.Merge(confmap.NewFromStringMap(profile)); err != nil {
+			return err
+		}
+	}
+
+	*conf = *merged
+	return nil
+}
+
+func profileNames(profiles map[string]interface{}) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
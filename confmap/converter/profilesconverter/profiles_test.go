@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profilesconverter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestProfilesConverter(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  string
+		expected map[string]interface{}
+	}{
+		{
+			name:    "no_profile",
+			profile: "",
+			expected: map[string]interface{}{
+				"exporters::otlp::endpoint":    "backend:4317",
+				"exporters::otlp::compression": "gzip",
+			},
+		},
+		{
+			name:    "agent",
+			profile: "agent",
+			expected: map[string]interface{}{
+				"exporters::otlp::endpoint":    "gateway:4317",
+				"exporters::otlp::compression": "gzip",
+			},
+		},
+		{
+			name:    "gateway",
+			profile: "gateway",
+			expected: map[string]interface{}{
+				"exporters::otlp::endpoint":              "backend:4317",
+				"exporters::otlp::compression":           "gzip",
+				"service::pipelines::traces::receivers":  []interface{}{"otlp"},
+				"service::pipelines::traces::processors": []interface{}{"batch"},
+				"service::pipelines::traces::exporters":  []interface{}{"otlp"},
+			},
+		},
+		{
+			name:    "empty",
+			profile: "empty",
+			expected: map[string]interface{}{
+				"exporters::otlp::endpoint": "backend:4317",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf, err := confmaptest.LoadConf(filepath.Join("testdata", "profiles.yaml"))
+			require.NoError(t, err)
+			require.NoError(t, New(tt.profile).Convert(context.Background(), conf))
+
+			assert.False(t, conf.IsSet(profilesKey))
+			for key, value := range tt.expected {
+				assert.Equal(t, value, conf.Get(key), key)
+			}
+		})
+	}
+}
+
+func TestProfilesConverterNoProfiles(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]interface{}{"foo": "bar"})
+	require.NoError(t, New("").Convert(context.Background(), conf))
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, conf.ToStringMap())
+
+	assert.EqualError(t, New("agent").Convert(context.Background(), conf),
+		`profile "agent" is not defined, the configuration has no "profiles"`)
+}
+
+func TestProfilesConverterUnknownProfile(t *testing.T) {
+	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "profiles.yaml"))
+	require.NoError(t, err)
+	assert.EqualError(t, New("debug").Convert(context.Background(), conf),
+		`profile "debug" is not defined, available profiles: [agent, empty, gateway]`)
+}
+
+func TestProfilesConverterInvalid(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]interface{}{"profiles": "agent"})
+	assert.EqualError(t, New("").Convert(context.Background(), conf), `"profiles" must be a map of named profiles`)
+
+	conf = confmap.NewFromStringMap(map[string]interface{}{"profiles": map[string]interface{}{"agent": "foo"}})
+	assert.EqualError(t, New("agent").Convert(context.Background(), conf), `profile "agent" must be a map of configuration sections`)
+}
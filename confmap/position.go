@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmap // import "go.opentelemetry.io/collector/confmap"
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Position is the location in a configuration source where a key is defined.
+type Position struct {
+	// URI of the configuration source, as passed to Provider.Retrieve.
+	URI string
+	// Line is the 1-based line of the key in the configuration source.
+	Line int
+	// Column is the 1-based column of the key in the configuration source.
+	Column int
+}
+
+// String returns the position formatted as "uri=<uri> line <line> column <column>".
+func (p Position) String() string {
+	return fmt.Sprintf("uri=%s line %d column %d", p.URI, p.Line, p.Column)
+}
+
+// PositionError is an error about a configuration key, annotated with the position
+// in the configuration source where the key, or its closest parent, is defined.
+type PositionError struct {
+	// Key is the configuration key the error refers to, with KeyDelimiter separators.
+	Key string
+	// Position is where Key, or its closest parent with a known position, is defined.
+	Position Position
+	// Err is the original error.
+	Err error
+}
+
+func (e *PositionError) Error() string {
+	return fmt.Sprintf("%v, key %s: %v", e.Position, e.Key, e.Err)
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// Position returns the position in the configuration source where the key is defined, if known.
+// Positions are known for the keys of the configurations retrieved with WithRetrievedPositions.
+func (l *Conf) Position(key string) (Position, bool) {
+	pos, ok := l.positions[key]
+	return pos, ok
+}
+
+// WithPosition returns err as a *PositionError referring to the key, if the position of the key
+// or of one of its parents is known, otherwise it returns err unchanged.
+func (l *Conf) WithPosition(key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	for k := key; k != ""; {
+		if pos, ok := l.positions[k]; ok {
+			return &PositionError{Key: key, Position: pos, Err: err}
+		}
+		idx := strings.LastIndex(k, KeyDelimiter)
+		if idx == -1 {
+			break
+		}
+		k = k[:idx]
+	}
+	return err
+}
+
+// mergePositions adds the given positions to the Conf, overriding the ones of the same keys.
+func (l *Conf) mergePositions(positions map[string]Position) {
+	if len(positions) == 0 {
+		return
+	}
+	if l.positions == nil {
+		l.positions = make(map[string]Position, len(positions))
+	}
+	for k, pos := range positions {
+		l.positions[k] = pos
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionString(t *testing.T) {
+	pos := Position{URI: "file:/etc/otel/config.yaml", Line: 42, Column: 5}
+	assert.Equal(t, "uri=file:/etc/otel/config.yaml line 42 column 5", pos.String())
+}
+
+func TestConfWithPosition(t *testing.T) {
+	ret, err := NewRetrieved(map[string]interface{}{
+		"exporters": map[string]interface{}{
+			"otlp": map[string]interface{}{"endpoint": "localhost:4317"},
+		},
+	}, WithRetrievedPositions(map[string]Position{
+		"exporters":       {URI: "file:config.yaml", Line: 1, Column: 1},
+		"exporters::otlp": {URI: "file:config.yaml", Line: 2, Column: 3},
+	}))
+	require.NoError(t, err)
+	conf, err := ret.AsConf()
+	require.NoError(t, err)
+
+	pos, ok := conf.Position("exporters::otlp")
+	assert.True(t, ok)
+	assert.Equal(t, Position{URI: "file:config.yaml", Line: 2, Column: 3}, pos)
+	_, ok = conf.Position("exporters::otlp::endpoint")
+	assert.False(t, ok)
+
+	errInvalid := errors.New("invalid endpoint")
+	err = conf.WithPosition("exporters::otlp::endpoint", errInvalid)
+	assert.EqualError(t, err, "uri=file:config.yaml line 2 column 3, key exporters::otlp::endpoint: invalid endpoint")
+	assert.ErrorIs(t, err, errInvalid)
+	var posErr *PositionError
+	require.True(t, errors.As(err, &posErr))
+	assert.Equal(t, "exporters::otlp::endpoint", posErr.Key)
+
+	assert.Equal(t, errInvalid, conf.WithPosition("receivers::otlp", errInvalid))
+	assert.NoError(t, conf.WithPosition("exporters", nil))
+	assert.Equal(t, errInvalid, New().WithPosition("exporters", errInvalid))
+}
+
+func TestConfMergeAndSubPositions(t *testing.T) {
+	base := NewFromStringMap(map[string]interface{}{"exporters::otlp::endpoint": "localhost:4317"})
+	base.mergePositions(map[string]Position{
+		"exporters":                 {URI: "file:base.yaml", Line: 1, Column: 1},
+		"exporters::otlp":           {URI: "file:base.yaml", Line: 2, Column: 3},
+		"exporters::otlp::endpoint": {URI: "file:base.yaml", Line: 3, Column: 5},
+	})
+	override := NewFromStringMap(map[string]interface{}{"exporters::otlp::endpoint": "remote:4317"})
+	override.mergePositions(map[string]Position{
+		"exporters::otlp::endpoint": {URI: "file:override.yaml", Line: 7, Column: 5},
+	})
+	require.NoError(t, base.Merge(override))
+
+	pos, ok := base.Position("exporters::otlp::endpoint")
+	assert.True(t, ok)
+	assert.Equal(t, Position{URI: "file:override.yaml", Line: 7, Column: 5}, pos)
+	pos, ok = base.Position("exporters::otlp")
+	assert.True(t, ok)
+	assert.Equal(t, Position{URI: "file:base.yaml", Line: 2, Column: 3}, pos)
+
+	sub, err := base.Sub("exporters")
+	require.NoError(t, err)
+	pos, ok = sub.Position("otlp::endpoint")
+	assert.True(t, ok)
+	assert.Equal(t, Position{URI: "file:override.yaml", Line: 7, Column: 5}, pos)
+	_, ok = sub.Position("exporters")
+	assert.False(t, ok)
+}
@@ -47,7 +47,8 @@ type Provider interface {
 	//     See https://datatracker.ietf.org/doc/html/rfc3986#section-3.1.
 	//   - MUST be at least 2 characters long to avoid conflicting with a driver-letter identifier as specified
 	//     in https://tools.ietf.org/id/draft-kerwin-file-scheme-07.html#syntax.
-	//   - For testing, all implementation MUST check that confmaptest.ValidateProviderScheme returns no error.
+	//   - For testing, all implementation MUST check that confmaptest.ValidateProviderScheme returns no error,
+	//     and SHOULD run the conformance tests of confmaptest.RunProviderConformance.
 	//
 	// `watcher` callback is called when the config changes. watcher may be called from
 	// a different go routine. After watcher is called Retrieved.Get should be called
@@ -55,6 +56,9 @@ type Provider interface {
 	// watcher may be nil, which indicates that the caller is not interested in
 	// knowing about the changes.
 	//
+	// The errors SHOULD be classified with NewProviderError, e.g. as ErrNotFound or ErrTransient,
+	// so that the callers can decide whether to retry.
+	//
 	// If ctx is cancelled should return immediately with an error.
 	// Should never be called concurrently with itself or with Shutdown.
 	Retrieve(ctx context.Context, uri string, watcher WatcherFunc) (*Retrieved, error)
@@ -70,10 +74,66 @@ type Provider interface {
 	// success or error. Retrieve cannot be called after Shutdown.
 	//
 	// Should never be called concurrently with itself or with Retrieve.
-	// If ctx is cancelled should return immediately with an error.
+	// If ctx is cancelled should return immediately with an error. The Resolver
+	// stops waiting for Shutdown once ctx is done, and reports the ctx error.
 	Shutdown(ctx context.Context) error
 }
 
+// ProviderCapabilities describes the behavior of a Provider, see CapabilitiesProvider.
+type ProviderCapabilities struct {
+	// SupportsWatch is true if the Provider notifies changes of the retrieved values.
+	SupportsWatch bool
+	// SupportsFragments is true if the Provider can be used to retrieve values embedded
+	// in the configuration using the "${<scheme>:<opaque_data>}" syntax.
+	SupportsFragments bool
+	// IsRemote is true if the Provider retrieves values over the network.
+	IsRemote bool
+	// ReturnsSecrets is true if the values retrieved by the Provider may be sensitive.
+	// Their keys are reported by Source.Secret, so that they can be redacted.
+	ReturnsSecrets bool
+}
+
+// CapabilitiesProvider is an optional interface that a Provider can implement to advertise its
+// capabilities. Providers not implementing it are assumed to support watching and fragments,
+// see GetProviderCapabilities.
+type CapabilitiesProvider interface {
+	// Capabilities returns the capabilities of the Provider.
+	Capabilities() ProviderCapabilities
+}
+
+// GetProviderCapabilities returns the capabilities advertised by the Provider if it implements
+// CapabilitiesProvider, otherwise the capabilities of a local Provider supporting watching and fragments.
+func GetProviderCapabilities(p Provider) ProviderCapabilities {
+	if cp, ok := p.(CapabilitiesProvider); ok {
+		return cp.Capabilities()
+	}
+	return ProviderCapabilities{SupportsWatch: true, SupportsFragments: true}
+}
+
+// ProviderSettings are the settings to initialize the Providers supporting them,
+// e.g. via fileprovider.NewWithSettings.
+type ProviderSettings struct {
+	// YAMLLimits bounds the resources used to parse the YAML documents retrieved by the Provider.
+	YAMLLimits YAMLLimits
+	// Warn is called with the warnings of the Provider, e.g. when it serves a stale configuration
+	// because the config source is unavailable. Nil discards the warnings.
+	Warn func(msg string)
+}
+
+// YAMLLimits bounds the resources used to parse a YAML document, protecting against
+// resource exhaustion by malicious documents, e.g. "billion laughs" alias expansion.
+// A zero value applies the default limit, a negative value disables the limit.
+type YAMLLimits struct {
+	// MaxDocumentSize is the maximum size, in bytes, of the document. Defaults to 16MiB.
+	MaxDocumentSize int
+	// MaxDepth is the maximum nesting depth of the mappings and sequences, aliases being expanded.
+	// Defaults to 1000.
+	MaxDepth int
+	// MaxNodes is the maximum number of nodes of the document once its aliases are expanded.
+	// Defaults to 1000000.
+	MaxNodes int
+}
+
 type WatcherFunc func(*ChangeEvent)
 
 // ChangeEvent describes the particular change event that happened with the config.
@@ -88,10 +148,14 @@ type ChangeEvent struct {
 type Retrieved struct {
 	rawConf   interface{}
 	closeFunc CloseFunc
+	positions map[string]Position
+	// secret is set by the Resolver if the Provider returns secrets.
+	secret bool
 }
 
 type retrievedSettings struct {
 	closeFunc CloseFunc
+	positions map[string]Position
 }
 
 // RetrievedOption options to customize Retrieved values.
@@ -105,6 +169,15 @@ func WithRetrievedClose(closeFunc CloseFunc) RetrievedOption {
 	}
 }
 
+// WithRetrievedPositions sets the positions in the configuration source where the keys of the
+// retrieved configuration are defined, used to annotate the configuration errors.
+// The keys use the KeyDelimiter separator, and are relative to the retrieved configuration.
+func WithRetrievedPositions(positions map[string]Position) RetrievedOption {
+	return func(settings *retrievedSettings) {
+		settings.positions = positions
+	}
+}
+
 // NewRetrieved returns a new Retrieved instance that contains the data from the raw deserialized config.
 // The rawConf can be one of the following types:
 //   - Primitives: int, int32, int64, float32, float64, bool, string;
@@ -118,7 +191,7 @@ func NewRetrieved(rawConf interface{}, opts ...RetrievedOption) (*Retrieved, err
 	for _, opt := range opts {
 		opt(&set)
 	}
-	return &Retrieved{rawConf: rawConf, closeFunc: set.closeFunc}, nil
+	return &Retrieved{rawConf: rawConf, closeFunc: set.closeFunc, positions: set.positions}, nil
 }
 
 // AsConf returns the retrieved configuration parsed as a Conf.
@@ -130,7 +203,9 @@ func (r *Retrieved) AsConf() (*Conf, error) {
 	if !ok {
 		return nil, fmt.Errorf("retrieved value (type=%T) cannot be used as a Conf", r.rawConf)
 	}
-	return NewFromStringMap(val), nil
+	conf := NewFromStringMap(val)
+	conf.mergePositions(r.positions)
+	return conf, nil
 }
 
 // AsRaw returns the retrieved configuration parsed as an interface{} which can be one of the following types:
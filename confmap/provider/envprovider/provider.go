@@ -26,14 +26,22 @@ import (
 
 const schemeName = "env"
 
-type provider struct{}
+type provider struct {
+	limits internal.YAMLLimits
+}
 
 // New returns a new confmap.Provider that reads the configuration from the given environment variable.
 //
 // This Provider supports "env" scheme, and can be called with a selector:
 // `env:NAME_OF_ENVIRONMENT_VARIABLE`
 func New() confmap.Provider {
-	return &provider{}
+	return NewWithSettings(confmap.ProviderSettings{})
+}
+
+// NewWithSettings is like New, but parses the retrieved YAML documents within the
+// limits of the given confmap.ProviderSettings.
+func NewWithSettings(set confmap.ProviderSettings) confmap.Provider {
+	return &provider{limits: internal.NewYAMLLimits(set.YAMLLimits)}
 }
 
 func (emp *provider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
@@ -41,7 +49,11 @@ func (emp *provider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFu
 		return nil, fmt.Errorf("%q uri is not supported by %q provider", uri, schemeName)
 	}
 
-	return internal.NewRetrievedFromYAML([]byte(os.Getenv(uri[len(schemeName)+1:])))
+	return internal.NewRetrievedFromYAMLAtWithLimits(uri, []byte(os.Getenv(uri[len(schemeName)+1:])), emp.limits)
+}
+
+func (*provider) Capabilities() confmap.ProviderCapabilities {
+	return confmap.ProviderCapabilities{SupportsFragments: true, ReturnsSecrets: true}
 }
 
 func (*provider) Scheme() string {
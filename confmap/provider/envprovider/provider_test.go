@@ -39,6 +39,10 @@ func TestValidateProviderScheme(t *testing.T) {
 	assert.NoError(t, confmaptest.ValidateProviderScheme(New()))
 }
 
+func TestCapabilities(t *testing.T) {
+	assert.Equal(t, confmap.ProviderCapabilities{SupportsFragments: true, ReturnsSecrets: true}, confmap.GetProviderCapabilities(New()))
+}
+
 func TestEmptyName(t *testing.T) {
 	env := New()
 	_, err := env.Retrieve(context.Background(), "", nil)
@@ -79,3 +83,13 @@ func TestEnv(t *testing.T) {
 
 	assert.NoError(t, env.Shutdown(context.Background()))
 }
+
+func TestProviderConformance(t *testing.T) {
+	t.Setenv("conformance-valid", validYAML)
+	t.Setenv("conformance-invalid", "[invalid,")
+	confmaptest.RunProviderConformance(t, confmaptest.ProviderConformanceSettings{
+		NewProvider:       New,
+		ValidURI:          envSchemePrefix + "conformance-valid",
+		InvalidPayloadURI: envSchemePrefix + "conformance-invalid",
+	})
+}
@@ -16,7 +16,9 @@ package fileprovider // import "go.opentelemetry.io/collector/confmap/provider/f
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -27,7 +29,9 @@ import (
 
 const schemeName = "file"
 
-type provider struct{}
+type provider struct {
+	limits internal.YAMLLimits
+}
 
 // New returns a new confmap.Provider that reads the configuration from a file.
 //
@@ -45,7 +49,13 @@ type provider struct{}
 // `file:c:/path/to/file` - absolute path including drive-letter (windows)
 // `file:c:\path\to\file` - absolute path including drive-letter (windows)
 func New() confmap.Provider {
-	return &provider{}
+	return NewWithSettings(confmap.ProviderSettings{})
+}
+
+// NewWithSettings is like New, but parses the retrieved YAML documents within the
+// limits of the given confmap.ProviderSettings.
+func NewWithSettings(set confmap.ProviderSettings) confmap.Provider {
+	return &provider{limits: internal.NewYAMLLimits(set.YAMLLimits)}
 }
 
 func (fmp *provider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
@@ -56,10 +66,21 @@ func (fmp *provider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFu
 	// Clean the path before using it.
 	content, err := os.ReadFile(filepath.Clean(uri[len(schemeName)+1:]))
 	if err != nil {
-		return nil, fmt.Errorf("unable to read the file %v: %w", uri, err)
+		err = fmt.Errorf("unable to read the file %v: %w", uri, err)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil, confmap.NewProviderError(confmap.ErrNotFound, err)
+		case errors.Is(err, fs.ErrPermission):
+			return nil, confmap.NewProviderError(confmap.ErrUnauthorized, err)
+		}
+		return nil, err
 	}
 
-	return internal.NewRetrievedFromYAML(content)
+	return internal.NewRetrievedFromYAMLAtWithLimits(uri, content, fmp.limits)
+}
+
+func (*provider) Capabilities() confmap.ProviderCapabilities {
+	return confmap.ProviderCapabilities{SupportsFragments: true}
 }
 
 func (*provider) Scheme() string {
@@ -33,6 +33,10 @@ func TestValidateProviderScheme(t *testing.T) {
 	assert.NoError(t, confmaptest.ValidateProviderScheme(New()))
 }
 
+func TestCapabilities(t *testing.T) {
+	assert.Equal(t, confmap.ProviderCapabilities{SupportsFragments: true}, confmap.GetProviderCapabilities(New()))
+}
+
 func TestEmptyName(t *testing.T) {
 	fp := New()
 	_, err := fp.Retrieve(context.Background(), "", nil)
@@ -50,18 +54,26 @@ func TestUnsupportedScheme(t *testing.T) {
 func TestNonExistent(t *testing.T) {
 	fp := New()
 	_, err := fp.Retrieve(context.Background(), fileSchemePrefix+filepath.Join("testdata", "non-existent.yaml"), nil)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, confmap.ErrNotFound)
 	_, err = fp.Retrieve(context.Background(), fileSchemePrefix+absolutePath(t, filepath.Join("testdata", "non-existent.yaml")), nil)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, confmap.ErrNotFound)
+	assert.False(t, confmap.IsRetryable(err))
 	require.NoError(t, fp.Shutdown(context.Background()))
 }
 
 func TestInvalidYAML(t *testing.T) {
 	fp := New()
 	_, err := fp.Retrieve(context.Background(), fileSchemePrefix+filepath.Join("testdata", "invalid-yaml.yaml"), nil)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, confmap.ErrInvalidFormat)
 	_, err = fp.Retrieve(context.Background(), fileSchemePrefix+absolutePath(t, filepath.Join("testdata", "invalid-yaml.yaml")), nil)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, confmap.ErrInvalidFormat)
+	require.NoError(t, fp.Shutdown(context.Background()))
+}
+
+func TestYAMLLimits(t *testing.T) {
+	fp := NewWithSettings(confmap.ProviderSettings{YAMLLimits: confmap.YAMLLimits{MaxDepth: 1}})
+	_, err := fp.Retrieve(context.Background(), fileSchemePrefix+filepath.Join("testdata", "default-config.yaml"), nil)
+	assert.ErrorIs(t, err, confmap.ErrInvalidFormat)
 	require.NoError(t, fp.Shutdown(context.Background()))
 }
 
@@ -75,7 +87,10 @@ func TestRelativePath(t *testing.T) {
 		"processors::batch":         nil,
 		"exporters::otlp::endpoint": "localhost:4317",
 	})
-	assert.Equal(t, expectedMap, retMap)
+	assert.Equal(t, expectedMap.ToStringMap(), retMap.ToStringMap())
+	pos, ok := retMap.Position("exporters::otlp::endpoint")
+	require.True(t, ok)
+	assert.Equal(t, confmap.Position{URI: fileSchemePrefix + filepath.Join("testdata", "default-config.yaml"), Line: 5, Column: 5}, pos)
 	assert.NoError(t, fp.Shutdown(context.Background()))
 }
 
@@ -89,7 +104,7 @@ func TestAbsolutePath(t *testing.T) {
 		"processors::batch":         nil,
 		"exporters::otlp::endpoint": "localhost:4317",
 	})
-	assert.Equal(t, expectedMap, retMap)
+	assert.Equal(t, expectedMap.ToStringMap(), retMap.ToStringMap())
 	assert.NoError(t, fp.Shutdown(context.Background()))
 }
 
@@ -98,3 +113,11 @@ func absolutePath(t *testing.T, relativePath string) string {
 	require.NoError(t, err)
 	return filepath.Join(dir, relativePath)
 }
+
+func TestProviderConformance(t *testing.T) {
+	confmaptest.RunProviderConformance(t, confmaptest.ProviderConformanceSettings{
+		NewProvider:       New,
+		ValidURI:          fileSchemePrefix + filepath.Join("testdata", "default-config.yaml"),
+		InvalidPayloadURI: fileSchemePrefix + filepath.Join("testdata", "invalid-yaml.yaml"),
+	})
+}
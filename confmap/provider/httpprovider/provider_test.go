@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpprovider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestValidateProviderScheme(t *testing.T) {
+	assert.NoError(t, confmaptest.ValidateProviderScheme(New()))
+}
+
+func TestScheme(t *testing.T) {
+	assert.Equal(t, "http", New().Scheme())
+}
+
+func TestProviderConformance(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/invalid" {
+			_, _ = w.Write([]byte("[invalid"))
+			return
+		}
+		_, _ = w.Write([]byte("key: value"))
+	}))
+	defer srv.Close()
+
+	confmaptest.RunProviderConformance(t, confmaptest.ProviderConformanceSettings{
+		NewProvider:       New,
+		ValidURI:          srv.URL + "/config",
+		InvalidPayloadURI: srv.URL + "/invalid",
+	})
+}
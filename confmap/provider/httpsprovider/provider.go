@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpsprovider // import "go.opentelemetry.io/collector/confmap/provider/httpsprovider"
+
+import (
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/internal/configurablehttpprovider"
+)
+
+const schemeName = "https"
+
+// New returns a new confmap.Provider that retrieves the configuration with a GET request.
+//
+// This Provider supports "https" scheme, and can be called with a "uri" that follows:
+//
+//	https-uri	= "https://" host [ ":" port ] path [ "?" query ]
+//
+// The common query parameters, e.g. "timeout", "cache_ttl", "cache_max_stale" and "cache_dir", are removed from
+// the uri before sending the request, the others are sent as is.
+//
+// Examples:
+// `https://localhost:3333/getConfig`
+// `https://config.example.com/collector.yaml?cache_ttl=5m&cache_max_stale=1h`
+func New() confmap.Provider {
+	return NewWithSettings(confmap.ProviderSettings{})
+}
+
+// NewWithSettings is like New, but parses the retrieved YAML documents within the
+// limits of the given confmap.ProviderSettings, and reports its warnings to their Warn.
+func NewWithSettings(set confmap.ProviderSettings) confmap.Provider {
+	return configurablehttpprovider.New(schemeName, set)
+}
@@ -0,0 +1,190 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/confmap/provider/internal"
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+// FetchFunc retrieves the content of a configuration document.
+type FetchFunc func(ctx context.Context) ([]byte, error)
+
+// StaleFunc is called when the document persisted in the CacheDir is served because it cannot be
+// retrieved, with the time it was retrieved and the retrieval error. Providers use it to log a
+// warning and to record the use of a stale configuration in their telemetry.
+type StaleFunc func(uri string, retrievedAt time.Time, err error)
+
+// CacheOption configures a Cache.
+type CacheOption func(*Cache)
+
+// WithStaleFunc sets the function called when a persisted document is served.
+func WithStaleFunc(onStale StaleFunc) CacheOption {
+	return func(c *Cache) {
+		c.onStale = onStale
+	}
+}
+
+// Cache caches the documents retrieved by a remote provider, to reduce the load on the config
+// sources of large fleets and to keep serving the configuration during their outages. Caching is
+// opt-in per URI, with the CacheTTLOption, and every URI is cached separately.
+//
+// The cached document is served without being retrieved again during CacheTTL. During the following
+// CacheMaxStale, it is still served, while being retrieved again in the background, so that it is
+// refreshed by the next Get if the config source is available (stale-while-revalidate). Afterwards,
+// the document is retrieved again before being served, and the errors are returned.
+//
+// Independently, with the CacheDirOption, the last document retrieved is persisted in the directory,
+// and served if it cannot be retrieved, e.g. when the collector restarts during an outage of the config
+// source (stale-on-error).
+//
+// Should be created once per provider, and shut down by its Shutdown.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	// now returns the current time, overridden in tests.
+	now     func() time.Time
+	onStale StaleFunc
+
+	wg sync.WaitGroup
+}
+
+type cacheEntry struct {
+	content   []byte
+	fetchedAt time.Time
+	// revalidating is true while the content is retrieved again in the background.
+	revalidating bool
+}
+
+// NewCache returns an empty Cache.
+func NewCache(opts ...CacheOption) *Cache {
+	c := &Cache{
+		entries: make(map[string]*cacheEntry),
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Get returns the document of the uri, calling fetch to retrieve it unless it is served from the
+// cache according to the CacheTTL and CacheMaxStale options. Nothing is cached in memory if
+// opts.CacheTTL is zero. If the document cannot be retrieved, the one persisted in opts.CacheDir,
+// if set, is served instead.
+// The background retrievals are bounded by opts.Timeout, if set, since ctx is canceled once Get returns.
+func (c *Cache) Get(ctx context.Context, uri string, opts URIOptions, fetch FetchFunc) ([]byte, error) {
+	if opts.CacheTTL > 0 {
+		c.mu.Lock()
+		entry, ok := c.entries[uri]
+		if ok {
+			age := c.now().Sub(entry.fetchedAt)
+			switch {
+			case age < opts.CacheTTL:
+				c.mu.Unlock()
+				return entry.content, nil
+			case age < opts.CacheTTL+opts.CacheMaxStale:
+				if !entry.revalidating {
+					entry.revalidating = true
+					c.wg.Add(1)
+					go c.revalidate(uri, opts, fetch)
+				}
+				c.mu.Unlock()
+				return entry.content, nil
+			}
+		}
+		c.mu.Unlock()
+	}
+
+	content, err := fetch(ctx)
+	if err != nil {
+		return c.loadPersisted(uri, opts, err)
+	}
+	c.store(uri, opts, content)
+	return content, nil
+}
+
+// revalidate retrieves the document of the uri again, and caches it if successful.
+func (c *Cache) revalidate(uri string, opts URIOptions, fetch FetchFunc) {
+	defer c.wg.Done()
+	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	content, err := fetch(ctx)
+	if err != nil {
+		// The stale document keeps being served until it expires.
+		c.mu.Lock()
+		c.entries[uri].revalidating = false
+		c.mu.Unlock()
+		return
+	}
+	c.store(uri, opts, content)
+}
+
+// store caches the retrieved document in memory, and persists it in opts.CacheDir, as enabled by opts.
+func (c *Cache) store(uri string, opts URIOptions, content []byte) {
+	if opts.CacheDir != "" {
+		// A document failing to be persisted is still served, and persisted by the next retrieval.
+		_ = persist(opts.CacheDir, uri, content, c.now())
+	}
+	if opts.CacheTTL <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[uri] = &cacheEntry{content: content, fetchedAt: c.now()}
+}
+
+// loadPersisted returns the document of the uri persisted in opts.CacheDir, if any, or fetchErr.
+func (c *Cache) loadPersisted(uri string, opts URIOptions, fetchErr error) ([]byte, error) {
+	if opts.CacheDir == "" {
+		return nil, fetchErr
+	}
+	doc, err := loadPersisted(opts.CacheDir, uri)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, fetchErr
+	case err != nil:
+		return nil, multierr.Append(fetchErr, err)
+	}
+	if c.onStale != nil {
+		c.onStale(uri, doc.RetrievedAt, fetchErr)
+	}
+	return doc.Content, nil
+}
+
+// Shutdown waits for the background retrievals to complete, or for the context to be done.
+func (c *Cache) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
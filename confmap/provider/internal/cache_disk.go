@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/confmap/provider/internal"
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// persistedDocument is the content of the file persisting the last document retrieved from a uri.
+type persistedDocument struct {
+	URI         string    `json:"uri"`
+	RetrievedAt time.Time `json:"retrieved_at"`
+	// SHA256 is the hex encoded hash of the Content, checked when loading it.
+	SHA256  string `json:"sha256"`
+	Content []byte `json:"content"`
+}
+
+// persistedPath returns the path of the file persisting the document of the uri in dir, named after
+// the hash of the uri, so that it is valid whatever the uri.
+func persistedPath(dir, uri string) string {
+	sum := sha256.Sum256([]byte(uri))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// persist writes the document of the uri in dir, creating it if needed. The file is replaced atomically,
+// and is only readable by the owner since the configuration may contain secrets.
+func persist(dir, uri string, content []byte, retrievedAt time.Time) error {
+	data, err := json.Marshal(persistedDocument{
+		URI:         uri,
+		RetrievedAt: retrievedAt,
+		SHA256:      contentHash(content),
+		Content:     content,
+	})
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), persistedPath(dir, uri))
+}
+
+// loadPersisted reads the document of the uri persisted in dir. The error wraps fs.ErrNotExist if
+// there is none.
+func loadPersisted(dir, uri string) (*persistedDocument, error) {
+	data, err := os.ReadFile(persistedPath(dir, uri))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the cached configuration of %q: %w", uri, err)
+	}
+	doc := &persistedDocument{}
+	if err = json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("invalid cached configuration of %q: %w", uri, err)
+	}
+	if doc.URI != uri {
+		return nil, fmt.Errorf("invalid cached configuration of %q: persisted for %q", uri, doc.URI)
+	}
+	if doc.SHA256 != contentHash(doc.Content) {
+		return nil, fmt.Errorf("invalid cached configuration of %q: content does not match its hash", uri)
+	}
+	return doc, nil
+}
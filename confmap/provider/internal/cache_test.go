@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource is a config source whose content and availability can be changed.
+type fakeSource struct {
+	mu      sync.Mutex
+	content string
+	err     error
+	fetches int
+}
+
+func (s *fakeSource) fetch(context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []byte(s.content), nil
+}
+
+func (s *fakeSource) set(content string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.content = content
+	s.err = err
+}
+
+func (s *fakeSource) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+func newTestCache() (*Cache, *time.Time) {
+	now := time.Unix(1000, 0)
+	c := NewCache()
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestCacheDisabled(t *testing.T) {
+	c, _ := newTestCache()
+	src := &fakeSource{content: "v1"}
+	for i := 0; i < 2; i++ {
+		content, err := c.Get(context.Background(), "https://host/cfg", URIOptions{}, src.fetch)
+		require.NoError(t, err)
+		assert.Equal(t, "v1", string(content))
+	}
+	assert.Equal(t, 2, src.fetchCount())
+}
+
+func TestCacheTTL(t *testing.T) {
+	c, now := newTestCache()
+	src := &fakeSource{content: "v1"}
+	opts := URIOptions{CacheTTL: time.Minute}
+
+	content, err := c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+
+	// Served from the cache during the TTL.
+	src.set("v2", nil)
+	*now = now.Add(59 * time.Second)
+	content, err = c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+	assert.Equal(t, 1, src.fetchCount())
+
+	// Every URI is cached separately.
+	content, err = c.Get(context.Background(), "https://host/other", opts, src.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+
+	// Retrieved again once expired, and the errors are returned without max stale.
+	*now = now.Add(time.Second)
+	content, err = c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+
+	src.set("", errors.New("unavailable"))
+	*now = now.Add(time.Minute)
+	_, err = c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	assert.EqualError(t, err, "unavailable")
+	assert.NoError(t, c.Shutdown(context.Background()))
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	c, now := newTestCache()
+	src := &fakeSource{content: "v1"}
+	opts := URIOptions{CacheTTL: time.Minute, CacheMaxStale: time.Hour}
+
+	content, err := c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+
+	// The stale content is served while the source is unavailable.
+	src.set("", errors.New("unavailable"))
+	*now = now.Add(30 * time.Minute)
+	content, err = c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+	require.NoError(t, c.Shutdown(context.Background()))
+	assert.Equal(t, 2, src.fetchCount())
+
+	// The stale content is served once more, then refreshed in the background.
+	src.set("v2", nil)
+	content, err = c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+	require.NoError(t, c.Shutdown(context.Background()))
+	content, err = c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+	assert.Equal(t, 3, src.fetchCount())
+
+	// Too old to be served once the max stale elapsed.
+	src.set("", errors.New("unavailable"))
+	*now = now.Add(time.Minute + time.Hour)
+	_, err = c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	assert.EqualError(t, err, "unavailable")
+}
+
+func TestCacheShutdownTimeout(t *testing.T) {
+	c, now := newTestCache()
+	block := make(chan struct{})
+	opts := URIOptions{CacheTTL: time.Minute, CacheMaxStale: time.Hour}
+	_, err := c.Get(context.Background(), "https://host/cfg", opts, func(context.Context) ([]byte, error) { return []byte("v1"), nil })
+	require.NoError(t, err)
+
+	*now = now.Add(2 * time.Minute)
+	_, err = c.Get(context.Background(), "https://host/cfg", opts, func(context.Context) ([]byte, error) {
+		<-block
+		return []byte("v2"), nil
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, c.Shutdown(ctx), context.DeadlineExceeded)
+	close(block)
+	assert.NoError(t, c.Shutdown(context.Background()))
+}
+
+func TestCacheDirStaleOnError(t *testing.T) {
+	type staleCall struct {
+		uri         string
+		retrievedAt time.Time
+		err         error
+	}
+	var calls []staleCall
+	c := NewCache(WithStaleFunc(func(uri string, retrievedAt time.Time, err error) {
+		calls = append(calls, staleCall{uri: uri, retrievedAt: retrievedAt, err: err})
+	}))
+	now := time.Unix(1000, 0).UTC()
+	c.now = func() time.Time { return now }
+	opts := URIOptions{CacheDir: filepath.Join(t.TempDir(), "cache")}
+	src := &fakeSource{}
+
+	// Nothing persisted yet, the error is returned.
+	errUnavailable := errors.New("unavailable")
+	src.set("", errUnavailable)
+	_, err := c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	assert.ErrorIs(t, err, errUnavailable)
+
+	src.set("v1", nil)
+	content, err := c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+
+	// The persisted document is served by another cache, e.g. after a restart.
+	c = NewCache(WithStaleFunc(func(uri string, retrievedAt time.Time, err error) {
+		calls = append(calls, staleCall{uri: uri, retrievedAt: retrievedAt, err: err})
+	}))
+	src.set("", errUnavailable)
+	content, err = c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(content))
+	require.Len(t, calls, 1)
+	assert.Equal(t, "https://host/cfg", calls[0].uri)
+	assert.True(t, now.Equal(calls[0].retrievedAt))
+	assert.ErrorIs(t, calls[0].err, errUnavailable)
+
+	// Every uri is persisted separately.
+	_, err = c.Get(context.Background(), "https://host/other", opts, src.fetch)
+	assert.ErrorIs(t, err, errUnavailable)
+
+	info, err := os.Stat(persistedPath(opts.CacheDir, "https://host/cfg"))
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+}
+
+func TestCacheDirCorrupted(t *testing.T) {
+	c := NewCache()
+	opts := URIOptions{CacheDir: t.TempDir()}
+	src := &fakeSource{content: "v1"}
+	_, err := c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	require.NoError(t, err)
+
+	path := persistedPath(opts.CacheDir, "https://host/cfg")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	doc := persistedDocument{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	doc.Content = []byte("tampered")
+	data, err = json.Marshal(doc)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	errUnavailable := errors.New("unavailable")
+	src.set("", errUnavailable)
+	_, err = c.Get(context.Background(), "https://host/cfg", opts, src.fetch)
+	assert.ErrorIs(t, err, errUnavailable)
+	assert.ErrorContains(t, err, "content does not match its hash")
+}
@@ -0,0 +1,39 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurablehttpprovider // import "go.opentelemetry.io/collector/confmap/provider/internal/configurablehttpprovider"
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	schemeTagKey      = tag.MustNewKey("scheme")
+	statStaleRetrieve = stats.Int64("config_provider_stale_retrievals", "Number of configurations served from the cache_dir since they could not be retrieved", stats.UnitDimensionless)
+)
+
+// MetricViews returns the metrics views related to the stale configurations, tagged with the scheme.
+func MetricViews() []*view.View {
+	countStaleRetrievesView := &view.View{
+		Name:        statStaleRetrieve.Name(),
+		Measure:     statStaleRetrieve,
+		Description: statStaleRetrieve.Description(),
+		TagKeys:     []tag.Key{schemeTagKey},
+		Aggregation: view.Sum(),
+	}
+
+	return []*view.View{countStaleRetrievesView}
+}
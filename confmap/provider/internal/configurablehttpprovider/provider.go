@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurablehttpprovider // import "go.opentelemetry.io/collector/confmap/provider/internal/configurablehttpprovider"
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/internal"
+)
+
+// maxResponseSize bounds the size of the retrieved documents, well above the default YAML limits.
+const maxResponseSize = 64 << 20
+
+type provider struct {
+	scheme string
+	client *http.Client
+	limits internal.YAMLLimits
+	cache  *internal.Cache
+	warn   func(msg string)
+}
+
+// New returns a new confmap.Provider that retrieves the configuration with a GET request to the
+// uri, using the given scheme, "http" or "https". The common query parameters of the uri, see
+// internal.URIOptions, are removed before sending the request, and the documents are cached
+// according to the "cache_ttl" and "cache_max_stale" ones. With the "cache_dir" one, the last document
+// retrieved is served if it cannot be retrieved, reported by a warning and the metrics of MetricViews.
+func New(scheme string, set confmap.ProviderSettings) confmap.Provider {
+	p := &provider{
+		scheme: scheme,
+		client: &http.Client{},
+		limits: internal.NewYAMLLimits(set.YAMLLimits),
+		warn:   set.Warn,
+	}
+	p.cache = internal.NewCache(internal.WithStaleFunc(p.onStale))
+	return p
+}
+
+func (p *provider) Retrieve(ctx context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if !strings.HasPrefix(uri, p.scheme+"://") {
+		return nil, fmt.Errorf("%q uri is not supported by %q provider", uri, p.scheme)
+	}
+	// Do not serve cached documents once the caller gave up.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	location, opts, err := internal.ParseURIOptions(uri)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Params) > 0 {
+		location += "?" + opts.Params.Encode()
+	}
+
+	content, err := p.cache.Get(ctx, uri, opts, func(ctx context.Context) ([]byte, error) {
+		return p.fetch(ctx, location, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return internal.NewRetrievedFromYAMLAtWithLimits(uri, content, p.limits)
+}
+
+// fetch retrieves the document at the location, within opts.Timeout if set.
+func (p *provider) fetch(ctx context.Context, location string, opts internal.URIOptions) ([]byte, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the request for %v: %w", location, err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, confmap.NewProviderError(confmap.ErrTransient, fmt.Errorf("unable to retrieve %v: %w", location, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unable to retrieve %v: unexpected status %q", location, resp.Status)
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, confmap.NewProviderError(confmap.ErrNotFound, err)
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			return nil, confmap.NewProviderError(confmap.ErrUnauthorized, err)
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			return nil, confmap.NewProviderError(confmap.ErrTransient, err)
+		}
+		return nil, err
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, confmap.NewProviderError(confmap.ErrTransient, fmt.Errorf("unable to read the response of %v: %w", location, err))
+	}
+	if len(content) > maxResponseSize {
+		return nil, fmt.Errorf("unable to retrieve %v: the response exceeds %d bytes", location, maxResponseSize)
+	}
+	return content, nil
+}
+
+// onStale reports that the document persisted in the cache_dir is served instead of the one of the uri.
+func (p *provider) onStale(uri string, retrievedAt time.Time, err error) {
+	_ = stats.RecordWithTags(context.Background(), []tag.Mutator{tag.Upsert(schemeTagKey, p.scheme)}, statStaleRetrieve.M(1))
+	if p.warn != nil {
+		p.warn(fmt.Sprintf("serving the configuration of %q retrieved at %v from the cache_dir, since it cannot be retrieved: %v",
+			uri, retrievedAt.Format(time.RFC3339), err))
+	}
+}
+
+func (*provider) Capabilities() confmap.ProviderCapabilities {
+	return confmap.ProviderCapabilities{SupportsFragments: true, IsRemote: true}
+}
+
+func (p *provider) Scheme() string {
+	return p.scheme
+}
+
+func (p *provider) Shutdown(ctx context.Context) error {
+	p.client.CloseIdleConnections()
+	return p.cache.Shutdown(ctx)
+}
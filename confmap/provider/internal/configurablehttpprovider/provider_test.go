@@ -0,0 +1,195 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configurablehttpprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRetrieve(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/config", r.URL.Path)
+		assert.Equal(t, "env=prod", r.URL.RawQuery)
+		_, _ = w.Write([]byte("key: value"))
+	})
+
+	p := New("http", confmap.ProviderSettings{})
+	ret, err := p.Retrieve(context.Background(), srv.URL+"/config?timeout=5s&env=prod", nil)
+	require.NoError(t, err)
+	conf, err := ret.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, "value", conf.Get("key"))
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestRetrieveHTTPS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("key: value"))
+	}))
+	defer srv.Close()
+
+	p := New("https", confmap.ProviderSettings{})
+	p.(*provider).client = srv.Client()
+	ret, err := p.Retrieve(context.Background(), srv.URL, nil)
+	require.NoError(t, err)
+	conf, err := ret.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, "value", conf.Get("key"))
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestRetrieveUnsupportedScheme(t *testing.T) {
+	p := New("https", confmap.ProviderSettings{})
+	_, err := p.Retrieve(context.Background(), "http://localhost/config", nil)
+	assert.Error(t, err)
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestRetrieveInvalidOptions(t *testing.T) {
+	p := New("http", confmap.ProviderSettings{})
+	_, err := p.Retrieve(context.Background(), "http://localhost/config?cache_ttl=invalid", nil)
+	assert.Error(t, err)
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestRetrieveStatusErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		class  error
+	}{
+		{status: http.StatusNotFound, class: confmap.ErrNotFound},
+		{status: http.StatusUnauthorized, class: confmap.ErrUnauthorized},
+		{status: http.StatusForbidden, class: confmap.ErrUnauthorized},
+		{status: http.StatusTooManyRequests, class: confmap.ErrTransient},
+		{status: http.StatusServiceUnavailable, class: confmap.ErrTransient},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.status), func(t *testing.T) {
+			srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			})
+
+			p := New("http", confmap.ProviderSettings{})
+			_, err := p.Retrieve(context.Background(), srv.URL, nil)
+			assert.ErrorIs(t, err, tt.class)
+			assert.NoError(t, p.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestRetrieveCached(t *testing.T) {
+	var requests int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "requests: %d", atomic.AddInt32(&requests, 1))
+	})
+
+	p := New("http", confmap.ProviderSettings{})
+	for _, uri := range []string{srv.URL + "?cache_ttl=1h", srv.URL + "?cache_ttl=1h"} {
+		ret, err := p.Retrieve(context.Background(), uri, nil)
+		require.NoError(t, err)
+		conf, err := ret.AsConf()
+		require.NoError(t, err)
+		assert.Equal(t, 1, conf.Get("requests"))
+	}
+
+	// Not cached without the cache_ttl option.
+	ret, err := p.Retrieve(context.Background(), srv.URL, nil)
+	require.NoError(t, err)
+	conf, err := ret.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, 2, conf.Get("requests"))
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestRetrieveCancelledContext(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("key: value"))
+	})
+
+	p := New("http", confmap.ProviderSettings{})
+	_, err := p.Retrieve(context.Background(), srv.URL+"?cache_ttl=1h", nil)
+	require.NoError(t, err)
+
+	// Cached documents are not served to the callers that gave up.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = p.Retrieve(ctx, srv.URL+"?cache_ttl=1h", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestRetrieveStaleOnError(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	var unavailable int32
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&unavailable) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("key: value"))
+	})
+	uri := srv.URL + "?cache_dir=" + t.TempDir()
+
+	var warnings []string
+	set := confmap.ProviderSettings{Warn: func(msg string) { warnings = append(warnings, msg) }}
+	p := New("http", set)
+	_, err := p.Retrieve(context.Background(), uri, nil)
+	require.NoError(t, err)
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Empty(t, warnings)
+
+	// A new provider, e.g. after a restart, serves the persisted document during the outage.
+	atomic.StoreInt32(&unavailable, 1)
+	p = New("http", set)
+	ret, err := p.Retrieve(context.Background(), uri, nil)
+	require.NoError(t, err)
+	conf, err := ret.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, "value", conf.Get("key"))
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "503 Service Unavailable")
+
+	rows, err := view.RetrieveData(statStaleRetrieve.Name())
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "http", rows[0].Tags[0].Value)
+	assert.Equal(t, float64(1), rows[0].Data.(*view.SumData).Value)
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestCapabilities(t *testing.T) {
+	assert.Equal(t, confmap.ProviderCapabilities{SupportsFragments: true, IsRemote: true},
+		confmap.GetProviderCapabilities(New("http", confmap.ProviderSettings{})))
+}
@@ -20,13 +20,73 @@ import (
 	"go.opentelemetry.io/collector/confmap"
 )
 
-// NewRetrievedFromYAML returns a new Retrieved instance that contains the deserialized data from the yaml bytes.
+// NewRetrievedFromYAML returns a new Retrieved instance that contains the deserialized data from the yaml bytes,
+// within the DefaultYAMLLimits.
 // * yamlBytes the yaml bytes that will be deserialized.
 // * opts specifies options associated with this Retrieved value, such as CloseFunc.
 func NewRetrievedFromYAML(yamlBytes []byte, opts ...confmap.RetrievedOption) (*confmap.Retrieved, error) {
+	return NewRetrievedFromYAMLWithLimits(yamlBytes, DefaultYAMLLimits, opts...)
+}
+
+// NewRetrievedFromYAMLAt is like NewRetrievedFromYAML, but also records the line and column where every
+// key is defined in the document retrieved from the uri, see confmap.WithRetrievedPositions.
+func NewRetrievedFromYAMLAt(uri string, yamlBytes []byte, opts ...confmap.RetrievedOption) (*confmap.Retrieved, error) {
+	return newRetrievedFromYAML(uri, yamlBytes, DefaultYAMLLimits, opts...)
+}
+
+// NewRetrievedFromYAMLAtWithLimits is like NewRetrievedFromYAMLAt, but fails if the document exceeds the given limits.
+func NewRetrievedFromYAMLAtWithLimits(uri string, yamlBytes []byte, limits YAMLLimits, opts ...confmap.RetrievedOption) (*confmap.Retrieved, error) {
+	return newRetrievedFromYAML(uri, yamlBytes, limits, opts...)
+}
+
+// NewRetrievedFromYAMLWithLimits is like NewRetrievedFromYAML, but fails if the document exceeds the given limits.
+func NewRetrievedFromYAMLWithLimits(yamlBytes []byte, limits YAMLLimits, opts ...confmap.RetrievedOption) (*confmap.Retrieved, error) {
+	return newRetrievedFromYAML("", yamlBytes, limits, opts...)
+}
+
+// newRetrievedFromYAML deserializes the yaml bytes within the limits, recording the positions of the keys if uri is not empty.
+// The errors are of the confmap.ErrInvalidFormat class.
+func newRetrievedFromYAML(uri string, yamlBytes []byte, limits YAMLLimits, opts ...confmap.RetrievedOption) (*confmap.Retrieved, error) {
+	if err := limits.checkSize(yamlBytes); err != nil {
+		return nil, confmap.NewProviderError(confmap.ErrInvalidFormat, err)
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(yamlBytes, &node); err != nil {
+		return nil, confmap.NewProviderError(confmap.ErrInvalidFormat, err)
+	}
+	if err := limits.checkNode(&node); err != nil {
+		return nil, confmap.NewProviderError(confmap.ErrInvalidFormat, err)
+	}
 	var rawConf interface{}
-	if err := yaml.Unmarshal(yamlBytes, &rawConf); err != nil {
-		return nil, err
+	if err := node.Decode(&rawConf); err != nil {
+		return nil, confmap.NewProviderError(confmap.ErrInvalidFormat, err)
+	}
+	if uri != "" {
+		positions := make(map[string]confmap.Position)
+		collectPositions(uri, &node, "", positions)
+		opts = append([]confmap.RetrievedOption{confmap.WithRetrievedPositions(positions)}, opts...)
 	}
 	return confmap.NewRetrieved(rawConf, opts...)
 }
+
+// collectPositions records in positions the line and column of every mapping key under the node,
+// with the confmap.KeyDelimiter separator. Aliases and merge keys are not followed, their keys
+// are reported at the position of the key referencing them.
+func collectPositions(uri string, node *yaml.Node, prefix string, positions map[string]confmap.Position) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			collectPositions(uri, child, prefix, positions)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			if keyNode.Kind != yaml.ScalarNode || keyNode.Tag == "!!merge" {
+				continue
+			}
+			key := prefix + keyNode.Value
+			positions[key] = confmap.Position{URI: uri, Line: keyNode.Line, Column: keyNode.Column}
+			collectPositions(uri, valueNode, key+confmap.KeyDelimiter, positions)
+		}
+	}
+}
@@ -44,9 +44,48 @@ func TestNewRetrievedFromYAMLWithOptions(t *testing.T) {
 	assert.Equal(t, want, ret.Close(context.Background()))
 }
 
+func TestNewRetrievedFromYAMLAt(t *testing.T) {
+	yamlBytes := []byte(`processors:
+  batch:
+exporters:
+  otlp:
+    endpoint: &endpoint "localhost:4317"
+    headers:
+      <<: {key: value}
+  otlp/2:
+    endpoint: *endpoint
+    tls: [insecure]
+`)
+	ret, err := NewRetrievedFromYAMLAt("file:config.yaml", yamlBytes)
+	require.NoError(t, err)
+	retMap, err := ret.AsConf()
+	require.NoError(t, err)
+
+	for key, line := range map[string]int{
+		"processors":                  1,
+		"processors::batch":           2,
+		"exporters::otlp":             4,
+		"exporters::otlp::endpoint":   5,
+		"exporters::otlp::headers":    6,
+		"exporters::otlp/2::endpoint": 9,
+		"exporters::otlp/2::tls":      10,
+	} {
+		pos, ok := retMap.Position(key)
+		require.True(t, ok, key)
+		assert.Equal(t, "file:config.yaml", pos.URI, key)
+		assert.Equal(t, line, pos.Line, key)
+	}
+	pos, _ := retMap.Position("exporters::otlp::endpoint")
+	assert.Equal(t, 5, pos.Column)
+
+	// Merge keys are not followed.
+	_, ok := retMap.Position("exporters::otlp::headers::key")
+	assert.False(t, ok)
+}
+
 func TestNewRetrievedFromYAMLInvalidYAMLBytes(t *testing.T) {
 	_, err := NewRetrievedFromYAML([]byte("[invalid:,"))
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, confmap.ErrInvalidFormat)
 }
 
 func TestNewRetrievedFromYAMLInvalidAsMap(t *testing.T) {
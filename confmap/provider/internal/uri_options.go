@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/confmap/provider/internal"
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	// TimeoutOption is the query parameter that bounds the time to retrieve the configuration.
+	TimeoutOption = "timeout"
+	// FormatOption is the query parameter that selects the format of the retrieved configuration.
+	FormatOption = "format"
+	// ProfileOption is the query parameter that selects the authentication profile, if supported.
+	ProfileOption = "profile"
+	// PollIntervalOption is the query parameter that sets the interval to poll for changes, for
+	// providers supporting watching. The confmap.Resolver sets it to ResolverSettings.PollInterval
+	// for the URIs not setting it.
+	PollIntervalOption = confmap.PollIntervalQueryParam
+	// CacheTTLOption is the query parameter that enables caching the retrieved configuration, for
+	// providers supporting it, and sets how long it is served without being retrieved again.
+	CacheTTLOption = "cache_ttl"
+	// CacheMaxStaleOption is the query parameter that sets how long after its TTL the cached
+	// configuration is still served, while being retrieved again in the background.
+	CacheMaxStaleOption = "cache_max_stale"
+	// CacheDirOption is the query parameter that sets the directory where the last retrieved
+	// configuration is persisted, to be served when the config source is unavailable.
+	CacheDirOption = "cache_dir"
+)
+
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// URIOptions are the options providers accept as query parameters of the URI, for example:
+// "s3://bucket/key?region=us-east-1&timeout=5s" or "https://host/cfg?format=json".
+type URIOptions struct {
+	// Timeout bounds the time to retrieve the configuration, zero if not set.
+	Timeout time.Duration
+	// Format of the retrieved configuration, FormatYAML if not set. JSON is parsed as YAML.
+	Format string
+	// Profile is the authentication profile to use, empty if not set.
+	Profile string
+	// PollInterval is the interval to poll for changes, zero if not set.
+	PollInterval time.Duration
+	// CacheTTL is how long the cached configuration is served, zero if caching is disabled.
+	CacheTTL time.Duration
+	// CacheMaxStale is how long after CacheTTL the cached configuration is still served, zero if not set.
+	CacheMaxStale time.Duration
+	// CacheDir is the directory where the last retrieved configuration is persisted, empty if not set.
+	CacheDir string
+	// Params contains all the other, provider-specific, query parameters.
+	Params url.Values
+}
+
+// ParseURIOptions splits the given uri into the location without the query and the parsed URIOptions.
+// Returns an error if any of the common options is invalid or repeated.
+func ParseURIOptions(uri string) (string, URIOptions, error) {
+	opts := URIOptions{Format: FormatYAML}
+	idx := strings.IndexByte(uri, '?')
+	if idx == -1 {
+		opts.Params = url.Values{}
+		return uri, opts, nil
+	}
+
+	location := uri[:idx]
+	params, err := url.ParseQuery(uri[idx+1:])
+	if err != nil {
+		return "", URIOptions{}, fmt.Errorf("invalid query in uri %q: %w", uri, err)
+	}
+
+	for _, name := range []string{TimeoutOption, FormatOption, ProfileOption, PollIntervalOption, CacheTTLOption, CacheMaxStaleOption, CacheDirOption} {
+		if len(params[name]) > 1 {
+			return "", URIOptions{}, fmt.Errorf("option %q is repeated in uri %q", name, uri)
+		}
+	}
+
+	if val := params.Get(TimeoutOption); val != "" {
+		if opts.Timeout, err = time.ParseDuration(val); err != nil {
+			return "", URIOptions{}, fmt.Errorf("invalid option %q in uri %q: %w", TimeoutOption, uri, err)
+		}
+		if opts.Timeout <= 0 {
+			return "", URIOptions{}, fmt.Errorf("invalid option %q in uri %q: must be positive", TimeoutOption, uri)
+		}
+	}
+	for _, d := range []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{name: PollIntervalOption, dst: &opts.PollInterval},
+		{name: CacheTTLOption, dst: &opts.CacheTTL},
+		{name: CacheMaxStaleOption, dst: &opts.CacheMaxStale},
+	} {
+		val := params.Get(d.name)
+		if val == "" {
+			continue
+		}
+		if *d.dst, err = time.ParseDuration(val); err != nil {
+			return "", URIOptions{}, fmt.Errorf("invalid option %q in uri %q: %w", d.name, uri, err)
+		}
+		if *d.dst <= 0 {
+			return "", URIOptions{}, fmt.Errorf("invalid option %q in uri %q: must be positive", d.name, uri)
+		}
+	}
+	if opts.CacheMaxStale > 0 && opts.CacheTTL == 0 {
+		return "", URIOptions{}, fmt.Errorf("invalid option %q in uri %q: requires %q", CacheMaxStaleOption, uri, CacheTTLOption)
+	}
+	if val := params.Get(FormatOption); val != "" {
+		if val != FormatYAML && val != FormatJSON {
+			return "", URIOptions{}, fmt.Errorf("invalid option %q in uri %q: unsupported format %q", FormatOption, uri, val)
+		}
+		opts.Format = val
+	}
+	opts.Profile = params.Get(ProfileOption)
+	opts.CacheDir = params.Get(CacheDirOption)
+
+	params.Del(TimeoutOption)
+	params.Del(FormatOption)
+	params.Del(ProfileOption)
+	params.Del(PollIntervalOption)
+	params.Del(CacheTTLOption)
+	params.Del(CacheMaxStaleOption)
+	params.Del(CacheDirOption)
+	opts.Params = params
+	return location, opts, nil
+}
@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURIOptions(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		uri      string
+		location string
+		opts     URIOptions
+	}{
+		{
+			name:     "no_query",
+			uri:      "s3://bucket/key",
+			location: "s3://bucket/key",
+			opts:     URIOptions{Format: FormatYAML, Params: url.Values{}},
+		},
+		{
+			name:     "all_options",
+			uri:      "s3://bucket/key?region=us-east-1&timeout=5s&format=json&profile=prod&poll_interval=1m&cache_ttl=5m&cache_max_stale=1h&cache_dir=%2Fvar%2Fcache",
+			location: "s3://bucket/key",
+			opts: URIOptions{
+				Timeout:       5 * time.Second,
+				Format:        FormatJSON,
+				Profile:       "prod",
+				PollInterval:  time.Minute,
+				CacheTTL:      5 * time.Minute,
+				CacheMaxStale: time.Hour,
+				CacheDir:      "/var/cache",
+				Params:        url.Values{"region": []string{"us-east-1"}},
+			},
+		},
+		{
+			name:     "empty_query",
+			uri:      "https://host/cfg?",
+			location: "https://host/cfg",
+			opts:     URIOptions{Format: FormatYAML, Params: url.Values{}},
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			location, opts, err := ParseURIOptions(tt.uri)
+			require.NoError(t, err)
+			assert.Equal(t, tt.location, location)
+			assert.Equal(t, tt.opts, opts)
+		})
+	}
+}
+
+func TestParseURIOptionsErrors(t *testing.T) {
+	var testCases = []struct {
+		name string
+		uri  string
+	}{
+		{name: "invalid_query", uri: "https://host/cfg?a=%zz"},
+		{name: "invalid_timeout", uri: "https://host/cfg?timeout=abc"},
+		{name: "negative_timeout", uri: "https://host/cfg?timeout=-1s"},
+		{name: "unsupported_format", uri: "https://host/cfg?format=toml"},
+		{name: "invalid_poll_interval", uri: "https://host/cfg?poll_interval=often"},
+		{name: "zero_poll_interval", uri: "https://host/cfg?poll_interval=0s"},
+		{name: "invalid_cache_ttl", uri: "https://host/cfg?cache_ttl=long"},
+		{name: "negative_cache_max_stale", uri: "https://host/cfg?cache_ttl=1m&cache_max_stale=-1m"},
+		{name: "cache_max_stale_without_ttl", uri: "https://host/cfg?cache_max_stale=1m"},
+		{name: "repeated_option", uri: "https://host/cfg?format=json&format=yaml"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseURIOptions(tt.uri)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/confmap/provider/internal"
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// YAMLLimits bounds the resources used to parse a YAML document, protecting against
+// resource exhaustion by malicious documents, e.g. "billion laughs" alias expansion,
+// retrieved from compromised config sources. A zero value disables the corresponding limit.
+type YAMLLimits struct {
+	// MaxDocumentSize is the maximum size, in bytes, of the document.
+	MaxDocumentSize int
+	// MaxDepth is the maximum nesting depth of the mappings and sequences, aliases being expanded.
+	MaxDepth int
+	// MaxNodes is the maximum number of nodes of the document once its aliases are expanded.
+	MaxNodes int
+}
+
+// DefaultYAMLLimits are the limits applied by NewRetrievedFromYAML.
+var DefaultYAMLLimits = YAMLLimits{
+	MaxDocumentSize: 16 * 1024 * 1024,
+	MaxDepth:        1000,
+	MaxNodes:        1000000,
+}
+
+// NewYAMLLimits returns the YAMLLimits configured by the confmap.YAMLLimits, the zero values
+// being replaced with the DefaultYAMLLimits and the negative ones disabling the limit.
+func NewYAMLLimits(set confmap.YAMLLimits) YAMLLimits {
+	return YAMLLimits{
+		MaxDocumentSize: yamlLimit(set.MaxDocumentSize, DefaultYAMLLimits.MaxDocumentSize),
+		MaxDepth:        yamlLimit(set.MaxDepth, DefaultYAMLLimits.MaxDepth),
+		MaxNodes:        yamlLimit(set.MaxNodes, DefaultYAMLLimits.MaxNodes),
+	}
+}
+
+func yamlLimit(limit, def int) int {
+	switch {
+	case limit < 0:
+		return 0
+	case limit == 0:
+		return def
+	}
+	return limit
+}
+
+// checkSize returns an error if the document exceeds the size limit.
+func (l YAMLLimits) checkSize(yamlBytes []byte) error {
+	if l.MaxDocumentSize > 0 && len(yamlBytes) > l.MaxDocumentSize {
+		return fmt.Errorf("yaml document size of %d bytes exceeds the limit of %d bytes", len(yamlBytes), l.MaxDocumentSize)
+	}
+	return nil
+}
+
+// checkNode returns an error if the parsed document exceeds the depth or nodes limits.
+func (l YAMLLimits) checkNode(root *yaml.Node) error {
+	if l.MaxDepth <= 0 && l.MaxNodes <= 0 {
+		return nil
+	}
+	w := &yamlWalker{limits: l, sizes: map[*yaml.Node]int{}, depths: map[*yaml.Node]int{}}
+	_, err := w.walk(root, 0)
+	return err
+}
+
+// yamlWalker computes the number of nodes and the depth of the document with its aliases
+// expanded. They are memoized for every node, so that the cost of the walk is proportional
+// to the size of the document before expansion.
+type yamlWalker struct {
+	limits YAMLLimits
+	// sizes holds the number of nodes of every walked node, itself included.
+	sizes map[*yaml.Node]int
+	// depths holds the maximum number of nested collections below every walked node.
+	depths map[*yaml.Node]int
+}
+
+func (w *yamlWalker) walk(node *yaml.Node, depth int) (int, error) {
+	node = resolveAlias(node)
+	depth += collectionLevel(node)
+	if size, ok := w.sizes[node]; ok {
+		if err := w.checkDepth(depth + w.depths[node]); err != nil {
+			return 0, err
+		}
+		return size, nil
+	}
+	if err := w.checkDepth(depth); err != nil {
+		return 0, err
+	}
+
+	size := 1
+	below := 0
+	for _, child := range node.Content {
+		childSize, err := w.walk(child, depth)
+		if err != nil {
+			return 0, err
+		}
+		size += childSize
+		if w.limits.MaxNodes > 0 && size > w.limits.MaxNodes {
+			return 0, fmt.Errorf("yaml document exceeds the limit of %d nodes, aliases being expanded", w.limits.MaxNodes)
+		}
+		child = resolveAlias(child)
+		if d := collectionLevel(child) + w.depths[child]; d > below {
+			below = d
+		}
+	}
+	w.sizes[node] = size
+	w.depths[node] = below
+	return size, nil
+}
+
+func (w *yamlWalker) checkDepth(depth int) error {
+	if w.limits.MaxDepth > 0 && depth > w.limits.MaxDepth {
+		return fmt.Errorf("yaml document exceeds the nesting depth limit of %d", w.limits.MaxDepth)
+	}
+	return nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		return node.Alias
+	}
+	return node
+}
+
+// collectionLevel returns 1 if the node is a mapping or a sequence, 0 otherwise.
+func collectionLevel(node *yaml.Node) int {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// billionLaughs returns a document whose aliases expand to 9^levels nodes.
+func billionLaughs(levels int) string {
+	var sb strings.Builder
+	sb.WriteString("a0: &a0 [lol, lol, lol, lol, lol, lol, lol, lol, lol]\n")
+	for i := 1; i <= levels; i++ {
+		name := "a" + strconv.Itoa(i)
+		prev := "*a" + strconv.Itoa(i-1)
+		sb.WriteString(name + ": &" + name + " [")
+		sb.WriteString(strings.TrimSuffix(strings.Repeat(prev+", ", 9), ", "))
+		sb.WriteString("]\n")
+	}
+	return sb.String()
+}
+
+func TestNewRetrievedFromYAMLWithLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		limits  YAMLLimits
+		wantErr string
+	}{
+		{
+			name:   "within_limits",
+			yaml:   "receivers:\n  otlp:\n    protocols:\n      grpc:\n",
+			limits: YAMLLimits{MaxDocumentSize: 100, MaxDepth: 4, MaxNodes: 10},
+		},
+		{
+			name:    "document_size",
+			yaml:    "receivers:\n  otlp:\n",
+			limits:  YAMLLimits{MaxDocumentSize: 10},
+			wantErr: "yaml document size of 19 bytes exceeds the limit of 10 bytes",
+		},
+		{
+			name:    "depth",
+			yaml:    "a:\n  b:\n    c:\n      d: e\n",
+			limits:  YAMLLimits{MaxDepth: 3},
+			wantErr: "yaml document exceeds the nesting depth limit of 3",
+		},
+		{
+			name:    "depth_through_aliases",
+			yaml:    "x: &x\n  c:\n    d: e\na:\n  b: *x\n",
+			limits:  YAMLLimits{MaxDepth: 3},
+			wantErr: "yaml document exceeds the nesting depth limit of 3",
+		},
+		{
+			name:   "aliases_within_limits",
+			yaml:   billionLaughs(2),
+			limits: YAMLLimits{MaxNodes: 1000},
+		},
+		{
+			name:    "billion_laughs",
+			yaml:    billionLaughs(9),
+			limits:  DefaultYAMLLimits,
+			wantErr: "yaml document exceeds the limit of 1000000 nodes, aliases being expanded",
+		},
+		{
+			name: "no_limits",
+			yaml: "a:\n  b:\n    c:\n      d: e\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ret, err := NewRetrievedFromYAMLWithLimits([]byte(tt.yaml), tt.limits)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			_, err = ret.AsConf()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewYAMLLimits(t *testing.T) {
+	assert.Equal(t, DefaultYAMLLimits, NewYAMLLimits(confmap.YAMLLimits{}))
+	assert.Equal(t, YAMLLimits{MaxDocumentSize: 10, MaxDepth: DefaultYAMLLimits.MaxDepth},
+		NewYAMLLimits(confmap.YAMLLimits{MaxDocumentSize: 10, MaxNodes: -1}))
+}
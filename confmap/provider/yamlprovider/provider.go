@@ -25,7 +25,9 @@ import (
 
 const schemeName = "yaml"
 
-type provider struct{}
+type provider struct {
+	limits internal.YAMLLimits
+}
 
 // New returns a new confmap.Provider that allows to provide yaml bytes.
 //
@@ -37,7 +39,13 @@ type provider struct{}
 // `yaml:processors::batch::timeout: 2s`
 // `yaml:processors::batch/foo::timeout: 3s`
 func New() confmap.Provider {
-	return &provider{}
+	return NewWithSettings(confmap.ProviderSettings{})
+}
+
+// NewWithSettings is like New, but parses the retrieved YAML documents within the
+// limits of the given confmap.ProviderSettings.
+func NewWithSettings(set confmap.ProviderSettings) confmap.Provider {
+	return &provider{limits: internal.NewYAMLLimits(set.YAMLLimits)}
 }
 
 func (s *provider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
@@ -45,7 +53,11 @@ func (s *provider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFunc
 		return nil, fmt.Errorf("%q uri is not supported by %q provider", uri, schemeName)
 	}
 
-	return internal.NewRetrievedFromYAML([]byte(uri[len(schemeName)+1:]))
+	return internal.NewRetrievedFromYAMLAtWithLimits(uri, []byte(uri[len(schemeName)+1:]), s.limits)
+}
+
+func (*provider) Capabilities() confmap.ProviderCapabilities {
+	return confmap.ProviderCapabilities{SupportsFragments: true}
 }
 
 func (*provider) Scheme() string {
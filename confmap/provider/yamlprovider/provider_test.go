@@ -20,6 +20,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 )
 
@@ -27,6 +28,10 @@ func TestValidateProviderScheme(t *testing.T) {
 	assert.NoError(t, confmaptest.ValidateProviderScheme(New()))
 }
 
+func TestCapabilities(t *testing.T) {
+	assert.Equal(t, confmap.ProviderCapabilities{SupportsFragments: true}, confmap.GetProviderCapabilities(New()))
+}
+
 func TestEmpty(t *testing.T) {
 	sp := New()
 	_, err := sp.Retrieve(context.Background(), "", nil)
@@ -120,3 +125,11 @@ func TestDotSeparator(t *testing.T) {
 	assert.Equal(t, map[string]interface{}{"processors.batch.timeout": "4s"}, retMap.ToStringMap())
 	assert.NoError(t, sp.Shutdown(context.Background()))
 }
+
+func TestProviderConformance(t *testing.T) {
+	confmaptest.RunProviderConformance(t, confmaptest.ProviderConformanceSettings{
+		NewProvider:       New,
+		ValidURI:          "yaml:processors::batch::timeout: 2s",
+		InvalidPayloadURI: "yaml:[invalid,",
+	})
+}
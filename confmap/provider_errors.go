@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmap // import "go.opentelemetry.io/collector/confmap"
+
+import (
+	"context"
+	"errors"
+)
+
+// The classes of the errors returned by Provider.Retrieve, to be checked with errors.Is. Providers
+// classify their errors with NewProviderError, so that the callers can decide to retry, to fall back
+// to another configuration, or to fail fast, without depending on the error messages.
+var (
+	// ErrNotFound is the class of the errors returned when the configuration does not exist.
+	ErrNotFound = errors.New("configuration not found")
+	// ErrUnauthorized is the class of the errors returned when the access to the configuration is denied.
+	ErrUnauthorized = errors.New("access to the configuration denied")
+	// ErrTransient is the class of the errors returned when the configuration cannot be retrieved
+	// temporarily, e.g. timeouts or unavailable config sources, so that retrying may succeed.
+	ErrTransient = errors.New("configuration temporarily unavailable")
+	// ErrInvalidFormat is the class of the errors returned when the retrieved configuration cannot be parsed.
+	ErrInvalidFormat = errors.New("invalid configuration format")
+)
+
+// providerError is an error of one of the classes of the Provider errors.
+type providerError struct {
+	class error
+	err   error
+}
+
+// NewProviderError returns an error of the given class, one of ErrNotFound, ErrUnauthorized,
+// ErrTransient or ErrInvalidFormat, with the message of err. Both errors.Is(ret, class) and
+// errors.Is(ret, err) are true.
+func NewProviderError(class error, err error) error {
+	return &providerError{class: class, err: err}
+}
+
+func (e *providerError) Error() string {
+	return e.err.Error()
+}
+
+func (e *providerError) Unwrap() error {
+	return e.err
+}
+
+func (e *providerError) Is(target error) bool {
+	return target == e.class
+}
+
+// IsRetryable returns true if err, returned by a Provider or by the Resolver, is of the
+// ErrTransient class, or a context deadline, so that retrying to retrieve the configuration may succeed.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrTransient) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsPermanent returns true if err, returned by a Provider or by the Resolver, is of the ErrNotFound,
+// ErrUnauthorized or ErrInvalidFormat class, so that neither retrying nor falling back to another
+// configuration is expected to succeed without fixing the configuration or its access.
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrInvalidFormat)
+}
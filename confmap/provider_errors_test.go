@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/multierr"
+)
+
+func TestProviderError(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := NewProviderError(ErrTransient, cause)
+	assert.EqualError(t, err, "connection refused")
+	assert.ErrorIs(t, err, ErrTransient)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, ErrNotFound)
+
+	// The class is kept when the error is wrapped, e.g. by the Resolver.
+	wrapped := fmt.Errorf("cannot retrieve the configuration: %w", multierr.Append(errors.New("other"), err))
+	assert.ErrorIs(t, wrapped, ErrTransient)
+	assert.True(t, IsRetryable(wrapped))
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(NewProviderError(ErrTransient, errors.New("unavailable"))))
+	assert.True(t, IsRetryable(fmt.Errorf("cannot retrieve %q: %w", "https://host/cfg", context.DeadlineExceeded)))
+	assert.False(t, IsRetryable(NewProviderError(ErrNotFound, errors.New("not found"))))
+	assert.False(t, IsRetryable(NewProviderError(ErrUnauthorized, errors.New("forbidden"))))
+	assert.False(t, IsRetryable(NewProviderError(ErrInvalidFormat, errors.New("invalid yaml"))))
+	assert.False(t, IsRetryable(context.Canceled))
+	assert.False(t, IsRetryable(errors.New("unclassified")))
+}
+
+func TestIsPermanent(t *testing.T) {
+	assert.True(t, IsPermanent(NewProviderError(ErrNotFound, errors.New("not found"))))
+	assert.True(t, IsPermanent(NewProviderError(ErrUnauthorized, errors.New("forbidden"))))
+	assert.True(t, IsPermanent(fmt.Errorf("cannot retrieve: %w", NewProviderError(ErrInvalidFormat, errors.New("invalid yaml")))))
+	assert.False(t, IsPermanent(NewProviderError(ErrTransient, errors.New("unavailable"))))
+	assert.False(t, IsPermanent(context.DeadlineExceeded))
+	assert.False(t, IsPermanent(errors.New("unclassified")))
+}
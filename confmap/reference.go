@@ -0,0 +1,179 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmap // import "go.opentelemetry.io/collector/confmap"
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+// configScheme is the reserved scheme of the references to other values of the configuration,
+// e.g. "${config:exporters::otlp::endpoint}". They are resolved by the Resolver once all the
+// configuration URIs are merged, so no Provider can be registered for it.
+const configScheme = "config"
+
+// configRefRegexp matches the "${config:key}" references, capturing the key.
+var configRefRegexp = regexp.MustCompile(`\$\{` + configScheme + `:([^}]*)}`)
+
+// isConfigRef returns true if the given uri is a reference to another value of the configuration.
+func isConfigRef(uri string) bool {
+	return strings.HasPrefix(uri, configScheme+":")
+}
+
+// configRefResolver resolves the references to other values of a Conf.
+type configRefResolver struct {
+	conf *Conf
+	// resolving are the keys being resolved, to detect circular references.
+	resolving map[string]bool
+	// resolved caches the resolved values by key.
+	resolved map[string]interface{}
+	// secretKeys caches whether the resolved values by key include secret values.
+	secretKeys map[string]bool
+	// secret is set when a secret value is resolved.
+	secret bool
+}
+
+// resolveConfigRefs returns the given Conf with every "${config:key}" reference replaced by the value
+// of the key, which can be any type if the reference is the entire value, or a primitive if it is
+// embedded in a longer string. The given Conf is returned as is if it has no references.
+func resolveConfigRefs(conf *Conf) (*Conf, error) {
+	r := &configRefResolver{
+		conf:       conf,
+		resolving:  make(map[string]bool),
+		resolved:   make(map[string]interface{}),
+		secretKeys: make(map[string]bool),
+	}
+	cfgMap := make(map[string]interface{})
+	changed := false
+	var secretKeys []string
+	for _, k := range conf.AllKeys() {
+		r.secret = false
+		val, valChanged, err := r.resolveValue(conf.Get(k))
+		if err != nil {
+			return nil, conf.WithPosition(k, err)
+		}
+		cfgMap[k] = val
+		changed = changed || valChanged
+		if r.secret {
+			secretKeys = append(secretKeys, k)
+		}
+	}
+	if !changed {
+		return conf, nil
+	}
+	resolved := NewFromStringMap(cfgMap)
+	resolved.mergePositions(conf.positions)
+	resolved.mergeSources(conf.sources)
+	// The values referencing secret values are secret too.
+	for _, k := range secretKeys {
+		resolved.setSecret(k)
+	}
+	return resolved, nil
+}
+
+func (r *configRefResolver) resolveValue(value interface{}) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case string:
+		return r.resolveString(v)
+	case []interface{}:
+		nslice := make([]interface{}, 0, len(v))
+		nchanged := false
+		for _, vint := range v {
+			val, changed, err := r.resolveValue(vint)
+			if err != nil {
+				return nil, false, err
+			}
+			nslice = append(nslice, val)
+			nchanged = nchanged || changed
+		}
+		return nslice, nchanged, nil
+	case map[string]interface{}:
+		nmap := map[string]interface{}{}
+		nchanged := false
+		for mk, mv := range v {
+			val, changed, err := r.resolveValue(mv)
+			if err != nil {
+				return nil, false, err
+			}
+			nmap[mk] = val
+			nchanged = nchanged || changed
+		}
+		return nmap, nchanged, nil
+	}
+	return value, false, nil
+}
+
+func (r *configRefResolver) resolveString(value string) (interface{}, bool, error) {
+	matches := configRefRegexp.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return value, false, nil
+	}
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(value) {
+		val, err := r.resolveKey(value[matches[0][2]:matches[0][3]])
+		return val, true, err
+	}
+
+	var errs error
+	expanded := configRefRegexp.ReplaceAllStringFunc(value, func(ref string) string {
+		key := ref[len("${"+configScheme+":") : len(ref)-1]
+		val, err := r.resolveKey(key)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			return ref
+		}
+		switch val.(type) {
+		case []interface{}, map[string]interface{}:
+			errs = multierr.Append(errs, fmt.Errorf("expanding %q: only primitive values can be embedded in a string", ref))
+			return ref
+		case nil:
+			return ""
+		}
+		return fmt.Sprint(val)
+	})
+	if errs != nil {
+		return nil, false, errs
+	}
+	return expanded, true, nil
+}
+
+// resolveKey returns the value of the given key, with its own references resolved.
+func (r *configRefResolver) resolveKey(key string) (interface{}, error) {
+	if val, ok := r.resolved[key]; ok {
+		r.secret = r.secret || r.secretKeys[key]
+		return val, nil
+	}
+	if r.resolving[key] {
+		return nil, fmt.Errorf("circular reference to %q", key)
+	}
+	if key == "" || !r.conf.IsSet(key) {
+		return nil, fmt.Errorf("reference to %q: key not found", key)
+	}
+	r.resolving[key] = true
+	defer delete(r.resolving, key)
+
+	outer := r.secret
+	r.secret = r.conf.hasSecret(key)
+	val, _, err := r.resolveValue(r.conf.Get(key))
+	if err != nil {
+		return nil, err
+	}
+	r.resolved[key] = val
+	r.secretKeys[key] = r.secret
+	r.secret = outer || r.secret
+	return val, nil
+}
@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveConfigRefs(t *testing.T) {
+	conf := NewFromStringMap(map[string]interface{}{
+		"common": map[string]interface{}{
+			"endpoint": "collector:4317",
+			"tenant":   "team-a",
+			"headers":  map[string]interface{}{"x-tenant": "${config:common::tenant}"},
+		},
+		"exporters": map[string]interface{}{
+			"otlp": map[string]interface{}{
+				"endpoint": "${config:common::endpoint}",
+				"headers":  "${config:common::headers}",
+			},
+			"otlphttp": map[string]interface{}{
+				"endpoint": "https://${config:exporters::otlp::endpoint}/v1",
+				"tags":     []interface{}{"tenant=${config:common::tenant}"},
+			},
+		},
+	})
+
+	resolved, err := resolveConfigRefs(conf)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"common": map[string]interface{}{
+			"endpoint": "collector:4317",
+			"tenant":   "team-a",
+			"headers":  map[string]interface{}{"x-tenant": "team-a"},
+		},
+		"exporters": map[string]interface{}{
+			"otlp": map[string]interface{}{
+				"endpoint": "collector:4317",
+				"headers":  map[string]interface{}{"x-tenant": "team-a"},
+			},
+			"otlphttp": map[string]interface{}{
+				"endpoint": "https://collector:4317/v1",
+				"tags":     []interface{}{"tenant=team-a"},
+			},
+		},
+	}, resolved.ToStringMap())
+}
+
+func TestResolveConfigRefsNoRefs(t *testing.T) {
+	conf := NewFromStringMap(map[string]interface{}{"key": "${env:VALUE}"})
+	resolved, err := resolveConfigRefs(conf)
+	require.NoError(t, err)
+	assert.Same(t, conf, resolved)
+}
+
+func TestResolveConfigRefsErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         map[string]interface{}
+		expectedErr string
+	}{
+		{
+			name:        "missing",
+			cfg:         map[string]interface{}{"a": "${config:b}"},
+			expectedErr: `reference to "b": key not found`,
+		},
+		{
+			name:        "empty",
+			cfg:         map[string]interface{}{"a": "${config:}"},
+			expectedErr: `reference to "": key not found`,
+		},
+		{
+			name:        "circular",
+			cfg:         map[string]interface{}{"a": "${config:b}", "b": "x${config:a}"},
+			expectedErr: "circular reference",
+		},
+		{
+			name:        "self_ancestor",
+			cfg:         map[string]interface{}{"a": map[string]interface{}{"b": "${config:a}"}},
+			expectedErr: `circular reference to "a"`,
+		},
+		{
+			name:        "embedded_map",
+			cfg:         map[string]interface{}{"a": map[string]interface{}{"b": "c"}, "d": "prefix ${config:a}"},
+			expectedErr: "only primitive values can be embedded in a string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveConfigRefs(NewFromStringMap(tt.cfg))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestResolverConfigRefsAcrossURIs(t *testing.T) {
+	common := newFakeProvider("common", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+		return NewRetrieved(map[string]interface{}{
+			"common": map[string]interface{}{"endpoint": "${test:host}:4317"},
+		})
+	})
+	pipeline := newFakeProvider("pipeline", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+		return NewRetrieved(map[string]interface{}{
+			"exporters": map[string]interface{}{
+				"otlp": map[string]interface{}{"endpoint": "${config:common::endpoint}"},
+			},
+		})
+	})
+	testProvider := newFakeProvider("test", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+		return NewRetrieved("collector")
+	})
+
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:      []string{"pipeline:", "common:"},
+		Providers: makeMapProvidersMap(common, pipeline, testProvider),
+	})
+	require.NoError(t, err)
+	resolver.enableExpand = true
+
+	cfgMap, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	// The references are resolved after the provider values are expanded.
+	assert.Equal(t, "collector:4317", cfgMap.Get("exporters::otlp::endpoint"))
+
+	// Without the expansion, the references are still resolved.
+	resolver.enableExpand = false
+	cfgMap, err = resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "${test:host}:4317", cfgMap.Get("exporters::otlp::endpoint"))
+}
+
+func TestResolverConfigRefsError(t *testing.T) {
+	provider := newFakeProvider("input", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+		return NewRetrieved(map[string]interface{}{"key": "${config:missing}"})
+	})
+	resolver, err := NewResolver(ResolverSettings{URIs: []string{"input:"}, Providers: makeMapProvidersMap(provider)})
+	require.NoError(t, err)
+
+	_, err = resolver.Resolve(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot resolve the configuration references")
+}
+
+func TestResolverConfigSchemeReserved(t *testing.T) {
+	_, err := NewResolver(ResolverSettings{
+		URIs:      []string{"config:"},
+		Providers: makeMapProvidersMap(&mockProvider{scheme: configScheme}),
+	})
+	assert.Error(t, err)
+}
@@ -18,9 +18,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
+	"reflect"
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"go.uber.org/multierr"
 )
@@ -29,6 +32,9 @@ import (
 // https://tools.ietf.org/id/draft-kerwin-file-scheme-07.html#syntax
 var driverLetterRegexp = regexp.MustCompile("^[A-z]:")
 
+// FallbackSeparator separates the URIs of a fallback chain, e.g. "https://cfgserver/config.yaml||file:/etc/otel/backup.yaml".
+const FallbackSeparator = "||"
+
 // Resolver resolves a configuration as a Conf.
 type Resolver struct {
 	uris       []string
@@ -39,6 +45,15 @@ type Resolver struct {
 	closers []CloseFunc
 	watcher chan error
 
+	resolveTimeout  time.Duration
+	shutdownTimeout time.Duration
+	pollInterval    time.Duration
+	watchDebounce   time.Duration
+	// debounceTimer, pendingErr and closed are guarded by the Mutex.
+	debounceTimer *time.Timer
+	pendingErr    error
+	closed        bool
+
 	enableExpand bool
 }
 
@@ -46,6 +61,9 @@ type Resolver struct {
 type ResolverSettings struct {
 	// URIs locations from where the Conf is retrieved, and merged in the given order.
 	// It is required to have at least one location.
+	//
+	// A location may be a fallback chain of URIs separated by FallbackSeparator, retrieved in order
+	// until one of them is retrieved successfully. The URIs failing to be retrieved are not watched.
 	URIs []string
 
 	// Providers is a map of pairs <scheme, Provider>.
@@ -54,6 +72,31 @@ type ResolverSettings struct {
 
 	// MapConverters is a slice of Converter.
 	Converters []Converter
+
+	// WatchDebounce is the quiet period the Resolver waits for after a change event before
+	// notifying the Watch channel. All the events received during this period are coalesced
+	// into a single notification, and their errors are combined. Every new event restarts
+	// the period. Zero disables debouncing and every event is notified.
+	WatchDebounce time.Duration
+
+	// ResolveTimeout bounds the time of every Resolve call, including retrieving all the URIs
+	// and applying the converters. Zero means no timeout other than the deadline of the
+	// context passed to Resolve.
+	ResolveTimeout time.Duration
+
+	// ShutdownTimeout bounds the time Shutdown waits for closing the active watches and for
+	// every Provider to shut down. Zero means no timeout other than the deadline of the
+	// context passed to Shutdown.
+	ShutdownTimeout time.Duration
+
+	// EnableExpand enables expanding the "${<scheme>:<opaque_data>}" references to the Providers
+	// embedded in the configuration values.
+	EnableExpand bool
+
+	// PollInterval is the default interval at which remote providers supporting watching poll for
+	// changes. It is passed to these providers as the "poll_interval" query parameter of the URIs
+	// not already setting it, so it can be overridden per URI. Zero leaves the provider default.
+	PollInterval time.Duration
 }
 
 // NewResolver returns a new Resolver that resolves configuration from multiple URIs.
@@ -84,6 +127,20 @@ func NewResolver(set ResolverSettings) (*Resolver, error) {
 		return nil, errors.New("invalid map resolver config: no Providers")
 	}
 
+	if _, ok := set.Providers[configScheme]; ok {
+		return nil, fmt.Errorf("invalid map resolver config: scheme %q is reserved for references to other configuration values", configScheme)
+	}
+
+	for _, uri := range set.URIs {
+		if strings.Contains(uri, FallbackSeparator) {
+			for _, fallback := range strings.Split(uri, FallbackSeparator) {
+				if fallback == "" {
+					return nil, fmt.Errorf("invalid map resolver config: empty URI in fallback chain %q", uri)
+				}
+			}
+		}
+	}
+
 	// Safe copy, ensures the slices and maps cannot be changed from the caller.
 	urisCopy := make([]string, len(set.URIs))
 	copy(urisCopy, set.URIs)
@@ -95,32 +152,58 @@ func NewResolver(set ResolverSettings) (*Resolver, error) {
 	copy(convertersCopy, set.Converters)
 
 	return &Resolver{
-		uris:       urisCopy,
-		providers:  providersCopy,
-		converters: convertersCopy,
-		watcher:    make(chan error, 1),
+		uris:            urisCopy,
+		providers:       providersCopy,
+		converters:      convertersCopy,
+		watcher:         make(chan error, 1),
+		enableExpand:    set.EnableExpand,
+		watchDebounce:   set.WatchDebounce,
+		resolveTimeout:  set.ResolveTimeout,
+		shutdownTimeout: set.ShutdownTimeout,
+		pollInterval:    set.PollInterval,
 	}, nil
 }
 
 // Resolve returns the configuration as a Conf, or error otherwise.
 //
+// The context cancellation and deadline, as well as the ResolverSettings.ResolveTimeout, are
+// enforced even for providers that do not honor the context: Resolve returns the context error
+// as soon as the context is done, and any value later retrieved is closed.
+//
+// The values retrieved by the previous call, and their watches, are only closed once the configuration
+// is resolved, so that the changes keep being notified if it cannot be.
+//
 // Should never be called concurrently with itself, Watch or Shutdown.
 func (mr *Resolver) Resolve(ctx context.Context) (*Conf, error) {
-	// First check if already an active watching, close that if any.
-	if err := mr.closeIfNeeded(ctx); err != nil {
+	if mr.resolveTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, mr.resolveTimeout)
+		defer cancel()
+	}
+
+	// Keep the previous watches until the configuration is resolved, so that the next changes are still
+	// notified if it cannot be, e.g. after an invalid edit. Only then, close either of the watches.
+	prevClosers := mr.closers
+	mr.closers = nil
+	retMap, err := mr.resolve(ctx)
+	if err != nil {
+		_ = mr.closeIfNeeded(ctx)
+		mr.closers = prevClosers
+		return nil, err
+	}
+	if err = mr.closeAll(ctx, prevClosers); err != nil {
 		return nil, fmt.Errorf("cannot close previous watch: %w", err)
 	}
+	return retMap, nil
+}
 
+// resolve retrieves, expands and converts the configuration, adding the CloseFunc of the retrieved
+// values to mr.closers.
+func (mr *Resolver) resolve(ctx context.Context) (*Conf, error) {
 	// Retrieves individual configurations from all URIs in the given order, and merge them in retMap.
 	retMap := New()
-	for _, uri := range mr.uris {
-		// For backwards compatibility:
-		// - empty url scheme means "file".
-		// - "^[A-z]:" also means "file"
-		if driverLetterRegexp.MatchString(uri) {
-			uri = "file:" + uri
-		}
-		ret, err := mr.retrieveValue(ctx, location{uri: uri, defaultScheme: "file"})
+	for _, chain := range mr.uris {
+		ret, uri, err := mr.retrieveFallbacks(ctx, strings.Split(chain, FallbackSeparator))
 		if err != nil {
 			return nil, fmt.Errorf("cannot retrieve the configuration: %w", err)
 		}
@@ -129,6 +212,7 @@ func (mr *Resolver) Resolve(ctx context.Context) (*Conf, error) {
 		if err != nil {
 			return nil, err
 		}
+		retCfgMap.setSource(Source{URI: uri, Secret: ret.secret})
 		if err = retMap.Merge(retCfgMap); err != nil {
 			return nil, err
 		}
@@ -136,21 +220,69 @@ func (mr *Resolver) Resolve(ctx context.Context) (*Conf, error) {
 
 	if mr.enableExpand {
 		cfgMap := make(map[string]interface{})
+		var secretKeys []string
 		for _, k := range retMap.AllKeys() {
-			val, err := mr.expandValueRecursively(ctx, retMap.Get(k))
+			val, secret, err := mr.expandValueRecursively(ctx, retMap.Get(k))
 			if err != nil {
 				return nil, err
 			}
 			cfgMap[k] = val
+			if secret {
+				secretKeys = append(secretKeys, k)
+			}
 		}
-		retMap = NewFromStringMap(cfgMap)
+		expanded := NewFromStringMap(cfgMap)
+		expanded.mergePositions(retMap.positions)
+		expanded.mergeSources(retMap.sources)
+		for _, k := range secretKeys {
+			expanded.setSecret(k)
+		}
+		retMap = expanded
+	}
+
+	// Resolve the references to other values once the configuration is merged and expanded,
+	// so they can point to values from any URI.
+	var err error
+	if retMap, err = resolveConfigRefs(retMap); err != nil {
+		return nil, fmt.Errorf("cannot resolve the configuration references: %w", err)
 	}
 
-	// Apply the converters in the given order.
+	// Apply the converters in the given order. The positions of the retrieved keys are kept,
+	// even if a converter replaces the Conf content, and the keys set or changed by every
+	// converter are recorded in their sources.
+	positions := retMap.positions
+	sources := make(map[string]Source, len(retMap.sources))
+	for k, src := range retMap.sources {
+		sources[k] = src
+	}
 	for _, confConv := range mr.converters {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("cannot convert the confmap.Conf: %w", err)
+		}
+		before := retMap.k.All()
 		if err := confConv.Convert(ctx, retMap); err != nil {
 			return nil, fmt.Errorf("cannot convert the confmap.Conf: %w", err)
 		}
+		for k, val := range retMap.k.All() {
+			if prev, ok := before[k]; !ok || !reflect.DeepEqual(prev, val) {
+				src := sources[k]
+				src.Converter = converterName(confConv)
+				sources[k] = src
+			}
+		}
+	}
+	missing := make(map[string]Position)
+	for k, pos := range positions {
+		if _, ok := retMap.positions[k]; !ok {
+			missing[k] = pos
+		}
+	}
+	retMap.mergePositions(missing)
+	retMap.sources = make(map[string]Source)
+	for _, k := range retMap.AllKeys() {
+		if src, ok := sources[k]; ok {
+			retMap.sources[k] = src
+		}
 	}
 
 	return retMap, nil
@@ -170,70 +302,153 @@ func (mr *Resolver) Watch() <-chan error {
 // Shutdown signals that the provider is no longer in use and the that should close
 // and release any resources that it may have created. It terminates the Watch channel.
 //
+// Shutdown first closes all the active watches, then shuts down every Provider. Every one of
+// these steps is bounded by the ResolverSettings.ShutdownTimeout and by the ctx, and abandoned
+// if not completed in time, so a wedged Provider cannot block Shutdown. All the errors,
+// including timeouts, are returned combined.
+//
 // Should never be called concurrently with itself or Get.
 func (mr *Resolver) Shutdown(ctx context.Context) error {
+	mr.Lock()
+	mr.closed = true
+	if mr.debounceTimer != nil {
+		mr.debounceTimer.Stop()
+	}
+	mr.Unlock()
 	close(mr.watcher)
 
 	var errs error
 	errs = multierr.Append(errs, mr.closeIfNeeded(ctx))
-	for _, p := range mr.providers {
-		errs = multierr.Append(errs, p.Shutdown(ctx))
+	for scheme, p := range mr.providers {
+		if err := mr.runWithShutdownTimeout(ctx, p.Shutdown); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("cannot shutdown provider %q: %w", scheme, err))
+		}
 	}
 
 	return errs
 }
 
+// runWithShutdownTimeout calls f, and returns the context error if it does not return in time.
+func (mr *Resolver) runWithShutdownTimeout(ctx context.Context, f func(context.Context) error) error {
+	if mr.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, mr.shutdownTimeout)
+		defer cancel()
+	}
+	if ctx.Done() == nil {
+		return f(ctx)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f(ctx)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (mr *Resolver) onChange(event *ChangeEvent) {
-	mr.watcher <- event.Error
+	if mr.watchDebounce <= 0 {
+		mr.watcher <- event.Error
+		return
+	}
+
+	mr.Lock()
+	defer mr.Unlock()
+	if mr.closed {
+		return
+	}
+	mr.pendingErr = multierr.Append(mr.pendingErr, event.Error)
+	if mr.debounceTimer != nil {
+		mr.debounceTimer.Stop()
+	}
+	mr.debounceTimer = time.AfterFunc(mr.watchDebounce, mr.flushChange)
+}
+
+// flushChange notifies the Watch channel about the changes received during the debounce period.
+func (mr *Resolver) flushChange() {
+	mr.Lock()
+	defer mr.Unlock()
+	if mr.closed {
+		return
+	}
+	err := mr.pendingErr
+	mr.pendingErr = nil
+	mr.debounceTimer = nil
+	select {
+	case mr.watcher <- err:
+	default:
+		// A notification is already waiting to be consumed, the configuration
+		// will be re-fetched anyway so coalesce this one into it.
+	}
 }
 
 func (mr *Resolver) closeIfNeeded(ctx context.Context) error {
+	err := mr.closeAll(ctx, mr.closers)
+	mr.closers = nil
+	return err
+}
+
+// closeAll calls all the CloseFunc, each bounded by the ResolverSettings.ShutdownTimeout.
+func (mr *Resolver) closeAll(ctx context.Context, closers []CloseFunc) error {
 	var err error
-	for _, ret := range mr.closers {
-		err = multierr.Append(err, ret(ctx))
+	for _, ret := range closers {
+		err = multierr.Append(err, mr.runWithShutdownTimeout(ctx, ret))
 	}
 	return err
 }
 
-func (mr *Resolver) expandValueRecursively(ctx context.Context, value interface{}) (interface{}, error) {
+// expandValueRecursively expands the references in the value until none is left, and
+// returns true if any of the expanded values was retrieved from a Provider returning secrets.
+func (mr *Resolver) expandValueRecursively(ctx context.Context, value interface{}) (interface{}, bool, error) {
+	secret := false
 	for i := 0; i < 100; i++ {
-		val, changed, err := mr.expandValue(ctx, value)
+		val, changed, err := mr.expandValue(ctx, value, &secret)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		if !changed {
-			return val, nil
+			return val, secret, nil
 		}
 		value = val
 	}
-	return nil, errors.New("too many recursive expansions")
+	return nil, false, errors.New("too many recursive expansions")
 }
 
 // Scheme name consist of a sequence of characters beginning with a letter and followed by any
 // combination of letters, digits, plus ("+"), period ("."), or hyphen ("-").
 var expandRegexp = regexp.MustCompile(`^\$\{[A-Za-z][A-Za-z0-9+.-]+:.*}$`)
 
-func (mr *Resolver) expandValue(ctx context.Context, value interface{}) (interface{}, bool, error) {
+// embeddedRegexp matches the "${scheme:opaque}" references embedded in a longer string value.
+var embeddedRegexp = regexp.MustCompile(`\$\{[A-Za-z][A-Za-z0-9+.-]+:[^}]*}`)
+
+func (mr *Resolver) expandValue(ctx context.Context, value interface{}, secret *bool) (interface{}, bool, error) {
 	switch v := value.(type) {
 	case string:
-		// If it doesn't have the format "${scheme:opaque}" no need to expand.
-		if !expandRegexp.MatchString(v) {
-			return value, false, nil
+		// If it doesn't have the format "${scheme:opaque}" check for embedded references.
+		// The references to other configuration values are resolved later, once expanded.
+		if !expandRegexp.MatchString(v) || isConfigRef(v[2:len(v)-1]) {
+			return mr.expandEmbedded(ctx, v, secret)
 		}
 		uri := v[2 : len(v)-1]
 		// At this point it is guaranteed to have a valid "scheme" based on the expandRegexp, so no default.
-		ret, err := mr.retrieveValue(ctx, location{uri: uri})
+		ret, err := mr.retrieveValue(ctx, location{uri: uri, fragment: true})
 		if err != nil {
 			return nil, false, err
 		}
 		mr.closers = append(mr.closers, ret.Close)
+		*secret = *secret || ret.secret
 		val, err := ret.AsRaw()
 		return val, true, err
 	case []interface{}:
 		nslice := make([]interface{}, 0, len(v))
 		nchanged := false
 		for _, vint := range v {
-			val, changed, err := mr.expandValue(ctx, vint)
+			val, changed, err := mr.expandValue(ctx, vint, secret)
 			if err != nil {
 				return nil, false, err
 			}
@@ -245,7 +460,7 @@ func (mr *Resolver) expandValue(ctx context.Context, value interface{}) (interfa
 		nmap := map[string]interface{}{}
 		nchanged := false
 		for mk, mv := range v {
-			val, changed, err := mr.expandValue(ctx, mv)
+			val, changed, err := mr.expandValue(ctx, mv, secret)
 			if err != nil {
 				return nil, false, err
 			}
@@ -257,9 +472,78 @@ func (mr *Resolver) expandValue(ctx context.Context, value interface{}) (interfa
 	return value, false, nil
 }
 
+// expandEmbedded replaces every "${scheme:opaque}" reference in the given string with the
+// retrieved value, which must be a primitive. The retrieved values are not included in errors,
+// since they may be secrets.
+func (mr *Resolver) expandEmbedded(ctx context.Context, value string, secret *bool) (interface{}, bool, error) {
+	if !embeddedRegexp.MatchString(value) {
+		return value, false, nil
+	}
+	var errs error
+	changed := false
+	expanded := embeddedRegexp.ReplaceAllStringFunc(value, func(ref string) string {
+		uri := ref[2 : len(ref)-1]
+		if isConfigRef(uri) {
+			return ref
+		}
+		changed = true
+		ret, err := mr.retrieveValue(ctx, location{uri: uri, fragment: true})
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			return ref
+		}
+		mr.closers = append(mr.closers, ret.Close)
+		*secret = *secret || ret.secret
+		raw, err := ret.AsRaw()
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			return ref
+		}
+		switch raw.(type) {
+		case []interface{}, map[string]interface{}:
+			errs = multierr.Append(errs, fmt.Errorf("expanding %q: only primitive values can be embedded in a string", uri))
+			return ref
+		case nil:
+			return ""
+		}
+		return fmt.Sprint(raw)
+	})
+	if errs != nil {
+		return nil, false, errs
+	}
+	return expanded, changed, nil
+}
+
+// retrieveFallbacks retrieves the first of the given URIs that can be retrieved, trying them in order,
+// and returns the URI it was retrieved from. If none can be, the errors of all the URIs are combined.
+func (mr *Resolver) retrieveFallbacks(ctx context.Context, uris []string) (*Retrieved, string, error) {
+	var errs error
+	for _, uri := range uris {
+		// For backwards compatibility:
+		// - empty url scheme means "file".
+		// - "^[A-z]:" also means "file"
+		if driverLetterRegexp.MatchString(uri) {
+			uri = "file:" + uri
+		}
+		ret, err := mr.retrieveValue(ctx, location{uri: uri, defaultScheme: "file"})
+		if err == nil {
+			return ret, uri, nil
+		}
+		errs = multierr.Append(errs, err)
+		if ctx.Err() != nil || IsPermanent(err) {
+			// No fallback can be retrieved once the context is done, and the configuration
+			// must be fixed if it is not found, not accessible or invalid.
+			break
+		}
+	}
+	return nil, "", errs
+}
+
 type location struct {
 	uri           string
 	defaultScheme string
+	// fragment is true if the value is embedded in the configuration.
+	fragment bool
 }
 
 func (mr *Resolver) retrieveValue(ctx context.Context, l location) (*Retrieved, error) {
@@ -274,5 +558,83 @@ func (mr *Resolver) retrieveValue(ctx context.Context, l location) (*Retrieved,
 	if !ok {
 		return nil, fmt.Errorf("scheme %q is not supported for uri %q", scheme, uri)
 	}
-	return p.Retrieve(ctx, uri, mr.onChange)
+	caps := GetProviderCapabilities(p)
+	if l.fragment && !caps.SupportsFragments {
+		return nil, fmt.Errorf("scheme %q does not support embedded values for uri %q", scheme, uri)
+	}
+	var watcher WatcherFunc
+	if caps.SupportsWatch {
+		watcher = mr.onChange
+		if caps.IsRemote && mr.pollInterval > 0 {
+			uri = withDefaultQueryParam(uri, PollIntervalQueryParam, mr.pollInterval.String())
+		}
+	}
+	ret, err := retrieveWithContext(ctx, p, uri, watcher)
+	if err != nil {
+		return nil, err
+	}
+	ret.secret = caps.ReturnsSecrets
+	return ret, nil
+}
+
+// SetPollInterval changes the ResolverSettings.PollInterval used by the next calls to Resolve.
+// Should never be called concurrently with Resolve.
+func (mr *Resolver) SetPollInterval(pollInterval time.Duration) {
+	mr.pollInterval = pollInterval
+}
+
+// retrieveWithContext calls Provider.Retrieve, but returns the context error as soon as the
+// context is done, even if the provider ignores the context.
+func retrieveWithContext(ctx context.Context, p Provider, uri string, watcher WatcherFunc) (*Retrieved, error) {
+	if ctx.Done() == nil {
+		// Context can never be canceled, no need to wait asynchronously.
+		return p.Retrieve(ctx, uri, watcher)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("cannot retrieve %q: %w", uri, err)
+	}
+
+	type result struct {
+		ret *Retrieved
+		err error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		ret, err := p.Retrieve(ctx, uri, watcher)
+		resCh <- result{ret: ret, err: err}
+	}()
+
+	select {
+	case res := <-resCh:
+		return res.ret, res.err
+	case <-ctx.Done():
+		go func() {
+			// Release the resources of the abandoned retrieve, once it returns.
+			if res := <-resCh; res.ret != nil {
+				_ = res.ret.Close(context.Background())
+			}
+		}()
+		return nil, fmt.Errorf("cannot retrieve %q: %w", uri, ctx.Err())
+	}
+}
+
+// PollIntervalQueryParam is the query parameter of the URIs setting the interval at which the
+// remote providers supporting watching poll for changes, see ResolverSettings.PollInterval.
+const PollIntervalQueryParam = "poll_interval"
+
+// withDefaultQueryParam adds the given query parameter to the uri, unless already set.
+func withDefaultQueryParam(uri, name, value string) string {
+	sep := "?"
+	if idx := strings.IndexByte(uri, '?'); idx != -1 {
+		params, err := url.ParseQuery(uri[idx+1:])
+		if err != nil || params.Has(name) {
+			// Invalid queries are reported by the provider.
+			return uri
+		}
+		sep = "&"
+		if idx == len(uri)-1 {
+			sep = ""
+		}
+	}
+	return uri + sep + url.QueryEscape(name) + "=" + url.QueryEscape(value)
 }
@@ -20,9 +20,11 @@ import (
 	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
 )
 
 type mockProvider struct {
@@ -275,6 +277,41 @@ func TestResolver(t *testing.T) {
 	assert.NoError(t, errC)
 }
 
+type replaceConverter struct{}
+
+func (replaceConverter) Convert(_ context.Context, conf *Conf) error {
+	*conf = *NewFromStringMap(conf.ToStringMap())
+	return nil
+}
+
+func TestResolverPositions(t *testing.T) {
+	provider := newFakeProvider("mock", func(_ context.Context, uri string, _ WatcherFunc) (*Retrieved, error) {
+		return NewRetrieved(map[string]interface{}{"processors": map[string]interface{}{uri[5:]: nil}},
+			WithRetrievedPositions(map[string]Position{
+				"processors":             {URI: uri, Line: 1, Column: 1},
+				"processors::" + uri[5:]: {URI: uri, Line: 2, Column: 3},
+			}))
+	})
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:       []string{"mock:batch", "mock:memory_limiter"},
+		Providers:  makeMapProvidersMap(provider),
+		Converters: []Converter{replaceConverter{}},
+	})
+	require.NoError(t, err)
+	conf, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+
+	// Positions are kept even if the converter replaces the Conf.
+	pos, ok := conf.Position("processors::batch")
+	assert.True(t, ok)
+	assert.Equal(t, Position{URI: "mock:batch", Line: 2, Column: 3}, pos)
+	pos, ok = conf.Position("processors")
+	assert.True(t, ok)
+	assert.Equal(t, Position{URI: "mock:memory_limiter", Line: 1, Column: 1}, pos)
+
+	assert.NoError(t, resolver.Shutdown(context.Background()))
+}
+
 func TestResolverNoLocations(t *testing.T) {
 	_, err := NewResolver(ResolverSettings{
 		URIs:       []string{},
@@ -283,6 +320,77 @@ func TestResolverNoLocations(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestResolverFallbacks(t *testing.T) {
+	var retrieved []string
+	failing := newFakeProvider("failing", func(_ context.Context, uri string, _ WatcherFunc) (*Retrieved, error) {
+		retrieved = append(retrieved, uri)
+		return nil, errors.New("unreachable " + uri)
+	})
+	mock := newFakeProvider("mock", func(_ context.Context, uri string, _ WatcherFunc) (*Retrieved, error) {
+		retrieved = append(retrieved, uri)
+		return NewRetrieved(map[string]interface{}{"key": uri})
+	})
+
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:      []string{"failing:primary||mock:backup||mock:unused"},
+		Providers: makeMapProvidersMap(failing, mock),
+	})
+	require.NoError(t, err)
+	conf, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"key": "mock:backup"}, conf.ToStringMap())
+	assert.Equal(t, []string{"failing:primary", "mock:backup"}, retrieved)
+	src, ok := conf.Source("key")
+	require.True(t, ok)
+	assert.Equal(t, "mock:backup", src.URI)
+
+	resolver, err = NewResolver(ResolverSettings{
+		URIs:      []string{"failing:primary||failing:backup"},
+		Providers: makeMapProvidersMap(failing, mock),
+	})
+	require.NoError(t, err)
+	_, err = resolver.Resolve(context.Background())
+	assert.EqualError(t, err, "cannot retrieve the configuration: unreachable failing:primary; unreachable failing:backup")
+
+	_, err = NewResolver(ResolverSettings{
+		URIs:      []string{"mock:primary||"},
+		Providers: makeMapProvidersMap(mock),
+	})
+	assert.EqualError(t, err, `invalid map resolver config: empty URI in fallback chain "mock:primary||"`)
+
+	// The fallbacks are not retrieved after a permanent error.
+	for _, class := range []error{ErrNotFound, ErrUnauthorized, ErrInvalidFormat} {
+		retrieved = nil
+		permanent := newFakeProvider("permanent", func(_ context.Context, uri string, _ WatcherFunc) (*Retrieved, error) {
+			retrieved = append(retrieved, uri)
+			return nil, NewProviderError(class, errors.New("cannot retrieve "+uri))
+		})
+		resolver, err = NewResolver(ResolverSettings{
+			URIs:      []string{"permanent:primary||mock:backup"},
+			Providers: makeMapProvidersMap(permanent, mock),
+		})
+		require.NoError(t, err)
+		_, err = resolver.Resolve(context.Background())
+		assert.ErrorIs(t, err, class)
+		assert.Equal(t, []string{"permanent:primary"}, retrieved)
+	}
+
+	// The fallbacks are retrieved after a transient error.
+	retrieved = nil
+	transient := newFakeProvider("transient", func(_ context.Context, uri string, _ WatcherFunc) (*Retrieved, error) {
+		retrieved = append(retrieved, uri)
+		return nil, NewProviderError(ErrTransient, errors.New("unavailable "+uri))
+	})
+	resolver, err = NewResolver(ResolverSettings{
+		URIs:      []string{"transient:primary||mock:backup"},
+		Providers: makeMapProvidersMap(transient, mock),
+	})
+	require.NoError(t, err)
+	_, err = resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"transient:primary", "mock:backup"}, retrieved)
+}
+
 func TestResolverNoProviders(t *testing.T) {
 	_, err := NewResolver(ResolverSettings{
 		URIs:       []string{filepath.Join("testdata", "config.yaml")},
@@ -314,6 +422,268 @@ func TestResolverShutdownClosesWatch(t *testing.T) {
 	watcherWG.Wait()
 }
 
+func TestResolverWatchDebounce(t *testing.T) {
+	var watcherFn WatcherFunc
+	provider := newFakeProvider("mock", func(_ context.Context, _ string, watcher WatcherFunc) (*Retrieved, error) {
+		watcherFn = watcher
+		return NewRetrieved(map[string]interface{}{})
+	})
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:          []string{"mock:"},
+		Providers:     makeMapProvidersMap(provider),
+		WatchDebounce: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	_, errN := resolver.Resolve(context.Background())
+	require.NoError(t, errN)
+
+	// A burst of events results in a single notification.
+	for i := 0; i < 10; i++ {
+		watcherFn(&ChangeEvent{})
+	}
+	errW := <-resolver.Watch()
+	assert.NoError(t, errW)
+	select {
+	case <-resolver.Watch():
+		assert.Fail(t, "unexpected second notification")
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	// Errors received during the debounce period are combined.
+	errW1 := errors.New("err1")
+	errW2 := errors.New("err2")
+	watcherFn(&ChangeEvent{Error: errW1})
+	watcherFn(&ChangeEvent{})
+	watcherFn(&ChangeEvent{Error: errW2})
+	errW = <-resolver.Watch()
+	assert.ErrorIs(t, errW, errW1)
+	assert.ErrorIs(t, errW, errW2)
+
+	// Events pending at shutdown are not notified.
+	watcherFn(&ChangeEvent{})
+	assert.NoError(t, resolver.Shutdown(context.Background()))
+	_, ok := <-resolver.Watch()
+	assert.False(t, ok)
+	// Events after shutdown are ignored.
+	watcherFn(&ChangeEvent{})
+}
+
+func TestResolverKeepsWatchOnFailure(t *testing.T) {
+	var closed []int
+	var fail bool
+	calls := 0
+	provider := newFakeProvider("mock", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+		calls++
+		if fail {
+			return nil, errors.New("invalid config")
+		}
+		n := calls
+		return NewRetrieved(map[string]interface{}{}, WithRetrievedClose(func(context.Context) error {
+			closed = append(closed, n)
+			return nil
+		}))
+	})
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:      []string{"mock:"},
+		Providers: makeMapProvidersMap(provider),
+	})
+	require.NoError(t, err)
+
+	_, err = resolver.Resolve(context.Background())
+	require.NoError(t, err)
+
+	// The watch of the first configuration is kept while the configuration cannot be resolved.
+	fail = true
+	_, err = resolver.Resolve(context.Background())
+	require.Error(t, err)
+	assert.Empty(t, closed)
+
+	// And closed once it is resolved again.
+	fail = false
+	_, err = resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, closed)
+
+	assert.NoError(t, resolver.Shutdown(context.Background()))
+	assert.Equal(t, []int{1, 3}, closed)
+}
+
+func TestResolverResolveTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	closed := make(chan struct{})
+	// Provider that ignores the context.
+	provider := newFakeProvider("mock", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+		<-unblock
+		return NewRetrieved(map[string]interface{}{}, WithRetrievedClose(func(context.Context) error {
+			close(closed)
+			return nil
+		}))
+	})
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:           []string{"mock:"},
+		Providers:      makeMapProvidersMap(provider),
+		ResolveTimeout: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	_, err = resolver.Resolve(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The value retrieved after the deadline is closed.
+	close(unblock)
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		assert.Fail(t, "retrieved value not closed")
+	}
+	assert.NoError(t, resolver.Shutdown(context.Background()))
+}
+
+func TestResolverResolveCanceledContext(t *testing.T) {
+	provider := newFakeProvider("mock", func(ctx context.Context, _ string, _ WatcherFunc) (*Retrieved, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:      []string{"mock:"},
+		Providers: makeMapProvidersMap(provider),
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err = resolver.Resolve(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Already canceled context, provider is not called.
+	_, err = resolver.Resolve(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, resolver.Shutdown(context.Background()))
+}
+
+type capabilitiesProvider struct {
+	Provider
+	caps ProviderCapabilities
+}
+
+func (c *capabilitiesProvider) Capabilities() ProviderCapabilities {
+	return c.caps
+}
+
+func TestResolverProviderCapabilities(t *testing.T) {
+	var gotWatcher WatcherFunc
+	noWatch := &capabilitiesProvider{
+		Provider: newFakeProvider("nowatch", func(_ context.Context, _ string, watcher WatcherFunc) (*Retrieved, error) {
+			gotWatcher = watcher
+			return NewRetrieved(map[string]interface{}{"key": "${nofragments:value}"})
+		}),
+		caps: ProviderCapabilities{IsRemote: true},
+	}
+	noFragments := &capabilitiesProvider{
+		Provider: newFakeProvider("nofragments", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+			return NewRetrieved("value")
+		}),
+	}
+	assert.Equal(t, ProviderCapabilities{SupportsWatch: true, SupportsFragments: true}, GetProviderCapabilities(&mockProvider{}))
+	assert.Equal(t, ProviderCapabilities{IsRemote: true}, GetProviderCapabilities(noWatch))
+
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:      []string{"nowatch:"},
+		Providers: makeMapProvidersMap(noWatch, noFragments),
+	})
+	require.NoError(t, err)
+	resolver.enableExpand = true
+	_, err = resolver.Resolve(context.Background())
+	assert.EqualError(t, err, `scheme "nofragments" does not support embedded values for uri "nofragments:value"`)
+	// No watcher is passed to providers not supporting watching.
+	assert.Nil(t, gotWatcher)
+	assert.NoError(t, resolver.Shutdown(context.Background()))
+}
+
+func TestResolverPollInterval(t *testing.T) {
+	var uris []string
+	retrieve := func(_ context.Context, uri string, _ WatcherFunc) (*Retrieved, error) {
+		uris = append(uris, uri)
+		return NewRetrieved(map[string]interface{}{})
+	}
+	remote := &capabilitiesProvider{
+		Provider: newFakeProvider("remote", retrieve),
+		caps:     ProviderCapabilities{SupportsWatch: true, IsRemote: true},
+	}
+	remoteNoWatch := &capabilitiesProvider{
+		Provider: newFakeProvider("remotenowatch", retrieve),
+		caps:     ProviderCapabilities{IsRemote: true},
+	}
+	local := newFakeProvider("local", retrieve)
+
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:         []string{"remote:a", "remote:b?region=eu", "remote:c?poll_interval=5s", "remotenowatch:d", "local:e"},
+		Providers:    makeMapProvidersMap(remote, remoteNoWatch, local),
+		PollInterval: time.Minute,
+	})
+	require.NoError(t, err)
+	_, err = resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"remote:a?poll_interval=1m0s", "remote:b?region=eu&poll_interval=1m0s", "remote:c?poll_interval=5s", "remotenowatch:d", "local:e"}, uris)
+
+	uris = nil
+	resolver.SetPollInterval(0)
+	_, err = resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"remote:a", "remote:b?region=eu", "remote:c?poll_interval=5s", "remotenowatch:d", "local:e"}, uris)
+	assert.NoError(t, resolver.Shutdown(context.Background()))
+}
+
+func TestWithDefaultQueryParam(t *testing.T) {
+	assert.Equal(t, "s:a?k=v", withDefaultQueryParam("s:a", "k", "v"))
+	assert.Equal(t, "s:a?k=v", withDefaultQueryParam("s:a?", "k", "v"))
+	assert.Equal(t, "s:a?x=y&k=v", withDefaultQueryParam("s:a?x=y", "k", "v"))
+	assert.Equal(t, "s:a?k=w", withDefaultQueryParam("s:a?k=w", "k", "v"))
+	assert.Equal(t, "s:a?%zz", withDefaultQueryParam("s:a?%zz", "k", "v"))
+}
+
+type blockingShutdownProvider struct {
+	Provider
+	unblock chan struct{}
+}
+
+func (b *blockingShutdownProvider) Shutdown(context.Context) error {
+	<-b.unblock
+	return nil
+}
+
+func TestResolverShutdownTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	wedged := &blockingShutdownProvider{
+		Provider: newFakeProvider("wedged", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+			return NewRetrieved(map[string]interface{}{}, WithRetrievedClose(func(context.Context) error {
+				<-unblock
+				return nil
+			}))
+		}),
+		unblock: unblock,
+	}
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:            []string{"wedged:"},
+		Providers:       map[string]Provider{"wedged": wedged, "mock": &mockProvider{errS: errors.New("shutdown_err")}},
+		ShutdownTimeout: 20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	_, err = resolver.Resolve(context.Background())
+	require.NoError(t, err)
+
+	err = resolver.Shutdown(context.Background())
+	errs := multierr.Errors(err)
+	require.Len(t, errs, 3)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), `cannot shutdown provider "wedged"`)
+	assert.Contains(t, err.Error(), `cannot shutdown provider "mock": shutdown_err`)
+}
+
 func TestResolverExpandEnvVars(t *testing.T) {
 	var testCases = []struct {
 		name string // test case name (also file name containing config yaml)
@@ -399,6 +769,63 @@ func TestResolverExpandMapAndSliceValues(t *testing.T) {
 	assert.Equal(t, expectedMap, cfgMap.ToStringMap())
 }
 
+func TestResolverExpandEmbeddedValues(t *testing.T) {
+	provider := newFakeProvider("input", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+		return NewRetrieved(map[string]interface{}{
+			"endpoint": "https://${test:host}:${test:port}/path",
+			"header":   "Bearer ${test:secret/data/otel#token}"})
+	})
+	testProvider := newFakeProvider("test", func(_ context.Context, uri string, _ WatcherFunc) (*Retrieved, error) {
+		switch uri {
+		case "test:host":
+			return NewRetrieved("localhost")
+		case "test:port":
+			return NewRetrieved(4317)
+		case "test:secret/data/otel#token":
+			return NewRetrieved("s3cr3t")
+		}
+		return nil, errors.New("unexpected uri")
+	})
+
+	resolver, err := NewResolver(ResolverSettings{URIs: []string{"input:"}, Providers: makeMapProvidersMap(provider, testProvider), Converters: nil})
+	require.NoError(t, err)
+	resolver.enableExpand = true
+
+	cfgMap, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"endpoint": "https://localhost:4317/path",
+		"header":   "Bearer s3cr3t"}, cfgMap.ToStringMap())
+}
+
+func TestResolverExpandEmbeddedNonPrimitiveError(t *testing.T) {
+	provider := newFakeProvider("input", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+		return NewRetrieved(map[string]interface{}{"test": "prefix ${test:MAP}"})
+	})
+	testProvider := newFakeProvider("test", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+		return NewRetrieved(map[string]interface{}{"key": "secret"})
+	})
+
+	resolver, err := NewResolver(ResolverSettings{URIs: []string{"input:"}, Providers: makeMapProvidersMap(provider, testProvider), Converters: nil})
+	require.NoError(t, err)
+	resolver.enableExpand = true
+
+	_, err = resolver.Resolve(context.Background())
+	require.Error(t, err)
+	// Retrieved values are not leaked in errors.
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestResolverEnableExpand(t *testing.T) {
+	resolver, err := NewResolver(ResolverSettings{URIs: []string{"input:"}, Providers: makeMapProvidersMap(&mockProvider{})})
+	require.NoError(t, err)
+	assert.False(t, resolver.enableExpand)
+
+	resolver, err = NewResolver(ResolverSettings{URIs: []string{"input:"}, Providers: makeMapProvidersMap(&mockProvider{}), EnableExpand: true})
+	require.NoError(t, err)
+	assert.True(t, resolver.enableExpand)
+}
+
 func TestResolverInfiniteExpand(t *testing.T) {
 	const receiverValue = "${test:VALUE}"
 	provider := newFakeProvider("input", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
@@ -0,0 +1,234 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmap // import "go.opentelemetry.io/collector/confmap"
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+// SchemaType is the type of the values accepted by a Schema.
+type SchemaType string
+
+const (
+	// SchemaTypeAny accepts any value.
+	SchemaTypeAny SchemaType = ""
+	// SchemaTypeString accepts the strings and the other primitive values, converted when unmarshaled.
+	SchemaTypeString SchemaType = "string"
+	// SchemaTypeInteger accepts the integers, and the strings holding one.
+	SchemaTypeInteger SchemaType = "integer"
+	// SchemaTypeNumber accepts the numbers, and the strings holding one.
+	SchemaTypeNumber SchemaType = "number"
+	// SchemaTypeBoolean accepts the booleans, and the strings holding one.
+	SchemaTypeBoolean SchemaType = "boolean"
+	// SchemaTypeObject accepts the maps.
+	SchemaTypeObject SchemaType = "object"
+	// SchemaTypeArray accepts the lists, and the single values unmarshaled as lists of one element.
+	SchemaTypeArray SchemaType = "array"
+)
+
+// Schema describes the constraints on a configuration value, after the model of JSON Schema. The
+// values are checked as they are unmarshaled, weakly typed: e.g. the string "10", as set by the --set
+// flags, is a valid integer. Null values are always valid, as the unset ones.
+//
+// Notice: This API is experimental.
+type Schema struct {
+	// Type of the value, SchemaTypeAny if not set.
+	Type SchemaType
+	// Properties are the schemas of the keys of an object.
+	Properties map[string]*Schema
+	// Required are the keys an object must have, with non-null values.
+	Required []string
+	// Items is the schema of the elements of an array.
+	Items *Schema
+	// Enum are the accepted values, compared with their string representations, if not empty.
+	Enum []interface{}
+	// Minimum and Maximum bound the integers and numbers, if set.
+	Minimum *float64
+	Maximum *float64
+}
+
+// SchemaError is an error about a configuration value not matching its Schema.
+type SchemaError struct {
+	// Key is the configuration key of the value, with KeyDelimiter separators.
+	Key string
+	// Message describes the constraint the value does not match, e.g. "must be a string".
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	return e.Key + " " + e.Message
+}
+
+// Validate checks the value of the given key against the schema, and returns all the
+// *SchemaError found, combined, in a stable order.
+func (s *Schema) Validate(key string, value interface{}) error {
+	if s == nil || value == nil {
+		return nil
+	}
+	if err := s.validateType(key, value); err != nil {
+		return err
+	}
+
+	var errs error
+	if len(s.Enum) != 0 && !inEnum(value, s.Enum) {
+		vals := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			vals = append(vals, fmt.Sprint(v))
+		}
+		errs = multierr.Append(errs, &SchemaError{Key: key, Message: "must be one of [" + strings.Join(vals, ", ") + "]"})
+	}
+	if s.Minimum != nil || s.Maximum != nil {
+		if num, ok := toFloat(value); ok {
+			if s.Minimum != nil && num < *s.Minimum {
+				errs = multierr.Append(errs, &SchemaError{Key: key, Message: fmt.Sprintf("must be at least %v", *s.Minimum)})
+			}
+			if s.Maximum != nil && num > *s.Maximum {
+				errs = multierr.Append(errs, &SchemaError{Key: key, Message: fmt.Sprintf("must be at most %v", *s.Maximum)})
+			}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if v[name] == nil {
+				errs = multierr.Append(errs, &SchemaError{Key: key + KeyDelimiter + name, Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			errs = multierr.Append(errs, s.Properties[name].Validate(key+KeyDelimiter+name, v[name]))
+		}
+	case []interface{}:
+		for i, item := range v {
+			errs = multierr.Append(errs, s.Items.Validate(key+KeyDelimiter+strconv.Itoa(i), item))
+		}
+	default:
+		if s.Type == SchemaTypeArray {
+			errs = multierr.Append(errs, s.Items.Validate(key, value))
+		}
+	}
+	return errs
+}
+
+// validateType returns a *SchemaError if the value is not of the type of the schema.
+func (s *Schema) validateType(key string, value interface{}) error {
+	_, isMap := value.(map[string]interface{})
+	_, isList := value.([]interface{})
+	var valid bool
+	var article string
+	switch s.Type {
+	case SchemaTypeAny:
+		return nil
+	case SchemaTypeString:
+		valid, article = !isMap && !isList, "a"
+	case SchemaTypeInteger:
+		valid, article = isInteger(value), "an"
+	case SchemaTypeNumber:
+		_, valid = toFloat(value)
+		article = "a"
+	case SchemaTypeBoolean:
+		valid, article = isBoolean(value), "a"
+	case SchemaTypeObject:
+		valid, article = isMap, "an"
+	case SchemaTypeArray:
+		valid, article = !isMap, "an"
+	default:
+		return &SchemaError{Key: key, Message: fmt.Sprintf("has an unsupported schema type %q", s.Type)}
+	}
+	if !valid {
+		return &SchemaError{Key: key, Message: fmt.Sprintf("must be %s %s", article, s.Type)}
+	}
+	return nil
+}
+
+func isInteger(value interface{}) bool {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	case float32:
+		return float32(int64(v)) == v
+	case float64:
+		return float64(int64(v)) == v
+	case string:
+		_, err := strconv.ParseInt(strings.TrimSpace(v), 0, 64)
+		return err == nil
+	}
+	return false
+}
+
+func isBoolean(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return true
+	case string:
+		_, err := strconv.ParseBool(strings.TrimSpace(v))
+		return err == nil
+	}
+	return false
+}
+
+// toFloat returns the value as a float64 if it is a number, or a string holding one.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	str := fmt.Sprint(value)
+	for _, v := range enum {
+		if fmt.Sprint(v) == str {
+			return true
+		}
+	}
+	return false
+}
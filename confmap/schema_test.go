@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/multierr"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	minPort, maxPort := 1.0, 65535.0
+	schema := &Schema{
+		Type:     SchemaTypeObject,
+		Required: []string{"endpoint"},
+		Properties: map[string]*Schema{
+			"endpoint":    {Type: SchemaTypeString},
+			"port":        {Type: SchemaTypeInteger, Minimum: &minPort, Maximum: &maxPort},
+			"ratio":       {Type: SchemaTypeNumber},
+			"insecure":    {Type: SchemaTypeBoolean},
+			"compression": {Type: SchemaTypeString, Enum: []interface{}{"gzip", "none"}},
+			"headers":     {Type: SchemaTypeObject},
+			"brokers":     {Type: SchemaTypeArray, Items: &Schema{Type: SchemaTypeString}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		value  interface{}
+		errors []string
+	}{
+		{
+			name: "valid",
+			value: map[string]interface{}{
+				"endpoint":    "localhost:4317",
+				"port":        4317,
+				"ratio":       0.5,
+				"insecure":    true,
+				"compression": "gzip",
+				"headers":     map[string]interface{}{"x-tenant": "t1"},
+				"brokers":     []interface{}{"a:9092", "b:9092"},
+				"unknown":     map[string]interface{}{"any": "value"},
+			},
+		},
+		{
+			name: "weakly_typed",
+			value: map[string]interface{}{
+				"endpoint": 4317,
+				"port":     "4317",
+				"ratio":    "0.5",
+				"insecure": "true",
+				"brokers":  "a:9092",
+			},
+		},
+		{
+			name:  "null_values",
+			value: map[string]interface{}{"endpoint": "localhost:4317", "port": nil, "headers": nil},
+		},
+		{
+			name: "invalid",
+			value: map[string]interface{}{
+				"endpoint":    map[string]interface{}{"host": "localhost"},
+				"port":        70000,
+				"ratio":       "half",
+				"insecure":    "maybe",
+				"compression": "zstd",
+				"headers":     []interface{}{"x-tenant"},
+				"brokers":     []interface{}{"a:9092", []interface{}{"b:9092"}},
+			},
+			errors: []string{
+				"exporters::otlp::brokers::1 must be a string",
+				"exporters::otlp::compression must be one of [gzip, none]",
+				"exporters::otlp::endpoint must be a string",
+				"exporters::otlp::headers must be an object",
+				"exporters::otlp::insecure must be a boolean",
+				"exporters::otlp::port must be at most 65535",
+				"exporters::otlp::ratio must be a number",
+			},
+		},
+		{
+			name:   "missing_required",
+			value:  map[string]interface{}{"endpoint": nil, "port": "0"},
+			errors: []string{"exporters::otlp::endpoint is required", "exporters::otlp::port must be at least 1"},
+		},
+		{
+			name:   "not_an_object",
+			value:  "localhost:4317",
+			errors: []string{"exporters::otlp must be an object"},
+		},
+		{
+			name:  "null",
+			value: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msgs []string
+			for _, err := range multierr.Errors(schema.Validate("exporters::otlp", tt.value)) {
+				assert.IsType(t, &SchemaError{}, err)
+				msgs = append(msgs, err.Error())
+			}
+			assert.Equal(t, tt.errors, msgs)
+		})
+	}
+}
+
+func TestSchemaValidateNil(t *testing.T) {
+	var schema *Schema
+	assert.NoError(t, schema.Validate("receivers::otlp", map[string]interface{}{"endpoint": 1}))
+}
+
+func TestSchemaValidateUnsupportedType(t *testing.T) {
+	schema := &Schema{Type: "date"}
+	assert.EqualError(t, schema.Validate("receivers::otlp", "2022-01-01"), `receivers::otlp has an unsupported schema type "date"`)
+}
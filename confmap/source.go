@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmap // import "go.opentelemetry.io/collector/confmap"
+
+import (
+	"path"
+	"reflect"
+	"strings"
+)
+
+// Source is where the value of a configuration key was last set.
+type Source struct {
+	// URI of the configuration source that last set the key, as given in ResolverSettings.URIs, or the
+	// URI retrieved from a fallback chain.
+	// Empty if the key was added by a Converter.
+	URI string
+	// Converter is the name of the package of the last Converter that set or changed the value
+	// after it was retrieved, empty if none.
+	Converter string
+	// Secret is true if the value was retrieved, as a whole or through an embedded or config
+	// reference, from a Provider whose capabilities report ReturnsSecrets.
+	Secret bool
+}
+
+// String returns the source formatted as "<uri>", "<uri> via <converter>" or "converter <converter>".
+func (s Source) String() string {
+	switch {
+	case s.Converter == "":
+		return s.URI
+	case s.URI == "":
+		return "converter " + s.Converter
+	}
+	return s.URI + " via " + s.Converter
+}
+
+// Source returns where the value of the key was last set, if known. Sources are known for the keys
+// holding values, not for their parents, in the configurations returned by Resolver.Resolve.
+func (l *Conf) Source(key string) (Source, bool) {
+	src, ok := l.sources[key]
+	return src, ok
+}
+
+// setSource sets the source of all the keys of the Conf.
+func (l *Conf) setSource(src Source) {
+	sources := make(map[string]Source)
+	for _, k := range l.AllKeys() {
+		sources[k] = src
+	}
+	l.mergeSources(sources)
+}
+
+// setSecret marks the value of the key, and the values of the keys under it, as secret.
+// The keys under it without a source get the source of the key.
+func (l *Conf) setSecret(key string) {
+	parent := l.sources[key]
+	parent.Secret = true
+	sources := make(map[string]Source)
+	for _, k := range l.AllKeys() {
+		if k != key && !strings.HasPrefix(k, key+KeyDelimiter) {
+			continue
+		}
+		src, ok := l.sources[k]
+		if !ok {
+			src = parent
+		}
+		src.Secret = true
+		sources[k] = src
+	}
+	l.mergeSources(sources)
+}
+
+// hasSecret returns true if the value of the key, or the value of any key under it, is secret.
+func (l *Conf) hasSecret(key string) bool {
+	for k, src := range l.sources {
+		if src.Secret && (k == key || strings.HasPrefix(k, key+KeyDelimiter)) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSources adds the given sources to the Conf, overriding the ones of the same keys.
+func (l *Conf) mergeSources(sources map[string]Source) {
+	if len(sources) == 0 {
+		return
+	}
+	if l.sources == nil {
+		l.sources = make(map[string]Source, len(sources))
+	}
+	for k, src := range sources {
+		l.sources[k] = src
+	}
+}
+
+// converterName returns the name of the package of the Converter.
+func converterName(c Converter) string {
+	t := reflect.TypeOf(c)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return path.Base(t.PkgPath())
+}
@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package confmap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceString(t *testing.T) {
+	assert.Equal(t, "file:config.yaml", Source{URI: "file:config.yaml"}.String())
+	assert.Equal(t, "file:config.yaml via expandconverter", Source{URI: "file:config.yaml", Converter: "expandconverter"}.String())
+	assert.Equal(t, "converter overwritepropertiesconverter", Source{Converter: "overwritepropertiesconverter"}.String())
+}
+
+func TestConfMergeAndSubSources(t *testing.T) {
+	base := NewFromStringMap(map[string]interface{}{
+		"exporters::otlp::endpoint": "localhost:4317",
+		"exporters::otlp::timeout":  "5s",
+	})
+	base.setSource(Source{URI: "file:base.yaml"})
+	override := NewFromStringMap(map[string]interface{}{"exporters::otlp::endpoint": "remote:4317"})
+	override.setSource(Source{URI: "file:override.yaml"})
+	require.NoError(t, base.Merge(override))
+
+	src, ok := base.Source("exporters::otlp::endpoint")
+	assert.True(t, ok)
+	assert.Equal(t, Source{URI: "file:override.yaml"}, src)
+	src, ok = base.Source("exporters::otlp::timeout")
+	assert.True(t, ok)
+	assert.Equal(t, Source{URI: "file:base.yaml"}, src)
+	_, ok = base.Source("exporters::otlp")
+	assert.False(t, ok)
+
+	sub, err := base.Sub("exporters")
+	require.NoError(t, err)
+	src, ok = sub.Source("otlp::endpoint")
+	assert.True(t, ok)
+	assert.Equal(t, Source{URI: "file:override.yaml"}, src)
+}
+
+type setConverter struct {
+	key string
+	val interface{}
+}
+
+func (c setConverter) Convert(_ context.Context, conf *Conf) error {
+	return conf.Merge(NewFromStringMap(map[string]interface{}{c.key: c.val}))
+}
+
+func TestResolverSources(t *testing.T) {
+	provider := newFakeProvider("mock", func(_ context.Context, uri string, _ WatcherFunc) (*Retrieved, error) {
+		if uri == "mock:base" {
+			return NewRetrieved(map[string]interface{}{
+				"processors": map[string]interface{}{
+					"batch":          map[string]interface{}{"timeout": "1s", "send_batch_size": 100},
+					"memory_limiter": map[string]interface{}{"limit_mib": 100},
+				},
+			})
+		}
+		return NewRetrieved(map[string]interface{}{
+			"processors": map[string]interface{}{
+				"batch": map[string]interface{}{"timeout": "5s"},
+			},
+		})
+	})
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:      []string{"mock:base", "mock:overlay"},
+		Providers: makeMapProvidersMap(provider),
+		Converters: []Converter{
+			replaceConverter{},
+			setConverter{key: "processors::memory_limiter::limit_mib", val: 200},
+			setConverter{key: "processors::memory_limiter::check_interval", val: "1s"},
+			setConverter{key: "processors::batch::timeout", val: "5s"},
+		},
+	})
+	require.NoError(t, err)
+	conf, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+
+	expected := map[string]Source{
+		"processors::batch::send_batch_size":         {URI: "mock:base"},
+		"processors::batch::timeout":                 {URI: "mock:overlay"}, // Set to the same value by a converter.
+		"processors::memory_limiter::limit_mib":      {URI: "mock:base", Converter: "confmap"},
+		"processors::memory_limiter::check_interval": {Converter: "confmap"},
+	}
+	for k, expectedSrc := range expected {
+		src, ok := conf.Source(k)
+		assert.True(t, ok, k)
+		assert.Equal(t, expectedSrc, src, k)
+	}
+
+	assert.NoError(t, resolver.Shutdown(context.Background()))
+}
+
+func TestResolverSecretSources(t *testing.T) {
+	provider := newFakeProvider("mock", func(context.Context, string, WatcherFunc) (*Retrieved, error) {
+		return NewRetrieved(map[string]interface{}{
+			"exporters": map[string]interface{}{
+				"otlp": map[string]interface{}{
+					"endpoint": "localhost:4317",
+					"headers":  "${secret:headers}",
+					"auth":     "Bearer ${secret:token}",
+					"copy":     "${config:exporters::otlp::auth}",
+				},
+			},
+		})
+	})
+	secrets := &capabilitiesProvider{
+		Provider: newFakeProvider("secret", func(_ context.Context, uri string, _ WatcherFunc) (*Retrieved, error) {
+			if uri == "secret:headers" {
+				return NewRetrieved(map[string]interface{}{"api-key": "key"})
+			}
+			return NewRetrieved("token")
+		}),
+		caps: ProviderCapabilities{SupportsFragments: true, ReturnsSecrets: true},
+	}
+	resolver, err := NewResolver(ResolverSettings{
+		URIs:         []string{"mock:config"},
+		Providers:    makeMapProvidersMap(provider, secrets),
+		EnableExpand: true,
+	})
+	require.NoError(t, err)
+	conf, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+
+	expected := map[string]Source{
+		"exporters::otlp::endpoint":         {URI: "mock:config"},
+		"exporters::otlp::headers::api-key": {URI: "mock:config", Secret: true},
+		"exporters::otlp::auth":             {URI: "mock:config", Secret: true},
+		"exporters::otlp::copy":             {URI: "mock:config", Secret: true},
+	}
+	for k, expectedSrc := range expected {
+		src, ok := conf.Source(k)
+		assert.True(t, ok, k)
+		assert.Equal(t, expectedSrc, src, k)
+	}
+	assert.Equal(t, "Bearer token", conf.Get("exporters::otlp::copy"))
+
+	resolver, err = NewResolver(ResolverSettings{
+		URIs:      []string{"secret:headers"},
+		Providers: makeMapProvidersMap(secrets),
+	})
+	require.NoError(t, err)
+	conf, err = resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	src, ok := conf.Source("api-key")
+	assert.True(t, ok)
+	assert.Equal(t, Source{URI: "secret:headers", Secret: true}, src)
+	assert.NoError(t, resolver.Shutdown(context.Background()))
+}
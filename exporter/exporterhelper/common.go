@@ -72,9 +72,12 @@ type baseSettings struct {
 	component.StartFunc
 	component.ShutdownFunc
 	consumerOptions []consumer.Option
+	// priorityClassifier returns the name of the priority tier of a request, it is set by the signal specific options.
+	priorityClassifier func(internal.Request) string
 	TimeoutSettings
 	QueueSettings
 	RetrySettings
+	DeadLetterSettings
 }
 
 // fromOptions returns the internal options starting from the default and applying all configured options.
@@ -85,7 +88,8 @@ func fromOptions(options ...Option) *baseSettings {
 		// TODO: Enable queuing by default (call DefaultQueueSettings)
 		QueueSettings: QueueSettings{Enabled: false},
 		// TODO: Enable retry by default (call DefaultRetrySettings)
-		RetrySettings: RetrySettings{Enabled: false},
+		RetrySettings:      RetrySettings{Enabled: false},
+		DeadLetterSettings: NewDefaultDeadLetterSettings(),
 	}
 
 	for _, op := range options {
@@ -138,6 +142,14 @@ func WithQueue(queueSettings QueueSettings) Option {
 	}
 }
 
+// WithDeadLetter overrides the default DeadLetterSettings for an exporter.
+// The default DeadLetterSettings is to drop the batches that failed permanently.
+func WithDeadLetter(deadLetterSettings DeadLetterSettings) Option {
+	return func(o *baseSettings) {
+		o.DeadLetterSettings = deadLetterSettings
+	}
+}
+
 // WithCapabilities overrides the default Capabilities() function for a Consumer.
 // The default is non-mutable data.
 // TODO: Verify if we can change the default to be mutable as we do for processors.
@@ -160,7 +172,13 @@ func newBaseExporter(cfg config.Exporter, set component.ExporterCreateSettings,
 	be := &baseExporter{}
 
 	be.obsrep = newObsExporter(obsreport.ExporterSettings{ExporterID: cfg.ID(), ExporterCreateSettings: set}, globalInstruments)
-	be.qrSender = newQueuedRetrySender(cfg.ID(), signal, bs.QueueSettings, bs.RetrySettings, reqUnmarshaler, &timeoutSender{cfg: bs.TimeoutSettings}, set.Logger)
+	deadLetter := newDeadLetterSender(cfg.ID(), signal, bs.DeadLetterSettings, set.Logger)
+	if deadLetter != nil && !bs.QueueSettings.Enabled {
+		// Without queue, the errors are returned to the caller, which would send the data again.
+		set.Logger.Warn("The dead letter is ignored since the sending queue is disabled.")
+		deadLetter = nil
+	}
+	be.qrSender = newQueuedRetrySender(cfg.ID(), signal, bs.QueueSettings, bs.RetrySettings, reqUnmarshaler, newSplitSender(cfg.ID(), set.Logger, &timeoutSender{cfg: bs.TimeoutSettings}), set.Logger, bs.priorityClassifier, deadLetter)
 	be.sender = be.qrSender
 	be.StartFunc = func(ctx context.Context, host component.Host) error {
 		// First start the wrapped exporter.
@@ -168,6 +186,10 @@ func newBaseExporter(cfg config.Exporter, set component.ExporterCreateSettings,
 			return err
 		}
 
+		if err := deadLetter.start(host); err != nil {
+			return err
+		}
+
 		// If no error then start the queuedRetrySender.
 		return be.qrSender.start(ctx, host)
 	}
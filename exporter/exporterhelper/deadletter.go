@@ -0,0 +1,404 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/metric"
+	"go.opencensus.io/metric/metricdata"
+	"go.uber.org/atomic"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configbytes"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// DeadLetterEncoding defines the encoding of the batches written in the dead-letter directory.
+type DeadLetterEncoding string
+
+const (
+	// DeadLetterEncodingProto writes the batches as OTLP protobuf.
+	DeadLetterEncodingProto DeadLetterEncoding = "proto"
+	// DeadLetterEncodingJSON writes the batches as OTLP JSON.
+	DeadLetterEncodingJSON DeadLetterEncoding = "json"
+)
+
+// DeadLetterSettings defines configuration for the batches that failed permanently, i.e. with a permanent
+// error or after the retries are exhausted, which are written to a directory or sent to another exporter
+// instead of being dropped. Only the batches whose error is not returned to the caller are kept, i.e.
+// the dead letter requires the sending queue to be enabled.
+type DeadLetterSettings struct {
+	// Enabled indicates whether to keep the batches that failed permanently.
+	Enabled bool `mapstructure:"enabled"`
+	// Directory if not empty, writes every failed batch in this directory: an OTLP payload file
+	// and a metadata file describing the failure, with the same name and the ".meta.json" extension.
+	Directory string `mapstructure:"directory"`
+	// Encoding of the payload files: "proto" (default) or "json".
+	Encoding DeadLetterEncoding `mapstructure:"encoding"`
+	// MaxBytes if positive, is the maximum size of the files written by the exporter in the directory.
+	// The oldest batches are removed to make room for the new ones.
+	MaxBytes configbytes.ByteSize `mapstructure:"max_bytes"`
+	// Exporter if not nil, sends the failed batches to this exporter, which must be used in
+	// a pipeline of the same data type.
+	Exporter *config.ComponentID `mapstructure:"exporter"`
+}
+
+// NewDefaultDeadLetterSettings returns the default settings for DeadLetterSettings.
+func NewDefaultDeadLetterSettings() DeadLetterSettings {
+	return DeadLetterSettings{
+		Enabled:  false,
+		Encoding: DeadLetterEncodingProto,
+	}
+}
+
+// Validate checks if the DeadLetterSettings configuration is valid
+func (dlCfg *DeadLetterSettings) Validate() error {
+	if !dlCfg.Enabled {
+		return nil
+	}
+
+	if dlCfg.Directory == "" && dlCfg.Exporter == nil {
+		return errors.New("dead letter requires a directory or an exporter")
+	}
+
+	if dlCfg.MaxBytes < 0 {
+		return errors.New("dead letter max_bytes must not be negative")
+	}
+
+	switch dlCfg.Encoding {
+	case "", DeadLetterEncodingProto, DeadLetterEncodingJSON:
+	default:
+		return fmt.Errorf("unsupported dead letter encoding %q", dlCfg.Encoding)
+	}
+
+	return nil
+}
+
+// deadLetterMetadata describes a batch written in the dead-letter directory.
+type deadLetterMetadata struct {
+	Time     time.Time          `json:"time"`
+	Exporter string             `json:"exporter"`
+	Signal   config.DataType    `json:"signal"`
+	Items    int                `json:"items"`
+	Error    string             `json:"error"`
+	Encoding DeadLetterEncoding `json:"encoding"`
+	Payload  string             `json:"payload"`
+}
+
+// deadLetterSender keeps the batches that failed permanently. A nil deadLetterSender drops them.
+type deadLetterSender struct {
+	id           config.ComponentID
+	signal       config.DataType
+	cfg          DeadLetterSettings
+	logger       *zap.Logger
+	exporter     component.Exporter
+	seq          *atomic.Uint64
+	sentEntry    *metric.Int64CumulativeEntry
+	failedEntry  *metric.Int64CumulativeEntry
+	filePrefix   string
+	fileEncoding DeadLetterEncoding
+
+	// filesLock guards the batches written in the directory, oldest first, and their total size.
+	// Only tracked if MaxBytes is set.
+	filesLock  sync.Mutex
+	files      []deadLetterFile
+	filesBytes int64
+}
+
+// deadLetterFile is a batch written in the dead-letter directory.
+type deadLetterFile struct {
+	name  string
+	bytes int64
+	items int
+}
+
+func newDeadLetterSender(id config.ComponentID, signal config.DataType, cfg DeadLetterSettings, logger *zap.Logger) *deadLetterSender {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	labelValue := metricdata.NewLabelValue(id.String())
+	sentEntry, _ := globalInstruments.deadLetterItems.GetEntry(labelValue)
+	failedEntry, _ := globalInstruments.deadLetterFailedItems.GetEntry(labelValue)
+
+	encoding := cfg.Encoding
+	if encoding == "" {
+		encoding = DeadLetterEncodingProto
+	}
+
+	return &deadLetterSender{
+		id:           id,
+		signal:       signal,
+		cfg:          cfg,
+		logger:       createSampledLogger(logger),
+		seq:          atomic.NewUint64(0),
+		sentEntry:    sentEntry,
+		failedEntry:  failedEntry,
+		filePrefix:   strings.ReplaceAll(id.String(), "/", "_") + "-" + string(signal),
+		fileEncoding: encoding,
+	}
+}
+
+// start creates the dead-letter directory and looks up the dead-letter exporter.
+func (dls *deadLetterSender) start(host component.Host) error {
+	if dls == nil {
+		return nil
+	}
+
+	if dls.cfg.Directory != "" {
+		if err := os.MkdirAll(dls.cfg.Directory, 0700); err != nil {
+			return fmt.Errorf("failed to create dead letter directory: %w", err)
+		}
+		if dls.cfg.MaxBytes > 0 {
+			if err := dls.loadFiles(); err != nil {
+				return fmt.Errorf("failed to read dead letter directory: %w", err)
+			}
+		}
+	}
+
+	if dls.cfg.Exporter != nil {
+		if *dls.cfg.Exporter == dls.id {
+			return fmt.Errorf("dead letter exporter %q must not be the exporter itself", dls.id)
+		}
+		exp, ok := host.GetExporters()[dls.signal][*dls.cfg.Exporter]
+		if !ok {
+			return fmt.Errorf("dead letter exporter %q not found for data type %q", *dls.cfg.Exporter, dls.signal)
+		}
+		dls.exporter = exp
+	}
+
+	return nil
+}
+
+// send keeps the request that failed permanently with the given error.
+func (dls *deadLetterSender) send(req internal.Request, exportErr error) {
+	if dls == nil {
+		return
+	}
+
+	// Only keep the items that failed, in case of partial failure.
+	req = req.OnError(exportErr)
+	var errs error
+	if dls.cfg.Directory != "" {
+		if err := dls.write(req, exportErr); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("failed to write to dead letter directory: %w", err))
+		}
+	}
+	if dls.exporter != nil {
+		if err := dls.consume(req); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("failed to send to dead letter exporter: %w", err))
+		}
+	}
+
+	if errs != nil {
+		dls.failedEntry.Inc(int64(req.Count()))
+		dls.logger.Error(
+			"Failed to keep data in dead letter. Dropping data.",
+			zap.Error(errs),
+			zap.Int("dropped_items", req.Count()),
+		)
+		return
+	}
+	dls.sentEntry.Inc(int64(req.Count()))
+	dls.logger.Warn(
+		"Exporting failed. Kept data in dead letter.",
+		zap.Error(exportErr),
+		zap.Int("dead_letter_items", req.Count()),
+	)
+}
+
+// write writes the payload of the request, then its metadata, in the dead-letter directory.
+func (dls *deadLetterSender) write(req internal.Request, exportErr error) error {
+	payload, err := deadLetterPayload(req, dls.fileEncoding)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%d-%d", dls.filePrefix, now.UnixNano(), dls.seq.Inc())
+	payloadName := name + "." + string(dls.fileEncoding)
+	if dls.fileEncoding == DeadLetterEncodingProto {
+		payloadName = name + ".pb"
+	}
+	meta, err := json.Marshal(deadLetterMetadata{
+		Time:     now,
+		Exporter: dls.id.String(),
+		Signal:   dls.signal,
+		Items:    req.Count(),
+		Error:    exportErr.Error(),
+		Encoding: dls.fileEncoding,
+		Payload:  payloadName,
+	})
+	if err != nil {
+		return err
+	}
+
+	if dls.cfg.MaxBytes > 0 {
+		dls.filesLock.Lock()
+		defer dls.filesLock.Unlock()
+		if err = dls.makeRoom(int64(len(payload) + len(meta))); err != nil {
+			return err
+		}
+	}
+	if err = os.WriteFile(filepath.Join(dls.cfg.Directory, payloadName), payload, 0600); err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(dls.cfg.Directory, name+".meta.json"), meta, 0600); err != nil {
+		return err
+	}
+	if dls.cfg.MaxBytes > 0 {
+		dls.files = append(dls.files, deadLetterFile{name: name, bytes: int64(len(payload) + len(meta)), items: req.Count()})
+		dls.filesBytes += int64(len(payload) + len(meta))
+	}
+	return nil
+}
+
+// makeRoom removes the oldest batches from the directory until a batch of the given size fits
+// within MaxBytes. Must be called with filesLock held.
+func (dls *deadLetterSender) makeRoom(size int64) error {
+	maxBytes := int64(dls.cfg.MaxBytes)
+	if size > maxBytes {
+		return fmt.Errorf("batch of %d bytes exceeds the dead letter max_bytes of %d bytes", size, maxBytes)
+	}
+	evictedItems := 0
+	for len(dls.files) > 0 && dls.filesBytes+size > maxBytes {
+		oldest := dls.files[0]
+		if err := dls.removeFile(oldest.name); err != nil {
+			return fmt.Errorf("failed to remove the oldest batch: %w", err)
+		}
+		dls.files = dls.files[1:]
+		dls.filesBytes -= oldest.bytes
+		evictedItems += oldest.items
+	}
+	if evictedItems > 0 {
+		dls.logger.Warn(
+			"Dead letter directory reached max_bytes. Removed the oldest data.",
+			zap.Int("removed_items", evictedItems),
+		)
+	}
+	return nil
+}
+
+// removeFile removes the payload and the metadata files of the batch with the given name.
+func (dls *deadLetterSender) removeFile(name string) error {
+	metaPath := filepath.Join(dls.cfg.Directory, name+".meta.json")
+	var meta deadLetterMetadata
+	if metaBytes, err := os.ReadFile(metaPath); err == nil && json.Unmarshal(metaBytes, &meta) == nil && meta.Payload != "" {
+		if err = os.Remove(filepath.Join(dls.cfg.Directory, filepath.Base(meta.Payload))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Remove(metaPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// loadFiles tracks the batches written in the directory by the exporter before it started,
+// so that they count towards MaxBytes.
+func (dls *deadLetterSender) loadFiles() error {
+	metaPaths, err := filepath.Glob(filepath.Join(dls.cfg.Directory, dls.filePrefix+"-*.meta.json"))
+	if err != nil {
+		return err
+	}
+	type loadedFile struct {
+		deadLetterFile
+		time time.Time
+	}
+	var loaded []loadedFile
+	for _, metaPath := range metaPaths {
+		metaBytes, errRead := os.ReadFile(metaPath)
+		if errRead != nil {
+			return errRead
+		}
+		var meta deadLetterMetadata
+		if errJSON := json.Unmarshal(metaBytes, &meta); errJSON != nil {
+			// Not written by the dead letter, left as is.
+			continue
+		}
+		size := int64(len(metaBytes))
+		if info, errStat := os.Stat(filepath.Join(dls.cfg.Directory, filepath.Base(meta.Payload))); errStat == nil {
+			size += info.Size()
+		}
+		loaded = append(loaded, loadedFile{
+			deadLetterFile: deadLetterFile{name: strings.TrimSuffix(filepath.Base(metaPath), ".meta.json"), bytes: size, items: meta.Items},
+			time:           meta.Time,
+		})
+	}
+	sort.SliceStable(loaded, func(i, j int) bool { return loaded[i].time.Before(loaded[j].time) })
+
+	dls.filesLock.Lock()
+	defer dls.filesLock.Unlock()
+	dls.files = dls.files[:0]
+	dls.filesBytes = 0
+	for _, f := range loaded {
+		dls.files = append(dls.files, f.deadLetterFile)
+		dls.filesBytes += f.bytes
+	}
+	return nil
+}
+
+// consume sends the data of the request to the dead-letter exporter.
+func (dls *deadLetterSender) consume(req internal.Request) error {
+	// The request may have been cancelled or timed out, keep only the values of its context.
+	ctx := noCancellationContext{Context: req.Context()}
+	switch r := req.(type) {
+	case *tracesRequest:
+		if tc, ok := dls.exporter.(consumer.Traces); ok {
+			return tc.ConsumeTraces(ctx, r.td)
+		}
+	case *metricsRequest:
+		if mc, ok := dls.exporter.(consumer.Metrics); ok {
+			return mc.ConsumeMetrics(ctx, r.md)
+		}
+	case *logsRequest:
+		if lc, ok := dls.exporter.(consumer.Logs); ok {
+			return lc.ConsumeLogs(ctx, r.ld)
+		}
+	}
+	return fmt.Errorf("dead letter exporter %q does not support data type %q", *dls.cfg.Exporter, dls.signal)
+}
+
+func deadLetterPayload(req internal.Request, encoding DeadLetterEncoding) ([]byte, error) {
+	if encoding == DeadLetterEncodingProto {
+		return req.Marshal()
+	}
+	switch r := req.(type) {
+	case *tracesRequest:
+		return ptrace.NewJSONMarshaler().MarshalTraces(r.td)
+	case *metricsRequest:
+		return pmetric.NewJSONMarshaler().MarshalMetrics(r.md)
+	case *logsRequest:
+		return plog.NewJSONMarshaler().MarshalLogs(r.ld)
+	}
+	return nil, fmt.Errorf("unsupported request type %T", req)
+}
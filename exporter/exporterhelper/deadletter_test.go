@@ -0,0 +1,316 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configbytes"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+type deadLetterHost struct {
+	component.Host
+	exporters map[config.DataType]map[config.ComponentID]component.Exporter
+}
+
+func (h *deadLetterHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return h.exporters
+}
+
+// deadLetterQueueSettings returns the settings of the sending queue required by the dead letter.
+func deadLetterQueueSettings() QueueSettings {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	return qCfg
+}
+
+func TestDeadLetterSettings_Validate(t *testing.T) {
+	dlCfg := NewDefaultDeadLetterSettings()
+	assert.NoError(t, dlCfg.Validate())
+
+	dlCfg.Enabled = true
+	assert.EqualError(t, dlCfg.Validate(), "dead letter requires a directory or an exporter")
+
+	dlCfg.Directory = "dead_letter"
+	assert.NoError(t, dlCfg.Validate())
+
+	dlCfg.MaxBytes = -1
+	assert.EqualError(t, dlCfg.Validate(), "dead letter max_bytes must not be negative")
+
+	dlCfg.MaxBytes = 0
+	dlCfg.Encoding = "yaml"
+	assert.EqualError(t, dlCfg.Validate(), `unsupported dead letter encoding "yaml"`)
+
+	dlCfg.Directory = ""
+	dlCfg.Encoding = DeadLetterEncodingJSON
+	exporterID := config.NewComponentID("otlp")
+	dlCfg.Exporter = &exporterID
+	assert.NoError(t, dlCfg.Validate())
+}
+
+func TestDeadLetter_Directory(t *testing.T) {
+	for _, encoding := range []DeadLetterEncoding{DeadLetterEncodingProto, DeadLetterEncodingJSON} {
+		t.Run(string(encoding), func(t *testing.T) {
+			dir := t.TempDir()
+			dlCfg := DeadLetterSettings{Enabled: true, Directory: dir, Encoding: encoding}
+			cfg := config.NewExporterSettings(config.NewComponentIDWithName("otlp", "dead_letter_"+string(encoding)))
+			td := testdata.GenerateTraces(2)
+			te, err := NewTracesExporterWithContext(context.Background(), componenttest.NewNopExporterCreateSettings(), &cfg,
+				func(context.Context, ptrace.Traces) error { return consumererror.NewPermanent(errors.New("bad data")) },
+				WithQueue(deadLetterQueueSettings()),
+				WithDeadLetter(dlCfg))
+			require.NoError(t, err)
+			require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+
+			assert.NoError(t, te.ConsumeTraces(context.Background(), td))
+			// Shutdown waits for the queued data to be sent.
+			require.NoError(t, te.Shutdown(context.Background()))
+
+			metaFiles, err := filepath.Glob(filepath.Join(dir, "*.meta.json"))
+			require.NoError(t, err)
+			require.Len(t, metaFiles, 1)
+			metaBytes, err := os.ReadFile(metaFiles[0])
+			require.NoError(t, err)
+			var meta deadLetterMetadata
+			require.NoError(t, json.Unmarshal(metaBytes, &meta))
+			assert.Equal(t, cfg.ID().String(), meta.Exporter)
+			assert.Equal(t, config.TracesDataType, meta.Signal)
+			assert.Equal(t, 2, meta.Items)
+			assert.Equal(t, "Permanent error: bad data", meta.Error)
+			assert.Equal(t, encoding, meta.Encoding)
+			assert.True(t, strings.HasPrefix(meta.Payload, "otlp_dead_letter_"+string(encoding)+"-traces-"))
+
+			payload, err := os.ReadFile(filepath.Join(dir, meta.Payload))
+			require.NoError(t, err)
+			unmarshaler := ptrace.NewProtoUnmarshaler()
+			if encoding == DeadLetterEncodingJSON {
+				unmarshaler = ptrace.NewJSONUnmarshaler()
+			}
+			got, err := unmarshaler.UnmarshalTraces(payload)
+			require.NoError(t, err)
+			assert.Equal(t, td, got)
+
+			checkValueForGlobalManager(t, tagsForExporterView(cfg.ID()), int64(2), "exporter/dead_letter_items")
+		})
+	}
+}
+
+func TestDeadLetter_Exporter(t *testing.T) {
+	dlID := config.NewComponentIDWithName("otlp", "dead_letter")
+	sink := new(consumertest.LogsSink)
+	dlCfg := config.NewExporterSettings(dlID)
+	dle, err := NewLogsExporterWithContext(context.Background(), componenttest.NewNopExporterCreateSettings(), &dlCfg, sink.ConsumeLogs)
+	require.NoError(t, err)
+	host := &deadLetterHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.DataType]map[config.ComponentID]component.Exporter{
+			config.LogsDataType: {dlID: dle},
+		},
+	}
+
+	rCfg := NewDefaultRetrySettings()
+	rCfg.Enabled = false
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("otlp", "dead_letter_exporter"))
+	le, err := NewLogsExporterWithContext(context.Background(), componenttest.NewNopExporterCreateSettings(), &cfg,
+		func(context.Context, plog.Logs) error { return errors.New("transient error") },
+		WithRetry(rCfg),
+		WithQueue(deadLetterQueueSettings()),
+		WithDeadLetter(DeadLetterSettings{Enabled: true, Exporter: &dlID}))
+	require.NoError(t, err)
+	require.NoError(t, le.Start(context.Background(), host))
+
+	ld := testdata.GenerateLogs(3)
+	assert.NoError(t, le.ConsumeLogs(context.Background(), ld))
+	require.NoError(t, le.Shutdown(context.Background()))
+	require.Len(t, sink.AllLogs(), 1)
+	assert.Equal(t, ld, sink.AllLogs()[0])
+	checkValueForGlobalManager(t, tagsForExporterView(cfg.ID()), int64(3), "exporter/dead_letter_items")
+}
+
+func TestDeadLetter_PartialFailure(t *testing.T) {
+	dir := t.TempDir()
+	failed := testdata.GenerateTraces(1)
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("otlp", "dead_letter_partial"))
+	te, err := NewTracesExporterWithContext(context.Background(), componenttest.NewNopExporterCreateSettings(), &cfg,
+		func(context.Context, ptrace.Traces) error {
+			return consumererror.NewPermanent(consumererror.NewTraces(errors.New("bad data"), failed))
+		},
+		WithQueue(deadLetterQueueSettings()),
+		WithDeadLetter(DeadLetterSettings{Enabled: true, Directory: dir}))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+
+	assert.NoError(t, te.ConsumeTraces(context.Background(), testdata.GenerateTraces(3)))
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	payloads, err := filepath.Glob(filepath.Join(dir, "*.pb"))
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	payload, err := os.ReadFile(payloads[0])
+	require.NoError(t, err)
+	got, err := ptrace.NewProtoUnmarshaler().UnmarshalTraces(payload)
+	require.NoError(t, err)
+	assert.Equal(t, failed, got)
+}
+
+func TestDeadLetter_StartErrors(t *testing.T) {
+	id := config.NewComponentIDWithName("otlp", "dead_letter_start")
+	otherID := config.NewComponentID("other")
+	cfg := config.NewExporterSettings(id)
+
+	testCases := []struct {
+		desc        string
+		dlCfg       DeadLetterSettings
+		expectedErr string
+	}{
+		{
+			desc:        "itself",
+			dlCfg:       DeadLetterSettings{Enabled: true, Exporter: &id},
+			expectedErr: `dead letter exporter "otlp/dead_letter_start" must not be the exporter itself`,
+		},
+		{
+			desc:        "not found",
+			dlCfg:       DeadLetterSettings{Enabled: true, Exporter: &otherID},
+			expectedErr: `dead letter exporter "other" not found for data type "traces"`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			te, err := NewTracesExporterWithContext(context.Background(), componenttest.NewNopExporterCreateSettings(), &cfg,
+				func(context.Context, ptrace.Traces) error { return nil },
+				WithQueue(deadLetterQueueSettings()),
+				WithDeadLetter(tC.dlCfg))
+			require.NoError(t, err)
+			assert.EqualError(t, te.Start(context.Background(), componenttest.NewNopHost()), tC.expectedErr)
+		})
+	}
+}
+
+func TestDeadLetter_WithoutQueue(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("otlp", "dead_letter_without_queue"))
+	te, err := NewTracesExporterWithContext(context.Background(), componenttest.NewNopExporterCreateSettings(), &cfg,
+		func(context.Context, ptrace.Traces) error { return consumererror.NewPermanent(errors.New("bad data")) },
+		WithDeadLetter(DeadLetterSettings{Enabled: true, Directory: dir}))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+
+	// The error is returned to the caller, the data is not kept.
+	assert.Error(t, te.ConsumeTraces(context.Background(), testdata.GenerateTraces(1)))
+	require.NoError(t, te.Shutdown(context.Background()))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDeadLetter_InterruptedRetry(t *testing.T) {
+	dir := t.TempDir()
+	attempted := make(chan struct{}, 1)
+	rCfg := NewDefaultRetrySettings()
+	rCfg.InitialInterval = time.Minute
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("otlp", "dead_letter_interrupted"))
+	te, err := NewTracesExporterWithContext(context.Background(), componenttest.NewNopExporterCreateSettings(), &cfg,
+		func(context.Context, ptrace.Traces) error {
+			select {
+			case attempted <- struct{}{}:
+			default:
+			}
+			return errors.New("transient error")
+		},
+		WithRetry(rCfg),
+		WithQueue(deadLetterQueueSettings()),
+		WithDeadLetter(DeadLetterSettings{Enabled: true, Directory: dir}))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+
+	// The retry interrupted by the shutdown is not a permanent failure.
+	assert.NoError(t, te.ConsumeTraces(context.Background(), testdata.GenerateTraces(1)))
+	<-attempted
+	require.NoError(t, te.Shutdown(context.Background()))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDeadLetter_MaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("otlp", "dead_letter_max_bytes"))
+	send := func(dlCfg DeadLetterSettings, count int) {
+		te, err := NewTracesExporterWithContext(context.Background(), componenttest.NewNopExporterCreateSettings(), &cfg,
+			func(context.Context, ptrace.Traces) error { return consumererror.NewPermanent(errors.New("bad data")) },
+			WithQueue(deadLetterQueueSettings()),
+			WithDeadLetter(dlCfg))
+		require.NoError(t, err)
+		require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+		for i := 0; i < count; i++ {
+			assert.NoError(t, te.ConsumeTraces(context.Background(), testdata.GenerateTraces(2)))
+		}
+		require.NoError(t, te.Shutdown(context.Background()))
+	}
+	dirSize := func() int64 {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		var size int64
+		for _, entry := range entries {
+			info, err := entry.Info()
+			require.NoError(t, err)
+			size += info.Size()
+		}
+		return size
+	}
+
+	// A batch written before the exporter started.
+	send(DeadLetterSettings{Enabled: true, Directory: dir}, 1)
+	first, err := filepath.Glob(filepath.Join(dir, "*.meta.json"))
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	batchSize := dirSize()
+
+	// Only the two most recent batches fit, the batch written before is removed first.
+	maxBytes := 2*batchSize + batchSize/2
+	send(DeadLetterSettings{Enabled: true, Directory: dir, MaxBytes: configbytes.ByteSize(maxBytes)}, 3)
+	metaFiles, err := filepath.Glob(filepath.Join(dir, "*.meta.json"))
+	require.NoError(t, err)
+	assert.Len(t, metaFiles, 2)
+	assert.NotContains(t, metaFiles, first[0])
+	payloads, err := filepath.Glob(filepath.Join(dir, "*.pb"))
+	require.NoError(t, err)
+	assert.Len(t, payloads, 2)
+	assert.LessOrEqual(t, dirSize(), maxBytes)
+
+	// A batch larger than max_bytes is not kept.
+	send(DeadLetterSettings{Enabled: true, Directory: dir, MaxBytes: 10}, 1)
+	metaFiles, err = filepath.Glob(filepath.Join(dir, "*.meta.json"))
+	require.NoError(t, err)
+	assert.Len(t, metaFiles, 2)
+}
@@ -0,0 +1,189 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+
+import (
+	"container/list"
+	"sync"
+)
+
+// PriorityQueue is a bounded in-memory queue that keeps requests in priority tiers, 0 being
+// the highest priority. Consumers always receive the oldest request of the highest priority
+// non-empty tier. When the queue is full, a new request evicts a request of the lowest priority
+// non-empty tier below its own; if there is none, the request either evicts the oldest request
+// of its own tier or is rejected, depending on the drop policy of the tier.
+type PriorityQueue struct {
+	stopWG     sync.WaitGroup
+	mu         sync.Mutex
+	notEmpty   *sync.Cond
+	tiers      []*list.List
+	dropOldest []bool
+	size       int
+	capacity   int
+	stopped    bool
+	classify   func(Request) int
+	onDropped  func(tier int, item Request, evicted bool)
+}
+
+var _ ProducerConsumerQueue = (*PriorityQueue)(nil)
+
+// NewPriorityQueue constructs a new queue of the given capacity, with one tier per element of dropOldest,
+// which sets the drop policy of the tier. The classify function returns the tier of a request and
+// onDropped, if not nil, is called for every request that is rejected or evicted from the queue.
+func NewPriorityQueue(capacity int, dropOldest []bool, classify func(Request) int, onDropped func(tier int, item Request, evicted bool)) *PriorityQueue {
+	tiers := make([]*list.List, len(dropOldest))
+	for i := range tiers {
+		tiers[i] = list.New()
+	}
+	pq := &PriorityQueue{
+		tiers:      tiers,
+		dropOldest: dropOldest,
+		capacity:   capacity,
+		classify:   classify,
+		onDropped:  onDropped,
+	}
+	pq.notEmpty = sync.NewCond(&pq.mu)
+	return pq
+}
+
+// StartConsumers starts a given number of goroutines consuming items from the queue
+// and passing them into the consumer callback.
+func (pq *PriorityQueue) StartConsumers(numWorkers int, callback func(item Request)) {
+	for i := 0; i < numWorkers; i++ {
+		pq.stopWG.Add(1)
+		go func() {
+			defer pq.stopWG.Done()
+			for {
+				item, ok := pq.next()
+				if !ok {
+					return
+				}
+				callback(item)
+			}
+		}()
+	}
+}
+
+// next blocks until an item is available, it returns false once the queue is stopped and drained.
+func (pq *PriorityQueue) next() (Request, bool) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	for pq.size == 0 && !pq.stopped {
+		pq.notEmpty.Wait()
+	}
+	for _, tier := range pq.tiers {
+		if e := tier.Front(); e != nil {
+			pq.size--
+			return tier.Remove(e).(Request), true
+		}
+	}
+	return nil, false
+}
+
+// Produce is used by the producer to submit new item to the queue. Returns false if the item
+// was rejected because the queue is stopped or full.
+func (pq *PriorityQueue) Produce(item Request) bool {
+	tier := pq.tierOf(item)
+
+	pq.mu.Lock()
+	if pq.stopped {
+		pq.mu.Unlock()
+		return false
+	}
+
+	var evicted Request
+	evictedTier := -1
+	if pq.size >= pq.capacity {
+		evictedTier = pq.evictionTier(tier)
+		if evictedTier < 0 {
+			pq.mu.Unlock()
+			pq.dropped(tier, item, false)
+			return false
+		}
+		evicted = pq.evict(evictedTier)
+	}
+
+	pq.tiers[tier].PushBack(item)
+	if evicted == nil {
+		pq.size++
+	}
+	pq.mu.Unlock()
+	pq.notEmpty.Signal()
+
+	if evicted != nil {
+		pq.dropped(evictedTier, evicted, true)
+	}
+	return true
+}
+
+// evictionTier returns the tier to evict a request from to make room for a request of the given tier,
+// or -1 if the request must be rejected. It must be called while holding the lock.
+func (pq *PriorityQueue) evictionTier(tier int) int {
+	for i := len(pq.tiers) - 1; i > tier; i-- {
+		if pq.tiers[i].Len() > 0 {
+			return i
+		}
+	}
+	if pq.dropOldest[tier] && pq.tiers[tier].Len() > 0 {
+		return tier
+	}
+	return -1
+}
+
+// evict removes a request from the given tier according to its drop policy. It must be called while holding the lock.
+func (pq *PriorityQueue) evict(tier int) Request {
+	l := pq.tiers[tier]
+	if pq.dropOldest[tier] {
+		return l.Remove(l.Front()).(Request)
+	}
+	return l.Remove(l.Back()).(Request)
+}
+
+func (pq *PriorityQueue) tierOf(item Request) int {
+	tier := pq.classify(item)
+	if tier < 0 || tier >= len(pq.tiers) {
+		return len(pq.tiers) - 1
+	}
+	return tier
+}
+
+func (pq *PriorityQueue) dropped(tier int, item Request, evicted bool) {
+	if pq.onDropped != nil {
+		pq.onDropped(tier, item, evicted)
+	}
+}
+
+// Stop stops accepting new items and blocks until the consumers have drained the queue.
+func (pq *PriorityQueue) Stop() {
+	pq.mu.Lock()
+	pq.stopped = true
+	pq.mu.Unlock()
+	pq.notEmpty.Broadcast()
+	pq.stopWG.Wait()
+}
+
+// Size returns the current size of the queue.
+func (pq *PriorityQueue) Size() int {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.size
+}
+
+// TierSize returns the current number of items in the given tier.
+func (pq *PriorityQueue) TierSize(tier int) int {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.tiers[tier].Len()
+}
@@ -0,0 +1,122 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// classifyByPrefix puts requests starting with "h" in the high priority tier, the rest in the low priority tier.
+func classifyByPrefix(item Request) int {
+	if strings.HasPrefix(item.(stringRequest).str, "h") {
+		return 0
+	}
+	return 1
+}
+
+func consumeAll(q *PriorityQueue) []string {
+	var mu sync.Mutex
+	var consumed []string
+	q.StartConsumers(1, func(item Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		consumed = append(consumed, item.(stringRequest).str)
+	})
+	q.Stop()
+	return consumed
+}
+
+func TestPriorityQueueOrder(t *testing.T) {
+	q := NewPriorityQueue(10, []bool{false, false}, classifyByPrefix, nil)
+
+	for _, s := range []string{"l1", "h1", "l2", "h2"} {
+		assert.True(t, q.Produce(newStringRequest(s)))
+	}
+	assert.Equal(t, 4, q.Size())
+	assert.Equal(t, 2, q.TierSize(0))
+	assert.Equal(t, 2, q.TierSize(1))
+
+	assert.Equal(t, []string{"h1", "h2", "l1", "l2"}, consumeAll(q))
+	assert.Equal(t, 0, q.Size())
+	assert.False(t, q.Produce(newStringRequest("h3")))
+}
+
+func TestPriorityQueueDropPolicies(t *testing.T) {
+	type drop struct {
+		tier    int
+		str     string
+		evicted bool
+	}
+	var dropped []drop
+	// The high priority tier drops the newest requests, the low priority tier drops the oldest.
+	q := NewPriorityQueue(2, []bool{false, true}, classifyByPrefix, func(tier int, item Request, evicted bool) {
+		dropped = append(dropped, drop{tier: tier, str: item.(stringRequest).str, evicted: evicted})
+	})
+
+	assert.True(t, q.Produce(newStringRequest("l1")))
+	assert.True(t, q.Produce(newStringRequest("l2")))
+
+	// A higher priority request evicts from the lower tier, according to its policy.
+	assert.True(t, q.Produce(newStringRequest("h1")))
+	// A request of a drop_oldest tier evicts the oldest request of its own tier.
+	assert.True(t, q.Produce(newStringRequest("l3")))
+	assert.True(t, q.Produce(newStringRequest("h2")))
+	// A request of a drop_newest tier with no lower priority request to evict is rejected.
+	assert.False(t, q.Produce(newStringRequest("h3")))
+	// A request that cannot evict anything is rejected.
+	assert.False(t, q.Produce(newStringRequest("l4")))
+
+	assert.Equal(t, 2, q.Size())
+	assert.Equal(t, []drop{{1, "l1", true}, {1, "l2", true}, {1, "l3", true}, {0, "h3", false}, {1, "l4", false}}, dropped)
+	assert.Equal(t, []string{"h1", "h2"}, consumeAll(q))
+}
+
+func TestPriorityQueueInvalidTier(t *testing.T) {
+	q := NewPriorityQueue(10, []bool{false, false}, func(Request) int { return 5 }, nil)
+	assert.True(t, q.Produce(newStringRequest("a")))
+	assert.Equal(t, 1, q.TierSize(1))
+	q.Stop()
+}
+
+func TestPriorityQueueConcurrentConsumers(t *testing.T) {
+	q := NewPriorityQueue(1000, []bool{false, false}, classifyByPrefix, nil)
+
+	var mu sync.Mutex
+	consumed := map[string]bool{}
+	q.StartConsumers(4, func(item Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		consumed[item.(stringRequest).str] = true
+	})
+
+	var wg sync.WaitGroup
+	for _, prefix := range []string{"h", "l"} {
+		wg.Add(1)
+		go func(prefix string) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				assert.True(t, q.Produce(newStringRequest(prefix+strings.Repeat("x", i))))
+			}
+		}(prefix)
+	}
+	wg.Wait()
+	q.Stop()
+
+	assert.Len(t, consumed, 200)
+}
@@ -61,6 +61,21 @@ func (req *logsRequest) OnError(err error) internal.Request {
 	return req
 }
 
+func (req *logsRequest) split() (internal.Request, internal.Request) {
+	first, second := splitLogs(req.ld)
+	return newLogsRequest(req.ctx, first, req.pusher), newLogsRequest(req.ctx, second, req.pusher)
+}
+
+func (req *logsRequest) merge(other internal.Request) internal.Request {
+	merged := req.ld.Clone()
+	other.(*logsRequest).ld.Clone().ResourceLogs().MoveAndAppendTo(merged.ResourceLogs())
+	return newLogsRequest(req.ctx, merged, req.pusher)
+}
+
+func (req *logsRequest) partialError(err error) error {
+	return consumererror.NewLogs(err, req.ld)
+}
+
 func (req *logsRequest) Export(ctx context.Context) error {
 	return req.pusher(ctx, req.ld)
 }
@@ -78,6 +93,20 @@ type logsExporter struct {
 	consumer.Logs
 }
 
+// WithLogsPriority sets the function that classifies the logs into the priority tiers of the sending queue,
+// by returning the name of a tier. It takes precedence over the configured priority metadata key, and is
+// only used when the sending queue has priority tiers configured.
+func WithLogsPriority(classify func(ctx context.Context, ld plog.Logs) string) Option {
+	return func(o *baseSettings) {
+		o.priorityClassifier = func(req internal.Request) string {
+			if r, ok := req.(*logsRequest); ok {
+				return classify(r.Context(), r.ld)
+			}
+			return ""
+		}
+	}
+}
+
 // Deprecated: [v0.58.0] use NewLogsExporterWithContext.
 func NewLogsExporter(
 	cfg config.Exporter,
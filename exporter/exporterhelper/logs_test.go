@@ -16,6 +16,7 @@ package exporterhelper
 import (
 	"context"
 	"errors"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -260,3 +261,13 @@ func checkWrapSpanForLogsExporter(t *testing.T, sr *tracetest.SpanRecorder, trac
 		require.Containsf(t, sd.Attributes(), attribute.KeyValue{Key: obsmetrics.FailedToSendLogRecordsKey, Value: attribute.Int64Value(failedToSendLogRecords)}, "SpanData %v", sd)
 	}
 }
+
+func TestLogsPriority(t *testing.T) {
+	bs := fromOptions(WithLogsPriority(func(_ context.Context, ld plog.Logs) string {
+		return strconv.Itoa(ld.LogRecordCount())
+	}))
+
+	ld := testdata.GenerateLogs(2)
+	assert.Equal(t, strconv.Itoa(ld.LogRecordCount()), bs.priorityClassifier(newLogsRequest(context.Background(), ld, nil)))
+	assert.Equal(t, "", bs.priorityClassifier(newMockRequest(context.Background(), 1, nil)))
+}
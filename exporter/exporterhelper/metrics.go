@@ -61,6 +61,21 @@ func (req *metricsRequest) OnError(err error) internal.Request {
 	return req
 }
 
+func (req *metricsRequest) split() (internal.Request, internal.Request) {
+	first, second := splitMetrics(req.md)
+	return newMetricsRequest(req.ctx, first, req.pusher), newMetricsRequest(req.ctx, second, req.pusher)
+}
+
+func (req *metricsRequest) merge(other internal.Request) internal.Request {
+	merged := req.md.Clone()
+	other.(*metricsRequest).md.Clone().ResourceMetrics().MoveAndAppendTo(merged.ResourceMetrics())
+	return newMetricsRequest(req.ctx, merged, req.pusher)
+}
+
+func (req *metricsRequest) partialError(err error) error {
+	return consumererror.NewMetrics(err, req.md)
+}
+
 func (req *metricsRequest) Export(ctx context.Context) error {
 	return req.pusher(ctx, req.md)
 }
@@ -79,6 +94,20 @@ type metricsExporter struct {
 	consumer.Metrics
 }
 
+// WithMetricsPriority sets the function that classifies the metrics into the priority tiers of the sending queue,
+// by returning the name of a tier. It takes precedence over the configured priority metadata key, and is
+// only used when the sending queue has priority tiers configured.
+func WithMetricsPriority(classify func(ctx context.Context, md pmetric.Metrics) string) Option {
+	return func(o *baseSettings) {
+		o.priorityClassifier = func(req internal.Request) string {
+			if r, ok := req.(*metricsRequest); ok {
+				return classify(r.Context(), r.md)
+			}
+			return ""
+		}
+	}
+}
+
 // Deprecated: [v0.58.0] use NewMetricsExporterWithContext.
 func NewMetricsExporter(
 	cfg config.Exporter,
@@ -16,6 +16,7 @@ package exporterhelper
 import (
 	"context"
 	"errors"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -262,3 +263,13 @@ func checkWrapSpanForMetricsExporter(t *testing.T, sr *tracetest.SpanRecorder, t
 		require.Containsf(t, sd.Attributes(), attribute.KeyValue{Key: obsmetrics.FailedToSendMetricPointsKey, Value: attribute.Int64Value(failedToSendMetricPoints)}, "SpanData %v", sd)
 	}
 }
+
+func TestMetricsPriority(t *testing.T) {
+	bs := fromOptions(WithMetricsPriority(func(_ context.Context, md pmetric.Metrics) string {
+		return strconv.Itoa(md.DataPointCount())
+	}))
+
+	md := testdata.GenerateMetrics(2)
+	assert.Equal(t, strconv.Itoa(md.DataPointCount()), bs.priorityClassifier(newMetricsRequest(context.Background(), md, nil)))
+	assert.Equal(t, "", bs.priorityClassifier(newMockRequest(context.Background(), 1, nil)))
+}
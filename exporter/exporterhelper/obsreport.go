@@ -33,6 +33,9 @@ var (
 	globalInstruments = newInstruments(metric.NewRegistry())
 )
 
+// priorityKey is the label of the priority tier of the sending queue.
+const priorityKey = "priority"
+
 func init() {
 	metricproducer.GlobalManager().AddProducer(globalInstruments.registry)
 }
@@ -44,6 +47,11 @@ type instruments struct {
 	failedToEnqueueTraceSpans   *metric.Int64Cumulative
 	failedToEnqueueMetricPoints *metric.Int64Cumulative
 	failedToEnqueueLogRecords   *metric.Int64Cumulative
+	priorityQueueSize           *metric.Int64DerivedGauge
+	priorityQueueDropped        *metric.Int64Cumulative
+	deadLetterItems             *metric.Int64Cumulative
+	deadLetterFailedItems       *metric.Int64Cumulative
+	splitRequests               *metric.Int64Cumulative
 }
 
 func newInstruments(registry *metric.Registry) *instruments {
@@ -80,6 +88,36 @@ func newInstruments(registry *metric.Registry) *instruments {
 		metric.WithLabelKeys(obsmetrics.ExporterKey),
 		metric.WithUnit(metricdata.UnitDimensionless))
 
+	insts.priorityQueueSize, _ = registry.AddInt64DerivedGauge(
+		obsmetrics.ExporterKey+"/queue_size_by_priority",
+		metric.WithDescription("Current size of each priority tier of the retry queue (in batches)"),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, priorityKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.priorityQueueDropped, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/queue_dropped_items_by_priority",
+		metric.WithDescription("Number of items rejected or evicted from each priority tier of the sending queue."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, priorityKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.deadLetterItems, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/dead_letter_items",
+		metric.WithDescription("Number of items that failed permanently and were kept in dead letter."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.deadLetterFailedItems, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/dead_letter_failed_items",
+		metric.WithDescription("Number of items that failed permanently and could not be kept in dead letter."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.splitRequests, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/split_requests",
+		metric.WithDescription("Number of requests rejected as too large and split in halves."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
 	return insts
 }
 
@@ -21,12 +21,14 @@ import (
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	"go.opencensus.io/metric"
 	"go.opencensus.io/metric/metricdata"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer/consumererror"
@@ -52,8 +54,42 @@ type QueueSettings struct {
 	// StorageID if not empty, enables the persistent storage and uses the component specified
 	// as a storage extension for the persistent queue
 	StorageID *config.ComponentID `mapstructure:"storage"`
+	// Priority if not nil, classifies the batches into priority tiers. Under pressure, the batches of the
+	// lowest priority tiers are dropped first, and the batches of the highest priority tiers are exported first.
+	// Not supported with the persistent queue.
+	Priority *PrioritySettings `mapstructure:"priority"`
 }
 
+// PrioritySettings defines the priority tiers of the sending queue.
+type PrioritySettings struct {
+	// Tiers lists the priority tiers, from the highest to the lowest priority.
+	Tiers []PriorityTier `mapstructure:"tiers"`
+	// MetadataKey if not empty, classifies the batches by the value of this client metadata key,
+	// which is matched against the names of the tiers. It is ignored if the exporter provides its
+	// own classification. Batches that don't match any tier are put in the lowest priority tier.
+	MetadataKey string `mapstructure:"metadata_key"`
+}
+
+// PriorityTier defines a priority tier of the sending queue.
+type PriorityTier struct {
+	// Name of the tier, used for classification and as the value of the priority metric label.
+	Name string `mapstructure:"name"`
+	// DropPolicy defines which batch is dropped when the queue is full and a batch of this tier
+	// needs to be dropped: "drop_newest" (default) rejects the incoming batch, "drop_oldest"
+	// evicts the oldest batch of the tier.
+	DropPolicy DropPolicy `mapstructure:"drop_policy"`
+}
+
+// DropPolicy defines which batch of a priority tier is dropped under pressure.
+type DropPolicy string
+
+const (
+	// DropNewest drops the newest batch of the tier.
+	DropNewest DropPolicy = "drop_newest"
+	// DropOldest drops the oldest batch of the tier.
+	DropOldest DropPolicy = "drop_oldest"
+)
+
 // NewDefaultQueueSettings returns the default settings for QueueSettings.
 func NewDefaultQueueSettings() QueueSettings {
 	return QueueSettings{
@@ -77,9 +113,52 @@ func (qCfg *QueueSettings) Validate() error {
 		return errors.New("queue size must be positive")
 	}
 
+	if qCfg.Priority != nil {
+		if qCfg.StorageID != nil {
+			return errors.New("priority is not supported with the persistent queue")
+		}
+		return qCfg.Priority.Validate()
+	}
+
+	return nil
+}
+
+// Validate checks if the PrioritySettings configuration is valid
+func (pCfg *PrioritySettings) Validate() error {
+	if len(pCfg.Tiers) == 0 {
+		return errors.New("priority must define at least one tier")
+	}
+
+	names := map[string]struct{}{}
+	for _, tier := range pCfg.Tiers {
+		if tier.Name == "" {
+			return errors.New("priority tier name must not be empty")
+		}
+		if _, ok := names[tier.Name]; ok {
+			return fmt.Errorf("duplicate priority tier %q", tier.Name)
+		}
+		names[tier.Name] = struct{}{}
+
+		switch tier.DropPolicy {
+		case "", DropNewest, DropOldest:
+		default:
+			return fmt.Errorf("unsupported drop_policy %q for priority tier %q", tier.DropPolicy, tier.Name)
+		}
+	}
+
 	return nil
 }
 
+// tierIndex returns the index of the tier with the given name, or the lowest priority tier if there is none.
+func (pCfg *PrioritySettings) tierIndex(name string) int {
+	for i, tier := range pCfg.Tiers {
+		if tier.Name == name {
+			return i
+		}
+	}
+	return len(pCfg.Tiers) - 1
+}
+
 type queuedRetrySender struct {
 	fullName           string
 	id                 config.ComponentID
@@ -92,9 +171,12 @@ type queuedRetrySender struct {
 	logger             *zap.Logger
 	requeuingEnabled   bool
 	requestUnmarshaler internal.RequestUnmarshaler
+	priorityQueue      *internal.PriorityQueue
+	priorityClassifier func(internal.Request) string
+	deadLetter         *deadLetterSender
 }
 
-func newQueuedRetrySender(id config.ComponentID, signal config.DataType, qCfg QueueSettings, rCfg RetrySettings, reqUnmarshaler internal.RequestUnmarshaler, nextSender requestSender, logger *zap.Logger) *queuedRetrySender {
+func newQueuedRetrySender(id config.ComponentID, signal config.DataType, qCfg QueueSettings, rCfg RetrySettings, reqUnmarshaler internal.RequestUnmarshaler, nextSender requestSender, logger *zap.Logger, priorityClassifier func(internal.Request) string, deadLetter *deadLetterSender) *queuedRetrySender {
 	retryStopCh := make(chan struct{})
 	sampledLogger := createSampledLogger(logger)
 	traceAttr := attribute.String(obsmetrics.ExporterKey, id.String())
@@ -108,6 +190,8 @@ func newQueuedRetrySender(id config.ComponentID, signal config.DataType, qCfg Qu
 		traceAttribute:     traceAttr,
 		logger:             sampledLogger,
 		requestUnmarshaler: reqUnmarshaler,
+		priorityClassifier: priorityClassifier,
+		deadLetter:         deadLetter,
 	}
 
 	qrs.consumerSender = &retrySender{
@@ -116,18 +200,61 @@ func newQueuedRetrySender(id config.ComponentID, signal config.DataType, qCfg Qu
 		nextSender:     nextSender,
 		stopCh:         retryStopCh,
 		logger:         sampledLogger,
+		deadLetter:     deadLetter,
 		// Following three functions actually depend on queuedRetrySender
 		onTemporaryFailure: qrs.onTemporaryFailure,
 	}
 
 	if qCfg.StorageID == nil {
-		qrs.queue = internal.NewBoundedMemoryQueue(qrs.cfg.QueueSize)
+		if qCfg.Priority != nil {
+			qrs.priorityQueue = newPriorityQueue(qrs)
+			qrs.queue = qrs.priorityQueue
+		} else {
+			qrs.queue = internal.NewBoundedMemoryQueue(qrs.cfg.QueueSize)
+		}
 	}
 	// The Persistent Queue is initialized separately as it needs extra information about the component
 
 	return qrs
 }
 
+func newPriorityQueue(qrs *queuedRetrySender) *internal.PriorityQueue {
+	tiers := qrs.cfg.Priority.Tiers
+	dropOldest := make([]bool, len(tiers))
+	droppedEntries := make([]*metric.Int64CumulativeEntry, len(tiers))
+	for i, tier := range tiers {
+		dropOldest[i] = tier.DropPolicy == DropOldest
+		droppedEntries[i], _ = globalInstruments.priorityQueueDropped.GetEntry(
+			metricdata.NewLabelValue(qrs.fullName), metricdata.NewLabelValue(tier.Name))
+	}
+
+	return internal.NewPriorityQueue(qrs.cfg.QueueSize, dropOldest, qrs.priorityOf, func(tier int, item internal.Request, evicted bool) {
+		droppedEntries[tier].Inc(int64(item.Count()))
+		// Rejected requests are reported to the caller, only evictions need to be logged.
+		if evicted {
+			qrs.logger.Warn(
+				"Dropping data from sending_queue to make room for data with a higher or equal priority.",
+				zap.String(priorityKey, tiers[tier].Name),
+				zap.Int("dropped_items", item.Count()),
+			)
+		}
+	})
+}
+
+// priorityOf returns the index of the priority tier of the request.
+func (qrs *queuedRetrySender) priorityOf(req internal.Request) int {
+	var name string
+	switch {
+	case qrs.priorityClassifier != nil:
+		name = qrs.priorityClassifier(req)
+	case qrs.cfg.Priority.MetadataKey != "":
+		if values := client.FromContext(req.Context()).Metadata.Get(qrs.cfg.Priority.MetadataKey); len(values) > 0 {
+			name = values[0]
+		}
+	}
+	return qrs.cfg.Priority.tierIndex(name)
+}
+
 func getStorageExtension(extensions map[config.ComponentID]component.Extension, storageID config.ComponentID) (storage.Extension, error) {
 	if ext, found := extensions[storageID]; found {
 		if storageExt, ok := ext.(storage.Extension); ok {
@@ -178,6 +305,7 @@ func (qrs *queuedRetrySender) onTemporaryFailure(logger *zap.Logger, req interna
 			zap.Error(err),
 			zap.Int("dropped_items", req.Count()),
 		)
+		qrs.deadLetter.send(req, err)
 		return err
 	}
 
@@ -192,6 +320,7 @@ func (qrs *queuedRetrySender) onTemporaryFailure(logger *zap.Logger, req interna
 			zap.Error(err),
 			zap.Int("dropped_items", req.Count()),
 		)
+		qrs.deadLetter.send(req, err)
 	}
 	return err
 }
@@ -223,6 +352,18 @@ func (qrs *queuedRetrySender) start(ctx context.Context, host component.Host) er
 		}
 	}
 
+	if qrs.priorityQueue != nil {
+		for i, tier := range qrs.cfg.Priority.Tiers {
+			i := i
+			err := globalInstruments.priorityQueueSize.UpsertEntry(func() int64 {
+				return int64(qrs.priorityQueue.TierSize(i))
+			}, metricdata.NewLabelValue(qrs.fullName), metricdata.NewLabelValue(tier.Name))
+			if err != nil {
+				return fmt.Errorf("failed to create retry queue priority size metric: %w", err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -234,6 +375,13 @@ func (qrs *queuedRetrySender) shutdown() {
 			return int64(0)
 		}, metricdata.NewLabelValue(qrs.fullName))
 	}
+	if qrs.priorityQueue != nil {
+		for _, tier := range qrs.cfg.Priority.Tiers {
+			_ = globalInstruments.priorityQueueSize.UpsertEntry(func() int64 {
+				return int64(0)
+			}, metricdata.NewLabelValue(qrs.fullName), metricdata.NewLabelValue(tier.Name))
+		}
+	}
 
 	// First Stop the retry goroutines, so that unblocks the queue numWorkers.
 	close(qrs.retryStopCh)
@@ -350,6 +498,7 @@ type retrySender struct {
 	nextSender         requestSender
 	stopCh             chan struct{}
 	logger             *zap.Logger
+	deadLetter         *deadLetterSender
 	onTemporaryFailure onRequestHandlingFinishedFunc
 }
 
@@ -362,6 +511,7 @@ func (rs *retrySender) send(req internal.Request) error {
 				"Exporting failed. Try enabling retry_on_failure config option to retry on retryable errors",
 				zap.Error(err),
 			)
+			rs.deadLetter.send(req, err)
 		}
 		return err
 	}
@@ -397,6 +547,7 @@ func (rs *retrySender) send(req internal.Request) error {
 				zap.Error(err),
 				zap.Int("dropped_items", req.Count()),
 			)
+			rs.deadLetter.send(req, err)
 			return err
 		}
 
@@ -432,6 +583,7 @@ func (rs *retrySender) send(req internal.Request) error {
 		retryNum++
 
 		// back-off, but get interrupted when shutting down or request is cancelled or timed out.
+		// The interrupted requests did not fail permanently, so they are not dead lettered.
 		select {
 		case <-req.Context().Done():
 			return fmt.Errorf("Request is cancelled or timed out %w", err)
@@ -30,6 +30,7 @@ import (
 	"go.opencensus.io/tag"
 	"go.uber.org/atomic"
 
+	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
@@ -384,6 +385,133 @@ func TestQueueSettings_Validate(t *testing.T) {
 	assert.NoError(t, qCfg.Validate())
 }
 
+func TestQueueSettings_ValidatePriority(t *testing.T) {
+	storageID := config.NewComponentID("file_storage")
+	testCases := []struct {
+		desc        string
+		priority    PrioritySettings
+		storageID   *config.ComponentID
+		expectedErr string
+	}{
+		{
+			desc:     "valid",
+			priority: PrioritySettings{Tiers: []PriorityTier{{Name: "high"}, {Name: "low", DropPolicy: DropOldest}}},
+		},
+		{
+			desc:        "no tiers",
+			priority:    PrioritySettings{},
+			expectedErr: "priority must define at least one tier",
+		},
+		{
+			desc:        "empty name",
+			priority:    PrioritySettings{Tiers: []PriorityTier{{Name: ""}}},
+			expectedErr: "priority tier name must not be empty",
+		},
+		{
+			desc:        "duplicate name",
+			priority:    PrioritySettings{Tiers: []PriorityTier{{Name: "high"}, {Name: "high"}}},
+			expectedErr: `duplicate priority tier "high"`,
+		},
+		{
+			desc:        "invalid drop policy",
+			priority:    PrioritySettings{Tiers: []PriorityTier{{Name: "high", DropPolicy: "drop_random"}}},
+			expectedErr: `unsupported drop_policy "drop_random" for priority tier "high"`,
+		},
+		{
+			desc:        "persistent queue",
+			priority:    PrioritySettings{Tiers: []PriorityTier{{Name: "high"}}},
+			storageID:   &storageID,
+			expectedErr: "priority is not supported with the persistent queue",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			qCfg := NewDefaultQueueSettings()
+			qCfg.StorageID = tC.storageID
+			qCfg.Priority = &tC.priority
+			if tC.expectedErr == "" {
+				assert.NoError(t, qCfg.Validate())
+			} else {
+				assert.EqualError(t, qCfg.Validate(), tC.expectedErr)
+			}
+		})
+	}
+}
+
+func TestQueuedRetry_PriorityMetadataKey(t *testing.T) {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 0 // to make every request go straight to the queue
+	qCfg.QueueSize = 2
+	qCfg.Priority = &PrioritySettings{
+		Tiers:       []PriorityTier{{Name: "high"}, {Name: "low", DropPolicy: DropOldest}},
+		MetadataKey: "tenant",
+	}
+	rCfg := NewDefaultRetrySettings()
+	cfg := config.NewExporterSettings(config.NewComponentID("priority"))
+	be := newBaseExporter(&cfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+
+	withTenant := func(tenant string) context.Context {
+		return client.NewContext(context.Background(), client.Info{
+			Metadata: client.NewMetadata(map[string][]string{"tenant": {tenant}}),
+		})
+	}
+
+	// Requests without a matching tier go to the lowest priority tier.
+	require.NoError(t, be.sender.send(newMockRequest(context.Background(), 3, nil)))
+	require.NoError(t, be.sender.send(newMockRequest(withTenant("low"), 3, nil)))
+	// The queue is full, high priority requests evict the low priority ones.
+	require.NoError(t, be.sender.send(newMockRequest(withTenant("high"), 1, nil)))
+	require.NoError(t, be.sender.send(newMockRequest(withTenant("high"), 1, nil)))
+	// Nothing left to evict, the high priority tier drops the newest requests.
+	require.ErrorIs(t, be.sender.send(newMockRequest(withTenant("high"), 1, nil)), errSendingQueueIsFull)
+
+	priorityTag, _ := tag.NewKey(priorityKey)
+	tags := func(priority string) []tag.Tag {
+		return []tag.Tag{{Key: exporterTag, Value: "priority"}, {Key: priorityTag, Value: priority}}
+	}
+	checkValueForGlobalManager(t, tags("high"), int64(2), "exporter/queue_size_by_priority")
+	checkValueForGlobalManager(t, tags("low"), int64(0), "exporter/queue_size_by_priority")
+	checkValueForGlobalManager(t, tags("high"), int64(1), "exporter/queue_dropped_items_by_priority")
+	checkValueForGlobalManager(t, tags("low"), int64(6), "exporter/queue_dropped_items_by_priority")
+
+	assert.NoError(t, be.Shutdown(context.Background()))
+	checkValueForGlobalManager(t, tags("high"), int64(0), "exporter/queue_size_by_priority")
+}
+
+func TestQueuedRetry_PriorityClassifier(t *testing.T) {
+	qCfg := NewDefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	qCfg.Priority = &PrioritySettings{
+		Tiers: []PriorityTier{{Name: "high"}, {Name: "low"}},
+	}
+	rCfg := NewDefaultRetrySettings()
+	classifier := func(o *baseSettings) {
+		o.priorityClassifier = func(req internal.Request) string {
+			if req.Count() > 1 {
+				return "high"
+			}
+			return "low"
+		}
+	}
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg), classifier), "", nopRequestUnmarshaler())
+
+	assert.Equal(t, 0, be.qrSender.priorityOf(newMockRequest(context.Background(), 2, nil)))
+	assert.Equal(t, 1, be.qrSender.priorityOf(newMockRequest(context.Background(), 1, nil)))
+
+	ocs := newObservabilityConsumerSender(be.qrSender.consumerSender)
+	be.qrSender.consumerSender = ocs
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	for i := 1; i <= 2; i++ {
+		ocs.run(func() {
+			require.NoError(t, be.sender.send(newMockRequest(context.Background(), i, nil)))
+		})
+	}
+	ocs.awaitAsyncProcessing()
+	ocs.checkSendItemsCount(t, 3)
+	assert.NoError(t, be.Shutdown(context.Background()))
+}
+
 func TestGetRetrySettings(t *testing.T) {
 	getStorageClientError := errors.New("unable to create storage client")
 	testCases := []struct {
@@ -701,10 +829,12 @@ func checkValueForGlobalManager(t *testing.T, wantTags []tag.Tag, value int64, v
 // checkValueForProducer checks that the given metrics with wantTags is reported by the metric producer
 func checkValueForProducer(t *testing.T, producer metricproducer.Producer, wantTags []tag.Tag, value int64, vName string) bool {
 	for _, metric := range producer.Read() {
-		if metric.Descriptor.Name == vName && len(metric.TimeSeries) > 0 {
-			lastValue := metric.TimeSeries[len(metric.TimeSeries)-1]
-			if tagsMatchLabelKeys(wantTags, metric.Descriptor.LabelKeys, lastValue.LabelValues) {
-				require.Equal(t, value, lastValue.Points[len(lastValue.Points)-1].Value.(int64))
+		if metric.Descriptor.Name != vName {
+			continue
+		}
+		for _, ts := range metric.TimeSeries {
+			if tagsMatchLabelKeys(wantTags, metric.Descriptor.LabelKeys, ts.LabelValues) {
+				require.Equal(t, value, ts.Points[len(ts.Points)-1].Value.(int64))
 				return true
 			}
 		}
@@ -0,0 +1,207 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+
+	"go.opencensus.io/metric"
+	"go.opencensus.io/metric/metricdata"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+type messageTooLarge struct {
+	err error
+}
+
+func (m messageTooLarge) Error() string {
+	return "Message too large, error: " + m.err.Error()
+}
+
+func (m messageTooLarge) Unwrap() error {
+	return m.err
+}
+
+// NewMessageTooLarge creates a new error reporting that the request was rejected because of its size.
+// The request is split in halves which are sent again, down to single items.
+func NewMessageTooLarge(err error) error {
+	return messageTooLarge{err: err}
+}
+
+// splittableRequest is a request that can be split in halves.
+type splittableRequest interface {
+	internal.Request
+	// split returns the two halves of the request, which must have more than one item.
+	split() (internal.Request, internal.Request)
+	// merge returns a request with the items of the request and of the other request.
+	merge(other internal.Request) internal.Request
+	// partialError returns an error reporting the items of the request as failed with the given error.
+	partialError(err error) error
+}
+
+// splitSender is a requestSender that splits the requests rejected as too large in halves, and sends them again.
+type splitSender struct {
+	nextSender requestSender
+	logger     *zap.Logger
+	splitEntry *metric.Int64CumulativeEntry
+}
+
+func newSplitSender(id config.ComponentID, logger *zap.Logger, nextSender requestSender) *splitSender {
+	splitEntry, _ := globalInstruments.splitRequests.GetEntry(metricdata.NewLabelValue(id.String()))
+	return &splitSender{
+		nextSender: nextSender,
+		logger:     logger,
+		splitEntry: splitEntry,
+	}
+}
+
+// send implements the requestSender interface
+func (ss *splitSender) send(req internal.Request) error {
+	err := ss.nextSender.send(req)
+	if err == nil || !errors.As(err, &messageTooLarge{}) || req.Count() <= 1 {
+		return err
+	}
+	sr, ok := req.(splittableRequest)
+	if !ok {
+		return err
+	}
+
+	first, second := sr.split()
+	ss.splitEntry.Inc(1)
+	ss.logger.Debug(
+		"Exporting failed, the request is too large. Splitting the request in halves.",
+		zap.Error(err),
+		zap.Int("items", req.Count()),
+	)
+
+	firstErr := ss.send(first)
+	secondErr := ss.send(second)
+	switch {
+	case firstErr == nil && secondErr == nil:
+		return nil
+	case secondErr == nil:
+		// Only report the items that failed, so that they are the only ones retried.
+		return first.OnError(firstErr).(splittableRequest).partialError(firstErr)
+	case firstErr == nil:
+		return second.OnError(secondErr).(splittableRequest).partialError(secondErr)
+	}
+	failed := first.OnError(firstErr).(splittableRequest).merge(second.OnError(secondErr))
+	return failed.(splittableRequest).partialError(multierr.Append(firstErr, secondErr))
+}
+
+// splitTraces returns the two halves of the spans of td, without modifying it.
+func splitTraces(td ptrace.Traces) (ptrace.Traces, ptrace.Traces) {
+	count := td.SpanCount()
+	first := td.Clone()
+	keepSpans(first, 0, count/2)
+	second := td.Clone()
+	keepSpans(second, count/2, count)
+	return first, second
+}
+
+// keepSpans removes the spans of td outside of the [from, to) range, and the resources and scopes left empty.
+func keepSpans(td ptrace.Traces, from, to int) {
+	keep := newRangeFilter(from, to)
+	td.ResourceSpans().RemoveIf(func(rs ptrace.ResourceSpans) bool {
+		rs.ScopeSpans().RemoveIf(func(ss ptrace.ScopeSpans) bool {
+			ss.Spans().RemoveIf(func(ptrace.Span) bool { return !keep() })
+			return ss.Spans().Len() == 0
+		})
+		return rs.ScopeSpans().Len() == 0
+	})
+}
+
+// splitMetrics returns the two halves of the data points of md, without modifying it.
+func splitMetrics(md pmetric.Metrics) (pmetric.Metrics, pmetric.Metrics) {
+	count := md.DataPointCount()
+	first := md.Clone()
+	keepDataPoints(first, 0, count/2)
+	second := md.Clone()
+	keepDataPoints(second, count/2, count)
+	return first, second
+}
+
+// keepDataPoints removes the data points of md outside of the [from, to) range, and the metrics, resources and
+// scopes left empty.
+func keepDataPoints(md pmetric.Metrics, from, to int) {
+	keep := newRangeFilter(from, to)
+	md.ResourceMetrics().RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		rm.ScopeMetrics().RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+			sm.Metrics().RemoveIf(func(m pmetric.Metric) bool {
+				switch m.DataType() {
+				case pmetric.MetricDataTypeGauge:
+					m.Gauge().DataPoints().RemoveIf(func(pmetric.NumberDataPoint) bool { return !keep() })
+					return m.Gauge().DataPoints().Len() == 0
+				case pmetric.MetricDataTypeSum:
+					m.Sum().DataPoints().RemoveIf(func(pmetric.NumberDataPoint) bool { return !keep() })
+					return m.Sum().DataPoints().Len() == 0
+				case pmetric.MetricDataTypeHistogram:
+					m.Histogram().DataPoints().RemoveIf(func(pmetric.HistogramDataPoint) bool { return !keep() })
+					return m.Histogram().DataPoints().Len() == 0
+				case pmetric.MetricDataTypeExponentialHistogram:
+					m.ExponentialHistogram().DataPoints().RemoveIf(func(pmetric.ExponentialHistogramDataPoint) bool { return !keep() })
+					return m.ExponentialHistogram().DataPoints().Len() == 0
+				case pmetric.MetricDataTypeSummary:
+					m.Summary().DataPoints().RemoveIf(func(pmetric.SummaryDataPoint) bool { return !keep() })
+					return m.Summary().DataPoints().Len() == 0
+				}
+				return true
+			})
+			return sm.Metrics().Len() == 0
+		})
+		return rm.ScopeMetrics().Len() == 0
+	})
+}
+
+// splitLogs returns the two halves of the log records of ld, without modifying it.
+func splitLogs(ld plog.Logs) (plog.Logs, plog.Logs) {
+	count := ld.LogRecordCount()
+	first := ld.Clone()
+	keepLogRecords(first, 0, count/2)
+	second := ld.Clone()
+	keepLogRecords(second, count/2, count)
+	return first, second
+}
+
+// keepLogRecords removes the log records of ld outside of the [from, to) range, and the resources and scopes
+// left empty.
+func keepLogRecords(ld plog.Logs, from, to int) {
+	keep := newRangeFilter(from, to)
+	ld.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(plog.LogRecord) bool { return !keep() })
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+}
+
+// newRangeFilter returns a function called once per item, in order, that reports whether the item is in the
+// [from, to) range.
+func newRangeFilter(from, to int) func() bool {
+	i := 0
+	return func() bool {
+		in := i >= from && i < to
+		i++
+		return in
+	}
+}
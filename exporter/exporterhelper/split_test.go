@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestSplitTraces(t *testing.T) {
+	td := testdata.GenerateTraces(5)
+	td.ResourceSpans().At(0).CopyTo(td.ResourceSpans().AppendEmpty())
+	orig := td.Clone()
+
+	first, second := splitTraces(td)
+	assert.Equal(t, orig, td)
+	assert.Equal(t, 5, first.SpanCount())
+	assert.Equal(t, 1, first.ResourceSpans().Len())
+	assert.Equal(t, 5, second.SpanCount())
+	assert.Equal(t, 1, second.ResourceSpans().Len())
+
+	first, second = splitTraces(testdata.GenerateTraces(3))
+	assert.Equal(t, 1, first.SpanCount())
+	assert.Equal(t, 2, second.SpanCount())
+}
+
+func TestSplitMetrics(t *testing.T) {
+	md := testdata.GenerateMetricsAllTypes()
+	count := md.DataPointCount()
+
+	first, second := splitMetrics(md)
+	assert.Equal(t, count, md.DataPointCount())
+	assert.Equal(t, count/2, first.DataPointCount())
+	assert.Equal(t, count-count/2, second.DataPointCount())
+	assert.Equal(t, md.MetricCount(), first.MetricCount()+second.MetricCount()-1)
+}
+
+func TestSplitLogs(t *testing.T) {
+	ld := testdata.GenerateLogs(7)
+
+	first, second := splitLogs(ld)
+	assert.Equal(t, 7, ld.LogRecordCount())
+	assert.Equal(t, 3, first.LogRecordCount())
+	assert.Equal(t, 4, second.LogRecordCount())
+	assert.Equal(t, ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(3),
+		second.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0))
+}
+
+func TestSplitSender_Traces(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("test", "split_traces"))
+	te, err := NewTracesExporterWithContext(context.Background(), componenttest.NewNopExporterCreateSettings(), &cfg,
+		func(ctx context.Context, td ptrace.Traces) error {
+			if td.SpanCount() > 2 {
+				return NewMessageTooLarge(consumererror.NewPermanent(errors.New("too large")))
+			}
+			return sink.ConsumeTraces(ctx, td)
+		})
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { assert.NoError(t, te.Shutdown(context.Background())) })
+
+	require.NoError(t, te.ConsumeTraces(context.Background(), testdata.GenerateTraces(7)))
+	assert.Equal(t, 7, sink.SpanCount())
+	assert.Len(t, sink.AllTraces(), 4)
+	// 7 -> 3 + 4, 3 -> 1 + 2, 4 -> 2 + 2.
+	checkValueForGlobalManager(t, tagsForExporterView(cfg.ID()), int64(3), "exporter/split_requests")
+}
+
+func TestSplitSender_PartialFailure(t *testing.T) {
+	tooLarge := NewMessageTooLarge(consumererror.NewPermanent(errors.New("too large")))
+	sink := new(consumertest.LogsSink)
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("test", "split_logs"))
+	le, err := NewLogsExporterWithContext(context.Background(), componenttest.NewNopExporterCreateSettings(), &cfg,
+		func(ctx context.Context, ld plog.Logs) error {
+			// Every other log record is too large on its own.
+			if ld.LogRecordCount() > 1 || ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().StringVal() == "This is a log message" {
+				return tooLarge
+			}
+			return sink.ConsumeLogs(ctx, ld)
+		})
+	require.NoError(t, err)
+	require.NoError(t, le.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { assert.NoError(t, le.Shutdown(context.Background())) })
+
+	ld := testdata.GenerateLogs(4)
+	err = le.ConsumeLogs(context.Background(), ld)
+	require.Error(t, err)
+	assert.True(t, consumererror.IsPermanent(err))
+	var logsErr consumererror.Logs
+	require.ErrorAs(t, err, &logsErr)
+	assert.Equal(t, 2, logsErr.GetLogs().LogRecordCount())
+	assert.Equal(t, 2, sink.LogRecordCount())
+}
+
+func TestSplitSender_Metrics(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	cfg := config.NewExporterSettings(config.NewComponentIDWithName("test", "split_metrics"))
+	me, err := NewMetricsExporterWithContext(context.Background(), componenttest.NewNopExporterCreateSettings(), &cfg,
+		func(ctx context.Context, md pmetric.Metrics) error {
+			if md.DataPointCount() > 1 {
+				return NewMessageTooLarge(errors.New("too large"))
+			}
+			return sink.ConsumeMetrics(ctx, md)
+		})
+	require.NoError(t, err)
+	require.NoError(t, me.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { assert.NoError(t, me.Shutdown(context.Background())) })
+
+	md := testdata.GenerateMetricsAllTypes()
+	require.NoError(t, me.ConsumeMetrics(context.Background(), md))
+	assert.Equal(t, md.DataPointCount(), sink.DataPointCount())
+}
@@ -66,6 +66,21 @@ func (req *tracesRequest) OnError(err error) internal.Request {
 	return req
 }
 
+func (req *tracesRequest) split() (internal.Request, internal.Request) {
+	first, second := splitTraces(req.td)
+	return newTracesRequest(req.ctx, first, req.pusher), newTracesRequest(req.ctx, second, req.pusher)
+}
+
+func (req *tracesRequest) merge(other internal.Request) internal.Request {
+	merged := req.td.Clone()
+	other.(*tracesRequest).td.Clone().ResourceSpans().MoveAndAppendTo(merged.ResourceSpans())
+	return newTracesRequest(req.ctx, merged, req.pusher)
+}
+
+func (req *tracesRequest) partialError(err error) error {
+	return consumererror.NewTraces(err, req.td)
+}
+
 func (req *tracesRequest) Export(ctx context.Context) error {
 	return req.pusher(ctx, req.td)
 }
@@ -79,6 +94,20 @@ type traceExporter struct {
 	consumer.Traces
 }
 
+// WithTracesPriority sets the function that classifies the traces into the priority tiers of the sending queue,
+// by returning the name of a tier. It takes precedence over the configured priority metadata key, and is
+// only used when the sending queue has priority tiers configured.
+func WithTracesPriority(classify func(ctx context.Context, td ptrace.Traces) string) Option {
+	return func(o *baseSettings) {
+		o.priorityClassifier = func(req internal.Request) string {
+			if r, ok := req.(*tracesRequest); ok {
+				return classify(r.Context(), r.td)
+			}
+			return ""
+		}
+	}
+}
+
 // Deprecated: [v0.58.0] use NewTracesExporterWithContext.
 func NewTracesExporter(
 	cfg config.Exporter,
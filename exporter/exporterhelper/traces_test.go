@@ -16,6 +16,7 @@ package exporterhelper
 import (
 	"context"
 	"errors"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -263,3 +264,13 @@ func checkWrapSpanForTracesExporter(t *testing.T, sr *tracetest.SpanRecorder, tr
 		require.Containsf(t, sd.Attributes(), attribute.KeyValue{Key: obsmetrics.FailedToSendSpansKey, Value: attribute.Int64Value(failedToSendSpans)}, "SpanData %v", sd)
 	}
 }
+
+func TestTracesPriority(t *testing.T) {
+	bs := fromOptions(WithTracesPriority(func(_ context.Context, td ptrace.Traces) string {
+		return strconv.Itoa(td.SpanCount())
+	}))
+
+	td := testdata.GenerateTraces(2)
+	assert.Equal(t, strconv.Itoa(td.SpanCount()), bs.priorityClassifier(newTracesRequest(context.Background(), td, nil)))
+	assert.Equal(t, "", bs.priorityClassifier(newMockRequest(context.Background(), 1, nil)))
+}
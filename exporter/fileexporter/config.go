@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter // import "go.opentelemetry.io/collector/exporter/fileexporter"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configbytes"
+	"go.opentelemetry.io/collector/config/configcompression"
+)
+
+const (
+	formatJSON  = "json"
+	formatProto = "proto"
+)
+
+// Config defines configuration for file exporter.
+type Config struct {
+	config.ExporterSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Path is the path of the file the data is written to.
+	Path string `mapstructure:"path"`
+
+	// Format is the encoding of the data: "json" writes a line of OTLP/JSON per batch, "proto"
+	// writes each batch as OTLP/protobuf prefixed with its signal as a byte, 1 for traces, 2 for
+	// metrics and 3 for logs, and its length as a 4 bytes big-endian integer.
+	Format string `mapstructure:"format"`
+
+	// Compression is the compression of the files, "gzip", "zstd" or "none".
+	Compression configcompression.CompressionType `mapstructure:"compression"`
+
+	// FlushInterval is the interval between the flushes of the buffered data to the file.
+	// Zero flushes after every batch.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+
+	// Rotation configures when the file is rotated.
+	Rotation RotationConfig `mapstructure:"rotation"`
+}
+
+// RotationConfig configures the rotation of the file. When rotated, the file is renamed by
+// appending the rotation time to its name, e.g. "data-2022-08-01T10-00-00.000.json", and a
+// new file is created.
+type RotationConfig struct {
+	// MaxSize is the size of the file, after compression, before it is rotated.
+	// Zero disables it.
+	MaxSize configbytes.ByteSize `mapstructure:"max_size"`
+
+	// Interval is the maximum time data is written to a file before it is rotated.
+	// Zero disables it.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// MaxBackups is the number of rotated files to keep, the oldest are deleted.
+	// Zero keeps all of them.
+	MaxBackups int `mapstructure:"max_backups"`
+}
+
+var _ config.Exporter = (*Config)(nil)
+
+// Validate checks if the exporter configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Path == "" {
+		return errors.New("path must be specified")
+	}
+	if cfg.Format != formatJSON && cfg.Format != formatProto {
+		return fmt.Errorf("format %q is not supported, must be %q or %q", cfg.Format, formatJSON, formatProto)
+	}
+	if configcompression.IsCompressed(cfg.Compression) && cfg.Compression != configcompression.Gzip && cfg.Compression != configcompression.Zstd {
+		return fmt.Errorf("compression %q is not supported, must be %q or %q", cfg.Compression, configcompression.Gzip, configcompression.Zstd)
+	}
+	if cfg.FlushInterval < 0 {
+		return errors.New("flush_interval must not be negative")
+	}
+	if cfg.Rotation.MaxSize < 0 || cfg.Rotation.Interval < 0 || cfg.Rotation.MaxBackups < 0 {
+		return errors.New("rotation settings must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter // import "go.opentelemetry.io/collector/exporter/fileexporter"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configbytes"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/internal/sharedcomponent"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "file"
+
+	defaultFlushInterval = time.Second
+	defaultMaxSize       = 100 * configbytes.Mebibyte
+	defaultMaxBackups    = 100
+)
+
+// NewFactory creates a factory for the file exporter.
+func NewFactory() component.ExporterFactory {
+	return component.NewExporterFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithTracesExporter(createTracesExporter, component.StabilityLevelAlpha),
+		component.WithMetricsExporter(createMetricsExporter, component.StabilityLevelAlpha),
+		component.WithLogsExporter(createLogsExporter, component.StabilityLevelAlpha),
+	)
+}
+
+func createDefaultConfig() config.Exporter {
+	return &Config{
+		ExporterSettings: config.NewExporterSettings(config.NewComponentID(typeStr)),
+		Format:           formatJSON,
+		FlushInterval:    defaultFlushInterval,
+		Rotation: RotationConfig{
+			MaxSize:    defaultMaxSize,
+			MaxBackups: defaultMaxBackups,
+		},
+	}
+}
+
+func createTracesExporter(ctx context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.TracesExporter, error) {
+	fe := getOrCreateFileExporter(cfg.(*Config))
+	return exporterhelper.NewTracesExporterWithContext(ctx, set, cfg,
+		fe.Unwrap().(*fileExporter).pushTraces,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		// Disable Timeout/RetryOnFailure and SendingQueue
+		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
+		exporterhelper.WithRetry(exporterhelper.RetrySettings{Enabled: false}),
+		exporterhelper.WithQueue(exporterhelper.QueueSettings{Enabled: false}),
+		exporterhelper.WithStart(fe.Start),
+		exporterhelper.WithShutdown(fe.Shutdown),
+	)
+}
+
+func createMetricsExporter(ctx context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.MetricsExporter, error) {
+	fe := getOrCreateFileExporter(cfg.(*Config))
+	return exporterhelper.NewMetricsExporterWithContext(ctx, set, cfg,
+		fe.Unwrap().(*fileExporter).pushMetrics,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		// Disable Timeout/RetryOnFailure and SendingQueue
+		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
+		exporterhelper.WithRetry(exporterhelper.RetrySettings{Enabled: false}),
+		exporterhelper.WithQueue(exporterhelper.QueueSettings{Enabled: false}),
+		exporterhelper.WithStart(fe.Start),
+		exporterhelper.WithShutdown(fe.Shutdown),
+	)
+}
+
+func createLogsExporter(ctx context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.LogsExporter, error) {
+	fe := getOrCreateFileExporter(cfg.(*Config))
+	return exporterhelper.NewLogsExporterWithContext(ctx, set, cfg,
+		fe.Unwrap().(*fileExporter).pushLogs,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		// Disable Timeout/RetryOnFailure and SendingQueue
+		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
+		exporterhelper.WithRetry(exporterhelper.RetrySettings{Enabled: false}),
+		exporterhelper.WithQueue(exporterhelper.QueueSettings{Enabled: false}),
+		exporterhelper.WithStart(fe.Start),
+		exporterhelper.WithShutdown(fe.Shutdown),
+	)
+}
+
+// getOrCreateFileExporter returns the exporter for the configuration, shared by the signals
+// so that they write to the same file.
+func getOrCreateFileExporter(cfg *Config) *sharedcomponent.SharedComponent {
+	return exporters.GetOrAdd(cfg, func() component.Component {
+		return newFileExporter(cfg)
+	})
+}
+
+// This is the map of already created file exporters for particular configurations.
+// We maintain this map because the Factory is asked trace, metric and log exporters separately
+// but they must not create separate objects, they must use one exporter object per configuration.
+var exporters = sharedcomponent.NewSharedComponents()
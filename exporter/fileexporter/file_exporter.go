@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter // import "go.opentelemetry.io/collector/exporter/fileexporter"
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// The signal of the data in a proto record, written before its length since the traces, metrics and logs
+// share the same file.
+const (
+	protoSignalTraces byte = iota + 1
+	protoSignalMetrics
+	protoSignalLogs
+)
+
+type fileExporter struct {
+	cfg    *Config
+	writer *rotatingWriter
+
+	tracesMarshaler  ptrace.Marshaler
+	metricsMarshaler pmetric.Marshaler
+	logsMarshaler    plog.Marshaler
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newFileExporter(cfg *Config) *fileExporter {
+	fe := &fileExporter{
+		cfg:    cfg,
+		writer: newRotatingWriter(cfg.Path, cfg.Compression, cfg.Rotation),
+		stopCh: make(chan struct{}),
+	}
+	if cfg.Format == formatProto {
+		fe.tracesMarshaler = ptrace.NewProtoMarshaler()
+		fe.metricsMarshaler = pmetric.NewProtoMarshaler()
+		fe.logsMarshaler = plog.NewProtoMarshaler()
+	} else {
+		fe.tracesMarshaler = ptrace.NewJSONMarshaler()
+		fe.metricsMarshaler = pmetric.NewJSONMarshaler()
+		fe.logsMarshaler = plog.NewJSONMarshaler()
+	}
+	return fe
+}
+
+func (fe *fileExporter) Start(context.Context, component.Host) error {
+	if err := fe.writer.open(); err != nil {
+		return err
+	}
+	if fe.cfg.FlushInterval > 0 {
+		fe.wg.Add(1)
+		go fe.flushLoop()
+	}
+	return nil
+}
+
+func (fe *fileExporter) Shutdown(context.Context) error {
+	close(fe.stopCh)
+	fe.wg.Wait()
+	return fe.writer.close()
+}
+
+func (fe *fileExporter) flushLoop() {
+	defer fe.wg.Done()
+	ticker := time.NewTicker(fe.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-fe.stopCh:
+			return
+		case <-ticker.C:
+			// The errors are returned by the next write.
+			_ = fe.writer.flush()
+		}
+	}
+}
+
+func (fe *fileExporter) pushTraces(_ context.Context, td ptrace.Traces) error {
+	buf, err := fe.tracesMarshaler.MarshalTraces(td)
+	if err != nil {
+		return err
+	}
+	return fe.write(protoSignalTraces, buf)
+}
+
+func (fe *fileExporter) pushMetrics(_ context.Context, md pmetric.Metrics) error {
+	buf, err := fe.metricsMarshaler.MarshalMetrics(md)
+	if err != nil {
+		return err
+	}
+	return fe.write(protoSignalMetrics, buf)
+}
+
+func (fe *fileExporter) pushLogs(_ context.Context, ld plog.Logs) error {
+	buf, err := fe.logsMarshaler.MarshalLogs(ld)
+	if err != nil {
+		return err
+	}
+	return fe.write(protoSignalLogs, buf)
+}
+
+// write frames the batch according to the format and writes it. The JSON records are identified
+// by their top-level field, the proto records are prefixed with the signal.
+func (fe *fileExporter) write(signal byte, buf []byte) error {
+	var record []byte
+	if fe.cfg.Format == formatProto {
+		record = make([]byte, 5, 5+len(buf))
+		record[0] = signal
+		binary.BigEndian.PutUint32(record[1:], uint32(len(buf)))
+		record = append(record, buf...)
+	} else {
+		record = append(buf, '\n')
+	}
+	return fe.writer.write(record, fe.cfg.FlushInterval == 0)
+}
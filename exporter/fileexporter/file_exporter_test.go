@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func newTestFileExporter(t *testing.T, modify func(cfg *Config)) *fileExporter {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Path = filepath.Join(t.TempDir(), "data")
+	modify(cfg)
+	fe := newFileExporter(cfg)
+	require.NoError(t, fe.Start(context.Background(), componenttest.NewNopHost()))
+	return fe
+}
+
+func readFile(t *testing.T, path string, compression configcompression.CompressionType) []byte {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var r io.Reader = f
+	switch compression {
+	case configcompression.Gzip:
+		gr, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		r = gr
+	case configcompression.Zstd:
+		zr, err := zstd.NewReader(f)
+		require.NoError(t, err)
+		defer zr.Close()
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return data
+}
+
+func TestFileExporterJSON(t *testing.T) {
+	for _, compression := range []configcompression.CompressionType{"", configcompression.Gzip, configcompression.Zstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			fe := newTestFileExporter(t, func(cfg *Config) { cfg.Compression = compression })
+
+			td := testdata.GenerateTraces(2)
+			md := testdata.GenerateMetrics(2)
+			ld := testdata.GenerateLogs(2)
+			require.NoError(t, fe.pushTraces(context.Background(), td))
+			require.NoError(t, fe.pushMetrics(context.Background(), md))
+			require.NoError(t, fe.pushLogs(context.Background(), ld))
+			require.NoError(t, fe.Shutdown(context.Background()))
+
+			scanner := bufio.NewScanner(bytes.NewReader(readFile(t, fe.cfg.Path, compression)))
+			require.True(t, scanner.Scan())
+			gotTraces, err := ptrace.NewJSONUnmarshaler().UnmarshalTraces(scanner.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, td, gotTraces)
+			require.True(t, scanner.Scan())
+			gotMetrics, err := pmetric.NewJSONUnmarshaler().UnmarshalMetrics(scanner.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, md, gotMetrics)
+			require.True(t, scanner.Scan())
+			gotLogs, err := plog.NewJSONUnmarshaler().UnmarshalLogs(scanner.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, ld, gotLogs)
+			assert.False(t, scanner.Scan())
+		})
+	}
+}
+
+func TestFileExporterProto(t *testing.T) {
+	fe := newTestFileExporter(t, func(cfg *Config) {
+		cfg.Format = formatProto
+		cfg.FlushInterval = 0
+	})
+	td := testdata.GenerateTraces(1)
+	md := testdata.GenerateMetrics(1)
+	ld := testdata.GenerateLogs(1)
+	require.NoError(t, fe.pushTraces(context.Background(), td))
+	require.NoError(t, fe.pushMetrics(context.Background(), md))
+	require.NoError(t, fe.pushLogs(context.Background(), ld))
+
+	// The data is flushed after every batch when the flush interval is zero.
+	data := readFile(t, fe.cfg.Path, "")
+	next := func(signal byte) []byte {
+		require.GreaterOrEqual(t, len(data), 5)
+		assert.Equal(t, signal, data[0])
+		size := binary.BigEndian.Uint32(data[1:])
+		record := data[5 : 5+size]
+		data = data[5+size:]
+		return record
+	}
+	gotTraces, err := ptrace.NewProtoUnmarshaler().UnmarshalTraces(next(protoSignalTraces))
+	require.NoError(t, err)
+	assert.Equal(t, td, gotTraces)
+	gotMetrics, err := pmetric.NewProtoUnmarshaler().UnmarshalMetrics(next(protoSignalMetrics))
+	require.NoError(t, err)
+	assert.Equal(t, md, gotMetrics)
+	gotLogs, err := plog.NewProtoUnmarshaler().UnmarshalLogs(next(protoSignalLogs))
+	require.NoError(t, err)
+	assert.Equal(t, ld, gotLogs)
+	assert.Empty(t, data)
+	require.NoError(t, fe.Shutdown(context.Background()))
+}
+
+func TestFileExporterFlushInterval(t *testing.T) {
+	fe := newTestFileExporter(t, func(cfg *Config) { cfg.FlushInterval = 10 * time.Millisecond })
+	require.NoError(t, fe.pushLogs(context.Background(), testdata.GenerateLogs(1)))
+	assert.Eventually(t, func() bool {
+		return len(readFile(t, fe.cfg.Path, "")) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, fe.Shutdown(context.Background()))
+
+	assert.ErrorIs(t, fe.pushLogs(context.Background(), testdata.GenerateLogs(1)), errWriterClosed)
+}
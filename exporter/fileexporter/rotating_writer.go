@@ -0,0 +1,255 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter // import "go.opentelemetry.io/collector/exporter/fileexporter"
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"go.uber.org/multierr"
+
+	"go.opentelemetry.io/collector/config/configcompression"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+var errWriterClosed = errors.New("file exporter is not started")
+
+// flushWriteCloser is a writer that buffers the data, such as a compressor.
+type flushWriteCloser interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// nopFlushCloser wraps the file when the data is not compressed, the file is closed separately.
+type nopFlushCloser struct {
+	io.Writer
+}
+
+func (nopFlushCloser) Flush() error { return nil }
+func (nopFlushCloser) Close() error { return nil }
+
+// countingWriter counts the bytes written to the file, after compression.
+type countingWriter struct {
+	io.Writer
+	count *int64
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.Writer.Write(p)
+	*c.count += int64(n)
+	return n, err
+}
+
+// rotatingWriter writes the data to a file, through a buffer and the compressor, and rotates
+// the file when it reaches the configured size or age. It is safe for concurrent use.
+type rotatingWriter struct {
+	path        string
+	compression configcompression.CompressionType
+	rotation    RotationConfig
+	now         func() time.Time
+
+	mu sync.Mutex
+	// file is nil if the writer is closed.
+	file       *os.File
+	compressor flushWriteCloser
+	buf        *bufio.Writer
+	// size is the size of the file, including the data appended to it after compression.
+	// The data buffered, or held by the compressor, is not counted until it is flushed.
+	size     int64
+	openedAt time.Time
+}
+
+func newRotatingWriter(path string, compression configcompression.CompressionType, rotation RotationConfig) *rotatingWriter {
+	return &rotatingWriter{
+		path:        path,
+		compression: compression,
+		rotation:    rotation,
+		now:         time.Now,
+	}
+}
+
+func (w *rotatingWriter) open() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.openFile()
+}
+
+// openFile opens the file, appending to it if it exists. Both gzip and zstd support
+// concatenated streams, so a compressed file can be appended to.
+func (w *rotatingWriter) openFile() error {
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return multierr.Combine(err, file.Close())
+	}
+
+	counting := countingWriter{Writer: file, count: &w.size}
+	switch w.compression {
+	case configcompression.Gzip:
+		w.compressor = gzip.NewWriter(counting)
+	case configcompression.Zstd:
+		if w.compressor, err = zstd.NewWriter(counting); err != nil {
+			return multierr.Combine(err, file.Close())
+		}
+	default:
+		w.compressor = nopFlushCloser{Writer: counting}
+	}
+	w.file = file
+	w.buf = bufio.NewWriter(w.compressor)
+	w.size = info.Size()
+	w.openedAt = w.now()
+	return nil
+}
+
+// write writes the record to the file, rotating it before if needed, and flushes it if requested.
+func (w *rotatingWriter) write(record []byte, flush bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return errWriterClosed
+	}
+	var rotateErr error
+	if w.shouldRotate(len(record)) {
+		if err := w.rotate(); err != nil {
+			rotateErr = fmt.Errorf("failed to rotate %q: %w", w.path, err)
+			// The record is still written if the rotation only failed to close the previous file.
+			if w.file == nil {
+				return rotateErr
+			}
+		}
+	}
+	if _, err := w.buf.Write(record); err != nil {
+		return multierr.Combine(rotateErr, err)
+	}
+	if flush {
+		return multierr.Combine(rotateErr, w.flushLocked())
+	}
+	return rotateErr
+}
+
+func (w *rotatingWriter) shouldRotate(recordSize int) bool {
+	if w.size == 0 && w.buf.Buffered() == 0 {
+		return false
+	}
+	if w.rotation.MaxSize > 0 && w.sizeAfter(recordSize) > int64(w.rotation.MaxSize) {
+		return true
+	}
+	return w.rotation.Interval > 0 && w.now().Sub(w.openedAt) >= w.rotation.Interval
+}
+
+// sizeAfter estimates the size of the file once the record is written. The compressed size of the
+// buffered data and of the record is only known once flushed, so only the flushed data is accounted
+// for when the file is compressed.
+func (w *rotatingWriter) sizeAfter(recordSize int) int64 {
+	if w.compression == configcompression.Gzip || w.compression == configcompression.Zstd {
+		return w.size
+	}
+	return w.size + int64(w.buf.Buffered()) + int64(recordSize)
+}
+
+// rotate closes the file, renames it with the current time, deletes the backups in excess
+// and opens a new file.
+func (w *rotatingWriter) rotate() error {
+	// The file is released even if it fails to be closed, e.g. the buffered data fails to be
+	// flushed, so the rotation goes on, and the new file is opened regardless.
+	var closeErr error
+	if err := w.closeFile(); err != nil {
+		closeErr = fmt.Errorf("failed to close the rotated file: %w", err)
+	}
+	ext := filepath.Ext(w.path)
+	backup := strings.TrimSuffix(w.path, ext) + "-" + w.now().UTC().Format(backupTimeFormat) + ext
+	if err := os.Rename(w.path, backup); err != nil {
+		return multierr.Combine(closeErr, err, w.openFile())
+	}
+	if err := w.removeOldBackups(); err != nil {
+		return multierr.Combine(closeErr, err, w.openFile())
+	}
+	return multierr.Combine(closeErr, w.openFile())
+}
+
+func (w *rotatingWriter) removeOldBackups() error {
+	if w.rotation.MaxBackups == 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(filepath.Dir(w.path))
+	if err != nil {
+		return err
+	}
+	ext := filepath.Ext(w.path)
+	prefix := strings.TrimSuffix(filepath.Base(w.path), ext) + "-"
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && len(name) == len(prefix)+len(backupTimeFormat)+len(ext) &&
+			strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ext) {
+			backups = append(backups, name)
+		}
+	}
+	if len(backups) <= w.rotation.MaxBackups {
+		return nil
+	}
+	// The time in the names sorts them from the oldest to the newest.
+	sort.Strings(backups)
+	var errs error
+	for _, backup := range backups[:len(backups)-w.rotation.MaxBackups] {
+		errs = multierr.Append(errs, os.Remove(filepath.Join(filepath.Dir(w.path), backup)))
+	}
+	return errs
+}
+
+func (w *rotatingWriter) flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.flushLocked()
+}
+
+func (w *rotatingWriter) flushLocked() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	return w.compressor.Flush()
+}
+
+func (w *rotatingWriter) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.closeFile()
+}
+
+func (w *rotatingWriter) closeFile() error {
+	err := multierr.Combine(w.buf.Flush(), w.compressor.Close(), w.file.Close())
+	w.file, w.compressor, w.buf = nil, nil, nil
+	return err
+}
@@ -0,0 +1,154 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/config/configcompression"
+)
+
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotatingWriterMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	w := newRotatingWriter(filepath.Join(dir, "data.json"), "", RotationConfig{MaxSize: 10, MaxBackups: 2})
+	w.now = func() time.Time { return now }
+	require.NoError(t, w.open())
+
+	for _, record := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		require.NoError(t, w.write([]byte(record), false))
+		now = now.Add(time.Second)
+	}
+	require.NoError(t, w.close())
+
+	// Each record is larger than half the max size, so each one is in its own file,
+	// and only the 2 most recent backups are kept.
+	assert.Equal(t, []string{"data-2022-08-01T10-00-02.000.json", "data-2022-08-01T10-00-03.000.json", "data.json"}, listDir(t, dir))
+	assert.Equal(t, "bbbbbb\n", string(readFile(t, filepath.Join(dir, "data-2022-08-01T10-00-02.000.json"), "")))
+	assert.Equal(t, "cccccc\n", string(readFile(t, filepath.Join(dir, "data-2022-08-01T10-00-03.000.json"), "")))
+	assert.Equal(t, "dddddd\n", string(readFile(t, filepath.Join(dir, "data.json"), "")))
+}
+
+func TestRotatingWriterMaxSizeCompressed(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	w := newRotatingWriter(filepath.Join(dir, "data.gz"), configcompression.Gzip, RotationConfig{MaxSize: 200})
+	w.now = func() time.Time { return now }
+	require.NoError(t, w.open())
+
+	// The max size applies to the compressed file: the records compress well, so they fit in
+	// a single file, although their size before compression is far above the max size.
+	record := strings.Repeat("a", 1000) + "\n"
+	for i := 0; i < 5; i++ {
+		require.NoError(t, w.write([]byte(record), true))
+		now = now.Add(time.Second)
+	}
+	assert.Equal(t, []string{"data.gz"}, listDir(t, dir))
+	info, err := os.Stat(filepath.Join(dir, "data.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), w.size)
+
+	// Once the file on disk reaches the max size, it is rotated.
+	for i := 0; i < 20 && len(listDir(t, dir)) == 1; i++ {
+		require.NoError(t, w.write([]byte(record), true))
+		now = now.Add(time.Second)
+	}
+	require.NoError(t, w.close())
+	backups := listDir(t, dir)
+	require.Len(t, backups, 2)
+	info, err = os.Stat(filepath.Join(dir, backups[0]))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), int64(200+len(record)))
+}
+
+func TestRotatingWriterInterval(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	w := newRotatingWriter(filepath.Join(dir, "data"), configcompression.Gzip, RotationConfig{Interval: time.Hour})
+	w.now = func() time.Time { return now }
+	require.NoError(t, w.open())
+
+	require.NoError(t, w.write([]byte("a"), false))
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, w.write([]byte("b"), false))
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, w.write([]byte("c"), false))
+	require.NoError(t, w.close())
+
+	assert.Equal(t, []string{"data", "data-2022-08-01T11-00-00.000"}, listDir(t, dir))
+	assert.Equal(t, "ab", string(readFile(t, filepath.Join(dir, "data-2022-08-01T11-00-00.000"), configcompression.Gzip)))
+	assert.Equal(t, "c", string(readFile(t, filepath.Join(dir, "data"), configcompression.Gzip)))
+}
+
+func TestRotatingWriterAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.zst")
+	for _, record := range []string{"a", "b"} {
+		w := newRotatingWriter(path, configcompression.Zstd, RotationConfig{})
+		require.NoError(t, w.open())
+		require.NoError(t, w.write([]byte(record), true))
+		require.NoError(t, w.close())
+	}
+	assert.Equal(t, "ab", string(readFile(t, path, configcompression.Zstd)))
+}
+
+func TestRotatingWriterCloseErrorOnRotate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	w := newRotatingWriter(filepath.Join(dir, "data.json"), "", RotationConfig{MaxSize: 10})
+	w.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	require.NoError(t, w.open())
+	require.NoError(t, w.write([]byte("aaaaaa\n"), true))
+
+	// The rotated file fails to be closed, the new file is opened regardless.
+	require.NoError(t, w.file.Close())
+	assert.ErrorContains(t, w.write([]byte("bbbbbb\n"), false), "failed to close the rotated file")
+	require.NoError(t, w.write([]byte("cccccc\n"), false))
+	require.NoError(t, w.close())
+
+	names := listDir(t, dir)
+	require.Len(t, names, 3)
+	assert.Equal(t, "bbbbbb\n", string(readFile(t, filepath.Join(dir, names[1]), "")))
+	assert.Equal(t, "cccccc\n", string(readFile(t, filepath.Join(dir, "data.json"), "")))
+}
+
+func TestRotatingWriterOpenError(t *testing.T) {
+	w := newRotatingWriter(filepath.Join(t.TempDir(), "missing", "data"), "", RotationConfig{})
+	assert.Error(t, w.open())
+	assert.ErrorIs(t, w.write([]byte("a"), false), errWriterClosed)
+	assert.NoError(t, w.flush())
+	assert.NoError(t, w.close())
+}
@@ -61,6 +61,11 @@ func TestUnmarshalConfig(t *testing.T) {
 				NumConsumers: 2,
 				QueueSize:    10,
 			},
+			DeadLetterSettings: exporterhelper.DeadLetterSettings{
+				Enabled:   true,
+				Directory: "/var/lib/otelcol/dead_letter",
+				Encoding:  exporterhelper.DeadLetterEncodingJSON,
+			},
 			GRPCClientSettings: configgrpc.GRPCClientSettings{
 				Headers: map[string]string{
 					"can you have a . here?": "F0000000-0000-0000-0000-000000000000",
@@ -18,7 +18,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"runtime"
+	"strconv"
 	"time"
 
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
@@ -39,6 +41,12 @@ import (
 	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 )
 
+var (
+	tracesSizer  = ptrace.NewProtoMarshaler().(ptrace.Sizer)
+	metricsSizer = pmetric.NewProtoMarshaler().(pmetric.Sizer)
+	logsSizer    = plog.NewProtoMarshaler().(plog.Sizer)
+)
+
 type exporter struct {
 	// Input configuration.
 	config *Config
@@ -103,19 +111,19 @@ func (e *exporter) shutdown(context.Context) error {
 func (e *exporter) pushTraces(ctx context.Context, td ptrace.Traces) error {
 	req := ptraceotlp.NewRequestFromTraces(td)
 	_, err := e.traceExporter.Export(e.enhanceContext(ctx), req, e.callOptions...)
-	return processError(err)
+	return processError(err, func() int { return tracesSizer.TracesSize(td) })
 }
 
 func (e *exporter) pushMetrics(ctx context.Context, md pmetric.Metrics) error {
 	req := pmetricotlp.NewRequestFromMetrics(md)
 	_, err := e.metricExporter.Export(e.enhanceContext(ctx), req, e.callOptions...)
-	return processError(err)
+	return processError(err, func() int { return metricsSizer.MetricsSize(md) })
 }
 
 func (e *exporter) pushLogs(ctx context.Context, ld plog.Logs) error {
 	req := plogotlp.NewRequestFromLogs(ld)
 	_, err := e.logExporter.Export(e.enhanceContext(ctx), req, e.callOptions...)
-	return processError(err)
+	return processError(err, func() int { return logsSizer.LogsSize(ld) })
 }
 
 func (e *exporter) enhanceContext(ctx context.Context) context.Context {
@@ -125,7 +133,8 @@ func (e *exporter) enhanceContext(ctx context.Context) context.Context {
 	return ctx
 }
 
-func processError(err error) error {
+// processError classifies the error of the export, size returning the size of the exported request.
+func processError(err error, size func() int) error {
 	if err == nil {
 		// Request is successful, we are done.
 		return nil
@@ -145,6 +154,10 @@ func processError(err error) error {
 
 	if !shouldRetry(st.Code(), retryInfo) {
 		// It is not a retryable error, we should not retry.
+		if isMessageTooLarge(st, size) {
+			// Let the caller retry with smaller requests.
+			return exporterhelper.NewMessageTooLarge(consumererror.NewPermanent(err))
+		}
 		return consumererror.NewPermanent(err)
 	}
 
@@ -179,6 +192,28 @@ func shouldRetry(code codes.Code, retryInfo *errdetails.RetryInfo) bool {
 	return false
 }
 
+// messageTooLargeRegexp matches the messages of the gRPC-Go clients and servers rejecting a message
+// larger than their max size, e.g. "grpc: received message larger than max (1000 vs. 100)", capturing
+// the max size.
+var messageTooLargeRegexp = regexp.MustCompile(`larger than max[a-z ]* \(\d+ vs\. (\d+)\)`)
+
+// isMessageTooLarge reports whether the request was rejected because of its size, by the gRPC client or server.
+//
+// This is a heuristic, since gRPC has no dedicated status for it: the status must be ResourceExhausted,
+// with the message of gRPC-Go reporting the max size, and the size of the request must exceed that max.
+// The requests rejected by the other implementations are not detected, and fail permanently.
+func isMessageTooLarge(st *status.Status, size func() int) bool {
+	if st.Code() != codes.ResourceExhausted {
+		return false
+	}
+	match := messageTooLargeRegexp.FindStringSubmatch(st.Message())
+	if match == nil {
+		return false
+	}
+	maxSize, err := strconv.Atoi(match[1])
+	return err == nil && size() > maxSize
+}
+
 func getRetryInfo(status *status.Status) *errdetails.RetryInfo {
 	for _, detail := range status.Details() {
 		if t, ok := detail.(*errdetails.RetryInfo); ok {
@@ -30,6 +30,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
@@ -38,6 +39,8 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/collector/internal/testdata"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
@@ -670,3 +673,48 @@ func TestSendLogData(t *testing.T) {
 	require.Equal(t, len(md.Get("User-Agent")), 1)
 	require.Contains(t, md.Get("User-Agent")[0], "Collector/1.2.3test")
 }
+
+func TestProcessErrorMessageTooLarge(t *testing.T) {
+	size := func(n int) func() int { return func() int { return n } }
+
+	tooLarge := status.Error(codes.ResourceExhausted, "grpc: received message larger than max (1000 vs. 100)")
+	assert.Equal(t, exporterhelper.NewMessageTooLarge(consumererror.NewPermanent(tooLarge)), processError(tooLarge, size(1000)))
+	clientTooLarge := status.Error(codes.ResourceExhausted, "trying to send message larger than max (1000 vs. 100)")
+	assert.Equal(t, exporterhelper.NewMessageTooLarge(consumererror.NewPermanent(clientTooLarge)), processError(clientTooLarge, size(1000)))
+
+	// The request is not larger than the max reported by the server.
+	assert.Equal(t, consumererror.NewPermanent(tooLarge), processError(tooLarge, size(100)))
+
+	exhausted := status.Error(codes.ResourceExhausted, "resource exhausted")
+	assert.Equal(t, consumererror.NewPermanent(exhausted), processError(exhausted, size(1000)))
+	mentioned := status.Error(codes.ResourceExhausted, "quota larger than max allowed")
+	assert.Equal(t, consumererror.NewPermanent(mentioned), processError(mentioned, size(1000)))
+	invalid := status.Error(codes.InvalidArgument, "grpc: received message larger than max (1000 vs. 100)")
+	assert.Equal(t, consumererror.NewPermanent(invalid), processError(invalid, size(1000)))
+}
+
+func TestProcessErrorMessageTooLargeFromServer(t *testing.T) {
+	// Pins the message of the gRPC-Go server rejecting a request larger than its max size.
+	ln, err := net.Listen("tcp", "localhost:")
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.MaxRecvMsgSize(100))
+	ptraceotlp.RegisterServer(srv, &mockTracesReceiver{mockReceiver: mockReceiver{
+		requestCount: atomic.NewInt32(0),
+		totalItems:   atomic.NewInt32(0),
+	}})
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	defer srv.Stop()
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	td := testdata.GenerateTraces(10)
+	_, err = ptraceotlp.NewClient(conn).Export(context.Background(), ptraceotlp.NewRequestFromTraces(td))
+	require.Error(t, err)
+	assert.Regexp(t, `^grpc: received message larger than max \(\d+ vs\. 100\)$`, status.Convert(err).Message())
+	assert.Equal(t, exporterhelper.NewMessageTooLarge(consumererror.NewPermanent(err)),
+		processError(err, func() int { return tracesSizer.TracesSize(td) }))
+}
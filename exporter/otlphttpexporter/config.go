@@ -16,6 +16,7 @@ package otlphttpexporter // import "go.opentelemetry.io/collector/exporter/otlph
 
 import (
 	"errors"
+	"fmt"
 
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/confighttp"
@@ -24,10 +25,11 @@ import (
 
 // Config defines configuration for OTLP/HTTP exporter.
 type Config struct {
-	config.ExporterSettings       `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
-	confighttp.HTTPClientSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
-	exporterhelper.QueueSettings  `mapstructure:"sending_queue"`
-	exporterhelper.RetrySettings  `mapstructure:"retry_on_failure"`
+	config.ExporterSettings           `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	confighttp.HTTPClientSettings     `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+	exporterhelper.QueueSettings      `mapstructure:"sending_queue"`
+	exporterhelper.RetrySettings      `mapstructure:"retry_on_failure"`
+	exporterhelper.DeadLetterSettings `mapstructure:"dead_letter"`
 
 	// The URL to send traces to. If omitted the Endpoint + "/v1/traces" will be used.
 	TracesEndpoint string `mapstructure:"traces_endpoint"`
@@ -46,5 +48,8 @@ func (cfg *Config) Validate() error {
 	if cfg.Endpoint == "" && cfg.TracesEndpoint == "" && cfg.MetricsEndpoint == "" && cfg.LogsEndpoint == "" {
 		return errors.New("at least one endpoint must be specified")
 	}
+	if err := cfg.DeadLetterSettings.Validate(); err != nil {
+		return fmt.Errorf("dead letter settings has invalid configuration: %w", err)
+	}
 	return nil
 }
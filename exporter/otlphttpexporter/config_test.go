@@ -54,6 +54,7 @@ func TestUnmarshalConfig(t *testing.T) {
 				MaxInterval:     1 * time.Minute,
 				MaxElapsedTime:  10 * time.Minute,
 			},
+			DeadLetterSettings: exporterhelper.NewDefaultDeadLetterSettings(),
 			QueueSettings: exporterhelper.QueueSettings{
 				Enabled:      true,
 				NumConsumers: 2,
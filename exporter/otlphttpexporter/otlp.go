@@ -177,6 +177,11 @@ func (e *exporter) export(ctx context.Context, url string, request []byte) error
 		return exporterhelper.NewThrottleRetry(formattedErr, time.Duration(retryAfter)*time.Second)
 	}
 
+	if resp.StatusCode == http.StatusRequestEntityTooLarge {
+		// Do not retry the same request, let our caller retry with smaller requests.
+		return exporterhelper.NewMessageTooLarge(consumererror.NewPermanent(formattedErr))
+	}
+
 	if isPermanentClientFailure(resp.StatusCode) {
 		// Do not retry; report the failure as permanent if the server thinks the request is malformed.
 		return consumererror.NewPermanent(formattedErr)
@@ -415,7 +415,8 @@ func TestErrorResponses(t *testing.T) {
 			name:           "413",
 			responseStatus: http.StatusRequestEntityTooLarge,
 			responseBody:   status.New(codes.InvalidArgument, "Bad field"),
-			isPermErr:      true,
+			err: exporterhelper.NewMessageTooLarge(consumererror.NewPermanent(
+				errors.New(errMsgPrefix + "413, Message=Bad field, Details=[]"))),
 		},
 		{
 			name:           "414",
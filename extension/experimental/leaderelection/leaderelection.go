@@ -0,0 +1,48 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelection // import "go.opentelemetry.io/collector/extension/experimental/leaderelection"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+)
+
+var (
+	errLeaderElectionNotFound = errors.New("leader election extension not found")
+	errNotLeaderElection      = errors.New("requested extension is not a leader election extension")
+)
+
+// Extension is the interface that leader election extensions implement.
+type Extension interface {
+	component.Extension
+
+	// IsLeader reports whether this collector currently holds the leadership of its group.
+	IsLeader() bool
+}
+
+// GetExtension returns the leader election extension with the given ID among the extensions of the host.
+func GetExtension(extensions map[config.ComponentID]component.Extension, id config.ComponentID) (Extension, error) {
+	if ext, found := extensions[id]; found {
+		if le, ok := ext.(Extension); ok {
+			return le, nil
+		}
+		return nil, errNotLeaderElection
+	}
+
+	return nil, fmt.Errorf("failed to resolve leader election %q: %w", id, errLeaderElectionNotFound)
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+)
+
+type nopExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+type leaderExtension struct {
+	nopExtension
+}
+
+func (leaderExtension) IsLeader() bool {
+	return true
+}
+
+func TestGetExtension(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		extension component.Extension
+		expected  error
+	}{
+		{
+			desc:      "obtain leader election",
+			extension: &leaderExtension{},
+		},
+		{
+			desc:      "not a leader election",
+			extension: &nopExtension{},
+			expected:  errNotLeaderElection,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			id := config.NewComponentID("mock")
+			ext := map[config.ComponentID]component.Extension{
+				id: tC.extension,
+			}
+
+			le, err := GetExtension(ext, id)
+
+			if tC.expected != nil {
+				assert.ErrorIs(t, err, tC.expected)
+				assert.Nil(t, le)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, le.IsLeader())
+			}
+		})
+	}
+}
+
+func TestGetExtensionFails(t *testing.T) {
+	le, err := GetExtension(map[config.ComponentID]component.Extension{}, config.NewComponentID("does-not-exist"))
+	assert.ErrorIs(t, err, errLeaderElectionNotFound)
+	assert.Nil(t, le)
+}
@@ -0,0 +1,183 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tap // import "go.opentelemetry.io/collector/extension/experimental/tap"
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Host is implemented by the component.Host of the service. Extensions access the tap points
+// by asserting that the component.Host implements it.
+type Host interface {
+	// GetTaps returns the registry of the tap points of the pipelines.
+	GetTaps() *Registry
+}
+
+// Data is a copy of a batch flowing through a pipeline. Only the field matching the data type
+// of the pipeline is set.
+type Data struct {
+	Pipeline config.ComponentID
+	Traces   ptrace.Traces
+	Metrics  pmetric.Metrics
+	Logs     plog.Logs
+}
+
+// SubscriptionSettings configures a Subscription.
+type SubscriptionSettings struct {
+	// MaxRate is the maximum number of batches per second delivered to the subscription,
+	// the others are skipped. Zero means no limit.
+	MaxRate float64
+
+	// BufferSize is the number of batches buffered for the subscription. The batches are
+	// skipped when the buffer is full, so the pipeline is never blocked by a slow subscriber.
+	BufferSize int
+}
+
+// Registry holds the tap points of the pipelines, and the subscriptions to them.
+// It is safe for concurrent use.
+type Registry struct {
+	mu   sync.RWMutex
+	subs map[config.ComponentID]map[*Subscription]struct{}
+}
+
+// NewRegistry returns a Registry with a tap point for each of the given pipelines.
+func NewRegistry(pipelines []config.ComponentID) *Registry {
+	r := &Registry{subs: make(map[config.ComponentID]map[*Subscription]struct{}, len(pipelines))}
+	for _, id := range pipelines {
+		r.subs[id] = map[*Subscription]struct{}{}
+	}
+	return r
+}
+
+// Pipelines returns the pipelines that can be tapped, sorted by ID.
+func (r *Registry) Pipelines() []config.ComponentID {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]config.ComponentID, 0, len(r.subs))
+	for id := range r.subs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
+// Subscribe returns a subscription receiving the batches flowing through the pipeline.
+// The subscription must be unsubscribed when not used anymore.
+func (r *Registry) Subscribe(pipeline config.ComponentID, set SubscriptionSettings) (*Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	subs, ok := r.subs[pipeline]
+	if !ok {
+		return nil, fmt.Errorf("pipeline %q does not exist", pipeline)
+	}
+	sub := &Subscription{
+		registry: r,
+		pipeline: pipeline,
+		ch:       make(chan Data, set.BufferSize),
+	}
+	if set.MaxRate > 0 {
+		sub.minInterval = time.Duration(float64(time.Second) / set.MaxRate)
+	}
+	subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Active returns true if the pipeline has subscriptions. The pipelines use it to skip the taps
+// without overhead when nobody is listening.
+func (r *Registry) Active(pipeline config.ComponentID) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.subs[pipeline]) > 0
+}
+
+// PublishTraces delivers a copy of the traces to the subscriptions of the pipeline.
+func (r *Registry) PublishTraces(pipeline config.ComponentID, td ptrace.Traces) {
+	r.publish(pipeline, func() Data { return Data{Pipeline: pipeline, Traces: td.Clone()} })
+}
+
+// PublishMetrics delivers a copy of the metrics to the subscriptions of the pipeline.
+func (r *Registry) PublishMetrics(pipeline config.ComponentID, md pmetric.Metrics) {
+	r.publish(pipeline, func() Data { return Data{Pipeline: pipeline, Metrics: md.Clone()} })
+}
+
+// PublishLogs delivers a copy of the logs to the subscriptions of the pipeline.
+func (r *Registry) PublishLogs(pipeline config.ComponentID, ld plog.Logs) {
+	r.publish(pipeline, func() Data { return Data{Pipeline: pipeline, Logs: ld.Clone()} })
+}
+
+func (r *Registry) publish(pipeline config.ComponentID, clone func() Data) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	now := time.Now()
+	for sub := range r.subs[pipeline] {
+		// Check the buffer and the rate before copying the data, so skipped batches cost nothing.
+		if (cap(sub.ch) > 0 && len(sub.ch) == cap(sub.ch)) || !sub.allow(now) {
+			continue
+		}
+		select {
+		case sub.ch <- clone():
+		default:
+		}
+	}
+}
+
+// Subscription receives the batches flowing through a pipeline.
+type Subscription struct {
+	registry    *Registry
+	pipeline    config.ComponentID
+	ch          chan Data
+	minInterval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// Data returns the channel delivering the batches. It is closed by Unsubscribe.
+func (s *Subscription) Data() <-chan Data {
+	return s.ch
+}
+
+// Unsubscribe stops the delivery of the batches, and closes the Data channel.
+// It is safe to call it multiple times.
+func (s *Subscription) Unsubscribe() {
+	s.registry.mu.Lock()
+	defer s.registry.mu.Unlock()
+	if _, ok := s.registry.subs[s.pipeline][s]; !ok {
+		return
+	}
+	delete(s.registry.subs[s.pipeline], s)
+	close(s.ch)
+}
+
+func (s *Subscription) allow(now time.Time) bool {
+	if s.minInterval == 0 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Before(s.next) {
+		return false
+	}
+	s.next = now.Add(s.minInterval)
+	return true
+}
@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var (
+	tracesID  = config.NewComponentID(config.TracesDataType)
+	metricsID = config.NewComponentID(config.MetricsDataType)
+	logsID    = config.NewComponentIDWithName(config.LogsDataType, "audit")
+)
+
+func TestRegistryPipelines(t *testing.T) {
+	r := NewRegistry([]config.ComponentID{tracesID, logsID, metricsID})
+	assert.Equal(t, []config.ComponentID{logsID, metricsID, tracesID}, r.Pipelines())
+
+	_, err := r.Subscribe(config.NewComponentIDWithName(config.TracesDataType, "missing"), SubscriptionSettings{})
+	assert.EqualError(t, err, `pipeline "traces/missing" does not exist`)
+}
+
+func TestRegistryPublish(t *testing.T) {
+	r := NewRegistry([]config.ComponentID{tracesID, metricsID, logsID})
+	assert.False(t, r.Active(tracesID))
+
+	sub, err := r.Subscribe(tracesID, SubscriptionSettings{BufferSize: 10})
+	require.NoError(t, err)
+	assert.True(t, r.Active(tracesID))
+	assert.False(t, r.Active(metricsID))
+
+	td := ptrace.NewTraces()
+	td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("span")
+	r.PublishTraces(tracesID, td)
+	r.PublishMetrics(metricsID, pmetric.NewMetrics())
+	r.PublishLogs(logsID, plog.NewLogs())
+
+	require.Len(t, sub.Data(), 1)
+	data := <-sub.Data()
+	assert.Equal(t, tracesID, data.Pipeline)
+	assert.Equal(t, td, data.Traces)
+
+	// The subscription receives a copy, which is not affected by the changes in the pipeline.
+	td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).SetName("changed")
+	assert.Equal(t, "span", data.Traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	assert.False(t, r.Active(tracesID))
+	_, ok := <-sub.Data()
+	assert.False(t, ok)
+	r.PublishTraces(tracesID, td)
+}
+
+func TestRegistryPublishNeverBlocks(t *testing.T) {
+	r := NewRegistry([]config.ComponentID{logsID})
+	sub, err := r.Subscribe(logsID, SubscriptionSettings{BufferSize: 2})
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	for i := 0; i < 5; i++ {
+		r.PublishLogs(logsID, plog.NewLogs())
+	}
+	assert.Len(t, sub.Data(), 2)
+}
+
+func TestRegistryPublishMaxRate(t *testing.T) {
+	r := NewRegistry([]config.ComponentID{metricsID})
+	sub, err := r.Subscribe(metricsID, SubscriptionSettings{MaxRate: 1, BufferSize: 10})
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	for i := 0; i < 5; i++ {
+		r.PublishMetrics(metricsID, pmetric.NewMetrics())
+	}
+	assert.Len(t, sub.Data(), 1)
+
+	now := time.Now()
+	assert.False(t, sub.allow(now))
+	assert.True(t, sub.allow(now.Add(time.Second)))
+}
@@ -0,0 +1,433 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestorageextension // import "go.opentelemetry.io/collector/extension/filestorageextension"
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"go.uber.org/multierr"
+
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+// The file of a client is a log of frames, each frame holding the operations of one batch:
+//
+//	frame:     payload length (uint32) | CRC32 of the payload (uint32) | payload
+//	payload:   record...
+//	record:    type (byte) | key length (uint32) | key | [value length (uint32) | value]
+//
+// The value is only present for the set records. A frame is only applied if complete and
+// its CRC32 matches, which makes every batch atomic: a frame torn by a crash is discarded,
+// and truncated, when the file is loaded.
+const (
+	frameHeaderSize = 8
+
+	recordSet    byte = 1
+	recordDelete byte = 2
+)
+
+var errClientClosed = errors.New("storage client is closed")
+
+// valueRef locates a live value in the file.
+type valueRef struct {
+	offset int64
+	size   int
+}
+
+type fileClient struct {
+	mu         sync.Mutex
+	path       string
+	fsync      bool
+	compaction CompactionConfig
+	onClose    func()
+
+	// file is nil once the client is closed.
+	file  *os.File
+	index map[string]valueRef
+	// size is the size of the valid part of the file, liveSize the part used by the live records.
+	size     int64
+	liveSize int64
+}
+
+var _ storage.Client = (*fileClient)(nil)
+
+func newFileClient(path string, cfg *Config, onClose func()) (*fileClient, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	c := &fileClient{
+		path:       path,
+		fsync:      cfg.Fsync,
+		compaction: cfg.Compaction,
+		file:       file,
+	}
+	if err = c.load(); err != nil {
+		return nil, multierr.Combine(fmt.Errorf("cannot load %q: %w", path, err), file.Close())
+	}
+	if cfg.Compaction.OnStart {
+		if err = c.compact(); err != nil {
+			return nil, multierr.Combine(fmt.Errorf("cannot compact %q: %w", path, err), c.closeFile())
+		}
+	}
+	c.onClose = onClose
+	return c, nil
+}
+
+// load builds the index from the file, and truncates the frame torn by a crash if any.
+func (c *fileClient) load() error {
+	c.index = map[string]valueRef{}
+	c.size, c.liveSize = 0, 0
+	if _, err := c.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	info, err := c.file.Stat()
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(c.file)
+	header := make([]byte, frameHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return c.file.Truncate(c.size)
+			}
+			return err
+		}
+		// A length beyond the end of the file is a torn or corrupted frame, checked before
+		// allocating the payload.
+		payloadLen := int64(binary.BigEndian.Uint32(header))
+		if payloadLen > info.Size()-c.size-frameHeaderSize {
+			return c.file.Truncate(c.size)
+		}
+		payload := make([]byte, payloadLen)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return c.file.Truncate(c.size)
+			}
+			return err
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:]) {
+			return c.file.Truncate(c.size)
+		}
+		if err := c.applyPayload(payload, c.size+frameHeaderSize); err != nil {
+			return err
+		}
+		c.size += frameHeaderSize + int64(len(payload))
+	}
+}
+
+// applyPayload updates the index with the records of the payload starting at the given offset.
+func (c *fileClient) applyPayload(payload []byte, offset int64) error {
+	for pos := 0; pos < len(payload); {
+		typ, key, value, n, err := decodeRecord(payload[pos:])
+		if err != nil {
+			return fmt.Errorf("corrupted record at offset %d: %w", offset+int64(pos), err)
+		}
+		switch typ {
+		case recordSet:
+			c.apply(key, &valueRef{offset: offset + int64(pos+n-len(value)), size: len(value)})
+		case recordDelete:
+			c.apply(key, nil)
+		default:
+			return fmt.Errorf("unknown record type %d at offset %d", typ, offset+int64(pos))
+		}
+		pos += n
+	}
+	return nil
+}
+
+// apply sets the value of the key in the index, or deletes it if ref is nil.
+func (c *fileClient) apply(key string, ref *valueRef) {
+	if old, ok := c.index[key]; ok {
+		c.liveSize -= setRecordSize(key, old.size)
+		delete(c.index, key)
+	}
+	if ref != nil {
+		c.index[key] = *ref
+		c.liveSize += setRecordSize(key, ref.size)
+	}
+}
+
+// Get will retrieve data from storage that corresponds to the specified key
+func (c *fileClient) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	if err := c.Batch(ctx, op); err != nil {
+		return nil, err
+	}
+	return op.Value, nil
+}
+
+// Set will store data. The data can be retrieved using the same key
+func (c *fileClient) Set(ctx context.Context, key string, value []byte) error {
+	return c.Batch(ctx, storage.SetOperation(key, value))
+}
+
+// Delete will delete data associated with the specified key
+func (c *fileClient) Delete(ctx context.Context, key string) error {
+	return c.Batch(ctx, storage.DeleteOperation(key))
+}
+
+// Batch executes the specified operations in order. The set and delete operations are written
+// in a single frame, so either all or none of them are persisted.
+func (c *fileClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file == nil {
+		return errClientClosed
+	}
+
+	type pendingRecord struct {
+		key   string
+		pos   int
+		value []byte
+		del   bool
+	}
+	var payload []byte
+	var records []pendingRecord
+	pending := map[string]pendingRecord{}
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			if rec, ok := pending[op.Key]; ok {
+				op.Value = nil
+				if !rec.del {
+					op.Value = append([]byte{}, rec.value...)
+				}
+				continue
+			}
+			value, err := c.read(op.Key)
+			if err != nil {
+				return err
+			}
+			op.Value = value
+		case storage.Set:
+			payload = appendRecord(payload, recordSet, op.Key, op.Value)
+			rec := pendingRecord{key: op.Key, pos: len(payload) - len(op.Value), value: op.Value}
+			records = append(records, rec)
+			pending[op.Key] = rec
+		case storage.Delete:
+			payload = appendRecord(payload, recordDelete, op.Key, nil)
+			rec := pendingRecord{key: op.Key, del: true}
+			records = append(records, rec)
+			pending[op.Key] = rec
+		default:
+			return fmt.Errorf("unsupported operation type %v", op.Type)
+		}
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+
+	offset := c.size + frameHeaderSize
+	if err := c.writeFrame(payload); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if rec.del {
+			c.apply(rec.key, nil)
+			continue
+		}
+		c.apply(rec.key, &valueRef{offset: offset + int64(rec.pos), size: len(rec.value)})
+	}
+
+	if c.shouldCompact() {
+		return c.compact()
+	}
+	return nil
+}
+
+// read returns the value of the key, nil if not found.
+func (c *fileClient) read(key string) ([]byte, error) {
+	ref, ok := c.index[key]
+	if !ok {
+		return nil, nil
+	}
+	value := make([]byte, ref.size)
+	if _, err := c.file.ReadAt(value, ref.offset); err != nil {
+		return nil, fmt.Errorf("cannot read the value of %q: %w", key, err)
+	}
+	return value, nil
+}
+
+// writeFrame appends a frame with the payload to the file. If it fails, the size of the file is
+// not updated, so the next frame overwrites the partially written one.
+func (c *fileClient) writeFrame(payload []byte) error {
+	frame := make([]byte, frameHeaderSize, frameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:], crc32.ChecksumIEEE(payload))
+	frame = append(frame, payload...)
+	if _, err := c.file.WriteAt(frame, c.size); err != nil {
+		return err
+	}
+	if c.fsync {
+		if err := c.file.Sync(); err != nil {
+			return err
+		}
+	}
+	c.size += int64(len(frame))
+	return nil
+}
+
+func (c *fileClient) shouldCompact() bool {
+	if c.compaction.MaxGarbageRatio <= 0 || c.size == 0 || c.size < int64(c.compaction.MinFileSize) {
+		return false
+	}
+	// The compacted file has a frame for each live value.
+	compactedSize := c.liveSize + int64(frameHeaderSize*len(c.index))
+	return float64(c.size-compactedSize)/float64(c.size) > c.compaction.MaxGarbageRatio
+}
+
+// compact rewrites the file with only the live values, and replaces the current one.
+func (c *fileClient) compact() error {
+	tmpPath := c.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err = c.writeLiveValues(tmp); err != nil {
+		return multierr.Combine(err, tmp.Close(), os.Remove(tmpPath))
+	}
+	if err = tmp.Close(); err != nil {
+		return multierr.Combine(err, os.Remove(tmpPath))
+	}
+
+	// The file must be closed before being replaced on Windows.
+	if err = c.file.Close(); err != nil {
+		return multierr.Combine(err, os.Remove(tmpPath))
+	}
+	renameErr := os.Rename(tmpPath, c.path)
+	if c.file, err = os.OpenFile(c.path, os.O_RDWR, 0600); err != nil {
+		c.file = nil
+		return multierr.Combine(renameErr, err)
+	}
+	if renameErr != nil {
+		return multierr.Combine(renameErr, os.Remove(tmpPath))
+	}
+	return c.load()
+}
+
+// writeLiveValues writes a frame for each live value to the given file and syncs it.
+func (c *fileClient) writeLiveValues(f *os.File) error {
+	keys := make([]string, 0, len(c.index))
+	for key := range c.index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	w := bufio.NewWriter(f)
+	header := make([]byte, frameHeaderSize)
+	for _, key := range keys {
+		value, err := c.read(key)
+		if err != nil {
+			return err
+		}
+		payload := appendRecord(nil, recordSet, key, value)
+		binary.BigEndian.PutUint32(header, uint32(len(payload)))
+		binary.BigEndian.PutUint32(header[4:], crc32.ChecksumIEEE(payload))
+		if _, err = w.Write(header); err != nil {
+			return err
+		}
+		if _, err = w.Write(payload); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// Close will close the file of the client
+func (c *fileClient) Close(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closeFile()
+}
+
+// closeFile closes the file if still open, and notifies the extension the first time it is called.
+func (c *fileClient) closeFile() error {
+	var err error
+	if c.file != nil {
+		err = c.file.Close()
+		c.file = nil
+	}
+	if c.onClose != nil {
+		c.onClose()
+		c.onClose = nil
+	}
+	return err
+}
+
+func appendRecord(buf []byte, typ byte, key string, value []byte) []byte {
+	buf = append(buf, typ)
+	buf = appendUint32(buf, uint32(len(key)))
+	buf = append(buf, key...)
+	if typ == recordSet {
+		buf = appendUint32(buf, uint32(len(value)))
+		buf = append(buf, value...)
+	}
+	return buf
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	return append(buf, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// decodeRecord decodes the record at the beginning of buf, and returns its length.
+func decodeRecord(buf []byte) (typ byte, key string, value []byte, n int, err error) {
+	errTruncated := errors.New("truncated record")
+	if len(buf) < 5 {
+		return 0, "", nil, 0, errTruncated
+	}
+	typ = buf[0]
+	keyLen := int(binary.BigEndian.Uint32(buf[1:]))
+	n = 5 + keyLen
+	if len(buf) < n {
+		return 0, "", nil, 0, errTruncated
+	}
+	key = string(buf[5:n])
+	if typ != recordSet {
+		return typ, key, nil, n, nil
+	}
+	if len(buf) < n+4 {
+		return 0, "", nil, 0, errTruncated
+	}
+	valueLen := int(binary.BigEndian.Uint32(buf[n:]))
+	n += 4
+	if len(buf) < n+valueLen {
+		return 0, "", nil, 0, errTruncated
+	}
+	return typ, key, buf[n : n+valueLen], n + valueLen, nil
+}
+
+func setRecordSize(key string, valueSize int) int64 {
+	return int64(9 + len(key) + valueSize)
+}
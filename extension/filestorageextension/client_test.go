@@ -0,0 +1,248 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestorageextension
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+func newTestClient(t *testing.T, path string, cfg *Config) *fileClient {
+	client, err := newFileClient(path, cfg, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close(context.Background()))
+	})
+	return client
+}
+
+func TestClientOperations(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, filepath.Join(t.TempDir(), "client"), createDefaultConfig().(*Config))
+
+	value, err := client.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	require.NoError(t, client.Set(ctx, "key", []byte("value")))
+	value, err = client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	require.NoError(t, client.Set(ctx, "key", []byte("other")))
+	value, err = client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("other"), value)
+
+	require.NoError(t, client.Delete(ctx, "key"))
+	require.NoError(t, client.Delete(ctx, "missing"))
+	value, err = client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, client.Set(cancelled, "key", nil), context.Canceled)
+}
+
+func TestClientBatch(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, filepath.Join(t.TempDir(), "client"), createDefaultConfig().(*Config))
+	require.NoError(t, client.Set(ctx, "a", []byte("1")))
+
+	ops := []storage.Operation{
+		storage.GetOperation("a"),
+		storage.SetOperation("a", []byte("2")),
+		storage.GetOperation("a"),
+		storage.SetOperation("b", []byte("3")),
+		storage.DeleteOperation("a"),
+		storage.GetOperation("a"),
+		storage.GetOperation("b"),
+	}
+	require.NoError(t, client.Batch(ctx, ops...))
+	assert.Equal(t, []byte("1"), ops[0].Value)
+	assert.Equal(t, []byte("2"), ops[2].Value)
+	assert.Nil(t, ops[5].Value)
+	assert.Equal(t, []byte("3"), ops[6].Value)
+
+	value, err := client.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	value, err = client.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("3"), value)
+}
+
+func TestClientPersistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "client")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Fsync = true
+
+	client, err := newFileClient(path, cfg, nil)
+	require.NoError(t, err)
+	require.NoError(t, client.Batch(ctx, storage.SetOperation("a", []byte("1")), storage.SetOperation("b", []byte("2"))))
+	require.NoError(t, client.Delete(ctx, "a"))
+	require.NoError(t, client.Close(ctx))
+
+	client = newTestClient(t, path, cfg)
+	value, err := client.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	value, err = client.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("2"), value)
+}
+
+func TestClientTornBatchDiscarded(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "client")
+	cfg := createDefaultConfig().(*Config)
+
+	client, err := newFileClient(path, cfg, nil)
+	require.NoError(t, err)
+	require.NoError(t, client.Set(ctx, "a", []byte("1")))
+	validSize := client.size
+	require.NoError(t, client.Batch(ctx, storage.SetOperation("a", []byte("2")), storage.SetOperation("b", []byte("3"))))
+	require.NoError(t, client.Close(ctx))
+
+	// Simulate a crash while writing the second batch.
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-1))
+
+	client = newTestClient(t, path, cfg)
+	value, err := client.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+	value, err = client.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	// The torn batch is truncated, so the next batches are appended after the valid ones.
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, validSize, info.Size())
+	require.NoError(t, client.Set(ctx, "b", []byte("4")))
+	value, err = client.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("4"), value)
+}
+
+func TestClientCorruptedBatchDiscarded(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "client")
+	cfg := createDefaultConfig().(*Config)
+
+	client, err := newFileClient(path, cfg, nil)
+	require.NoError(t, err)
+	require.NoError(t, client.Set(ctx, "a", []byte("1")))
+	require.NoError(t, client.Set(ctx, "a", []byte("2")))
+	require.NoError(t, client.Close(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[len(data)-1] = 'x'
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	client = newTestClient(t, path, cfg)
+	value, err := client.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+}
+
+func TestClientOversizedFrameDiscarded(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "client")
+	cfg := createDefaultConfig().(*Config)
+
+	client, err := newFileClient(path, cfg, nil)
+	require.NoError(t, err)
+	require.NoError(t, client.Set(ctx, "a", []byte("1")))
+	validSize := client.size
+	require.NoError(t, client.Close(ctx))
+
+	// A frame header claiming a payload of 4GiB, beyond the end of the file.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0, 1})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	client = newTestClient(t, path, cfg)
+	value, err := client.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, validSize, info.Size())
+}
+
+func TestClientCompaction(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "client")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Compaction.MinFileSize = 1024
+
+	client := newTestClient(t, path, cfg)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, client.Set(ctx, "key", []byte(fmt.Sprintf("value-%d", i))))
+		require.NoError(t, client.Set(ctx, fmt.Sprintf("tmp-%d", i), []byte("x")))
+		require.NoError(t, client.Delete(ctx, fmt.Sprintf("tmp-%d", i)))
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Less(t, info.Size(), int64(1024))
+	assert.Equal(t, info.Size(), client.size)
+	assert.NoFileExists(t, path+".compact")
+
+	value, err := client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value-99"), value)
+}
+
+func TestClientCompactionOnStart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "client")
+	cfg := createDefaultConfig().(*Config)
+
+	client, err := newFileClient(path, cfg, nil)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, client.Set(ctx, "key", []byte(fmt.Sprintf("value-%d", i))))
+	}
+	require.NoError(t, client.Set(ctx, "empty", []byte{}))
+	require.NoError(t, client.Close(ctx))
+
+	cfg.Compaction.OnStart = true
+	client = newTestClient(t, path, cfg)
+	assert.Equal(t, int64(2*frameHeaderSize)+setRecordSize("key", len("value-9"))+setRecordSize("empty", 0), client.size)
+
+	value, err := client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value-9"), value)
+	value, err = client.Get(ctx, "empty")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{}, value)
+}
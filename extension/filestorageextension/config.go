@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestorageextension // import "go.opentelemetry.io/collector/extension/filestorageextension"
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configbytes"
+)
+
+// Config has the configuration for the file storage extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Directory is the directory where the files of the storage clients are stored. It must exist.
+	Directory string `mapstructure:"directory"`
+
+	// Fsync, if true, flushes every write to the disk before returning, trading throughput for
+	// durability in case of a power loss.
+	Fsync bool `mapstructure:"fsync"`
+
+	// Compaction configures when the files are rewritten to reclaim the space used by
+	// overwritten and deleted values.
+	Compaction CompactionConfig `mapstructure:"compaction"`
+}
+
+// CompactionConfig configures the compaction of the files.
+type CompactionConfig struct {
+	// OnStart, if true, compacts the file when the client is created.
+	OnStart bool `mapstructure:"on_start"`
+
+	// MinFileSize is the size under which a file is never compacted while the client is in use.
+	MinFileSize configbytes.ByteSize `mapstructure:"min_file_size"`
+
+	// MaxGarbageRatio is the ratio of the file size used by overwritten and deleted values above
+	// which the file is compacted while the client is in use. Zero disables it.
+	MaxGarbageRatio float64 `mapstructure:"max_garbage_ratio"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Directory == "" {
+		return errors.New("directory must be specified")
+	}
+	if cfg.Compaction.MinFileSize < 0 {
+		return errors.New("compaction::min_file_size must not be negative")
+	}
+	if cfg.Compaction.MaxGarbageRatio < 0 || cfg.Compaction.MaxGarbageRatio >= 1 {
+		return errors.New("compaction::max_garbage_ratio must be in range [0, 1)")
+	}
+	return nil
+}
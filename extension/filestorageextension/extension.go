@@ -0,0 +1,150 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestorageextension // import "go.opentelemetry.io/collector/extension/filestorageextension"
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+type fileStorage struct {
+	cfg    *Config
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	clients map[string]*fileClient
+}
+
+var _ storage.Extension = (*fileStorage)(nil)
+
+func newFileStorage(cfg *Config, logger *zap.Logger) *fileStorage {
+	return &fileStorage{
+		cfg:     cfg,
+		logger:  logger,
+		clients: map[string]*fileClient{},
+	}
+}
+
+func (fs *fileStorage) Start(context.Context, component.Host) error {
+	info, err := os.Stat(fs.cfg.Directory)
+	if err != nil {
+		return fmt.Errorf("cannot access the storage directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%q is not a directory", fs.cfg.Directory)
+	}
+	return nil
+}
+
+// Shutdown closes the clients that the components did not close.
+func (fs *fileStorage) Shutdown(ctx context.Context) error {
+	fs.mu.Lock()
+	clients := make([]*fileClient, 0, len(fs.clients))
+	for _, client := range fs.clients {
+		clients = append(clients, client)
+	}
+	fs.mu.Unlock()
+
+	var errs error
+	for _, client := range clients {
+		fs.logger.Warn("Closing a storage client that was not closed", zap.String("path", client.path))
+		errs = multierr.Append(errs, client.Close(ctx))
+	}
+	return errs
+}
+
+// GetClient returns a client storing its data in a file dedicated to the component and storage name.
+// Only one client can use the file at a time.
+func (fs *fileStorage) GetClient(_ context.Context, kind component.Kind, id config.ComponentID, storageName string) (storage.Client, error) {
+	name, err := clientFileName(kind, id, storageName)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(fs.cfg.Directory, name)
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, ok := fs.clients[path]; ok {
+		return nil, fmt.Errorf("storage client for %q is already in use", name)
+	}
+	client, err := newFileClient(path, fs.cfg, func() {
+		fs.mu.Lock()
+		delete(fs.clients, path)
+		fs.mu.Unlock()
+	})
+	if err != nil {
+		return nil, err
+	}
+	fs.clients[path] = client
+	return client, nil
+}
+
+// clientFileName returns the name of the file for the given component and storage name,
+// e.g. "exporter_otlp_backend_traces". The parts are escaped, so that they never contain the "_"
+// separating them, and the name part is always present if the storage name is, even if empty,
+// e.g. "exporter_otlp__traces", so that distinct components and storage names never share a file.
+func clientFileName(kind component.Kind, id config.ComponentID, storageName string) (string, error) {
+	var kindStr string
+	switch kind {
+	case component.KindReceiver:
+		kindStr = "receiver"
+	case component.KindProcessor:
+		kindStr = "processor"
+	case component.KindExporter:
+		kindStr = "exporter"
+	case component.KindExtension:
+		kindStr = "extension"
+	default:
+		return "", fmt.Errorf("unsupported component kind %v", kind)
+	}
+	parts := []string{kindStr, escapeFileName(string(id.Type()))}
+	if id.Name() != "" || storageName != "" {
+		parts = append(parts, escapeFileName(id.Name()))
+	}
+	if storageName != "" {
+		parts = append(parts, escapeFileName(storageName))
+	}
+	return strings.Join(parts, "_"), nil
+}
+
+// escapeFileName percent-encodes the bytes of the name that are not allowed, or unsafe, in file
+// names, as well as "%" and the "_" separator, so that the encoding is reversible.
+func escapeFileName(name string) string {
+	const hex = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		b := name[i]
+		switch {
+		case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9', b == '-', b == '.':
+			sb.WriteByte(b)
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(hex[b>>4])
+			sb.WriteByte(hex[b&0xF])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestorageextension
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+)
+
+func newTestExtension(t *testing.T) (*fileStorage, *Config) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Directory = t.TempDir()
+	ext, err := createExtension(context.Background(), componenttest.NewNopExtensionCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	return ext.(*fileStorage), cfg
+}
+
+func TestFileStorageStartInvalidDirectory(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Directory = filepath.Join(t.TempDir(), "missing")
+	ext := newFileStorage(cfg, zap.NewNop())
+	assert.ErrorContains(t, ext.Start(context.Background(), componenttest.NewNopHost()), "cannot access the storage directory")
+
+	cfg.Directory = filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(cfg.Directory, nil, 0600))
+	assert.ErrorContains(t, ext.Start(context.Background(), componenttest.NewNopHost()), "is not a directory")
+}
+
+func TestFileStorageGetClient(t *testing.T) {
+	ext, cfg := newTestExtension(t)
+	ctx := context.Background()
+	id := config.NewComponentIDWithName("otlp", "backend")
+
+	client, err := ext.GetClient(ctx, component.KindExporter, id, "traces")
+	require.NoError(t, err)
+	require.NoError(t, client.Set(ctx, "key", []byte("value")))
+	assert.FileExists(t, filepath.Join(cfg.Directory, "exporter_otlp_backend_traces"))
+
+	// The file can only be used by one client at a time.
+	_, err = ext.GetClient(ctx, component.KindExporter, id, "traces")
+	assert.ErrorContains(t, err, "is already in use")
+
+	other, err := ext.GetClient(ctx, component.KindExporter, id, "metrics")
+	require.NoError(t, err)
+	value, err := other.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	require.NoError(t, other.Close(ctx))
+
+	require.NoError(t, client.Close(ctx))
+	client, err = ext.GetClient(ctx, component.KindExporter, id, "traces")
+	require.NoError(t, err)
+	value, err = client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	// The clients that are not closed by the components are closed on shutdown.
+	require.NoError(t, ext.Shutdown(ctx))
+	assert.ErrorIs(t, client.Set(ctx, "key", nil), errClientClosed)
+	assert.Empty(t, ext.clients)
+}
+
+func TestClientFileName(t *testing.T) {
+	tests := []struct {
+		kind        component.Kind
+		id          config.ComponentID
+		storageName string
+		expected    string
+	}{
+		{kind: component.KindReceiver, id: config.NewComponentID("filelog"), expected: "receiver_filelog"},
+		{kind: component.KindProcessor, id: config.NewComponentIDWithName("dedup", "a/b"), expected: "processor_dedup_a%2Fb"},
+		{kind: component.KindProcessor, id: config.NewComponentIDWithName("dedup", "a~b"), expected: "processor_dedup_a%7Eb"},
+		{kind: component.KindReceiver, id: config.NewComponentIDWithName("r", "a_b"), expected: "receiver_r_a%5Fb"},
+		{kind: component.KindReceiver, id: config.NewComponentIDWithName("r", "a"), storageName: "b", expected: "receiver_r_a_b"},
+		{kind: component.KindReceiver, id: config.NewComponentIDWithName("r", "100%"), expected: "receiver_r_100%25"},
+		{kind: component.KindExporter, id: config.NewComponentID("otlp"), storageName: "logs", expected: "exporter_otlp__logs"},
+		{kind: component.KindExtension, id: config.NewComponentIDWithName("ext", "x"), storageName: "y", expected: "extension_ext_x_y"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			name, err := clientFileName(tt.kind, tt.id, tt.storageName)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, name)
+		})
+	}
+
+	// The unnamed component with a storage name and the named one without do not share a file.
+	withStorage, err := clientFileName(component.KindExporter, config.NewComponentID("otlp"), "x")
+	require.NoError(t, err)
+	withName, err := clientFileName(component.KindExporter, config.NewComponentIDWithName("otlp", "x"), "")
+	require.NoError(t, err)
+	assert.NotEqual(t, withStorage, withName)
+
+	_, err = clientFileName(42, config.NewComponentID("otlp"), "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestorageextension // import "go.opentelemetry.io/collector/extension/filestorageextension"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configbytes"
+)
+
+const (
+	// The value of extension "type" in configuration.
+	typeStr = "file_storage"
+
+	defaultMinFileSize     = configbytes.Mebibyte
+	defaultMaxGarbageRatio = 0.5
+)
+
+// NewFactory creates a factory for the file storage extension.
+func NewFactory() component.ExtensionFactory {
+	return component.NewExtensionFactoryWithStabilityLevel(typeStr, createDefaultConfig, createExtension, component.StabilityLevelAlpha)
+}
+
+func createDefaultConfig() config.Extension {
+	return &Config{
+		ExtensionSettings: config.NewExtensionSettings(config.NewComponentID(typeStr)),
+		Directory:         defaultDirectory(),
+		Compaction: CompactionConfig{
+			MinFileSize:     defaultMinFileSize,
+			MaxGarbageRatio: defaultMaxGarbageRatio,
+		},
+	}
+}
+
+func createExtension(_ context.Context, set component.ExtensionCreateSettings, cfg config.Extension) (component.Extension, error) {
+	return newFileStorage(cfg.(*Config), set.Logger), nil
+}
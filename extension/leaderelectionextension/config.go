@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension // import "go.opentelemetry.io/collector/extension/leaderelectionextension"
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config has the configuration for the leader election extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Identity of this collector, unique among the group. Defaults to the host name.
+	Identity string `mapstructure:"identity"`
+
+	// LeaseDuration is how long the other collectors wait, after the last renewal of the
+	// leadership, before trying to acquire it. Only used by the Kubernetes Lease.
+	LeaseDuration time.Duration `mapstructure:"lease_duration"`
+
+	// RenewDeadline is how long the leader keeps the leadership while failing to renew it.
+	RenewDeadline time.Duration `mapstructure:"renew_deadline"`
+
+	// RetryPeriod is the time between the attempts to acquire or renew the leadership.
+	RetryPeriod time.Duration `mapstructure:"retry_period"`
+
+	// Kubernetes elects the leader with a Kubernetes Lease.
+	Kubernetes *KubernetesConfig `mapstructure:"kubernetes"`
+
+	// File elects the leader with a lock on a file.
+	File *FileConfig `mapstructure:"file"`
+}
+
+// KubernetesConfig has the configuration of the Kubernetes Lease.
+type KubernetesConfig struct {
+	// LeaseName is the name of the Lease object.
+	LeaseName string `mapstructure:"lease_name"`
+
+	// Namespace of the Lease object. Defaults to the namespace of the pod of the collector.
+	Namespace string `mapstructure:"namespace"`
+
+	// Endpoint of the Kubernetes API server. Defaults to the endpoint of the cluster the
+	// collector runs in, which is also authenticated with the service account of its pod.
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// FileConfig has the configuration of the file lock.
+type FileConfig struct {
+	// Path of the lock file, which must be on a file system shared by the group and
+	// supporting file locks.
+	Path string `mapstructure:"path"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if (cfg.Kubernetes == nil) == (cfg.File == nil) {
+		return errors.New("exactly one of \"kubernetes\" or \"file\" must be configured")
+	}
+	if cfg.Kubernetes != nil && cfg.Kubernetes.LeaseName == "" {
+		return errors.New("\"lease_name\" is required for the Kubernetes Lease")
+	}
+	if cfg.File != nil && cfg.File.Path == "" {
+		return errors.New("\"path\" is required for the file lock")
+	}
+	if cfg.RetryPeriod <= 0 {
+		return errors.New("\"retry_period\" must be positive")
+	}
+	if cfg.RenewDeadline <= cfg.RetryPeriod {
+		return errors.New("\"renew_deadline\" must be greater than \"retry_period\"")
+	}
+	if cfg.Kubernetes != nil && cfg.LeaseDuration <= cfg.RenewDeadline {
+		return errors.New("\"lease_duration\" must be greater than \"renew_deadline\"")
+	}
+	return nil
+}
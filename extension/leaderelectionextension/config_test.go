@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestUnmarshalDefaultConfig(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.NoError(t, config.UnmarshalExtension(confmap.New(), cfg))
+	assert.Equal(t, factory.CreateDefaultConfig(), cfg)
+}
+
+func TestUnmarshalConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.NoError(t, config.UnmarshalExtension(cm, cfg))
+	assert.Equal(t,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(config.NewComponentID(typeStr)),
+			Identity:          "collector-1",
+			LeaseDuration:     30 * time.Second,
+			RenewDeadline:     20 * time.Second,
+			RetryPeriod:       5 * time.Second,
+			Kubernetes: &KubernetesConfig{
+				LeaseName: "otel-collector",
+				Namespace: "observability",
+			},
+		}, cfg)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.EqualError(t, cfg.Validate(), "exactly one of \"kubernetes\" or \"file\" must be configured")
+
+	cfg.Kubernetes = &KubernetesConfig{}
+	cfg.File = &FileConfig{Path: "leader.lock"}
+	assert.EqualError(t, cfg.Validate(), "exactly one of \"kubernetes\" or \"file\" must be configured")
+
+	cfg.File = nil
+	assert.EqualError(t, cfg.Validate(), "\"lease_name\" is required for the Kubernetes Lease")
+
+	cfg.Kubernetes.LeaseName = "otel-collector"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.LeaseDuration = cfg.RenewDeadline
+	assert.EqualError(t, cfg.Validate(), "\"lease_duration\" must be greater than \"renew_deadline\"")
+
+	cfg.Kubernetes = nil
+	cfg.File = &FileConfig{}
+	assert.EqualError(t, cfg.Validate(), "\"path\" is required for the file lock")
+
+	// The lease duration is not used by the file lock.
+	cfg.File.Path = "leader.lock"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.RenewDeadline = cfg.RetryPeriod
+	assert.EqualError(t, cfg.Validate(), "\"renew_deadline\" must be greater than \"retry_period\"")
+
+	cfg.RetryPeriod = 0
+	assert.EqualError(t, cfg.Validate(), "\"retry_period\" must be positive")
+}
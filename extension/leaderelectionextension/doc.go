@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package leaderelectionextension implements an extension electing a leader among a group of
+// collectors, e.g. an active/standby pair, with a Kubernetes Lease or a file lock, so that only
+// the leader scrapes the targets that every member of the group can scrape.
+package leaderelectionextension // import "go.opentelemetry.io/collector/extension/leaderelectionextension"
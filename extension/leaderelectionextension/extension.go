@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension // import "go.opentelemetry.io/collector/extension/leaderelectionextension"
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/leaderelection"
+)
+
+// lock is the resource the collectors of a group compete for, its holder is the leader.
+type lock interface {
+	// start prepares the lock, before the first attempt to acquire it.
+	start(ctx context.Context) error
+
+	// tryAcquireOrRenew acquires the lock, or renews it if already held, and reports whether it is held.
+	tryAcquireOrRenew(ctx context.Context) (bool, error)
+
+	// release releases the lock if held, so that another collector can acquire it without waiting.
+	release(ctx context.Context) error
+}
+
+type leaderElection struct {
+	config *Config
+	logger *zap.Logger
+	lock   lock
+	leader *atomic.Bool
+
+	// lastRenew is the last time the lock was acquired or renewed, only accessed by the election loop.
+	lastRenew time.Time
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+var _ leaderelection.Extension = (*leaderElection)(nil)
+
+func newLeaderElection(config *Config, logger *zap.Logger, l lock) *leaderElection {
+	return &leaderElection{
+		config: config,
+		logger: logger,
+		lock:   l,
+		leader: atomic.NewBool(false),
+	}
+}
+
+func (le *leaderElection) Start(ctx context.Context, _ component.Host) error {
+	if err := le.lock.start(ctx); err != nil {
+		return err
+	}
+
+	le.stopCh = make(chan struct{})
+	le.doneCh = make(chan struct{})
+	go le.run()
+	return nil
+}
+
+func (le *leaderElection) Shutdown(ctx context.Context) error {
+	if le.stopCh == nil {
+		return nil
+	}
+	close(le.stopCh)
+	<-le.doneCh
+
+	if !le.leader.Load() {
+		return nil
+	}
+	le.setLeader(false)
+	return le.lock.release(ctx)
+}
+
+// IsLeader implements leaderelection.Extension.
+func (le *leaderElection) IsLeader() bool {
+	return le.leader.Load()
+}
+
+// run tries to acquire or renew the lock every retry period, until shutdown.
+func (le *leaderElection) run() {
+	defer close(le.doneCh)
+
+	ticker := time.NewTicker(le.config.RetryPeriod)
+	defer ticker.Stop()
+	for {
+		le.tryAcquireOrRenew()
+		select {
+		case <-ticker.C:
+		case <-le.stopCh:
+			return
+		}
+	}
+}
+
+func (le *leaderElection) tryAcquireOrRenew() {
+	ctx, cancel := context.WithTimeout(context.Background(), le.config.RenewDeadline)
+	defer cancel()
+
+	held, err := le.lock.tryAcquireOrRenew(ctx)
+	now := time.Now()
+	if err != nil {
+		le.logger.Warn("Failed to acquire or renew the leadership", zap.Error(err))
+		// The leader keeps the leadership until the renew deadline, which is shorter than the
+		// duration the other collectors wait before acquiring it.
+		if le.leader.Load() && now.Sub(le.lastRenew) < le.config.RenewDeadline {
+			return
+		}
+	}
+	if held {
+		le.lastRenew = now
+	}
+	le.setLeader(held)
+}
+
+func (le *leaderElection) setLeader(leader bool) {
+	if le.leader.Swap(leader) == leader {
+		return
+	}
+	if leader {
+		le.logger.Info("Acquired the leadership")
+	} else {
+		le.logger.Info("Lost the leadership")
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+type fakeLock struct {
+	mu       sync.Mutex
+	held     bool
+	err      error
+	released bool
+}
+
+func (fl *fakeLock) start(context.Context) error {
+	return nil
+}
+
+func (fl *fakeLock) tryAcquireOrRenew(context.Context) (bool, error) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	return fl.held, fl.err
+}
+
+func (fl *fakeLock) release(context.Context) error {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	fl.released = true
+	return nil
+}
+
+func (fl *fakeLock) set(held bool, err error) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	fl.held = held
+	fl.err = err
+}
+
+func newTestConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.RetryPeriod = 10 * time.Millisecond
+	cfg.RenewDeadline = 200 * time.Millisecond
+	return cfg
+}
+
+func TestLeaderElection(t *testing.T) {
+	l := &fakeLock{}
+	le := newLeaderElection(newTestConfig(), zap.NewNop(), l)
+	require.NoError(t, le.Start(context.Background(), componenttest.NewNopHost()))
+	assert.False(t, le.IsLeader())
+
+	l.set(true, nil)
+	assert.Eventually(t, le.IsLeader, time.Second, 5*time.Millisecond)
+
+	l.set(false, nil)
+	assert.Eventually(t, func() bool { return !le.IsLeader() }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, le.Shutdown(context.Background()))
+	assert.False(t, l.released)
+}
+
+func TestLeaderElectionRenewDeadline(t *testing.T) {
+	l := &fakeLock{held: true}
+	le := newLeaderElection(newTestConfig(), zap.NewNop(), l)
+	require.NoError(t, le.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Eventually(t, le.IsLeader, time.Second, 5*time.Millisecond)
+
+	// The leadership is kept while failing to renew it, until the renew deadline.
+	start := time.Now()
+	l.set(false, errors.New("unavailable"))
+	assert.Eventually(t, func() bool { return !le.IsLeader() }, time.Second, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	require.NoError(t, le.Shutdown(context.Background()))
+}
+
+func TestLeaderElectionShutdownReleases(t *testing.T) {
+	l := &fakeLock{held: true}
+	le := newLeaderElection(newTestConfig(), zap.NewNop(), l)
+	require.NoError(t, le.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Eventually(t, le.IsLeader, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, le.Shutdown(context.Background()))
+	assert.False(t, le.IsLeader())
+	assert.True(t, l.released)
+}
+
+func TestLeaderElectionShutdownWithoutStart(t *testing.T) {
+	le := newLeaderElection(newTestConfig(), zap.NewNop(), &fakeLock{})
+	assert.NoError(t, le.Shutdown(context.Background()))
+}
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/extension/experimental/leaderelection"
+)
+
+func TestFactory_CreateDefaultConfig(t *testing.T) {
+	cfg := createDefaultConfig()
+	assert.Equal(t, &Config{
+		ExtensionSettings: config.NewExtensionSettings(config.NewComponentID(typeStr)),
+		LeaseDuration:     15 * time.Second,
+		RenewDeadline:     10 * time.Second,
+		RetryPeriod:       2 * time.Second,
+	}, cfg)
+	assert.NoError(t, configtest.CheckConfigStruct(cfg))
+}
+
+func TestFactory_CreateExtension(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.File = &FileConfig{Path: filepath.Join(t.TempDir(), "leader.lock")}
+
+	ext, err := NewFactory().CreateExtension(context.Background(), componenttest.NewNopExtensionCreateSettings(), cfg)
+	require.NoError(t, err)
+	assert.Implements(t, (*leaderelection.Extension)(nil), ext)
+
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Eventually(t, ext.(leaderelection.Extension).IsLeader, time.Second, 10*time.Millisecond)
+	assert.NoError(t, ext.Shutdown(context.Background()))
+	assert.False(t, ext.(leaderelection.Extension).IsLeader())
+}
+
+func TestFactory_CreateExtensionKubernetes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Identity = "collector-1"
+	cfg.Kubernetes = &KubernetesConfig{LeaseName: "otel-collector"}
+
+	ext, err := NewFactory().CreateExtension(context.Background(), componenttest.NewNopExtensionCreateSettings(), cfg)
+	require.NoError(t, err)
+	le := ext.(*leaderElection)
+	require.IsType(t, &kubernetesLock{}, le.lock)
+	assert.Equal(t, "collector-1", le.lock.(*kubernetesLock).identity)
+}
@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension // import "go.opentelemetry.io/collector/extension/leaderelectionextension"
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/multierr"
+)
+
+// fileLock is an exclusive lock on a file, released by the operating system when the collector exits.
+type fileLock struct {
+	path     string
+	identity string
+	file     *os.File
+}
+
+func newFileLock(path string, identity string) *fileLock {
+	return &fileLock{
+		path:     path,
+		identity: identity,
+	}
+}
+
+func (fl *fileLock) start(context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(fl.path), 0700); err != nil {
+		return fmt.Errorf("failed to create the directory of the lock file: %w", err)
+	}
+	return nil
+}
+
+func (fl *fileLock) tryAcquireOrRenew(context.Context) (bool, error) {
+	if fl.file != nil {
+		// The lock is held until it is released.
+		return true, nil
+	}
+
+	file, err := os.OpenFile(fl.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return false, err
+	}
+	locked, err := lockFile(file)
+	if err != nil || !locked {
+		return false, multierr.Append(err, file.Close())
+	}
+
+	// Record the identity of the holder, for troubleshooting.
+	if err = file.Truncate(0); err == nil {
+		_, err = file.WriteAt([]byte(fl.identity+"\n"), 0)
+	}
+	if err != nil {
+		return false, multierr.Combine(err, unlockFile(file), file.Close())
+	}
+	fl.file = file
+	return true, nil
+}
+
+func (fl *fileLock) release(context.Context) error {
+	if fl.file == nil {
+		return nil
+	}
+	err := multierr.Append(unlockFile(fl.file), fl.file.Close())
+	fl.file = nil
+	return err
+}
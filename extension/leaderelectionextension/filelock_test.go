@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLock(t *testing.T) {
+	if runtime.GOOS != "windows" && runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("file locks are tested on linux, darwin and windows")
+	}
+	path := filepath.Join(t.TempDir(), "leader", "leader.lock")
+	first := newFileLock(path, "collector-1")
+	second := newFileLock(path, "collector-2")
+	require.NoError(t, first.start(context.Background()))
+	require.NoError(t, second.start(context.Background()))
+
+	held, err := first.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+	held, err = first.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	held, err = second.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	require.NoError(t, first.release(context.Background()))
+	held, err = second.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	require.NoError(t, second.release(context.Background()))
+	assert.NoError(t, second.release(context.Background()))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "collector-2\n", string(content))
+}
@@ -0,0 +1,284 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension // import "go.opentelemetry.io/collector/extension/leaderelectionextension"
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// serviceAccountDir is where Kubernetes mounts the credentials of the service account of the pod.
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// microTimeLayout is the layout of the times of the Lease objects.
+	microTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+	maxResponseBytes = 64 * 1024
+)
+
+// errConflict is returned when the Lease was modified by another collector since it was read.
+var errConflict = errors.New("the lease was modified concurrently")
+
+// lease is a coordination.k8s.io/v1 Lease object, limited to the fields used for leader election.
+type lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   leaseMetadata `json:"metadata"`
+	Spec       leaseSpec     `json:"spec"`
+}
+
+type leaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int32  `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+	LeaseTransitions     int32  `json:"leaseTransitions,omitempty"`
+}
+
+// kubernetesLock is a Kubernetes Lease, held while its holder renews it before its duration elapses.
+type kubernetesLock struct {
+	config        *KubernetesConfig
+	identity      string
+	leaseDuration time.Duration
+
+	// tokenFile and caFile are the credentials of the service account, used when they exist.
+	tokenFile string
+	caFile    string
+
+	client    *http.Client
+	leasesURL string
+	namespace string
+
+	// The holder and renew time of the Lease are compared to the last observed ones, with
+	// the local clock, so that the clocks of the collectors don't need to be synchronized.
+	observedHolder    string
+	observedRenewTime string
+	observedTime      time.Time
+}
+
+func newKubernetesLock(config *KubernetesConfig, identity string, leaseDuration time.Duration) *kubernetesLock {
+	return &kubernetesLock{
+		config:        config,
+		identity:      identity,
+		leaseDuration: leaseDuration,
+		tokenFile:     filepath.Join(serviceAccountDir, "token"),
+		caFile:        filepath.Join(serviceAccountDir, "ca.crt"),
+	}
+}
+
+func (kl *kubernetesLock) start(context.Context) error {
+	endpoint := kl.config.Endpoint
+	if endpoint == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return errors.New("not running in a Kubernetes cluster, the \"endpoint\" of the API server must be configured")
+		}
+		endpoint = "https://" + net.JoinHostPort(host, port)
+	}
+
+	kl.namespace = kl.config.Namespace
+	if kl.namespace == "" {
+		namespace, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+		if err != nil {
+			return fmt.Errorf("failed to read the namespace of the pod, the \"namespace\" of the lease must be configured: %w", err)
+		}
+		kl.namespace = strings.TrimSpace(string(namespace))
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if ca, err := os.ReadFile(kl.caFile); err == nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("failed to load the CA certificate %q", kl.caFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	kl.client = &http.Client{Transport: transport}
+	kl.leasesURL = strings.TrimSuffix(endpoint, "/") + "/apis/coordination.k8s.io/v1/namespaces/" + kl.namespace + "/leases"
+	return nil
+}
+
+func (kl *kubernetesLock) tryAcquireOrRenew(ctx context.Context) (bool, error) {
+	now := time.Now()
+	l, err := kl.get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if l == nil {
+		l = &lease{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata:   leaseMetadata{Name: kl.config.LeaseName, Namespace: kl.namespace},
+			Spec: leaseSpec{
+				HolderIdentity:       kl.identity,
+				LeaseDurationSeconds: int32(kl.leaseDuration / time.Second),
+				AcquireTime:          now.UTC().Format(microTimeLayout),
+				RenewTime:            now.UTC().Format(microTimeLayout),
+			},
+		}
+		if l, err = kl.do(ctx, http.MethodPost, kl.leasesURL, l); err != nil {
+			return false, ignoreConflict(err)
+		}
+		kl.observe(l, now)
+		return true, nil
+	}
+
+	if l.Spec.HolderIdentity != kl.observedHolder || l.Spec.RenewTime != kl.observedRenewTime {
+		kl.observe(l, now)
+	}
+	if l.Spec.HolderIdentity != "" && l.Spec.HolderIdentity != kl.identity {
+		duration := time.Duration(l.Spec.LeaseDurationSeconds) * time.Second
+		if kl.observedTime.Add(duration).After(now) {
+			// Another collector holds the lease.
+			return false, nil
+		}
+	}
+
+	if l.Spec.HolderIdentity != kl.identity {
+		l.Spec.AcquireTime = now.UTC().Format(microTimeLayout)
+		l.Spec.LeaseTransitions++
+	}
+	l.Spec.HolderIdentity = kl.identity
+	l.Spec.LeaseDurationSeconds = int32(kl.leaseDuration / time.Second)
+	l.Spec.RenewTime = now.UTC().Format(microTimeLayout)
+	if l, err = kl.do(ctx, http.MethodPut, kl.leaseURL(), l); err != nil {
+		return false, ignoreConflict(err)
+	}
+	kl.observe(l, now)
+	return true, nil
+}
+
+func (kl *kubernetesLock) release(ctx context.Context) error {
+	l, err := kl.get(ctx)
+	if err != nil || l == nil || l.Spec.HolderIdentity != kl.identity {
+		return err
+	}
+
+	// Let the other collectors acquire the lease right away.
+	l.Spec.HolderIdentity = ""
+	l.Spec.LeaseDurationSeconds = 1
+	l.Spec.RenewTime = time.Now().UTC().Format(microTimeLayout)
+	_, err = kl.do(ctx, http.MethodPut, kl.leaseURL(), l)
+	return ignoreConflict(err)
+}
+
+func (kl *kubernetesLock) observe(l *lease, now time.Time) {
+	kl.observedHolder = l.Spec.HolderIdentity
+	kl.observedRenewTime = l.Spec.RenewTime
+	kl.observedTime = now
+}
+
+func (kl *kubernetesLock) leaseURL() string {
+	return kl.leasesURL + "/" + kl.config.LeaseName
+}
+
+// get returns the Lease, nil if it does not exist.
+func (kl *kubernetesLock) get(ctx context.Context) (*lease, error) {
+	l, err := kl.do(ctx, http.MethodGet, kl.leaseURL(), nil)
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.code == http.StatusNotFound {
+		return nil, nil
+	}
+	return l, err
+}
+
+// do sends the request to the API server and returns the Lease of the response.
+func (kl *kubernetesLock) do(ctx context.Context, method string, url string, l *lease) (*lease, error) {
+	var body io.Reader
+	if l != nil {
+		b, err := json.Marshal(l)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if l != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// The token is read on every request, as Kubernetes rotates it.
+	if token, err := os.ReadFile(kl.tokenFile); err == nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := kl.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+	case http.StatusConflict:
+		return nil, errConflict
+	default:
+		return nil, &statusError{code: resp.StatusCode, method: method, url: url, body: string(respBody)}
+	}
+
+	result := &lease{}
+	if err = json.Unmarshal(respBody, result); err != nil {
+		return nil, fmt.Errorf("failed to decode the lease: %w", err)
+	}
+	return result, nil
+}
+
+// statusError is returned when the API server responds with an unexpected status code.
+type statusError struct {
+	code   int
+	method string
+	url    string
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s %s responded with HTTP status code %d: %s", e.method, e.url, e.code, e.body)
+}
+
+// ignoreConflict returns nil for the conflicts, which mean another collector updated the lease first.
+func ignoreConflict(err error) error {
+	if errors.Is(err, errConflict) {
+		return nil
+	}
+	return err
+}
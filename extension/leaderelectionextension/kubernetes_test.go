@@ -0,0 +1,220 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelectionextension
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLeasePath = "/apis/coordination.k8s.io/v1/namespaces/observability/leases"
+
+// fakeLeaseAPI serves a single Lease, with the optimistic concurrency of the Kubernetes API server.
+type fakeLeaseAPI struct {
+	mu      sync.Mutex
+	lease   *lease
+	version int
+	auth    string
+}
+
+func (api *fakeLeaseAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.auth = r.Header.Get("Authorization")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == testLeasePath+"/otel-collector":
+		if api.lease == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	case r.Method == http.MethodPost && r.URL.Path == testLeasePath:
+		if api.lease != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if !api.decode(w, r) {
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && r.URL.Path == testLeasePath+"/otel-collector":
+		if api.lease == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !api.decode(w, r) {
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(api.lease)
+}
+
+func (api *fakeLeaseAPI) decode(w http.ResponseWriter, r *http.Request) bool {
+	l := &lease{}
+	if err := json.NewDecoder(r.Body).Decode(l); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+	if api.lease != nil && l.Metadata.ResourceVersion != api.lease.Metadata.ResourceVersion {
+		w.WriteHeader(http.StatusConflict)
+		return false
+	}
+	api.version++
+	l.Metadata.ResourceVersion = strconv.Itoa(api.version)
+	api.lease = l
+	return true
+}
+
+func (api *fakeLeaseAPI) get() lease {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return *api.lease
+}
+
+func newTestKubernetesLock(t *testing.T, endpoint string, identity string) *kubernetesLock {
+	kl := newKubernetesLock(&KubernetesConfig{
+		LeaseName: "otel-collector",
+		Namespace: "observability",
+		Endpoint:  endpoint,
+	}, identity, 15*time.Second)
+	dir := t.TempDir()
+	kl.tokenFile = filepath.Join(dir, "token")
+	kl.caFile = filepath.Join(dir, "ca.crt")
+	require.NoError(t, kl.start(context.Background()))
+	return kl
+}
+
+func TestKubernetesLock(t *testing.T) {
+	api := &fakeLeaseAPI{}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	first := newTestKubernetesLock(t, srv.URL, "collector-1")
+	second := newTestKubernetesLock(t, srv.URL, "collector-2")
+	require.NoError(t, os.WriteFile(first.tokenFile, []byte("secret\n"), 0600))
+
+	// The lease is created by the first collector.
+	held, err := first.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+	assert.Equal(t, "Bearer secret", api.auth)
+	l := api.get()
+	assert.Equal(t, "collector-1", l.Spec.HolderIdentity)
+	assert.EqualValues(t, 15, l.Spec.LeaseDurationSeconds)
+	assert.Equal(t, l.Spec.AcquireTime, l.Spec.RenewTime)
+
+	held, err = second.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.False(t, held)
+	assert.Empty(t, api.auth)
+
+	// The lease is renewed by its holder.
+	held, err = first.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+	assert.Equal(t, "collector-1", api.get().Spec.HolderIdentity)
+	assert.Zero(t, api.get().Spec.LeaseTransitions)
+
+	// The lease is acquired by the second collector once released.
+	require.NoError(t, first.release(context.Background()))
+	assert.Empty(t, api.get().Spec.HolderIdentity)
+	held, err = second.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+	l = api.get()
+	assert.Equal(t, "collector-2", l.Spec.HolderIdentity)
+	assert.EqualValues(t, 1, l.Spec.LeaseTransitions)
+
+	// Releasing a lease held by another collector does nothing.
+	require.NoError(t, first.release(context.Background()))
+	assert.Equal(t, "collector-2", api.get().Spec.HolderIdentity)
+}
+
+func TestKubernetesLockExpired(t *testing.T) {
+	api := &fakeLeaseAPI{}
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	first := newTestKubernetesLock(t, srv.URL, "collector-1")
+	second := newTestKubernetesLock(t, srv.URL, "collector-2")
+
+	held, err := first.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+	held, err = second.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	// The lease expires when it has not been renewed for its duration, on the clock of the second collector.
+	second.observedTime = second.observedTime.Add(-16 * time.Second)
+	held, err = second.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+	assert.Equal(t, "collector-2", api.get().Spec.HolderIdentity)
+
+	held, err = first.tryAcquireOrRenew(context.Background())
+	require.NoError(t, err)
+	assert.False(t, held)
+}
+
+func TestKubernetesLockConflict(t *testing.T) {
+	api := &fakeLeaseAPI{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			api.ServeHTTP(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	kl := newTestKubernetesLock(t, srv.URL, "collector-1")
+	held, err := kl.tryAcquireOrRenew(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, held)
+}
+
+func TestKubernetesLockError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("forbidden"))
+	}))
+	defer srv.Close()
+
+	kl := newTestKubernetesLock(t, srv.URL, "collector-1")
+	held, err := kl.tryAcquireOrRenew(context.Background())
+	assert.EqualError(t, err, "GET "+srv.URL+testLeasePath+"/otel-collector responded with HTTP status code 403: forbidden")
+	assert.False(t, held)
+}
+
+func TestKubernetesLockStartOutsideCluster(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	kl := newKubernetesLock(&KubernetesConfig{LeaseName: "otel-collector"}, "collector-1", 15*time.Second)
+	assert.EqualError(t, kl.start(context.Background()), "not running in a Kubernetes cluster, the \"endpoint\" of the API server must be configured")
+}
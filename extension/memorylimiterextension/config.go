@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorylimiterextension // import "go.opentelemetry.io/collector/extension/memorylimiterextension"
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configbytes"
+	"go.opentelemetry.io/collector/internal/memorylimiter"
+)
+
+// Config defines configuration for the memory limiter extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// CheckInterval is the time between measurements of memory usage for the
+	// purposes of avoiding going over the limits.
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+
+	// MemoryLimitMiB is the maximum amount of memory, in MiB, targeted to be
+	// allocated by the process.
+	MemoryLimitMiB uint32 `mapstructure:"limit_mib"`
+
+	// MemorySpikeLimitMiB is the maximum, in MiB, spike expected between the
+	// measurements of memory usage.
+	MemorySpikeLimitMiB uint32 `mapstructure:"spike_limit_mib"`
+
+	// MemoryLimit is the maximum amount of memory targeted to be allocated by the
+	// process, as a byte size such as "4GiB". It cannot be set together with MemoryLimitMiB.
+	MemoryLimit configbytes.ByteSize `mapstructure:"limit"`
+
+	// MemorySpikeLimit is the maximum spike expected between the measurements of memory
+	// usage, as a byte size. It cannot be set together with MemorySpikeLimitMiB.
+	MemorySpikeLimit configbytes.ByteSize `mapstructure:"spike_limit"`
+
+	// MemoryLimitPercentage is the maximum amount of memory, in %, targeted to be
+	// allocated by the process. The fixed memory settings MemoryLimitMiB and MemoryLimit have a higher precedence.
+	MemoryLimitPercentage uint32 `mapstructure:"limit_percentage"`
+
+	// MemorySpikePercentage is the maximum, in percents against the total memory,
+	// spike expected between the measurements of memory usage.
+	MemorySpikePercentage uint32 `mapstructure:"spike_limit_percentage"`
+
+	// WaitTimeout is how long an HTTP request is delayed, waiting for the memory usage
+	// to go back within the limits, before being refused. Zero refuses it immediately.
+	// gRPC calls are always refused immediately.
+	WaitTimeout time.Duration `mapstructure:"wait_timeout"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if err := cfg.memoryLimiterConfig().Validate(); err != nil {
+		return err
+	}
+	if cfg.WaitTimeout < 0 {
+		return errors.New("\"wait_timeout\" must not be negative")
+	}
+	return nil
+}
+
+func (cfg *Config) memoryLimiterConfig() memorylimiter.Config {
+	return memorylimiter.Config{
+		CheckInterval:         cfg.CheckInterval,
+		MemoryLimitMiB:        cfg.MemoryLimitMiB,
+		MemorySpikeLimitMiB:   cfg.MemorySpikeLimitMiB,
+		MemoryLimit:           cfg.MemoryLimit,
+		MemorySpikeLimit:      cfg.MemorySpikeLimit,
+		MemoryLimitPercentage: cfg.MemoryLimitPercentage,
+		MemorySpikePercentage: cfg.MemorySpikePercentage,
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorylimiterextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configbytes"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/internal/memorylimiter"
+)
+
+func TestUnmarshalDefaultConfig(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.NoError(t, config.UnmarshalExtension(confmap.New(), cfg))
+	assert.Equal(t, factory.CreateDefaultConfig(), cfg)
+}
+
+func TestUnmarshalConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.NoError(t, config.UnmarshalExtension(cm, cfg))
+	assert.Equal(t,
+		&Config{
+			ExtensionSettings:   config.NewExtensionSettings(config.NewComponentID(typeStr)),
+			CheckInterval:       2 * time.Second,
+			MemoryLimitMiB:      4000,
+			MemorySpikeLimitMiB: 500,
+			WaitTimeout:         100 * time.Millisecond,
+		}, cfg)
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.ErrorIs(t, cfg.Validate(), memorylimiter.ErrLimitOutOfRange)
+
+	cfg.MemoryLimitPercentage = 80
+	assert.NoError(t, cfg.Validate())
+
+	cfg.MemoryLimit = 4 * configbytes.Gibibyte
+	assert.NoError(t, cfg.Validate())
+
+	cfg.MemoryLimitMiB = 4000
+	assert.ErrorIs(t, cfg.Validate(), memorylimiter.ErrLimitConflict)
+
+	cfg.MemoryLimitMiB = 0
+	cfg.WaitTimeout = -time.Second
+	assert.EqualError(t, cfg.Validate(), "\"wait_timeout\" must not be negative")
+
+	cfg.CheckInterval = 0
+	assert.ErrorIs(t, cfg.Validate(), memorylimiter.ErrCheckIntervalOutOfRange)
+}
@@ -0,0 +1,160 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorylimiterextension // import "go.opentelemetry.io/collector/extension/memorylimiterextension"
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configmiddleware"
+	"go.opentelemetry.io/collector/internal/memorylimiter"
+)
+
+// errDataRefused is returned to the clients while the memory usage is above the limits.
+var errDataRefused = errors.New("data refused due to high memory usage")
+
+var (
+	_ configmiddleware.ServerMiddleware   = (*memoryLimiterExtension)(nil)
+	_ configmiddleware.ServerInTapHandler = (*memoryLimiterExtension)(nil)
+)
+
+// memoryLimiterExtension refuses the requests received by the servers it is
+// configured on with retryable errors while the memory usage is too high, so that
+// the clients keep the data and send it again later.
+type memoryLimiterExtension struct {
+	memlimiter *memorylimiter.MemoryLimiter
+
+	// The function deciding whether data is refused is set as a reference to
+	// help with testing.
+	mustRefuse func() bool
+
+	checkInterval time.Duration
+	waitTimeout   time.Duration
+	logger        *zap.Logger
+}
+
+func newMemoryLimiterExtension(cfg *Config, logger *zap.Logger) (*memoryLimiterExtension, error) {
+	memlimiter, err := memorylimiter.NewMemoryLimiter(cfg.memoryLimiterConfig(), logger)
+	if err != nil {
+		return nil, err
+	}
+	return &memoryLimiterExtension{
+		memlimiter:    memlimiter,
+		mustRefuse:    memlimiter.MustRefuse,
+		checkInterval: cfg.CheckInterval,
+		waitTimeout:   cfg.WaitTimeout,
+		logger:        logger,
+	}, nil
+}
+
+func (ml *memoryLimiterExtension) Start(ctx context.Context, host component.Host) error {
+	return ml.memlimiter.Start(ctx, host)
+}
+
+func (ml *memoryLimiterExtension) Shutdown(ctx context.Context) error {
+	return ml.memlimiter.Shutdown(ctx)
+}
+
+// admit returns whether a request can be accepted, waiting up to the wait timeout
+// for the memory usage to go back within the limits.
+func (ml *memoryLimiterExtension) admit(ctx context.Context) bool {
+	if !ml.mustRefuse() {
+		return true
+	}
+	if ml.waitTimeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(ml.waitTimeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(ml.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !ml.mustRefuse() {
+				return true
+			}
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// retryAfter returns the delay suggested to the clients before sending the data again.
+func (ml *memoryLimiterExtension) retryAfter() time.Duration {
+	return ml.checkInterval
+}
+
+// GetHTTPHandler wraps the handler to respond with "503 Service Unavailable" and
+// a "Retry-After" header, without reading the body, while the data is refused.
+func (ml *memoryLimiterExtension) GetHTTPHandler(next http.Handler) (http.Handler, error) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ml.admit(r.Context()) {
+			ml.logger.Debug("Refusing HTTP request due to high memory usage.")
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(ml.retryAfter().Seconds()))))
+			http.Error(w, errDataRefused.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}), nil
+}
+
+// GetUnaryServerInterceptor returns no interceptor, the gRPC calls are refused by the in tap handle.
+func (ml *memoryLimiterExtension) GetUnaryServerInterceptor() (grpc.UnaryServerInterceptor, error) {
+	return nil, nil
+}
+
+// GetStreamServerInterceptor returns no interceptor, the gRPC calls are refused by the in tap handle.
+func (ml *memoryLimiterExtension) GetStreamServerInterceptor() (grpc.StreamServerInterceptor, error) {
+	return nil, nil
+}
+
+// GetInTapHandle returns a handle failing the new gRPC calls, unary or streaming, with the
+// "Unavailable" code while the data is refused, before the requests are read and decoded.
+// Since the handle must not block the connection, it doesn't wait for the memory usage to
+// go back within the limits.
+func (ml *memoryLimiterExtension) GetInTapHandle() (tap.ServerInHandle, error) {
+	return func(ctx context.Context, _ *tap.Info) (context.Context, error) {
+		if ml.mustRefuse() {
+			ml.logger.Debug("Refusing gRPC call due to high memory usage.")
+			return nil, ml.refusedStatus().Err()
+		}
+		return ctx, nil
+	}, nil
+}
+
+func (ml *memoryLimiterExtension) refusedStatus() *status.Status {
+	st := status.New(codes.Unavailable, errDataRefused.Error())
+	if withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(ml.retryAfter())}); err == nil {
+		return withDetails
+	}
+	return st
+}
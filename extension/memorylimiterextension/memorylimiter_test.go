@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorylimiterextension
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+)
+
+func newTestExtension(t *testing.T, refuse *atomic.Bool, waitTimeout time.Duration) *memoryLimiterExtension {
+	cfg := createDefaultConfig().(*Config)
+	cfg.CheckInterval = 10 * time.Millisecond
+	cfg.MemoryLimitMiB = 1024
+	cfg.WaitTimeout = waitTimeout
+	ml, err := newMemoryLimiterExtension(cfg, zap.NewNop())
+	require.NoError(t, err)
+	ml.mustRefuse = refuse.Load
+	return ml
+}
+
+func TestHTTPHandler(t *testing.T) {
+	refuse := atomic.NewBool(false)
+	ml := newTestExtension(t, refuse, 0)
+
+	var served int
+	handler, err := ml.GetHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+	}))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/traces", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, served)
+
+	refuse.Store(true)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/traces", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), errDataRefused.Error())
+	assert.Equal(t, 1, served)
+}
+
+func TestInTapHandle(t *testing.T) {
+	refuse := atomic.NewBool(true)
+	// The wait timeout doesn't delay the gRPC calls.
+	ml := newTestExtension(t, refuse, time.Minute)
+
+	unary, err := ml.GetUnaryServerInterceptor()
+	require.NoError(t, err)
+	assert.Nil(t, unary)
+	stream, err := ml.GetStreamServerInterceptor()
+	require.NoError(t, err)
+	assert.Nil(t, stream)
+
+	handle, err := ml.GetInTapHandle()
+	require.NoError(t, err)
+	info := &tap.Info{FullMethodName: "/opentelemetry.proto.collector.trace.v1.TraceService/Export"}
+
+	_, err = handle(context.Background(), info)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Unavailable, st.Code())
+	require.Len(t, st.Details(), 1)
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Equal(t, 10*time.Millisecond, retryInfo.RetryDelay.AsDuration())
+
+	refuse.Store(false)
+	ctx, err := handle(context.Background(), info)
+	assert.NoError(t, err)
+	assert.Equal(t, context.Background(), ctx)
+}
+
+func TestAdmitWaitsForMemory(t *testing.T) {
+	refuse := atomic.NewBool(true)
+	ml := newTestExtension(t, refuse, time.Minute)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		refuse.Store(false)
+	}()
+	assert.True(t, ml.admit(context.Background()))
+}
+
+func TestAdmitWaitTimeout(t *testing.T) {
+	refuse := atomic.NewBool(true)
+	ml := newTestExtension(t, refuse, 50*time.Millisecond)
+	assert.False(t, ml.admit(context.Background()))
+
+	ml.waitTimeout = time.Minute
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, ml.admit(ctx))
+}
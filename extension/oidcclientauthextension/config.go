@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcclientauthextension // import "go.opentelemetry.io/collector/extension/oidcclientauthextension"
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+var (
+	errNoClientIDProvided     = errors.New("no ClientID provided in the OIDC client configuration")
+	errNoSecretProvided       = errors.New("no ClientSecret provided in the OIDC client configuration")
+	errNoTokenSourceProvided  = errors.New("either IssuerURL or TokenURL must be provided in the OIDC client configuration")
+	errNegativeExpiryBuffer   = errors.New("ExpiryBuffer must not be negative")
+	errNegativeTimeoutSetting = errors.New("Timeout must not be negative")
+)
+
+// Config stores the configuration for the OIDC client credentials authenticator.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// ClientID is the application's ID.
+	ClientID string `mapstructure:"client_id"`
+
+	// ClientSecret is the application's secret.
+	ClientSecret configopaque.String `mapstructure:"client_secret"`
+
+	// IssuerURL is the base URL of the OIDC provider. When set, the token endpoint is
+	// discovered from "<issuer_url>/.well-known/openid-configuration" on first use.
+	IssuerURL string `mapstructure:"issuer_url"`
+
+	// TokenURL is the resource server's token endpoint URL. When set, it takes precedence
+	// over the endpoint discovered from IssuerURL.
+	TokenURL string `mapstructure:"token_url"`
+
+	// Scopes specifies optional requested permissions.
+	Scopes []string `mapstructure:"scopes"`
+
+	// Audience is sent as the "audience" parameter of the token request when set.
+	Audience string `mapstructure:"audience"`
+
+	// EndpointParams specifies additional parameters for requests to the token endpoint.
+	EndpointParams map[string]string `mapstructure:"endpoint_params"`
+
+	// ExpiryBuffer is how long before the token expiry a new token is requested,
+	// so that in-flight requests never carry a token that is about to expire.
+	ExpiryBuffer time.Duration `mapstructure:"expiry_buffer"`
+
+	// TLSSetting struct exposes TLS client configuration for the token client.
+	TLSSetting configtls.TLSClientSetting `mapstructure:"tls,omitempty"`
+
+	// Timeout parameter configures `http.Client.Timeout` for the token client.
+	Timeout time.Duration `mapstructure:"timeout,omitempty"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.ClientID == "" {
+		return errNoClientIDProvided
+	}
+	if cfg.ClientSecret == "" {
+		return errNoSecretProvided
+	}
+	if cfg.IssuerURL == "" && cfg.TokenURL == "" {
+		return errNoTokenSourceProvided
+	}
+	if cfg.ExpiryBuffer < 0 {
+		return errNegativeExpiryBuffer
+	}
+	if cfg.Timeout < 0 {
+		return errNegativeTimeoutSetting
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcclientauthextension
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestUnmarshalDefaultConfig(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.NoError(t, config.UnmarshalExtension(confmap.New(), cfg))
+	assert.Equal(t, factory.CreateDefaultConfig(), cfg)
+}
+
+func TestUnmarshalConfig(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.NoError(t, config.UnmarshalExtension(cm, cfg))
+	assert.Equal(t,
+		&Config{
+			ExtensionSettings: config.NewExtensionSettings(config.NewComponentID(typeStr)),
+			ClientID:          "someclientid",
+			ClientSecret:      "someclientsecret",
+			IssuerURL:         "https://example.com/auth/realms/opentelemetry",
+			Scopes:            []string{"api.metrics"},
+			Audience:          "collector",
+			EndpointParams:    map[string]string{"resource": "https://example.com/api"},
+			ExpiryBuffer:      time.Minute,
+			TLSSetting: configtls.TLSClientSetting{
+				TLSSetting: configtls.TLSSetting{
+					CAFile:   "cafile",
+					CertFile: "certfile",
+					KeyFile:  "keyfile",
+				},
+				Insecure: true,
+			},
+			Timeout: 2 * time.Second,
+		}, cfg)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		modifyFn func(cfg *Config)
+		expected error
+	}{
+		{
+			name:     "valid token url",
+			modifyFn: func(cfg *Config) {},
+		},
+		{
+			name: "valid issuer url",
+			modifyFn: func(cfg *Config) {
+				cfg.TokenURL = ""
+				cfg.IssuerURL = "https://example.com"
+			},
+		},
+		{
+			name:     "missing client id",
+			modifyFn: func(cfg *Config) { cfg.ClientID = "" },
+			expected: errNoClientIDProvided,
+		},
+		{
+			name:     "missing client secret",
+			modifyFn: func(cfg *Config) { cfg.ClientSecret = "" },
+			expected: errNoSecretProvided,
+		},
+		{
+			name:     "missing token url and issuer url",
+			modifyFn: func(cfg *Config) { cfg.TokenURL = "" },
+			expected: errNoTokenSourceProvided,
+		},
+		{
+			name:     "negative expiry buffer",
+			modifyFn: func(cfg *Config) { cfg.ExpiryBuffer = -time.Second },
+			expected: errNegativeExpiryBuffer,
+		},
+		{
+			name:     "negative timeout",
+			modifyFn: func(cfg *Config) { cfg.Timeout = -time.Second },
+			expected: errNegativeTimeoutSetting,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.ClientID = "id"
+			cfg.ClientSecret = "secret"
+			cfg.TokenURL = "https://example.com/token"
+			tt.modifyFn(cfg)
+			assert.Equal(t, tt.expected, cfg.Validate())
+		})
+	}
+}
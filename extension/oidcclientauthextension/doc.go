@@ -0,0 +1,19 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oidcclientauthextension implements a client authenticator that obtains
+// OAuth2 access tokens using the client credentials flow, optionally discovering
+// the token endpoint via OpenID Connect discovery, and injects them into
+// outgoing HTTP and gRPC requests.
+package oidcclientauthextension // import "go.opentelemetry.io/collector/extension/oidcclientauthextension"
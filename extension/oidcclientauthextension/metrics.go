@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcclientauthextension // import "go.opentelemetry.io/collector/extension/oidcclientauthextension"
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	extensionTagKey         = tag.MustNewKey("extension")
+	statTokenRefreshes      = stats.Int64("oidcclient_token_refreshes", "Number of access tokens successfully obtained from the token endpoint", stats.UnitDimensionless)
+	statTokenRefreshFailure = stats.Int64("oidcclient_token_refresh_failures", "Number of failed attempts to obtain an access token from the token endpoint", stats.UnitDimensionless)
+)
+
+// MetricViews returns the metrics views related to token refreshes.
+func MetricViews() []*view.View {
+	tagKeys := []tag.Key{extensionTagKey}
+
+	countTokenRefreshesView := &view.View{
+		Name:        statTokenRefreshes.Name(),
+		Measure:     statTokenRefreshes,
+		Description: statTokenRefreshes.Description(),
+		TagKeys:     tagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	countTokenRefreshFailuresView := &view.View{
+		Name:        statTokenRefreshFailure.Name(),
+		Measure:     statTokenRefreshFailure,
+		Description: statTokenRefreshFailure.Description(),
+		TagKeys:     tagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	return []*view.View{
+		countTokenRefreshesView,
+		countTokenRefreshFailuresView,
+	}
+}
@@ -0,0 +1,312 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcclientauthextension // import "go.opentelemetry.io/collector/extension/oidcclientauthextension"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configauth"
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+var _ configauth.ClientAuthenticator = (*clientAuthenticator)(nil)
+
+// clientAuthenticator implements configauth.ClientAuthenticator using the OAuth2
+// client credentials flow. Tokens are cached and transparently refreshed once they
+// are within Config.ExpiryBuffer of their expiry.
+type clientAuthenticator struct {
+	config    *Config
+	telemetry component.TelemetrySettings
+	client    *http.Client
+	ctx       context.Context
+
+	mu    sync.Mutex
+	token *token
+	// refresh is the in-flight token refresh, nil if none. The token endpoint is requested
+	// without holding mu, and the concurrent callers wait for the same refresh.
+	refresh *tokenRefresh
+
+	// tokenURL is only accessed by the in-flight refresh.
+	tokenURL string
+}
+
+// tokenRefresh is a token request shared by the callers needing a new token.
+type tokenRefresh struct {
+	// done is closed once tok or err is set.
+	done chan struct{}
+	tok  *token
+	err  error
+}
+
+type token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	expiry      time.Time
+}
+
+type tokenErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type discoveryDocument struct {
+	TokenEndpoint string `json:"token_endpoint"`
+}
+
+func newClientAuthenticator(cfg *Config, telemetry component.TelemetrySettings) *clientAuthenticator {
+	return &clientAuthenticator{
+		config:    cfg,
+		telemetry: telemetry,
+		tokenURL:  cfg.TokenURL,
+		client:    &http.Client{Timeout: cfg.Timeout},
+		ctx:       context.Background(),
+	}
+}
+
+func (a *clientAuthenticator) Start(context.Context, component.Host) error {
+	tlsCfg, err := a.config.TLSSetting.LoadTLSConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load TLS settings for the token client: %w", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	a.client.Transport = transport
+	a.ctx, err = tag.New(context.Background(), tag.Upsert(extensionTagKey, a.config.ID().String()))
+	return err
+}
+
+func (a *clientAuthenticator) Shutdown(context.Context) error {
+	a.client.CloseIdleConnections()
+	return nil
+}
+
+// RoundTripper returns a RoundTripper that adds a valid access token to every request.
+func (a *clientAuthenticator) RoundTripper(base http.RoundTripper) (http.RoundTripper, error) {
+	return &roundTripper{base: base, auth: a}, nil
+}
+
+// PerRPCCredentials returns gRPC credentials that add a valid access token to every RPC.
+func (a *clientAuthenticator) PerRPCCredentials() (credentials.PerRPCCredentials, error) {
+	return &perRPCCredentials{auth: a}, nil
+}
+
+// authorization returns the value of the Authorization header, refreshing the cached
+// token when it is missing or about to expire.
+func (a *clientAuthenticator) authorization(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	if a.token != nil && time.Now().Add(a.config.ExpiryBuffer).Before(a.token.expiry) {
+		tok := a.token
+		a.mu.Unlock()
+		return tok.authorization(), nil
+	}
+	refresh := a.refresh
+	if refresh == nil {
+		refresh = &tokenRefresh{done: make(chan struct{})}
+		a.refresh = refresh
+		go a.refreshToken(refresh)
+	}
+	a.mu.Unlock()
+
+	// Every caller, including the one that started the refresh, only gives up its own wait.
+	select {
+	case <-refresh.done:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	if refresh.err != nil {
+		return "", refresh.err
+	}
+	return refresh.tok.authorization(), nil
+}
+
+// refreshToken requests a new token, caches it and completes the refresh.
+func (a *clientAuthenticator) refreshToken(refresh *tokenRefresh) {
+	// The refresh is shared by the callers, so it is detached from their contexts, and only
+	// bounded by the configured timeout.
+	ctx := context.Background()
+	if a.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.config.Timeout)
+		defer cancel()
+	}
+	refresh.tok, refresh.err = a.fetchToken(ctx)
+	if refresh.err != nil {
+		stats.Record(a.ctx, statTokenRefreshFailure.M(1))
+		a.telemetry.Logger.Warn("Failed to obtain an access token", zap.Error(refresh.err))
+	} else {
+		stats.Record(a.ctx, statTokenRefreshes.M(1))
+	}
+
+	a.mu.Lock()
+	if refresh.err == nil {
+		a.token = refresh.tok
+	}
+	a.refresh = nil
+	a.mu.Unlock()
+	close(refresh.done)
+}
+
+// authorization returns the value of the Authorization header carrying the token.
+func (t *token) authorization() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+func (a *clientAuthenticator) fetchToken(ctx context.Context) (*token, error) {
+	if a.tokenURL == "" {
+		tokenURL, err := a.discoverTokenURL(ctx)
+		if err != nil {
+			return nil, err
+		}
+		a.tokenURL = tokenURL
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(a.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.config.Scopes, " "))
+	}
+	if a.config.Audience != "" {
+		form.Set("audience", a.config.Audience)
+	}
+	for k, v := range a.config.EndpointParams {
+		form.Set(k, v)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(a.config.ClientID), url.QueryEscape(string(a.config.ClientSecret)))
+
+	body, err := a.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request to %q failed: %w", a.tokenURL, err)
+	}
+
+	tok := &token{}
+	if err = json.Unmarshal(body, tok); err != nil {
+		return nil, fmt.Errorf("cannot decode token response: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return nil, errors.New("token response does not contain an access_token")
+	}
+	if tok.ExpiresIn > 0 {
+		tok.expiry = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	} else {
+		// Tokens without an expiry are refreshed as soon as the expiry buffer allows it.
+		tok.expiry = time.Now().Add(a.config.ExpiryBuffer + time.Minute)
+	}
+	return tok, nil
+}
+
+func (a *clientAuthenticator) discoverTokenURL(ctx context.Context) (string, error) {
+	discoveryURL := strings.TrimSuffix(a.config.IssuerURL, "/") + discoveryPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	body, err := a.do(req)
+	if err != nil {
+		return "", fmt.Errorf("OIDC discovery request to %q failed: %w", discoveryURL, err)
+	}
+
+	doc := discoveryDocument{}
+	if err = json.Unmarshal(body, &doc); err != nil {
+		return "", fmt.Errorf("cannot decode OIDC discovery document: %w", err)
+	}
+	if doc.TokenEndpoint == "" {
+		return "", fmt.Errorf("OIDC discovery document at %q does not contain a token_endpoint", discoveryURL)
+	}
+	return doc.TokenEndpoint, nil
+}
+
+func (a *clientAuthenticator) do(req *http.Request) ([]byte, error) {
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		errResp := tokenErrorResponse{}
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("status %d: %s %s", resp.StatusCode, errResp.Error, errResp.ErrorDescription)
+		}
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+type roundTripper struct {
+	base http.RoundTripper
+	auth *clientAuthenticator
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	authorization, err := rt.auth.authorization(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	// Per the RoundTripper contract the original request must not be modified.
+	req2 := req.Clone(req.Context())
+	req2.Header.Set("Authorization", authorization)
+	return rt.base.RoundTrip(req2)
+}
+
+type perRPCCredentials struct {
+	auth *clientAuthenticator
+}
+
+func (c *perRPCCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	authorization, err := c.auth.authorization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": authorization}, nil
+}
+
+func (c *perRPCCredentials) RequireTransportSecurity() bool {
+	return true
+}
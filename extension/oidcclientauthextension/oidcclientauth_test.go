@@ -0,0 +1,285 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidcclientauthextension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/atomic"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+type tokenServer struct {
+	*httptest.Server
+	tokenRequests *atomic.Int64
+}
+
+func newTokenServer(t *testing.T, expiresIn int64) *tokenServer {
+	ts := &tokenServer{tokenRequests: atomic.NewInt64(0)}
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewEncoder(w).Encode(discoveryDocument{TokenEndpoint: ts.URL + "/token"}))
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "id" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"bad credentials"}`))
+			return
+		}
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "read write", r.PostForm.Get("scope"))
+		assert.Equal(t, "collector", r.PostForm.Get("audience"))
+		n := ts.tokenRequests.Inc()
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "bearer",
+			"expires_in":   expiresIn,
+		}))
+	})
+	ts.Server = httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newTestConfig(tokenURL string) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ClientID = "id"
+	cfg.ClientSecret = "secret"
+	cfg.TokenURL = tokenURL
+	cfg.Scopes = []string{"read", "write"}
+	cfg.Audience = "collector"
+	return cfg
+}
+
+func startAuthenticator(t *testing.T, cfg *Config) *clientAuthenticator {
+	auth := newClientAuthenticator(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, auth.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { assert.NoError(t, auth.Shutdown(context.Background())) })
+	return auth
+}
+
+func TestRoundTripperAddsToken(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	auth := startAuthenticator(t, newTestConfig(ts.URL+"/token"))
+
+	var received []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Authorization"))
+	}))
+	defer backend.Close()
+
+	rt, err := auth.RoundTripper(http.DefaultTransport)
+	require.NoError(t, err)
+	client := &http.Client{Transport: rt}
+	for i := 0; i < 3; i++ {
+		req, errReq := http.NewRequest(http.MethodGet, backend.URL, nil)
+		require.NoError(t, errReq)
+		resp, errResp := client.Do(req)
+		require.NoError(t, errResp)
+		require.NoError(t, resp.Body.Close())
+		assert.Empty(t, req.Header.Get("Authorization"), "original request must not be modified")
+	}
+
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1", "Bearer token-1"}, received)
+	assert.EqualValues(t, 1, ts.tokenRequests.Load())
+}
+
+func TestConcurrentTokenRefresh(t *testing.T) {
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	tokenRequests := atomic.NewInt64(0)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tokenRequests.Inc() == 1 {
+			close(requested)
+		}
+		<-release
+		_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	defer ts.Close()
+	auth := startAuthenticator(t, newTestConfig(ts.URL))
+
+	var wg sync.WaitGroup
+	results := make(chan string, 5)
+	for i := 0; i < cap(results); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			authorization, err := auth.authorization(context.Background())
+			assert.NoError(t, err)
+			results <- authorization
+		}()
+	}
+	<-requested
+
+	// The lock is not held while the token is requested.
+	require.True(t, auth.mu.TryLock())
+	auth.mu.Unlock()
+
+	// Callers waiting for the refresh give up once their context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := auth.authorization(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	wg.Wait()
+	close(results)
+	for authorization := range results {
+		assert.Equal(t, "Bearer token", authorization)
+	}
+	assert.EqualValues(t, 1, tokenRequests.Load())
+}
+
+func TestTokenRefreshDetachedFromCaller(t *testing.T) {
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-release
+		_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	defer ts.Close()
+	auth := startAuthenticator(t, newTestConfig(ts.URL))
+
+	// The caller starting the refresh gives up its wait, without failing the refresh.
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := auth.authorization(ctx)
+		firstErr <- err
+	}()
+	<-requested
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+
+	waiting := make(chan string, 1)
+	go func() {
+		authorization, err := auth.authorization(context.Background())
+		assert.NoError(t, err)
+		waiting <- authorization
+	}()
+	close(release)
+	assert.Equal(t, "Bearer token", <-waiting)
+}
+
+func TestTokenRefreshTimeout(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+	cfg := newTestConfig(ts.URL)
+	cfg.Timeout = 50 * time.Millisecond
+	auth := startAuthenticator(t, cfg)
+
+	// The refresh is bounded by the configured timeout, not by the caller.
+	_, err := auth.authorization(context.Background())
+	assert.Error(t, err)
+}
+
+func TestTokenRefreshedBeforeExpiry(t *testing.T) {
+	// Tokens expire within the expiry buffer, so every call needs a new token.
+	ts := newTokenServer(t, 10)
+	cfg := newTestConfig(ts.URL + "/token")
+	cfg.ExpiryBuffer = time.Minute
+	auth := startAuthenticator(t, cfg)
+
+	first, err := auth.authorization(context.Background())
+	require.NoError(t, err)
+	second, err := auth.authorization(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer token-1", first)
+	assert.Equal(t, "Bearer token-2", second)
+	assert.EqualValues(t, 2, ts.tokenRequests.Load())
+}
+
+func TestTokenURLDiscovery(t *testing.T) {
+	ts := newTokenServer(t, 3600)
+	cfg := newTestConfig("")
+	cfg.IssuerURL = ts.URL + "/"
+	auth := startAuthenticator(t, cfg)
+
+	creds, err := auth.PerRPCCredentials()
+	require.NoError(t, err)
+	assert.True(t, creds.RequireTransportSecurity())
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer token-1"}, md)
+	assert.Equal(t, ts.URL+"/token", auth.tokenURL)
+}
+
+func TestDiscoveryFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	cfg := newTestConfig("")
+	cfg.IssuerURL = srv.URL
+	auth := startAuthenticator(t, cfg)
+
+	_, err := auth.authorization(context.Background())
+	assert.ErrorContains(t, err, "does not contain a token_endpoint")
+}
+
+func TestTokenFailureMetrics(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	ts := newTokenServer(t, 3600)
+	cfg := newTestConfig(ts.URL + "/token")
+	cfg.ClientSecret = "wrong"
+	auth := startAuthenticator(t, cfg)
+
+	rt, err := auth.RoundTripper(http.DefaultTransport)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	assert.ErrorContains(t, err, "status 401: invalid_client bad credentials")
+
+	rows, err := view.RetrieveData(statTokenRefreshFailure.Name())
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, config.NewComponentID(typeStr).String(), rows[0].Tags[0].Value)
+	assert.Equal(t, float64(1), rows[0].Data.(*view.SumData).Value)
+}
+
+func TestStartInvalidTLS(t *testing.T) {
+	cfg := newTestConfig("https://example.com/token")
+	cfg.TLSSetting = configtls.TLSClientSetting{
+		TLSSetting: configtls.TLSSetting{CAFile: "/nonexistent/ca.pem"},
+	}
+	auth := newClientAuthenticator(cfg, componenttest.NewNopTelemetrySettings())
+	assert.Error(t, auth.Start(context.Background(), componenttest.NewNopHost()))
+}
@@ -0,0 +1,49 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotetapextension // import "go.opentelemetry.io/collector/extension/remotetapextension"
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confignet"
+)
+
+// Config has the configuration for the remote tap extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// TCPAddr is the address and port in which the WebSocket clients connect.
+	// Use localhost:<port> to make it available only locally, or ":<port>" to
+	// make it available on all network interfaces.
+	TCPAddr confignet.TCPAddr `mapstructure:",squash"`
+
+	// MaxBatchesPerSecond is the maximum number of batches per second streamed to each client,
+	// the others are skipped.
+	MaxBatchesPerSecond float64 `mapstructure:"max_batches_per_second"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.TCPAddr.Endpoint == "" {
+		return errors.New("\"endpoint\" is required when using the \"remotetap\" extension")
+	}
+	if cfg.MaxBatchesPerSecond <= 0 {
+		return errors.New("\"max_batches_per_second\" must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotetapextension // import "go.opentelemetry.io/collector/extension/remotetapextension"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/tap"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	pipelinesPath = "/pipelines"
+	tapPath       = "/tap"
+
+	// clientBufferSize is the number of batches buffered for a client that is slower than the data.
+	clientBufferSize = 1
+)
+
+type remoteTap struct {
+	config    *Config
+	telemetry component.TelemetrySettings
+	taps      *tap.Registry
+	server    http.Server
+	stopCh    chan struct{}
+
+	// shutdownCh is closed on shutdown, to disconnect the clients, as the server does not
+	// track the connections upgraded to WebSocket. The mutex guards the clients counter
+	// against connections accepted while shutting down.
+	mu         sync.Mutex
+	shutdownCh chan struct{}
+	clients    sync.WaitGroup
+
+	tracesMarshaler  ptrace.Marshaler
+	metricsMarshaler pmetric.Marshaler
+	logsMarshaler    plog.Marshaler
+}
+
+func newRemoteTap(config *Config, telemetry component.TelemetrySettings) *remoteTap {
+	return &remoteTap{
+		config:           config,
+		telemetry:        telemetry,
+		shutdownCh:       make(chan struct{}),
+		tracesMarshaler:  ptrace.NewJSONMarshaler(),
+		metricsMarshaler: pmetric.NewJSONMarshaler(),
+		logsMarshaler:    plog.NewJSONMarshaler(),
+	}
+}
+
+func (rt *remoteTap) Start(_ context.Context, host component.Host) error {
+	tapHost, ok := host.(tap.Host)
+	if !ok {
+		return errors.New("the host does not support tapping the pipelines")
+	}
+	rt.taps = tapHost.GetTaps()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(pipelinesPath, rt.handlePipelines)
+	mux.HandleFunc(tapPath, rt.handleTap)
+
+	// Start the listener here so we can have earlier failure if port is
+	// already in use.
+	ln, err := rt.config.TCPAddr.Listen()
+	if err != nil {
+		return err
+	}
+
+	rt.telemetry.Logger.Info("Starting remote tap extension", zap.Any("config", rt.config))
+	rt.server = http.Server{Handler: mux}
+	rt.stopCh = make(chan struct{})
+	go func() {
+		defer close(rt.stopCh)
+
+		if errHTTP := rt.server.Serve(ln); errHTTP != nil && !errors.Is(errHTTP, http.ErrServerClosed) {
+			host.ReportFatalError(errHTTP)
+		}
+	}()
+
+	return nil
+}
+
+func (rt *remoteTap) Shutdown(context.Context) error {
+	rt.mu.Lock()
+	close(rt.shutdownCh)
+	rt.mu.Unlock()
+	err := rt.server.Close()
+	if rt.stopCh != nil {
+		<-rt.stopCh
+	}
+	rt.clients.Wait()
+	return err
+}
+
+// handlePipelines returns the IDs of the pipelines that can be tapped.
+func (rt *remoteTap) handlePipelines(w http.ResponseWriter, _ *http.Request) {
+	ids := []string{}
+	for _, id := range rt.taps.Pipelines() {
+		ids = append(ids, id.String())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ids)
+}
+
+// handleTap upgrades the connection to WebSocket, and streams the batches flowing through the
+// pipeline in the "pipeline" query parameter as OTLP JSON text messages.
+func (rt *remoteTap) handleTap(w http.ResponseWriter, r *http.Request) {
+	pipelineID, err := config.NewComponentIDFromString(r.URL.Query().Get("pipeline"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid pipeline: %v", err), http.StatusBadRequest)
+		return
+	}
+	rt.mu.Lock()
+	select {
+	case <-rt.shutdownCh:
+		rt.mu.Unlock()
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+	rt.clients.Add(1)
+	rt.mu.Unlock()
+	defer rt.clients.Done()
+
+	sub, err := rt.taps.Subscribe(pipelineID, tap.SubscriptionSettings{
+		MaxRate:    rt.config.MaxBatchesPerSecond,
+		BufferSize: clientBufferSize,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer sub.Unsubscribe()
+
+	websocket.Server{
+		Handshake: checkOrigin,
+		Handler: func(conn *websocket.Conn) {
+			rt.stream(conn, sub)
+		},
+	}.ServeHTTP(w, r)
+}
+
+func (rt *remoteTap) stream(conn *websocket.Conn, sub *tap.Subscription) {
+	logger := rt.telemetry.Logger.With(zap.String("remote_addr", conn.Request().RemoteAddr))
+	logger.Info("Remote tap client connected", zap.String("pipeline", conn.Request().URL.Query().Get("pipeline")))
+	defer logger.Info("Remote tap client disconnected")
+
+	// The messages from the client are ignored, reading them detects the disconnection.
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+	defer func() {
+		_ = conn.Close()
+		<-disconnected
+	}()
+
+	for {
+		select {
+		case <-rt.shutdownCh:
+			return
+		case <-disconnected:
+			return
+		case data := <-sub.Data():
+			msg, err := rt.marshal(data)
+			if err != nil {
+				logger.Warn("Failed to marshal the tapped data", zap.Error(err))
+				continue
+			}
+			if err = websocket.Message.Send(conn, string(msg)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (rt *remoteTap) marshal(data tap.Data) ([]byte, error) {
+	switch data.Pipeline.Type() {
+	case config.TracesDataType:
+		return rt.tracesMarshaler.MarshalTraces(data.Traces)
+	case config.MetricsDataType:
+		return rt.metricsMarshaler.MarshalMetrics(data.Metrics)
+	case config.LogsDataType:
+		return rt.logsMarshaler.MarshalLogs(data.Logs)
+	}
+	return nil, fmt.Errorf("data type %q is not supported", data.Pipeline.Type())
+}
+
+// checkOrigin accepts the clients that do not send an Origin header, and the browsers that
+// loaded the page from the extension host, so that other web sites cannot read the data.
+func checkOrigin(cfg *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if u.Host != req.Host {
+		return fmt.Errorf("origin %q is not allowed", origin)
+	}
+	cfg.Origin = u
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotetapextension
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/tap"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/internal/testutil"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var tracesID = config.NewComponentIDWithName(config.TracesDataType, "debug")
+
+type tapHost struct {
+	component.Host
+	taps *tap.Registry
+}
+
+func (h tapHost) GetTaps() *tap.Registry {
+	return h.taps
+}
+
+func startRemoteTap(t *testing.T, taps *tap.Registry) (*remoteTap, string) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.TCPAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+	cfg.MaxBatchesPerSecond = 1000
+	rt := newRemoteTap(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, rt.Start(context.Background(), tapHost{Host: componenttest.NewNopHost(), taps: taps}))
+	return rt, cfg.TCPAddr.Endpoint
+}
+
+func TestRemoteTapRequiresTapHost(t *testing.T) {
+	rt := newRemoteTap(createDefaultConfig().(*Config), componenttest.NewNopTelemetrySettings())
+	assert.EqualError(t, rt.Start(context.Background(), componenttest.NewNopHost()), "the host does not support tapping the pipelines")
+}
+
+func TestRemoteTapPipelines(t *testing.T) {
+	rt, endpoint := startRemoteTap(t, tap.NewRegistry([]config.ComponentID{tracesID, config.NewComponentID(config.LogsDataType)}))
+	defer func() {
+		assert.NoError(t, rt.Shutdown(context.Background()))
+	}()
+
+	resp, err := http.Get("http://" + endpoint + pipelinesPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var ids []string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ids))
+	assert.Equal(t, []string{"logs", "traces/debug"}, ids)
+}
+
+func TestRemoteTapUnknownPipeline(t *testing.T) {
+	rt, endpoint := startRemoteTap(t, tap.NewRegistry([]config.ComponentID{tracesID}))
+	defer func() {
+		assert.NoError(t, rt.Shutdown(context.Background()))
+	}()
+
+	resp, err := http.Get("http://" + endpoint + tapPath + "?pipeline=traces/missing")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.NoError(t, resp.Body.Close())
+
+	_, err = websocket.Dial("ws://"+endpoint+tapPath+"?pipeline=traces/missing", "", "http://"+endpoint)
+	assert.Error(t, err)
+}
+
+func TestRemoteTapRejectsCrossOrigin(t *testing.T) {
+	rt, endpoint := startRemoteTap(t, tap.NewRegistry([]config.ComponentID{tracesID}))
+	defer func() {
+		assert.NoError(t, rt.Shutdown(context.Background()))
+	}()
+
+	_, err := websocket.Dial("ws://"+endpoint+tapPath+"?pipeline=traces/debug", "", "http://example.com")
+	assert.Error(t, err)
+}
+
+func TestRemoteTapStream(t *testing.T) {
+	taps := tap.NewRegistry([]config.ComponentID{tracesID})
+	rt, endpoint := startRemoteTap(t, taps)
+
+	conn, err := websocket.Dial("ws://"+endpoint+tapPath+"?pipeline=traces/debug", "", "http://"+endpoint)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool { return taps.Active(tracesID) }, 5*time.Second, 10*time.Millisecond)
+	td := testdata.GenerateTraces(2)
+	taps.PublishTraces(tracesID, td)
+
+	var msg string
+	require.NoError(t, websocket.Message.Receive(conn, &msg))
+	received, err := ptrace.NewJSONUnmarshaler().UnmarshalTraces([]byte(msg))
+	require.NoError(t, err)
+	assert.Equal(t, td, received)
+
+	// The clients are disconnected on shutdown.
+	assert.NoError(t, rt.Shutdown(context.Background()))
+	assert.False(t, taps.Active(tracesID))
+	assert.Error(t, websocket.Message.Receive(conn, &msg))
+}
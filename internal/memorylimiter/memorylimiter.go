@@ -0,0 +1,361 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memorylimiter implements the memory usage monitoring shared by the
+// memory_limiter processor and extension.
+package memorylimiter // import "go.opentelemetry.io/collector/internal/memorylimiter"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configbytes"
+	"go.opentelemetry.io/collector/extension/ballastextension"
+	"go.opentelemetry.io/collector/internal/iruntime"
+)
+
+const (
+	mibBytes = 1024 * 1024
+)
+
+var (
+	// ErrCheckIntervalOutOfRange is returned when the check interval is not positive.
+	ErrCheckIntervalOutOfRange = errors.New(
+		"checkInterval must be greater than zero")
+
+	// ErrLimitOutOfRange is returned when neither a fixed nor a percentage limit is configured.
+	ErrLimitOutOfRange = errors.New(
+		"memAllocLimit or memoryLimitPercentage must be greater than zero")
+
+	// ErrLimitConflict is returned when a limit is configured both in MiB and with a byte size.
+	ErrLimitConflict = errors.New(
+		"limit_mib and limit, or spike_limit_mib and spike_limit, cannot be both set")
+
+	// ErrMemSpikeLimitOutOfRange is returned when the spike limit is not smaller than the limit.
+	ErrMemSpikeLimitOutOfRange = errors.New(
+		"memSpikeLimit must be smaller than memAllocLimit")
+
+	// ErrPercentageLimitOutOfRange is returned when a percentage is not in (0, 100].
+	ErrPercentageLimitOutOfRange = errors.New(
+		"memoryLimitPercentage and memorySpikePercentage must be greater than zero and less than or equal to hundred",
+	)
+
+	// ErrShutdownNotStarted is returned by Shutdown if the monitoring is not running.
+	ErrShutdownNotStarted = errors.New("no existing monitoring routine is running")
+)
+
+// make it overridable by tests
+var getMemoryFn = iruntime.TotalMemory
+
+// Config defines the limits of a MemoryLimiter.
+type Config struct {
+	// CheckInterval is the time between measurements of memory usage.
+	CheckInterval time.Duration
+
+	// MemoryLimitMiB is the maximum amount of memory, in MiB, targeted to be
+	// allocated by the process.
+	MemoryLimitMiB uint32
+
+	// MemorySpikeLimitMiB is the maximum, in MiB, spike expected between the
+	// measurements of memory usage.
+	MemorySpikeLimitMiB uint32
+
+	// MemoryLimit is the maximum amount of memory targeted to be allocated by the
+	// process, as a byte size such as "4GiB". It is an alternative to MemoryLimitMiB.
+	MemoryLimit configbytes.ByteSize
+
+	// MemorySpikeLimit is the maximum spike expected between the measurements of
+	// memory usage, as a byte size. It is an alternative to MemorySpikeLimitMiB.
+	MemorySpikeLimit configbytes.ByteSize
+
+	// MemoryLimitPercentage is the maximum amount of memory, in %, targeted to be
+	// allocated by the process. The fixed memory settings MemoryLimitMiB has a higher precedence.
+	MemoryLimitPercentage uint32
+
+	// MemorySpikePercentage is the maximum, in percents against the total memory,
+	// spike expected between the measurements of memory usage.
+	MemorySpikePercentage uint32
+}
+
+// Validate checks that the limits of the Config are consistent.
+func (cfg Config) Validate() error {
+	if cfg.CheckInterval <= 0 {
+		return ErrCheckIntervalOutOfRange
+	}
+	if cfg.MemoryLimit < 0 || cfg.MemorySpikeLimit < 0 {
+		return ErrLimitOutOfRange
+	}
+	if (cfg.MemoryLimitMiB != 0 && cfg.MemoryLimit != 0) || (cfg.MemorySpikeLimitMiB != 0 && cfg.MemorySpikeLimit != 0) {
+		return ErrLimitConflict
+	}
+	if cfg.MemoryLimitMiB == 0 && cfg.MemoryLimit == 0 && cfg.MemoryLimitPercentage == 0 {
+		return ErrLimitOutOfRange
+	}
+	return nil
+}
+
+// fixedLimits returns the fixed limit and spike limit in bytes, zero when not set.
+func (cfg Config) fixedLimits() (uint64, uint64) {
+	memAllocLimit := uint64(cfg.MemoryLimitMiB) * mibBytes
+	if cfg.MemoryLimit != 0 {
+		memAllocLimit = uint64(cfg.MemoryLimit)
+	}
+	memSpikeLimit := uint64(cfg.MemorySpikeLimitMiB) * mibBytes
+	if cfg.MemorySpikeLimit != 0 {
+		memSpikeLimit = uint64(cfg.MemorySpikeLimit)
+	}
+	return memAllocLimit, memSpikeLimit
+}
+
+// MemoryLimiter periodically checks the memory usage of the process, forcing
+// GCs when needed, and decides whether incoming data must be refused.
+type MemoryLimiter struct {
+	usageChecker memUsageChecker
+
+	memCheckWait time.Duration
+	ballastSize  uint64
+
+	// mustRefuse is used atomically to indicate when data should be refused.
+	mustRefuse *atomic.Bool
+
+	ticker *time.Ticker
+
+	lastGCDone time.Time
+
+	// The function to read the mem values is set as a reference to help with
+	// testing different values.
+	readMemStatsFn func(m *runtime.MemStats)
+
+	// Fields used for logging.
+	logger                 *zap.Logger
+	configMismatchedLogged bool
+
+	refCounterLock sync.Mutex
+	refCounter     int
+}
+
+// Minimum interval between forced GC when in soft limited mode. We don't want to
+// do GCs too frequently since it is a CPU-heavy operation.
+const minGCIntervalWhenSoftLimited = 10 * time.Second
+
+// NewMemoryLimiter returns a new MemoryLimiter. The monitoring starts with Start.
+func NewMemoryLimiter(cfg Config, logger *zap.Logger) (*MemoryLimiter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	usageChecker, err := getMemUsageChecker(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Memory limiter configured",
+		zap.Uint64("limit_mib", usageChecker.memAllocLimit/mibBytes),
+		zap.Uint64("spike_limit_mib", usageChecker.memSpikeLimit/mibBytes),
+		zap.Duration("check_interval", cfg.CheckInterval))
+
+	return &MemoryLimiter{
+		usageChecker:   *usageChecker,
+		memCheckWait:   cfg.CheckInterval,
+		ticker:         time.NewTicker(cfg.CheckInterval),
+		readMemStatsFn: runtime.ReadMemStats,
+		logger:         logger,
+		mustRefuse:     atomic.NewBool(false),
+	}, nil
+}
+
+func getMemUsageChecker(cfg Config, logger *zap.Logger) (*memUsageChecker, error) {
+	memAllocLimit, memSpikeLimit := cfg.fixedLimits()
+	if memAllocLimit != 0 {
+		return newFixedMemUsageChecker(memAllocLimit, memSpikeLimit)
+	}
+	totalMemory, err := getMemoryFn()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total memory, use fixed memory settings (limit_mib or limit): %w", err)
+	}
+	logger.Info("Using percentage memory limiter",
+		zap.Uint64("total_memory_mib", totalMemory/mibBytes),
+		zap.Uint32("limit_percentage", cfg.MemoryLimitPercentage),
+		zap.Uint32("spike_limit_percentage", cfg.MemorySpikePercentage))
+	return newPercentageMemUsageChecker(totalMemory, uint64(cfg.MemoryLimitPercentage), uint64(cfg.MemorySpikePercentage))
+}
+
+// Start looks up the memory ballast, if any, and starts the monitoring. A
+// MemoryLimiter can be started several times, it keeps a single monitoring
+// routine until it is shut down the same number of times.
+func (ml *MemoryLimiter) Start(_ context.Context, host component.Host) error {
+	extensions := host.GetExtensions()
+	for _, extension := range extensions {
+		if ext, ok := extension.(*ballastextension.MemoryBallast); ok {
+			ml.ballastSize = ext.GetBallastSize()
+			break
+		}
+	}
+	ml.startMonitoring()
+	return nil
+}
+
+// Shutdown stops the monitoring once it has been called for every Start.
+func (ml *MemoryLimiter) Shutdown(context.Context) error {
+	ml.refCounterLock.Lock()
+	defer ml.refCounterLock.Unlock()
+
+	if ml.refCounter == 0 {
+		return ErrShutdownNotStarted
+	} else if ml.refCounter == 1 {
+		ml.ticker.Stop()
+	}
+	ml.refCounter--
+	return nil
+}
+
+// MustRefuse returns whether incoming data must be refused because the memory
+// usage is above the soft limit.
+func (ml *MemoryLimiter) MustRefuse() bool {
+	return ml.mustRefuse.Load()
+}
+
+// SetReadMemStatsFn replaces the function reading the memory values, to help with
+// testing different values from the packages using the MemoryLimiter.
+func (ml *MemoryLimiter) SetReadMemStatsFn(fn func(m *runtime.MemStats)) {
+	ml.readMemStatsFn = fn
+}
+
+func (ml *MemoryLimiter) readMemStats() *runtime.MemStats {
+	ms := &runtime.MemStats{}
+	ml.readMemStatsFn(ms)
+	// If proper configured ms.Alloc should be at least ml.ballastSize but since
+	// a misconfiguration is possible check for that here.
+	if ms.Alloc >= ml.ballastSize {
+		ms.Alloc -= ml.ballastSize
+	} else if !ml.configMismatchedLogged {
+		// This indicates misconfiguration. Log it once.
+		ml.configMismatchedLogged = true
+		ml.logger.Warn(`"size_mib" in ballast extension is likely incorrectly configured.`)
+	}
+
+	return ms
+}
+
+// startMonitoring starts a single ticker'd goroutine per instance
+// that will check memory usage every checkInterval period.
+func (ml *MemoryLimiter) startMonitoring() {
+	ml.refCounterLock.Lock()
+	defer ml.refCounterLock.Unlock()
+
+	ml.refCounter++
+	if ml.refCounter == 1 {
+		go func() {
+			for range ml.ticker.C {
+				ml.CheckMemLimits()
+			}
+		}()
+	}
+}
+
+func memstatToZapField(ms *runtime.MemStats) zap.Field {
+	return zap.Uint64("cur_mem_mib", ms.Alloc/mibBytes)
+}
+
+func (ml *MemoryLimiter) doGCandReadMemStats() *runtime.MemStats {
+	runtime.GC()
+	ml.lastGCDone = time.Now()
+	ms := ml.readMemStats()
+	ml.logger.Info("Memory usage after GC.", memstatToZapField(ms))
+	return ms
+}
+
+// CheckMemLimits reads the memory usage, forcing a GC if it is above the limits,
+// and updates the decision returned by MustRefuse. It is called every check
+// interval by the monitoring routine.
+func (ml *MemoryLimiter) CheckMemLimits() {
+	ms := ml.readMemStats()
+
+	ml.logger.Debug("Currently used memory.", memstatToZapField(ms))
+
+	if ml.usageChecker.aboveHardLimit(ms) {
+		ml.logger.Warn("Memory usage is above hard limit. Forcing a GC.", memstatToZapField(ms))
+		ms = ml.doGCandReadMemStats()
+	}
+
+	// Remember current refusing state.
+	wasRefusing := ml.mustRefuse.Load()
+
+	// Check if the memory usage is above the soft limit.
+	mustRefuse := ml.usageChecker.aboveSoftLimit(ms)
+
+	if wasRefusing && !mustRefuse {
+		// Was previously refusing but enough memory is available now, no need to limit.
+		ml.logger.Info("Memory usage back within limits. Resuming normal operation.", memstatToZapField(ms))
+	}
+
+	if !wasRefusing && mustRefuse {
+		// We are above soft limit, do a GC if it wasn't done recently and see if
+		// it brings memory usage below the soft limit.
+		if time.Since(ml.lastGCDone) > minGCIntervalWhenSoftLimited {
+			ml.logger.Info("Memory usage is above soft limit. Forcing a GC.", memstatToZapField(ms))
+			ms = ml.doGCandReadMemStats()
+			// Check the limit again to see if GC helped.
+			mustRefuse = ml.usageChecker.aboveSoftLimit(ms)
+		}
+
+		if mustRefuse {
+			ml.logger.Warn("Memory usage is above soft limit. Refusing data.", memstatToZapField(ms))
+		}
+	}
+
+	ml.mustRefuse.Store(mustRefuse)
+}
+
+type memUsageChecker struct {
+	memAllocLimit uint64
+	memSpikeLimit uint64
+}
+
+func (d memUsageChecker) aboveSoftLimit(ms *runtime.MemStats) bool {
+	return ms.Alloc >= d.memAllocLimit-d.memSpikeLimit
+}
+
+func (d memUsageChecker) aboveHardLimit(ms *runtime.MemStats) bool {
+	return ms.Alloc >= d.memAllocLimit
+}
+
+func newFixedMemUsageChecker(memAllocLimit, memSpikeLimit uint64) (*memUsageChecker, error) {
+	if memSpikeLimit >= memAllocLimit {
+		return nil, ErrMemSpikeLimitOutOfRange
+	}
+	if memSpikeLimit == 0 {
+		// If spike limit is unspecified use 20% of mem limit.
+		memSpikeLimit = memAllocLimit / 5
+	}
+	return &memUsageChecker{
+		memAllocLimit: memAllocLimit,
+		memSpikeLimit: memSpikeLimit,
+	}, nil
+}
+
+func newPercentageMemUsageChecker(totalMemory uint64, percentageLimit, percentageSpike uint64) (*memUsageChecker, error) {
+	if percentageLimit > 100 || percentageLimit <= 0 || percentageSpike > 100 || percentageSpike <= 0 {
+		return nil, ErrPercentageLimitOutOfRange
+	}
+	return newFixedMemUsageChecker(percentageLimit*totalMemory/100, percentageSpike*totalMemory/100)
+}
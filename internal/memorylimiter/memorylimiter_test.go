@@ -0,0 +1,298 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorylimiter
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configbytes"
+	"go.opentelemetry.io/collector/extension/ballastextension"
+	"go.opentelemetry.io/collector/internal/iruntime"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+	}{
+		{
+			name:    "zero_checkInterval",
+			wantErr: ErrCheckIntervalOutOfRange,
+		},
+		{
+			name:    "zero_memAllocLimit",
+			cfg:     Config{CheckInterval: 100 * time.Millisecond},
+			wantErr: ErrLimitOutOfRange,
+		},
+		{
+			name:    "memSpikeLimit_gt_memAllocLimit",
+			cfg:     Config{CheckInterval: 100 * time.Millisecond, MemoryLimitMiB: 1, MemorySpikeLimitMiB: 2},
+			wantErr: ErrMemSpikeLimitOutOfRange,
+		},
+		{
+			name:    "limit_conflict",
+			cfg:     Config{CheckInterval: 100 * time.Millisecond, MemoryLimitMiB: 1024, MemoryLimit: configbytes.Gibibyte},
+			wantErr: ErrLimitConflict,
+		},
+		{
+			name:    "spike_limit_conflict",
+			cfg:     Config{CheckInterval: 100 * time.Millisecond, MemoryLimit: configbytes.Gibibyte, MemorySpikeLimitMiB: 1, MemorySpikeLimit: configbytes.Mebibyte},
+			wantErr: ErrLimitConflict,
+		},
+		{
+			name:    "negative_memAllocLimit",
+			cfg:     Config{CheckInterval: 100 * time.Millisecond, MemoryLimit: -1},
+			wantErr: ErrLimitOutOfRange,
+		},
+		{
+			name:    "memSpikeLimit_gt_memAllocLimit_bytes",
+			cfg:     Config{CheckInterval: 100 * time.Millisecond, MemoryLimit: configbytes.Mebibyte, MemorySpikeLimit: 2 * configbytes.Mebibyte},
+			wantErr: ErrMemSpikeLimitOutOfRange,
+		},
+		{
+			name: "success",
+			cfg:  Config{CheckInterval: 100 * time.Millisecond, MemoryLimitMiB: 1024},
+		},
+		{
+			name: "success_bytes",
+			cfg:  Config{CheckInterval: 100 * time.Millisecond, MemoryLimit: configbytes.Gibibyte, MemorySpikeLimit: 200 * configbytes.Mebibyte},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMemoryLimiter(tt.cfg, zap.NewNop())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, got.Start(context.Background(), componenttest.NewNopHost()))
+			assert.NoError(t, got.Start(context.Background(), componenttest.NewNopHost()))
+			assert.NoError(t, got.Shutdown(context.Background()))
+			assert.NoError(t, got.Shutdown(context.Background()))
+			assert.ErrorIs(t, got.Shutdown(context.Background()), ErrShutdownNotStarted)
+		})
+	}
+}
+
+func TestFixedLimits(t *testing.T) {
+	alloc, spike := Config{MemoryLimitMiB: 1024, MemorySpikeLimitMiB: 200}.fixedLimits()
+	assert.Equal(t, uint64(1024*mibBytes), alloc)
+	assert.Equal(t, uint64(200*mibBytes), spike)
+
+	alloc, spike = Config{MemoryLimit: 1500 * configbytes.Megabyte, MemorySpikeLimit: 300 * configbytes.Megabyte}.fixedLimits()
+	assert.Equal(t, uint64(1500*configbytes.Megabyte), alloc)
+	assert.Equal(t, uint64(300*configbytes.Megabyte), spike)
+}
+
+// TestMemoryPressureResponse manipulates results from querying memory and
+// check expected side effects.
+func TestMemoryPressureResponse(t *testing.T) {
+	var currentMemAlloc uint64
+	ml := &MemoryLimiter{
+		usageChecker: memUsageChecker{
+			memAllocLimit: 1024,
+		},
+		mustRefuse: atomic.NewBool(false),
+		readMemStatsFn: func(ms *runtime.MemStats) {
+			ms.Alloc = currentMemAlloc
+		},
+		logger: zap.NewNop(),
+	}
+
+	// Below memAllocLimit.
+	currentMemAlloc = 800
+	ml.CheckMemLimits()
+	assert.False(t, ml.MustRefuse())
+
+	// Above memAllocLimit.
+	currentMemAlloc = 1800
+	ml.CheckMemLimits()
+	assert.True(t, ml.MustRefuse())
+
+	// Check ballast effect
+	ml.ballastSize = 1000
+
+	// Below memAllocLimit accounting for ballast.
+	currentMemAlloc = 800 + ml.ballastSize
+	ml.CheckMemLimits()
+	assert.False(t, ml.MustRefuse())
+
+	// Above memAllocLimit even accountiing for ballast.
+	currentMemAlloc = 1800 + ml.ballastSize
+	ml.CheckMemLimits()
+	assert.True(t, ml.MustRefuse())
+
+	// Restore ballast to default.
+	ml.ballastSize = 0
+
+	// Check spike limit
+	ml.usageChecker.memSpikeLimit = 512
+
+	// Below memSpikeLimit.
+	currentMemAlloc = 500
+	ml.CheckMemLimits()
+	assert.False(t, ml.MustRefuse())
+
+	// Above memSpikeLimit.
+	currentMemAlloc = 550
+	ml.CheckMemLimits()
+	assert.True(t, ml.MustRefuse())
+}
+
+func TestGetDecision(t *testing.T) {
+	t.Run("fixed_limit", func(t *testing.T) {
+		d, err := getMemUsageChecker(Config{MemoryLimitMiB: 100, MemorySpikeLimitMiB: 20}, zap.NewNop())
+		require.NoError(t, err)
+		assert.Equal(t, &memUsageChecker{
+			memAllocLimit: 100 * mibBytes,
+			memSpikeLimit: 20 * mibBytes,
+		}, d)
+	})
+	t.Run("fixed_limit_error", func(t *testing.T) {
+		d, err := getMemUsageChecker(Config{MemoryLimitMiB: 20, MemorySpikeLimitMiB: 100}, zap.NewNop())
+		require.Error(t, err)
+		assert.Nil(t, d)
+	})
+
+	t.Cleanup(func() {
+		getMemoryFn = iruntime.TotalMemory
+	})
+	getMemoryFn = func() (uint64, error) {
+		return 100 * mibBytes, nil
+	}
+	t.Run("percentage_limit", func(t *testing.T) {
+		d, err := getMemUsageChecker(Config{MemoryLimitPercentage: 50, MemorySpikePercentage: 10}, zap.NewNop())
+		require.NoError(t, err)
+		assert.Equal(t, &memUsageChecker{
+			memAllocLimit: 50 * mibBytes,
+			memSpikeLimit: 10 * mibBytes,
+		}, d)
+	})
+	t.Run("percentage_limit_error", func(t *testing.T) {
+		d, err := getMemUsageChecker(Config{MemoryLimitPercentage: 101, MemorySpikePercentage: 10}, zap.NewNop())
+		require.Error(t, err)
+		assert.Nil(t, d)
+		d, err = getMemUsageChecker(Config{MemoryLimitPercentage: 99, MemorySpikePercentage: 101}, zap.NewNop())
+		require.Error(t, err)
+		assert.Nil(t, d)
+	})
+}
+
+func TestDropDecision(t *testing.T) {
+	decison1000Limit30Spike30, err := newPercentageMemUsageChecker(1000, 60, 30)
+	require.NoError(t, err)
+	decison1000Limit60Spike50, err := newPercentageMemUsageChecker(1000, 60, 50)
+	require.NoError(t, err)
+	decison1000Limit40Spike20, err := newPercentageMemUsageChecker(1000, 40, 20)
+	require.NoError(t, err)
+	decison1000Limit40Spike60, err := newPercentageMemUsageChecker(1000, 40, 60)
+	require.Error(t, err)
+	assert.Nil(t, decison1000Limit40Spike60)
+
+	tests := []struct {
+		name         string
+		usageChecker memUsageChecker
+		ms           *runtime.MemStats
+		shouldDrop   bool
+	}{
+		{
+			name:         "should drop over limit",
+			usageChecker: *decison1000Limit30Spike30,
+			ms:           &runtime.MemStats{Alloc: 600},
+			shouldDrop:   true,
+		},
+		{
+			name:         "should not drop",
+			usageChecker: *decison1000Limit30Spike30,
+			ms:           &runtime.MemStats{Alloc: 100},
+			shouldDrop:   false,
+		},
+		{
+			name: "should not drop spike, fixed usageChecker",
+			usageChecker: memUsageChecker{
+				memAllocLimit: 600,
+				memSpikeLimit: 500,
+			},
+			ms:         &runtime.MemStats{Alloc: 300},
+			shouldDrop: true,
+		},
+		{
+			name:         "should drop, spike, percentage usageChecker",
+			usageChecker: *decison1000Limit60Spike50,
+			ms:           &runtime.MemStats{Alloc: 300},
+			shouldDrop:   true,
+		},
+		{
+			name:         "should drop, spike, percentage usageChecker",
+			usageChecker: *decison1000Limit40Spike20,
+			ms:           &runtime.MemStats{Alloc: 250},
+			shouldDrop:   true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			shouldDrop := test.usageChecker.aboveSoftLimit(test.ms)
+			assert.Equal(t, test.shouldDrop, shouldDrop)
+		})
+	}
+}
+
+func TestBallastSizeMiB(t *testing.T) {
+	ctx := context.Background()
+	ballastExtFactory := ballastextension.NewFactory()
+	ballastExtCfg := ballastExtFactory.CreateDefaultConfig().(*ballastextension.Config)
+	ballastExtCfg.SizeMiB = 100
+	extCreateSet := componenttest.NewNopExtensionCreateSettings()
+
+	tests := []struct {
+		name                          string
+		ballastExtBallastSizeSetting  uint64
+		expectedMemLimiterBallastSize uint64
+		expectResult                  bool
+	}{
+		{
+			name:                          "ballast size matched",
+			ballastExtBallastSizeSetting:  100,
+			expectedMemLimiterBallastSize: 100,
+			expectResult:                  true,
+		},
+		{
+			name:                          "ballast size not matched",
+			ballastExtBallastSizeSetting:  1000,
+			expectedMemLimiterBallastSize: 100,
+			expectResult:                  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ballastExtCfg.SizeMiB = tt.ballastExtBallastSizeSetting
+			ballastExt, _ := ballastExtFactory.CreateExtension(ctx, extCreateSet, ballastExtCfg)
+			require.NoError(t, ballastExt.Start(ctx, nil))
+			assert.Equal(t, tt.expectResult, tt.expectedMemLimiterBallastSize*mibBytes == ballastExt.(*ballastextension.MemoryBallast).GetBallastSize())
+		})
+	}
+}
@@ -37,6 +37,9 @@ const (
 	SentLogRecordsKey = "sent_log_records"
 	// FailedToSendLogRecordsKey used to track logs that failed to be sent by exporters.
 	FailedToSendLogRecordsKey = "send_failed_log_records"
+
+	// SendLatencyKey used to track the duration of the export operations.
+	SendLatencyKey = "send_latency"
 )
 
 var (
@@ -76,4 +79,8 @@ var (
 		ExporterPrefix+FailedToSendLogRecordsKey,
 		"Number of log records in failed attempts to send to destination.",
 		stats.UnitDimensionless)
+	ExporterSendLatency = stats.Float64(
+		ExporterPrefix+SendLatencyKey,
+		"Duration of the attempts to send data to destination.",
+		stats.UnitMilliseconds)
 )
@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package obsmetrics // import "go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+const (
+	// PipelineKey used to identify pipelines in metrics and traces.
+	PipelineKey = "pipeline"
+	// StageKey used to identify the stage of a pipeline the data was dropped at.
+	StageKey = "stage"
+
+	// StageProcessors identifies the processors of a pipeline.
+	StageProcessors = "processors"
+	// StageExporters identifies the exporters of a pipeline.
+	StageExporters = "exporters"
+
+	// IncomingItemsKey used to track the items received by pipelines.
+	IncomingItemsKey = "incoming_items"
+	// OutgoingItemsKey used to track the items accepted by the exporters of pipelines.
+	OutgoingItemsKey = "outgoing_items"
+	// IncomingBytesKey used to track the size of the data received by pipelines.
+	IncomingBytesKey = "incoming_bytes"
+	// OutgoingBytesKey used to track the size of the data accepted by the exporters of pipelines.
+	OutgoingBytesKey = "outgoing_bytes"
+	// DroppedItemsKey used to track the items refused by a stage of pipelines.
+	DroppedItemsKey = "dropped_items"
+	// LatencyKey used to track the time the data takes to flow through pipelines.
+	LatencyKey = "latency"
+)
+
+var (
+	TagKeyPipeline, _ = tag.NewKey(PipelineKey)
+	TagKeyStage, _    = tag.NewKey(StageKey)
+
+	PipelinePrefix = PipelineKey + NameSep
+
+	// Pipeline metrics. The items are the spans, metric points or log records,
+	// depending on the data type of the pipeline, and the bytes are the size of
+	// the data in the OTLP protobuf encoding.
+	PipelineIncomingItems = stats.Int64(
+		PipelinePrefix+IncomingItemsKey,
+		"Number of items received by the pipeline from its receivers.",
+		stats.UnitDimensionless)
+	PipelineOutgoingItems = stats.Int64(
+		PipelinePrefix+OutgoingItemsKey,
+		"Number of items successfully pushed into the exporters of the pipeline.",
+		stats.UnitDimensionless)
+	PipelineIncomingBytes = stats.Int64(
+		PipelinePrefix+IncomingBytesKey,
+		"Size of the items received by the pipeline from its receivers.",
+		stats.UnitBytes)
+	PipelineOutgoingBytes = stats.Int64(
+		PipelinePrefix+OutgoingBytesKey,
+		"Size of the items successfully pushed into the exporters of the pipeline.",
+		stats.UnitBytes)
+	PipelineDroppedItems = stats.Int64(
+		PipelinePrefix+DroppedItemsKey,
+		"Number of items refused by a stage of the pipeline.",
+		stats.UnitDimensionless)
+	PipelineLatency = stats.Float64(
+		PipelinePrefix+LatencyKey,
+		"Time from the reception of the items by the pipeline to their acceptance by its exporters.",
+		stats.UnitMilliseconds)
+)
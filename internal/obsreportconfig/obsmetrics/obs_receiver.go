@@ -43,6 +43,9 @@ const (
 	// RefusedLogRecordsKey used to identify log records refused (ie.: not ingested) by the
 	// Collector.
 	RefusedLogRecordsKey = "refused_log_records"
+
+	// ReceiveLatencyKey used to track the duration of the receive operations.
+	ReceiveLatencyKey = "receive_latency"
 )
 
 var (
@@ -83,4 +86,8 @@ var (
 		ReceiverPrefix+RefusedLogRecordsKey,
 		"Number of log records that could not be pushed into the pipeline.",
 		stats.UnitDimensionless)
+	ReceiverReceiveLatency = stats.Float64(
+		ReceiverPrefix+ReceiveLatencyKey,
+		"Duration of the operations pushing the received data into the pipeline.",
+		stats.UnitMilliseconds)
 )
@@ -37,9 +37,55 @@ func Configure(level configtelemetry.Level) *ObsMetrics {
 	}
 
 	ret.Views = allViews()
+	if level >= configtelemetry.LevelDetailed {
+		ret.Views = append(ret.Views, detailedViews()...)
+	}
 	return ret
 }
 
+// detailedViews return the list of views that are only configured with the detailed level.
+// The latency distributions keep the exemplars attached to the receiver and exporter measurements,
+// which the sum views drop.
+func detailedViews() []*view.View {
+	return []*view.View{
+		{
+			Name:        obsmetrics.PipelineLatency.Name(),
+			Description: obsmetrics.PipelineLatency.Description(),
+			TagKeys:     []tag.Key{obsmetrics.TagKeyPipeline},
+			Measure:     obsmetrics.PipelineLatency,
+			Aggregation: view.Distribution(0, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000),
+		},
+		{
+			Name:        obsmetrics.PipelineIncomingBytes.Name(),
+			Description: obsmetrics.PipelineIncomingBytes.Description(),
+			TagKeys:     []tag.Key{obsmetrics.TagKeyPipeline},
+			Measure:     obsmetrics.PipelineIncomingBytes,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        obsmetrics.PipelineOutgoingBytes.Name(),
+			Description: obsmetrics.PipelineOutgoingBytes.Description(),
+			TagKeys:     []tag.Key{obsmetrics.TagKeyPipeline},
+			Measure:     obsmetrics.PipelineOutgoingBytes,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        obsmetrics.ExporterSendLatency.Name(),
+			Description: obsmetrics.ExporterSendLatency.Description(),
+			TagKeys:     []tag.Key{obsmetrics.TagKeyExporter},
+			Measure:     obsmetrics.ExporterSendLatency,
+			Aggregation: view.Distribution(0, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000),
+		},
+		{
+			Name:        obsmetrics.ReceiverReceiveLatency.Name(),
+			Description: obsmetrics.ReceiverReceiveLatency.Description(),
+			TagKeys:     []tag.Key{obsmetrics.TagKeyReceiver, obsmetrics.TagKeyTransport},
+			Measure:     obsmetrics.ReceiverReceiveLatency,
+			Aggregation: view.Distribution(0, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000),
+		},
+	}
+}
+
 // allViews return the list of all views that needs to be configured.
 func allViews() []*view.View {
 	var views []*view.View
@@ -100,6 +146,21 @@ func allViews() []*view.View {
 	tagKeys = []tag.Key{obsmetrics.TagKeyProcessor}
 	views = append(views, genViews(measures, tagKeys, view.Sum())...)
 
+	// Pipeline views.
+	measures = []*stats.Int64Measure{
+		obsmetrics.PipelineIncomingItems,
+		obsmetrics.PipelineOutgoingItems,
+	}
+	tagKeys = []tag.Key{obsmetrics.TagKeyPipeline}
+	views = append(views, genViews(measures, tagKeys, view.Sum())...)
+	views = append(views, &view.View{
+		Name:        obsmetrics.PipelineDroppedItems.Name(),
+		Description: obsmetrics.PipelineDroppedItems.Description(),
+		TagKeys:     []tag.Key{obsmetrics.TagKeyPipeline, obsmetrics.TagKeyStage},
+		Measure:     obsmetrics.PipelineDroppedItems,
+		Aggregation: view.Sum(),
+	})
+
 	return views
 }
 
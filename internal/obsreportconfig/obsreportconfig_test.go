@@ -25,9 +25,10 @@ import (
 
 func TestConfigure(t *testing.T) {
 	tests := []struct {
-		name      string
-		level     configtelemetry.Level
-		wantViews []*view.View
+		name         string
+		level        configtelemetry.Level
+		wantViews    []*view.View
+		wantDetailed bool
 	}{
 		{
 			name:  "none",
@@ -44,15 +45,29 @@ func TestConfigure(t *testing.T) {
 			wantViews: allViews(),
 		},
 		{
-			name:      "detailed",
-			level:     configtelemetry.LevelDetailed,
-			wantViews: allViews(),
+			name:         "detailed",
+			level:        configtelemetry.LevelDetailed,
+			wantViews:    allViews(),
+			wantDetailed: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			gotViews := Configure(tt.level)
-			assert.Equal(t, tt.wantViews, gotViews.Views)
+			if !tt.wantDetailed {
+				assert.Equal(t, tt.wantViews, gotViews.Views)
+				return
+			}
+			assert.Equal(t, tt.wantViews, gotViews.Views[:len(tt.wantViews)])
+			// Distribution aggregations can't be compared, compare the names of the detailed views.
+			var gotDetailed, wantDetailed []string
+			for _, v := range gotViews.Views[len(tt.wantViews):] {
+				gotDetailed = append(gotDetailed, v.Name)
+			}
+			for _, v := range detailedViews() {
+				wantDetailed = append(wantDetailed, v.Name)
+			}
+			assert.Equal(t, wantDetailed, gotDetailed)
 		})
 	}
 }
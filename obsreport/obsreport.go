@@ -15,6 +15,11 @@
 package obsreport // import "go.opentelemetry.io/collector/obsreport"
 
 import (
+	"context"
+	"time"
+
+	"go.opencensus.io/metric/metricdata"
+	octrace "go.opencensus.io/trace"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -24,3 +29,38 @@ func recordError(span trace.Span, err error) {
 		span.SetStatus(codes.Error, err.Error())
 	}
 }
+
+// startTimeKey is the context key of the start time of a receive or export operation.
+type startTimeKey struct{}
+
+// withStartTime returns a copy of the context holding the start time of the operation.
+func withStartTime(ctx context.Context) context.Context {
+	return context.WithValue(ctx, startTimeKey{}, time.Now())
+}
+
+// elapsedMillis returns the milliseconds elapsed since the start time of the operation held by
+// the context, if any.
+func elapsedMillis(ctx context.Context) (float64, bool) {
+	start, ok := ctx.Value(startTimeKey{}).(time.Time)
+	if !ok {
+		return 0, false
+	}
+	return float64(time.Since(start)) / float64(time.Millisecond), true
+}
+
+// exemplarAttachments returns the attachments referencing the sampled span of the context, if any.
+// They are kept as exemplars by the latency distribution views, linking the metrics to the internal
+// traces, and dropped by the sum views.
+func exemplarAttachments(ctx context.Context) metricdata.Attachments {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsSampled() {
+		return nil
+	}
+	return metricdata.Attachments{
+		metricdata.AttachmentKeySpanContext: octrace.SpanContext{
+			TraceID:      octrace.TraceID(sc.TraceID()),
+			SpanID:       octrace.SpanID(sc.SpanID()),
+			TraceOptions: octrace.TraceOptions(sc.TraceFlags()),
+		},
+	}
+}
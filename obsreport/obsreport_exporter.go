@@ -102,19 +102,25 @@ func (exp *Exporter) EndLogsOp(ctx context.Context, numLogRecords int, err error
 func (exp *Exporter) startOp(ctx context.Context, operationSuffix string) context.Context {
 	spanName := exp.spanNamePrefix + operationSuffix
 	ctx, _ = exp.tracer.Start(ctx, spanName)
-	return ctx
+	return withStartTime(ctx)
 }
 
 func (exp *Exporter) recordMetrics(ctx context.Context, numSent, numFailedToSend int64, sentMeasure, failedToSendMeasure *stats.Int64Measure) {
 	if exp.level == configtelemetry.LevelNone {
 		return
 	}
-	// Ignore the error for now. This should not happen.
+	measurements := []stats.Measurement{sentMeasure.M(numSent)}
 	if numFailedToSend > 0 {
-		_ = stats.RecordWithTags(ctx, exp.mutators, sentMeasure.M(numSent), failedToSendMeasure.M(numFailedToSend))
-	} else {
-		_ = stats.RecordWithTags(ctx, exp.mutators, sentMeasure.M(numSent))
+		measurements = append(measurements, failedToSendMeasure.M(numFailedToSend))
+	}
+	if latency, ok := elapsedMillis(ctx); ok {
+		measurements = append(measurements, obsmetrics.ExporterSendLatency.M(latency))
 	}
+	// Ignore the error for now. This should not happen.
+	_ = stats.RecordWithOptions(ctx,
+		stats.WithTags(exp.mutators...),
+		stats.WithMeasurements(measurements...),
+		stats.WithAttachments(exemplarAttachments(ctx)))
 }
 
 func endSpan(ctx context.Context, err error, numSent, numFailedToSend int64, sentItemsKey, failedToSendItemsKey string) {
@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package obsreport // import "go.opentelemetry.io/collector/obsreport"
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.uber.org/atomic"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
+)
+
+// Pipeline is a helper to add observability to a pipeline. The service sets it on every
+// pipeline, so that the data flowing through them is reported with the same metrics and
+// attributes, whatever their components.
+type Pipeline struct {
+	level              configtelemetry.Level
+	mutators           []tag.Mutator
+	processorsMutators []tag.Mutator
+	exportersMutators  []tag.Mutator
+}
+
+// PipelineSettings are settings for creating a Pipeline.
+type PipelineSettings struct {
+	Level      configtelemetry.Level
+	PipelineID config.ComponentID
+}
+
+// NewPipeline creates a new Pipeline.
+func NewPipeline(cfg PipelineSettings) *Pipeline {
+	pipeline := tag.Upsert(obsmetrics.TagKeyPipeline, cfg.PipelineID.String(), tag.WithTTL(tag.TTLNoPropagation))
+	return &Pipeline{
+		level:    cfg.Level,
+		mutators: []tag.Mutator{pipeline},
+		processorsMutators: []tag.Mutator{pipeline,
+			tag.Upsert(obsmetrics.TagKeyStage, obsmetrics.StageProcessors, tag.WithTTL(tag.TTLNoPropagation))},
+		exportersMutators: []tag.Mutator{pipeline,
+			tag.Upsert(obsmetrics.TagKeyStage, obsmetrics.StageExporters, tag.WithTTL(tag.TTLNoPropagation))},
+	}
+}
+
+// BytesEnabled returns whether the size of the data is reported. If not, the callers do not
+// need to compute it, and pass zero instead.
+func (p *Pipeline) BytesEnabled() bool {
+	return p.level >= configtelemetry.LevelDetailed
+}
+
+// pipelineOpKey is the context key of the pipelineOp of a consume operation.
+type pipelineOpKey struct{}
+
+// pipelineOp is the state of a consume operation shared by the entry and the exporters of a pipeline.
+type pipelineOp struct {
+	start        time.Time
+	exportFailed *atomic.Bool
+}
+
+// StartConsume is called when the pipeline receives data from its receivers, with the number of
+// items and their size. The returned context must be passed to the first consumer of the pipeline.
+func (p *Pipeline) StartConsume(ctx context.Context, numItems, numBytes int) context.Context {
+	if p.level == configtelemetry.LevelNone {
+		return ctx
+	}
+	measurements := []stats.Measurement{obsmetrics.PipelineIncomingItems.M(int64(numItems))}
+	if p.BytesEnabled() {
+		measurements = append(measurements, obsmetrics.PipelineIncomingBytes.M(int64(numBytes)))
+	}
+	p.record(ctx, p.mutators, measurements...)
+	return context.WithValue(ctx, pipelineOpKey{}, pipelineOp{start: time.Now(), exportFailed: atomic.NewBool(false)})
+}
+
+// EndConsume completes the consume operation started with StartConsume, with the error returned
+// by the first consumer of the pipeline. The items refused are reported as dropped by the processors,
+// unless the exporters refused them, as reported to EndExport.
+func (p *Pipeline) EndConsume(ctx context.Context, numItems int, err error) {
+	if p.level == configtelemetry.LevelNone || err == nil {
+		return
+	}
+	if op, ok := ctx.Value(pipelineOpKey{}).(pipelineOp); ok && op.exportFailed.Load() {
+		return
+	}
+	p.record(ctx, p.processorsMutators, obsmetrics.PipelineDroppedItems.M(int64(numItems)))
+}
+
+// EndExport is called when the exporters of the pipeline return, with the number of items pushed
+// into them, their size and the error returned. The latency is only reported if the context carries
+// the start of the consume operation, which is not the case when a processor does not pass the
+// context of the received data to the next consumer, e.g. because it batches the data.
+func (p *Pipeline) EndExport(ctx context.Context, numItems, numBytes int, err error) {
+	if p.level == configtelemetry.LevelNone {
+		return
+	}
+	op, hasOp := ctx.Value(pipelineOpKey{}).(pipelineOp)
+	if err != nil {
+		if hasOp {
+			op.exportFailed.Store(true)
+		}
+		p.record(ctx, p.exportersMutators, obsmetrics.PipelineDroppedItems.M(int64(numItems)))
+		return
+	}
+	measurements := []stats.Measurement{obsmetrics.PipelineOutgoingItems.M(int64(numItems))}
+	// The sizes and the latency are only reported with the detailed level.
+	if p.level >= configtelemetry.LevelDetailed {
+		measurements = append(measurements, obsmetrics.PipelineOutgoingBytes.M(int64(numBytes)))
+		if hasOp {
+			measurements = append(measurements, obsmetrics.PipelineLatency.M(float64(time.Since(op.start))/float64(time.Millisecond)))
+		}
+	}
+	p.record(ctx, p.mutators, measurements...)
+}
+
+func (p *Pipeline) record(ctx context.Context, mutators []tag.Mutator, measurements ...stats.Measurement) {
+	// Ignore the error for now. This should not happen.
+	_ = stats.RecordWithOptions(ctx,
+		stats.WithTags(mutators...),
+		stats.WithMeasurements(measurements...),
+		stats.WithAttachments(exemplarAttachments(ctx)))
+}
@@ -142,7 +142,7 @@ func (rec *Receiver) startOp(receiverCtx context.Context, operationSuffix string
 	if rec.transport != "" {
 		span.SetAttributes(attribute.String(obsmetrics.TransportKey, rec.transport))
 	}
-	return ctx
+	return withStartTime(ctx)
 }
 
 // endOp records the observability signals at the end of an operation.
@@ -176,10 +176,14 @@ func (rec *Receiver) endOp(
 			refusedMeasure = obsmetrics.ReceiverRefusedLogRecords
 		}
 
-		stats.Record(
+		measurements := []stats.Measurement{acceptedMeasure.M(int64(numAccepted)), refusedMeasure.M(int64(numRefused))}
+		if latency, ok := elapsedMillis(receiverCtx); ok {
+			measurements = append(measurements, obsmetrics.ReceiverReceiveLatency.M(latency))
+		}
+		_ = stats.RecordWithOptions(
 			receiverCtx,
-			acceptedMeasure.M(int64(numAccepted)),
-			refusedMeasure.M(int64(numRefused)))
+			stats.WithMeasurements(measurements...),
+			stats.WithAttachments(exemplarAttachments(receiverCtx)))
 	}
 
 	// end span according to errors
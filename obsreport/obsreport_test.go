@@ -21,10 +21,16 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/stats/view"
+	octrace "go.opencensus.io/trace"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/internal/obsreportconfig"
 	"go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
 	"go.opentelemetry.io/collector/obsreport/obsreporttest"
 	"go.opentelemetry.io/collector/receiver/scrapererror"
@@ -40,6 +46,7 @@ var (
 	scraper   = config.NewComponentID("fakeScraper")
 	processor = config.NewComponentID("fakeProcessor")
 	exporter  = config.NewComponentID("fakeExporter")
+	pipeline  = config.NewComponentID(config.TracesDataType)
 
 	errFake        = errors.New("errFake")
 	partialErrFake = scrapererror.NewPartialScrapeError(errFake, 1)
@@ -302,6 +309,100 @@ func TestExportTraceDataOp(t *testing.T) {
 	require.NoError(t, obsreporttest.CheckExporterTraces(tt, exporter, int64(sentSpans), int64(failedToSendSpans)))
 }
 
+func TestExportOpExemplars(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+	latencyView := registerDetailedView(t, "exporter/send_latency")
+
+	parentCtx, parentSpan := tt.TracerProvider.Tracer("test").Start(context.Background(), t.Name())
+	defer parentSpan.End()
+
+	obsrep := NewExporter(ExporterSettings{
+		ExporterID:             exporter,
+		ExporterCreateSettings: tt.ToExporterCreateSettings(),
+	})
+	ctx := obsrep.StartTracesOp(parentCtx)
+	obsrep.EndTracesOp(ctx, 7, nil)
+
+	spans := tt.SpanRecorder.Ended()
+	require.Len(t, spans, 1)
+	assertSpanExemplar(t, latencyView, spans[0].SpanContext())
+}
+
+func TestReceiveOpExemplars(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+	latencyView := registerDetailedView(t, "receiver/receive_latency")
+
+	parentCtx, parentSpan := tt.TracerProvider.Tracer("test").Start(context.Background(), t.Name())
+	defer parentSpan.End()
+
+	rec := NewReceiver(ReceiverSettings{
+		ReceiverID:             receiver,
+		Transport:              transport,
+		ReceiverCreateSettings: tt.ToReceiverCreateSettings(),
+	})
+	ctx := rec.StartLogsOp(parentCtx)
+	rec.EndLogsOp(ctx, format, 7, nil)
+
+	spans := tt.SpanRecorder.Ended()
+	require.Len(t, spans, 1)
+	assertSpanExemplar(t, latencyView, spans[0].SpanContext())
+}
+
+// registerDetailedView registers the view of the detailed level with the given name.
+func registerDetailedView(t *testing.T, name string) *view.View {
+	for _, v := range obsreportconfig.Configure(configtelemetry.LevelDetailed).Views {
+		if v.Name == name {
+			require.NoError(t, view.Register(v))
+			t.Cleanup(func() { view.Unregister(v) })
+			return v
+		}
+	}
+	require.Failf(t, "view not found", "no detailed view named %q", name)
+	return nil
+}
+
+// assertSpanExemplar checks that the distribution view recorded a single measurement, with an
+// exemplar referencing the given span.
+func assertSpanExemplar(t *testing.T, v *view.View, spanCtx trace.SpanContext) {
+	rows, err := view.RetrieveData(v.Name)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	dist, ok := rows[0].Data.(*view.DistributionData)
+	require.True(t, ok)
+	assert.EqualValues(t, 1, dist.Count)
+
+	var exemplars []*metricdata.Exemplar
+	for _, e := range dist.ExemplarsPerBucket {
+		if e != nil {
+			exemplars = append(exemplars, e)
+		}
+	}
+	require.Len(t, exemplars, 1)
+	sc, ok := exemplars[0].Attachments[metricdata.AttachmentKeySpanContext].(octrace.SpanContext)
+	require.True(t, ok)
+	assert.Equal(t, octrace.TraceID(spanCtx.TraceID()), sc.TraceID)
+	assert.Equal(t, octrace.SpanID(spanCtx.SpanID()), sc.SpanID)
+}
+
+func TestExemplarAttachmentsNotSampled(t *testing.T) {
+	assert.Nil(t, exemplarAttachments(context.Background()))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	assert.Nil(t, exemplarAttachments(trace.ContextWithSpanContext(context.Background(), sc)))
+
+	sampled := sc.WithTraceFlags(trace.FlagsSampled)
+	attachments := exemplarAttachments(trace.ContextWithSpanContext(context.Background(), sampled))
+	assert.Equal(t, octrace.SpanContext{TraceID: octrace.TraceID{1}, SpanID: octrace.SpanID{2}, TraceOptions: 1},
+		attachments[metricdata.AttachmentKeySpanContext])
+}
+
 func TestExportMetricsOp(t *testing.T) {
 	tt, err := obsreporttest.SetupTelemetry()
 	require.NoError(t, err)
@@ -536,3 +637,68 @@ func TestProcessorLogRecords(t *testing.T) {
 
 	require.NoError(t, obsreporttest.CheckProcessorLogs(tt, processor, acceptedRecords, refusedRecords, droppedRecords))
 }
+
+func TestPipelineData(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+
+	obsrep := NewPipeline(PipelineSettings{Level: configtelemetry.LevelNormal, PipelineID: pipeline})
+	assert.False(t, obsrep.BytesEnabled())
+
+	// Accepted by the pipeline.
+	ctx := obsrep.StartConsume(context.Background(), 5, 0)
+	obsrep.EndExport(ctx, 5, 0, nil)
+	obsrep.EndConsume(ctx, 5, nil)
+
+	// Refused by the processors.
+	ctx = obsrep.StartConsume(context.Background(), 3, 0)
+	obsrep.EndConsume(ctx, 3, errFake)
+
+	// Refused by the exporters, not reported again as refused by the processors.
+	ctx = obsrep.StartConsume(context.Background(), 2, 0)
+	obsrep.EndExport(ctx, 2, 0, errFake)
+	obsrep.EndConsume(ctx, 2, errFake)
+
+	// Refused by the exporters, after a processor not passing the context.
+	obsrep.EndExport(context.Background(), 4, 0, errFake)
+
+	require.NoError(t, obsreporttest.CheckPipeline(tt, pipeline, 10, 5, 3, 6))
+}
+
+func TestPipelineDataDetailed(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+
+	views := obsreportconfig.Configure(configtelemetry.LevelDetailed).Views
+	var detailed []*view.View
+	for _, v := range views {
+		switch v.Name {
+		case "pipeline/latency", "pipeline/incoming_bytes", "pipeline/outgoing_bytes":
+			detailed = append(detailed, v)
+		}
+	}
+	require.Len(t, detailed, 3)
+	require.NoError(t, view.Register(detailed...))
+	t.Cleanup(func() { view.Unregister(detailed...) })
+
+	obsrep := NewPipeline(PipelineSettings{Level: configtelemetry.LevelDetailed, PipelineID: pipeline})
+	assert.True(t, obsrep.BytesEnabled())
+	ctx := obsrep.StartConsume(context.Background(), 5, 100)
+	obsrep.EndExport(ctx, 4, 80, nil)
+	obsrep.EndConsume(ctx, 5, nil)
+	// Without the context of the consume operation, the latency is not reported.
+	obsrep.EndExport(context.Background(), 1, 20, nil)
+
+	for name, want := range map[string]float64{"pipeline/incoming_bytes": 100, "pipeline/outgoing_bytes": 100} {
+		rows, err := view.RetrieveData(name)
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, want, rows[0].Data.(*view.SumData).Value, name)
+	}
+	rows, err := view.RetrieveData("pipeline/latency")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.EqualValues(t, 1, rows[0].Data.(*view.DistributionData).Count)
+}
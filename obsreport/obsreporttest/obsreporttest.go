@@ -45,6 +45,8 @@ var (
 	transportTag, _ = tag.NewKey("transport")
 	exporterTag, _  = tag.NewKey("exporter")
 	processorTag, _ = tag.NewKey("processor")
+	pipelineTag, _  = tag.NewKey("pipeline")
+	stageTag, _     = tag.NewKey("stage")
 )
 
 type TestTelemetry struct {
@@ -204,6 +206,24 @@ func CheckScraperMetrics(_ TestTelemetry, receiver config.ComponentID, scraper c
 		checkValueForView(scraperTags, erroredMetricPoints, "scraper/errored_metric_points"))
 }
 
+// CheckPipeline checks that for the current exported values for pipeline metrics match given values.
+// When this function is called it is required to also call SetupTelemetry as first thing.
+func CheckPipeline(_ TestTelemetry, pipeline config.ComponentID, incomingItems, outgoingItems, droppedByProcessors, droppedByExporters int64) error {
+	pipelineTags := []tag.Tag{{Key: pipelineTag, Value: pipeline.String()}}
+	errs := multierr.Combine(
+		checkValueForView(pipelineTags, incomingItems, "pipeline/incoming_items"),
+		checkValueForView(pipelineTags, outgoingItems, "pipeline/outgoing_items"))
+	if droppedByProcessors > 0 {
+		errs = multierr.Append(errs, checkValueForView(
+			append([]tag.Tag{{Key: stageTag, Value: "processors"}}, pipelineTags...), droppedByProcessors, "pipeline/dropped_items"))
+	}
+	if droppedByExporters > 0 {
+		errs = multierr.Append(errs, checkValueForView(
+			append([]tag.Tag{{Key: stageTag, Value: "exporters"}}, pipelineTags...), droppedByExporters, "pipeline/dropped_items"))
+	}
+	return errs
+}
+
 // checkValueForView checks that for the current exported value in the view with the given name
 // for {LegacyTagKeyReceiver: receiverName} is equal to "value".
 func checkValueForView(wantTags []tag.Tag, value int64, vName string) error {
@@ -16,6 +16,7 @@ package obsreporttest_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,6 +35,7 @@ const (
 var (
 	receiver = config.NewComponentID("fakeReicever")
 	exporter = config.NewComponentID("fakeExporter")
+	pipeline = config.NewComponentID(config.LogsDataType)
 )
 
 func TestCheckReceiverTracesViews(t *testing.T) {
@@ -152,3 +154,20 @@ func TestCheckExporterLogsViews(t *testing.T) {
 	assert.Error(t, obsreporttest.CheckExporterLogs(tt, exporter, 0, 0))
 	assert.Error(t, obsreporttest.CheckExporterLogs(tt, exporter, 0, 7))
 }
+
+func TestCheckPipelineViews(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+
+	obsrep := obsreport.NewPipeline(obsreport.PipelineSettings{Level: tt.MetricsLevel, PipelineID: pipeline})
+	ctx := obsrep.StartConsume(context.Background(), 7, 0)
+	obsrep.EndExport(ctx, 7, 0, nil)
+	obsrep.EndConsume(ctx, 7, nil)
+	ctx = obsrep.StartConsume(context.Background(), 2, 0)
+	obsrep.EndConsume(ctx, 2, errors.New("refused"))
+
+	assert.NoError(t, obsreporttest.CheckPipeline(tt, pipeline, 9, 7, 2, 0))
+	assert.Error(t, obsreporttest.CheckPipeline(tt, pipeline, 7, 7, 0, 0))
+	assert.Error(t, obsreporttest.CheckPipeline(tt, pipeline, 9, 7, 2, 2))
+}
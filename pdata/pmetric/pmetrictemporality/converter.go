@@ -0,0 +1,269 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pmetrictemporality // import "go.opentelemetry.io/collector/pdata/pmetric/pmetrictemporality"
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// Converter converts the sums and histograms of metrics to a temporality, tracking the state of
+// their streams in a Store. The gauges, summaries and exponential histograms are left unchanged.
+//
+// The data points that cannot be converted are removed: the data points not newer than the last
+// one of their stream, and the first cumulative data point of a stream with an unknown start time
+// when converting to delta. A cumulative stream is considered reset when its start time changes,
+// when the count of a histogram or the value of a monotonic sum decreases, or when the bucket
+// bounds of a histogram change.
+type Converter struct {
+	store Store
+	to    pmetric.MetricAggregationTemporality
+}
+
+// NewDeltaToCumulative returns a Converter accumulating the delta sums and histograms into
+// cumulative ones, starting at the start time of the first data point of every stream.
+func NewDeltaToCumulative(store Store) *Converter {
+	return &Converter{store: store, to: pmetric.MetricAggregationTemporalityCumulative}
+}
+
+// NewCumulativeToDelta returns a Converter reporting the changes of the cumulative sums and
+// histograms since their previous data point.
+func NewCumulativeToDelta(store Store) *Converter {
+	return &Converter{store: store, to: pmetric.MetricAggregationTemporalityDelta}
+}
+
+// ConvertMetrics converts md in place. The metrics left without data points are removed.
+func (c *Converter) ConvertMetrics(md pmetric.Metrics) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			sm := sms.At(j)
+			sm.Metrics().RemoveIf(func(metric pmetric.Metric) bool {
+				switch metric.DataType() {
+				case pmetric.MetricDataTypeSum:
+					sum := metric.Sum()
+					if !c.convertible(sum.AggregationTemporality()) {
+						return false
+					}
+					sum.SetAggregationTemporality(c.to)
+					sum.DataPoints().RemoveIf(func(dp pmetric.NumberDataPoint) bool {
+						return !c.convertNumber(NewStreamID(rm.Resource(), sm.Scope(), metric, dp.Attributes()), sum.IsMonotonic(), dp)
+					})
+					return sum.DataPoints().Len() == 0
+				case pmetric.MetricDataTypeHistogram:
+					hist := metric.Histogram()
+					if !c.convertible(hist.AggregationTemporality()) {
+						return false
+					}
+					hist.SetAggregationTemporality(c.to)
+					c.convertHistograms(rm.Resource(), sm.Scope(), metric, hist.DataPoints())
+					return hist.DataPoints().Len() == 0
+				}
+				return false
+			})
+		}
+	}
+}
+
+// convertible returns whether data points of the temporality are converted.
+func (c *Converter) convertible(temporality pmetric.MetricAggregationTemporality) bool {
+	return temporality != c.to && temporality != pmetric.MetricAggregationTemporalityUnspecified
+}
+
+// convertNumber converts the data point of a sum, returning false if it must be removed.
+func (c *Converter) convertNumber(id StreamID, monotonic bool, dp pmetric.NumberDataPoint) bool {
+	prev, ok := c.store.Load(id)
+	if ok && dp.Timestamp() <= prev.Timestamp {
+		return false
+	}
+	cur := StreamState{StartTimestamp: dp.StartTimestamp(), Timestamp: dp.Timestamp()}
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		cur.IntValue = dp.IntVal()
+	} else {
+		cur.DoubleValue = dp.DoubleVal()
+	}
+
+	if c.to == pmetric.MetricAggregationTemporalityCumulative {
+		if ok {
+			cur.StartTimestamp = prev.StartTimestamp
+			cur.IntValue += prev.IntValue
+			cur.DoubleValue += prev.DoubleValue
+		}
+		c.store.Store(id, cur)
+		dp.SetStartTimestamp(cur.StartTimestamp)
+		setNumberValue(dp, cur.IntValue, cur.DoubleValue)
+		return true
+	}
+
+	c.store.Store(id, cur)
+	reset := !ok || cur.StartTimestamp != prev.StartTimestamp ||
+		(monotonic && (cur.IntValue < prev.IntValue || cur.DoubleValue < prev.DoubleValue))
+	if !reset {
+		dp.SetStartTimestamp(prev.Timestamp)
+		setNumberValue(dp, cur.IntValue-prev.IntValue, cur.DoubleValue-prev.DoubleValue)
+		return true
+	}
+	// After a reset, the cumulative value is the change since the start time, if known.
+	if cur.StartTimestamp == 0 || (ok && cur.StartTimestamp == prev.StartTimestamp) {
+		if !ok {
+			return false
+		}
+		dp.SetStartTimestamp(prev.Timestamp)
+	}
+	return true
+}
+
+func setNumberValue(dp pmetric.NumberDataPoint, intValue int64, doubleValue float64) {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		dp.SetIntVal(intValue)
+	} else {
+		dp.SetDoubleVal(doubleValue)
+	}
+}
+
+// convertHistograms converts the data points of a histogram, removing the ones that cannot be converted.
+// The data points are rebuilt, since the min and max of a converted data point may become unknown.
+func (c *Converter) convertHistograms(resource pcommon.Resource, scope pcommon.InstrumentationScope, metric pmetric.Metric, dps pmetric.HistogramDataPointSlice) {
+	converted := pmetric.NewHistogramDataPointSlice()
+	converted.EnsureCapacity(dps.Len())
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		state, ok := c.convertHistogram(NewStreamID(resource, scope, metric, dp.Attributes()), dp)
+		if !ok {
+			continue
+		}
+		out := converted.AppendEmpty()
+		dp.Attributes().CopyTo(out.Attributes())
+		dp.Exemplars().CopyTo(out.Exemplars())
+		dp.Flags().CopyTo(out.Flags())
+		out.SetStartTimestamp(state.StartTimestamp)
+		out.SetTimestamp(state.Timestamp)
+		out.SetCount(state.Count)
+		if state.HasSum {
+			out.SetSum(state.Sum)
+		}
+		out.SetBucketCounts(pcommon.NewImmutableUInt64Slice(state.BucketCounts))
+		out.SetExplicitBounds(pcommon.NewImmutableFloat64Slice(state.ExplicitBounds))
+		if state.HasMin {
+			out.SetMin(state.Min)
+		}
+		if state.HasMax {
+			out.SetMax(state.Max)
+		}
+	}
+	dps.RemoveIf(func(pmetric.HistogramDataPoint) bool { return true })
+	converted.MoveAndAppendTo(dps)
+}
+
+// convertHistogram returns the converted values of the data point of a histogram, and false if
+// the data point must be removed.
+func (c *Converter) convertHistogram(id StreamID, dp pmetric.HistogramDataPoint) (StreamState, bool) {
+	prev, ok := c.store.Load(id)
+	if ok && dp.Timestamp() <= prev.Timestamp {
+		return StreamState{}, false
+	}
+	cur := StreamState{
+		StartTimestamp: dp.StartTimestamp(),
+		Timestamp:      dp.Timestamp(),
+		Count:          dp.Count(),
+		Sum:            dp.Sum(),
+		HasSum:         dp.HasSum(),
+		BucketCounts:   dp.BucketCounts().AsRaw(),
+		ExplicitBounds: dp.ExplicitBounds().AsRaw(),
+		Min:            dp.Min(),
+		HasMin:         dp.HasMin(),
+		Max:            dp.Max(),
+		HasMax:         dp.HasMax(),
+	}
+	compatible := ok && equalBounds(cur.ExplicitBounds, prev.ExplicitBounds) && len(cur.BucketCounts) == len(prev.BucketCounts)
+
+	if c.to == pmetric.MetricAggregationTemporalityCumulative {
+		if compatible {
+			cur = accumulate(prev, cur)
+		}
+		c.store.Store(id, cur)
+		return cur, true
+	}
+
+	c.store.Store(id, cur)
+	if compatible && cur.StartTimestamp == prev.StartTimestamp && cur.Count >= prev.Count {
+		return difference(prev, cur), true
+	}
+	// After a reset, the cumulative values are the changes since the start time, if known.
+	if cur.StartTimestamp == 0 || (ok && cur.StartTimestamp == prev.StartTimestamp) {
+		if !ok {
+			return StreamState{}, false
+		}
+		cur.StartTimestamp = prev.Timestamp
+		cur.HasMin, cur.HasMax = false, false
+	}
+	return cur, true
+}
+
+// accumulate returns the cumulative state of a histogram after the delta cur.
+func accumulate(prev, cur StreamState) StreamState {
+	acc := cur
+	acc.StartTimestamp = prev.StartTimestamp
+	acc.Count += prev.Count
+	acc.Sum += prev.Sum
+	acc.HasSum = cur.HasSum && prev.HasSum
+	acc.BucketCounts = make([]uint64, len(cur.BucketCounts))
+	for i := range cur.BucketCounts {
+		acc.BucketCounts[i] = prev.BucketCounts[i] + cur.BucketCounts[i]
+	}
+	acc.HasMin = cur.HasMin && prev.HasMin
+	if acc.HasMin && prev.Min < cur.Min {
+		acc.Min = prev.Min
+	}
+	acc.HasMax = cur.HasMax && prev.HasMax
+	if acc.HasMax && prev.Max > cur.Max {
+		acc.Max = prev.Max
+	}
+	return acc
+}
+
+// difference returns the delta of a histogram between prev and cur. The min and max of the
+// delta are unknown.
+func difference(prev, cur StreamState) StreamState {
+	delta := StreamState{
+		StartTimestamp: prev.Timestamp,
+		Timestamp:      cur.Timestamp,
+		Count:          cur.Count - prev.Count,
+		Sum:            cur.Sum - prev.Sum,
+		HasSum:         cur.HasSum && prev.HasSum,
+		BucketCounts:   make([]uint64, len(cur.BucketCounts)),
+		ExplicitBounds: cur.ExplicitBounds,
+	}
+	for i := range cur.BucketCounts {
+		if cur.BucketCounts[i] > prev.BucketCounts[i] {
+			delta.BucketCounts[i] = cur.BucketCounts[i] - prev.BucketCounts[i]
+		}
+	}
+	return delta
+}
+
+func equalBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,192 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pmetrictemporality
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+type numberPoint struct {
+	start, ts uint64
+	value     int64
+}
+
+func newSum(temporality pmetric.MetricAggregationTemporality, monotonic bool, points ...numberPoint) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("requests")
+	metric.SetDataType(pmetric.MetricDataTypeSum)
+	metric.Sum().SetAggregationTemporality(temporality)
+	metric.Sum().SetIsMonotonic(monotonic)
+	for _, p := range points {
+		dp := metric.Sum().DataPoints().AppendEmpty()
+		dp.Attributes().UpsertString("method", "GET")
+		dp.SetStartTimestamp(pcommon.Timestamp(p.start))
+		dp.SetTimestamp(pcommon.Timestamp(p.ts))
+		dp.SetIntVal(p.value)
+	}
+	return md
+}
+
+func sumPoints(t *testing.T, md pmetric.Metrics, temporality pmetric.MetricAggregationTemporality) []numberPoint {
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	if metrics.Len() == 0 {
+		return nil
+	}
+	sum := metrics.At(0).Sum()
+	assert.Equal(t, temporality, sum.AggregationTemporality())
+	var points []numberPoint
+	for i := 0; i < sum.DataPoints().Len(); i++ {
+		dp := sum.DataPoints().At(i)
+		points = append(points, numberPoint{start: uint64(dp.StartTimestamp()), ts: uint64(dp.Timestamp()), value: dp.IntVal()})
+	}
+	return points
+}
+
+func TestDeltaToCumulativeSum(t *testing.T) {
+	conv := NewDeltaToCumulative(NewMemoryStore())
+
+	md := newSum(pmetric.MetricAggregationTemporalityDelta, true, numberPoint{10, 20, 3}, numberPoint{20, 30, 2})
+	conv.ConvertMetrics(md)
+	assert.Equal(t, []numberPoint{{10, 20, 3}, {10, 30, 5}}, sumPoints(t, md, pmetric.MetricAggregationTemporalityCumulative))
+
+	// The duplicated and out of order data points are removed.
+	md = newSum(pmetric.MetricAggregationTemporalityDelta, true, numberPoint{20, 30, 2}, numberPoint{30, 40, 4})
+	conv.ConvertMetrics(md)
+	assert.Equal(t, []numberPoint{{10, 40, 9}}, sumPoints(t, md, pmetric.MetricAggregationTemporalityCumulative))
+
+	md = newSum(pmetric.MetricAggregationTemporalityDelta, true, numberPoint{20, 30, 2})
+	conv.ConvertMetrics(md)
+	assert.Equal(t, 0, md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().Len(), "the metrics without data points are removed")
+
+	// The cumulative metrics are left unchanged.
+	md = newSum(pmetric.MetricAggregationTemporalityCumulative, true, numberPoint{10, 20, 3})
+	conv.ConvertMetrics(md)
+	assert.Equal(t, []numberPoint{{10, 20, 3}}, sumPoints(t, md, pmetric.MetricAggregationTemporalityCumulative))
+}
+
+func TestCumulativeToDeltaSum(t *testing.T) {
+	conv := NewCumulativeToDelta(NewMemoryStore())
+
+	md := newSum(pmetric.MetricAggregationTemporalityCumulative, true,
+		numberPoint{10, 20, 3}, numberPoint{10, 30, 5}, numberPoint{10, 30, 5}, numberPoint{10, 40, 9})
+	conv.ConvertMetrics(md)
+	assert.Equal(t, []numberPoint{{10, 20, 3}, {20, 30, 2}, {30, 40, 4}}, sumPoints(t, md, pmetric.MetricAggregationTemporalityDelta))
+
+	// A decreasing monotonic sum is reset, as well as a sum with a new start time.
+	md = newSum(pmetric.MetricAggregationTemporalityCumulative, true,
+		numberPoint{10, 50, 1}, numberPoint{10, 60, 4}, numberPoint{55, 70, 2})
+	conv.ConvertMetrics(md)
+	assert.Equal(t, []numberPoint{{40, 50, 1}, {50, 60, 3}, {55, 70, 2}}, sumPoints(t, md, pmetric.MetricAggregationTemporalityDelta))
+}
+
+func TestCumulativeToDeltaSumUnknownStart(t *testing.T) {
+	conv := NewCumulativeToDelta(NewMemoryStore())
+
+	md := newSum(pmetric.MetricAggregationTemporalityCumulative, false, numberPoint{0, 20, 3}, numberPoint{0, 30, 1})
+	conv.ConvertMetrics(md)
+	assert.Equal(t, []numberPoint{{20, 30, -2}}, sumPoints(t, md, pmetric.MetricAggregationTemporalityDelta),
+		"the first data point with an unknown start time is removed, a non monotonic sum may decrease")
+}
+
+func newHistogram(temporality pmetric.MetricAggregationTemporality, start, ts uint64, count uint64, buckets []uint64, min, max float64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("latency")
+	metric.SetDataType(pmetric.MetricDataTypeHistogram)
+	metric.Histogram().SetAggregationTemporality(temporality)
+	dp := metric.Histogram().DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(pcommon.Timestamp(start))
+	dp.SetTimestamp(pcommon.Timestamp(ts))
+	dp.SetCount(count)
+	dp.SetSum(float64(count) * 10)
+	dp.SetBucketCounts(pcommon.NewImmutableUInt64Slice(buckets))
+	dp.SetExplicitBounds(pcommon.NewImmutableFloat64Slice([]float64{5, 10}))
+	dp.SetMin(min)
+	dp.SetMax(max)
+	return md
+}
+
+func histogramPoint(t *testing.T, md pmetric.Metrics, temporality pmetric.MetricAggregationTemporality) pmetric.HistogramDataPoint {
+	hist := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Histogram()
+	assert.Equal(t, temporality, hist.AggregationTemporality())
+	require.Equal(t, 1, hist.DataPoints().Len())
+	return hist.DataPoints().At(0)
+}
+
+func TestDeltaToCumulativeHistogram(t *testing.T) {
+	conv := NewDeltaToCumulative(NewMemoryStore())
+
+	md := newHistogram(pmetric.MetricAggregationTemporalityDelta, 10, 20, 3, []uint64{1, 1, 1}, 2, 12)
+	conv.ConvertMetrics(md)
+	md = newHistogram(pmetric.MetricAggregationTemporalityDelta, 20, 30, 2, []uint64{2, 0, 0}, 1, 4)
+	conv.ConvertMetrics(md)
+
+	dp := histogramPoint(t, md, pmetric.MetricAggregationTemporalityCumulative)
+	assert.EqualValues(t, 10, dp.StartTimestamp())
+	assert.EqualValues(t, 30, dp.Timestamp())
+	assert.EqualValues(t, 5, dp.Count())
+	assert.EqualValues(t, 50, dp.Sum())
+	assert.Equal(t, []uint64{3, 1, 1}, dp.BucketCounts().AsRaw())
+	assert.Equal(t, []float64{5, 10}, dp.ExplicitBounds().AsRaw())
+	assert.EqualValues(t, 1, dp.Min())
+	assert.EqualValues(t, 12, dp.Max())
+}
+
+func TestCumulativeToDeltaHistogram(t *testing.T) {
+	conv := NewCumulativeToDelta(NewMemoryStore())
+
+	md := newHistogram(pmetric.MetricAggregationTemporalityCumulative, 10, 20, 3, []uint64{1, 1, 1}, 2, 12)
+	conv.ConvertMetrics(md)
+	dp := histogramPoint(t, md, pmetric.MetricAggregationTemporalityDelta)
+	assert.EqualValues(t, 3, dp.Count())
+	assert.True(t, dp.HasMin(), "the first data point is the change since the start time")
+
+	md = newHistogram(pmetric.MetricAggregationTemporalityCumulative, 10, 30, 5, []uint64{3, 1, 1}, 1, 12)
+	conv.ConvertMetrics(md)
+	dp = histogramPoint(t, md, pmetric.MetricAggregationTemporalityDelta)
+	assert.EqualValues(t, 20, dp.StartTimestamp())
+	assert.EqualValues(t, 30, dp.Timestamp())
+	assert.EqualValues(t, 2, dp.Count())
+	assert.EqualValues(t, 20, dp.Sum())
+	assert.Equal(t, []uint64{2, 0, 0}, dp.BucketCounts().AsRaw())
+	assert.False(t, dp.HasMin())
+	assert.False(t, dp.HasMax())
+
+	// A decreasing count is a reset.
+	md = newHistogram(pmetric.MetricAggregationTemporalityCumulative, 10, 40, 1, []uint64{1, 0, 0}, 1, 1)
+	conv.ConvertMetrics(md)
+	dp = histogramPoint(t, md, pmetric.MetricAggregationTemporalityDelta)
+	assert.EqualValues(t, 30, dp.StartTimestamp())
+	assert.EqualValues(t, 1, dp.Count())
+	assert.False(t, dp.HasMin())
+}
+
+func TestConvertMetricsGaugeUnchanged(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetDataType(pmetric.MetricDataTypeGauge)
+	metric.Gauge().DataPoints().AppendEmpty().SetIntVal(1)
+	expected := md.Clone()
+
+	NewCumulativeToDelta(NewMemoryStore()).ConvertMetrics(md)
+	assert.Equal(t, expected, md)
+}
@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pmetrictemporality // import "go.opentelemetry.io/collector/pdata/pmetric/pmetrictemporality"
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// StreamID identifies a metric stream: the resource, the instrumentation scope, the name, unit,
+// type and monotonicity of the metric, and the attributes of the data points. The temporality
+// is not part of the identity, so that a stream keeps its identity once converted.
+type StreamID [sha256.Size]byte
+
+// NewStreamID returns the StreamID of the data points with the given attributes, of the metric
+// reported by the resource and the instrumentation scope.
+func NewStreamID(resource pcommon.Resource, scope pcommon.InstrumentationScope, metric pmetric.Metric, attrs pcommon.Map) StreamID {
+	h := sha256.New()
+	writeMap(h, resource.Attributes())
+	writeString(h, scope.Name())
+	writeString(h, scope.Version())
+	writeString(h, metric.Name())
+	writeString(h, metric.Unit())
+	writeString(h, metric.DataType().String())
+	if metric.DataType() == pmetric.MetricDataTypeSum && metric.Sum().IsMonotonic() {
+		writeString(h, "monotonic")
+	}
+	writeMap(h, attrs)
+	var id StreamID
+	copy(id[:], h.Sum(nil))
+	return id
+}
+
+// writeString writes s prefixed by its length, so that the concatenation of strings is unambiguous.
+func writeString(h hash.Hash, s string) {
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(s)))
+	_, _ = h.Write(l[:])
+	_, _ = h.Write([]byte(s))
+}
+
+// writeMap writes the entries of m sorted by key, so that the order of the entries does not matter.
+func writeMap(h hash.Hash, m pcommon.Map) {
+	keys := make([]string, 0, m.Len())
+	m.Range(func(k string, _ pcommon.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+	writeString(h, "map")
+	for _, k := range keys {
+		v, _ := m.Get(k)
+		writeString(h, k)
+		writeString(h, v.Type().String())
+		writeString(h, v.AsString())
+	}
+}
+
+// StreamState is the state of a metric stream tracked to convert its temporality: the cumulative
+// value of the stream since StartTimestamp, as of Timestamp.
+type StreamState struct {
+	StartTimestamp pcommon.Timestamp
+	Timestamp      pcommon.Timestamp
+
+	// IntValue or DoubleValue is the value of a sum.
+	IntValue    int64
+	DoubleValue float64
+
+	// Count, Sum, BucketCounts, ExplicitBounds, Min and Max are the values of a histogram.
+	Count          uint64
+	Sum            float64
+	HasSum         bool
+	BucketCounts   []uint64
+	ExplicitBounds []float64
+	Min            float64
+	HasMin         bool
+	Max            float64
+	HasMax         bool
+}
+
+// Store holds the state of the metric streams. Implementations must be safe for concurrent use.
+type Store interface {
+	// Load returns the state of the stream, and false if the stream is not known.
+	Load(id StreamID) (StreamState, bool)
+
+	// Store sets the state of the stream.
+	Store(id StreamID, state StreamState)
+
+	// Delete forgets the stream.
+	Delete(id StreamID)
+}
+
+// MemoryStore is a Store keeping the state of the streams in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	streams map[StreamID]StreamState
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{streams: map[StreamID]StreamState{}}
+}
+
+// Load implements Store.
+func (ms *MemoryStore) Load(id StreamID) (StreamState, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	state, ok := ms.streams[id]
+	return state, ok
+}
+
+// Store implements Store.
+func (ms *MemoryStore) Store(id StreamID, state StreamState) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.streams[id] = state
+}
+
+// Delete implements Store.
+func (ms *MemoryStore) Delete(id StreamID) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.streams, id)
+}
+
+// RemoveStale forgets the streams not updated since before, so that the state of the streams
+// that stopped reporting does not grow unbounded. It returns the number of streams removed.
+func (ms *MemoryStore) RemoveStale(before pcommon.Timestamp) int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	removed := 0
+	for id, state := range ms.streams {
+		if state.Timestamp < before {
+			delete(ms.streams, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Len returns the number of streams tracked.
+func (ms *MemoryStore) Len() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return len(ms.streams)
+}
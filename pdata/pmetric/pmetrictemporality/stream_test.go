@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pmetrictemporality
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestNewStreamID(t *testing.T) {
+	resource := pcommon.NewResource()
+	resource.Attributes().UpsertString("service.name", "checkout")
+	scope := pcommon.NewInstrumentationScope()
+	scope.SetName("otelhttp")
+	metric := pmetric.NewMetric()
+	metric.SetName("requests")
+	metric.SetDataType(pmetric.MetricDataTypeSum)
+	attrs := pcommon.NewMap()
+	attrs.UpsertString("method", "GET")
+	attrs.UpsertInt("status", 200)
+	id := NewStreamID(resource, scope, metric, attrs)
+
+	reordered := pcommon.NewMap()
+	reordered.UpsertInt("status", 200)
+	reordered.UpsertString("method", "GET")
+	assert.Equal(t, id, NewStreamID(resource, scope, metric, reordered))
+
+	metric.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityDelta)
+	assert.Equal(t, id, NewStreamID(resource, scope, metric, attrs), "the temporality is not part of the identity")
+
+	other := pcommon.NewMap()
+	other.UpsertString("method", "GET")
+	other.UpsertString("status", "200")
+	assert.NotEqual(t, id, NewStreamID(resource, scope, metric, other), "the type of the attributes is part of the identity")
+
+	metric.Sum().SetIsMonotonic(true)
+	assert.NotEqual(t, id, NewStreamID(resource, scope, metric, attrs))
+
+	metric.SetName("requests.total")
+	metric.Sum().SetIsMonotonic(false)
+	assert.NotEqual(t, id, NewStreamID(resource, scope, metric, attrs))
+
+	// The concatenation of the name and the unit is not ambiguous.
+	metric.SetName("request")
+	metric.SetUnit("s")
+	renamed := NewStreamID(resource, scope, metric, attrs)
+	metric.SetName("requests")
+	metric.SetUnit("")
+	assert.NotEqual(t, renamed, NewStreamID(resource, scope, metric, attrs))
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	a, b := StreamID{1}, StreamID{2}
+	_, ok := store.Load(a)
+	assert.False(t, ok)
+
+	store.Store(a, StreamState{Timestamp: 10, IntValue: 1})
+	store.Store(b, StreamState{Timestamp: 20, IntValue: 2})
+	state, ok := store.Load(a)
+	assert.True(t, ok)
+	assert.Equal(t, StreamState{Timestamp: 10, IntValue: 1}, state)
+	assert.Equal(t, 2, store.Len())
+
+	assert.Equal(t, 1, store.RemoveStale(15))
+	_, ok = store.Load(a)
+	assert.False(t, ok)
+	assert.Equal(t, 1, store.Len())
+
+	store.Delete(b)
+	assert.Equal(t, 0, store.Len())
+}
@@ -16,7 +16,10 @@ package batchprocessor // import "go.opentelemetry.io/collector/processor/batchp
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,9 +27,11 @@ import (
 	"go.opencensus.io/tag"
 	"go.uber.org/zap"
 
+	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configtelemetry"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -40,21 +45,52 @@ import (
 // Batches are sent out with any of the following conditions:
 // - batch size reaches cfg.SendBatchSize
 // - cfg.Timeout is elapsed since the timestamp when the previous batch was sent out.
+//
+// When cfg.MetadataKeys is set, a distinct batch, called shard, is formed for each
+// combination of the values of the keys, and sent out on its own conditions.
 type batchProcessor struct {
 	logger           *zap.Logger
 	exportCtx        context.Context
-	timer            *time.Timer
 	timeout          time.Duration
 	sendBatchSize    int
 	sendBatchMaxSize int
 
-	newItem chan interface{}
-	batch   batch
+	// newBatch creates the batch of a new shard.
+	newBatch func() batch
+
+	// metadataKeys are the keys forming the shards, nil if a single shard is formed.
+	metadataKeys  []string
+	metadataLimit int
 
 	shutdownC  chan struct{}
 	goroutines sync.WaitGroup
 
 	telemetryLevel configtelemetry.Level
+
+	// single is the only shard when no metadata keys are set.
+	single *shard
+
+	lock   sync.Mutex
+	shards map[string]*shard
+	// stopped is set by Shutdown, after which no shard is started.
+	stopped bool
+}
+
+// shard is a batch of the items sharing the same values of the metadata keys.
+type shard struct {
+	processor *batchProcessor
+	// exportCtx is the context the batch is sent with, carrying the values of the metadata keys.
+	exportCtx context.Context
+	timer     *time.Timer
+	newItem   chan interface{}
+	batch     batch
+
+	// stopC is closed to stop the shard when it is evicted, nil for the single shard.
+	stopC chan struct{}
+	// lastUsed is the last time data was received for the shard, and senders the number of
+	// the items being sent to newItem. Both are guarded by the processor lock.
+	lastUsed time.Time
+	senders  int
 }
 
 type batch interface {
@@ -68,16 +104,23 @@ type batch interface {
 	add(item interface{})
 }
 
+// errTooManyBatches is returned when the data would form more shards than the cardinality limit,
+// none of the shards being idle.
+var errTooManyBatches = consumererror.NewPermanent(errors.New("too many batches for the metadata keys values"))
+
+// errShutdown is returned when the data is received after Shutdown.
+var errShutdown = errors.New("the batch processor is shut down")
+
 var _ consumer.Traces = (*batchProcessor)(nil)
 var _ consumer.Metrics = (*batchProcessor)(nil)
 var _ consumer.Logs = (*batchProcessor)(nil)
 
-func newBatchProcessor(set component.ProcessorCreateSettings, cfg *Config, batch batch, telemetryLevel configtelemetry.Level) (*batchProcessor, error) {
+func newBatchProcessor(set component.ProcessorCreateSettings, cfg *Config, newBatch func() batch, telemetryLevel configtelemetry.Level) (*batchProcessor, error) {
 	exportCtx, err := tag.New(context.Background(), tag.Insert(processorTagKey, cfg.ID().String()))
 	if err != nil {
 		return nil, err
 	}
-	return &batchProcessor{
+	bp := &batchProcessor{
 		logger:         set.Logger,
 		exportCtx:      exportCtx,
 		telemetryLevel: telemetryLevel,
@@ -85,10 +128,26 @@ func newBatchProcessor(set component.ProcessorCreateSettings, cfg *Config, batch
 		sendBatchSize:    int(cfg.SendBatchSize),
 		sendBatchMaxSize: int(cfg.SendBatchMaxSize),
 		timeout:          cfg.Timeout,
-		newItem:          make(chan interface{}, runtime.NumCPU()),
-		batch:            batch,
+		newBatch:         newBatch,
 		shutdownC:        make(chan struct{}, 1),
-	}, nil
+	}
+	if len(cfg.MetadataKeys) == 0 {
+		bp.single = bp.newShard(exportCtx)
+	} else {
+		bp.metadataKeys = cfg.MetadataKeys
+		bp.metadataLimit = int(cfg.MetadataCardinalityLimit)
+		bp.shards = make(map[string]*shard)
+	}
+	return bp, nil
+}
+
+func (bp *batchProcessor) newShard(exportCtx context.Context) *shard {
+	return &shard{
+		processor: bp,
+		exportCtx: exportCtx,
+		newItem:   make(chan interface{}, runtime.NumCPU()),
+		batch:     bp.newBatch(),
+	}
 }
 
 func (bp *batchProcessor) Capabilities() consumer.Capabilities {
@@ -97,13 +156,19 @@ func (bp *batchProcessor) Capabilities() consumer.Capabilities {
 
 // Start is invoked during service startup.
 func (bp *batchProcessor) Start(context.Context, component.Host) error {
-	bp.goroutines.Add(1)
-	go bp.startProcessingCycle()
+	if bp.single != nil {
+		bp.goroutines.Add(1)
+		go bp.single.startProcessingCycle()
+	}
 	return nil
 }
 
 // Shutdown is invoked during service shutdown.
 func (bp *batchProcessor) Shutdown(context.Context) error {
+	// No shard is started once stopped, so that goroutines is not added to while waited for.
+	bp.lock.Lock()
+	bp.stopped = true
+	bp.lock.Unlock()
 	close(bp.shutdownC)
 
 	// Wait until all goroutines are done.
@@ -111,69 +176,166 @@ func (bp *batchProcessor) Shutdown(context.Context) error {
 	return nil
 }
 
-func (bp *batchProcessor) startProcessingCycle() {
-	defer bp.goroutines.Done()
-	bp.timer = time.NewTimer(bp.timeout)
-	for {
-		select {
-		case <-bp.shutdownC:
-		DONE:
-			for {
-				select {
-				case item := <-bp.newItem:
-					bp.processItem(item)
-				default:
-					break DONE
+// shardFor returns the shard of the data received with the given context, creating and
+// starting it if needed. Once the limit of shards is reached, the least recently used idle
+// shard is evicted. The shards returned for metadata keys must be released once the data
+// is sent to them.
+func (bp *batchProcessor) shardFor(ctx context.Context) (*shard, error) {
+	if bp.single != nil {
+		return bp.single, nil
+	}
+
+	info := client.FromContext(ctx)
+	md := make(map[string][]string)
+	auth := make(authData)
+	var key strings.Builder
+	for _, k := range bp.metadataKeys {
+		var values []string
+		if name := strings.TrimPrefix(k, authKeyPrefix); name != k {
+			if info.Auth != nil {
+				if v := info.Auth.GetAttribute(name); v != nil {
+					auth[name] = v
+					values = authValues(v)
 				}
 			}
-			// This is the close of the channel
-			if bp.batch.itemCount() > 0 {
-				// TODO: Set a timeout on sendTraces or
-				// make it cancellable using the context that Shutdown gets as a parameter
-				bp.sendItems(statTimeoutTriggerSend)
-			}
+		} else if values = info.Metadata.Get(k); len(values) > 0 {
+			md[k] = values
+		}
+		fmt.Fprintf(&key, "%q;", values)
+	}
+
+	bp.lock.Lock()
+	defer bp.lock.Unlock()
+	if bp.stopped {
+		return nil, errShutdown
+	}
+	s, ok := bp.shards[key.String()]
+	if !ok {
+		if len(bp.shards) >= bp.metadataLimit && !bp.evictIdleShard() {
+			return nil, errTooManyBatches
+		}
+		exportInfo := client.Info{Metadata: client.NewMetadata(md)}
+		if len(auth) > 0 {
+			exportInfo.Auth = auth
+		}
+		s = bp.newShard(client.NewContext(bp.exportCtx, exportInfo))
+		s.stopC = make(chan struct{})
+		bp.shards[key.String()] = s
+		bp.goroutines.Add(1)
+		go s.startProcessingCycle()
+	}
+	s.lastUsed = time.Now()
+	s.senders++
+	return s, nil
+}
+
+// evictIdleShard stops the least recently used shard which has not received data for at
+// least the timeout, its pending items being sent. Returns false if no shard is idle.
+// Must be called with the lock held.
+func (bp *batchProcessor) evictIdleShard() bool {
+	idleSince := time.Now().Add(-bp.timeout)
+	var evictKey string
+	var evict *shard
+	for key, s := range bp.shards {
+		if s.senders == 0 && s.lastUsed.Before(idleSince) && (evict == nil || s.lastUsed.Before(evict.lastUsed)) {
+			evictKey, evict = key, s
+		}
+	}
+	if evict == nil {
+		return false
+	}
+	delete(bp.shards, evictKey)
+	close(evict.stopC)
+	return true
+}
+
+func (bp *batchProcessor) consume(ctx context.Context, item interface{}) error {
+	s, err := bp.shardFor(ctx)
+	if err != nil {
+		return err
+	}
+	s.newItem <- item
+	if s.stopC != nil {
+		bp.lock.Lock()
+		s.senders--
+		bp.lock.Unlock()
+	}
+	return nil
+}
+
+func (s *shard) startProcessingCycle() {
+	defer s.processor.goroutines.Done()
+	s.timer = time.NewTimer(s.processor.timeout)
+	for {
+		select {
+		case <-s.processor.shutdownC:
+			s.stop()
 			return
-		case item := <-bp.newItem:
+		case <-s.stopC:
+			s.stop()
+			return
+		case item := <-s.newItem:
 			if item == nil {
 				continue
 			}
-			bp.processItem(item)
-		case <-bp.timer.C:
-			if bp.batch.itemCount() > 0 {
-				bp.sendItems(statTimeoutTriggerSend)
+			s.processItem(item)
+		case <-s.timer.C:
+			if s.batch.itemCount() > 0 {
+				s.sendItems(statTimeoutTriggerSend)
 			}
-			bp.resetTimer()
+			s.resetTimer()
 		}
 	}
 }
 
-func (bp *batchProcessor) processItem(item interface{}) {
-	bp.batch.add(item)
+// stop sends the pending items.
+func (s *shard) stop() {
+DONE:
+	for {
+		select {
+		case item := <-s.newItem:
+			s.processItem(item)
+		default:
+			break DONE
+		}
+	}
+	// This is the close of the channel
+	if s.batch.itemCount() > 0 {
+		// TODO: Set a timeout on sendTraces or
+		// make it cancellable using the context that Shutdown gets as a parameter
+		s.sendItems(statTimeoutTriggerSend)
+	}
+	s.timer.Stop()
+}
+
+func (s *shard) processItem(item interface{}) {
+	s.batch.add(item)
 	sent := false
-	for bp.batch.itemCount() >= bp.sendBatchSize {
+	for s.batch.itemCount() >= s.processor.sendBatchSize {
 		sent = true
-		bp.sendItems(statBatchSizeTriggerSend)
+		s.sendItems(statBatchSizeTriggerSend)
 	}
 
 	if sent {
-		bp.stopTimer()
-		bp.resetTimer()
+		s.stopTimer()
+		s.resetTimer()
 	}
 }
 
-func (bp *batchProcessor) stopTimer() {
-	if !bp.timer.Stop() {
-		<-bp.timer.C
+func (s *shard) stopTimer() {
+	if !s.timer.Stop() {
+		<-s.timer.C
 	}
 }
 
-func (bp *batchProcessor) resetTimer() {
-	bp.timer.Reset(bp.timeout)
+func (s *shard) resetTimer() {
+	s.timer.Reset(s.processor.timeout)
 }
 
-func (bp *batchProcessor) sendItems(triggerMeasure *stats.Int64Measure) {
+func (s *shard) sendItems(triggerMeasure *stats.Int64Measure) {
+	bp := s.processor
 	detailed := bp.telemetryLevel == configtelemetry.LevelDetailed
-	sent, bytes, err := bp.batch.export(bp.exportCtx, bp.sendBatchMaxSize, detailed)
+	sent, bytes, err := s.batch.export(s.exportCtx, bp.sendBatchMaxSize, detailed)
 	if err != nil {
 		bp.logger.Warn("Sender failed", zap.Error(err))
 	} else {
@@ -186,37 +348,34 @@ func (bp *batchProcessor) sendItems(triggerMeasure *stats.Int64Measure) {
 }
 
 // ConsumeTraces implements TracesProcessor
-func (bp *batchProcessor) ConsumeTraces(_ context.Context, td ptrace.Traces) error {
-	bp.newItem <- td
-	return nil
+func (bp *batchProcessor) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return bp.consume(ctx, td)
 }
 
 // ConsumeMetrics implements MetricsProcessor
-func (bp *batchProcessor) ConsumeMetrics(_ context.Context, md pmetric.Metrics) error {
+func (bp *batchProcessor) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
 	// First thing is convert into a different internal format
-	bp.newItem <- md
-	return nil
+	return bp.consume(ctx, md)
 }
 
 // ConsumeLogs implements LogsProcessor
-func (bp *batchProcessor) ConsumeLogs(_ context.Context, ld plog.Logs) error {
-	bp.newItem <- ld
-	return nil
+func (bp *batchProcessor) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return bp.consume(ctx, ld)
 }
 
 // newBatchTracesProcessor creates a new batch processor that batches traces by size or with timeout
 func newBatchTracesProcessor(set component.ProcessorCreateSettings, next consumer.Traces, cfg *Config, telemetryLevel configtelemetry.Level) (*batchProcessor, error) {
-	return newBatchProcessor(set, cfg, newBatchTraces(next), telemetryLevel)
+	return newBatchProcessor(set, cfg, func() batch { return newBatchTraces(next) }, telemetryLevel)
 }
 
 // newBatchMetricsProcessor creates a new batch processor that batches metrics by size or with timeout
 func newBatchMetricsProcessor(set component.ProcessorCreateSettings, next consumer.Metrics, cfg *Config, telemetryLevel configtelemetry.Level) (*batchProcessor, error) {
-	return newBatchProcessor(set, cfg, newBatchMetrics(next), telemetryLevel)
+	return newBatchProcessor(set, cfg, func() batch { return newBatchMetrics(next) }, telemetryLevel)
 }
 
 // newBatchLogsProcessor creates a new batch processor that batches logs by size or with timeout
 func newBatchLogsProcessor(set component.ProcessorCreateSettings, next consumer.Logs, cfg *Config, telemetryLevel configtelemetry.Level) (*batchProcessor, error) {
-	return newBatchProcessor(set, cfg, newBatchLogs(next), telemetryLevel)
+	return newBatchProcessor(set, cfg, func() batch { return newBatchLogs(next) }, telemetryLevel)
 }
 
 type batchTraces struct {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -26,10 +27,12 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opencensus.io/stats/view"
 
+	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configtelemetry"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/internal/testdata"
 	"go.opentelemetry.io/collector/pdata/plog"
@@ -843,3 +846,100 @@ func TestShutdown(t *testing.T) {
 	factory := NewFactory()
 	componenttest.VerifyProcessorShutdown(t, factory, factory.CreateDefaultConfig())
 }
+
+func TestBatchProcessorMetadataKeys(t *testing.T) {
+	var mu sync.Mutex
+	spansByKey := make(map[string]int)
+	next, err := consumer.NewTraces(func(ctx context.Context, td ptrace.Traces) error {
+		info := client.FromContext(ctx)
+		key := strings.Join(info.Metadata.Get("tenant_id"), ",") + "|"
+		if info.Auth != nil {
+			key += fmt.Sprint(info.Auth.GetAttribute("subject"))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		spansByKey[key] += td.SpanCount()
+		return nil
+	})
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.SendBatchSize = 1000
+	cfg.Timeout = time.Hour
+	cfg.MetadataKeys = []string{"tenant_id", "auth.subject"}
+	batcher, err := newBatchTracesProcessor(componenttest.NewNopProcessorCreateSettings(), next, cfg, configtelemetry.LevelDetailed)
+	require.NoError(t, err)
+	require.NoError(t, batcher.Start(context.Background(), componenttest.NewNopHost()))
+
+	newContext := func(tenantKey, tenant, subject string) context.Context {
+		info := client.Info{Metadata: client.NewMetadata(map[string][]string{tenantKey: {tenant}, "other": {"ignored"}})}
+		if subject != "" {
+			info.Auth = authData{"subject": subject, "other": "ignored"}
+		}
+		return client.NewContext(context.Background(), info)
+	}
+	assert.NoError(t, batcher.ConsumeTraces(newContext("tenant_id", "a", "x"), testdata.GenerateTraces(1)))
+	assert.NoError(t, batcher.ConsumeTraces(newContext("Tenant_ID", "a", "x"), testdata.GenerateTraces(2)))
+	assert.NoError(t, batcher.ConsumeTraces(newContext("tenant_id", "b", "x"), testdata.GenerateTraces(3)))
+	assert.NoError(t, batcher.ConsumeTraces(newContext("tenant_id", "a", ""), testdata.GenerateTraces(4)))
+	assert.NoError(t, batcher.ConsumeTraces(context.Background(), testdata.GenerateTraces(5)))
+	require.NoError(t, batcher.Shutdown(context.Background()))
+
+	assert.Equal(t, map[string]int{
+		"a|x": 3,
+		"b|x": 3,
+		"a|":  4,
+		"|":   5,
+	}, spansByKey)
+}
+
+func TestBatchProcessorMetadataCardinalityLimit(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Timeout = time.Hour
+	cfg.MetadataKeys = []string{"tenant_id"}
+	cfg.MetadataCardinalityLimit = 1
+	batcher, err := newBatchLogsProcessor(componenttest.NewNopProcessorCreateSettings(), new(consumertest.LogsSink), cfg, configtelemetry.LevelDetailed)
+	require.NoError(t, err)
+	require.NoError(t, batcher.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, batcher.Shutdown(context.Background()))
+	}()
+
+	newContext := func(tenant string) context.Context {
+		return client.NewContext(context.Background(), client.Info{Metadata: client.NewMetadata(map[string][]string{"tenant_id": {tenant}})})
+	}
+	assert.NoError(t, batcher.ConsumeLogs(newContext("a"), testdata.GenerateLogs(1)))
+	assert.NoError(t, batcher.ConsumeLogs(newContext("a"), testdata.GenerateLogs(1)))
+	err = batcher.ConsumeLogs(newContext("b"), testdata.GenerateLogs(1))
+	assert.ErrorIs(t, err, errTooManyBatches)
+	assert.True(t, consumererror.IsPermanent(err))
+}
+
+func TestBatchProcessorMetadataIdleShardEvicted(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Timeout = 10 * time.Millisecond
+	cfg.SendBatchSize = 1000
+	cfg.MetadataKeys = []string{"tenant_id"}
+	cfg.MetadataCardinalityLimit = 1
+	batcher, err := newBatchLogsProcessor(componenttest.NewNopProcessorCreateSettings(), sink, cfg, configtelemetry.LevelDetailed)
+	require.NoError(t, err)
+	require.NoError(t, batcher.Start(context.Background(), componenttest.NewNopHost()))
+
+	newContext := func(tenant string) context.Context {
+		return client.NewContext(context.Background(), client.Info{Metadata: client.NewMetadata(map[string][]string{"tenant_id": {tenant}})})
+	}
+	require.NoError(t, batcher.ConsumeLogs(newContext("a"), testdata.GenerateLogs(1)))
+	// The shard of "a" is evicted once idle for the timeout.
+	time.Sleep(5 * cfg.Timeout)
+	require.NoError(t, batcher.ConsumeLogs(newContext("b"), testdata.GenerateLogs(2)))
+	batcher.lock.Lock()
+	assert.Len(t, batcher.shards, 1)
+	batcher.lock.Unlock()
+
+	require.NoError(t, batcher.Shutdown(context.Background()))
+	assert.Equal(t, 3, sink.LogRecordCount())
+
+	// No shard is started after Shutdown.
+	assert.ErrorIs(t, batcher.ConsumeLogs(newContext("c"), testdata.GenerateLogs(1)), errShutdown)
+}
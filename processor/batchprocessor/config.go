@@ -16,6 +16,8 @@ package batchprocessor // import "go.opentelemetry.io/collector/processor/batchp
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/collector/config"
@@ -35,6 +37,17 @@ type Config struct {
 	// Larger batches are split into smaller units.
 	// Default value is 0, that means no maximum size.
 	SendBatchMaxSize uint32 `mapstructure:"send_batch_max_size"`
+
+	// MetadataKeys is a list of client.Metadata keys, or of client.AuthData attributes prefixed
+	// with "auth.", used to form distinct batches. The batches are sent with a client.Info holding
+	// the values of these keys, available to the next processors and the exporters.
+	// Default value is empty, that means a single batch is formed and the client.Info is not propagated.
+	MetadataKeys []string `mapstructure:"metadata_keys"`
+
+	// MetadataCardinalityLimit is the maximum number of distinct combinations of the values of
+	// MetadataKeys. Once reached, the least recently used batch idle for Timeout is evicted, and the
+	// data is refused if none is idle. Only used when MetadataKeys is set.
+	MetadataCardinalityLimit uint32 `mapstructure:"metadata_cardinality_limit"`
 }
 
 var _ config.Processor = (*Config)(nil)
@@ -44,5 +57,17 @@ func (cfg *Config) Validate() error {
 	if cfg.SendBatchMaxSize > 0 && cfg.SendBatchMaxSize < cfg.SendBatchSize {
 		return errors.New("send_batch_max_size must be greater or equal to send_batch_size")
 	}
+	uniq := make(map[string]struct{}, len(cfg.MetadataKeys))
+	for _, k := range cfg.MetadataKeys {
+		// The client.Metadata keys are case-insensitive.
+		lk := strings.ToLower(k)
+		if _, ok := uniq[lk]; ok {
+			return fmt.Errorf("duplicate entry in metadata_keys: %q (case-insensitive)", k)
+		}
+		uniq[lk] = struct{}{}
+	}
+	if len(cfg.MetadataKeys) > 0 && cfg.MetadataCardinalityLimit == 0 {
+		return errors.New("metadata_cardinality_limit must be greater than zero when metadata_keys is set")
+	}
 	return nil
 }
@@ -46,6 +46,9 @@ func TestUnmarshalConfig(t *testing.T) {
 			SendBatchSize:     uint32(10000),
 			SendBatchMaxSize:  uint32(11000),
 			Timeout:           time.Second * 10,
+
+			MetadataKeys:             []string{"tenant_id", "auth.subject"},
+			MetadataCardinalityLimit: 100,
 		}, cfg)
 }
 
@@ -76,3 +79,20 @@ func TestValidateConfig_InvalidBatchSize(t *testing.T) {
 	}
 	assert.Error(t, cfg.Validate())
 }
+
+func TestValidateConfig_MetadataKeys(t *testing.T) {
+	cfg := &Config{
+		ProcessorSettings:        config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "2")),
+		SendBatchSize:            100,
+		MetadataKeys:             []string{"tenant_id", "auth.subject"},
+		MetadataCardinalityLimit: 10,
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.MetadataKeys = []string{"tenant_id", "Tenant_ID"}
+	assert.EqualError(t, cfg.Validate(), `duplicate entry in metadata_keys: "Tenant_ID" (case-insensitive)`)
+
+	cfg.MetadataKeys = []string{"tenant_id"}
+	cfg.MetadataCardinalityLimit = 0
+	assert.EqualError(t, cfg.Validate(), "metadata_cardinality_limit must be greater than zero when metadata_keys is set")
+}
@@ -29,6 +29,8 @@ const (
 
 	defaultSendBatchSize = uint32(8192)
 	defaultTimeout       = 200 * time.Millisecond
+
+	defaultMetadataCardinalityLimit = 1000
 )
 
 // NewFactory returns a new factory for the Batch processor.
@@ -46,6 +48,8 @@ func createDefaultConfig() config.Processor {
 		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
 		SendBatchSize:     defaultSendBatchSize,
 		Timeout:           defaultTimeout,
+
+		MetadataCardinalityLimit: defaultMetadataCardinalityLimit,
 	}
 }
 
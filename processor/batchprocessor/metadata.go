@@ -0,0 +1,57 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchprocessor // import "go.opentelemetry.io/collector/processor/batchprocessor"
+
+import (
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/collector/client"
+)
+
+// authKeyPrefix prefixes the metadata keys referring to attributes of the client.AuthData,
+// instead of the client.Metadata.
+const authKeyPrefix = "auth."
+
+// authData is the client.AuthData of the batches, holding the attributes referred to by the
+// metadata keys.
+type authData map[string]interface{}
+
+var _ client.AuthData = (authData)(nil)
+
+func (a authData) GetAttribute(name string) interface{} {
+	return a[name]
+}
+
+func (a authData) GetAttributeNames() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// authValues returns the values of an authentication attribute used to form the batches.
+func authValues(v interface{}) []string {
+	switch tv := v.(type) {
+	case string:
+		return []string{tv}
+	case []string:
+		return tv
+	default:
+		return []string{fmt.Sprint(tv)}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configbytes"
 )
 
 // Config defines configuration for memory memoryLimiter processor.
@@ -40,8 +41,16 @@ type Config struct {
 	// measurements of memory usage.
 	MemorySpikeLimitMiB uint32 `mapstructure:"spike_limit_mib"`
 
+	// MemoryLimit is the maximum amount of memory targeted to be allocated by the
+	// process, as a byte size such as "4GiB". It cannot be set together with MemoryLimitMiB.
+	MemoryLimit configbytes.ByteSize `mapstructure:"limit"`
+
+	// MemorySpikeLimit is the maximum spike expected between the measurements of memory
+	// usage, as a byte size. It cannot be set together with MemorySpikeLimitMiB.
+	MemorySpikeLimit configbytes.ByteSize `mapstructure:"spike_limit"`
+
 	// MemoryLimitPercentage is the maximum amount of memory, in %, targeted to be
-	// allocated by the process. The fixed memory settings MemoryLimitMiB has a higher precedence.
+	// allocated by the process. The fixed memory settings MemoryLimitMiB and MemoryLimit have a higher precedence.
 	MemoryLimitPercentage uint32 `mapstructure:"limit_percentage"`
 
 	// MemorySpikePercentage is the maximum, in percents against the total memory,
@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configbytes"
 	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 )
@@ -48,3 +49,21 @@ func TestUnmarshalConfig(t *testing.T) {
 			MemorySpikeLimitMiB: 500,
 		}, cfg)
 }
+
+func TestUnmarshalConfigByteSizes(t *testing.T) {
+	cm := confmap.NewFromStringMap(map[string]interface{}{
+		"check_interval": "5s",
+		"limit":          "4GiB",
+		"spike_limit":    "512MiB",
+	})
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.NoError(t, config.UnmarshalProcessor(cm, cfg))
+	assert.Equal(t,
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+			CheckInterval:     5 * time.Second,
+			MemoryLimit:       4 * configbytes.Gibibyte,
+			MemorySpikeLimit:  512 * configbytes.Mebibyte,
+		}, cfg)
+}
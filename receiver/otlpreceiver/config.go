@@ -21,6 +21,7 @@ import (
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
 )
 
 const (
@@ -41,6 +42,8 @@ type Config struct {
 	config.ReceiverSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
 	// Protocols is the configuration for the supported protocols, currently gRPC and HTTP (Proto and JSON).
 	Protocols `mapstructure:"protocols"`
+	// MetadataAttributes lists the gRPC metadata and HTTP headers copied into the resource attributes of the received data.
+	MetadataAttributes []receiverhelper.MetadataAttribute `mapstructure:"metadata_attributes"`
 }
 
 var _ config.Receiver = (*Config)(nil)
@@ -52,7 +55,7 @@ func (cfg *Config) Validate() error {
 		cfg.HTTP == nil {
 		return errors.New("must specify at least one protocol when using the OTLP receiver")
 	}
-	return nil
+	return receiverhelper.ValidateMetadataAttributes(cfg.MetadataAttributes)
 }
 
 // Unmarshal a confmap.Conf into the config struct.
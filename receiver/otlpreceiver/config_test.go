@@ -29,6 +29,7 @@ import (
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
 )
 
 func TestUnmarshalDefaultConfig(t *testing.T) {
@@ -200,3 +201,22 @@ func TestUnmarshalConfigEmpty(t *testing.T) {
 	cfg := factory.CreateDefaultConfig()
 	assert.EqualError(t, config.UnmarshalReceiver(confmap.New(), cfg), "empty config for OTLP receiver")
 }
+
+func TestUnmarshalConfigMetadataAttributes(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "metadata_attributes.yaml"))
+	require.NoError(t, err)
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	assert.NoError(t, config.UnmarshalReceiver(cm, cfg))
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, []receiverhelper.MetadataAttribute{
+		{Key: "X-Tenant", Attribute: "tenant"},
+		{Key: "x-region"},
+	}, cfg.MetadataAttributes)
+}
+
+func TestValidateConfigMetadataAttributes(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.MetadataAttributes = []receiverhelper.MetadataAttribute{{Key: "X-Tenant"}, {Key: "x-tenant"}}
+	assert.EqualError(t, cfg.Validate(), `duplicate metadata attribute key "x-tenant"`)
+}
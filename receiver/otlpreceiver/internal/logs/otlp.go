@@ -22,6 +22,7 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/obsreport"
 	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
 )
 
 const (
@@ -33,12 +34,14 @@ const (
 type Receiver struct {
 	nextConsumer consumer.Logs
 	obsrecv      *obsreport.Receiver
+	attrs        *receiverhelper.MetadataAttributes
 }
 
 // New creates a new Receiver reference.
-func New(id config.ComponentID, nextConsumer consumer.Logs, set component.ReceiverCreateSettings) *Receiver {
+func New(id config.ComponentID, nextConsumer consumer.Logs, set component.ReceiverCreateSettings, attrs *receiverhelper.MetadataAttributes) *Receiver {
 	return &Receiver{
 		nextConsumer: nextConsumer,
+		attrs:        attrs,
 		obsrecv: obsreport.NewReceiver(obsreport.ReceiverSettings{
 			ReceiverID:             id,
 			Transport:              receiverTransport,
@@ -49,12 +52,19 @@ func New(id config.ComponentID, nextConsumer consumer.Logs, set component.Receiv
 
 // Export implements the service Export logs func.
 func (r *Receiver) Export(ctx context.Context, req plogotlp.Request) (plogotlp.Response, error) {
+	return r.ExportWithMetadata(ctx, req, receiverhelper.GRPCMetadata(ctx))
+}
+
+// ExportWithMetadata exports the logs, after copying the transport metadata returned by getMetadata
+// into their resource attributes, as configured.
+func (r *Receiver) ExportWithMetadata(ctx context.Context, req plogotlp.Request, getMetadata receiverhelper.MetadataFunc) (plogotlp.Response, error) {
 	ld := req.Logs()
 	numSpans := ld.LogRecordCount()
 	if numSpans == 0 {
 		return plogotlp.NewResponse(), nil
 	}
 
+	r.attrs.Logs(getMetadata, ld)
 	ctx = r.obsrecv.StartLogsOp(ctx)
 	err := r.nextConsumer.ConsumeLogs(ctx, ld)
 	r.obsrecv.EndLogsOp(ctx, dataFormatProtobuf, numSpans, err)
@@ -22,6 +22,7 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/obsreport"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
 )
 
 const (
@@ -33,12 +34,14 @@ const (
 type Receiver struct {
 	nextConsumer consumer.Metrics
 	obsrecv      *obsreport.Receiver
+	attrs        *receiverhelper.MetadataAttributes
 }
 
 // New creates a new Receiver reference.
-func New(id config.ComponentID, nextConsumer consumer.Metrics, set component.ReceiverCreateSettings) *Receiver {
+func New(id config.ComponentID, nextConsumer consumer.Metrics, set component.ReceiverCreateSettings, attrs *receiverhelper.MetadataAttributes) *Receiver {
 	return &Receiver{
 		nextConsumer: nextConsumer,
+		attrs:        attrs,
 		obsrecv: obsreport.NewReceiver(obsreport.ReceiverSettings{
 			ReceiverID:             id,
 			Transport:              receiverTransport,
@@ -49,12 +52,19 @@ func New(id config.ComponentID, nextConsumer consumer.Metrics, set component.Rec
 
 // Export implements the service Export metrics func.
 func (r *Receiver) Export(ctx context.Context, req pmetricotlp.Request) (pmetricotlp.Response, error) {
+	return r.ExportWithMetadata(ctx, req, receiverhelper.GRPCMetadata(ctx))
+}
+
+// ExportWithMetadata exports the metrics, after copying the transport metadata returned by getMetadata
+// into their resource attributes, as configured.
+func (r *Receiver) ExportWithMetadata(ctx context.Context, req pmetricotlp.Request, getMetadata receiverhelper.MetadataFunc) (pmetricotlp.Response, error) {
 	md := req.Metrics()
 	dataPointCount := md.DataPointCount()
 	if dataPointCount == 0 {
 		return pmetricotlp.NewResponse(), nil
 	}
 
+	r.attrs.Metrics(getMetadata, md)
 	ctx = r.obsrecv.StartMetricsOp(ctx)
 	err := r.nextConsumer.ConsumeMetrics(ctx, md)
 	r.obsrecv.EndMetricsOp(ctx, dataFormatProtobuf, dataPointCount, err)
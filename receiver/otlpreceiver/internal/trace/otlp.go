@@ -22,6 +22,7 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/obsreport"
 	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
 )
 
 const (
@@ -33,12 +34,14 @@ const (
 type Receiver struct {
 	nextConsumer consumer.Traces
 	obsrecv      *obsreport.Receiver
+	attrs        *receiverhelper.MetadataAttributes
 }
 
 // New creates a new Receiver reference.
-func New(id config.ComponentID, nextConsumer consumer.Traces, set component.ReceiverCreateSettings) *Receiver {
+func New(id config.ComponentID, nextConsumer consumer.Traces, set component.ReceiverCreateSettings, attrs *receiverhelper.MetadataAttributes) *Receiver {
 	return &Receiver{
 		nextConsumer: nextConsumer,
+		attrs:        attrs,
 		obsrecv: obsreport.NewReceiver(obsreport.ReceiverSettings{
 			ReceiverID:             id,
 			Transport:              receiverTransport,
@@ -49,6 +52,12 @@ func New(id config.ComponentID, nextConsumer consumer.Traces, set component.Rece
 
 // Export implements the service Export traces func.
 func (r *Receiver) Export(ctx context.Context, req ptraceotlp.Request) (ptraceotlp.Response, error) {
+	return r.ExportWithMetadata(ctx, req, receiverhelper.GRPCMetadata(ctx))
+}
+
+// ExportWithMetadata exports the traces, after copying the transport metadata returned by getMetadata
+// into their resource attributes, as configured.
+func (r *Receiver) ExportWithMetadata(ctx context.Context, req ptraceotlp.Request, getMetadata receiverhelper.MetadataFunc) (ptraceotlp.Response, error) {
 	td := req.Traces()
 	// We need to ensure that it propagates the receiver name as a tag
 	numSpans := td.SpanCount()
@@ -56,6 +65,7 @@ func (r *Receiver) Export(ctx context.Context, req ptraceotlp.Request) (ptraceot
 		return ptraceotlp.NewResponse(), nil
 	}
 
+	r.attrs.Traces(getMetadata, td)
 	ctx = r.obsrecv.StartTracesOp(ctx)
 	err := r.nextConsumer.ConsumeTraces(ctx, td)
 	r.obsrecv.EndTracesOp(ctx, dataFormatProtobuf, numSpans, err)
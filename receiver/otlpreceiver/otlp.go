@@ -34,6 +34,7 @@ import (
 	"go.opentelemetry.io/collector/receiver/otlpreceiver/internal/logs"
 	"go.opentelemetry.io/collector/receiver/otlpreceiver/internal/metrics"
 	"go.opentelemetry.io/collector/receiver/otlpreceiver/internal/trace"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
 )
 
 // otlpReceiver is the type that exposes Trace and Metrics reception.
@@ -48,6 +49,7 @@ type otlpReceiver struct {
 	logReceiver     *logs.Receiver
 	shutdownWG      sync.WaitGroup
 
+	attrs    *receiverhelper.MetadataAttributes
 	settings component.ReceiverCreateSettings
 }
 
@@ -57,6 +59,7 @@ type otlpReceiver struct {
 func newOtlpReceiver(cfg *Config, settings component.ReceiverCreateSettings) *otlpReceiver {
 	r := &otlpReceiver{
 		cfg:      cfg,
+		attrs:    receiverhelper.NewMetadataAttributes(cfg.MetadataAttributes),
 		settings: settings,
 	}
 	if cfg.HTTP != nil {
@@ -155,16 +158,21 @@ func (r *otlpReceiver) Start(_ context.Context, host component.Host) error {
 	return r.startProtocolServers(host)
 }
 
-// Shutdown is a method to turn off receiving.
+// Shutdown is a method to turn off receiving. Both servers stop accepting new
+// connections and are drained concurrently, letting the in-flight requests complete.
 func (r *otlpReceiver) Shutdown(ctx context.Context) error {
 	var err error
 
-	if r.serverHTTP != nil {
-		err = r.serverHTTP.Shutdown(ctx)
+	if r.serverGRPC != nil {
+		r.shutdownWG.Add(1)
+		go func() {
+			defer r.shutdownWG.Done()
+			r.cfg.GRPC.ShutdownServer(ctx, r.serverGRPC)
+		}()
 	}
 
-	if r.serverGRPC != nil {
-		r.serverGRPC.GracefulStop()
+	if r.serverHTTP != nil {
+		err = r.cfg.HTTP.ShutdownServer(ctx, r.serverHTTP)
 	}
 
 	r.shutdownWG.Wait()
@@ -175,14 +183,14 @@ func (r *otlpReceiver) registerTraceConsumer(tc consumer.Traces) error {
 	if tc == nil {
 		return component.ErrNilNextConsumer
 	}
-	r.traceReceiver = trace.New(r.cfg.ID(), tc, r.settings)
+	r.traceReceiver = trace.New(r.cfg.ID(), tc, r.settings, r.attrs)
 	if r.httpMux != nil {
 		r.httpMux.HandleFunc("/v1/traces", func(resp http.ResponseWriter, req *http.Request) {
 			if req.Method != http.MethodPost {
 				handleUnmatchedMethod(resp)
 				return
 			}
-			switch req.Header.Get("Content-Type") {
+			switch getMimeTypeFromContentType(req.Header.Get("Content-Type")) {
 			case pbContentType:
 				handleTraces(resp, req, r.traceReceiver, pbEncoder)
 			case jsonContentType:
@@ -199,14 +207,14 @@ func (r *otlpReceiver) registerMetricsConsumer(mc consumer.Metrics) error {
 	if mc == nil {
 		return component.ErrNilNextConsumer
 	}
-	r.metricsReceiver = metrics.New(r.cfg.ID(), mc, r.settings)
+	r.metricsReceiver = metrics.New(r.cfg.ID(), mc, r.settings, r.attrs)
 	if r.httpMux != nil {
 		r.httpMux.HandleFunc("/v1/metrics", func(resp http.ResponseWriter, req *http.Request) {
 			if req.Method != http.MethodPost {
 				handleUnmatchedMethod(resp)
 				return
 			}
-			switch req.Header.Get("Content-Type") {
+			switch getMimeTypeFromContentType(req.Header.Get("Content-Type")) {
 			case pbContentType:
 				handleMetrics(resp, req, r.metricsReceiver, pbEncoder)
 			case jsonContentType:
@@ -223,14 +231,14 @@ func (r *otlpReceiver) registerLogsConsumer(lc consumer.Logs) error {
 	if lc == nil {
 		return component.ErrNilNextConsumer
 	}
-	r.logReceiver = logs.New(r.cfg.ID(), lc, r.settings)
+	r.logReceiver = logs.New(r.cfg.ID(), lc, r.settings, r.attrs)
 	if r.httpMux != nil {
 		r.httpMux.HandleFunc("/v1/logs", func(resp http.ResponseWriter, req *http.Request) {
 			if req.Method != http.MethodPost {
 				handleUnmatchedMethod(resp)
 				return
 			}
-			switch req.Header.Get("Content-Type") {
+			switch getMimeTypeFromContentType(req.Header.Get("Content-Type")) {
 			case pbContentType:
 				handleLogs(resp, req, r.logReceiver, pbEncoder)
 			case jsonContentType:
@@ -34,12 +34,14 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configbytes"
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/confignet"
@@ -54,6 +56,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
 	semconv "go.opentelemetry.io/collector/semconv/v1.5.0"
 )
 
@@ -137,9 +140,10 @@ var traceOtlp = func() ptrace.Traces {
 
 func TestJsonHttp(t *testing.T) {
 	tests := []struct {
-		name     string
-		encoding string
-		err      error
+		name        string
+		encoding    string
+		contentType string
+		err         error
 	}{
 		{
 			name:     "JSONUncompressed",
@@ -149,6 +153,11 @@ func TestJsonHttp(t *testing.T) {
 			name:     "JSONGzipCompressed",
 			encoding: "gzip",
 		},
+		{
+			name:        "JSONWithCharset",
+			encoding:    "",
+			contentType: "application/json; charset=utf-8",
+		},
 		{
 			name:     "NotGRPCError",
 			encoding: "",
@@ -177,7 +186,7 @@ func TestJsonHttp(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			url := fmt.Sprintf("http://%s/v1/traces", addr)
 			sink.Reset()
-			testHTTPJSONRequest(t, url, sink, test.encoding, test.err)
+			testHTTPJSONRequest(t, url, sink, test.encoding, test.contentType, test.err)
 		})
 	}
 }
@@ -335,7 +344,7 @@ func TestHandleInvalidRequests(t *testing.T) {
 	require.NoError(t, err)
 }
 
-func testHTTPJSONRequest(t *testing.T, url string, sink *errOrSinkConsumer, encoding string, expectedErr error) {
+func testHTTPJSONRequest(t *testing.T, url string, sink *errOrSinkConsumer, encoding string, contentType string, expectedErr error) {
 	var buf *bytes.Buffer
 	var err error
 	switch encoding {
@@ -348,7 +357,10 @@ func testHTTPJSONRequest(t *testing.T, url string, sink *errOrSinkConsumer, enco
 	sink.SetConsumeError(expectedErr)
 	req, err := http.NewRequest("POST", url, buf)
 	require.NoError(t, err, "Error creating trace POST request: %v", err)
-	req.Header.Set("Content-Type", "application/json")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Content-Encoding", encoding)
 
 	client := &http.Client{}
@@ -777,6 +789,57 @@ func TestGRPCMaxRecvSize(t *testing.T) {
 	assert.Equal(t, td, sink.AllTraces()[0])
 }
 
+func TestMetadataAttributes(t *testing.T) {
+	grpcAddr := testutil.GetAvailableLocalAddress(t)
+	httpAddr := testutil.GetAvailableLocalAddress(t)
+	sink := new(consumertest.TracesSink)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPC.NetAddr.Endpoint = grpcAddr
+	cfg.HTTP.Endpoint = httpAddr
+	cfg.MetadataAttributes = []receiverhelper.MetadataAttribute{
+		{Key: "X-Tenant", Attribute: "tenant"},
+		{Key: "x-region"},
+	}
+	ocr := newReceiver(t, factory, cfg, sink, nil)
+	require.NoError(t, ocr.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, ocr.Shutdown(context.Background())) })
+
+	cc, err := grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, cc.Close())
+	}()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-tenant", "acme")
+	_, err = ptraceotlp.NewClient(cc).Export(ctx, ptraceotlp.NewRequestFromTraces(testdata.GenerateTraces(1)))
+	require.NoError(t, err)
+
+	traceBytes, err := ptrace.NewProtoMarshaler().MarshalTraces(testdata.GenerateTraces(1))
+	require.NoError(t, err)
+	req := createHTTPProtobufRequest(t, fmt.Sprintf("http://%s/v1/traces", httpAddr), "", traceBytes)
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Add("X-Region", "eu")
+	req.Header.Add("X-Region", "us")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Len(t, sink.AllTraces(), 2)
+	grpcAttrs := sink.AllTraces()[0].ResourceSpans().At(0).Resource().Attributes()
+	assert.Equal(t, map[string]interface{}{
+		"resource-attr": "resource-attr-val-1",
+		"tenant":        "acme",
+	}, grpcAttrs.AsRaw())
+	httpAttrs := sink.AllTraces()[1].ResourceSpans().At(0).Resource().Attributes()
+	assert.Equal(t, map[string]interface{}{
+		"resource-attr": "resource-attr-val-1",
+		"tenant":        "acme",
+		"x-region":      []interface{}{"eu", "us"},
+	}, httpAttrs.AsRaw())
+}
+
 func TestHTTPInvalidTLSCredentials(t *testing.T) {
 	cfg := &Config{
 		ReceiverSettings: config.NewReceiverSettings(config.NewComponentID(typeStr)),
@@ -812,7 +875,7 @@ func testHTTPMaxRequestBodySizeJSON(t *testing.T, payload []byte, size int, expe
 		Protocols: Protocols{
 			HTTP: &confighttp.HTTPServerSettings{
 				Endpoint:           endpoint,
-				MaxRequestBodySize: int64(size),
+				MaxRequestBodySize: configbytes.ByteSize(size),
 			},
 		},
 	}
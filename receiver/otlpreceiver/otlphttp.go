@@ -16,6 +16,7 @@ package otlpreceiver // import "go.opentelemetry.io/collector/receiver/otlprecei
 
 import (
 	"io"
+	"mime"
 	"net/http"
 
 	spb "google.golang.org/genproto/googleapis/rpc/status"
@@ -44,7 +45,7 @@ func handleTraces(resp http.ResponseWriter, req *http.Request, tracesReceiver *t
 		return
 	}
 
-	otlpResp, err := tracesReceiver.Export(req.Context(), otlpReq)
+	otlpResp, err := tracesReceiver.ExportWithMetadata(req.Context(), otlpReq, req.Header.Values)
 	if err != nil {
 		writeError(resp, encoder, err, http.StatusInternalServerError)
 		return
@@ -70,7 +71,7 @@ func handleMetrics(resp http.ResponseWriter, req *http.Request, metricsReceiver
 		return
 	}
 
-	otlpResp, err := metricsReceiver.Export(req.Context(), otlpReq)
+	otlpResp, err := metricsReceiver.ExportWithMetadata(req.Context(), otlpReq, req.Header.Values)
 	if err != nil {
 		writeError(resp, encoder, err, http.StatusInternalServerError)
 		return
@@ -96,7 +97,7 @@ func handleLogs(resp http.ResponseWriter, req *http.Request, logsReceiver *logs.
 		return
 	}
 
-	otlpResp, err := logsReceiver.Export(req.Context(), otlpReq)
+	otlpResp, err := logsReceiver.ExportWithMetadata(req.Context(), otlpReq, req.Header.Values)
 	if err != nil {
 		writeError(resp, encoder, err, http.StatusInternalServerError)
 		return
@@ -136,8 +137,7 @@ func writeError(w http.ResponseWriter, encoder encoder, err error, statusCode in
 // by the OTLP protocol.
 func errorHandler(w http.ResponseWriter, r *http.Request, errMsg string, statusCode int) {
 	s := errorMsgToStatus(errMsg, statusCode)
-	contentType := r.Header.Get("Content-Type")
-	switch contentType {
+	switch getMimeTypeFromContentType(r.Header.Get("Content-Type")) {
 	case pbContentType:
 		writeStatusResponse(w, pbEncoder, statusCode, s.Proto())
 		return
@@ -171,3 +171,13 @@ func errorMsgToStatus(errMsg string, statusCode int) *status.Status {
 	}
 	return status.New(codes.Unknown, errMsg)
 }
+
+// getMimeTypeFromContentType returns the media type of the Content-Type header, without its
+// parameters such as the charset, e.g. "application/json; charset=utf-8" returns "application/json".
+func getMimeTypeFromContentType(contentType string) string {
+	mediatype, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return mediatype
+}
@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package receiverhelper // import "go.opentelemetry.io/collector/receiver/receiverhelper"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// MetadataAttribute configures the copy of a transport metadata entry, i.e. a gRPC metadata
+// or an HTTP header, into a resource attribute of the received data.
+type MetadataAttribute struct {
+	// Key is the name of the gRPC metadata or HTTP header, case-insensitive.
+	Key string `mapstructure:"key"`
+
+	// Attribute is the name of the resource attribute. If empty, Key is used.
+	Attribute string `mapstructure:"attribute"`
+}
+
+// ValidateMetadataAttributes checks that the keys and the attributes are set, and unique.
+func ValidateMetadataAttributes(cfg []MetadataAttribute) error {
+	keys := make(map[string]struct{}, len(cfg))
+	attrs := make(map[string]struct{}, len(cfg))
+	for _, ma := range cfg {
+		if ma.Key == "" {
+			return errors.New("metadata attribute key must not be empty")
+		}
+		key := strings.ToLower(ma.Key)
+		if _, ok := keys[key]; ok {
+			return fmt.Errorf("duplicate metadata attribute key %q", ma.Key)
+		}
+		keys[key] = struct{}{}
+		attr := ma.attribute()
+		if _, ok := attrs[attr]; ok {
+			return fmt.Errorf("duplicate metadata attribute %q", attr)
+		}
+		attrs[attr] = struct{}{}
+	}
+	return nil
+}
+
+func (ma MetadataAttribute) attribute() string {
+	if ma.Attribute == "" {
+		return ma.Key
+	}
+	return ma.Attribute
+}
+
+// MetadataFunc returns the values of the given transport metadata key, nil if not set.
+type MetadataFunc func(key string) []string
+
+// GRPCMetadata returns the MetadataFunc of the incoming gRPC metadata of the context.
+func GRPCMetadata(ctx context.Context) MetadataFunc {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return md.Get
+}
+
+// MetadataAttributes copies selected transport metadata into the resource attributes of the
+// received data. The values of the metadata replace the values of the existing attributes, and
+// the metadata not set leave the attributes unchanged. A nil MetadataAttributes copies nothing.
+type MetadataAttributes struct {
+	attrs []MetadataAttribute
+}
+
+// NewMetadataAttributes returns the MetadataAttributes for the given configuration, nil if empty.
+func NewMetadataAttributes(cfg []MetadataAttribute) *MetadataAttributes {
+	if len(cfg) == 0 {
+		return nil
+	}
+	return &MetadataAttributes{attrs: cfg}
+}
+
+// Traces copies the metadata returned by md into the resource attributes of td.
+func (ma *MetadataAttributes) Traces(md MetadataFunc, td ptrace.Traces) {
+	if ma == nil {
+		return
+	}
+	rss := td.ResourceSpans()
+	ma.apply(md, rss.Len(), func(i int) pcommon.Map { return rss.At(i).Resource().Attributes() })
+}
+
+// Metrics copies the metadata returned by md into the resource attributes of mds.
+func (ma *MetadataAttributes) Metrics(md MetadataFunc, mds pmetric.Metrics) {
+	if ma == nil {
+		return
+	}
+	rms := mds.ResourceMetrics()
+	ma.apply(md, rms.Len(), func(i int) pcommon.Map { return rms.At(i).Resource().Attributes() })
+}
+
+// Logs copies the metadata returned by md into the resource attributes of ld.
+func (ma *MetadataAttributes) Logs(md MetadataFunc, ld plog.Logs) {
+	if ma == nil {
+		return
+	}
+	rls := ld.ResourceLogs()
+	ma.apply(md, rls.Len(), func(i int) pcommon.Map { return rls.At(i).Resource().Attributes() })
+}
+
+func (ma *MetadataAttributes) apply(md MetadataFunc, numResources int, resourceAttrs func(i int) pcommon.Map) {
+	for _, a := range ma.attrs {
+		values := md(a.Key)
+		if len(values) == 0 {
+			continue
+		}
+		for i := 0; i < numResources; i++ {
+			attrs := resourceAttrs(i)
+			if len(values) == 1 {
+				attrs.UpsertString(a.attribute(), values[0])
+				continue
+			}
+			v := pcommon.NewValueSlice()
+			for _, value := range values {
+				v.SliceVal().AppendEmpty().SetStringVal(value)
+			}
+			attrs.Upsert(a.attribute(), v)
+		}
+	}
+}
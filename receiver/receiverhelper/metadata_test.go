@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package receiverhelper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestValidateMetadataAttributes(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    []MetadataAttribute
+		expect string
+	}{
+		{
+			name: "valid",
+			cfg:  []MetadataAttribute{{Key: "x-tenant", Attribute: "tenant"}, {Key: "x-region"}},
+		},
+		{
+			name:   "empty key",
+			cfg:    []MetadataAttribute{{Attribute: "tenant"}},
+			expect: "metadata attribute key must not be empty",
+		},
+		{
+			name:   "duplicate key",
+			cfg:    []MetadataAttribute{{Key: "X-Tenant", Attribute: "a"}, {Key: "x-tenant", Attribute: "b"}},
+			expect: `duplicate metadata attribute key "x-tenant"`,
+		},
+		{
+			name:   "duplicate attribute",
+			cfg:    []MetadataAttribute{{Key: "x-tenant", Attribute: "tenant"}, {Key: "tenant"}},
+			expect: `duplicate metadata attribute "tenant"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetadataAttributes(tt.cfg)
+			if tt.expect == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expect)
+		})
+	}
+}
+
+func TestNewMetadataAttributesEmpty(t *testing.T) {
+	ma := NewMetadataAttributes(nil)
+	assert.Nil(t, ma)
+
+	td := ptrace.NewTraces()
+	td.ResourceSpans().AppendEmpty().Resource().Attributes().UpsertString("k", "v")
+	ma.Traces(func(string) []string { return []string{"value"} }, td)
+	assert.Equal(t, map[string]interface{}{"k": "v"}, td.ResourceSpans().At(0).Resource().Attributes().AsRaw())
+}
+
+func TestMetadataAttributes(t *testing.T) {
+	ma := NewMetadataAttributes([]MetadataAttribute{
+		{Key: "x-tenant", Attribute: "tenant"},
+		{Key: "x-region"},
+		{Key: "x-missing"},
+	})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"X-Tenant", "acme",
+		"x-region", "eu",
+		"x-region", "us",
+	))
+	expected := map[string]interface{}{
+		"tenant":   "acme",
+		"x-region": []interface{}{"eu", "us"},
+	}
+
+	td := ptrace.NewTraces()
+	td.ResourceSpans().AppendEmpty().Resource().Attributes().UpsertString("tenant", "other")
+	td.ResourceSpans().AppendEmpty()
+	ma.Traces(GRPCMetadata(ctx), td)
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		assert.Equal(t, expected, td.ResourceSpans().At(i).Resource().Attributes().AsRaw())
+	}
+
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty()
+	ma.Metrics(GRPCMetadata(ctx), md)
+	assert.Equal(t, expected, md.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty()
+	ma.Logs(GRPCMetadata(ctx), ld)
+	assert.Equal(t, expected, ld.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+}
+
+func TestGRPCMetadataNoMetadata(t *testing.T) {
+	assert.Nil(t, GRPCMetadata(context.Background())("x-tenant"))
+}
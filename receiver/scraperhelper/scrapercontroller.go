@@ -25,6 +25,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/experimental/leaderelection"
 	"go.opentelemetry.io/collector/obsreport"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/receiver/scrapererror"
@@ -36,6 +37,10 @@ import (
 type ScraperControllerSettings struct {
 	config.ReceiverSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
 	CollectionInterval      time.Duration            `mapstructure:"collection_interval"`
+
+	// LeaderElection is the ID of a leader election extension, when set the scrapers are only
+	// called while this collector is the leader of its group.
+	LeaderElection *config.ComponentID `mapstructure:"leader_election"`
 }
 
 // NewDefaultScraperControllerSettings returns default scraper controller
@@ -76,6 +81,9 @@ type controller struct {
 	collectionInterval time.Duration
 	nextConsumer       consumer.Metrics
 
+	leaderElectionID *config.ComponentID
+	leaderElection   leaderelection.Extension
+
 	scrapers []Scraper
 
 	tickerCh <-chan time.Time
@@ -108,6 +116,7 @@ func NewScraperControllerReceiver(
 		logger:             set.Logger,
 		collectionInterval: cfg.CollectionInterval,
 		nextConsumer:       nextConsumer,
+		leaderElectionID:   cfg.LeaderElection,
 		done:               make(chan struct{}),
 		terminated:         make(chan struct{}),
 		obsrecv: obsreport.NewReceiver(obsreport.ReceiverSettings{
@@ -127,6 +136,14 @@ func NewScraperControllerReceiver(
 
 // Start the receiver, invoked during service start.
 func (sc *controller) Start(ctx context.Context, host component.Host) error {
+	if sc.leaderElectionID != nil {
+		le, err := leaderelection.GetExtension(host.GetExtensions(), *sc.leaderElectionID)
+		if err != nil {
+			return err
+		}
+		sc.leaderElection = le
+	}
+
 	for _, scraper := range sc.scrapers {
 		if err := scraper.Start(ctx, host); err != nil {
 			return err
@@ -169,6 +186,10 @@ func (sc *controller) startScraping() {
 		for {
 			select {
 			case <-sc.tickerCh:
+				if sc.leaderElection != nil && !sc.leaderElection.IsLeader() {
+					sc.logger.Debug("Skipping the scrape, this collector is not the leader")
+					continue
+				}
 				sc.scrapeMetricsAndReport(context.Background())
 			case <-sc.done:
 				sc.terminated <- struct{}{}
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/codes"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/atomic"
 	"go.uber.org/multierr"
 
 	"go.opentelemetry.io/collector/component"
@@ -363,3 +364,69 @@ func TestSingleScrapePerTick(t *testing.T) {
 		return
 	}
 }
+
+type mockHost struct {
+	component.Host
+	ext map[config.ComponentID]component.Extension
+}
+
+func (nh *mockHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return nh.ext
+}
+
+type mockLeaderElection struct {
+	component.StartFunc
+	component.ShutdownFunc
+	leader *atomic.Bool
+}
+
+func (mle *mockLeaderElection) IsLeader() bool {
+	return mle.leader.Load()
+}
+
+func TestScrapeOnlyWhenLeader(t *testing.T) {
+	scrapeMetricsCh := make(chan int, 10)
+	tsm := &testScrapeMetrics{ch: scrapeMetricsCh}
+
+	leID := config.NewComponentID("leader_election")
+	cfg := NewDefaultScraperControllerSettings("")
+	cfg.LeaderElection = &leID
+
+	tickerCh := make(chan time.Time)
+
+	scp, err := NewScraper("", tsm.scrape)
+	assert.NoError(t, err)
+
+	receiver, err := NewScraperControllerReceiver(
+		&cfg,
+		componenttest.NewNopReceiverCreateSettings(),
+		new(consumertest.MetricsSink),
+		AddScraper(scp),
+		WithTickerChannel(tickerCh),
+	)
+	require.NoError(t, err)
+
+	assert.EqualError(t, receiver.Start(context.Background(), componenttest.NewNopHost()),
+		"failed to resolve leader election \"leader_election\": leader election extension not found")
+
+	le := &mockLeaderElection{leader: atomic.NewBool(false)}
+	host := &mockHost{
+		Host: componenttest.NewNopHost(),
+		ext:  map[config.ComponentID]component.Extension{leID: le},
+	}
+	require.NoError(t, receiver.Start(context.Background(), host))
+
+	// The ticker channel is unbuffered, the second tick is sent after the first one is handled.
+	tickerCh <- time.Now()
+	tickerCh <- time.Now()
+	select {
+	case <-scrapeMetricsCh:
+		assert.Fail(t, "Scrape was called while not the leader")
+	default:
+	}
+
+	le.leader.Store(true)
+	tickerCh <- time.Now()
+	assert.Equal(t, 1, <-scrapeMetricsCh)
+	require.NoError(t, receiver.Shutdown(context.Background()))
+}
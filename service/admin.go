@@ -0,0 +1,191 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/internal/pipelines"
+)
+
+const (
+	adminPipelinesPath = "/v1/pipelines"
+	adminPausePath     = "/v1/pipelines/pause"
+	adminResumePath    = "/v1/pipelines/resume"
+	adminStatePath     = "/v1/state"
+	adminPipelineParam = "pipeline"
+
+	// adminReadHeaderTimeout bounds the time to read the headers of the requests to the admin API.
+	adminReadHeaderTimeout = 10 * time.Second
+	adminBearerPrefix      = "Bearer "
+)
+
+// AdminSettings configures the admin API of the Collector, used to operate it at runtime
+// without changing its configuration.
+type AdminSettings struct {
+	// Endpoint is the address the admin API listens on, e.g. "localhost:13134".
+	// Empty disables the admin API.
+	Endpoint string
+
+	// Token is the bearer token the clients of the admin API must present in the
+	// Authorization header. It is required when Endpoint is set.
+	Token string
+}
+
+// adminServer serves the admin API of the Collector.
+type adminServer struct {
+	token  string
+	pauses *pipelines.PauseRegistry
+	state  func(context.Context) (runtimeState, error)
+	logger *zap.Logger
+	server *http.Server
+	// done is closed by shutdown, so that the serving errors are not reported afterwards.
+	done chan struct{}
+}
+
+// adminPipelineStatus is the JSON document describing a pipeline in the admin API responses.
+type adminPipelineStatus struct {
+	Pipeline string `json:"pipeline"`
+	Paused   bool   `json:"paused"`
+}
+
+func newAdminServer(set AdminSettings, pauses *pipelines.PauseRegistry, state func(context.Context) (runtimeState, error), logger *zap.Logger) *adminServer {
+	as := &adminServer{
+		token:  set.Token,
+		pauses: pauses,
+		state:  state,
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(adminPipelinesPath, as.authenticate(as.handlePipelines))
+	mux.HandleFunc(adminPausePath, as.authenticate(as.handlePause))
+	mux.HandleFunc(adminResumePath, as.authenticate(as.handleResume))
+	mux.HandleFunc(adminStatePath, as.authenticate(as.handleState))
+	as.server = &http.Server{Handler: mux, ReadHeaderTimeout: adminReadHeaderTimeout}
+	return as
+}
+
+// start listens on the endpoint and serves the admin API in the background. Errors happening
+// after the listener is created are reported to the asyncErrorChannel, unless shut down meanwhile.
+func (as *adminServer) start(endpoint string, asyncErrorChannel chan error) error {
+	ln, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	as.logger.Info("Admin API listening", zap.String("endpoint", ln.Addr().String()))
+	go as.serve(ln, asyncErrorChannel)
+	return nil
+}
+
+// serve serves the admin API on the listener, until it fails or the server is shut down.
+func (as *adminServer) serve(ln net.Listener, asyncErrorChannel chan error) {
+	if serveErr := as.server.Serve(ln); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+		select {
+		case asyncErrorChannel <- serveErr:
+		case <-as.done:
+		}
+	}
+}
+
+func (as *adminServer) shutdown() error {
+	close(as.done)
+	return as.server.Close()
+}
+
+// authenticate rejects the requests not presenting the token of the admin API.
+func (as *adminServer) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, adminBearerPrefix) ||
+			subtle.ConstantTimeCompare([]byte(authorization[len(adminBearerPrefix):]), []byte(as.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid or missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (as *adminServer) handlePipelines(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var statuses []adminPipelineStatus
+	for _, id := range as.pauses.Pipelines() {
+		statuses = append(statuses, adminPipelineStatus{Pipeline: id.String(), Paused: as.pauses.Paused(id)})
+	}
+	writeAdminResponse(w, statuses)
+}
+
+func (as *adminServer) handlePause(w http.ResponseWriter, r *http.Request) {
+	as.handlePipelineChange(w, r, "Pipeline paused", as.pauses.Pause)
+}
+
+func (as *adminServer) handleResume(w http.ResponseWriter, r *http.Request) {
+	as.handlePipelineChange(w, r, "Pipeline resumed", as.pauses.Resume)
+}
+
+// handlePipelineChange applies the change to the pipeline set in the query of the request,
+// and responds with the resulting status of the pipeline.
+func (as *adminServer) handlePipelineChange(w http.ResponseWriter, r *http.Request, msg string, change func(config.ComponentID) error) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, err := config.NewComponentIDFromString(r.URL.Query().Get(adminPipelineParam))
+	if err != nil {
+		http.Error(w, "invalid pipeline: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = change(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	as.logger.Info(msg, zap.Stringer("pipeline", id))
+	writeAdminResponse(w, adminPipelineStatus{Pipeline: id.String(), Paused: as.pauses.Paused(id)})
+}
+
+func (as *adminServer) handleState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	state, err := as.state(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	writeAdminResponse(w, state)
+}
+
+func writeAdminResponse(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
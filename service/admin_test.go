@@ -0,0 +1,175 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/internal/testutil"
+	"go.opentelemetry.io/collector/service/featuregate"
+)
+
+func TestCollectorAdminRequiresToken(t *testing.T) {
+	cfgProvider, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{filepath.Join("testdata", "otelcol-nop.yaml")}))
+	require.NoError(t, err)
+
+	_, err = New(CollectorSettings{
+		ConfigProvider: cfgProvider,
+		Admin:          AdminSettings{Endpoint: "localhost:0"},
+	})
+	assert.EqualError(t, err, "invalid admin settings: a token is required to enable the admin API")
+}
+
+func TestAdminServerServeError(t *testing.T) {
+	as := newAdminServer(AdminSettings{Token: "secret"}, nil, nil, zap.NewNop())
+	assert.Equal(t, adminReadHeaderTimeout, as.server.ReadHeaderTimeout)
+
+	// The serving error is reported to the asyncErrorChannel.
+	asyncErrorChannel := make(chan error, 1)
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+	as.serve(ln, asyncErrorChannel)
+	assert.Error(t, <-asyncErrorChannel)
+
+	// Once shut down, nobody receives from the asyncErrorChannel, and the error is not reported.
+	close(as.done)
+	ln, err = net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+	as.serve(ln, make(chan error))
+}
+
+func TestCollectorAdminPausePipelines(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	cfgProvider, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{filepath.Join("testdata", "otelcol-nop.yaml")}))
+	require.NoError(t, err)
+
+	endpoint := testutil.GetAvailableLocalAddress(t)
+	col, err := New(CollectorSettings{
+		BuildInfo:      component.NewDefaultBuildInfo(),
+		Factories:      factories,
+		ConfigProvider: cfgProvider,
+		Admin:          AdminSettings{Endpoint: endpoint, Token: "secret"},
+		telemetry:      newColTelemetry(featuregate.NewRegistry()),
+	})
+	require.NoError(t, err)
+
+	wg := startCollector(context.Background(), t, col)
+	defer func() {
+		col.Shutdown()
+		wg.Wait()
+	}()
+	assert.Eventually(t, func() bool {
+		return Running == col.GetState()
+	}, 2*time.Second, 200*time.Millisecond)
+
+	do := func(method, path, token string) (int, string) {
+		req, reqErr := http.NewRequest(method, "http://"+endpoint+path, nil)
+		require.NoError(t, reqErr)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, reqErr := http.DefaultClient.Do(req)
+		require.NoError(t, reqErr)
+		defer resp.Body.Close()
+		body, reqErr := io.ReadAll(resp.Body)
+		require.NoError(t, reqErr)
+		return resp.StatusCode, string(body)
+	}
+
+	status, _ := do(http.MethodGet, adminPipelinesPath, "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = do(http.MethodPost, adminPausePath+"?pipeline=traces", "wrong")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	// The token must be presented as a bearer token.
+	req, err := http.NewRequest(http.MethodGet, "http://"+endpoint+adminPipelinesPath, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.False(t, col.pauses.Paused(newPipelineID(t, "traces")))
+
+	status, body := do(http.MethodGet, adminPipelinesPath, "secret")
+	assert.Equal(t, http.StatusOK, status)
+	var statuses []adminPipelineStatus
+	require.NoError(t, json.Unmarshal([]byte(body), &statuses))
+	assert.Equal(t, []adminPipelineStatus{
+		{Pipeline: "logs"},
+		{Pipeline: "metrics"},
+		{Pipeline: "traces"},
+	}, statuses)
+
+	status, body = do(http.MethodPost, adminPausePath+"?pipeline=traces", "secret")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"pipeline":"traces","paused":true}`, body)
+	assert.True(t, col.pauses.Paused(newPipelineID(t, "traces")))
+	assert.False(t, col.pauses.Paused(newPipelineID(t, "logs")))
+
+	status, body = do(http.MethodGet, adminStatePath, "secret")
+	assert.Equal(t, http.StatusOK, status)
+	var state runtimeState
+	require.NoError(t, json.Unmarshal([]byte(body), &state))
+	assert.Equal(t, Running.String(), state.State)
+	assert.Equal(t, col.configHash(), state.ConfigHash)
+	require.Len(t, state.Pipelines, 3)
+	assert.Equal(t, pipelineState{
+		Pipeline:   "traces",
+		Paused:     true,
+		Receivers:  []string{"nop"},
+		Processors: []string{"nop"},
+		Exporters:  []string{"nop"},
+	}, state.Pipelines[2])
+	status, _ = do(http.MethodGet, adminStatePath, "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, body = do(http.MethodPost, adminResumePath+"?pipeline=traces", "secret")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"pipeline":"traces","paused":false}`, body)
+	assert.False(t, col.pauses.Paused(newPipelineID(t, "traces")))
+
+	status, body = do(http.MethodPost, adminPausePath+"?pipeline=traces/unknown", "secret")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "pipeline \"traces/unknown\" does not exist\n", body)
+	status, _ = do(http.MethodPost, adminPausePath, "secret")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = do(http.MethodGet, adminPausePath+"?pipeline=traces", "secret")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+	status, _ = do(http.MethodPost, adminPipelinesPath, "secret")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+}
+
+func newPipelineID(t *testing.T, id string) config.ComponentID {
+	pipelineID, err := config.NewComponentIDFromString(id)
+	require.NoError(t, err)
+	return pipelineID
+}
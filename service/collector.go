@@ -24,13 +24,18 @@ import (
 	"os/signal"
 	"runtime"
 	"syscall"
+	"time"
 
 	"go.uber.org/atomic"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/extension/ballastextension"
+	"go.opentelemetry.io/collector/service/featuregate"
+	"go.opentelemetry.io/collector/service/internal/pipelines"
+	"go.opentelemetry.io/collector/service/internal/telemetry"
 	"go.opentelemetry.io/collector/service/internal/telemetrylogs"
 )
 
@@ -82,18 +87,54 @@ type Collector struct {
 	// signalsChannel is used to receive termination signals from the OS.
 	signalsChannel chan os.Signal
 
+	// stateDumpChannel is used to receive the signals requesting a dump of the runtime state.
+	stateDumpChannel chan os.Signal
+
+	// stateRequests is used to request the runtime state from the goroutine running the collector.
+	stateRequests chan chan runtimeState
+
 	// asyncErrorChannel is used to signal a fatal error from any component.
 	asyncErrorChannel chan error
+
+	// loaded is the configuration of the running service.
+	loaded loadedConfig
+
+	// reloads counts the outcomes of the configuration reloads.
+	reloads *telemetry.ReloadCounters
+
+	// limiter paces the configuration reloads.
+	limiter *reloadLimiter
+	// retryReload is set by the reload failing to get the configuration because of a transient error.
+	retryReload bool
+
+	// pauses holds the paused pipelines, across configuration reloads.
+	pauses *pipelines.PauseRegistry
+
+	// admin serves the admin API, nil if disabled.
+	admin *adminServer
+
+	// status reports the outcomes of the configuration loads, nil if disabled.
+	status *configStatusReporter
 }
 
 // New creates and returns a new instance of Collector.
+//
+// Multiple Collector instances, each with its own settings and configuration, can be
+// created and run concurrently in the same process, but they are not isolated: the feature
+// gates, and the OpenCensus views, exporters and metric producers backing the internal
+// metrics, are process-wide. When the OpenCensus internal metrics are enabled, every
+// instance therefore exports the internal metrics recorded by all the instances.
 func New(set CollectorSettings) (*Collector, error) {
 	if set.ConfigProvider == nil {
 		return nil, errors.New("invalid nil config provider")
 	}
 
+	if set.Admin.Endpoint != "" && set.Admin.Token == "" {
+		return nil, errors.New("invalid admin settings: a token is required to enable the admin API")
+	}
+
 	if set.telemetry == nil {
-		set.telemetry = collectorTelemetry
+		set.telemetry = newColTelemetry(featuregate.GetRegistry())
 	}
 
 	return &Collector{
@@ -102,6 +143,11 @@ func New(set CollectorSettings) (*Collector, error) {
 		set:          set,
 		state:        atomic.NewInt32(int32(Starting)),
 		shutdownChan: make(chan struct{}),
+		reloads:      telemetry.NewReloadCounters(),
+		limiter:      newReloadLimiter(set.Reload),
+		pauses:       pipelines.NewPauseRegistry(),
+
+		stateRequests: make(chan chan runtimeState),
 	}, nil
 
 }
@@ -132,10 +178,24 @@ func (col *Collector) runAndWaitForShutdownEvent(ctx context.Context) error {
 	if !col.set.DisableGracefulShutdown {
 		signal.Notify(col.signalsChannel, os.Interrupt, syscall.SIGTERM)
 	}
+	col.stateDumpChannel = make(chan os.Signal, 1)
+	notifyStateDump(col.stateDumpChannel)
+
+	// reloadTimer fires when a deferred configuration reload is due, nil if no reload is pending.
+	var reloadTimer *time.Timer
+	defer func() {
+		if reloadTimer != nil {
+			reloadTimer.Stop()
+		}
+	}()
 
 	col.setCollectorState(Running)
 LOOP:
 	for {
+		var reloadDue <-chan time.Time
+		if reloadTimer != nil {
+			reloadDue = reloadTimer.C
+		}
 		select {
 		case err := <-col.set.ConfigProvider.Watch():
 			if err != nil {
@@ -143,21 +203,40 @@ LOOP:
 				break LOOP
 			}
 
-			col.service.telemetrySettings.Logger.Warn("Config updated, restart service")
-			col.setCollectorState(Closing)
-
-			if err = col.service.Shutdown(ctx); err != nil {
-				return fmt.Errorf("failed to shutdown the retiring config: %w", err)
+			// A pending reload gets the latest config, including this change, unless it only retries
+			// a reload that failed to get the config: the change is applied without waiting for it.
+			if reloadTimer != nil && !col.retryReload {
+				continue
+			}
+			if reloadTimer != nil {
+				reloadTimer.Stop()
+				reloadTimer = nil
+			}
+			if delay, held := col.limiter.delay(time.Now()); delay > 0 {
+				col.deferReload(delay, held)
+				reloadTimer = time.NewTimer(delay)
+				continue
 			}
-			if err = col.setupConfigurationComponents(ctx); err != nil {
-				return fmt.Errorf("failed to setup configuration components: %w", err)
+			if err = col.reload(ctx); err != nil {
+				return err
 			}
+			reloadTimer = col.retryReloadTimer()
+		case <-reloadDue:
+			reloadTimer = nil
+			if err := col.reload(ctx); err != nil {
+				return err
+			}
+			reloadTimer = col.retryReloadTimer()
 		case err := <-col.asyncErrorChannel:
 			col.service.telemetrySettings.Logger.Error("Asynchronous error received, terminating process", zap.Error(err))
 			break LOOP
 		case s := <-col.signalsChannel:
 			col.service.telemetrySettings.Logger.Info("Received signal from OS", zap.String("signal", s.String()))
 			break LOOP
+		case <-col.stateDumpChannel:
+			col.dumpState()
+		case reply := <-col.stateRequests:
+			reply <- col.runtimeState()
 		case <-col.shutdownChan:
 			col.service.telemetrySettings.Logger.Info("Received shutdown request")
 			break LOOP
@@ -171,27 +250,208 @@ LOOP:
 	return col.shutdown(ctx)
 }
 
+// retryReloadTimer returns a timer firing when the last reload is to be retried, nil if it doesn't
+// need to be retried.
+func (col *Collector) retryReloadTimer() *time.Timer {
+	if !col.retryReload {
+		return nil
+	}
+	col.service.telemetrySettings.Logger.Info("Config source temporarily unavailable, retrying the reload",
+		zap.Duration("delay", col.limiter.set.RetryInterval))
+	return time.NewTimer(col.limiter.set.RetryInterval)
+}
+
+// deferReload logs that a configuration change is applied after delay, because of the minimum
+// interval between reloads or because the configuration is flapping.
+func (col *Collector) deferReload(delay time.Duration, held bool) {
+	logger := col.service.telemetrySettings.Logger
+	if !held {
+		logger.Info("Config updated, reload deferred to respect the minimum interval between reloads",
+			zap.Duration("delay", delay))
+		return
+	}
+	col.reloads.SetFlapping(true)
+	logger.Warn("Config is flapping, holding the current config",
+		zap.Int("reloads", col.limiter.set.FlapThreshold),
+		zap.Duration("window", col.limiter.set.FlapWindow),
+		zap.Duration("delay", delay))
+}
+
+// reload records the reload in the limiter and reloads the configuration.
+func (col *Collector) reload(ctx context.Context) error {
+	col.limiter.record(time.Now())
+	if col.reloads.Flapping() {
+		col.reloads.SetFlapping(false)
+		col.service.telemetrySettings.Logger.Info("Config hold period elapsed, resuming reloads")
+	}
+	return col.reloadConfiguration(ctx)
+}
+
+// reloadConfiguration loads the updated config and restarts the components with it. If the updated config
+// cannot be loaded, the running components are kept. If the components fail to start with the updated config,
+// they are restarted with the previous config. In both cases the failure is logged and counted, by reason,
+// in the "config_reload_failures" metric. Returns an error only if the previous config cannot be restored.
+func (col *Collector) reloadConfiguration(ctx context.Context) error {
+	logger := col.service.telemetrySettings.Logger
+	logger.Warn("Config updated, restart service")
+
+	start := time.Now()
+	prev := col.loaded
+	prevCfgHash := prev.hash
+	loaded, err := col.getConfig(ctx)
+	col.retryReload = err != nil && confmap.IsRetryable(err)
+	if err != nil {
+		col.reloads.RecordFailure(telemetry.ReloadFailureConfig, time.Since(start))
+		logger.Error("Failed to get the updated config, keep running with the previous config", zap.Error(err))
+		col.auditConfig(logger, configTriggerWatcher, configOutcomeRejected, prevCfgHash, err)
+		col.reportConfigStatus(logger, configTriggerWatcher, configOutcomeRejected, prevCfgHash, err)
+		return nil
+	}
+
+	col.setCollectorState(Closing)
+	if err = col.service.Shutdown(ctx); err != nil {
+		col.reloads.RecordFailure(telemetry.ReloadFailureShutdown, time.Since(start))
+		err = fmt.Errorf("failed to shutdown the retiring config: %w", err)
+		col.auditConfig(logger, configTriggerWatcher, configOutcomeFailed, prevCfgHash, err)
+		col.reportConfigStatus(logger, configTriggerWatcher, configOutcomeFailed, prevCfgHash, err)
+		return err
+	}
+
+	if err = col.startService(ctx, loaded); err == nil {
+		duration := time.Since(start)
+		col.reloads.RecordSuccess(duration)
+		col.service.telemetrySettings.Logger.Info("Config reload applied",
+			zap.String("config_hash", loaded.hash),
+			zap.String("previous_config_hash", prevCfgHash),
+			zap.Duration("duration", duration))
+		col.saveLastKnownGood(col.service.telemetrySettings.Logger)
+		col.auditConfig(col.service.telemetrySettings.Logger, configTriggerWatcher, configOutcomeApplied, prevCfgHash, nil)
+		col.reportConfigStatus(col.service.telemetrySettings.Logger, configTriggerWatcher, configOutcomeApplied, prevCfgHash, nil)
+		col.setCollectorState(Running)
+		return nil
+	}
+
+	col.reloads.RecordFailure(telemetry.ReloadFailureStart, time.Since(start))
+	logger.Error("Failed to start with the updated config, rolling back to the previous config", zap.Error(err))
+	col.auditConfig(logger, configTriggerWatcher, configOutcomeRolledBack, prevCfgHash, err)
+	col.reportConfigStatus(logger, configTriggerWatcher, configOutcomeRolledBack, prevCfgHash, err)
+	if col.service != nil {
+		if shutdownErr := col.service.Shutdown(ctx); shutdownErr != nil {
+			logger.Warn("Failed to shutdown the components started with the updated config", zap.Error(shutdownErr))
+		}
+	}
+	if rp, ok := col.set.ConfigProvider.(configRollbackProvider); ok {
+		// The next reload and the config status are compared to the restored configuration.
+		rp.rollbackConfig()
+	}
+	if err = col.startService(ctx, prev); err != nil {
+		err = fmt.Errorf("failed to setup configuration components: %w", err)
+		col.auditConfig(logger, configTriggerWatcher, configOutcomeFailed, prevCfgHash, err)
+		col.reportConfigStatus(logger, configTriggerWatcher, configOutcomeFailed, prevCfgHash, err)
+		return err
+	}
+	col.setCollectorState(Running)
+	return nil
+}
+
+// loadedConfig is a configuration returned by the ConfigProvider, with the details reported by the service.
+type loadedConfig struct {
+	cfg     *Config
+	hash    string
+	sources []string
+	// effective is the redacted effective configuration, nil if not available.
+	effective map[string]interface{}
+	// warnings are reported by the converters and providers while resolving the configuration.
+	warnings []string
+}
+
+// getConfig gets the configuration from the ConfigProvider, and the details it supports reporting.
+func (col *Collector) getConfig(ctx context.Context) (loadedConfig, error) {
+	cfg, err := col.set.ConfigProvider.Get(ctx, col.set.Factories)
+	if err != nil {
+		return loadedConfig{}, err
+	}
+	loaded := loadedConfig{cfg: cfg, hash: col.configHash()}
+	if auditor, ok := col.set.ConfigProvider.(configAuditor); ok {
+		loaded.sources = auditor.configSources()
+	}
+	if ep, ok := col.set.ConfigProvider.(effectiveConfigProvider); ok {
+		effective := ep.effectiveConfig()
+		loaded.effective = redactConf(effective.ToStringMap(), redactedKeys(cfg, effective))
+	}
+	if wp, ok := col.set.ConfigProvider.(configWarningsProvider); ok {
+		loaded.warnings = wp.configWarnings()
+	}
+	return loaded, nil
+}
+
+// configHash returns the hash of the config returned by the last ConfigProvider.Get, if supported.
+func (col *Collector) configHash() string {
+	if hp, ok := col.set.ConfigProvider.(configHashProvider); ok {
+		return hp.configHash()
+	}
+	return ""
+}
+
 // setupConfigurationComponents loads the config and starts the components. If all the steps succeeds it
 // sets the col.service with the service currently running.
 func (col *Collector) setupConfigurationComponents(ctx context.Context) error {
 	col.setCollectorState(Starting)
 
-	cfg, err := col.set.ConfigProvider.Get(ctx, col.set.Factories)
+	loaded, err := col.getConfig(ctx)
 	if err != nil {
+		logger := col.startupLogger(nil)
+		col.auditConfig(logger, configTriggerStartup, configOutcomeRejected, "", err)
+		col.reportConfigStatus(logger, configTriggerStartup, configOutcomeRejected, "", err)
 		return fmt.Errorf("failed to get config: %w", err)
 	}
 
+	if err = col.startService(ctx, loaded); err != nil {
+		logger := col.startupLogger(loaded.cfg)
+		col.auditConfig(logger, configTriggerStartup, configOutcomeFailed, "", err)
+		col.reportConfigStatus(logger, configTriggerStartup, configOutcomeFailed, "", err)
+		return err
+	}
+	col.saveLastKnownGood(col.service.telemetrySettings.Logger)
+	col.auditConfig(col.service.telemetrySettings.Logger, configTriggerStartup, configOutcomeApplied, "", nil)
+	col.reportConfigStatus(col.service.telemetrySettings.Logger, configTriggerStartup, configOutcomeApplied, "", nil)
+	return nil
+}
+
+// startService creates the service for the given config and starts it, and sets col.service.
+func (col *Collector) startService(ctx context.Context, loaded loadedConfig) error {
+	col.setCollectorState(Starting)
+
+	cfg, cfgHash := loaded.cfg, loaded.hash
+	var err error
 	col.service, err = newService(&settings{
 		BuildInfo:         col.set.BuildInfo,
 		Factories:         col.set.Factories,
 		Config:            cfg,
+		ConfigHash:        cfgHash,
+		ConfigSources:     loaded.sources,
+		EffectiveConfig:   loaded.effective,
 		AsyncErrorChannel: col.asyncErrorChannel,
 		LoggingOptions:    col.set.LoggingOptions,
 		telemetry:         col.set.telemetry,
+		reloads:           col.reloads,
+		pauses:            col.pauses,
 	})
 	if err != nil {
 		return err
 	}
+	col.loaded = loaded
+
+	if cfgHash != "" {
+		col.service.telemetrySettings.Logger.Info("Effective configuration loaded", zap.String("config_hash", cfgHash))
+	}
+	for _, warning := range loaded.warnings {
+		col.service.telemetrySettings.Logger.Warn("Configuration warning", zap.String("warning", warning))
+	}
+	if lp, ok := col.set.ConfigProvider.(lastKnownGoodProvider); ok && lp.lastKnownGoodErr() != nil {
+		col.service.telemetrySettings.Logger.Warn("Cannot resolve the configuration, started from the last known good configuration",
+			zap.Error(lp.lastKnownGoodErr()))
+	}
 
 	if !col.set.SkipSettingGRPCLogger {
 		telemetrylogs.SetColGRPCLogger(col.service.telemetrySettings.Logger, cfg.Service.Telemetry.Logs.Level)
@@ -204,12 +464,27 @@ func (col *Collector) setupConfigurationComponents(ctx context.Context) error {
 	return nil
 }
 
+// saveLastKnownGood persists the running config as the last known good config, if supported. It must be
+// called only once the components started successfully, a failure is logged and the components keep running.
+func (col *Collector) saveLastKnownGood(logger *zap.Logger) {
+	lp, ok := col.set.ConfigProvider.(lastKnownGoodProvider)
+	if !ok {
+		return
+	}
+	if err := lp.saveLastKnownGood(); err != nil {
+		logger.Warn("Failed to persist the last known good configuration", zap.Error(err))
+	}
+}
+
 // Run starts the collector according to the given configuration, and waits for it to complete.
 // Consecutive calls to Run are not allowed, Run shouldn't be called once a collector is shut down.
 func (col *Collector) Run(ctx context.Context) error {
+	if col.set.ConfigStatus.URL != "" {
+		col.status = newConfigStatusReporter(col.set.ConfigStatus)
+	}
 	if err := col.setupConfigurationComponents(ctx); err != nil {
 		col.setCollectorState(Closed)
-		return err
+		return multierr.Append(err, col.shutdownConfigStatus(ctx))
 	}
 
 	col.service.telemetrySettings.Logger.Info("Starting "+col.set.BuildInfo.Command+"...",
@@ -217,6 +492,14 @@ func (col *Collector) Run(ctx context.Context) error {
 		zap.Int("NumCPU", runtime.NumCPU()),
 	)
 
+	if col.set.Admin.Endpoint != "" {
+		col.admin = newAdminServer(col.set.Admin, col.pauses, col.requestRuntimeState, col.service.telemetrySettings.Logger)
+		if err := col.admin.start(col.set.Admin.Endpoint, col.asyncErrorChannel); err != nil {
+			col.admin = nil
+			return multierr.Append(fmt.Errorf("failed to start admin API: %w", err), col.shutdown(ctx))
+		}
+	}
+
 	// Everything is ready, now run until an event requiring shutdown happens.
 	return col.runAndWaitForShutdownEvent(ctx)
 }
@@ -224,16 +507,31 @@ func (col *Collector) Run(ctx context.Context) error {
 func (col *Collector) shutdown(ctx context.Context) error {
 	col.setCollectorState(Closing)
 
+	if col.signalsChannel != nil {
+		signal.Stop(col.signalsChannel)
+	}
+	if col.stateDumpChannel != nil {
+		signal.Stop(col.stateDumpChannel)
+	}
+
 	// Accumulate errors and proceed with shutting down remaining components.
 	var errs error
 
 	// Begin shutdown sequence.
 	col.service.telemetrySettings.Logger.Info("Starting shutdown...")
 
+	if col.admin != nil {
+		if err := col.admin.shutdown(); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("failed to shutdown admin API: %w", err))
+		}
+	}
+
 	if err := col.set.ConfigProvider.Shutdown(ctx); err != nil {
 		errs = multierr.Append(errs, fmt.Errorf("failed to shutdown config provider: %w", err))
 	}
 
+	errs = multierr.Append(errs, col.shutdownConfigStatus(ctx))
+
 	if err := col.service.Shutdown(ctx); err != nil {
 		errs = multierr.Append(errs, fmt.Errorf("failed to shutdown service: %w", err))
 	}
@@ -19,6 +19,7 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"path/filepath"
 	"strings"
@@ -35,10 +36,13 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
 	"go.opentelemetry.io/collector/extension/zpagesextension"
 	"go.opentelemetry.io/collector/internal/testutil"
 	"go.opentelemetry.io/collector/service/featuregate"
+	"go.opentelemetry.io/collector/service/internal/telemetry"
 )
 
 func TestStateString(t *testing.T) {
@@ -338,7 +342,9 @@ func TestCollectorStartWithOpenCensusMetrics(t *testing.T) {
 func TestCollectorStartWithOpenTelemetryMetrics(t *testing.T) {
 	for _, tc := range ownMetricsTestCases("test version") {
 		t.Run(tc.name, func(t *testing.T) {
-			colTel := newColTelemetry(featuregate.NewRegistry())
+			registry := featuregate.NewRegistry()
+			registry.MustRegister(useOtelForInternalMetricsfeatureGate)
+			colTel := newColTelemetry(registry)
 			require.NoError(t, colTel.registry.Apply(map[string]bool{useOtelForInternalMetricsfeatureGateID: true}))
 			testCollectorStartHelper(t, colTel, tc)
 		})
@@ -379,6 +385,377 @@ func TestCollectorRun(t *testing.T) {
 	}
 }
 
+func TestCollectorMultipleInstances(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	newCollector := func(metricsAddr string) *Collector {
+		cfgSet := newDefaultConfigProviderSettings([]string{filepath.Join("testdata", "otelcol-nop.yaml")})
+		cfgSet.ResolverSettings.Converters = append([]confmap.Converter{
+			mapConverter{map[string]interface{}{"service::telemetry::metrics::address": metricsAddr}}},
+			cfgSet.ResolverSettings.Converters...,
+		)
+		cfgProvider, errCfg := NewConfigProvider(cfgSet)
+		require.NoError(t, errCfg)
+
+		// Use the default telemetry, shared by instances created via the public API.
+		col, errCol := New(CollectorSettings{
+			BuildInfo:      component.NewDefaultBuildInfo(),
+			Factories:      factories,
+			ConfigProvider: cfgProvider,
+		})
+		require.NoError(t, errCol)
+		return col
+	}
+
+	metricsAddr1 := testutil.GetAvailableLocalAddress(t)
+	metricsAddr2 := testutil.GetAvailableLocalAddress(t)
+	col1 := newCollector(metricsAddr1)
+	col2 := newCollector(metricsAddr2)
+
+	wg1 := startCollector(context.Background(), t, col1)
+	wg2 := startCollector(context.Background(), t, col2)
+	assert.Eventually(t, func() bool {
+		return Running == col1.GetState() && Running == col2.GetState()
+	}, 2*time.Second, 200*time.Millisecond)
+
+	// Shutting down one instance must not affect the other one.
+	col1.Shutdown()
+	wg1.Wait()
+	assert.Equal(t, Closed, col1.GetState())
+	assert.Equal(t, Running, col2.GetState())
+
+	resp, err := http.Get("http://" + metricsAddr2 + "/metrics")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), "otelcol_process_uptime")
+
+	col2.Shutdown()
+	wg2.Wait()
+	assert.Equal(t, Closed, col2.GetState())
+}
+
+// sequenceConfigProvider returns the configured results in order, and repeats the last one.
+// It records the configs persisted as the last known good config.
+type sequenceConfigProvider struct {
+	cfgs    []*Config
+	errs    []error
+	calls   int
+	watcher chan error
+	saved   []*Config
+	// rollbacks counts the calls to rollbackConfig.
+	rollbacks int
+}
+
+func (p *sequenceConfigProvider) Get(context.Context, component.Factories) (*Config, error) {
+	i := p.calls
+	if i >= len(p.cfgs) {
+		i = len(p.cfgs) - 1
+	}
+	p.calls++
+	return p.cfgs[i], p.errs[i]
+}
+
+func (p *sequenceConfigProvider) lastKnownGoodErr() error {
+	return nil
+}
+
+func (p *sequenceConfigProvider) saveLastKnownGood() error {
+	i := p.calls - 1
+	if i >= len(p.cfgs) {
+		i = len(p.cfgs) - 1
+	}
+	p.saved = append(p.saved, p.cfgs[i])
+	return nil
+}
+
+func (p *sequenceConfigProvider) rollbackConfig() {
+	p.rollbacks++
+}
+
+func (p *sequenceConfigProvider) Watch() <-chan error {
+	return p.watcher
+}
+
+func (p *sequenceConfigProvider) Shutdown(context.Context) error {
+	close(p.watcher)
+	return nil
+}
+
+func TestCollectorReloadRollback(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	cfgW, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{filepath.Join("testdata", "otelcol-nop.yaml")}))
+	require.NoError(t, err)
+	cfg, err := cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	require.NoError(t, cfgW.Shutdown(context.Background()))
+
+	// Config that passes Get but fails to start, since the receiver is not configured.
+	badCfg := *cfg
+	badCfg.Service.Pipelines = map[config.ComponentID]*config.Pipeline{
+		config.NewComponentID("traces"): {
+			Receivers: []config.ComponentID{config.NewComponentID("unknown")},
+			Exporters: []config.ComponentID{config.NewComponentID("nop")},
+		},
+	}
+
+	cfgProvider := &sequenceConfigProvider{
+		cfgs:    []*Config{cfg, nil, &badCfg, cfg},
+		errs:    []error{nil, errors.New("invalid config"), nil, nil},
+		watcher: make(chan error, 1),
+	}
+	col, err := New(CollectorSettings{
+		BuildInfo:      component.NewDefaultBuildInfo(),
+		Factories:      factories,
+		ConfigProvider: cfgProvider,
+		telemetry:      newColTelemetry(featuregate.NewRegistry()),
+	})
+	require.NoError(t, err)
+
+	wg := startCollector(context.Background(), t, col)
+	assert.Eventually(t, func() bool {
+		return Running == col.GetState()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Get fails, the running service is kept.
+	cfgProvider.watcher <- nil
+	assert.Eventually(t, func() bool {
+		return col.reloads.FailuresFor(telemetry.ReloadFailureConfig) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, Running, col.GetState())
+
+	// Start fails, rolled back to the previous config.
+	cfgProvider.watcher <- nil
+	assert.Eventually(t, func() bool {
+		return col.reloads.FailuresFor(telemetry.ReloadFailureStart) == 1 && Running == col.GetState()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Successful reload, completed before the shutdown request is processed.
+	cfgProvider.watcher <- nil
+	assert.Eventually(t, func() bool {
+		return len(cfgProvider.watcher) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	col.Shutdown()
+	wg.Wait()
+	assert.Equal(t, Closed, col.GetState())
+	assert.Equal(t, int64(3), col.reloads.Attempts())
+	assert.Equal(t, int64(1), col.reloads.Successes())
+	assert.Equal(t, int64(2), col.reloads.Failures())
+	assert.Equal(t, int64(0), col.reloads.FailuresFor(telemetry.ReloadFailureShutdown))
+	assert.Greater(t, col.reloads.LastDuration(), time.Duration(0))
+	assert.Equal(t, 4, cfgProvider.calls)
+	assert.Same(t, cfg, col.loaded.cfg)
+	assert.Equal(t, 1, cfgProvider.rollbacks)
+	// The config that failed to start is not persisted as the last known good config.
+	assert.Equal(t, []*Config{cfg, cfg}, cfgProvider.saved)
+}
+
+func TestCollectorReloadFlapping(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	cfgW, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{filepath.Join("testdata", "otelcol-nop.yaml")}))
+	require.NoError(t, err)
+	cfg, err := cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	require.NoError(t, cfgW.Shutdown(context.Background()))
+
+	cfgProvider := &sequenceConfigProvider{
+		cfgs:    []*Config{cfg},
+		errs:    []error{nil},
+		watcher: make(chan error, 1),
+	}
+	col, err := New(CollectorSettings{
+		BuildInfo:      component.NewDefaultBuildInfo(),
+		Factories:      factories,
+		ConfigProvider: cfgProvider,
+		Reload:         ReloadSettings{FlapThreshold: 1, FlapWindow: 500 * time.Millisecond},
+		telemetry:      newColTelemetry(featuregate.NewRegistry()),
+	})
+	require.NoError(t, err)
+
+	wg := startCollector(context.Background(), t, col)
+	assert.Eventually(t, func() bool {
+		return Running == col.GetState()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// The first change is applied right away.
+	cfgProvider.watcher <- nil
+	assert.Eventually(t, func() bool {
+		return col.reloads.Attempts() == 1 && Running == col.GetState()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// The next changes are held until the flap window has elapsed, then applied once.
+	cfgProvider.watcher <- nil
+	assert.Eventually(t, col.reloads.Flapping, 2*time.Second, 10*time.Millisecond)
+	cfgProvider.watcher <- nil
+	assert.Eventually(t, func() bool {
+		return len(cfgProvider.watcher) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), col.reloads.Attempts())
+
+	assert.Eventually(t, func() bool {
+		return col.reloads.Attempts() == 2 && !col.reloads.Flapping()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	col.Shutdown()
+	wg.Wait()
+	assert.Equal(t, Closed, col.GetState())
+	assert.Equal(t, int64(2), col.reloads.Successes())
+}
+
+func TestCollectorReloadRetryTransientError(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	cfgW, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{filepath.Join("testdata", "otelcol-nop.yaml")}))
+	require.NoError(t, err)
+	cfg, err := cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	require.NoError(t, cfgW.Shutdown(context.Background()))
+
+	cfgProvider := &sequenceConfigProvider{
+		cfgs: []*Config{cfg, nil, nil, cfg},
+		errs: []error{
+			nil,
+			confmap.NewProviderError(confmap.ErrTransient, errors.New("unavailable")),
+			confmap.NewProviderError(confmap.ErrNotFound, errors.New("not found")),
+			nil,
+		},
+		watcher: make(chan error, 1),
+	}
+	col, err := New(CollectorSettings{
+		BuildInfo:      component.NewDefaultBuildInfo(),
+		Factories:      factories,
+		ConfigProvider: cfgProvider,
+		Reload:         ReloadSettings{RetryInterval: 10 * time.Millisecond},
+		telemetry:      newColTelemetry(featuregate.NewRegistry()),
+	})
+	require.NoError(t, err)
+
+	wg := startCollector(context.Background(), t, col)
+	assert.Eventually(t, func() bool {
+		return Running == col.GetState()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// The transient error is retried without a new change, the permanent error is not.
+	cfgProvider.watcher <- nil
+	assert.Eventually(t, func() bool {
+		return col.reloads.FailuresFor(telemetry.ReloadFailureConfig) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(2), col.reloads.Attempts())
+
+	// A change is applied.
+	cfgProvider.watcher <- nil
+	assert.Eventually(t, func() bool {
+		return col.reloads.Successes() == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	col.Shutdown()
+	wg.Wait()
+	assert.Equal(t, Closed, col.GetState())
+	assert.Equal(t, 4, cfgProvider.calls)
+}
+
+// editableProvider is a confmap.Provider watching an in-memory configuration, invalid until fixed.
+type editableProvider struct {
+	mu       sync.Mutex
+	conf     map[string]interface{}
+	invalid  bool
+	watchers map[int]confmap.WatcherFunc
+	next     int
+}
+
+func (p *editableProvider) Retrieve(_ context.Context, _ string, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.invalid {
+		return nil, confmap.NewProviderError(confmap.ErrInvalidFormat, errors.New("invalid config"))
+	}
+	id := p.next
+	p.next++
+	p.watchers[id] = watcher
+	return confmap.NewRetrieved(p.conf, confmap.WithRetrievedClose(func(context.Context) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.watchers, id)
+		return nil
+	}))
+}
+
+// edit changes the configuration, and notifies the active watches.
+func (p *editableProvider) edit(invalid bool) {
+	p.mu.Lock()
+	p.invalid = invalid
+	var watchers []confmap.WatcherFunc
+	for _, watcher := range p.watchers {
+		watchers = append(watchers, watcher)
+	}
+	p.mu.Unlock()
+	for _, watcher := range watchers {
+		watcher(&confmap.ChangeEvent{})
+	}
+}
+
+func (*editableProvider) Scheme() string {
+	return "edit"
+}
+
+func (*editableProvider) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestCollectorReloadAfterInvalidEdit(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "otelcol-nop.yaml"))
+	require.NoError(t, err)
+	provider := &editableProvider{conf: conf.ToStringMap(), watchers: map[int]confmap.WatcherFunc{}}
+	cfgProvider, err := NewConfigProvider(ConfigProviderSettings{
+		ResolverSettings: confmap.ResolverSettings{
+			URIs:      []string{"edit:config"},
+			Providers: makeMapProvidersMap(provider),
+		},
+	})
+	require.NoError(t, err)
+	col, err := New(CollectorSettings{
+		BuildInfo:      component.NewDefaultBuildInfo(),
+		Factories:      factories,
+		ConfigProvider: cfgProvider,
+		telemetry:      newColTelemetry(featuregate.NewRegistry()),
+	})
+	require.NoError(t, err)
+
+	wg := startCollector(context.Background(), t, col)
+	assert.Eventually(t, func() bool {
+		return Running == col.GetState()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// The invalid edit is rejected, without closing the watch of the running config.
+	provider.edit(true)
+	assert.Eventually(t, func() bool {
+		return col.reloads.FailuresFor(telemetry.ReloadFailureConfig) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// So the next edit, fixing the config, is reloaded.
+	provider.edit(false)
+	assert.Eventually(t, func() bool {
+		return col.reloads.Successes() == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	col.Shutdown()
+	wg.Wait()
+	assert.Equal(t, Closed, col.GetState())
+}
+
 func TestCollectorShutdownBeforeRun(t *testing.T) {
 	factories, err := componenttest.NopFactories()
 	require.NoError(t, err)
@@ -482,6 +859,7 @@ func assertZPages(t *testing.T) {
 		"/debug/pipelinez",
 		"/debug/servicez",
 		"/debug/extensionz",
+		"/debug/configz",
 	}
 
 	const defaultZPagesPort = "55679"
@@ -31,8 +31,9 @@ import (
 	"golang.org/x/sys/windows/svc/eventlog"
 
 	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/converter/envoverlayconverter"
+	"go.opentelemetry.io/collector/confmap/converter/legacyconverter"
 	"go.opentelemetry.io/collector/confmap/converter/overwritepropertiesconverter"
-	"go.opentelemetry.io/collector/service/featuregate"
 )
 
 type windowsService struct {
@@ -95,7 +96,9 @@ func (s *windowsService) start(elog *eventlog.Log, colErrorChannel chan error) e
 	if err := s.flags.Parse(os.Args[1:]); err != nil {
 		return err
 	}
-	if err := featuregate.GetRegistry().Apply(gatesList); err != nil {
+	if err := applyFeatureGatesFlag(s.flags, func(err error) {
+		elog.Warning(2, fmt.Sprintf("ignoring feature gates: %v", err))
+	}); err != nil {
 		return err
 	}
 	var err error
@@ -144,17 +147,39 @@ func openEventLog(serviceName string) (*eventlog.Log, error) {
 
 func newWithWindowsEventLogCore(set CollectorSettings, flags *flag.FlagSet, elog *eventlog.Log) (*Collector, error) {
 	if set.ConfigProvider == nil {
-		var err error
-		cfgSet := newDefaultConfigProviderSettings(getConfigFlag(flags))
-		// Append the "overwrite properties converter" as the first converter.
+		uris, err := getConfigURIs(flags)
+		if err != nil {
+			return nil, err
+		}
+		warnings := &configWarnings{}
+		provSet := getProviderSettings(flags)
+		provSet.Warn = warnings.add
+		cfgSet := newConfigProviderSettings(uris, provSet)
+		cfgSet.ResolverSettings.PollInterval = getPollIntervalFlag(flags)
+		if cfgSet.LastKnownGood, err = getLastKnownGoodSettings(flags); err != nil {
+			return nil, err
+		}
+		// Prepend the "legacy converter", the "env overlay converter" and the "overwrite properties converter"
+		// as the first converters.
+		cfgSet.warnings = warnings
 		cfgSet.ResolverSettings.Converters = append(
-			[]confmap.Converter{overwritepropertiesconverter.New(getSetFlag(flags))},
+			[]confmap.Converter{
+				legacyconverter.New(cfgSet.warnings.add),
+				envoverlayconverter.New(getConfigEnvPrefixFlag(flags)),
+				overwritepropertiesconverter.New(getSetFlag(flags)),
+			},
 			cfgSet.ResolverSettings.Converters...)
 		set.ConfigProvider, err = NewConfigProvider(cfgSet)
 		if err != nil {
 			return nil, err
 		}
 	}
+	if set.Reload == (ReloadSettings{}) {
+		set.Reload = getReloadSettings(flags)
+	}
+	if set.Admin == (AdminSettings{}) {
+		set.Admin = getAdminSettings(flags)
+	}
 	set.LoggingOptions = append(
 		[]zap.Option{zap.WrapCore(withWindowsCore(elog))},
 		set.LoggingOptions...,
@@ -15,11 +15,15 @@
 package service // import "go.opentelemetry.io/collector/service"
 
 import (
+	"flag"
+
 	"github.com/spf13/cobra"
 
 	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/converter/envoverlayconverter"
+	"go.opentelemetry.io/collector/confmap/converter/legacyconverter"
 	"go.opentelemetry.io/collector/confmap/converter/overwritepropertiesconverter"
-	"go.opentelemetry.io/collector/service/featuregate"
+	"go.opentelemetry.io/collector/confmap/converter/profilesconverter"
 )
 
 // NewCommand constructs a new cobra.Command using the given CollectorSettings.
@@ -30,21 +34,33 @@ func NewCommand(set CollectorSettings) *cobra.Command {
 		Version:      set.BuildInfo.Version,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := featuregate.GetRegistry().Apply(gatesList); err != nil {
+			if err := applyFeatureGatesFlag(flagSet, func(err error) {
+				cmd.PrintErrf("Warning: ignoring feature gates: %v\n", err)
+			}); err != nil {
 				return err
 			}
 			if set.ConfigProvider == nil {
-				var err error
-				cfgSet := newDefaultConfigProviderSettings(getConfigFlag(flagSet))
-				// Append the "overwrite properties converter" as the first converter.
-				cfgSet.ResolverSettings.Converters = append(
-					[]confmap.Converter{overwritepropertiesconverter.New(getSetFlag(flagSet))},
-					cfgSet.ResolverSettings.Converters...)
+				cfgSet, err := newConfigProviderSettingsFromFlags(set, flagSet)
+				if err != nil {
+					return err
+				}
 				set.ConfigProvider, err = NewConfigProvider(cfgSet)
 				if err != nil {
 					return err
 				}
 			}
+			if set.Reload == (ReloadSettings{}) {
+				set.Reload = getReloadSettings(flagSet)
+			}
+			if set.Admin == (AdminSettings{}) {
+				set.Admin = getAdminSettings(flagSet)
+			}
+			if set.ConfigStatus == (ConfigStatusSettings{}) {
+				set.ConfigStatus = getConfigStatusSettings(flagSet)
+			}
+			if set.StateDumpFile == "" {
+				set.StateDumpFile = getStateDumpFileFlag(flagSet)
+			}
 			col, err := New(set)
 			if err != nil {
 				return err
@@ -54,5 +70,46 @@ func NewCommand(set CollectorSettings) *cobra.Command {
 	}
 
 	rootCmd.Flags().AddGoFlagSet(flagSet)
+	rootCmd.AddCommand(newDebugBundleCommand())
+	rootCmd.AddCommand(newGenerateCommand(set.Factories))
+	rootCmd.AddCommand(newDoctorCommand(set))
+	rootCmd.AddCommand(newPrintConfigCommand(set))
+	rootCmd.AddCommand(newUpgradeConfigCommand())
+	rootCmd.AddCommand(newSmokeTestCommand(set))
+	rootCmd.AddCommand(newValidateCommand(set))
 	return rootCmd
 }
+
+// newConfigProviderSettingsFromFlags returns the settings of the ConfigProvider configured by the flags,
+// registering the providers and converters of the CollectorSettings.
+func newConfigProviderSettingsFromFlags(set CollectorSettings, flagSet *flag.FlagSet) (ConfigProviderSettings, error) {
+	uris, err := getConfigURIs(flagSet)
+	if err != nil {
+		return ConfigProviderSettings{}, err
+	}
+	warnings := &configWarnings{}
+	provSet := getProviderSettings(flagSet)
+	provSet.Warn = warnings.add
+	cfgSet := newConfigProviderSettings(uris, provSet)
+	for _, provider := range set.ConfigProviders {
+		cfgSet.ResolverSettings.Providers[provider.Scheme()] = provider
+	}
+	cfgSet.ResolverSettings.Converters = append(cfgSet.ResolverSettings.Converters, set.ConfigConverters...)
+	cfgSet.ResolverSettings.PollInterval = getPollIntervalFlag(flagSet)
+	if cfgSet.LastKnownGood, err = getLastKnownGoodSettings(flagSet); err != nil {
+		return ConfigProviderSettings{}, err
+	}
+	// Prepend the "legacy converter", the "profiles converter", the "env overlay converter" and the
+	// "overwrite properties converter", so that the legacy layouts are rewritten first and the values set
+	// via environment variables and flags take precedence over the selected profile, in this order.
+	cfgSet.warnings = warnings
+	cfgSet.ResolverSettings.Converters = append(
+		[]confmap.Converter{
+			legacyconverter.New(cfgSet.warnings.add),
+			profilesconverter.New(getProfileFlag(flagSet)),
+			envoverlayconverter.New(getConfigEnvPrefixFlag(flagSet)),
+			overwritepropertiesconverter.New(getSetFlag(flagSet)),
+		},
+		cfgSet.ResolverSettings.Converters...)
+	return cfgSet, nil
+}
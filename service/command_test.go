@@ -15,7 +15,9 @@
 package service
 
 import (
+	"context"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,6 +25,9 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
 )
 
 func TestNewCommandVersion(t *testing.T) {
@@ -38,6 +43,17 @@ func TestNewCommandNoConfigURI(t *testing.T) {
 	require.Error(t, cmd.Execute())
 }
 
+func TestNewCommandConfigFromEnv(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	t.Setenv(configEnvVar, filepath.Join("testdata", "otelcol-invalid.yaml"))
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	cmd.SetArgs([]string{})
+	// The config file is found, so the error is about the invalid config instead of the missing URIs.
+	assert.ErrorContains(t, cmd.Execute(), "references processor \"invalid\" which does not exist")
+}
+
 func TestNewCommandInvalidComponent(t *testing.T) {
 	factories, err := componenttest.NopFactories()
 	require.NoError(t, err)
@@ -48,3 +64,108 @@ func TestNewCommandInvalidComponent(t *testing.T) {
 	cmd := NewCommand(CollectorSettings{Factories: factories, ConfigProvider: cfgProvider})
 	require.Error(t, cmd.Execute())
 }
+
+func TestNewCommandFeatureGatesIsolated(t *testing.T) {
+	cmd1 := NewCommand(CollectorSettings{})
+	cmd2 := NewCommand(CollectorSettings{})
+
+	require.NoError(t, cmd1.ParseFlags([]string{"--feature-gates=foo,-bar"}))
+	assert.Equal(t, "-bar,foo", cmd1.Flags().Lookup(featureGatesFlag).Value.String())
+	assert.Equal(t, "", cmd2.Flags().Lookup(featureGatesFlag).Value.String())
+}
+
+func TestNewCommandInvalidConfigDir(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	cmd.SetArgs([]string{"--config-dir", filepath.Join("testdata", "missing")})
+	assert.ErrorContains(t, cmd.Execute(), "cannot read the config directory")
+}
+
+func TestNewCommandProfile(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	cmd.SetArgs([]string{"--config", filepath.Join("testdata", "otelcol-profiles.yaml"), "--profile", "unknown"})
+	assert.ErrorContains(t, cmd.Execute(), "profile \"unknown\" is not defined, available profiles: [invalid]")
+
+	cmd = NewCommand(CollectorSettings{Factories: factories})
+	cmd.SetArgs([]string{"--config", filepath.Join("testdata", "otelcol-profiles.yaml"), "--profile", "invalid"})
+	// The selected profile is merged, so the error is about the processor it references.
+	assert.ErrorContains(t, cmd.Execute(), "references processor \"invalid\" which does not exist")
+}
+
+func TestNewCommandConfigEnvPrefix(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	t.Setenv("TESTENVPREFIX_service__pipelines__traces__processors", "invalid")
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	cmd.SetArgs([]string{"--config", filepath.Join("testdata", "otelcol-nop.yaml"), "--config-env-prefix", "TESTENVPREFIX_"})
+	// The overlay is merged, so the error is about the processor it references.
+	assert.ErrorContains(t, cmd.Execute(), "references processor \"invalid\" which does not exist")
+}
+
+// aliasProvider retrieves the files referenced with its own scheme.
+type aliasProvider struct {
+	confmap.Provider
+	scheme string
+}
+
+func (ap *aliasProvider) Retrieve(ctx context.Context, uri string, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	return ap.Provider.Retrieve(ctx, "file"+strings.TrimPrefix(uri, ap.scheme), watcher)
+}
+
+func (ap *aliasProvider) Scheme() string {
+	return ap.scheme
+}
+
+type recordingConverter struct {
+	converted bool
+}
+
+func (rc *recordingConverter) Convert(context.Context, *confmap.Conf) error {
+	rc.converted = true
+	return nil
+}
+
+func TestNewCommandCustomProvidersAndConverters(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	converter := &recordingConverter{}
+	cmd := NewCommand(CollectorSettings{
+		Factories:        factories,
+		ConfigProviders:  []confmap.Provider{&aliasProvider{Provider: fileprovider.New(), scheme: "custom"}},
+		ConfigConverters: []confmap.Converter{converter},
+	})
+	cmd.SetArgs([]string{"--config", "custom:" + filepath.Join("testdata", "otelcol-invalid.yaml")})
+	// The config is retrieved by the custom provider, so the error is about the invalid config.
+	assert.ErrorContains(t, cmd.Execute(), "references processor \"invalid\" which does not exist")
+	assert.True(t, converter.converted)
+}
+
+func TestNewConfigProviderSettingsFromFlagsLegacyLayout(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{"--config", filepath.Join("testdata", "otelcol-legacy.yaml")}))
+	cfgSet, err := newConfigProviderSettingsFromFlags(CollectorSettings{Factories: factories}, flagSet)
+	require.NoError(t, err)
+	cfgProvider, err := NewConfigProvider(cfgSet)
+	require.NoError(t, err)
+
+	// The legacy "queued_retry" processor is removed, so the config is valid.
+	cfg, err := cfgProvider.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.Equal(t, []config.ComponentID{config.NewComponentID("nop")}, cfg.Service.Pipelines[config.NewComponentID("traces")].Processors)
+
+	warnings := cfgProvider.(configWarningsProvider).configWarnings()
+	assert.Len(t, warnings, 2)
+	// The warnings are returned only once.
+	assert.Empty(t, cfgProvider.(configWarningsProvider).configWarnings())
+	require.NoError(t, cfgProvider.Shutdown(context.Background()))
+}
@@ -23,3 +23,7 @@ type Config = config.Config
 type ConfigService = config.Service
 
 type ConfigServicePipeline = config.Pipeline
+
+type ConfigServiceTimeouts = config.ServiceTimeouts
+
+type ConfigComponentTimeouts = config.ComponentTimeouts
@@ -0,0 +1,149 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"reflect"
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/service/internal/telemetrylogs"
+	"go.opentelemetry.io/collector/service/telemetry"
+)
+
+// Triggers of a configuration load reported in the audit records.
+const (
+	configTriggerStartup = "startup"
+	configTriggerWatcher = "watcher"
+)
+
+// Outcomes of a configuration load reported in the audit records.
+const (
+	configOutcomeApplied    = "applied"
+	configOutcomeRejected   = "rejected"
+	configOutcomeRolledBack = "rolled_back"
+	// configOutcomeFailed is reported when the components fail to start with the configuration
+	// loaded at startup, or when a reload fails to shut down the running components or to restart
+	// them with the previous configuration.
+	configOutcomeFailed = "failed"
+)
+
+// configDiff summarizes the changes between two configurations. Only the keys are
+// reported, never the values, since they may be secrets.
+type configDiff struct {
+	added   []string
+	removed []string
+	changed []string
+	// sources of the added and changed keys, if known.
+	sources map[string]string
+}
+
+// diffConf returns the keys added, removed and changed in cur compared to prev.
+// A nil prev means that all the keys are added.
+func diffConf(prev, cur *confmap.Conf) configDiff {
+	var diff configDiff
+	if prev == nil {
+		prev = confmap.New()
+	}
+	for _, k := range cur.AllKeys() {
+		switch {
+		case !prev.IsSet(k):
+			diff.added = append(diff.added, k)
+		case !reflect.DeepEqual(prev.Get(k), cur.Get(k)):
+			diff.changed = append(diff.changed, k)
+		default:
+			continue
+		}
+		if src, ok := cur.Source(k); ok {
+			if diff.sources == nil {
+				diff.sources = make(map[string]string)
+			}
+			diff.sources[k] = src.String()
+		}
+	}
+	for _, k := range prev.AllKeys() {
+		if !cur.IsSet(k) {
+			diff.removed = append(diff.removed, k)
+		}
+	}
+	sort.Strings(diff.added)
+	sort.Strings(diff.removed)
+	sort.Strings(diff.changed)
+	return diff
+}
+
+// configAuditor is implemented by the ConfigProvider returned by NewConfigProvider, and reports
+// the configuration sources and the changes applied by the last successful Get.
+type configAuditor interface {
+	configSources() []string
+	configDiff() configDiff
+}
+
+// startupLogger returns the logger used to report the configuration loaded at startup: the logger
+// of the service if it was created, otherwise a logger built from the telemetry logs settings of cfg,
+// or from the default ones if cfg is nil.
+func (col *Collector) startupLogger(cfg *Config) *zap.Logger {
+	if col.service != nil {
+		return col.service.telemetrySettings.Logger
+	}
+	logsCfg := telemetry.LogsConfig{
+		Level:            zapcore.InfoLevel,
+		Encoding:         "console",
+		OutputPaths:      []string{"stderr"},
+		ErrorOutputPaths: []string{"stderr"},
+	}
+	if cfg != nil {
+		logsCfg = cfg.Service.Telemetry.Logs
+	}
+	logger, err := telemetrylogs.NewLogger(logsCfg, col.set.LoggingOptions)
+	if err != nil {
+		return zap.NewNop()
+	}
+	return logger
+}
+
+// auditConfig logs a structured audit record of a configuration load. Nothing is logged if the
+// ConfigProvider does not support auditing.
+func (col *Collector) auditConfig(logger *zap.Logger, trigger, outcome, prevHash string, err error) {
+	auditor, ok := col.set.ConfigProvider.(configAuditor)
+	if !ok {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("trigger", trigger),
+		zap.String("outcome", outcome),
+		zap.Strings("sources", auditor.configSources()),
+		zap.String("previous_config_hash", prevHash),
+	}
+	if outcome == configOutcomeRejected {
+		fields = append(fields, zap.Error(err))
+	} else {
+		diff := auditor.configDiff()
+		fields = append(fields,
+			zap.String("config_hash", col.configHash()),
+			zap.Strings("keys_added", diff.added),
+			zap.Strings("keys_removed", diff.removed),
+			zap.Strings("keys_changed", diff.changed),
+			zap.Any("key_sources", diff.sources),
+		)
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		}
+	}
+	logger.Info("Configuration audit", fields...)
+}
@@ -0,0 +1,183 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/service/featuregate"
+)
+
+func TestDiffConf(t *testing.T) {
+	prev := confmap.NewFromStringMap(map[string]interface{}{
+		"receivers": map[string]interface{}{"otlp": map[string]interface{}{"endpoint": "a"}},
+		"exporters": map[string]interface{}{"logging": map[string]interface{}{"loglevel": "info"}},
+		"service":   map[string]interface{}{"extensions": []interface{}{"a"}},
+	})
+	cur := confmap.NewFromStringMap(map[string]interface{}{
+		"receivers":  map[string]interface{}{"otlp": map[string]interface{}{"endpoint": "b"}},
+		"extensions": map[string]interface{}{"zpages": map[string]interface{}{"endpoint": "c"}},
+		"service":    map[string]interface{}{"extensions": []interface{}{"a"}},
+	})
+
+	assert.Equal(t, configDiff{
+		added:   []string{"extensions::zpages::endpoint"},
+		removed: []string{"exporters::logging::loglevel"},
+		changed: []string{"receivers::otlp::endpoint"},
+	}, diffConf(prev, cur))
+
+	assert.Equal(t, configDiff{
+		added: []string{"extensions::zpages::endpoint", "receivers::otlp::endpoint", "service::extensions"},
+	}, diffConf(nil, cur))
+}
+
+func TestCollectorConfigAudit(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	uri := filepath.Join("testdata", "otelcol-nop.yaml")
+	cfgProvider, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{uri}))
+	require.NoError(t, err)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	col, err := New(CollectorSettings{
+		BuildInfo:      component.NewDefaultBuildInfo(),
+		Factories:      factories,
+		ConfigProvider: cfgProvider,
+		LoggingOptions: []zap.Option{zap.WrapCore(func(c zapcore.Core) zapcore.Core { return zapcore.NewTee(c, core) })},
+		telemetry:      newColTelemetry(featuregate.NewRegistry()),
+	})
+	require.NoError(t, err)
+
+	wg := startCollector(context.Background(), t, col)
+	assert.Eventually(t, func() bool {
+		return Running == col.GetState()
+	}, 2*time.Second, 10*time.Millisecond)
+	col.Shutdown()
+	wg.Wait()
+
+	records := logs.FilterMessage("Configuration audit").All()
+	require.Len(t, records, 1)
+	fields := records[0].ContextMap()
+	assert.Equal(t, configTriggerStartup, fields["trigger"])
+	assert.Equal(t, configOutcomeApplied, fields["outcome"])
+	assert.Equal(t, []interface{}{uri}, fields["sources"])
+	assert.Equal(t, col.configHash(), fields["config_hash"])
+	assert.Contains(t, fields["keys_added"], "receivers::nop")
+	assert.Empty(t, fields["keys_removed"])
+	assert.Equal(t, uri, fields["key_sources"].(map[string]string)["receivers::nop"])
+}
+
+func TestCollectorConfigAuditStartupRejected(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	uri := filepath.Join("testdata", "otelcol-invalid.yaml")
+	cfgProvider, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{uri}))
+	require.NoError(t, err)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	col, err := New(CollectorSettings{
+		BuildInfo:      component.NewDefaultBuildInfo(),
+		Factories:      factories,
+		ConfigProvider: cfgProvider,
+		LoggingOptions: []zap.Option{zap.WrapCore(func(c zapcore.Core) zapcore.Core { return zapcore.NewTee(c, core) })},
+		telemetry:      newColTelemetry(featuregate.NewRegistry()),
+	})
+	require.NoError(t, err)
+	require.Error(t, col.Run(context.Background()))
+
+	records := logs.FilterMessage("Configuration audit").All()
+	require.Len(t, records, 1)
+	fields := records[0].ContextMap()
+	assert.Equal(t, configTriggerStartup, fields["trigger"])
+	assert.Equal(t, configOutcomeRejected, fields["outcome"])
+	assert.Equal(t, []interface{}{uri}, fields["sources"])
+	assert.Contains(t, fields["error"], "invalid configuration")
+}
+
+// auditedConfigProvider is a sequenceConfigProvider supporting the configuration audit.
+type auditedConfigProvider struct {
+	*sequenceConfigProvider
+}
+
+func (p auditedConfigProvider) configSources() []string {
+	return []string{"test"}
+}
+
+func (p auditedConfigProvider) configDiff() configDiff {
+	return configDiff{}
+}
+
+func TestCollectorConfigAuditStartupFailed(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	cfgW, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{filepath.Join("testdata", "otelcol-nop.yaml")}))
+	require.NoError(t, err)
+	cfg, err := cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	require.NoError(t, cfgW.Shutdown(context.Background()))
+
+	// Config that passes Get but fails to start, since the receiver is not configured.
+	badCfg := *cfg
+	badCfg.Service.Pipelines = map[config.ComponentID]*config.Pipeline{
+		config.NewComponentID("traces"): {
+			Receivers: []config.ComponentID{config.NewComponentID("unknown")},
+			Exporters: []config.ComponentID{config.NewComponentID("nop")},
+		},
+	}
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	tel := newColTelemetry(featuregate.NewRegistry())
+	t.Cleanup(func() {
+		// The telemetry initialized by the service that failed to start is not shut down by Run.
+		assert.NoError(t, tel.shutdown())
+	})
+	col, err := New(CollectorSettings{
+		BuildInfo: component.NewDefaultBuildInfo(),
+		Factories: factories,
+		ConfigProvider: auditedConfigProvider{&sequenceConfigProvider{
+			cfgs:    []*Config{&badCfg},
+			errs:    []error{nil},
+			watcher: make(chan error, 1),
+		}},
+		LoggingOptions: []zap.Option{zap.WrapCore(func(c zapcore.Core) zapcore.Core { return zapcore.NewTee(c, core) })},
+		telemetry:      tel,
+	})
+	require.NoError(t, err)
+	require.Error(t, col.Run(context.Background()))
+
+	records := logs.FilterMessage("Configuration audit").All()
+	require.Len(t, records, 1)
+	fields := records[0].ContextMap()
+	assert.Equal(t, configTriggerStartup, fields["trigger"])
+	assert.Equal(t, configOutcomeFailed, fields["outcome"])
+	assert.Equal(t, []interface{}{"test"}, fields["sources"])
+	assert.Contains(t, fields["error"], "unknown")
+}
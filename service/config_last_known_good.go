@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const defaultLastKnownGoodRetryInterval = 30 * time.Second
+
+// LastKnownGoodSettings configures persisting the last successfully resolved configuration, so that the
+// Collector can start from it when the configuration sources are unavailable at startup.
+type LastKnownGoodSettings struct {
+	// Path of the file where the last known good configuration is persisted. Empty disables the feature.
+	Path string
+
+	// EncryptionKey is the AES key, 16, 24 or 32 bytes long, used to encrypt the persisted configuration
+	// since it may contain secrets. Required if Path is set.
+	EncryptionKey []byte
+
+	// RetryInterval is the interval to retry resolving the configuration sources after starting from the
+	// last known good configuration. Once they are available a configuration change is notified.
+	// Defaults to 30s.
+	RetryInterval time.Duration
+}
+
+// lastKnownGood persists and loads the last known good configuration.
+type lastKnownGood struct {
+	set LastKnownGoodSettings
+}
+
+func newLastKnownGood(set LastKnownGoodSettings) (*lastKnownGood, error) {
+	if set.Path == "" {
+		return nil, nil
+	}
+	if len(set.EncryptionKey) == 0 {
+		return nil, errors.New("the last known good config requires an encryption key")
+	}
+	if _, err := aes.NewCipher(set.EncryptionKey); err != nil {
+		return nil, fmt.Errorf("invalid last known good config encryption key: %w", err)
+	}
+	if set.RetryInterval <= 0 {
+		set.RetryInterval = defaultLastKnownGoodRetryInterval
+	}
+	return &lastKnownGood{set: set}, nil
+}
+
+// save atomically replaces the persisted configuration with the given one.
+func (l *lastKnownGood) save(conf *confmap.Conf) error {
+	data, err := json.Marshal(conf.ToStringMap())
+	if err != nil {
+		return err
+	}
+	if data, err = l.seal(data); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.set.Path), filepath.Base(l.set.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), l.set.Path)
+}
+
+// load returns the persisted configuration.
+func (l *lastKnownGood) load() (*confmap.Conf, error) {
+	data, err := os.ReadFile(l.set.Path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = l.open(data); err != nil {
+		return nil, err
+	}
+	var rawConf map[string]interface{}
+	if err = json.Unmarshal(data, &rawConf); err != nil {
+		return nil, err
+	}
+	return confmap.NewFromStringMap(rawConf), nil
+}
+
+func (l *lastKnownGood) seal(data []byte) ([]byte, error) {
+	gcm, err := l.gcm()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+func (l *lastKnownGood) open(data []byte) ([]byte, error) {
+	gcm, err := l.gcm()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted configuration")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+func (l *lastKnownGood) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(l.set.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// retryResolve periodically resolves the configuration until it succeeds, and then notifies a configuration
+// change on the given channel. It returns when ctx is done.
+func (l *lastKnownGood) retryResolve(ctx context.Context, mr *confmap.Resolver, watcher chan<- error) {
+	ticker := time.NewTicker(l.set.RetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := mr.Resolve(ctx); err == nil {
+			select {
+			case watcher <- nil:
+			default:
+			}
+			return
+		}
+	}
+}
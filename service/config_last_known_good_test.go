@@ -0,0 +1,201 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestLastKnownGoodSaveLoad(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]interface{}{"receivers": map[string]interface{}{"nop": nil}, "key": "secret"})
+	for _, key := range [][]byte{[]byte("0123456789abcdef"), []byte("0123456789abcdef0123456789abcdef")} {
+		path := filepath.Join(t.TempDir(), "lkg.json")
+		lkg, err := newLastKnownGood(LastKnownGoodSettings{Path: path, EncryptionKey: key})
+		require.NoError(t, err)
+		require.NoError(t, lkg.save(conf))
+
+		fi, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		// The configuration is encrypted.
+		assert.False(t, bytes.Contains(content, []byte("secret")))
+
+		loaded, err := lkg.load()
+		require.NoError(t, err)
+		assert.Equal(t, conf.ToStringMap(), loaded.ToStringMap())
+	}
+}
+
+func TestLastKnownGoodErrors(t *testing.T) {
+	lkg, err := newLastKnownGood(LastKnownGoodSettings{})
+	require.NoError(t, err)
+	assert.Nil(t, lkg)
+
+	_, err = newLastKnownGood(LastKnownGoodSettings{Path: "lkg.json"})
+	assert.EqualError(t, err, "the last known good config requires an encryption key")
+
+	_, err = newLastKnownGood(LastKnownGoodSettings{Path: "lkg.json", EncryptionKey: []byte("short")})
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "lkg.json")
+	lkg, err = newLastKnownGood(LastKnownGoodSettings{Path: path, EncryptionKey: []byte("0123456789abcdef")})
+	require.NoError(t, err)
+	_, err = lkg.load()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	require.NoError(t, lkg.save(confmap.New()))
+
+	// Wrong key.
+	lkg, err = newLastKnownGood(LastKnownGoodSettings{Path: path, EncryptionKey: []byte("fedcba9876543210")})
+	require.NoError(t, err)
+	_, err = lkg.load()
+	assert.Error(t, err)
+}
+
+// flakyProvider returns the nop configuration, or an error if failing is set, not found if
+// notFound is also set.
+type flakyProvider struct {
+	failing  *atomic.Bool
+	notFound bool
+}
+
+func (f *flakyProvider) Retrieve(_ context.Context, _ string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if f.failing.Load() {
+		if f.notFound {
+			return nil, confmap.NewProviderError(confmap.ErrNotFound, errors.New("source not found"))
+		}
+		return nil, errors.New("source unavailable")
+	}
+	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "otelcol-nop.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	return confmap.NewRetrieved(conf.ToStringMap())
+}
+
+func (*flakyProvider) Scheme() string {
+	return "flaky"
+}
+
+func (*flakyProvider) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestConfigProviderLastKnownGood(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	failing := atomic.NewBool(false)
+	path := filepath.Join(t.TempDir(), "lkg.json")
+	newSettings := func() ConfigProviderSettings {
+		set := newDefaultConfigProviderSettings([]string{"flaky:"})
+		set.ResolverSettings.Providers["flaky"] = &flakyProvider{failing: failing}
+		set.LastKnownGood = LastKnownGoodSettings{Path: path, EncryptionKey: []byte("0123456789abcdef"), RetryInterval: 10 * time.Millisecond}
+		return set
+	}
+
+	// No last known good configuration yet.
+	failing.Store(true)
+	cfgW, err := NewConfigProvider(newSettings())
+	require.NoError(t, err)
+	_, err = cfgW.Get(context.Background(), factories)
+	assert.Error(t, err)
+	assert.NoError(t, cfgW.Shutdown(context.Background()))
+
+	// The configuration is persisted only once the components started with it.
+	failing.Store(false)
+	cfgW, err = NewConfigProvider(newSettings())
+	require.NoError(t, err)
+	cfg, err := cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.NoError(t, cfgW.(lastKnownGoodProvider).lastKnownGoodErr())
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	require.NoError(t, cfgW.(lastKnownGoodProvider).saveLastKnownGood())
+	assert.NoError(t, cfgW.Shutdown(context.Background()))
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	// Source unavailable at startup, the last known good configuration is used.
+	failing.Store(true)
+	cfgW, err = NewConfigProvider(newSettings())
+	require.NoError(t, err)
+	lkgCfg, err := cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, lkgCfg)
+	assert.EqualError(t, cfgW.(lastKnownGoodProvider).lastKnownGoodErr(), "cannot retrieve the configuration: source unavailable")
+
+	// Once the source is available again a change is notified.
+	failing.Store(false)
+	select {
+	case errW := <-cfgW.Watch():
+		assert.NoError(t, errW)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "expected configuration change")
+	}
+	_, err = cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.NoError(t, cfgW.(lastKnownGoodProvider).lastKnownGoodErr())
+
+	// After the first Get the last known good configuration is not used.
+	failing.Store(true)
+	_, err = cfgW.Get(context.Background(), factories)
+	assert.Error(t, err)
+	assert.NoError(t, cfgW.Shutdown(context.Background()))
+}
+
+func TestConfigProviderLastKnownGoodPermanentError(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	failing := atomic.NewBool(false)
+	path := filepath.Join(t.TempDir(), "lkg.json")
+	newSettings := func() ConfigProviderSettings {
+		set := newDefaultConfigProviderSettings([]string{"flaky:"})
+		set.ResolverSettings.Providers["flaky"] = &flakyProvider{failing: failing, notFound: true}
+		set.LastKnownGood = LastKnownGoodSettings{Path: path, EncryptionKey: []byte("0123456789abcdef")}
+		return set
+	}
+
+	cfgW, err := NewConfigProvider(newSettings())
+	require.NoError(t, err)
+	_, err = cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	require.NoError(t, cfgW.(lastKnownGoodProvider).saveLastKnownGood())
+	assert.NoError(t, cfgW.Shutdown(context.Background()))
+
+	// The configuration is not found, the last known good configuration is not used.
+	failing.Store(true)
+	cfgW, err = NewConfigProvider(newSettings())
+	require.NoError(t, err)
+	_, err = cfgW.Get(context.Background(), factories)
+	assert.ErrorIs(t, err, confmap.ErrNotFound)
+	assert.NoError(t, cfgW.Shutdown(context.Background()))
+}
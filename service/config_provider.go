@@ -16,14 +16,26 @@ package service // import "go.opentelemetry.io/collector/service"
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/confmap/converter/expandconverter"
 	"go.opentelemetry.io/collector/confmap/provider/envprovider"
 	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+	"go.opentelemetry.io/collector/confmap/provider/httpprovider"
+	"go.opentelemetry.io/collector/confmap/provider/httpsprovider"
 	"go.opentelemetry.io/collector/confmap/provider/yamlprovider"
+	"go.opentelemetry.io/collector/service/featuregate"
 	"go.opentelemetry.io/collector/service/internal/configunmarshaler"
 )
 
@@ -63,6 +75,115 @@ type ConfigProvider interface {
 
 type configProvider struct {
 	mapResolver *confmap.Resolver
+
+	// hash of the effective configuration returned by the last successful Get.
+	hash string
+
+	// uris are the configuration sources.
+	uris []string
+	// conf is the configuration returned by the last successful Get, and diff its
+	// changes compared to the configuration returned by the previous one.
+	conf *confmap.Conf
+	diff configDiff
+	// prevConf, prevHash and prevDiff are the state before the last successful Get, restored
+	// by rollbackConfig.
+	prevConf *confmap.Conf
+	prevHash string
+	prevDiff configDiff
+
+	// lkg is nil if persisting the last known good configuration is disabled.
+	lkg *lastKnownGood
+	// watcher forwards the mapResolver changes, and the changes detected by retryResolve.
+	// Only used if lkg is not nil.
+	watcher chan error
+	// lkgErr is the error resolving the configuration if the last Get returned the
+	// last known good configuration.
+	lkgErr error
+	// lkgPending is the configuration returned by the last Get, persisted by saveLastKnownGood once
+	// the components started with it. Nil if there is nothing to persist.
+	lkgPending  *confmap.Conf
+	gotConfig   bool
+	retryCancel context.CancelFunc
+	retryDone   chan struct{}
+
+	// warnings collects the warnings reported by the converters, nil if not supported.
+	warnings *configWarnings
+
+	// pollInterval is the confmap.ResolverSettings.PollInterval. If zero, the service
+	// config_poll_interval applies, servicePollInterval being the one set on the mapResolver.
+	pollInterval        time.Duration
+	servicePollInterval time.Duration
+}
+
+// configHashProvider is implemented by the ConfigProvider returned by NewConfigProvider, and
+// reports the hash of the effective configuration returned by the last successful Get.
+type configHashProvider interface {
+	configHash() string
+}
+
+// effectiveConfigProvider is implemented by the ConfigProvider returned by NewConfigProvider, and
+// reports the effective configuration returned by the last successful Get.
+type effectiveConfigProvider interface {
+	effectiveConfig() *confmap.Conf
+}
+
+// unvalidatedConfigProvider is implemented by the ConfigProvider returned by NewConfigProvider, and
+// resolves and unmarshals the configuration without validating it, nor changing the state of the
+// ConfigProvider.
+type unvalidatedConfigProvider interface {
+	unvalidatedConfig(ctx context.Context, factories component.Factories) (*confmap.Conf, *Config, error)
+}
+
+// lastKnownGoodProvider is implemented by the ConfigProvider returned by NewConfigProvider, and
+// reports the error resolving the configuration if the last Get returned the last known good
+// configuration instead. saveLastKnownGood persists the configuration returned by the last Get,
+// and must be called only once the components started successfully with it.
+type lastKnownGoodProvider interface {
+	lastKnownGoodErr() error
+	saveLastKnownGood() error
+}
+
+// configRollbackProvider is implemented by the ConfigProvider returned by NewConfigProvider, and
+// restores the configuration, hash and changes reported before the last successful Get, once the
+// service rolled back to the previous configuration. It must be called at most once after a Get.
+type configRollbackProvider interface {
+	rollbackConfig()
+}
+
+// configWarningsProvider is implemented by the ConfigProvider returned by NewConfigProvider, and
+// returns, only once, the warnings reported by the converters and providers while resolving the configuration
+// returned by the last Get.
+type configWarningsProvider interface {
+	configWarnings() []string
+}
+
+// configWarnings collects the warnings reported by the converters and providers while resolving the configuration.
+// The zero value is ready to use, and a nil *configWarnings discards the warnings.
+type configWarnings struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// add is passed to the converters and providers reporting warnings.
+func (w *configWarnings) add(msg string) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, msg)
+}
+
+// reset discards the collected warnings, and returns them.
+func (w *configWarnings) reset() []string {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	messages := w.messages
+	w.messages = nil
+	return messages
 }
 
 // ConfigProviderSettings are the settings to configure the behavior of the ConfigProvider.
@@ -70,6 +191,10 @@ type ConfigProviderSettings struct {
 	// ResolverSettings are the settings to configure the behavior of the confmap.Resolver.
 	ResolverSettings confmap.ResolverSettings
 
+	// LastKnownGood configures persisting the last successfully resolved configuration, and starting
+	// from it when the configuration cannot be resolved at startup.
+	LastKnownGood LastKnownGoodSettings
+
 	// Deprecated: [v0.58.0] use ConfigProviderSettings.ResolverSettings.URIs
 	Locations []string
 
@@ -78,13 +203,44 @@ type ConfigProviderSettings struct {
 
 	// Deprecated: [v0.58.0] use ConfigProviderSettings.ResolverSettings.Converter
 	MapConverters []confmap.Converter
+
+	// warnings collects the warnings reported by the converters, nil if none reports them.
+	warnings *configWarnings
+}
+
+const (
+	// expandEnabledGateID is the feature gate ID that controls whether the config providers
+	// expand the "${<scheme>:<opaque_data>}" references embedded in the configuration values.
+	expandEnabledGateID = "confmap.expandEnabled"
+)
+
+var expandEnabledGate = featuregate.Gate{
+	ID:          expandEnabledGateID,
+	Description: "controls whether the references to config providers embedded in config values are expanded",
+	Enabled:     false,
+}
+
+func init() {
+	featuregate.GetRegistry().MustRegister(expandEnabledGate)
 }
 
 func newDefaultConfigProviderSettings(uris []string) ConfigProviderSettings {
+	return newConfigProviderSettings(uris, confmap.ProviderSettings{})
+}
+
+// newConfigProviderSettings returns the default ConfigProviderSettings, the default providers
+// being initialized with the given confmap.ProviderSettings.
+func newConfigProviderSettings(uris []string, provSet confmap.ProviderSettings) ConfigProviderSettings {
 	return ConfigProviderSettings{
 		ResolverSettings: confmap.ResolverSettings{
-			URIs:       uris,
-			Providers:  makeMapProvidersMap(fileprovider.New(), envprovider.New(), yamlprovider.New()),
+			URIs: uris,
+			Providers: makeMapProvidersMap(
+				fileprovider.NewWithSettings(provSet),
+				envprovider.NewWithSettings(provSet),
+				yamlprovider.NewWithSettings(provSet),
+				httpprovider.NewWithSettings(provSet),
+				httpsprovider.NewWithSettings(provSet),
+			),
 			Converters: []confmap.Converter{expandconverter.New()},
 		},
 	}
@@ -96,6 +252,11 @@ func newDefaultConfigProviderSettings(uris []string) ConfigProviderSettings {
 //   - Then applies all the confmap.Converter in the given order.
 //
 // * Then unmarshalls the confmap.Conf into the service Config.
+//
+// If ConfigProviderSettings.LastKnownGood is configured, every configuration the components started
+// with is persisted. If the configuration cannot be resolved by the first Get, unless the error is
+// permanent (see confmap.IsPermanent), the persisted configuration is returned instead, and resolving
+// is retried in the background until it succeeds, when a configuration change is notified via Watch.
 func NewConfigProvider(set ConfigProviderSettings) (ConfigProvider, error) {
 	if len(set.Locations) != 0 {
 		set.ResolverSettings.URIs = set.Locations
@@ -106,20 +267,97 @@ func NewConfigProvider(set ConfigProviderSettings) (ConfigProvider, error) {
 	if len(set.MapConverters) != 0 {
 		set.ResolverSettings.Converters = set.MapConverters
 	}
+	if featuregate.GetRegistry().IsEnabled(expandEnabledGateID) {
+		set.ResolverSettings.EnableExpand = true
+	}
 	mr, err := confmap.NewResolver(set.ResolverSettings)
 	if err != nil {
 		return nil, err
 	}
+	lkg, err := newLastKnownGood(set.LastKnownGood)
+	if err != nil {
+		return nil, err
+	}
 
-	return &configProvider{
-		mapResolver: mr,
-	}, nil
+	cm := &configProvider{
+		mapResolver:  mr,
+		lkg:          lkg,
+		uris:         append([]string(nil), set.ResolverSettings.URIs...),
+		warnings:     set.warnings,
+		pollInterval: set.ResolverSettings.PollInterval,
+	}
+	if lkg != nil {
+		cm.watcher = make(chan error, 1)
+		go func() {
+			for err := range mr.Watch() {
+				cm.watcher <- err
+			}
+			close(cm.watcher)
+		}()
+	}
+	return cm, nil
+}
+
+// NewConfigProviderFromConf returns a ConfigProvider that provides the configuration from the given
+// in-memory confmap.Conf, instead of retrieving it from config URIs.
+//
+// This allows applications embedding the Collector to generate the configuration in code, without
+// temporary files or custom providers. The default converters are applied to the given conf,
+// so environment variables references like "${ENV}" are expanded. The returned ConfigProvider
+// never reports configuration changes.
+func NewConfigProviderFromConf(conf *confmap.Conf) (ConfigProvider, error) {
+	if conf == nil {
+		return nil, errors.New("invalid nil confmap.Conf")
+	}
+	set := newDefaultConfigProviderSettings([]string{inMemorySchemeName + ":"})
+	set.ResolverSettings.Providers[inMemorySchemeName] = &inMemoryProvider{conf: conf}
+	return NewConfigProvider(set)
+}
+
+// NewConfigProviderFromBytes returns a ConfigProvider that provides the configuration parsed from
+// the given YAML encoded bytes. See NewConfigProviderFromConf for more details.
+func NewConfigProviderFromBytes(yamlBytes []byte) (ConfigProvider, error) {
+	var rawConf map[string]interface{}
+	if err := yaml.Unmarshal(yamlBytes, &rawConf); err != nil {
+		return nil, fmt.Errorf("cannot parse the configuration: %w", err)
+	}
+	return NewConfigProviderFromConf(confmap.NewFromStringMap(rawConf))
+}
+
+func (cm *configProvider) unvalidatedConfig(ctx context.Context, factories component.Factories) (*confmap.Conf, *Config, error) {
+	retMap, err := cm.mapResolver.Resolve(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot resolve the configuration: %w", err)
+	}
+	cfg, err := configunmarshaler.New().Unmarshal(retMap, factories)
+	if err != nil {
+		return retMap, nil, fmt.Errorf("cannot unmarshal the configuration: %w", err)
+	}
+	return retMap, cfg, nil
 }
 
 func (cm *configProvider) Get(ctx context.Context, factories component.Factories) (*Config, error) {
+	cm.stopRetryResolve()
+	cm.lkgErr = nil
+	cm.lkgPending = nil
+	cm.warnings.reset()
 	retMap, err := cm.mapResolver.Resolve(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("cannot resolve the configuration: %w", err)
+		// The last known good configuration is not used if the configuration is not found, not
+		// accessible or invalid, since it must be fixed rather than waited for.
+		if cm.lkg == nil || cm.gotConfig || confmap.IsPermanent(err) {
+			return nil, fmt.Errorf("cannot resolve the configuration: %w", err)
+		}
+		lkgMap, lkgErr := cm.lkg.load()
+		if lkgErr != nil {
+			return nil, fmt.Errorf("cannot resolve the configuration: %w; cannot load the last known good configuration: %v", err, lkgErr)
+		}
+		retMap = lkgMap
+		cm.lkgErr = err
+	}
+
+	if err = validateConfigSchemas(retMap, factories); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
 	var cfg *Config
@@ -128,20 +366,139 @@ func (cm *configProvider) Get(ctx context.Context, factories component.Factories
 	}
 
 	if err = cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+		return nil, fmt.Errorf("invalid configuration: %w", withComponentPosition(retMap, err))
+	}
+
+	// Resolve again once the service config_poll_interval changes, so that the remote providers
+	// poll at the configured interval right away.
+	if cm.pollInterval == 0 && cm.lkgErr == nil && cfg.Service.ConfigPollInterval != cm.servicePollInterval {
+		cm.servicePollInterval = cfg.Service.ConfigPollInterval
+		cm.mapResolver.SetPollInterval(cm.servicePollInterval)
+		return cm.Get(ctx, factories)
+	}
+
+	hash, err := computeConfigHash(retMap)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compute the configuration hash: %w", err)
+	}
+
+	switch {
+	case cm.lkgErr != nil:
+		retryCtx, cancel := context.WithCancel(context.Background())
+		cm.retryCancel = cancel
+		cm.retryDone = make(chan struct{})
+		go func() {
+			defer close(cm.retryDone)
+			cm.lkg.retryResolve(retryCtx, cm.mapResolver, cm.watcher)
+		}()
+	case cm.lkg != nil:
+		cm.lkgPending = retMap
 	}
 
+	cm.prevConf, cm.prevHash, cm.prevDiff = cm.conf, cm.hash, cm.diff
+	cm.diff = diffConf(cm.conf, retMap)
+	cm.conf = retMap
+	cm.hash = hash
+	cm.gotConfig = true
 	return cfg, nil
 }
 
+func (cm *configProvider) effectiveConfig() *confmap.Conf {
+	return cm.conf
+}
+
+func (cm *configProvider) configSources() []string {
+	return cm.uris
+}
+
+func (cm *configProvider) configDiff() configDiff {
+	return cm.diff
+}
+
+func (cm *configProvider) configHash() string {
+	return cm.hash
+}
+
+func (cm *configProvider) lastKnownGoodErr() error {
+	return cm.lkgErr
+}
+
+func (cm *configProvider) saveLastKnownGood() error {
+	if cm.lkgPending == nil {
+		return nil
+	}
+	if err := cm.lkg.save(cm.lkgPending); err != nil {
+		return fmt.Errorf("cannot persist the last known good configuration: %w", err)
+	}
+	cm.lkgPending = nil
+	return nil
+}
+
+func (cm *configProvider) rollbackConfig() {
+	cm.conf, cm.hash, cm.diff = cm.prevConf, cm.prevHash, cm.prevDiff
+	cm.lkgPending = nil
+}
+
+func (cm *configProvider) configWarnings() []string {
+	return cm.warnings.reset()
+}
+
+// computeConfigHash returns a stable hash of the resolved configuration. The configuration
+// is encoded as JSON, which sorts the map keys, so equal configurations have equal hashes
+// regardless of the order of the keys in the config sources.
+func computeConfigHash(conf *confmap.Conf) (string, error) {
+	b, err := json.Marshal(conf.ToStringMap())
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 func (cm *configProvider) Watch() <-chan error {
+	if cm.watcher != nil {
+		return cm.watcher
+	}
 	return cm.mapResolver.Watch()
 }
 
 func (cm *configProvider) Shutdown(ctx context.Context) error {
+	cm.stopRetryResolve()
 	return cm.mapResolver.Shutdown(ctx)
 }
 
+// stopRetryResolve stops resolving the configuration in the background, if started
+// after returning the last known good configuration.
+func (cm *configProvider) stopRetryResolve() {
+	if cm.retryCancel != nil {
+		cm.retryCancel()
+		<-cm.retryDone
+		cm.retryCancel = nil
+	}
+}
+
+const inMemorySchemeName = "inmemory"
+
+// inMemoryProvider is a confmap.Provider that always returns a copy of a fixed confmap.Conf.
+type inMemoryProvider struct {
+	conf *confmap.Conf
+}
+
+func (p *inMemoryProvider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if uri != inMemorySchemeName+":" {
+		return nil, fmt.Errorf("%q uri is not supported by %q provider", uri, inMemorySchemeName)
+	}
+	return confmap.NewRetrieved(p.conf.ToStringMap())
+}
+
+func (*inMemoryProvider) Scheme() string {
+	return inMemorySchemeName
+}
+
+func (*inMemoryProvider) Shutdown(context.Context) error {
+	return nil
+}
+
 func makeMapProvidersMap(providers ...confmap.Provider) map[string]confmap.Provider {
 	ret := make(map[string]confmap.Provider, len(providers))
 	for _, provider := range providers {
@@ -149,3 +506,13 @@ func makeMapProvidersMap(providers ...confmap.Provider) map[string]confmap.Provi
 	}
 	return ret
 }
+
+// withComponentPosition annotates the error with the position of the invalid component in the
+// configuration sources, if known.
+func withComponentPosition(conf *confmap.Conf, err error) error {
+	var compErr *config.ComponentValidationError
+	if !errors.As(err, &compErr) {
+		return err
+	}
+	return conf.WithPosition(compErr.Kind+"s"+confmap.KeyDelimiter+compErr.ID.String(), err)
+}
@@ -16,13 +16,21 @@ package service
 
 import (
 	"context"
+	"errors"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+	"go.opentelemetry.io/collector/service/featuregate"
 )
 
 func TestConfigProviderValidationError(t *testing.T) {
@@ -39,3 +47,227 @@ func TestConfigProviderValidationError(t *testing.T) {
 
 	assert.NoError(t, cfgW.Shutdown(context.Background()))
 }
+
+func TestWithComponentPosition(t *testing.T) {
+	uri := "file:" + filepath.ToSlash(filepath.Join("testdata", "otelcol-nop.yaml"))
+	ret, err := fileprovider.New().Retrieve(context.Background(), uri, nil)
+	require.NoError(t, err)
+	conf, err := ret.AsConf()
+	require.NoError(t, err)
+
+	errInvalid := errors.New("invalid")
+	err = withComponentPosition(conf, &config.ComponentValidationError{Kind: "exporter", ID: config.NewComponentID("nop"), Err: errInvalid})
+	assert.EqualError(t, err, `uri=`+uri+` line 8 column 3, key exporters::nop: exporter "nop" has invalid configuration: invalid`)
+	assert.ErrorIs(t, err, errInvalid)
+
+	assert.Equal(t, errInvalid, withComponentPosition(conf, errInvalid))
+}
+
+func TestConfigProviderFromConf(t *testing.T) {
+	factories, errF := componenttest.NopFactories()
+	require.NoError(t, errF)
+
+	t.Setenv("NOP_PIPELINE", "nop")
+	conf := confmap.NewFromStringMap(map[string]interface{}{
+		"receivers":  map[string]interface{}{"nop": nil},
+		"exporters":  map[string]interface{}{"nop": nil},
+		"extensions": map[string]interface{}{"nop": nil},
+		"service": map[string]interface{}{
+			"extensions": []interface{}{"nop"},
+			"pipelines": map[string]interface{}{
+				"traces": map[string]interface{}{
+					"receivers": []interface{}{"${NOP_PIPELINE}"},
+					"exporters": []interface{}{"nop"},
+				},
+			},
+		},
+	})
+
+	cfgW, err := NewConfigProviderFromConf(conf)
+	require.NoError(t, err)
+
+	cfg, err := cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.Len(t, cfg.Receivers, 1)
+	assert.Len(t, cfg.Service.Pipelines, 1)
+
+	// Config is returned again on subsequent calls.
+	_, err = cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.NoError(t, cfgW.Shutdown(context.Background()))
+}
+
+func TestConfigProviderFromConfNil(t *testing.T) {
+	_, err := NewConfigProviderFromConf(nil)
+	assert.Error(t, err)
+}
+
+func TestConfigProviderFromBytes(t *testing.T) {
+	factories, errF := componenttest.NopFactories()
+	require.NoError(t, errF)
+
+	content, err := os.ReadFile(filepath.Join("testdata", "otelcol-nop.yaml"))
+	require.NoError(t, err)
+
+	cfgW, err := NewConfigProviderFromBytes(content)
+	require.NoError(t, err)
+
+	cfg, err := cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.Len(t, cfg.Service.Pipelines, 3)
+	assert.NoError(t, cfgW.Shutdown(context.Background()))
+
+	_, err = NewConfigProviderFromBytes([]byte("[invalid"))
+	assert.Error(t, err)
+}
+
+func TestConfigProviderConfigHash(t *testing.T) {
+	factories, errF := componenttest.NopFactories()
+	require.NoError(t, errF)
+
+	cfgW, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{filepath.Join("testdata", "otelcol-nop.yaml")}))
+	require.NoError(t, err)
+	hp, ok := cfgW.(configHashProvider)
+	require.True(t, ok)
+	assert.Empty(t, hp.configHash())
+
+	_, err = cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	hash := hp.configHash()
+	assert.Len(t, hash, 64)
+
+	// Same configuration, same hash.
+	_, err = cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.Equal(t, hash, hp.configHash())
+	assert.NoError(t, cfgW.Shutdown(context.Background()))
+}
+
+func TestConfigProviderRollbackConfig(t *testing.T) {
+	factories, errF := componenttest.NopFactories()
+	require.NoError(t, errF)
+
+	base, err := os.ReadFile(filepath.Join("testdata", "otelcol-nop.yaml"))
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, base, 0600))
+	cfgW, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{file}))
+	require.NoError(t, err)
+	cm := cfgW.(*configProvider)
+
+	_, err = cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	conf, hash, diff := cm.effectiveConfig(), cm.configHash(), cm.configDiff()
+
+	require.NoError(t, os.WriteFile(file, append(base, []byte("  telemetry:\n    logs:\n      level: debug\n")...), 0600))
+	_, err = cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, cm.configHash())
+	assert.Equal(t, []string{"service::telemetry::logs::level"}, cm.configDiff().added)
+
+	// The service rolled back to the previous configuration.
+	cm.rollbackConfig()
+	assert.Same(t, conf, cm.effectiveConfig())
+	assert.Equal(t, hash, cm.configHash())
+	assert.Equal(t, diff, cm.configDiff())
+
+	// The next configuration is compared to the restored one.
+	_, err = cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"service::telemetry::logs::level"}, cm.configDiff().added)
+	assert.NoError(t, cfgW.Shutdown(context.Background()))
+}
+
+// remoteProvider is a remote confmap.Provider supporting watching, recording the retrieved URIs.
+type remoteProvider struct {
+	conf map[string]interface{}
+	uris []string
+}
+
+func (p *remoteProvider) Retrieve(_ context.Context, uri string, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	p.uris = append(p.uris, uri)
+	return confmap.NewRetrieved(p.conf)
+}
+
+func (p *remoteProvider) Capabilities() confmap.ProviderCapabilities {
+	return confmap.ProviderCapabilities{SupportsWatch: true, IsRemote: true}
+}
+
+func (*remoteProvider) Scheme() string {
+	return "remote"
+}
+
+func (*remoteProvider) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestConfigProviderServicePollInterval(t *testing.T) {
+	factories, errF := componenttest.NopFactories()
+	require.NoError(t, errF)
+
+	content, err := os.ReadFile(filepath.Join("testdata", "otelcol-nop.yaml"))
+	require.NoError(t, err)
+	var conf map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(content, &conf))
+	conf["service"].(map[string]interface{})["config_poll_interval"] = "30s"
+
+	newProvider := func(pollInterval time.Duration) (ConfigProvider, *remoteProvider) {
+		remote := &remoteProvider{conf: conf}
+		set := newDefaultConfigProviderSettings([]string{"remote:config"})
+		set.ResolverSettings.Providers[remote.Scheme()] = remote
+		set.ResolverSettings.PollInterval = pollInterval
+		cfgW, errCfg := NewConfigProvider(set)
+		require.NoError(t, errCfg)
+		return cfgW, remote
+	}
+
+	// The configuration is retrieved again with the poll interval of the service.
+	cfgW, remote := newProvider(0)
+	cfg, err := cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, cfg.Service.ConfigPollInterval)
+	assert.Equal(t, []string{"remote:config", "remote:config?poll_interval=30s"}, remote.uris)
+
+	_, err = cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"remote:config", "remote:config?poll_interval=30s", "remote:config?poll_interval=30s"}, remote.uris)
+	assert.NoError(t, cfgW.Shutdown(context.Background()))
+
+	// The poll interval of the settings takes precedence.
+	cfgW, remote = newProvider(time.Minute)
+	_, err = cfgW.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"remote:config?poll_interval=1m0s"}, remote.uris)
+	assert.NoError(t, cfgW.Shutdown(context.Background()))
+}
+
+func TestConfigProviderExpandEnabledGate(t *testing.T) {
+	t.Setenv("OTELCOL_TEST_EXPAND", "expanded")
+	resolve := func() interface{} {
+		cfgW, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{"yaml:key: ${env:OTELCOL_TEST_EXPAND}"}))
+		require.NoError(t, err)
+		conf, err := cfgW.(*configProvider).mapResolver.Resolve(context.Background())
+		require.NoError(t, err)
+		assert.NoError(t, cfgW.Shutdown(context.Background()))
+		return conf.Get("key")
+	}
+
+	// Without the gate the reference is left to the expandconverter, which doesn't support it.
+	assert.Equal(t, "", resolve())
+
+	featuregate.GetRegistry().MustApply(map[string]bool{expandEnabledGateID: true})
+	defer featuregate.GetRegistry().MustApply(map[string]bool{expandEnabledGateID: false})
+	assert.Equal(t, "expanded", resolve())
+}
+
+func TestComputeConfigHash(t *testing.T) {
+	hash1, err := computeConfigHash(confmap.NewFromStringMap(map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": "d", "e": "f"}}))
+	require.NoError(t, err)
+	hash2, err := computeConfigHash(confmap.NewFromStringMap(map[string]interface{}{"b": map[string]interface{}{"e": "f", "c": "d"}, "a": 1}))
+	require.NoError(t, err)
+	assert.Equal(t, hash1, hash2)
+
+	hash3, err := computeConfigHash(confmap.NewFromStringMap(map[string]interface{}{"a": 2, "b": map[string]interface{}{"c": "d", "e": "f"}}))
+	require.NoError(t, err)
+	assert.NotEqual(t, hash1, hash3)
+}
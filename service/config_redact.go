@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"reflect"
+	"strings"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeyParts are the parts of the config keys whose values are redacted.
+var sensitiveKeyParts = []string{"password", "secret", "token", "key", "credential", "auth", "bearer"}
+
+// redactConf returns a copy of the given raw configuration, where all the values of the keys that
+// may hold secrets, of the opaque keys, and all the values nested under them, are replaced.
+// The opaque keys, lower case and with KeyDelimiter separators, may be nil.
+func redactConf(conf map[string]interface{}, opaque map[string]bool) map[string]interface{} {
+	return redactValue(conf, "", false, opaque).(map[string]interface{})
+}
+
+func redactValue(value interface{}, key string, redact bool, opaque map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			childKey := strings.ToLower(k)
+			if key != "" {
+				childKey = key + confmap.KeyDelimiter + childKey
+			}
+			ret[k] = redactValue(val, childKey, redact || isSensitiveKey(k) || opaque[childKey], opaque)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, 0, len(v))
+		for _, val := range v {
+			ret = append(ret, redactValue(val, key, redact, opaque))
+		}
+		return ret
+	case nil:
+		return nil
+	}
+	if redact {
+		return redactedValue
+	}
+	return value
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedKeys returns the keys to redact in the given effective configuration of cfg, lower case and
+// with KeyDelimiter separators: the opaque keys of the components, and the keys whose values were
+// retrieved from config providers returning secrets.
+func redactedKeys(cfg *Config, conf *confmap.Conf) map[string]bool {
+	keys := opaqueKeys(cfg)
+	for _, k := range conf.AllKeys() {
+		if src, ok := conf.Source(k); ok && src.Secret {
+			keys[strings.ToLower(k)] = true
+		}
+	}
+	return keys
+}
+
+var opaqueStringType = reflect.TypeOf(configopaque.String(""))
+
+// opaqueKeys returns the keys of the configuration of the components holding configopaque.String
+// values, lower case and with KeyDelimiter separators, so that they are redacted whatever their names.
+func opaqueKeys(cfg *Config) map[string]bool {
+	keys := map[string]bool{}
+	for id, c := range cfg.Receivers {
+		collectOpaqueKeys(reflect.ValueOf(c), "receivers"+confmap.KeyDelimiter+id.String(), keys)
+	}
+	for id, c := range cfg.Processors {
+		collectOpaqueKeys(reflect.ValueOf(c), "processors"+confmap.KeyDelimiter+id.String(), keys)
+	}
+	for id, c := range cfg.Exporters {
+		collectOpaqueKeys(reflect.ValueOf(c), "exporters"+confmap.KeyDelimiter+id.String(), keys)
+	}
+	for id, c := range cfg.Extensions {
+		collectOpaqueKeys(reflect.ValueOf(c), "extensions"+confmap.KeyDelimiter+id.String(), keys)
+	}
+	return keys
+}
+
+// collectOpaqueKeys adds the keys of the configopaque.String values found under v, named after
+// the mapstructure tags of the struct fields.
+func collectOpaqueKeys(v reflect.Value, key string, keys map[string]bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			collectOpaqueKeys(v.Elem(), key, keys)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, squash := mapstructureName(field)
+			switch {
+			case name == "-":
+				continue
+			case squash:
+				collectOpaqueKeys(v.Field(i), key, keys)
+			default:
+				collectOpaqueKeys(v.Field(i), key+confmap.KeyDelimiter+name, keys)
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			collectOpaqueKeys(iter.Value(), key+confmap.KeyDelimiter+strings.ToLower(iter.Key().String()), keys)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem() == opaqueStringType {
+			keys[strings.ToLower(key)] = true
+		}
+	case reflect.String:
+		if v.Type() == opaqueStringType {
+			keys[strings.ToLower(key)] = true
+		}
+	}
+}
+
+// mapstructureName returns the name of the field in the configuration, and whether it is squashed.
+func mapstructureName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("mapstructure")
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "squash" {
+			return "", true
+		}
+	}
+	if parts[0] == "" {
+		return strings.ToLower(field.Name), false
+	}
+	return strings.ToLower(parts[0]), false
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+func TestRedactConf(t *testing.T) {
+	conf := map[string]interface{}{
+		"exporters": map[string]interface{}{
+			"otlp": map[string]interface{}{
+				"endpoint": "localhost:4317",
+				"headers":  map[string]interface{}{"X-API-Key": "abc", "X-Tenant": "t1"},
+				"tls":      map[string]interface{}{"insecure": true, "key_file": "/etc/key.pem"},
+			},
+		},
+		"extensions": map[string]interface{}{
+			"basicauth": map[string]interface{}{
+				"client_auth": map[string]interface{}{"username": "user", "password": "pass"},
+			},
+			"zpages": nil,
+		},
+		"receivers": map[string]interface{}{
+			"otlp": map[string]interface{}{"secrets": []interface{}{"a", "b"}, "ports": []interface{}{1, 2}},
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"exporters": map[string]interface{}{
+			"otlp": map[string]interface{}{
+				"endpoint": "localhost:4317",
+				"headers":  map[string]interface{}{"X-API-Key": redactedValue, "X-Tenant": "t1"},
+				"tls":      map[string]interface{}{"insecure": true, "key_file": redactedValue},
+			},
+		},
+		"extensions": map[string]interface{}{
+			"basicauth": map[string]interface{}{
+				"client_auth": map[string]interface{}{"username": redactedValue, "password": redactedValue},
+			},
+			"zpages": nil,
+		},
+		"receivers": map[string]interface{}{
+			"otlp": map[string]interface{}{"secrets": []interface{}{redactedValue, redactedValue}, "ports": []interface{}{1, 2}},
+		},
+	}, redactConf(conf, nil))
+
+	// The input is not modified.
+	assert.Equal(t, "pass", conf["extensions"].(map[string]interface{})["basicauth"].(map[string]interface{})["client_auth"].(map[string]interface{})["password"])
+}
+
+type opaqueTestConfig struct {
+	config.ExporterSettings `mapstructure:",squash"`
+	Endpoint                string                         `mapstructure:"endpoint"`
+	APIKey                  configopaque.String            `mapstructure:"api_key"`
+	Passphrase              configopaque.String            `mapstructure:"passphrase"`
+	Headers                 map[string]configopaque.String `mapstructure:"headers"`
+	Nested                  *struct {
+		Value configopaque.String
+	} `mapstructure:"nested"`
+}
+
+func TestOpaqueKeys(t *testing.T) {
+	id := config.NewComponentIDWithName("test", "Main")
+	cfg := &Config{Exporters: map[config.ComponentID]config.Exporter{
+		id: &opaqueTestConfig{
+			ExporterSettings: config.NewExporterSettings(id),
+			Headers:          map[string]configopaque.String{"X-Tenant": "t1"},
+			Nested: &struct {
+				Value configopaque.String
+			}{},
+		},
+	}}
+	keys := opaqueKeys(cfg)
+	assert.Equal(t, map[string]bool{
+		"exporters::test/main::api_key":           true,
+		"exporters::test/main::passphrase":        true,
+		"exporters::test/main::headers::x-tenant": true,
+		"exporters::test/main::nested::value":     true,
+	}, keys)
+
+	conf := map[string]interface{}{
+		"exporters": map[string]interface{}{
+			"test/Main": map[string]interface{}{
+				"endpoint":   "localhost:4317",
+				"passphrase": "open sesame",
+				"headers":    map[string]interface{}{"X-Tenant": "t1"},
+			},
+		},
+	}
+	assert.Equal(t, map[string]interface{}{
+		"exporters": map[string]interface{}{
+			"test/Main": map[string]interface{}{
+				"endpoint":   "localhost:4317",
+				"passphrase": redactedValue,
+				"headers":    map[string]interface{}{"X-Tenant": redactedValue},
+			},
+		},
+	}, redactConf(conf, keys))
+}
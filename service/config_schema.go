@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"errors"
+	"sort"
+
+	"go.uber.org/multierr"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// validateConfigSchemas validates the configuration section of every component whose factory
+// implements component.ConfigSchemaProvider, before the configuration is unmarshaled. All the errors
+// are returned combined, as *config.ComponentValidationError wrapping the *confmap.SchemaError,
+// annotated with their positions if known.
+func validateConfigSchemas(conf *confmap.Conf, factories component.Factories) error {
+	var errs error
+	for _, kind := range []struct {
+		name    string
+		factory func(config.Type) (component.Factory, bool)
+	}{
+		{name: "receiver", factory: func(t config.Type) (component.Factory, bool) { f, ok := factories.Receivers[t]; return f, ok }},
+		{name: "processor", factory: func(t config.Type) (component.Factory, bool) { f, ok := factories.Processors[t]; return f, ok }},
+		{name: "exporter", factory: func(t config.Type) (component.Factory, bool) { f, ok := factories.Exporters[t]; return f, ok }},
+		{name: "extension", factory: func(t config.Type) (component.Factory, bool) { f, ok := factories.Extensions[t]; return f, ok }},
+	} {
+		section, ok := conf.Get(kind.name + "s").(map[string]interface{})
+		if !ok {
+			continue
+		}
+		keys := make([]string, 0, len(section))
+		for key := range section {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			// The invalid IDs and the unknown types are reported when unmarshaling.
+			id, err := config.NewComponentIDFromString(key)
+			if err != nil {
+				continue
+			}
+			factory, ok := kind.factory(id.Type())
+			if !ok {
+				continue
+			}
+			sp, ok := factory.(component.ConfigSchemaProvider)
+			if !ok {
+				continue
+			}
+			for _, e := range multierr.Errors(sp.ConfigSchema().Validate(kind.name+"s"+confmap.KeyDelimiter+key, section[key])) {
+				var schemaErr *confmap.SchemaError
+				posKey := ""
+				if errors.As(e, &schemaErr) {
+					posKey = schemaErr.Key
+				}
+				errs = multierr.Append(errs, conf.WithPosition(posKey, &config.ComponentValidationError{Kind: kind.name, ID: id, Err: e}))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// schemaExporterFactory publishes a schema for the configuration of the nop exporter.
+type schemaExporterFactory struct {
+	component.ExporterFactory
+}
+
+func (schemaExporterFactory) ConfigSchema() *confmap.Schema {
+	return &confmap.Schema{
+		Type: confmap.SchemaTypeObject,
+		Properties: map[string]*confmap.Schema{
+			"endpoint": {Type: confmap.SchemaTypeString},
+			"retries":  {Type: confmap.SchemaTypeInteger},
+		},
+	}
+}
+
+func newSchemaTestFactories(t *testing.T) component.Factories {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	factories.Exporters["nop"] = schemaExporterFactory{ExporterFactory: factories.Exporters["nop"]}
+	return factories
+}
+
+func TestValidateConfigSchemas(t *testing.T) {
+	ret, err := confmap.NewRetrieved(map[string]interface{}{
+		"receivers": map[string]interface{}{"nop": map[string]interface{}{"endpoint": []interface{}{"ignored"}}},
+		"exporters": map[string]interface{}{
+			"nop":     map[string]interface{}{"endpoint": "localhost:4317", "retries": "3"},
+			"nop/bad": map[string]interface{}{"endpoint": map[string]interface{}{"host": "localhost"}, "retries": "many"},
+			"unknown": map[string]interface{}{"endpoint": 1},
+		},
+	}, confmap.WithRetrievedPositions(map[string]confmap.Position{
+		"exporters::nop/bad":          {URI: "file:config.yaml", Line: 5, Column: 3},
+		"exporters::nop/bad::retries": {URI: "file:config.yaml", Line: 7, Column: 5},
+	}))
+	require.NoError(t, err)
+	conf, err := ret.AsConf()
+	require.NoError(t, err)
+
+	errs := multierr.Errors(validateConfigSchemas(conf, newSchemaTestFactories(t)))
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], `uri=file:config.yaml line 5 column 3, key exporters::nop/bad::endpoint: exporter "nop/bad" has invalid configuration: exporters::nop/bad::endpoint must be a string`)
+	assert.EqualError(t, errs[1], `uri=file:config.yaml line 7 column 5, key exporters::nop/bad::retries: exporter "nop/bad" has invalid configuration: exporters::nop/bad::retries must be an integer`)
+
+	// Without schemas nothing is validated.
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	assert.NoError(t, validateConfigSchemas(conf, factories))
+}
+
+func TestConfigProviderSchemaError(t *testing.T) {
+	cp, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{
+		"file:testdata/otelcol-nop.yaml",
+		"yaml:exporters::nop::retries: many",
+	}))
+	require.NoError(t, err)
+	_, err = cp.Get(context.Background(), newSchemaTestFactories(t))
+	assert.ErrorContains(t, err, "exporters::nop::retries must be an integer")
+	require.NoError(t, cp.Shutdown(context.Background()))
+}
+
+func TestValidateCommandSchemaStage(t *testing.T) {
+	cmd := NewCommand(CollectorSettings{Factories: newSchemaTestFactories(t)})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"validate", "--config", "file:testdata/otelcol-nop.yaml", "--set", "exporters.nop.retries=many", "--format", "json"})
+	assert.Equal(t, errInvalidConfig, cmd.Execute())
+
+	var report validationReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, validationError{
+		Stage:     validateStageSchema,
+		Component: "exporter nop",
+		Key:       "exporters::nop::retries",
+		URI:       "file:testdata/otelcol-nop.yaml",
+		Line:      8,
+		Column:    3,
+		Message:   "exporters::nop::retries must be an integer",
+	}, report.Errors[0])
+}
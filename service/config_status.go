@@ -0,0 +1,190 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultConfigStatusTimeout = 5 * time.Second
+
+	// configStatusQueueSize is the number of reports waiting to be sent, the next ones are dropped.
+	configStatusQueueSize = 16
+)
+
+// ConfigStatusSettings configures reporting the outcome of every configuration load to a callback
+// URL, so that control planes can track the convergence of configuration rollouts.
+type ConfigStatusSettings struct {
+	// URL the status reports are POSTed to, as JSON documents. Empty disables the reports.
+	URL string
+
+	// Token is the bearer token sent in the Authorization header of the reports, if set.
+	Token string
+
+	// Identity of the Collector in the reports. Defaults to the host name.
+	Identity string
+
+	// Timeout of every report. Defaults to 5s.
+	Timeout time.Duration
+}
+
+// configStatusReport is the JSON document POSTed to the callback URL.
+type configStatusReport struct {
+	Identity           string    `json:"identity"`
+	Version            string    `json:"version"`
+	Time               time.Time `json:"time"`
+	Trigger            string    `json:"trigger"`
+	Outcome            string    `json:"outcome"`
+	Success            bool      `json:"success"`
+	ConfigHash         string    `json:"config_hash,omitempty"`
+	PreviousConfigHash string    `json:"previous_config_hash,omitempty"`
+	Sources            []string  `json:"sources,omitempty"`
+	Error              string    `json:"error,omitempty"`
+}
+
+type queuedConfigStatusReport struct {
+	report configStatusReport
+	logger *zap.Logger
+}
+
+// configStatusReporter sends the status reports in the background, in order, so that the
+// configuration loads are never delayed by the callback URL.
+type configStatusReporter struct {
+	set     ConfigStatusSettings
+	client  *http.Client
+	reports chan queuedConfigStatusReport
+	done    chan struct{}
+}
+
+func newConfigStatusReporter(set ConfigStatusSettings) *configStatusReporter {
+	if set.Timeout <= 0 {
+		set.Timeout = defaultConfigStatusTimeout
+	}
+	if set.Identity == "" {
+		set.Identity, _ = os.Hostname()
+	}
+	csr := &configStatusReporter{
+		set:     set,
+		client:  &http.Client{Timeout: set.Timeout},
+		reports: make(chan queuedConfigStatusReport, configStatusQueueSize),
+		done:    make(chan struct{}),
+	}
+	go csr.run()
+	return csr
+}
+
+// report queues the report, filling its identity and time. Failures are logged to logger.
+func (csr *configStatusReporter) report(logger *zap.Logger, report configStatusReport) {
+	report.Identity = csr.set.Identity
+	report.Time = time.Now().UTC()
+	select {
+	case csr.reports <- queuedConfigStatusReport{report: report, logger: logger}:
+	default:
+		logger.Warn("Too many pending configuration status reports, dropping one",
+			zap.String("outcome", report.Outcome), zap.String("config_hash", report.ConfigHash))
+	}
+}
+
+func (csr *configStatusReporter) run() {
+	defer close(csr.done)
+	for queued := range csr.reports {
+		if err := csr.send(queued.report); err != nil {
+			queued.logger.Warn("Failed to send the configuration status report", zap.String("url", csr.set.URL), zap.Error(err))
+		}
+	}
+}
+
+func (csr *configStatusReporter) send(report configStatusReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, csr.set.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if csr.set.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+csr.set.Token)
+	}
+	resp, err := csr.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected HTTP status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// shutdown sends the pending reports, waiting until the context is done.
+func (csr *configStatusReporter) shutdown(ctx context.Context) error {
+	close(csr.reports)
+	select {
+	case <-csr.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reportConfigStatus reports the outcome of a configuration load, if enabled.
+func (col *Collector) reportConfigStatus(logger *zap.Logger, trigger, outcome, prevHash string, err error) {
+	if col.status == nil {
+		return
+	}
+	report := configStatusReport{
+		Version:            col.set.BuildInfo.Version,
+		Trigger:            trigger,
+		Outcome:            outcome,
+		Success:            outcome == configOutcomeApplied,
+		PreviousConfigHash: prevHash,
+	}
+	if outcome != configOutcomeRejected {
+		report.ConfigHash = col.configHash()
+	}
+	if auditor, ok := col.set.ConfigProvider.(configAuditor); ok {
+		report.Sources = auditor.configSources()
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	col.status.report(logger, report)
+}
+
+// shutdownConfigStatus sends the pending status reports, within the timeout of a report.
+func (col *Collector) shutdownConfigStatus(ctx context.Context) error {
+	if col.status == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, col.status.set.Timeout)
+	defer cancel()
+	if err := col.status.shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to send the pending configuration status reports: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/service/featuregate"
+)
+
+// statusServer records the configuration status reports it receives.
+type statusServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	reports []configStatusReport
+	auth    []string
+	status  int
+}
+
+func newStatusServer(t *testing.T, status int) *statusServer {
+	ss := &statusServer{status: status}
+	ss.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report configStatusReport
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&report))
+		ss.mu.Lock()
+		ss.reports = append(ss.reports, report)
+		ss.auth = append(ss.auth, r.Header.Get("Authorization"))
+		ss.mu.Unlock()
+		w.WriteHeader(ss.status)
+	}))
+	t.Cleanup(ss.Close)
+	return ss
+}
+
+func (ss *statusServer) received() ([]configStatusReport, []string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return append([]configStatusReport{}, ss.reports...), append([]string{}, ss.auth...)
+}
+
+func TestConfigStatusReporter(t *testing.T) {
+	ss := newStatusServer(t, http.StatusNoContent)
+	csr := newConfigStatusReporter(ConfigStatusSettings{URL: ss.URL, Token: "secret", Identity: "gateway-0"})
+	assert.Equal(t, defaultConfigStatusTimeout, csr.set.Timeout)
+
+	csr.report(zap.NewNop(), configStatusReport{Outcome: configOutcomeApplied, ConfigHash: "a"})
+	csr.report(zap.NewNop(), configStatusReport{Outcome: configOutcomeRejected, Error: "invalid"})
+	csr.report(zap.NewNop(), configStatusReport{Outcome: configOutcomeApplied, ConfigHash: "b"})
+	require.NoError(t, csr.shutdown(context.Background()))
+
+	reports, auth := ss.received()
+	require.Len(t, reports, 3)
+	assert.Equal(t, []string{"Bearer secret", "Bearer secret", "Bearer secret"}, auth)
+	for _, report := range reports {
+		assert.Equal(t, "gateway-0", report.Identity)
+		assert.False(t, report.Time.IsZero())
+	}
+	assert.Equal(t, "a", reports[0].ConfigHash)
+	assert.Equal(t, "invalid", reports[1].Error)
+	assert.Equal(t, "b", reports[2].ConfigHash)
+}
+
+func TestConfigStatusReporterFailure(t *testing.T) {
+	ss := newStatusServer(t, http.StatusServiceUnavailable)
+	csr := newConfigStatusReporter(ConfigStatusSettings{URL: ss.URL})
+
+	core, logs := observer.New(zapcore.WarnLevel)
+	csr.report(zap.New(core), configStatusReport{Outcome: configOutcomeApplied})
+	require.NoError(t, csr.shutdown(context.Background()))
+
+	_, auth := ss.received()
+	assert.Equal(t, []string{""}, auth)
+	records := logs.FilterMessage("Failed to send the configuration status report").All()
+	require.Len(t, records, 1)
+	assert.Equal(t, "unexpected HTTP status code 503", records[0].ContextMap()["error"])
+}
+
+func TestCollectorConfigStatus(t *testing.T) {
+	ss := newStatusServer(t, http.StatusOK)
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	uri := filepath.Join("testdata", "otelcol-nop.yaml")
+	cfgProvider, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{uri}))
+	require.NoError(t, err)
+
+	col, err := New(CollectorSettings{
+		BuildInfo:      component.BuildInfo{Version: "1.2.3"},
+		Factories:      factories,
+		ConfigProvider: cfgProvider,
+		ConfigStatus:   ConfigStatusSettings{URL: ss.URL, Identity: "agent-0"},
+		telemetry:      newColTelemetry(featuregate.NewRegistry()),
+	})
+	require.NoError(t, err)
+
+	wg := startCollector(context.Background(), t, col)
+	assert.Eventually(t, func() bool {
+		return Running == col.GetState()
+	}, 2*time.Second, 10*time.Millisecond)
+	col.Shutdown()
+	wg.Wait()
+
+	reports, _ := ss.received()
+	require.Len(t, reports, 1)
+	assert.Equal(t, "agent-0", reports[0].Identity)
+	assert.Equal(t, "1.2.3", reports[0].Version)
+	assert.Equal(t, configTriggerStartup, reports[0].Trigger)
+	assert.Equal(t, configOutcomeApplied, reports[0].Outcome)
+	assert.True(t, reports[0].Success)
+	assert.Equal(t, col.configHash(), reports[0].ConfigHash)
+	assert.Equal(t, []string{uri}, reports[0].Sources)
+	assert.Empty(t, reports[0].Error)
+}
+
+func TestCollectorConfigStatusRejected(t *testing.T) {
+	ss := newStatusServer(t, http.StatusOK)
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	cfgProvider, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{filepath.Join("testdata", "otelcol-invalid.yaml")}))
+	require.NoError(t, err)
+
+	col, err := New(CollectorSettings{
+		BuildInfo:      component.NewDefaultBuildInfo(),
+		Factories:      factories,
+		ConfigProvider: cfgProvider,
+		ConfigStatus:   ConfigStatusSettings{URL: ss.URL},
+		telemetry:      newColTelemetry(featuregate.NewRegistry()),
+	})
+	require.NoError(t, err)
+	assert.Error(t, col.Run(context.Background()))
+
+	reports, _ := ss.received()
+	require.Len(t, reports, 1)
+	assert.Equal(t, configTriggerStartup, reports[0].Trigger)
+	assert.Equal(t, configOutcomeRejected, reports[0].Outcome)
+	assert.False(t, reports[0].Success)
+	assert.Empty(t, reports[0].ConfigHash)
+	assert.Contains(t, reports[0].Error, "invalid")
+}
@@ -16,8 +16,8 @@ package service
 
 import (
 	"errors"
-	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zapcore"
@@ -188,6 +188,15 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expected: errMissingServicePipelines,
 		},
+		{
+			name: "negative-config-poll-interval",
+			cfgFn: func() *Config {
+				cfg := generateConfig()
+				cfg.Service.ConfigPollInterval = -time.Second
+				return cfg
+			},
+			expected: errors.New("service config_poll_interval must not be negative"),
+		},
 		{
 			name: "invalid-receiver-config",
 			cfgFn: func() *Config {
@@ -197,7 +206,7 @@ func TestConfigValidate(t *testing.T) {
 				}
 				return cfg
 			},
-			expected: fmt.Errorf(`receiver "nop" has invalid configuration: %w`, errInvalidRecvConfig),
+			expected: &config.ComponentValidationError{Kind: "receiver", ID: config.NewComponentID("nop"), Err: errInvalidRecvConfig},
 		},
 		{
 			name: "invalid-exporter-config",
@@ -208,7 +217,7 @@ func TestConfigValidate(t *testing.T) {
 				}
 				return cfg
 			},
-			expected: fmt.Errorf(`exporter "nop" has invalid configuration: %w`, errInvalidExpConfig),
+			expected: &config.ComponentValidationError{Kind: "exporter", ID: config.NewComponentID("nop"), Err: errInvalidExpConfig},
 		},
 		{
 			name: "invalid-processor-config",
@@ -219,7 +228,38 @@ func TestConfigValidate(t *testing.T) {
 				}
 				return cfg
 			},
-			expected: fmt.Errorf(`processor "nop" has invalid configuration: %w`, errInvalidProcConfig),
+			expected: &config.ComponentValidationError{Kind: "processor", ID: config.NewComponentID("nop"), Err: errInvalidProcConfig},
+		},
+		{
+			name: "invalid-service-timeouts",
+			cfgFn: func() *Config {
+				cfg := generateConfig()
+				cfg.Service.Timeouts.Shutdown = -time.Second
+				return cfg
+			},
+			expected: errors.New("service timeouts: shutdown timeout must not be negative, got -1s"),
+		},
+		{
+			name: "invalid-service-timeouts-reference",
+			cfgFn: func() *Config {
+				cfg := generateConfig()
+				cfg.Service.Timeouts.Exporters = map[config.ComponentID]ConfigComponentTimeouts{
+					config.NewComponentIDWithName("nop", "2"): {Start: time.Second},
+				}
+				return cfg
+			},
+			expected: errors.New(`service timeouts reference exporter "nop/2" which does not exist`),
+		},
+		{
+			name: "invalid-service-component-timeouts",
+			cfgFn: func() *Config {
+				cfg := generateConfig()
+				cfg.Service.Timeouts.Receivers = map[config.ComponentID]ConfigComponentTimeouts{
+					config.NewComponentID("nop"): {Start: -time.Second},
+				}
+				return cfg
+			},
+			expected: errors.New(`service timeouts of receiver "nop": start timeout must not be negative, got -1s`),
 		},
 		{
 			name: "invalid-extension-config",
@@ -230,7 +270,7 @@ func TestConfigValidate(t *testing.T) {
 				}
 				return cfg
 			},
-			expected: fmt.Errorf(`extension "nop" has invalid configuration: %w`, errInvalidExtConfig),
+			expected: &config.ComponentValidationError{Kind: "extension", ID: config.NewComponentID("nop"), Err: errInvalidExtConfig},
 		},
 	}
 
@@ -0,0 +1,205 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime/pprof"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.opencensus.io/metric/metricproducer"
+	"go.uber.org/multierr"
+
+	"go.opentelemetry.io/collector/service/internal/runtimeinfo"
+)
+
+const (
+	debugBundlezPath = "debugbundlez"
+
+	defaultDebugBundleEndpoint = "localhost:55679"
+	defaultDebugBundleOutput   = "otelcol-debug-bundle.tar.gz"
+)
+
+// debugBundleFile is a file included in the diagnostics bundle.
+type debugBundleFile struct {
+	name  string
+	write func(w io.Writer) error
+}
+
+// handleDebugBundlezRequest serves a gzipped tar archive with the diagnostics of the running
+// collector, to be attached to support cases.
+func (host *serviceHost) handleDebugBundlezRequest(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := host.writeDebugBundle(&buf, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+defaultDebugBundleOutput+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// writeDebugBundle writes the diagnostics bundle as a gzipped tar archive.
+func (host *serviceHost) writeDebugBundle(w io.Writer, now time.Time) error {
+	files := []debugBundleFile{
+		{name: "build_info.json", write: func(w io.Writer) error {
+			return writeJSON(w, map[string]interface{}{
+				"command":     host.buildInfo.Command,
+				"description": host.buildInfo.Description,
+				"version":     host.buildInfo.Version,
+				"runtime":     runtimeinfo.Info(),
+			})
+		}},
+		{name: "config.json", write: func(w io.Writer) error {
+			return writeJSON(w, configzResponse{
+				ConfigHash: host.configHash,
+				Sources:    host.configSources,
+				Config:     host.effectiveConfig,
+			})
+		}},
+		{name: "feature_gates.json", write: func(w io.Writer) error {
+			return writeJSON(w, getFeaturesTableData().Rows)
+		}},
+		{name: "components.json", write: func(w io.Writer) error {
+			return writeJSON(w, host.getComponentsData())
+		}},
+		{name: "metrics.json", write: func(w io.Writer) error {
+			var metrics []interface{}
+			for _, p := range metricproducer.GlobalManager().GetAll() {
+				for _, m := range p.Read() {
+					metrics = append(metrics, m)
+				}
+			}
+			return writeJSON(w, metrics)
+		}},
+		{name: "goroutines.txt", write: func(w io.Writer) error {
+			return pprof.Lookup("goroutine").WriteTo(w, 2)
+		}},
+		{name: "heap.pprof", write: func(w io.Writer) error {
+			return pprof.Lookup("heap").WriteTo(w, 0)
+		}},
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		var buf bytes.Buffer
+		if err := f.write(&buf); err != nil {
+			return fmt.Errorf("failed to collect %q: %w", f.name, err)
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    f.name,
+			Mode:    0600,
+			Size:    int64(buf.Len()),
+			ModTime: now,
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// componentsData lists the components started by the service.
+type componentsData struct {
+	Extensions []string            `json:"extensions"`
+	Exporters  map[string][]string `json:"exporters"`
+}
+
+func (host *serviceHost) getComponentsData() componentsData {
+	data := componentsData{Exporters: map[string][]string{}}
+	for id := range host.GetExtensions() {
+		data.Extensions = append(data.Extensions, id.String())
+	}
+	sort.Strings(data.Extensions)
+	for dt, exps := range host.GetExporters() {
+		var ids []string
+		for id := range exps {
+			ids = append(ids, id.String())
+		}
+		sort.Strings(ids)
+		data.Exporters[string(dt)] = ids
+	}
+	return data
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// newDebugBundleCommand constructs the command that downloads the diagnostics bundle from a
+// running collector, using the zPages extension endpoint.
+func newDebugBundleCommand() *cobra.Command {
+	var endpoint, output string
+	cmd := &cobra.Command{
+		Use:   "debug-bundle",
+		Short: "Collects the diagnostics of a running collector into an archive",
+		Long: "Collects the build info, the redacted effective configuration, the feature gates, the internal metrics, " +
+			"the goroutine and heap profiles, and the started components of a running collector into a gzipped tar " +
+			"archive. Requires the zpages extension to be enabled.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return downloadDebugBundle(cmd, "http://"+endpoint+"/debug/"+debugBundlezPath, output)
+		},
+	}
+	cmd.Flags().StringVar(&endpoint, "endpoint", defaultDebugBundleEndpoint, "Endpoint of the zpages extension of the running collector.")
+	cmd.Flags().StringVar(&output, "output", defaultDebugBundleOutput, "Path of the archive to write.")
+	return cmd
+}
+
+func downloadDebugBundle(cmd *cobra.Command, url, output string) error {
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot retrieve the debug bundle: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot retrieve the debug bundle from %q: %s", url, resp.Status)
+	}
+
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		return multierr.Combine(err, f.Close())
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	cmd.Printf("Debug bundle written to %s\n", output)
+	return nil
+}
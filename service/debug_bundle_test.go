@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestDebugBundlezRequest(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	srv := createExampleService(t, factories)
+	srv.host.configHash = "abc"
+	srv.host.effectiveConfig = redactConf(map[string]interface{}{"exporters": map[string]interface{}{"otlp": map[string]interface{}{"token": "secret"}}}, nil)
+
+	rr := httptest.NewRecorder()
+	srv.host.handleDebugBundlezRequest(rr, httptest.NewRequest(http.MethodGet, "/debug/debugbundlez", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/gzip", rr.Header().Get("Content-Type"))
+
+	files := readDebugBundle(t, rr.Body)
+	assert.ElementsMatch(t, []string{"build_info.json", "config.json", "feature_gates.json", "components.json",
+		"metrics.json", "goroutines.txt", "heap.pprof"}, mapKeys(files))
+	assert.NotContains(t, string(files["config.json"]), "secret")
+	assert.Contains(t, string(files["config.json"]), `"config_hash": "abc"`)
+
+	var components componentsData
+	require.NoError(t, json.Unmarshal(files["components.json"], &components))
+	assert.Equal(t, []string{"nop"}, components.Extensions)
+	assert.Equal(t, []string{"nop"}, components.Exporters["traces"])
+}
+
+func TestDebugBundleCommand(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	srv := createExampleService(t, factories)
+
+	mux := http.NewServeMux()
+	srv.host.RegisterZPages(mux, "/debug")
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	cmd := NewCommand(CollectorSettings{})
+	cmd.SetOut(io.Discard)
+	cmd.SetArgs([]string{"debug-bundle", "--endpoint", server.Listener.Addr().String(), "--output", output})
+	require.NoError(t, cmd.Execute())
+
+	f, err := os.Open(output)
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Contains(t, readDebugBundle(t, f), "build_info.json")
+}
+
+func TestDebugBundleCommandNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	cmd := NewCommand(CollectorSettings{})
+	cmd.SetArgs([]string{"debug-bundle", "--endpoint", server.Listener.Addr().String(), "--output", filepath.Join(t.TempDir(), "bundle.tar.gz")})
+	assert.ErrorContains(t, cmd.Execute(), "404 Not Found")
+}
+
+func readDebugBundle(t *testing.T, r io.Reader) map[string][]byte {
+	gr, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	files := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		files[hdr.Name], err = io.ReadAll(tr)
+		require.NoError(t, err)
+	}
+	return files
+}
+
+func mapKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,433 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/service/extensions"
+	"go.opentelemetry.io/collector/service/featuregate"
+)
+
+const defaultDoctorTimeout = 5 * time.Second
+
+// doctorStatus is the outcome of a doctor check.
+type doctorStatus int
+
+const (
+	doctorPass doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+func (s doctorStatus) String() string {
+	switch s {
+	case doctorPass:
+		return "PASS"
+	case doctorWarn:
+		return "WARN"
+	}
+	return "FAIL"
+}
+
+// doctorCheck is the result of a check of a target, e.g. the endpoint of an exporter.
+type doctorCheck struct {
+	status  doctorStatus
+	name    string
+	target  string
+	message string
+}
+
+// doctor runs the preflight checks of a configuration, recording their results.
+type doctor struct {
+	factories component.Factories
+	buildInfo component.BuildInfo
+	timeout   time.Duration
+	checks    []doctorCheck
+}
+
+// newDoctorCommand constructs the command that checks that the collector can run with a configuration
+// in the current environment: config sources access, receivers ports, exporters endpoints, TLS and auth.
+func newDoctorCommand(set CollectorSettings) *cobra.Command {
+	flagSet := flags()
+	timeout := defaultDoctorTimeout
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Checks that the collector can run with the configuration in the current environment",
+		Long: "Checks the access to the config sources, the validity of the configuration, that the ports of the " +
+			"receivers can be bound, and that the endpoints of the exporters are reachable, including their TLS " +
+			"handshake and authentication, then prints a pass/warn/fail report. Fails if any check fails.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var cfgSet *ConfigProviderSettings
+			cfgProvider := set.ConfigProvider
+			if cfgProvider == nil {
+				s, err := newConfigProviderSettingsFromFlags(set, flagSet)
+				if err != nil {
+					return err
+				}
+				// The actual config sources are checked, never the last known good configuration.
+				s.LastKnownGood = LastKnownGoodSettings{}
+				if cfgProvider, err = NewConfigProvider(s); err != nil {
+					return err
+				}
+				cfgSet = &s
+			}
+			d := &doctor{factories: set.Factories, buildInfo: set.BuildInfo, timeout: timeout}
+			d.run(cmd.Context(), cfgSet, cfgProvider)
+			return d.writeReport(cmd.OutOrStdout())
+		},
+	}
+	cmd.Flags().AddGoFlagSet(flagSet)
+	cmd.Flags().DurationVar(&timeout, "timeout", defaultDoctorTimeout, "Timeout of every network check.")
+	return cmd
+}
+
+func (d *doctor) add(status doctorStatus, name, target, format string, args ...interface{}) {
+	d.checks = append(d.checks, doctorCheck{status: status, name: name, target: target, message: fmt.Sprintf(format, args...)})
+}
+
+// run runs all the checks. The config sources are checked only if cfgSet is not nil.
+func (d *doctor) run(ctx context.Context, cfgSet *ConfigProviderSettings, cfgProvider ConfigProvider) {
+	if cfgSet != nil && !d.checkConfigSources(ctx, cfgSet.ResolverSettings) {
+		return
+	}
+	defer func() {
+		_ = cfgProvider.Shutdown(ctx)
+	}()
+	cfg, err := cfgProvider.Get(ctx, d.factories)
+	if err != nil {
+		d.add(doctorFail, "config", "", "%v", err)
+		return
+	}
+	d.add(doctorPass, "config", "", "valid configuration")
+
+	recvIDs := make([]config.ComponentID, 0, len(cfg.Receivers))
+	for id := range cfg.Receivers {
+		recvIDs = append(recvIDs, id)
+	}
+	for _, id := range sortComponentIDs(recvIDs) {
+		d.checkReceiver(id, cfg.Receivers[id])
+	}
+	expIDs := make([]config.ComponentID, 0, len(cfg.Exporters))
+	for id := range cfg.Exporters {
+		expIDs = append(expIDs, id)
+	}
+	for _, id := range sortComponentIDs(expIDs) {
+		d.checkExporter(ctx, cfg, id, cfg.Exporters[id])
+	}
+}
+
+// checkConfigSources retrieves every config source individually, returning false if any cannot be retrieved.
+func (d *doctor) checkConfigSources(ctx context.Context, set confmap.ResolverSettings) bool {
+	ok := true
+	for _, uri := range set.URIs {
+		resolver, err := confmap.NewResolver(confmap.ResolverSettings{
+			URIs:           []string{uri},
+			Providers:      set.Providers,
+			ResolveTimeout: d.timeout,
+			EnableExpand:   featuregate.GetRegistry().IsEnabled(expandEnabledGateID),
+		})
+		if err == nil {
+			_, err = resolver.Resolve(ctx)
+			err = multierr.Append(err, resolver.Shutdown(ctx))
+		}
+		if err != nil {
+			d.add(doctorFail, "config_source", uri, "%v", err)
+			ok = false
+			continue
+		}
+		d.add(doctorPass, "config_source", uri, "retrieved")
+	}
+	return ok
+}
+
+// checkReceiver checks that the server endpoints of the receiver can be bound.
+func (d *doctor) checkReceiver(id config.ComponentID, cfg config.Receiver) {
+	target := "receivers::" + id.String()
+	walkConfig(reflect.ValueOf(cfg), func(v interface{}) {
+		var (
+			endpoint string
+			ln       net.Listener
+			err      error
+		)
+		switch s := v.(type) {
+		case *configgrpc.GRPCServerSettings:
+			endpoint = s.NetAddr.Endpoint
+			ln, err = s.ToListener()
+		case *confighttp.HTTPServerSettings:
+			endpoint = s.Endpoint
+			ln, err = s.ToListener()
+		default:
+			return
+		}
+		if err != nil {
+			d.add(doctorFail, "receiver_port", target, "cannot listen on %q: %v", endpoint, err)
+			return
+		}
+		_ = ln.Close()
+		d.add(doctorPass, "receiver_port", target, "can listen on %q", endpoint)
+	})
+}
+
+// checkExporter checks that the client endpoints of the exporter are reachable, with TLS and authentication.
+func (d *doctor) checkExporter(ctx context.Context, cfg *Config, id config.ComponentID, expCfg config.Exporter) {
+	target := "exporters::" + id.String()
+	walkConfig(reflect.ValueOf(expCfg), func(v interface{}) {
+		switch s := v.(type) {
+		case *configgrpc.GRPCClientSettings:
+			addr := strings.TrimPrefix(s.SanitizedEndpoint(), "dns:///")
+			var tlsCfg *tls.Config
+			if !strings.HasPrefix(s.Endpoint, "http://") {
+				var err error
+				if tlsCfg, err = s.TLSSetting.LoadTLSConfig(); err != nil {
+					d.add(doctorFail, "exporter_tls", target, "%v", err)
+					return
+				}
+			}
+			if !d.checkEndpoint(ctx, target, s.Endpoint, addr, tlsCfg) || s.Auth == nil {
+				return
+			}
+			d.checkAuth(ctx, cfg, target, s.Auth, func(auth configauth.ClientAuthenticator) error {
+				creds, err := auth.PerRPCCredentials()
+				if err != nil {
+					return err
+				}
+				_, err = creds.GetRequestMetadata(ctx, "https://"+addr)
+				return err
+			})
+		case *confighttp.HTTPClientSettings:
+			u, err := url.Parse(s.Endpoint)
+			if err != nil {
+				d.add(doctorFail, "exporter_endpoint", target, "invalid endpoint %q: %v", s.Endpoint, err)
+				return
+			}
+			addr := u.Host
+			if u.Port() == "" {
+				addr = net.JoinHostPort(u.Hostname(), map[string]string{"http": "80", "https": "443"}[u.Scheme])
+			}
+			var tlsCfg *tls.Config
+			if u.Scheme == "https" {
+				if tlsCfg, err = s.TLSSetting.LoadTLSConfig(); err != nil {
+					d.add(doctorFail, "exporter_tls", target, "%v", err)
+					return
+				}
+				if tlsCfg == nil {
+					tlsCfg = &tls.Config{MinVersion: tls.VersionTLS12}
+				}
+			}
+			if !d.checkEndpoint(ctx, target, s.Endpoint, addr, tlsCfg) || s.Auth == nil {
+				return
+			}
+			d.checkAuth(ctx, cfg, target, s.Auth, func(auth configauth.ClientAuthenticator) error {
+				return d.checkHTTPAuth(ctx, auth, s.Endpoint, tlsCfg)
+			})
+		}
+	})
+}
+
+// checkEndpoint checks that a TCP connection can be established with addr, followed by a TLS handshake
+// if tlsCfg is not nil, returning true if the checks passed.
+func (d *doctor) checkEndpoint(ctx context.Context, target, endpoint, addr string, tlsCfg *tls.Config) bool {
+	if endpoint == "" {
+		d.add(doctorWarn, "exporter_endpoint", target, "no endpoint configured")
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		d.add(doctorFail, "exporter_endpoint", target, "%q is not reachable: %v", endpoint, err)
+		return false
+	}
+	d.add(doctorPass, "exporter_endpoint", target, "%q is reachable", endpoint)
+
+	if tlsCfg == nil {
+		_ = conn.Close()
+		host, _, _ := net.SplitHostPort(addr)
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			d.add(doctorWarn, "exporter_tls", target, "TLS is disabled for %q", endpoint)
+		}
+		return true
+	}
+	tlsCfg = tlsCfg.Clone()
+	if tlsCfg.ServerName == "" {
+		tlsCfg.ServerName, _, _ = net.SplitHostPort(addr)
+	}
+	tlsConn := tls.Client(conn, tlsCfg)
+	defer tlsConn.Close()
+	if err = tlsConn.HandshakeContext(ctx); err != nil {
+		d.add(doctorFail, "exporter_tls", target, "TLS handshake with %q failed: %v", endpoint, err)
+		return false
+	}
+	d.add(doctorPass, "exporter_tls", target, "TLS handshake with %q succeeded", endpoint)
+	return true
+}
+
+// checkAuth starts the authenticator extension referenced by the exporter, and checks the credentials with verify.
+func (d *doctor) checkAuth(ctx context.Context, cfg *Config, target string, auth *configauth.Authentication, verify func(configauth.ClientAuthenticator) error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	exts, err := extensions.New(ctx, extensions.Settings{
+		Telemetry: component.TelemetrySettings{
+			Logger:         zap.NewNop(),
+			TracerProvider: trace.NewNoopTracerProvider(),
+			MeterProvider:  metric.NewNoopMeterProvider(),
+			MetricsLevel:   configtelemetry.LevelNone,
+		},
+		BuildInfo: d.buildInfo,
+		Configs:   cfg.Extensions,
+		Factories: d.factories.Extensions,
+	}, extensions.Config{auth.AuthenticatorID})
+	if err != nil {
+		d.add(doctorFail, "exporter_auth", target, "%v", err)
+		return
+	}
+	defer func() {
+		_ = exts.Shutdown(ctx)
+	}()
+	if err = exts.Start(ctx, &doctorHost{factories: d.factories, extensions: exts}); err != nil {
+		d.add(doctorFail, "exporter_auth", target, "cannot start authenticator %q: %v", auth.AuthenticatorID, err)
+		return
+	}
+	authenticator, err := auth.GetClientAuthenticator(exts.GetExtensions())
+	if err != nil {
+		d.add(doctorFail, "exporter_auth", target, "%v", err)
+		return
+	}
+	if err = verify(authenticator); err != nil {
+		d.add(doctorFail, "exporter_auth", target, "authenticator %q: %v", auth.AuthenticatorID, err)
+		return
+	}
+	d.add(doctorPass, "exporter_auth", target, "authenticator %q obtained credentials", auth.AuthenticatorID)
+}
+
+// errAuthRejected is returned by checkHTTPAuth when the endpoint rejects the credentials.
+var errAuthRejected = errors.New("the credentials are rejected by the endpoint")
+
+// checkHTTPAuth sends an authenticated request to the endpoint, failing if the credentials cannot be
+// obtained or are rejected. Any other response is accepted, since the endpoint may not support the request.
+func (d *doctor) checkHTTPAuth(ctx context.Context, auth configauth.ClientAuthenticator, endpoint string, tlsCfg *tls.Config) error {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	rt, err := auth.RoundTripper(transport)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %s", errAuthRejected, resp.Status)
+	}
+	return nil
+}
+
+// writeReport writes the results of the checks, returning an error if any check failed.
+func (d *doctor) writeReport(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	counts := map[doctorStatus]int{}
+	for _, c := range d.checks {
+		counts[c.status]++
+		target := c.target
+		if target == "" {
+			target = "-"
+		}
+		fmt.Fprintf(tw, "%v\t%s\t%s\t%s\n", c.status, c.name, target, c.message)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n%d passed, %d warnings, %d failed\n", counts[doctorPass], counts[doctorWarn], counts[doctorFail])
+	if counts[doctorFail] > 0 {
+		return fmt.Errorf("%d check(s) failed", counts[doctorFail])
+	}
+	return nil
+}
+
+// walkConfig calls visit with a pointer to every struct nested in the configuration, itself included.
+func walkConfig(v reflect.Value, visit func(interface{})) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			walkConfig(v.Elem(), visit)
+		}
+	case reflect.Struct:
+		if v.CanAddr() && v.Addr().CanInterface() {
+			visit(v.Addr().Interface())
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				walkConfig(v.Field(i), visit)
+			}
+		}
+	}
+}
+
+func sortComponentIDs(ids []config.ComponentID) []config.ComponentID {
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
+// doctorHost is the component.Host of the extensions started by the doctor.
+type doctorHost struct {
+	factories  component.Factories
+	extensions *extensions.Extensions
+}
+
+func (h *doctorHost) ReportFatalError(error) {}
+
+func (h *doctorHost) GetFactory(kind component.Kind, componentType config.Type) component.Factory {
+	return (&serviceHost{factories: h.factories}).GetFactory(kind, componentType)
+}
+
+func (h *doctorHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions.GetExtensions()
+}
+
+func (h *doctorHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return nil
+}
@@ -0,0 +1,235 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/confignet"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+type doctorTestReceiverConfig struct {
+	config.ReceiverSettings `mapstructure:",squash"`
+	GRPC                    *configgrpc.GRPCServerSettings `mapstructure:"grpc"`
+	HTTP                    *confighttp.HTTPServerSettings `mapstructure:"http"`
+}
+
+type doctorTestExporterConfig struct {
+	config.ExporterSettings       `mapstructure:",squash"`
+	configgrpc.GRPCClientSettings `mapstructure:",squash"`
+}
+
+type doctorTestCredentials struct {
+	err error
+}
+
+func (c doctorTestCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer token"}, c.err
+}
+
+func (c doctorTestCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+func newDoctorTestAuthFactory(credsErr error) component.ExtensionFactory {
+	return component.NewExtensionFactory("mockauth",
+		func() config.Extension {
+			cfg := config.NewExtensionSettings(config.NewComponentID("mockauth"))
+			return &cfg
+		},
+		func(context.Context, component.ExtensionCreateSettings, config.Extension) (component.Extension, error) {
+			return &configauth.MockClientAuthenticator{
+				ResultRoundTripper:      http.DefaultTransport,
+				ResultPerRPCCredentials: doctorTestCredentials{err: credsErr},
+			}, nil
+		})
+}
+
+func checkStatuses(checks []doctorCheck) []string {
+	var ret []string
+	for _, c := range checks {
+		ret = append(ret, c.status.String()+" "+c.name)
+	}
+	return ret
+}
+
+func TestDoctorCheckReceiver(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	d := &doctor{timeout: time.Second}
+	d.checkReceiver(config.NewComponentID("test"), &doctorTestReceiverConfig{
+		GRPC: &configgrpc.GRPCServerSettings{NetAddr: confignet.NetAddr{Endpoint: ln.Addr().String(), Transport: "tcp"}},
+		HTTP: &confighttp.HTTPServerSettings{Endpoint: "127.0.0.1:0"},
+	})
+	require.Len(t, d.checks, 2)
+	assert.Equal(t, []string{"FAIL receiver_port", "PASS receiver_port"}, checkStatuses(d.checks))
+	assert.Equal(t, "receivers::test", d.checks[0].target)
+}
+
+func TestDoctorCheckExporter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	closedLn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := closedLn.Addr().String()
+	require.NoError(t, closedLn.Close())
+
+	authID := config.NewComponentID("mockauth")
+	authExtCfg := config.NewExtensionSettings(authID)
+
+	testCases := []struct {
+		name     string
+		settings configgrpc.GRPCClientSettings
+		credsErr error
+		expected []string
+	}{
+		{
+			name:     "no_endpoint",
+			expected: []string{"WARN exporter_endpoint"},
+		},
+		{
+			name:     "unreachable",
+			settings: configgrpc.GRPCClientSettings{Endpoint: closedAddr, TLSSetting: configtls.TLSClientSetting{Insecure: true}},
+			expected: []string{"FAIL exporter_endpoint"},
+		},
+		{
+			name:     "reachable",
+			settings: configgrpc.GRPCClientSettings{Endpoint: ln.Addr().String(), TLSSetting: configtls.TLSClientSetting{Insecure: true}},
+			expected: []string{"PASS exporter_endpoint"},
+		},
+		{
+			name: "auth",
+			settings: configgrpc.GRPCClientSettings{Endpoint: "http://" + ln.Addr().String(),
+				Auth: &configauth.Authentication{AuthenticatorID: authID}},
+			expected: []string{"PASS exporter_endpoint", "PASS exporter_auth"},
+		},
+		{
+			name: "auth_credentials_error",
+			settings: configgrpc.GRPCClientSettings{Endpoint: "http://" + ln.Addr().String(),
+				Auth: &configauth.Authentication{AuthenticatorID: authID}},
+			credsErr: errors.New("invalid token"),
+			expected: []string{"PASS exporter_endpoint", "FAIL exporter_auth"},
+		},
+		{
+			name: "auth_not_configured",
+			settings: configgrpc.GRPCClientSettings{Endpoint: "http://" + ln.Addr().String(),
+				Auth: &configauth.Authentication{AuthenticatorID: config.NewComponentID("missing")}},
+			expected: []string{"PASS exporter_endpoint", "FAIL exporter_auth"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &doctor{
+				factories: component.Factories{Extensions: map[config.Type]component.ExtensionFactory{
+					"mockauth": newDoctorTestAuthFactory(tt.credsErr),
+				}},
+				timeout: time.Second,
+			}
+			cfg := &Config{Extensions: map[config.ComponentID]config.Extension{authID: &authExtCfg}}
+			d.checkExporter(context.Background(), cfg, config.NewComponentID("test"), &doctorTestExporterConfig{GRPCClientSettings: tt.settings})
+			assert.Equal(t, tt.expected, checkStatuses(d.checks), d.checks)
+		})
+	}
+}
+
+func TestDoctorCheckEndpointTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+	addr := srv.Listener.Addr().String()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	d := &doctor{timeout: time.Second}
+	assert.True(t, d.checkEndpoint(context.Background(), "exporters::test", srv.URL, addr, &tls.Config{RootCAs: roots, ServerName: "example.com", MinVersion: tls.VersionTLS12}))
+	assert.False(t, d.checkEndpoint(context.Background(), "exporters::test", srv.URL, addr, &tls.Config{RootCAs: x509.NewCertPool(), MinVersion: tls.VersionTLS12}))
+	assert.Equal(t, []string{"PASS exporter_endpoint", "PASS exporter_tls", "PASS exporter_endpoint", "FAIL exporter_tls"}, checkStatuses(d.checks))
+}
+
+func TestDoctorCheckHTTPAuth(t *testing.T) {
+	status := http.StatusUnauthorized
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	d := &doctor{timeout: time.Second}
+	auth := &configauth.MockClientAuthenticator{ResultRoundTripper: http.DefaultTransport}
+	assert.ErrorIs(t, d.checkHTTPAuth(context.Background(), auth, srv.URL, nil), errAuthRejected)
+	status = http.StatusMethodNotAllowed
+	assert.NoError(t, d.checkHTTPAuth(context.Background(), auth, srv.URL, nil))
+	assert.Error(t, d.checkHTTPAuth(context.Background(), &configauth.MockClientAuthenticator{MustError: true}, srv.URL, nil))
+}
+
+func TestDoctorWriteReport(t *testing.T) {
+	d := &doctor{}
+	d.add(doctorPass, "config", "", "valid configuration")
+	d.add(doctorWarn, "exporter_tls", "exporters::otlp", "TLS is disabled for %q", "collector:4317")
+	buf := &bytes.Buffer{}
+	require.NoError(t, d.writeReport(buf))
+	assert.Equal(t, `PASS  config        -                valid configuration
+WARN  exporter_tls  exporters::otlp  TLS is disabled for "collector:4317"
+
+1 passed, 1 warnings, 0 failed
+`, buf.String())
+
+	d.add(doctorFail, "exporter_endpoint", "exporters::otlp", "not reachable")
+	assert.EqualError(t, d.writeReport(&bytes.Buffer{}), "1 check(s) failed")
+}
+
+func TestDoctorCommand(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"doctor", "--config", filepath.Join("testdata", "otelcol-nop.yaml")})
+	require.NoError(t, cmd.Execute())
+	assert.True(t, strings.HasPrefix(out.String(), "PASS  config_source"), out.String())
+	assert.Contains(t, out.String(), "2 passed, 0 warnings, 0 failed")
+
+	cmd = NewCommand(CollectorSettings{Factories: factories})
+	out = &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"doctor", "--config", filepath.Join("testdata", "otelcol-invalid.yaml")})
+	assert.EqualError(t, cmd.Execute(), "1 check(s) failed")
+	assert.Contains(t, out.String(), "FAIL  config")
+}
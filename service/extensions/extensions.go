@@ -35,6 +35,7 @@ const zExtensionName = "zextensionname"
 type Extensions struct {
 	telemetry component.TelemetrySettings
 	extMap    map[config.ComponentID]component.Extension
+	timeouts  config.ServiceTimeouts
 }
 
 // Start starts all extensions.
@@ -43,7 +44,8 @@ func (bes *Extensions) Start(ctx context.Context, host component.Host) error {
 	for extID, ext := range bes.extMap {
 		extLogger := extensionLogger(bes.telemetry.Logger, extID)
 		extLogger.Info("Extension is starting...")
-		if err := ext.Start(ctx, components.NewHostWrapper(host, extLogger)); err != nil {
+		if err := components.StartWithTimeout(ctx, ext, components.NewHostWrapper(host, extLogger),
+			components.ZapKindExtension, extID, bes.timeouts.Extension(extID).Start); err != nil {
 			return err
 		}
 		extLogger.Info("Extension started.")
@@ -55,8 +57,9 @@ func (bes *Extensions) Start(ctx context.Context, host component.Host) error {
 func (bes *Extensions) Shutdown(ctx context.Context) error {
 	bes.telemetry.Logger.Info("Stopping extensions...")
 	var errs error
-	for _, ext := range bes.extMap {
-		errs = multierr.Append(errs, ext.Shutdown(ctx))
+	for extID, ext := range bes.extMap {
+		errs = multierr.Append(errs, components.ShutdownWithTimeout(ctx, ext,
+			components.ZapKindExtension, extID, bes.timeouts.Extension(extID).Shutdown))
 	}
 
 	return errs
@@ -128,6 +131,9 @@ type Settings struct {
 
 	// Factories maps extension type names in the config to the respective component.ExtensionFactory.
 	Factories map[config.Type]component.ExtensionFactory
+
+	// Timeouts are the timeouts of starting and shutting down the extensions.
+	Timeouts config.ServiceTimeouts
 }
 
 // New creates a new Extensions from Config.
@@ -135,6 +141,7 @@ func New(ctx context.Context, set Settings, cfg Config) (*Extensions, error) {
 	exts := &Extensions{
 		telemetry: set.Telemetry,
 		extMap:    make(map[config.ComponentID]component.Extension),
+		timeouts:  set.Timeouts,
 	}
 	for _, extID := range cfg {
 		extCfg, existsCfg := set.Configs[extID]
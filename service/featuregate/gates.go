@@ -15,7 +15,10 @@
 package featuregate // import "go.opentelemetry.io/collector/service/featuregate"
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -46,18 +49,110 @@ type Registry struct {
 
 // Apply a configuration in the form of a map of Gate identifiers to boolean values.
 // Sets only those values provided in the map, other gate values are not changed.
+// If any identifier is not registered, no value is set and the returned error lists all the
+// unregistered identifiers, suggesting the registered ones they are likely a typo of.
 func (r *Registry) Apply(cfg map[string]bool) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if err := r.checkRegistered(cfg); err != nil {
+		return err
+	}
+	r.apply(cfg)
+	return nil
+}
+
+// ApplyLenient is like Apply, but ignores the unregistered identifiers instead of failing: the values
+// of the registered ones are set, and the returned error lists the ignored identifiers, so that the
+// caller can report them as a warning.
+func (r *Registry) ApplyLenient(cfg map[string]bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.apply(cfg)
+	return r.checkRegistered(cfg)
+}
+
+// apply sets the values of the registered gates of the configuration. Must be called with the lock held.
+func (r *Registry) apply(cfg map[string]bool) {
 	for id, val := range cfg {
-		g, ok := r.gates[id]
-		if !ok {
-			return fmt.Errorf("feature gate %s is unregistered", id)
+		if g, ok := r.gates[id]; ok {
+			g.Enabled = val
+			r.gates[g.ID] = g
 		}
-		g.Enabled = val
-		r.gates[g.ID] = g
 	}
-	return nil
+}
+
+// checkRegistered returns an error listing the unregistered identifiers of the configuration.
+// Must be called with the lock held.
+func (r *Registry) checkRegistered(cfg map[string]bool) error {
+	var unknown []string
+	for id := range cfg {
+		if _, ok := r.gates[id]; !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	msgs := make([]string, 0, len(unknown))
+	for _, id := range unknown {
+		msg := fmt.Sprintf("feature gate %s is unregistered", id)
+		if suggestion := r.suggest(id); suggestion != "" {
+			msg += fmt.Sprintf(", did you mean %s?", suggestion)
+		}
+		msgs = append(msgs, msg)
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// suggest returns the registered identifier closest to the given one, if close enough to be a typo,
+// or an empty string otherwise. Must be called with the lock held.
+func (r *Registry) suggest(id string) string {
+	// Allow roughly one edit every four characters, and at least two edits.
+	maxDistance := len(id) / 4
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	best, bestDistance := "", maxDistance+1
+	for candidate := range r.gates {
+		d := editDistance(strings.ToLower(id), strings.ToLower(candidate))
+		if d < bestDistance || (d == bestDistance && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
 }
 
 // Deprecated: [v0.58.0] Use Apply instead.
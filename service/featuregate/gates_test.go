@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRegistry(t *testing.T) {
@@ -85,3 +86,49 @@ func TestRegistryWithErrorApply(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistryApplyUnregisteredGates(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(Gate{ID: "exporter.otlp.retryOnTimeout"}))
+	require.NoError(t, r.Register(Gate{ID: "confmap.expandEnabled"}))
+
+	cfg := map[string]bool{
+		"confmap.expandEnabled":       true,
+		"exporter.otlp.retryOnTimout": true,
+		"unknown":                     true,
+	}
+	err := r.Apply(cfg)
+	assert.EqualError(t, err, "feature gate exporter.otlp.retryOnTimout is unregistered, did you mean exporter.otlp.retryOnTimeout?; "+
+		"feature gate unknown is unregistered")
+	// No gate is set if any is unregistered.
+	assert.False(t, r.IsEnabled("confmap.expandEnabled"))
+
+	err = r.ApplyLenient(cfg)
+	assert.EqualError(t, err, "feature gate exporter.otlp.retryOnTimout is unregistered, did you mean exporter.otlp.retryOnTimeout?; "+
+		"feature gate unknown is unregistered")
+	assert.True(t, r.IsEnabled("confmap.expandEnabled"))
+	assert.False(t, r.IsEnabled("exporter.otlp.retryOnTimeout"))
+
+	assert.NoError(t, r.ApplyLenient(map[string]bool{"confmap.expandEnabled": false}))
+	assert.False(t, r.IsEnabled("confmap.expandEnabled"))
+}
+
+func TestRegistrySuggest(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(Gate{ID: "confmap.expandEnabled"}))
+	require.NoError(t, r.Register(Gate{ID: "telemetry.useOtelForInternalMetrics"}))
+
+	assert.Equal(t, "confmap.expandEnabled", r.suggest("confmap.expandenabled"))
+	assert.Equal(t, "confmap.expandEnabled", r.suggest("confmap.expndEnabled"))
+	assert.Equal(t, "telemetry.useOtelForInternalMetrics", r.suggest("telemetry.useOtelForInternalMetric"))
+	assert.Equal(t, "", r.suggest("foo"))
+	assert.Equal(t, "", r.suggest("receiver.otlp.enabled"))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("gate", "gate"))
+	assert.Equal(t, 1, editDistance("gate", "gates"))
+	assert.Equal(t, 1, editDistance("gate", "gare"))
+	assert.Equal(t, 2, editDistance("gate", "agte"))
+	assert.Equal(t, 4, editDistance("", "gate"))
+}
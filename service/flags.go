@@ -15,20 +15,53 @@
 package service // import "go.opentelemetry.io/collector/service"
 
 import (
+	"encoding/base64"
 	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/service/featuregate"
 )
 
 const (
-	configFlag = "config"
-	setFlag    = "set"
-)
+	configFlag              = "config"
+	configDirFlag           = "config-dir"
+	setFlag                 = "set"
+	profileFlag             = "profile"
+	configEnvPrefixFlag     = "config-env-prefix"
+	featureGatesFlag        = "feature-gates"
+	featureGatesLenientFlag = "feature-gates-lenient"
+	lastKnownGoodFlag       = "last-known-good-config"
+	pollIntervalFlag        = "config-poll-interval"
+	configMaxSizeFlag       = "config-max-size"
+	configMaxDepthFlag      = "config-max-depth"
+	configMaxNodesFlag      = "config-max-nodes"
+	reloadMinIntervalFlag   = "config-reload-min-interval"
+	reloadFlapThresholdFlag = "config-reload-flap-threshold"
+	reloadFlapWindowFlag    = "config-reload-flap-window"
+	adminEndpointFlag       = "admin-endpoint"
+	configStatusURLFlag     = "config-status-url"
+	configStatusIDFlag      = "config-status-identity"
+	stateDumpFileFlag       = "state-dump-file"
 
-var (
-	// Command-line flag that control the configuration file.
-	gatesList = featuregate.FlagValue{}
+	// configEnvVar is the environment variable holding the path to the config file,
+	// used when no --config flag is set.
+	configEnvVar = "OTELCOL_CONFIG"
+	// configURIEnvVar is the environment variable holding a config URI,
+	// used when no --config flag is set.
+	configURIEnvVar = "OTELCOL_CONFIG_URI"
+	// lastKnownGoodKeyEnvVar is the environment variable holding the base64 encoded AES key
+	// used to encrypt the last known good configuration.
+	lastKnownGoodKeyEnvVar = "OTELCOL_LAST_KNOWN_GOOD_KEY"
+	// adminTokenEnvVar is the environment variable holding the bearer token of the admin API.
+	adminTokenEnvVar = "OTELCOL_ADMIN_TOKEN"
+	// configStatusTokenEnvVar is the environment variable holding the bearer token sent with the
+	// configuration status reports.
+	configStatusTokenEnvVar = "OTELCOL_CONFIG_STATUS_TOKEN"
 )
 
 type stringArrayValue struct {
@@ -48,25 +81,229 @@ func flags() *flag.FlagSet {
 	flagSet := new(flag.FlagSet)
 
 	flagSet.Var(new(stringArrayValue), configFlag, "Locations to the config file(s), note that only a"+
-		" single location can be set per flag entry e.g. `--config=file:/path/to/first --config=file:path/to/second`.")
+		" single location can be set per flag entry e.g. `--config=file:/path/to/first --config=file:path/to/second`."+
+		" A location can be a chain of fallbacks separated by '||', retrieved in order until one succeeds."+
+		" If not set, the locations are read from the "+configEnvVar+" (file path) and "+configURIEnvVar+" (config URI)"+
+		" environment variables.")
+
+	flagSet.Var(new(stringArrayValue), configDirFlag, "Directories containing config files. All the *.yaml and *.yml"+
+		" files in a directory are merged in lexical order, after the config locations set via --config. Note that only a"+
+		" single directory can be set per flag entry e.g. `--config-dir=/etc/otelcol/conf.d`.")
 
 	flagSet.Var(new(stringArrayValue), setFlag,
 		"Set arbitrary component config property. The component has to be defined in the config file and the flag"+
 			" has a higher precedence. Array config properties are overridden and maps are joined, note that only a single"+
 			" (first) array property can be set e.g. --set=processors.attributes.actions.key=some_key. Example --set=processors.batch.timeout=2s")
 
+	flagSet.String(profileFlag, "", "Name of the configuration profile to activate. Profiles are defined under the"+
+		" top level `profiles` key of the configuration, and the sections of the selected one are merged over the rest"+
+		" of the configuration. The properties set via --set have a higher precedence.")
+
+	flagSet.String(configEnvPrefixFlag, "", "Prefix of the environment variables merged over the configuration, e.g."+
+		" `--config-env-prefix=OTELCOL_` to set the `exporters::otlp::endpoint` key with the OTELCOL_exporters__otlp__endpoint"+
+		" variable, `__` separating the keys. The selected profile is merged first, and the properties set via --set"+
+		" have a higher precedence.")
+
+	flagSet.Duration(pollIntervalFlag, 0, "Default interval at which remote config providers supporting watching"+
+		" poll for changes. It can be overridden per config URI via the poll_interval query parameter, e.g."+
+		" `--config=<scheme>://host/config.yaml?poll_interval=30s`. If not set, every provider uses its own default.")
+
+	flagSet.Int(configMaxSizeFlag, 0, "Maximum size, in bytes, of the YAML documents retrieved by the file, env and yaml"+
+		" config providers. If not set, it defaults to 16MiB. A negative value disables the limit.")
+
+	flagSet.Int(configMaxDepthFlag, 0, "Maximum nesting depth of the YAML documents retrieved by the file, env and yaml"+
+		" config providers, aliases being expanded. If not set, it defaults to 1000. A negative value disables the limit.")
+
+	flagSet.Int(configMaxNodesFlag, 0, "Maximum number of nodes of the YAML documents retrieved by the file, env and yaml"+
+		" config providers, aliases being expanded. If not set, it defaults to 1000000. A negative value disables the limit.")
+
+	flagSet.Duration(reloadMinIntervalFlag, 0, "Minimum interval between two configuration reloads. The changes"+
+		" notified earlier are applied together once the interval has elapsed.")
+
+	flagSet.Int(reloadFlapThresholdFlag, 0, "Number of configuration reloads within --"+reloadFlapWindowFlag+" after which"+
+		" the configuration is considered flapping: the current configuration is held, and the changes are applied only"+
+		" once the window has elapsed since the last reload. If not set, the flap detection is disabled.")
+
+	flagSet.Duration(reloadFlapWindowFlag, defaultReloadFlapWindow, "Window in which the configuration reloads are"+
+		" counted for the flap detection.")
+
+	flagSet.String(lastKnownGoodFlag, "", "Path of the file where the last successfully loaded configuration is"+
+		" persisted. If the configuration cannot be loaded at startup, the Collector starts from this file and"+
+		" retries loading the configuration in the background. The file is encrypted with the base64 encoded AES key"+
+		" set in the "+lastKnownGoodKeyEnvVar+" environment variable, which is required.")
+
+	flagSet.String(adminEndpointFlag, "", "Address the admin API, used to pause and resume pipelines at runtime, listens"+
+		" on, e.g. localhost:13134. The clients must present the bearer token set in the "+adminTokenEnvVar+
+		" environment variable. If not set, the admin API is disabled.")
+
+	flagSet.String(configStatusURLFlag, "", "URL the outcome of every configuration load, applied or not, is POSTed to"+
+		" as a JSON status report, with the config hash and the error if any. The bearer token set in the "+
+		configStatusTokenEnvVar+" environment variable, if any, is sent with the reports. If not set, no report is sent.")
+
+	flagSet.String(configStatusIDFlag, "", "Identity of the collector in the configuration status reports."+
+		" If not set, the host name is used.")
+
+	flagSet.String(stateDumpFileFlag, "", "Path the runtime state of the collector (pipelines, queue depths, config hash,"+
+		" feature gates, goroutine count) is written to as JSON on SIGUSR2. If not set, the runtime state is logged.")
+
+	// Every flag set gets its own FlagValue, so that multiple commands created in the
+	// same process do not share the parsed feature gates.
 	flagSet.Var(
-		gatesList,
-		"feature-gates",
+		featuregate.FlagValue{},
+		featureGatesFlag,
 		"Comma-delimited list of feature gate identifiers. Prefix with '-' to disable the feature. '+' or no prefix will enable the feature.")
 
+	flagSet.Bool(featureGatesLenientFlag, false, "Ignore, with a warning, the unregistered feature gates set via"+
+		" --"+featureGatesFlag+" instead of failing. Intended for deployments shared by collector versions with different gates.")
+
 	return flagSet
 }
 
+// getConfigFlag returns the config locations set via the --config flag. If the flag is not set,
+// it falls back to the locations set via the OTELCOL_CONFIG and OTELCOL_CONFIG_URI environment
+// variables, in this order.
 func getConfigFlag(flagSet *flag.FlagSet) []string {
-	return flagSet.Lookup(configFlag).Value.(*stringArrayValue).values
+	if values := flagSet.Lookup(configFlag).Value.(*stringArrayValue).values; len(values) != 0 {
+		return values
+	}
+	var values []string
+	if path := os.Getenv(configEnvVar); path != "" {
+		values = append(values, path)
+	}
+	if uri := os.Getenv(configURIEnvVar); uri != "" {
+		values = append(values, uri)
+	}
+	return values
+}
+
+// getConfigURIs returns the config locations set via getConfigFlag, followed by the config files
+// found in the directories set via the --config-dir flag.
+func getConfigURIs(flagSet *flag.FlagSet) ([]string, error) {
+	uris := getConfigFlag(flagSet)
+	for _, dir := range flagSet.Lookup(configDirFlag).Value.(*stringArrayValue).values {
+		files, err := configFilesInDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			uris = append(uris, "file:"+file)
+		}
+	}
+	return uris, nil
+}
+
+// configFilesInDir returns the paths of all the YAML files in the given directory, sorted by name.
+func configFilesInDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the config directory: %w", err)
+	}
+	var files []string
+	// os.ReadDir returns the entries sorted by filename.
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if ext := filepath.Ext(entry.Name()); ext == ".yaml" || ext == ".yml" {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
 }
 
 func getSetFlag(flagSet *flag.FlagSet) []string {
 	return flagSet.Lookup(setFlag).Value.(*stringArrayValue).values
 }
+
+func getProfileFlag(flagSet *flag.FlagSet) string {
+	return flagSet.Lookup(profileFlag).Value.String()
+}
+
+func getConfigEnvPrefixFlag(flagSet *flag.FlagSet) string {
+	return flagSet.Lookup(configEnvPrefixFlag).Value.String()
+}
+
+func getStateDumpFileFlag(flagSet *flag.FlagSet) string {
+	return flagSet.Lookup(stateDumpFileFlag).Value.String()
+}
+
+func getFeatureGatesFlag(flagSet *flag.FlagSet) featuregate.FlagValue {
+	return flagSet.Lookup(featureGatesFlag).Value.(featuregate.FlagValue)
+}
+
+// applyFeatureGatesFlag applies the feature gates set via the --feature-gates flag to the global registry.
+// Unregistered gates fail, unless the --feature-gates-lenient flag is set, in which case they are passed to warn.
+func applyFeatureGatesFlag(flagSet *flag.FlagSet, warn func(error)) error {
+	if flagSet.Lookup(featureGatesLenientFlag).Value.String() != "true" {
+		return featuregate.GetRegistry().Apply(getFeatureGatesFlag(flagSet))
+	}
+	if err := featuregate.GetRegistry().ApplyLenient(getFeatureGatesFlag(flagSet)); err != nil {
+		warn(err)
+	}
+	return nil
+}
+
+// getLastKnownGoodSettings returns the LastKnownGoodSettings configured via the --last-known-good-config
+// flag and the OTELCOL_LAST_KNOWN_GOOD_KEY environment variable.
+func getLastKnownGoodSettings(flagSet *flag.FlagSet) (LastKnownGoodSettings, error) {
+	set := LastKnownGoodSettings{Path: flagSet.Lookup(lastKnownGoodFlag).Value.String()}
+	if set.Path == "" {
+		return set, nil
+	}
+	key := os.Getenv(lastKnownGoodKeyEnvVar)
+	if key == "" {
+		return LastKnownGoodSettings{}, fmt.Errorf("--%s requires the %s environment variable", lastKnownGoodFlag, lastKnownGoodKeyEnvVar)
+	}
+	var err error
+	if set.EncryptionKey, err = base64.StdEncoding.DecodeString(key); err != nil {
+		return LastKnownGoodSettings{}, fmt.Errorf("invalid %s: %w", lastKnownGoodKeyEnvVar, err)
+	}
+	return set, nil
+}
+
+func getPollIntervalFlag(flagSet *flag.FlagSet) time.Duration {
+	return flagSet.Lookup(pollIntervalFlag).Value.(flag.Getter).Get().(time.Duration)
+}
+
+// getProviderSettings returns the confmap.ProviderSettings of the default config providers, configured
+// via the --config-max-* flags.
+func getProviderSettings(flagSet *flag.FlagSet) confmap.ProviderSettings {
+	return confmap.ProviderSettings{
+		YAMLLimits: confmap.YAMLLimits{
+			MaxDocumentSize: flagSet.Lookup(configMaxSizeFlag).Value.(flag.Getter).Get().(int),
+			MaxDepth:        flagSet.Lookup(configMaxDepthFlag).Value.(flag.Getter).Get().(int),
+			MaxNodes:        flagSet.Lookup(configMaxNodesFlag).Value.(flag.Getter).Get().(int),
+		},
+	}
+}
+
+// getReloadSettings returns the ReloadSettings configured via the --config-reload-* flags.
+func getReloadSettings(flagSet *flag.FlagSet) ReloadSettings {
+	return ReloadSettings{
+		MinInterval:   flagSet.Lookup(reloadMinIntervalFlag).Value.(flag.Getter).Get().(time.Duration),
+		FlapThreshold: flagSet.Lookup(reloadFlapThresholdFlag).Value.(flag.Getter).Get().(int),
+		FlapWindow:    flagSet.Lookup(reloadFlapWindowFlag).Value.(flag.Getter).Get().(time.Duration),
+	}
+}
+
+// getAdminSettings returns the AdminSettings configured via the --admin-endpoint flag and
+// the OTELCOL_ADMIN_TOKEN environment variable.
+func getAdminSettings(flagSet *flag.FlagSet) AdminSettings {
+	return AdminSettings{
+		Endpoint: flagSet.Lookup(adminEndpointFlag).Value.String(),
+		Token:    os.Getenv(adminTokenEnvVar),
+	}
+}
+
+// getConfigStatusSettings returns the ConfigStatusSettings configured via the --config-status-* flags
+// and the OTELCOL_CONFIG_STATUS_TOKEN environment variable.
+func getConfigStatusSettings(flagSet *flag.FlagSet) ConfigStatusSettings {
+	set := ConfigStatusSettings{
+		URL:      flagSet.Lookup(configStatusURLFlag).Value.String(),
+		Identity: flagSet.Lookup(configStatusIDFlag).Value.String(),
+	}
+	if set.URL != "" {
+		set.Token = os.Getenv(configStatusTokenEnvVar)
+	}
+	return set
+}
@@ -0,0 +1,235 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestGetConfigFlag(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		configEnv string
+		uriEnv    string
+		expected  []string
+	}{
+		{
+			name: "no flag and no env",
+		},
+		{
+			name:     "flags",
+			args:     []string{"--config=file:a.yaml", "--config=env:B"},
+			expected: []string{"file:a.yaml", "env:B"},
+		},
+		{
+			name:      "flags take precedence over env",
+			args:      []string{"--config=file:a.yaml"},
+			configEnv: "b.yaml",
+			uriEnv:    "env:C",
+			expected:  []string{"file:a.yaml"},
+		},
+		{
+			name:      "config env",
+			configEnv: "/etc/otelcol/config.yaml",
+			expected:  []string{"/etc/otelcol/config.yaml"},
+		},
+		{
+			name:     "config uri env",
+			uriEnv:   "yaml:exporters::logging::loglevel: debug",
+			expected: []string{"yaml:exporters::logging::loglevel: debug"},
+		},
+		{
+			name:      "both env",
+			configEnv: "/etc/otelcol/config.yaml",
+			uriEnv:    "env:OVERRIDES",
+			expected:  []string{"/etc/otelcol/config.yaml", "env:OVERRIDES"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(configEnvVar, tt.configEnv)
+			t.Setenv(configURIEnvVar, tt.uriEnv)
+			flagSet := flags()
+			require.NoError(t, flagSet.Parse(tt.args))
+			assert.Equal(t, tt.expected, getConfigFlag(flagSet))
+		})
+	}
+}
+
+func TestGetConfigURIsWithConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20-exporters.yml", "10-receivers.yaml", "README.md", "30-other.json"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte{}, 0600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "00-subdir.yaml"), 0700))
+
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{"--config-dir=" + dir, "--config=file:main.yaml"}))
+	uris, err := getConfigURIs(flagSet)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"file:main.yaml",
+		"file:" + filepath.Join(dir, "10-receivers.yaml"),
+		"file:" + filepath.Join(dir, "20-exporters.yml"),
+	}, uris)
+}
+
+func TestGetConfigURIsInvalidConfigDir(t *testing.T) {
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{"--config-dir=" + filepath.Join(t.TempDir(), "missing")}))
+	_, err := getConfigURIs(flagSet)
+	assert.Error(t, err)
+}
+
+func TestGetLastKnownGoodSettings(t *testing.T) {
+	t.Setenv(lastKnownGoodKeyEnvVar, "MDEyMzQ1Njc4OWFiY2RlZg==")
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{"--last-known-good-config=/var/lib/otelcol/lkg.json"}))
+	set, err := getLastKnownGoodSettings(flagSet)
+	require.NoError(t, err)
+	assert.Equal(t, LastKnownGoodSettings{Path: "/var/lib/otelcol/lkg.json", EncryptionKey: []byte("0123456789abcdef")}, set)
+
+	t.Setenv(lastKnownGoodKeyEnvVar, "not base64")
+	_, err = getLastKnownGoodSettings(flagSet)
+	assert.Error(t, err)
+
+	// The encryption key is required.
+	t.Setenv(lastKnownGoodKeyEnvVar, "")
+	_, err = getLastKnownGoodSettings(flagSet)
+	assert.EqualError(t, err, "--last-known-good-config requires the "+lastKnownGoodKeyEnvVar+" environment variable")
+
+	// Disabled without the flag.
+	set, err = getLastKnownGoodSettings(flags())
+	require.NoError(t, err)
+	assert.Equal(t, LastKnownGoodSettings{}, set)
+}
+
+func TestGetPollIntervalFlag(t *testing.T) {
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{}))
+	assert.Equal(t, time.Duration(0), getPollIntervalFlag(flagSet))
+
+	flagSet = flags()
+	require.NoError(t, flagSet.Parse([]string{"--config-poll-interval=30s"}))
+	assert.Equal(t, 30*time.Second, getPollIntervalFlag(flagSet))
+
+	assert.Error(t, flags().Parse([]string{"--config-poll-interval=often"}))
+}
+
+func TestGetProviderSettings(t *testing.T) {
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{}))
+	assert.Equal(t, confmap.ProviderSettings{}, getProviderSettings(flagSet))
+
+	flagSet = flags()
+	require.NoError(t, flagSet.Parse([]string{
+		"--config-max-size=1024",
+		"--config-max-depth=10",
+		"--config-max-nodes=-1",
+	}))
+	assert.Equal(t, confmap.ProviderSettings{YAMLLimits: confmap.YAMLLimits{MaxDocumentSize: 1024, MaxDepth: 10, MaxNodes: -1}},
+		getProviderSettings(flagSet))
+}
+
+func TestGetReloadSettings(t *testing.T) {
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{}))
+	assert.Equal(t, ReloadSettings{FlapWindow: defaultReloadFlapWindow}, getReloadSettings(flagSet))
+
+	flagSet = flags()
+	require.NoError(t, flagSet.Parse([]string{
+		"--config-reload-min-interval=10s",
+		"--config-reload-flap-threshold=5",
+		"--config-reload-flap-window=1m",
+	}))
+	assert.Equal(t, ReloadSettings{MinInterval: 10 * time.Second, FlapThreshold: 5, FlapWindow: time.Minute}, getReloadSettings(flagSet))
+
+	assert.Error(t, flags().Parse([]string{"--config-reload-flap-threshold=many"}))
+}
+
+func TestGetAdminSettings(t *testing.T) {
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{}))
+	assert.Equal(t, AdminSettings{}, getAdminSettings(flagSet))
+
+	t.Setenv(adminTokenEnvVar, "secret")
+	flagSet = flags()
+	require.NoError(t, flagSet.Parse([]string{"--admin-endpoint=localhost:13134"}))
+	assert.Equal(t, AdminSettings{Endpoint: "localhost:13134", Token: "secret"}, getAdminSettings(flagSet))
+}
+
+func TestGetConfigStatusSettings(t *testing.T) {
+	t.Setenv(configStatusTokenEnvVar, "secret")
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{}))
+	assert.Equal(t, ConfigStatusSettings{}, getConfigStatusSettings(flagSet))
+
+	flagSet = flags()
+	require.NoError(t, flagSet.Parse([]string{"--config-status-url=https://fleet.example.com/status", "--config-status-identity=agent-0"}))
+	assert.Equal(t, ConfigStatusSettings{URL: "https://fleet.example.com/status", Token: "secret", Identity: "agent-0"}, getConfigStatusSettings(flagSet))
+}
+
+func TestGetStateDumpFileFlag(t *testing.T) {
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{}))
+	assert.Equal(t, "", getStateDumpFileFlag(flagSet))
+
+	flagSet = flags()
+	require.NoError(t, flagSet.Parse([]string{"--state-dump-file=/tmp/otelcol-state.json"}))
+	assert.Equal(t, "/tmp/otelcol-state.json", getStateDumpFileFlag(flagSet))
+}
+
+func TestGetProfileFlag(t *testing.T) {
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{}))
+	assert.Equal(t, "", getProfileFlag(flagSet))
+
+	flagSet = flags()
+	require.NoError(t, flagSet.Parse([]string{"--profile=gateway"}))
+	assert.Equal(t, "gateway", getProfileFlag(flagSet))
+}
+
+func TestGetConfigEnvPrefixFlag(t *testing.T) {
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{}))
+	assert.Equal(t, "", getConfigEnvPrefixFlag(flagSet))
+
+	flagSet = flags()
+	require.NoError(t, flagSet.Parse([]string{"--config-env-prefix=OTELCOL_"}))
+	assert.Equal(t, "OTELCOL_", getConfigEnvPrefixFlag(flagSet))
+}
+
+func TestApplyFeatureGatesFlag(t *testing.T) {
+	flagSet := flags()
+	require.NoError(t, flagSet.Parse([]string{"--feature-gates=-confmap.expandEnabld"}))
+	assert.EqualError(t, applyFeatureGatesFlag(flagSet, func(err error) { t.Fatalf("unexpected warning: %v", err) }),
+		"feature gate confmap.expandEnabld is unregistered, did you mean confmap.expandEnabled?")
+
+	flagSet = flags()
+	require.NoError(t, flagSet.Parse([]string{"--feature-gates=-confmap.expandEnabld", "--feature-gates-lenient"}))
+	var warning error
+	assert.NoError(t, applyFeatureGatesFlag(flagSet, func(err error) { warning = err }))
+	assert.EqualError(t, warning, "feature gate confmap.expandEnabld is unregistered, did you mean confmap.expandEnabled?")
+}
@@ -0,0 +1,315 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/service/internal/configunmarshaler"
+)
+
+// generateSettings are the components and pipelines selected for the generated configuration.
+type generateSettings struct {
+	receivers  []string
+	processors []string
+	exporters  []string
+	extensions []string
+	pipelines  []string
+}
+
+// newGenerateCommand constructs the command that prints a starter configuration using the
+// default configurations of the selected factories.
+func newGenerateCommand(factories component.Factories) *cobra.Command {
+	var gs generateSettings
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generates a starter configuration using the selected components",
+		Long: "Generates a minimal valid configuration using the default configuration of the selected components. " +
+			"Every pipeline uses all the selected receivers, processors and exporters supporting its data type.",
+		Example: "  generate --receivers otlp --exporters otlp,logging --pipelines traces,metrics",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := generateStarterConfig(factories, gs)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(out)
+			return err
+		},
+	}
+	cmd.Flags().StringSliceVar(&gs.receivers, "receivers", nil, "Comma separated receivers, as type[/name].")
+	cmd.Flags().StringSliceVar(&gs.processors, "processors", nil, "Comma separated processors, as type[/name].")
+	cmd.Flags().StringSliceVar(&gs.exporters, "exporters", nil, "Comma separated exporters, as type[/name].")
+	cmd.Flags().StringSliceVar(&gs.extensions, "extensions", nil, "Comma separated extensions, as type[/name].")
+	cmd.Flags().StringSliceVar(&gs.pipelines, "pipelines", []string{string(config.TracesDataType)}, "Comma separated pipelines, as data_type[/name].")
+	return cmd
+}
+
+// generateStarterConfig returns the YAML configuration using the selected components, after checking that it is valid.
+func generateStarterConfig(factories component.Factories, gs generateSettings) ([]byte, error) {
+	if len(gs.receivers) == 0 || len(gs.exporters) == 0 {
+		return nil, errors.New("at least one receiver and one exporter must be selected")
+	}
+	if len(gs.pipelines) == 0 {
+		return nil, errors.New("at least one pipeline must be selected")
+	}
+
+	receivers, err := generateComponents("receiver", gs.receivers, func(t config.Type) (component.Factory, interface{}) {
+		if f, ok := factories.Receivers[t]; ok {
+			return f, f.CreateDefaultConfig()
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	processors, err := generateComponents("processor", gs.processors, func(t config.Type) (component.Factory, interface{}) {
+		if f, ok := factories.Processors[t]; ok {
+			return f, f.CreateDefaultConfig()
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	exporters, err := generateComponents("exporter", gs.exporters, func(t config.Type) (component.Factory, interface{}) {
+		if f, ok := factories.Exporters[t]; ok {
+			return f, f.CreateDefaultConfig()
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	extensions, err := generateComponents("extension", gs.extensions, func(t config.Type) (component.Factory, interface{}) {
+		if f, ok := factories.Extensions[t]; ok {
+			return f, f.CreateDefaultConfig()
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pipelines := make(map[string]interface{}, len(gs.pipelines))
+	for _, p := range gs.pipelines {
+		id, err := config.NewComponentIDFromString(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pipeline %q: %w", p, err)
+		}
+		dt := id.Type()
+		if dt != config.TracesDataType && dt != config.MetricsDataType && dt != config.LogsDataType {
+			return nil, fmt.Errorf("unknown pipeline data type %q for %q", dt, p)
+		}
+		pipeline := map[string]interface{}{
+			"receivers": receivers.supporting(dt),
+			"exporters": exporters.supporting(dt),
+		}
+		if len(pipeline["receivers"].([]string)) == 0 || len(pipeline["exporters"].([]string)) == 0 {
+			return nil, fmt.Errorf("pipeline %q needs at least one selected receiver and one selected exporter supporting %q", p, dt)
+		}
+		if procs := processors.supporting(dt); len(procs) > 0 {
+			pipeline["processors"] = procs
+		}
+		pipelines[id.String()] = pipeline
+	}
+
+	service := map[string]interface{}{"pipelines": pipelines}
+	raw := map[string]interface{}{
+		"receivers": receivers.configs,
+		"exporters": exporters.configs,
+		"service":   service,
+	}
+	if len(processors.configs) > 0 {
+		raw["processors"] = processors.configs
+	}
+	if len(extensions.configs) > 0 {
+		raw["extensions"] = extensions.configs
+		service["extensions"] = extensions.ids
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(raw); err != nil {
+		return nil, err
+	}
+	out := buf.Bytes()
+	if err := validateGeneratedConfig(factories, out); err != nil {
+		return nil, fmt.Errorf("the generated configuration is invalid: %w", err)
+	}
+	return out, nil
+}
+
+// validateGeneratedConfig checks that the YAML configuration is loaded and validated as the collector does.
+func validateGeneratedConfig(factories component.Factories, out []byte) error {
+	var rawConf map[string]interface{}
+	if err := yaml.Unmarshal(out, &rawConf); err != nil {
+		return err
+	}
+	cfg, err := configunmarshaler.New().Unmarshal(confmap.NewFromStringMap(rawConf), factories)
+	if err != nil {
+		return err
+	}
+	return cfg.Validate()
+}
+
+// generatedComponents are the generated configurations of the selected components of a kind.
+type generatedComponents struct {
+	// ids of the components, in the selected order.
+	ids []string
+	// configs of the components, by id.
+	configs map[string]interface{}
+	// factories of the components, by id.
+	factories map[string]component.Factory
+}
+
+// supporting returns the ids of the components supporting the given data type.
+func (gc generatedComponents) supporting(dt config.DataType) []string {
+	var ids []string
+	for _, id := range gc.ids {
+		if gc.factories[id].StabilityLevel(dt) != component.StabilityLevelUndefined {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func generateComponents(kind string, selected []string, newDefault func(config.Type) (component.Factory, interface{})) (generatedComponents, error) {
+	gc := generatedComponents{
+		configs:   make(map[string]interface{}, len(selected)),
+		factories: make(map[string]component.Factory, len(selected)),
+	}
+	for _, s := range selected {
+		id, err := config.NewComponentIDFromString(s)
+		if err != nil {
+			return gc, fmt.Errorf("invalid %s %q: %w", kind, s, err)
+		}
+		if _, ok := gc.configs[id.String()]; ok {
+			return gc, fmt.Errorf("duplicate %s %q", kind, id)
+		}
+		factory, cfg := newDefault(id.Type())
+		if factory == nil {
+			return gc, fmt.Errorf("unknown %s type %q for %q", kind, id.Type(), id)
+		}
+		gc.ids = append(gc.ids, id.String())
+		gc.configs[id.String()] = encodeConfigValue(reflect.ValueOf(cfg))
+		gc.factories[id.String()] = factory
+	}
+	return gc, nil
+}
+
+var (
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// encodeConfigValue encodes a configuration value as the raw configuration decoded into it, following
+// the mapstructure tags. Zero values are omitted, since they are the defaults anyway, and nil is
+// returned if nothing is left.
+func encodeConfigValue(v reflect.Value) interface{} {
+	if !v.IsValid() || v.IsZero() {
+		return nil
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	if v.Type().Implements(textMarshalerType) && v.CanInterface() {
+		if text, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return encodeConfigValue(v.Elem())
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		encodeStructFields(v, m)
+		if len(m) == 0 {
+			return nil
+		}
+		return m
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(encodeConfigValue(iter.Key()))] = encodeConfigValue(iter.Value())
+		}
+		if len(m) == 0 {
+			return nil
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		s := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s = append(s, encodeConfigValue(v.Index(i)))
+		}
+		if len(s) == 0 {
+			return nil
+		}
+		return s
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// encodeStructFields adds the non-zero exported fields of the struct to m, squashing the embedded ones.
+func encodeStructFields(v reflect.Value, m map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// Unexported fields are not configurable.
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if strings.Contains(opts, "squash") {
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				encodeStructFields(fv, m)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if val := encodeConfigValue(fv); val != nil {
+			m[name] = val
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestGenerateCommand(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"generate", "--receivers", "nop", "--processors", "nop", "--exporters", "nop,nop/2", "--extensions", "nop", "--pipelines", "traces,metrics/2"})
+	require.NoError(t, cmd.Execute())
+
+	var raw map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(out.Bytes(), &raw))
+	assert.Equal(t, map[string]interface{}{
+		"receivers":  map[string]interface{}{"nop": nil},
+		"processors": map[string]interface{}{"nop": nil},
+		"exporters":  map[string]interface{}{"nop": nil, "nop/2": nil},
+		"extensions": map[string]interface{}{"nop": nil},
+		"service": map[string]interface{}{
+			"extensions": []interface{}{"nop"},
+			"pipelines": map[string]interface{}{
+				"traces": map[string]interface{}{
+					"receivers":  []interface{}{"nop"},
+					"processors": []interface{}{"nop"},
+					"exporters":  []interface{}{"nop", "nop/2"},
+				},
+				"metrics/2": map[string]interface{}{
+					"receivers":  []interface{}{"nop"},
+					"processors": []interface{}{"nop"},
+					"exporters":  []interface{}{"nop", "nop/2"},
+				},
+			},
+		},
+	}, raw)
+}
+
+func TestGenerateStarterConfigErrors(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		settings generateSettings
+		expected string
+	}{
+		{
+			name:     "no_exporters",
+			settings: generateSettings{receivers: []string{"nop"}, pipelines: []string{"traces"}},
+			expected: "at least one receiver and one exporter must be selected",
+		},
+		{
+			name:     "no_pipelines",
+			settings: generateSettings{receivers: []string{"nop"}, exporters: []string{"nop"}},
+			expected: "at least one pipeline must be selected",
+		},
+		{
+			name:     "unknown_receiver",
+			settings: generateSettings{receivers: []string{"otlp"}, exporters: []string{"nop"}, pipelines: []string{"traces"}},
+			expected: `unknown receiver type "otlp" for "otlp"`,
+		},
+		{
+			name:     "invalid_exporter",
+			settings: generateSettings{receivers: []string{"nop"}, exporters: []string{"nop/"}, pipelines: []string{"traces"}},
+			expected: `invalid exporter "nop/": in "nop/" id: the part after / should not be empty`,
+		},
+		{
+			name:     "duplicate_processor",
+			settings: generateSettings{receivers: []string{"nop"}, processors: []string{"nop", " nop"}, exporters: []string{"nop"}, pipelines: []string{"traces"}},
+			expected: `duplicate processor "nop"`,
+		},
+		{
+			name:     "unknown_pipeline_type",
+			settings: generateSettings{receivers: []string{"nop"}, exporters: []string{"nop"}, pipelines: []string{"profiles"}},
+			expected: `unknown pipeline data type "profiles" for "profiles"`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generateStarterConfig(factories, tt.settings)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+type generateTestEmbedded struct {
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+type generateTestConfig struct {
+	Embedded   generateTestEmbedded  `mapstructure:",squash"`
+	Timeout    time.Duration         `mapstructure:"timeout"`
+	Level      zapcore.Level         `mapstructure:"level"`
+	Headers    map[string]string     `mapstructure:"headers"`
+	Tags       []string              `mapstructure:"tags"`
+	Nested     *generateTestEmbedded `mapstructure:"nested"`
+	Empty      generateTestEmbedded  `mapstructure:"empty"`
+	Skipped    string                `mapstructure:"-"`
+	Untagged   int
+	unexported string
+	Extra      map[string]interface{} `mapstructure:"extra"`
+}
+
+func TestEncodeConfigValue(t *testing.T) {
+	cfg := &generateTestConfig{
+		Embedded:   generateTestEmbedded{Endpoint: "localhost:4317"},
+		Timeout:    5 * time.Second,
+		Level:      zapcore.DebugLevel,
+		Headers:    map[string]string{"key": "value"},
+		Tags:       []string{"a", "b"},
+		Nested:     &generateTestEmbedded{Endpoint: "nested"},
+		Skipped:    "skipped",
+		Untagged:   1,
+		unexported: "unexported",
+		Extra:      map[string]interface{}{},
+	}
+	assert.Equal(t, map[string]interface{}{
+		"endpoint": "localhost:4317",
+		"timeout":  "5s",
+		"level":    "debug",
+		"headers":  map[string]interface{}{"key": "value"},
+		"tags":     []interface{}{"a", "b"},
+		"nested":   map[string]interface{}{"endpoint": "nested"},
+		"untagged": 1,
+	}, encodeConfigValue(reflect.ValueOf(cfg)))
+
+	assert.Nil(t, encodeConfigValue(reflect.ValueOf(&generateTestConfig{})))
+}
@@ -17,16 +17,24 @@ package service // import "go.opentelemetry.io/collector/service"
 import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/tap"
 	"go.opentelemetry.io/collector/service/extensions"
 	"go.opentelemetry.io/collector/service/internal/pipelines"
 )
 
-var _ component.Host = (*serviceHost)(nil)
+var (
+	_ component.Host = (*serviceHost)(nil)
+	_ tap.Host       = (*serviceHost)(nil)
+)
 
 type serviceHost struct {
 	asyncErrorChannel chan error
 	factories         component.Factories
 	buildInfo         component.BuildInfo
+	configHash        string
+	configSources     []string
+	effectiveConfig   map[string]interface{}
+	taps              *tap.Registry
 
 	pipelines  *pipelines.Pipelines
 	extensions *extensions.Extensions
@@ -60,3 +68,15 @@ func (host *serviceHost) GetExtensions() map[config.ComponentID]component.Extens
 func (host *serviceHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
 	return host.pipelines.GetExporters()
 }
+
+// ConfigHash returns the hash of the effective configuration the service was started with,
+// or an empty string if not available. Extensions reporting the collector health can access
+// it by asserting that the component.Host implements interface{ ConfigHash() string }.
+func (host *serviceHost) ConfigHash() string {
+	return host.configHash
+}
+
+// GetTaps returns the registry of the tap points of the pipelines.
+func (host *serviceHost) GetTaps() *tap.Registry {
+	return host.taps
+}
@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components // import "go.opentelemetry.io/collector/service/internal/components"
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+)
+
+// StartWithTimeout starts the given component of the given kind and ID, and fails if it does not
+// return within the timeout. The context passed to Start is canceled once the timeout elapses, but
+// a component that ignores it is abandoned, still running, so that it cannot block the service.
+// Zero means no timeout.
+func StartWithTimeout(ctx context.Context, comp component.Component, host component.Host, kind string, id config.ComponentID, timeout time.Duration) error {
+	return runWithTimeout(ctx, timeout, func(ctx context.Context) error {
+		return comp.Start(ctx, host)
+	}, func() error {
+		return fmt.Errorf("%s %q did not start within %v", kind, id, timeout)
+	})
+}
+
+// ShutdownWithTimeout shuts down the given component of the given kind and ID, and fails if it does
+// not return within the timeout, abandoning the component as StartWithTimeout does. Zero means no timeout.
+func ShutdownWithTimeout(ctx context.Context, comp component.Component, kind string, id config.ComponentID, timeout time.Duration) error {
+	return runWithTimeout(ctx, timeout, comp.Shutdown, func() error {
+		return fmt.Errorf("%s %q did not shut down within %v", kind, id, timeout)
+	})
+}
+
+func runWithTimeout(ctx context.Context, timeout time.Duration, f func(context.Context) error, timeoutErr func() error) error {
+	if timeout <= 0 {
+		return f(ctx)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f(timeoutCtx)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-timeoutCtx.Done():
+		if err := ctx.Err(); err != nil {
+			// Done because of the parent context, not the timeout.
+			return err
+		}
+		return timeoutErr()
+	}
+}
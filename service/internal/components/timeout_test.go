@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+)
+
+type blockingComponent struct {
+	// release unblocks Start and Shutdown, that otherwise ignore the context.
+	release chan struct{}
+	err     error
+}
+
+func (bc *blockingComponent) Start(context.Context, component.Host) error {
+	<-bc.release
+	return bc.err
+}
+
+func (bc *blockingComponent) Shutdown(context.Context) error {
+	<-bc.release
+	return bc.err
+}
+
+func TestStartWithTimeout(t *testing.T) {
+	id := config.NewComponentID("otlp")
+	host := componenttest.NewNopHost()
+
+	bc := &blockingComponent{release: make(chan struct{})}
+	defer close(bc.release)
+	err := StartWithTimeout(context.Background(), bc, host, ZapKindExporter, id, 10*time.Millisecond)
+	assert.EqualError(t, err, `exporter "otlp" did not start within 10ms`)
+
+	err = ShutdownWithTimeout(context.Background(), bc, ZapKindExporter, id, 10*time.Millisecond)
+	assert.EqualError(t, err, `exporter "otlp" did not shut down within 10ms`)
+}
+
+func TestStartWithTimeoutReturns(t *testing.T) {
+	id := config.NewComponentID("otlp")
+	host := componenttest.NewNopHost()
+
+	bc := &blockingComponent{release: make(chan struct{}), err: errors.New("my error")}
+	close(bc.release)
+	assert.EqualError(t, StartWithTimeout(context.Background(), bc, host, ZapKindReceiver, id, time.Minute), "my error")
+	assert.EqualError(t, ShutdownWithTimeout(context.Background(), bc, ZapKindReceiver, id, time.Minute), "my error")
+
+	// No timeout.
+	assert.EqualError(t, StartWithTimeout(context.Background(), bc, host, ZapKindReceiver, id, 0), "my error")
+	assert.EqualError(t, ShutdownWithTimeout(context.Background(), bc, ZapKindReceiver, id, 0), "my error")
+}
+
+func TestStartWithTimeoutContextHonored(t *testing.T) {
+	id := config.NewComponentID("otlp")
+	done := make(chan struct{})
+	comp := startFunc(func(ctx context.Context) error {
+		defer close(done)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	err := StartWithTimeout(context.Background(), comp, componenttest.NewNopHost(), ZapKindExporter, id, 10*time.Millisecond)
+	assert.EqualError(t, err, `exporter "otlp" did not start within 10ms`)
+	// The context passed to Start is canceled.
+	<-done
+}
+
+func TestStartWithTimeoutParentCanceled(t *testing.T) {
+	bc := &blockingComponent{release: make(chan struct{})}
+	defer close(bc.release)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := StartWithTimeout(ctx, bc, componenttest.NewNopHost(), ZapKindExporter, config.NewComponentID("otlp"), time.Minute)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// startFunc is a component running the given func on Start.
+type startFunc func(ctx context.Context) error
+
+func (f startFunc) Start(ctx context.Context, _ component.Host) error {
+	return f(ctx)
+}
+
+func (f startFunc) Shutdown(context.Context) error {
+	return nil
+}
@@ -15,9 +15,14 @@
 package configunmarshaler // import "go.opentelemetry.io/collector/service/internal/configunmarshaler"
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"go.uber.org/zap/zapcore"
 
 	"go.opentelemetry.io/collector/component"
@@ -65,6 +70,9 @@ const (
 	// processorsKeyName is the configuration key name for processors section.
 	processorsKeyName = "processors"
 
+	// serviceKeyName is the configuration key name for service section.
+	serviceKeyName = "service"
+
 	// pipelinesKeyName is the configuration key name for pipelines section.
 	pipelinesKeyName = "pipelines"
 )
@@ -100,35 +108,35 @@ func (ConfigUnmarshaler) Unmarshal(v *confmap.Conf, factories component.Factorie
 	}
 
 	var err error
-	if cfg.Extensions, err = unmarshalExtensions(rawCfg.Extensions, factories.Extensions); err != nil {
+	if cfg.Extensions, err = unmarshalExtensions(v, rawCfg.Extensions, factories.Extensions); err != nil {
 		return nil, configError{
 			error: err,
 			code:  errUnmarshalExtension,
 		}
 	}
 
-	if cfg.Receivers, err = unmarshalReceivers(rawCfg.Receivers, factories.Receivers); err != nil {
+	if cfg.Receivers, err = unmarshalReceivers(v, rawCfg.Receivers, factories.Receivers); err != nil {
 		return nil, configError{
 			error: err,
 			code:  errUnmarshalReceiver,
 		}
 	}
 
-	if cfg.Processors, err = unmarshalProcessors(rawCfg.Processors, factories.Processors); err != nil {
+	if cfg.Processors, err = unmarshalProcessors(v, rawCfg.Processors, factories.Processors); err != nil {
 		return nil, configError{
 			error: err,
 			code:  errUnmarshalProcessor,
 		}
 	}
 
-	if cfg.Exporters, err = unmarshalExporters(rawCfg.Exporters, factories.Exporters); err != nil {
+	if cfg.Exporters, err = unmarshalExporters(v, rawCfg.Exporters, factories.Exporters); err != nil {
 		return nil, configError{
 			error: err,
 			code:  errUnmarshalExporter,
 		}
 	}
 
-	if cfg.Service, err = unmarshalService(rawCfg.Service); err != nil {
+	if cfg.Service, err = unmarshalService(v, rawCfg.Service); err != nil {
 		return nil, configError{
 			error: err,
 			code:  errUnmarshalService,
@@ -138,7 +146,7 @@ func (ConfigUnmarshaler) Unmarshal(v *confmap.Conf, factories component.Factorie
 	return &cfg, nil
 }
 
-func unmarshalExtensions(exts map[config.ComponentID]map[string]interface{}, factories map[config.Type]component.ExtensionFactory) (map[config.ComponentID]config.Extension, error) {
+func unmarshalExtensions(v *confmap.Conf, exts map[config.ComponentID]map[string]interface{}, factories map[config.Type]component.ExtensionFactory) (map[config.ComponentID]config.Extension, error) {
 	// Prepare resulting map.
 	extensions := make(map[config.ComponentID]config.Extension)
 
@@ -147,7 +155,7 @@ func unmarshalExtensions(exts map[config.ComponentID]map[string]interface{}, fac
 		// Find extension factory based on "type" that we read from config source.
 		factory, ok := factories[id.Type()]
 		if !ok {
-			return nil, errorUnknownType(extensionsKeyName, id, reflect.ValueOf(factories).MapKeys())
+			return nil, v.WithPosition(componentKey(extensionsKeyName, id), errorUnknownType(extensionsKeyName, id, reflect.ValueOf(factories).MapKeys()))
 		}
 
 		// Create the default config for this extension.
@@ -157,7 +165,7 @@ func unmarshalExtensions(exts map[config.ComponentID]map[string]interface{}, fac
 		// Now that the default config struct is created we can Unmarshal into it,
 		// and it will apply user-defined config on top of the default.
 		if err := config.UnmarshalExtension(confmap.NewFromStringMap(value), extensionCfg); err != nil {
-			return nil, errorUnmarshalError(extensionsKeyName, id, err)
+			return nil, v.WithPosition(fieldKey(componentKey(extensionsKeyName, id), err), errorUnmarshalError(extensionsKeyName, id, err))
 		}
 
 		extensions[id] = extensionCfg
@@ -166,7 +174,7 @@ func unmarshalExtensions(exts map[config.ComponentID]map[string]interface{}, fac
 	return extensions, nil
 }
 
-func unmarshalService(srvRaw map[string]interface{}) (config.Service, error) {
+func unmarshalService(v *confmap.Conf, srvRaw map[string]interface{}) (config.Service, error) {
 	// Setup default telemetry values as in service/logger.go.
 	// TODO: Add a component.ServiceFactory to allow this to be defined by the Service.
 	srv := config.Service{
@@ -183,20 +191,34 @@ func unmarshalService(srvRaw map[string]interface{}) (config.Service, error) {
 			},
 			Metrics: defaultServiceTelemetryMetricsSettings(),
 		},
+		Timeouts: config.ServiceTimeouts{
+			ComponentTimeouts: config.ComponentTimeouts{
+				Start:    defaultComponentStartTimeout,
+				Shutdown: defaultComponentShutdownTimeout,
+			},
+		},
 	}
 
 	if err := confmap.NewFromStringMap(srvRaw).UnmarshalExact(&srv); err != nil {
-		return srv, fmt.Errorf("error reading service configuration: %w", err)
+		return srv, v.WithPosition(fieldKey(serviceKeyName, err), fmt.Errorf("error reading service configuration: %w", err))
 	}
 
 	for id := range srv.Pipelines {
 		if id.Type() != config.TracesDataType && id.Type() != config.MetricsDataType && id.Type() != config.LogsDataType {
-			return srv, fmt.Errorf("unknown %q datatype %q for %v", pipelinesKeyName, id.Type(), id)
+			err := fmt.Errorf("unknown %q datatype %q for %v", pipelinesKeyName, id.Type(), id)
+			return srv, v.WithPosition(serviceKeyName+confmap.KeyDelimiter+pipelinesKeyName+confmap.KeyDelimiter+id.String(), err)
 		}
 	}
 	return srv, nil
 }
 
+// Default timeouts of starting and shutting down the components, long enough for the components
+// connecting to remote endpoints but bounding a component stuck e.g. resolving DNS.
+const (
+	defaultComponentStartTimeout    = time.Minute
+	defaultComponentShutdownTimeout = time.Minute
+)
+
 func defaultServiceTelemetryMetricsSettings() telemetry.MetricsConfig {
 	return telemetry.MetricsConfig{
 		Level:   configtelemetry.LevelBasic, //nolint:staticcheck
@@ -219,7 +241,7 @@ func LoadReceiver(componentConfig *confmap.Conf, id config.ComponentID, factory
 	return receiverCfg, nil
 }
 
-func unmarshalReceivers(recvs map[config.ComponentID]map[string]interface{}, factories map[config.Type]component.ReceiverFactory) (map[config.ComponentID]config.Receiver, error) {
+func unmarshalReceivers(v *confmap.Conf, recvs map[config.ComponentID]map[string]interface{}, factories map[config.Type]component.ReceiverFactory) (map[config.ComponentID]config.Receiver, error) {
 	// Prepare resulting map.
 	receivers := make(map[config.ComponentID]config.Receiver)
 
@@ -228,13 +250,13 @@ func unmarshalReceivers(recvs map[config.ComponentID]map[string]interface{}, fac
 		// Find receiver factory based on "type" that we read from config source.
 		factory := factories[id.Type()]
 		if factory == nil {
-			return nil, errorUnknownType(receiversKeyName, id, reflect.ValueOf(factories).MapKeys())
+			return nil, v.WithPosition(componentKey(receiversKeyName, id), errorUnknownType(receiversKeyName, id, reflect.ValueOf(factories).MapKeys()))
 		}
 
 		receiverCfg, err := LoadReceiver(confmap.NewFromStringMap(value), id, factory)
 		if err != nil {
 			// LoadReceiver already wraps the error.
-			return nil, err
+			return nil, v.WithPosition(fieldKey(componentKey(receiversKeyName, id), err), err)
 		}
 
 		receivers[id] = receiverCfg
@@ -243,7 +265,7 @@ func unmarshalReceivers(recvs map[config.ComponentID]map[string]interface{}, fac
 	return receivers, nil
 }
 
-func unmarshalExporters(exps map[config.ComponentID]map[string]interface{}, factories map[config.Type]component.ExporterFactory) (map[config.ComponentID]config.Exporter, error) {
+func unmarshalExporters(v *confmap.Conf, exps map[config.ComponentID]map[string]interface{}, factories map[config.Type]component.ExporterFactory) (map[config.ComponentID]config.Exporter, error) {
 	// Prepare resulting map.
 	exporters := make(map[config.ComponentID]config.Exporter)
 
@@ -252,7 +274,7 @@ func unmarshalExporters(exps map[config.ComponentID]map[string]interface{}, fact
 		// Find exporter factory based on "type" that we read from config source.
 		factory := factories[id.Type()]
 		if factory == nil {
-			return nil, errorUnknownType(exportersKeyName, id, reflect.ValueOf(factories).MapKeys())
+			return nil, v.WithPosition(componentKey(exportersKeyName, id), errorUnknownType(exportersKeyName, id, reflect.ValueOf(factories).MapKeys()))
 		}
 
 		// Create the default config for this exporter.
@@ -262,7 +284,7 @@ func unmarshalExporters(exps map[config.ComponentID]map[string]interface{}, fact
 		// Now that the default config struct is created we can Unmarshal into it,
 		// and it will apply user-defined config on top of the default.
 		if err := config.UnmarshalExporter(confmap.NewFromStringMap(value), exporterCfg); err != nil {
-			return nil, errorUnmarshalError(exportersKeyName, id, err)
+			return nil, v.WithPosition(fieldKey(componentKey(exportersKeyName, id), err), errorUnmarshalError(exportersKeyName, id, err))
 		}
 
 		exporters[id] = exporterCfg
@@ -271,7 +293,7 @@ func unmarshalExporters(exps map[config.ComponentID]map[string]interface{}, fact
 	return exporters, nil
 }
 
-func unmarshalProcessors(procs map[config.ComponentID]map[string]interface{}, factories map[config.Type]component.ProcessorFactory) (map[config.ComponentID]config.Processor, error) {
+func unmarshalProcessors(v *confmap.Conf, procs map[config.ComponentID]map[string]interface{}, factories map[config.Type]component.ProcessorFactory) (map[config.ComponentID]config.Processor, error) {
 	// Prepare resulting map.
 	processors := make(map[config.ComponentID]config.Processor)
 
@@ -280,7 +302,7 @@ func unmarshalProcessors(procs map[config.ComponentID]map[string]interface{}, fa
 		// Find processor factory based on "type" that we read from config source.
 		factory := factories[id.Type()]
 		if factory == nil {
-			return nil, errorUnknownType(processorsKeyName, id, reflect.ValueOf(factories).MapKeys())
+			return nil, v.WithPosition(componentKey(processorsKeyName, id), errorUnknownType(processorsKeyName, id, reflect.ValueOf(factories).MapKeys()))
 		}
 
 		// Create the default config for this processor.
@@ -290,7 +312,7 @@ func unmarshalProcessors(procs map[config.ComponentID]map[string]interface{}, fa
 		// Now that the default config struct is created we can Unmarshal into it,
 		// and it will apply user-defined config on top of the default.
 		if err := config.UnmarshalProcessor(confmap.NewFromStringMap(value), processorCfg); err != nil {
-			return nil, errorUnmarshalError(processorsKeyName, id, err)
+			return nil, v.WithPosition(fieldKey(componentKey(processorsKeyName, id), err), errorUnmarshalError(processorsKeyName, id, err))
 		}
 
 		processors[id] = processorCfg
@@ -306,3 +328,38 @@ func errorUnknownType(component string, id config.ComponentID, factories []refle
 func errorUnmarshalError(component string, id config.ComponentID, err error) error {
 	return fmt.Errorf("error reading %s configuration for %q: %w", component, id, err)
 }
+
+// componentKey returns the configuration key of the component in the given section.
+func componentKey(section string, id config.ComponentID) string {
+	return section + confmap.KeyDelimiter + id.String()
+}
+
+// mapstructureFieldRegexp matches the name of the field, and the first invalid key if any,
+// reported by mapstructure errors such as "'tls' has invalid keys: insecure_skip" or
+// "error decoding 'timeout': ...".
+var mapstructureFieldRegexp = regexp.MustCompile(`^(?:error decoding )?'([^']*)'(?: has invalid keys: ([^,]+))?`)
+
+// fieldKey returns the configuration key of the first field reported by the mapstructure error
+// unmarshalling the configuration at the key, or the key itself if no field can be found.
+func fieldKey(key string, err error) string {
+	var msErr *mapstructure.Error
+	if !errors.As(err, &msErr) || len(msErr.Errors) == 0 {
+		return key
+	}
+	match := mapstructureFieldRegexp.FindStringSubmatch(msErr.Errors[0])
+	if match == nil {
+		return key
+	}
+	// Nested fields are reported as "field.sub", and map entries as "field[entry]".
+	name := strings.NewReplacer("[", ".", "]", "").Replace(match[1])
+	var parts []string
+	for _, part := range append(strings.Split(name, "."), match[2]) {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return key
+	}
+	return key + confmap.KeyDelimiter + strings.Join(parts, confmap.KeyDelimiter)
+}
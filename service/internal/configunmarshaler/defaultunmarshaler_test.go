@@ -15,10 +15,15 @@
 package configunmarshaler
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -29,6 +34,7 @@ import (
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configtelemetry"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
 	"go.opentelemetry.io/collector/service/telemetry"
 )
 
@@ -79,6 +85,15 @@ func TestDecodeConfig(t *testing.T) {
 			},
 		}, cfg.Service.Telemetry)
 
+	// Verify Service Timeouts, the start timeout is not set so it has the default value.
+	assert.Equal(t,
+		config.ServiceTimeouts{
+			ComponentTimeouts: config.ComponentTimeouts{Start: time.Minute, Shutdown: 10 * time.Second},
+			Exporters: map[config.ComponentID]config.ComponentTimeouts{
+				config.NewComponentIDWithName("nop", "myexporter"): {Start: 5 * time.Second},
+			},
+		}, cfg.Service.Timeouts)
+
 	// Verify Service Extensions
 	assert.Equal(t, 2, len(cfg.Service.Extensions))
 	assert.Equal(t, config.NewComponentIDWithName("nop", "0"), cfg.Service.Extensions[0])
@@ -170,6 +185,55 @@ func TestDecodeConfig_Invalid(t *testing.T) {
 	}
 }
 
+func TestDecodeConfig_InvalidPositions(t *testing.T) {
+	var testCases = []struct {
+		name            string // file name containing config yaml
+		expectedMessage string // string that the error must start with
+	}{
+		{name: "unknown-exporter-type", expectedMessage: "uri=file:testdata/unknown-exporter-type.yaml line 4 column 3, key exporters::nosuchexporter: unknown exporters type"},
+		{name: "invalid-exporter-section", expectedMessage: "uri=file:testdata/invalid-exporter-section.yaml line 7 column 5, key exporters::nop::unknown_section: error reading exporters configuration for \"nop\""},
+		{name: "unknown-pipeline-type", expectedMessage: "uri=file:testdata/unknown-pipeline-type.yaml line"},
+	}
+
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			uri := "file:" + filepath.ToSlash(filepath.Join("testdata", test.name+".yaml"))
+			ret, err := fileprovider.New().Retrieve(context.Background(), uri, nil)
+			require.NoError(t, err)
+			conf, err := ret.AsConf()
+			require.NoError(t, err)
+
+			_, err = New().Unmarshal(conf, factories)
+			require.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), test.expectedMessage), err)
+		})
+	}
+}
+
+func TestFieldKey(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "not_mapstructure", err: errors.New("invalid"), expected: "exporters::nop"},
+		{name: "invalid_keys", err: &mapstructure.Error{Errors: []string{"'' has invalid keys: foo, bar"}}, expected: "exporters::nop::foo"},
+		{name: "nested_invalid_keys", err: &mapstructure.Error{Errors: []string{"'tls' has invalid keys: foo"}}, expected: "exporters::nop::tls::foo"},
+		{name: "nested_field", err: &mapstructure.Error{Errors: []string{"'tls.ca_file' expected type 'string', got unconvertible type 'int'"}}, expected: "exporters::nop::tls::ca_file"},
+		{name: "map_entry", err: &mapstructure.Error{Errors: []string{"error decoding 'headers[key]': invalid"}}, expected: "exporters::nop::headers::key"},
+		{name: "wrapped", err: fmt.Errorf("wrapped: %w", &mapstructure.Error{Errors: []string{"'endpoint' expected type 'string'"}}), expected: "exporters::nop::endpoint"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, fieldKey("exporters::nop", test.err))
+		})
+	}
+}
+
 func TestLoadEmpty(t *testing.T) {
 	factories, err := componenttest.NopFactories()
 	assert.NoError(t, err)
@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelines // import "go.opentelemetry.io/collector/service/internal/pipelines"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/obsreport"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// The observed consumers report the data flowing through the pipeline: the entry consumers
+// are set before the first processor, the exit consumers before the fan out to the exporters.
+
+var (
+	tracesSizer  = ptrace.NewProtoMarshaler().(ptrace.Sizer)
+	metricsSizer = pmetric.NewProtoMarshaler().(pmetric.Sizer)
+	logsSizer    = plog.NewProtoMarshaler().(plog.Sizer)
+)
+
+type entryLogs struct {
+	consumer.Logs
+	obsrep *obsreport.Pipeline
+}
+
+func (el entryLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	numRecords := ld.LogRecordCount()
+	ctx = el.obsrep.StartConsume(ctx, numRecords, logsSize(el.obsrep, ld))
+	err := el.Logs.ConsumeLogs(ctx, ld)
+	el.obsrep.EndConsume(ctx, numRecords, err)
+	return err
+}
+
+type exitLogs struct {
+	consumer.Logs
+	obsrep *obsreport.Pipeline
+}
+
+func (el exitLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	numRecords, size := ld.LogRecordCount(), logsSize(el.obsrep, ld)
+	err := el.Logs.ConsumeLogs(ctx, ld)
+	el.obsrep.EndExport(ctx, numRecords, size, err)
+	return err
+}
+
+func logsSize(obsrep *obsreport.Pipeline, ld plog.Logs) int {
+	if !obsrep.BytesEnabled() {
+		return 0
+	}
+	return logsSizer.LogsSize(ld)
+}
+
+type entryMetrics struct {
+	consumer.Metrics
+	obsrep *obsreport.Pipeline
+}
+
+func (em entryMetrics) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	numPoints := md.DataPointCount()
+	ctx = em.obsrep.StartConsume(ctx, numPoints, metricsSize(em.obsrep, md))
+	err := em.Metrics.ConsumeMetrics(ctx, md)
+	em.obsrep.EndConsume(ctx, numPoints, err)
+	return err
+}
+
+type exitMetrics struct {
+	consumer.Metrics
+	obsrep *obsreport.Pipeline
+}
+
+func (em exitMetrics) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	numPoints, size := md.DataPointCount(), metricsSize(em.obsrep, md)
+	err := em.Metrics.ConsumeMetrics(ctx, md)
+	em.obsrep.EndExport(ctx, numPoints, size, err)
+	return err
+}
+
+func metricsSize(obsrep *obsreport.Pipeline, md pmetric.Metrics) int {
+	if !obsrep.BytesEnabled() {
+		return 0
+	}
+	return metricsSizer.MetricsSize(md)
+}
+
+type entryTraces struct {
+	consumer.Traces
+	obsrep *obsreport.Pipeline
+}
+
+func (et entryTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	numSpans := td.SpanCount()
+	ctx = et.obsrep.StartConsume(ctx, numSpans, tracesSize(et.obsrep, td))
+	err := et.Traces.ConsumeTraces(ctx, td)
+	et.obsrep.EndConsume(ctx, numSpans, err)
+	return err
+}
+
+type exitTraces struct {
+	consumer.Traces
+	obsrep *obsreport.Pipeline
+}
+
+func (et exitTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	numSpans, size := td.SpanCount(), tracesSize(et.obsrep, td)
+	err := et.Traces.ConsumeTraces(ctx, td)
+	et.obsrep.EndExport(ctx, numSpans, size, err)
+	return err
+}
+
+func tracesSize(obsrep *obsreport.Pipeline, td ptrace.Traces) int {
+	if !obsrep.BytesEnabled() {
+		return 0
+	}
+	return tracesSizer.TracesSize(td)
+}
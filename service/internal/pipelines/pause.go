@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelines // import "go.opentelemetry.io/collector/service/internal/pipelines"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// ErrPipelinePaused is returned to the receivers by the paused pipelines. It is not a permanent
+// error, so that the data is retried by the senders once the pipeline is resumed.
+var ErrPipelinePaused = errors.New("pipeline is paused")
+
+// PauseRegistry holds the paused pipelines. It outlives the built pipelines, so that the pipelines
+// stay paused across configuration reloads. It is safe for concurrent use.
+type PauseRegistry struct {
+	mu        sync.RWMutex
+	pipelines map[config.ComponentID]struct{}
+	paused    map[config.ComponentID]struct{}
+}
+
+// NewPauseRegistry returns a PauseRegistry with no pipelines.
+func NewPauseRegistry() *PauseRegistry {
+	return &PauseRegistry{
+		pipelines: make(map[config.ComponentID]struct{}),
+		paused:    make(map[config.ComponentID]struct{}),
+	}
+}
+
+// setPipelines replaces the pipelines which can be paused. The pipelines paused before keep
+// their state, even if not part of the given ones.
+func (r *PauseRegistry) setPipelines(ids []config.ComponentID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pipelines = make(map[config.ComponentID]struct{}, len(ids))
+	for _, id := range ids {
+		r.pipelines[id] = struct{}{}
+	}
+}
+
+// Pause pauses the given pipeline. It fails if the pipeline does not exist.
+func (r *PauseRegistry) Pause(id config.ComponentID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.pipelines[id]; !ok {
+		return fmt.Errorf("pipeline %q does not exist", id)
+	}
+	r.paused[id] = struct{}{}
+	return nil
+}
+
+// Resume resumes the given pipeline. It fails if the pipeline does not exist.
+func (r *PauseRegistry) Resume(id config.ComponentID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.pipelines[id]; !ok {
+		return fmt.Errorf("pipeline %q does not exist", id)
+	}
+	delete(r.paused, id)
+	return nil
+}
+
+// Paused returns whether the given pipeline is paused.
+func (r *PauseRegistry) Paused(id config.ComponentID) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.paused[id]
+	return ok
+}
+
+// Pipelines returns the pipelines which can be paused, sorted by ID.
+func (r *PauseRegistry) Pipelines() []config.ComponentID {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]config.ComponentID, 0, len(r.pipelines))
+	for id := range r.pipelines {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
+// The pause consumers reject the data with ErrPipelinePaused while the pipeline is paused,
+// otherwise pass it to the next consumer.
+
+type pauseLogs struct {
+	consumer.Logs
+	pipelineID config.ComponentID
+	pauses     *PauseRegistry
+}
+
+func (pl pauseLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	if pl.pauses.Paused(pl.pipelineID) {
+		return ErrPipelinePaused
+	}
+	return pl.Logs.ConsumeLogs(ctx, ld)
+}
+
+type pauseMetrics struct {
+	consumer.Metrics
+	pipelineID config.ComponentID
+	pauses     *PauseRegistry
+}
+
+func (pm pauseMetrics) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	if pm.pauses.Paused(pm.pipelineID) {
+		return ErrPipelinePaused
+	}
+	return pm.Metrics.ConsumeMetrics(ctx, md)
+}
+
+type pauseTraces struct {
+	consumer.Traces
+	pipelineID config.ComponentID
+	pauses     *PauseRegistry
+}
+
+func (pt pauseTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	if pt.pauses.Paused(pt.pipelineID) {
+		return ErrPipelinePaused
+	}
+	return pt.Traces.ConsumeTraces(ctx, td)
+}
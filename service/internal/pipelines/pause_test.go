@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelines
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+func TestPauseRegistry(t *testing.T) {
+	tracesID := config.NewComponentID(config.TracesDataType)
+	logsID := config.NewComponentID(config.LogsDataType)
+	r := NewPauseRegistry()
+	assert.Empty(t, r.Pipelines())
+	assert.EqualError(t, r.Pause(tracesID), `pipeline "traces" does not exist`)
+	assert.EqualError(t, r.Resume(tracesID), `pipeline "traces" does not exist`)
+
+	r.setPipelines([]config.ComponentID{tracesID, logsID})
+	assert.Equal(t, []config.ComponentID{logsID, tracesID}, r.Pipelines())
+	require.NoError(t, r.Pause(tracesID))
+	assert.True(t, r.Paused(tracesID))
+	assert.False(t, r.Paused(logsID))
+
+	// The paused pipelines stay paused when the pipelines are rebuilt.
+	r.setPipelines([]config.ComponentID{logsID})
+	assert.True(t, r.Paused(tracesID))
+	r.setPipelines([]config.ComponentID{tracesID, logsID})
+	assert.True(t, r.Paused(tracesID))
+
+	require.NoError(t, r.Resume(tracesID))
+	assert.False(t, r.Paused(tracesID))
+	// Resuming a running pipeline is a no-op.
+	require.NoError(t, r.Resume(tracesID))
+	assert.False(t, r.Paused(tracesID))
+}
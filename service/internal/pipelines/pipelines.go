@@ -25,7 +25,10 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtelemetry"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/experimental/tap"
+	"go.opentelemetry.io/collector/obsreport"
 	"go.opentelemetry.io/collector/service/internal/components"
 	"go.opentelemetry.io/collector/service/internal/fanoutconsumer"
 	"go.opentelemetry.io/collector/service/internal/zpages"
@@ -63,6 +66,8 @@ type Pipelines struct {
 	allExporters map[config.DataType]map[config.ComponentID]component.Exporter
 
 	pipelines map[config.ComponentID]*builtPipeline
+
+	timeouts config.ServiceTimeouts
 }
 
 // StartAll starts all pipelines.
@@ -76,7 +81,8 @@ func (bps *Pipelines) StartAll(ctx context.Context, host component.Host) error {
 		for expID, exp := range expByID {
 			expLogger := exporterLogger(bps.telemetry.Logger, expID, dt)
 			expLogger.Info("Exporter is starting...")
-			if err := exp.Start(ctx, components.NewHostWrapper(host, expLogger)); err != nil {
+			if err := components.StartWithTimeout(ctx, exp, components.NewHostWrapper(host, expLogger),
+				components.ZapKindExporter, expID, bps.timeouts.Exporter(expID).Start); err != nil {
 				return err
 			}
 			expLogger.Info("Exporter started.")
@@ -88,7 +94,9 @@ func (bps *Pipelines) StartAll(ctx context.Context, host component.Host) error {
 		for i := len(bp.processors) - 1; i >= 0; i-- {
 			procLogger := processorLogger(bps.telemetry.Logger, bp.processors[i].id, pipelineID)
 			procLogger.Info("Processor is starting...")
-			if err := bp.processors[i].comp.Start(ctx, components.NewHostWrapper(host, procLogger)); err != nil {
+			procID := bp.processors[i].id
+			if err := components.StartWithTimeout(ctx, bp.processors[i].comp, components.NewHostWrapper(host, procLogger),
+				components.ZapKindProcessor, procID, bps.timeouts.Processor(procID).Start); err != nil {
 				return err
 			}
 			procLogger.Info("Processor started.")
@@ -100,7 +108,8 @@ func (bps *Pipelines) StartAll(ctx context.Context, host component.Host) error {
 		for recvID, recv := range recvByID {
 			recvLogger := receiverLogger(bps.telemetry.Logger, recvID, dt)
 			recvLogger.Info("Receiver is starting...")
-			if err := recv.Start(ctx, components.NewHostWrapper(host, recvLogger)); err != nil {
+			if err := components.StartWithTimeout(ctx, recv, components.NewHostWrapper(host, recvLogger),
+				components.ZapKindReceiver, recvID, bps.timeouts.Receiver(recvID).Start); err != nil {
 				return err
 			}
 			recvLogger.Info("Receiver started.")
@@ -117,22 +126,25 @@ func (bps *Pipelines) ShutdownAll(ctx context.Context) error {
 	var errs error
 	bps.telemetry.Logger.Info("Stopping receivers...")
 	for _, recvByID := range bps.allReceivers {
-		for _, recv := range recvByID {
-			errs = multierr.Append(errs, recv.Shutdown(ctx))
+		for recvID, recv := range recvByID {
+			errs = multierr.Append(errs, components.ShutdownWithTimeout(ctx, recv,
+				components.ZapKindReceiver, recvID, bps.timeouts.Receiver(recvID).Shutdown))
 		}
 	}
 
 	bps.telemetry.Logger.Info("Stopping processors...")
 	for _, bp := range bps.pipelines {
 		for _, p := range bp.processors {
-			errs = multierr.Append(errs, p.comp.Shutdown(ctx))
+			errs = multierr.Append(errs, components.ShutdownWithTimeout(ctx, p.comp,
+				components.ZapKindProcessor, p.id, bps.timeouts.Processor(p.id).Shutdown))
 		}
 	}
 
 	bps.telemetry.Logger.Info("Stopping exporters...")
 	for _, expByID := range bps.allExporters {
-		for _, exp := range expByID {
-			errs = multierr.Append(errs, exp.Shutdown(ctx))
+		for expID, exp := range expByID {
+			errs = multierr.Append(errs, components.ShutdownWithTimeout(ctx, exp,
+				components.ZapKindExporter, expID, bps.timeouts.Exporter(expID).Shutdown))
 		}
 	}
 
@@ -202,6 +214,17 @@ type Settings struct {
 
 	// PipelineConfigs is a map of config.ComponentID to config.Pipeline.
 	PipelineConfigs map[config.ComponentID]*config.Pipeline
+
+	// Taps is the registry of the tap points, set between the processors and the exporters of
+	// each pipeline. Nil disables the tap points.
+	Taps *tap.Registry
+
+	// Timeouts are the timeouts of starting and shutting down the components.
+	Timeouts config.ServiceTimeouts
+
+	// Pauses is the registry of the paused pipelines, checked before the first processor of
+	// each pipeline. Nil disables pausing the pipelines.
+	Pauses *PauseRegistry
 }
 
 // Build builds all pipelines from config.
@@ -211,6 +234,15 @@ func Build(ctx context.Context, set Settings) (*Pipelines, error) {
 		allReceivers: make(map[config.DataType]map[config.ComponentID]component.Receiver),
 		allExporters: make(map[config.DataType]map[config.ComponentID]component.Exporter),
 		pipelines:    make(map[config.ComponentID]*builtPipeline, len(set.PipelineConfigs)),
+		timeouts:     set.Timeouts,
+	}
+
+	if set.Pauses != nil {
+		ids := make([]config.ComponentID, 0, len(set.PipelineConfigs))
+		for pipelineID := range set.PipelineConfigs {
+			ids = append(ids, pipelineID)
+		}
+		set.Pauses.setPipelines(ids)
 	}
 
 	receiversConsumers := make(map[config.DataType]map[config.ComponentID][]baseConsumer)
@@ -261,6 +293,30 @@ func Build(ctx context.Context, set Settings) (*Pipelines, error) {
 			return nil, fmt.Errorf("create fan-out exporter in pipeline %q, data type %q is not supported", pipelineID, pipelineID.Type())
 		}
 
+		var obsrep *obsreport.Pipeline
+		if set.Telemetry.MetricsLevel != configtelemetry.LevelNone {
+			obsrep = obsreport.NewPipeline(obsreport.PipelineSettings{Level: set.Telemetry.MetricsLevel, PipelineID: pipelineID})
+			switch pipelineID.Type() {
+			case config.TracesDataType:
+				bp.lastConsumer = exitTraces{Traces: bp.lastConsumer.(consumer.Traces), obsrep: obsrep}
+			case config.MetricsDataType:
+				bp.lastConsumer = exitMetrics{Metrics: bp.lastConsumer.(consumer.Metrics), obsrep: obsrep}
+			case config.LogsDataType:
+				bp.lastConsumer = exitLogs{Logs: bp.lastConsumer.(consumer.Logs), obsrep: obsrep}
+			}
+		}
+
+		if set.Taps != nil {
+			switch pipelineID.Type() {
+			case config.TracesDataType:
+				bp.lastConsumer = tapTraces{Traces: bp.lastConsumer.(consumer.Traces), pipelineID: pipelineID, taps: set.Taps}
+			case config.MetricsDataType:
+				bp.lastConsumer = tapMetrics{Metrics: bp.lastConsumer.(consumer.Metrics), pipelineID: pipelineID, taps: set.Taps}
+			case config.LogsDataType:
+				bp.lastConsumer = tapLogs{Logs: bp.lastConsumer.(consumer.Logs), pipelineID: pipelineID, taps: set.Taps}
+			}
+		}
+
 		mutatesConsumedData := bp.lastConsumer.Capabilities().MutatesData
 		// Build the processors backwards, starting from the last one.
 		// The last processor points to fan out consumer to all Exporters, then the processor itself becomes a
@@ -291,6 +347,28 @@ func Build(ctx context.Context, set Settings) (*Pipelines, error) {
 			return nil, fmt.Errorf("create cap consumer in pipeline %q, data type %q is not supported", pipelineID, pipelineID.Type())
 		}
 
+		if obsrep != nil {
+			switch pipelineID.Type() {
+			case config.TracesDataType:
+				bp.lastConsumer = entryTraces{Traces: bp.lastConsumer.(consumer.Traces), obsrep: obsrep}
+			case config.MetricsDataType:
+				bp.lastConsumer = entryMetrics{Metrics: bp.lastConsumer.(consumer.Metrics), obsrep: obsrep}
+			case config.LogsDataType:
+				bp.lastConsumer = entryLogs{Logs: bp.lastConsumer.(consumer.Logs), obsrep: obsrep}
+			}
+		}
+
+		if set.Pauses != nil {
+			switch pipelineID.Type() {
+			case config.TracesDataType:
+				bp.lastConsumer = pauseTraces{Traces: bp.lastConsumer.(consumer.Traces), pipelineID: pipelineID, pauses: set.Pauses}
+			case config.MetricsDataType:
+				bp.lastConsumer = pauseMetrics{Metrics: bp.lastConsumer.(consumer.Metrics), pipelineID: pipelineID, pauses: set.Pauses}
+			case config.LogsDataType:
+				bp.lastConsumer = pauseLogs{Logs: bp.lastConsumer.(consumer.Logs), pipelineID: pipelineID, pauses: set.Pauses}
+			}
+		}
+
 		// The data type of the pipeline defines what data type each exporter is expected to receive.
 		if _, ok := receiversConsumers[pipelineID.Type()]; !ok {
 			receiversConsumers[pipelineID.Type()] = make(map[config.ComponentID][]baseConsumer)
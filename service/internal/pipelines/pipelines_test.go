@@ -27,9 +27,12 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/experimental/tap"
 	"go.opentelemetry.io/collector/internal/testcomponents"
 	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/obsreport/obsreporttest"
 	"go.opentelemetry.io/collector/service/servicetest"
 )
 
@@ -200,6 +203,126 @@ func TestBuild(t *testing.T) {
 	}
 }
 
+func TestBuildWithTaps(t *testing.T) {
+	factories, err := testcomponents.ExampleComponents()
+	require.NoError(t, err)
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "pipelines_simple.yaml"), factories)
+	require.NoError(t, err)
+
+	tracesID := config.NewComponentID(config.TracesDataType)
+	metricsID := config.NewComponentID(config.MetricsDataType)
+	logsID := config.NewComponentID(config.LogsDataType)
+	set := toSettings(factories, cfg)
+	set.Taps = tap.NewRegistry([]config.ComponentID{tracesID, metricsID, logsID})
+	pipelines, err := Build(context.Background(), set)
+	require.NoError(t, err)
+	require.NoError(t, pipelines.StartAll(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, pipelines.ShutdownAll(context.Background()))
+	}()
+
+	var subs []*tap.Subscription
+	for _, id := range []config.ComponentID{tracesID, metricsID, logsID} {
+		sub, err := set.Taps.Subscribe(id, tap.SubscriptionSettings{BufferSize: 1})
+		require.NoError(t, err)
+		defer sub.Unsubscribe()
+		subs = append(subs, sub)
+	}
+
+	recvID := config.NewComponentID("examplereceiver")
+	assert.NoError(t, pipelines.allReceivers[config.TracesDataType][recvID].(*testcomponents.ExampleReceiver).ConsumeTraces(context.Background(), testdata.GenerateTraces(1)))
+	assert.NoError(t, pipelines.allReceivers[config.MetricsDataType][recvID].(*testcomponents.ExampleReceiver).ConsumeMetrics(context.Background(), testdata.GenerateMetrics(1)))
+	assert.NoError(t, pipelines.allReceivers[config.LogsDataType][recvID].(*testcomponents.ExampleReceiver).ConsumeLogs(context.Background(), testdata.GenerateLogs(1)))
+
+	// The data is both tapped and exported.
+	assert.Equal(t, 1, (<-subs[0].Data()).Traces.SpanCount())
+	assert.Equal(t, 1, (<-subs[1].Data()).Metrics.MetricCount())
+	assert.Equal(t, 1, (<-subs[2].Data()).Logs.LogRecordCount())
+	expID := config.NewComponentID("exampleexporter")
+	assert.Len(t, pipelines.GetExporters()[config.TracesDataType][expID].(*testcomponents.ExampleExporter).Traces, 1)
+	assert.Len(t, pipelines.GetExporters()[config.MetricsDataType][expID].(*testcomponents.ExampleExporter).Metrics, 1)
+	assert.Len(t, pipelines.GetExporters()[config.LogsDataType][expID].(*testcomponents.ExampleExporter).Logs, 1)
+}
+
+func TestBuildWithPipelineMetrics(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+
+	factories, err := testcomponents.ExampleComponents()
+	require.NoError(t, err)
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "pipelines_simple.yaml"), factories)
+	require.NoError(t, err)
+
+	set := toSettings(factories, cfg)
+	set.Telemetry = tt.TelemetrySettings
+	pipelines, err := Build(context.Background(), set)
+	require.NoError(t, err)
+	require.NoError(t, pipelines.StartAll(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, pipelines.ShutdownAll(context.Background()))
+	}()
+
+	recvID := config.NewComponentID("examplereceiver")
+	assert.NoError(t, pipelines.allReceivers[config.TracesDataType][recvID].(*testcomponents.ExampleReceiver).ConsumeTraces(context.Background(), testdata.GenerateTraces(2)))
+	assert.NoError(t, pipelines.allReceivers[config.MetricsDataType][recvID].(*testcomponents.ExampleReceiver).ConsumeMetrics(context.Background(), testdata.GenerateMetrics(2)))
+	assert.NoError(t, pipelines.allReceivers[config.LogsDataType][recvID].(*testcomponents.ExampleReceiver).ConsumeLogs(context.Background(), testdata.GenerateLogs(2)))
+
+	// Every metric generated by testdata.GenerateMetrics has 2 data points.
+	assert.NoError(t, obsreporttest.CheckPipeline(tt, config.NewComponentID(config.TracesDataType), 2, 2, 0, 0))
+	assert.NoError(t, obsreporttest.CheckPipeline(tt, config.NewComponentID(config.MetricsDataType), 4, 4, 0, 0))
+	assert.NoError(t, obsreporttest.CheckPipeline(tt, config.NewComponentID(config.LogsDataType), 2, 2, 0, 0))
+}
+
+func TestBuildWithPauses(t *testing.T) {
+	factories, err := testcomponents.ExampleComponents()
+	require.NoError(t, err)
+	cfg, err := servicetest.LoadConfigAndValidate(filepath.Join("testdata", "pipelines_simple.yaml"), factories)
+	require.NoError(t, err)
+
+	set := toSettings(factories, cfg)
+	set.Pauses = NewPauseRegistry()
+	pipelines, err := Build(context.Background(), set)
+	require.NoError(t, err)
+	require.NoError(t, pipelines.StartAll(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, pipelines.ShutdownAll(context.Background()))
+	}()
+
+	tracesID := config.NewComponentID(config.TracesDataType)
+	metricsID := config.NewComponentID(config.MetricsDataType)
+	logsID := config.NewComponentID(config.LogsDataType)
+	assert.Equal(t, []config.ComponentID{logsID, metricsID, tracesID}, set.Pauses.Pipelines())
+	require.NoError(t, set.Pauses.Pause(tracesID))
+	require.NoError(t, set.Pauses.Pause(metricsID))
+	require.NoError(t, set.Pauses.Pause(logsID))
+
+	recvID := config.NewComponentID("examplereceiver")
+	traces := pipelines.allReceivers[config.TracesDataType][recvID].(*testcomponents.ExampleReceiver)
+	metrics := pipelines.allReceivers[config.MetricsDataType][recvID].(*testcomponents.ExampleReceiver)
+	logs := pipelines.allReceivers[config.LogsDataType][recvID].(*testcomponents.ExampleReceiver)
+	assert.ErrorIs(t, traces.ConsumeTraces(context.Background(), testdata.GenerateTraces(1)), ErrPipelinePaused)
+	assert.ErrorIs(t, metrics.ConsumeMetrics(context.Background(), testdata.GenerateMetrics(1)), ErrPipelinePaused)
+	assert.ErrorIs(t, logs.ConsumeLogs(context.Background(), testdata.GenerateLogs(1)), ErrPipelinePaused)
+	assert.False(t, consumererror.IsPermanent(ErrPipelinePaused))
+
+	expID := config.NewComponentID("exampleexporter")
+	assert.Len(t, pipelines.GetExporters()[config.TracesDataType][expID].(*testcomponents.ExampleExporter).Traces, 0)
+	assert.Len(t, pipelines.GetExporters()[config.MetricsDataType][expID].(*testcomponents.ExampleExporter).Metrics, 0)
+	assert.Len(t, pipelines.GetExporters()[config.LogsDataType][expID].(*testcomponents.ExampleExporter).Logs, 0)
+
+	// The data flows again once the pipelines are resumed.
+	require.NoError(t, set.Pauses.Resume(tracesID))
+	require.NoError(t, set.Pauses.Resume(metricsID))
+	require.NoError(t, set.Pauses.Resume(logsID))
+	assert.NoError(t, traces.ConsumeTraces(context.Background(), testdata.GenerateTraces(1)))
+	assert.NoError(t, metrics.ConsumeMetrics(context.Background(), testdata.GenerateMetrics(1)))
+	assert.NoError(t, logs.ConsumeLogs(context.Background(), testdata.GenerateLogs(1)))
+	assert.Len(t, pipelines.GetExporters()[config.TracesDataType][expID].(*testcomponents.ExampleExporter).Traces, 1)
+	assert.Len(t, pipelines.GetExporters()[config.MetricsDataType][expID].(*testcomponents.ExampleExporter).Metrics, 1)
+	assert.Len(t, pipelines.GetExporters()[config.LogsDataType][expID].(*testcomponents.ExampleExporter).Logs, 1)
+}
+
 func TestBuildErrors(t *testing.T) {
 	nopReceiverFactory := componenttest.NewNopReceiverFactory()
 	nopProcessorFactory := componenttest.NewNopProcessorFactory()
@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipelines // import "go.opentelemetry.io/collector/service/internal/pipelines"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/experimental/tap"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// The tap consumers publish the data to the subscriptions of the tap point of the pipeline, if any,
+// before passing it to the next consumer.
+
+type tapLogs struct {
+	consumer.Logs
+	pipelineID config.ComponentID
+	taps       *tap.Registry
+}
+
+func (tl tapLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	if tl.taps.Active(tl.pipelineID) {
+		tl.taps.PublishLogs(tl.pipelineID, ld)
+	}
+	return tl.Logs.ConsumeLogs(ctx, ld)
+}
+
+type tapMetrics struct {
+	consumer.Metrics
+	pipelineID config.ComponentID
+	taps       *tap.Registry
+}
+
+func (tm tapMetrics) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	if tm.taps.Active(tm.pipelineID) {
+		tm.taps.PublishMetrics(tm.pipelineID, md)
+	}
+	return tm.Metrics.ConsumeMetrics(ctx, md)
+}
+
+type tapTraces struct {
+	consumer.Traces
+	pipelineID config.ComponentID
+	taps       *tap.Registry
+}
+
+func (tt tapTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	if tt.taps.Active(tt.pipelineID) {
+		tt.taps.PublishTraces(tt.pipelineID, td)
+	}
+	return tt.Traces.ConsumeTraces(ctx, td)
+}
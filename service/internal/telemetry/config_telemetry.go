@@ -0,0 +1,211 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry // import "go.opentelemetry.io/collector/service/internal/telemetry"
+
+import (
+	"time"
+
+	"go.opencensus.io/metric"
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/stats"
+	"go.uber.org/atomic"
+)
+
+// Reasons of a failed configuration reload, reported as the "reason" label of the
+// "config_reload_failures" metric.
+const (
+	// ReloadFailureConfig is reported when the updated configuration cannot be retrieved or is invalid.
+	ReloadFailureConfig = "config"
+	// ReloadFailureShutdown is reported when the components of the retiring configuration fail to shut down.
+	ReloadFailureShutdown = "shutdown"
+	// ReloadFailureStart is reported when the components fail to start with the updated configuration.
+	ReloadFailureStart = "start"
+)
+
+var reloadFailureReasons = []string{ReloadFailureConfig, ReloadFailureShutdown, ReloadFailureStart}
+
+// ReloadCounters counts the outcomes of the configuration reloads. It is safe for concurrent use,
+// and outlives the service instances that report it, so the counts survive the reloads.
+type ReloadCounters struct {
+	attempts     *atomic.Int64
+	successes    *atomic.Int64
+	failures     map[string]*atomic.Int64
+	lastDuration *atomic.Duration
+	flapping     *atomic.Bool
+}
+
+// NewReloadCounters returns ReloadCounters with all the counts set to zero.
+func NewReloadCounters() *ReloadCounters {
+	rc := &ReloadCounters{
+		attempts:     atomic.NewInt64(0),
+		successes:    atomic.NewInt64(0),
+		failures:     make(map[string]*atomic.Int64, len(reloadFailureReasons)),
+		lastDuration: atomic.NewDuration(0),
+		flapping:     atomic.NewBool(false),
+	}
+	for _, reason := range reloadFailureReasons {
+		rc.failures[reason] = atomic.NewInt64(0)
+	}
+	return rc
+}
+
+// RecordSuccess records a reload that applied the updated configuration in d.
+func (rc *ReloadCounters) RecordSuccess(d time.Duration) {
+	rc.attempts.Inc()
+	rc.successes.Inc()
+	rc.lastDuration.Store(d)
+}
+
+// RecordFailure records a reload that failed for the given reason after d.
+// Unknown reasons are reported as ReloadFailureConfig.
+func (rc *ReloadCounters) RecordFailure(reason string, d time.Duration) {
+	failures, ok := rc.failures[reason]
+	if !ok {
+		failures = rc.failures[ReloadFailureConfig]
+	}
+	rc.attempts.Inc()
+	failures.Inc()
+	rc.lastDuration.Store(d)
+}
+
+// Attempts returns the number of reloads attempted.
+func (rc *ReloadCounters) Attempts() int64 {
+	return rc.attempts.Load()
+}
+
+// Successes returns the number of reloads that applied the updated configuration.
+func (rc *ReloadCounters) Successes() int64 {
+	return rc.successes.Load()
+}
+
+// Failures returns the number of reloads that failed, for all the reasons.
+func (rc *ReloadCounters) Failures() int64 {
+	var total int64
+	for _, failures := range rc.failures {
+		total += failures.Load()
+	}
+	return total
+}
+
+// FailuresFor returns the number of reloads that failed for the given reason.
+func (rc *ReloadCounters) FailuresFor(reason string) int64 {
+	if failures, ok := rc.failures[reason]; ok {
+		return failures.Load()
+	}
+	return 0
+}
+
+// LastDuration returns the duration of the last reload, zero if no reload was attempted.
+func (rc *ReloadCounters) LastDuration() time.Duration {
+	return rc.lastDuration.Load()
+}
+
+// SetFlapping sets whether the configuration reloads are held because the configuration is flapping.
+func (rc *ReloadCounters) SetFlapping(flapping bool) {
+	rc.flapping.Store(flapping)
+}
+
+// Flapping returns whether the configuration reloads are held because the configuration is flapping.
+func (rc *ReloadCounters) Flapping() bool {
+	return rc.flapping.Load()
+}
+
+// RegisterConfigMetrics registers the "config_info" gauge, always reporting 1 with the hash of the
+// effective configuration as the "config_hash" label, so fleet tooling can verify rollout convergence.
+// If reloads is not nil, it also registers the metrics reporting the configuration reloads:
+//   - "config_reload_attempts", the number of reloads attempted;
+//   - "config_reload_successes", the number of reloads that applied the updated configuration;
+//   - "config_reload_failures", the number of failed reloads, by "reason";
+//   - "config_reload_duration", the time taken by the last reload to apply or roll back, in seconds;
+//   - "config_reload_flapping", 1 while the reloads are held because the configuration is flapping, else 0.
+//
+// Calling it again, e.g. after a config reload, replaces the previously registered metrics.
+func RegisterConfigMetrics(registry *metric.Registry, configHash string, reloads *ReloadCounters) error {
+	configInfo, err := registry.AddInt64DerivedGauge(
+		"config_info",
+		metric.WithDescription("Information about the effective configuration, the value is always 1"),
+		metric.WithLabelKeys("config_hash"),
+		metric.WithUnit(stats.UnitDimensionless))
+	if err != nil {
+		return err
+	}
+	if err = configInfo.UpsertEntry(func() int64 { return 1 }, metricdata.NewLabelValue(configHash)); err != nil {
+		return err
+	}
+	if reloads == nil {
+		return nil
+	}
+
+	attempts, err := registry.AddInt64DerivedCumulative(
+		"config_reload_attempts",
+		metric.WithDescription("Number of configuration reloads attempted"),
+		metric.WithUnit(stats.UnitDimensionless))
+	if err != nil {
+		return err
+	}
+	if err = attempts.UpsertEntry(reloads.Attempts); err != nil {
+		return err
+	}
+
+	successes, err := registry.AddInt64DerivedCumulative(
+		"config_reload_successes",
+		metric.WithDescription("Number of configuration reloads that applied the updated configuration"),
+		metric.WithUnit(stats.UnitDimensionless))
+	if err != nil {
+		return err
+	}
+	if err = successes.UpsertEntry(reloads.Successes); err != nil {
+		return err
+	}
+
+	failures, err := registry.AddInt64DerivedCumulative(
+		"config_reload_failures",
+		metric.WithDescription("Number of configuration reloads that failed and kept or restored the previous configuration"),
+		metric.WithLabelKeys("reason"),
+		metric.WithUnit(stats.UnitDimensionless))
+	if err != nil {
+		return err
+	}
+	for _, reason := range reloadFailureReasons {
+		if err = failures.UpsertEntry(reloads.failures[reason].Load, metricdata.NewLabelValue(reason)); err != nil {
+			return err
+		}
+	}
+
+	duration, err := registry.AddFloat64DerivedGauge(
+		"config_reload_duration",
+		metric.WithDescription("Time taken by the last configuration reload to apply or roll back"),
+		metric.WithUnit(stats.UnitSeconds))
+	if err != nil {
+		return err
+	}
+	if err = duration.UpsertEntry(func() float64 { return reloads.LastDuration().Seconds() }); err != nil {
+		return err
+	}
+
+	flapping, err := registry.AddInt64DerivedGauge(
+		"config_reload_flapping",
+		metric.WithDescription("Whether the configuration reloads are held because the configuration is flapping"),
+		metric.WithUnit(stats.UnitDimensionless))
+	if err != nil {
+		return err
+	}
+	return flapping.UpsertEntry(func() int64 {
+		if reloads.Flapping() {
+			return 1
+		}
+		return 0
+	})
+}
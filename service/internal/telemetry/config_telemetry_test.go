@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/metric"
+	"go.opencensus.io/metric/metricdata"
+)
+
+func TestConfigTelemetry(t *testing.T) {
+	registry := metric.NewRegistry()
+	require.NoError(t, RegisterConfigMetrics(registry, "abc", nil))
+	assertConfigInfo(t, registry, "abc")
+	assert.Nil(t, findMetric(registry.Read(), "config_reload_failures"))
+
+	// Registering again replaces the previous hash.
+	reloads := NewReloadCounters()
+	require.NoError(t, RegisterConfigMetrics(registry, "def", reloads))
+	assertConfigInfo(t, registry, "def")
+
+	reloads.RecordFailure(ReloadFailureConfig, time.Second)
+	reloads.RecordFailure(ReloadFailureStart, time.Second)
+	reloads.RecordFailure(ReloadFailureStart, time.Second)
+	reloads.RecordSuccess(1500 * time.Millisecond)
+
+	assertSinglePoint(t, registry, "config_reload_attempts", int64(4))
+	assertSinglePoint(t, registry, "config_reload_successes", int64(1))
+	assertSinglePoint(t, registry, "config_reload_duration", 1.5)
+	assertSinglePoint(t, registry, "config_reload_flapping", int64(0))
+	reloads.SetFlapping(true)
+	assertSinglePoint(t, registry, "config_reload_flapping", int64(1))
+
+	m := findMetric(registry.Read(), "config_reload_failures")
+	require.NotNil(t, m)
+	failures := map[string]interface{}{}
+	for _, ts := range m.TimeSeries {
+		require.Len(t, ts.LabelValues, 1)
+		require.Len(t, ts.Points, 1)
+		failures[ts.LabelValues[0].Value] = ts.Points[0].Value
+	}
+	assert.Equal(t, map[string]interface{}{
+		ReloadFailureConfig:   int64(1),
+		ReloadFailureShutdown: int64(0),
+		ReloadFailureStart:    int64(2),
+	}, failures)
+}
+
+func TestReloadCounters(t *testing.T) {
+	reloads := NewReloadCounters()
+	assert.Equal(t, time.Duration(0), reloads.LastDuration())
+
+	reloads.RecordSuccess(time.Second)
+	reloads.RecordFailure(ReloadFailureShutdown, 2*time.Second)
+	// Unknown reasons are counted as configuration failures.
+	reloads.RecordFailure("unknown", 3*time.Second)
+
+	assert.Equal(t, int64(3), reloads.Attempts())
+	assert.Equal(t, int64(1), reloads.Successes())
+	assert.Equal(t, int64(2), reloads.Failures())
+	assert.Equal(t, int64(1), reloads.FailuresFor(ReloadFailureShutdown))
+	assert.Equal(t, int64(1), reloads.FailuresFor(ReloadFailureConfig))
+	assert.Equal(t, int64(0), reloads.FailuresFor("unknown"))
+	assert.Equal(t, 3*time.Second, reloads.LastDuration())
+}
+
+func assertSinglePoint(t *testing.T, registry *metric.Registry, name string, value interface{}) {
+	m := findMetric(registry.Read(), name)
+	require.NotNil(t, m, name)
+	require.Len(t, m.TimeSeries, 1)
+	require.Len(t, m.TimeSeries[0].Points, 1)
+	assert.Equal(t, value, m.TimeSeries[0].Points[0].Value)
+}
+
+func assertConfigInfo(t *testing.T, registry *metric.Registry, hash string) {
+	m := findMetric(registry.Read(), "config_info")
+	require.NotNil(t, m)
+	require.Len(t, m.TimeSeries, 1)
+	ts := m.TimeSeries[0]
+	assert.Equal(t, []metricdata.LabelValue{metricdata.NewLabelValue(hash)}, ts.LabelValues)
+	require.Len(t, ts.Points, 1)
+	assert.Equal(t, int64(1), ts.Points[0].Value)
+}
@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// newPrintConfigCommand constructs the command that prints the redacted effective configuration,
+// optionally annotated with the source of every value.
+func newPrintConfigCommand(set CollectorSettings) *cobra.Command {
+	flagSet := flags()
+	withSources := false
+	format := "yaml"
+	cmd := &cobra.Command{
+		Use:   "print-config",
+		Short: "Prints the effective configuration",
+		Long: "Resolves and validates the configuration as the collector does, then prints the effective configuration " +
+			"as YAML or JSON, with the values that may be secrets redacted: the values of the keys whose names suggest " +
+			"secrets, the configopaque.String fields of the components, and the values retrieved from config " +
+			"providers returning secrets, such as env. With --with-sources, every YAML value is " +
+			"annotated with the configuration source that set it, and the converter that last changed it if any, e.g. " +
+			"for --set flags.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case format != "yaml" && format != "json":
+				return fmt.Errorf("unsupported format %q, must be yaml or json", format)
+			case format == "json" && withSources:
+				return errors.New("--with-sources is only supported by the yaml format")
+			}
+			cfgProvider := set.ConfigProvider
+			if cfgProvider == nil {
+				cfgSet, err := newConfigProviderSettingsFromFlags(set, flagSet)
+				if err != nil {
+					return err
+				}
+				// The actual config sources are printed, never the last known good configuration.
+				cfgSet.LastKnownGood = LastKnownGoodSettings{}
+				if cfgProvider, err = NewConfigProvider(cfgSet); err != nil {
+					return err
+				}
+				defer func() {
+					_ = cfgProvider.Shutdown(cmd.Context())
+				}()
+			}
+			ep, ok := cfgProvider.(effectiveConfigProvider)
+			if !ok {
+				return errors.New("the config provider does not support printing the effective configuration")
+			}
+			cfg, err := cfgProvider.Get(cmd.Context(), set.Factories)
+			if err != nil {
+				return err
+			}
+			effective := ep.effectiveConfig()
+			if format == "json" {
+				return writeJSON(cmd.OutOrStdout(), redactConf(effective.ToStringMap(), redactedKeys(cfg, effective)))
+			}
+			return printConfig(cmd.OutOrStdout(), effective, redactedKeys(cfg, effective), withSources)
+		},
+	}
+	cmd.Flags().AddGoFlagSet(flagSet)
+	cmd.Flags().BoolVar(&withSources, "with-sources", false, "Annotate every value with its source.")
+	cmd.Flags().StringVar(&format, "format", format, "Format of the configuration, yaml or json.")
+	return cmd
+}
+
+// printConfig writes the configuration as YAML, redacting the opaque keys and the keys that may hold
+// secrets, with the sources of the values as comments if withSources is true.
+func printConfig(w io.Writer, conf *confmap.Conf, opaque map[string]bool, withSources bool) error {
+	node := &yaml.Node{}
+	if err := node.Encode(redactConf(conf.ToStringMap(), opaque)); err != nil {
+		return err
+	}
+	if withSources {
+		annotateSources(node, "", conf)
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// annotateSources sets the sources of the values under the mapping node as line comments.
+// The comments of scalar values are set on the values, the others on their keys, so that
+// they are rendered on the line of the key.
+func annotateSources(node *yaml.Node, prefix string, conf *confmap.Conf) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := prefix + keyNode.Value
+		if src, ok := conf.Source(key); ok {
+			if valueNode.Kind == yaml.ScalarNode {
+				valueNode.LineComment = src.String()
+			} else {
+				keyNode.LineComment = src.String()
+			}
+		}
+		annotateSources(valueNode, key+confmap.KeyDelimiter, conf)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/service/featuregate"
+)
+
+func TestPrintConfigCommand(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	uri := filepath.Join("testdata", "otelcol-nop.yaml")
+	overlay := "yaml:service: {telemetry: {logs: {level: warn, development: true}}}"
+	args := []string{"print-config",
+		"--config", uri,
+		"--config", overlay,
+		"--set", "service.telemetry.logs.level=debug",
+		"--set", "service.telemetry.metrics.level=none"}
+
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs(append(args, "--with-sources"))
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "receivers:\n  nop: null # "+uri+"\n")
+	assert.Contains(t, out.String(), "      exporters: # "+uri+"\n        - nop\n")
+	assert.Contains(t, out.String(), `  telemetry:
+    logs:
+      development: true # `+overlay+`
+      level: debug # `+overlay+` via overwritepropertiesconverter
+    metrics:
+      level: none # converter overwritepropertiesconverter
+`)
+
+	cmd = NewCommand(CollectorSettings{Factories: factories})
+	out.Reset()
+	cmd.SetOut(out)
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.Execute())
+	assert.NotContains(t, out.String(), "#")
+	assert.Contains(t, out.String(), "    logs:\n      development: true\n      level: debug\n")
+}
+
+func TestPrintConfigCommandJSON(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	args := []string{"print-config", "--config", filepath.Join("testdata", "otelcol-nop.yaml"), "--format", "json"}
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.Execute())
+	conf := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &conf))
+	assert.Contains(t, conf, "receivers")
+	assert.Contains(t, conf, "service")
+
+	cmd = NewCommand(CollectorSettings{Factories: factories})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(append(args, "--with-sources"))
+	assert.EqualError(t, cmd.Execute(), "--with-sources is only supported by the yaml format")
+
+	cmd = NewCommand(CollectorSettings{Factories: factories})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"print-config", "--config", filepath.Join("testdata", "otelcol-nop.yaml"), "--format", "toml"})
+	assert.EqualError(t, cmd.Execute(), `unsupported format "toml", must be yaml or json`)
+}
+
+func TestPrintConfigCommandSecretProvider(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	featuregate.GetRegistry().MustApply(map[string]bool{expandEnabledGateID: true})
+	defer featuregate.GetRegistry().MustApply(map[string]bool{expandEnabledGateID: false})
+	t.Setenv("PRINT_CONFIG_LOG_LEVEL", "warn")
+
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"print-config",
+		"--config", filepath.Join("testdata", "otelcol-nop.yaml"),
+		"--config", "yaml:service: {telemetry: {logs: {level: '${env:PRINT_CONFIG_LOG_LEVEL}', encoding: json}}}"})
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "level: '[REDACTED]'")
+	assert.Contains(t, out.String(), "encoding: json")
+	assert.NotContains(t, out.String(), "warn")
+}
+
+func TestPrintConfigRedacted(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]interface{}{
+		"exporters": map[string]interface{}{
+			"otlp": map[string]interface{}{
+				"endpoint": "backend:4317",
+				"headers":  map[string]interface{}{"authorization": "Bearer secret"},
+			},
+		},
+	})
+	out := &bytes.Buffer{}
+	require.NoError(t, printConfig(out, conf, nil, true))
+	assert.Equal(t, `exporters:
+  otlp:
+    endpoint: backend:4317
+    headers:
+      authorization: '[REDACTED]'
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, printConfig(out, conf, map[string]bool{"exporters::otlp::endpoint": true}, false))
+	assert.Equal(t, `exporters:
+  otlp:
+    endpoint: '[REDACTED]'
+    headers:
+      authorization: '[REDACTED]'
+`, out.String())
+}
+
+func TestPrintConfigCommandError(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"print-config", "--config", filepath.Join("testdata", "otelcol-invalid.yaml")})
+	assert.Error(t, cmd.Execute())
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"time"
+)
+
+const (
+	// defaultReloadFlapWindow is the ReloadSettings.FlapWindow used when only the FlapThreshold is set.
+	defaultReloadFlapWindow = 5 * time.Minute
+	// defaultReloadRetryInterval is the ReloadSettings.RetryInterval used if not set.
+	defaultReloadRetryInterval = 30 * time.Second
+)
+
+// ReloadSettings configures how the Collector paces the configuration reloads triggered by the
+// ConfigProvider, to protect the pipelines from a config source publishing rapid successive revisions.
+// The zero value applies every change as soon as it is notified.
+type ReloadSettings struct {
+	// MinInterval is the minimum interval between two reloads. A change notified earlier is applied
+	// once the interval has elapsed, together with any other change notified in the meantime.
+	MinInterval time.Duration
+
+	// FlapThreshold is the number of reloads within FlapWindow after which the configuration is
+	// considered flapping. The current configuration is then held, and the changes notified are applied
+	// only once FlapWindow has elapsed since the last reload. Zero disables the flap detection.
+	FlapThreshold int
+
+	// FlapWindow is the window in which the reloads are counted for the flap detection.
+	// Defaults to 5m if FlapThreshold is set.
+	FlapWindow time.Duration
+
+	// RetryInterval is the delay before retrying a reload whose configuration cannot be retrieved
+	// because of a transient error, see confmap.IsRetryable. Defaults to 30s.
+	RetryInterval time.Duration
+}
+
+// reloadLimiter decides when the configuration changes are applied according to the ReloadSettings.
+type reloadLimiter struct {
+	set ReloadSettings
+	// reloads are the times of the reloads within the flap window, or the last one if none.
+	reloads []time.Time
+}
+
+func newReloadLimiter(set ReloadSettings) *reloadLimiter {
+	if set.FlapThreshold > 0 && set.FlapWindow <= 0 {
+		set.FlapWindow = defaultReloadFlapWindow
+	}
+	if set.RetryInterval <= 0 {
+		set.RetryInterval = defaultReloadRetryInterval
+	}
+	return &reloadLimiter{set: set}
+}
+
+// delay returns how long a change notified at now has to wait before being applied, and whether
+// it is held because the configuration is flapping.
+func (l *reloadLimiter) delay(now time.Time) (time.Duration, bool) {
+	if len(l.reloads) == 0 {
+		return 0, false
+	}
+	last := l.reloads[len(l.reloads)-1]
+	if l.set.FlapThreshold > 0 && l.countSince(now.Add(-l.set.FlapWindow)) >= l.set.FlapThreshold {
+		return last.Add(l.set.FlapWindow).Sub(now), true
+	}
+	if d := last.Add(l.set.MinInterval).Sub(now); d > 0 {
+		return d, false
+	}
+	return 0, false
+}
+
+// record records a reload at now.
+func (l *reloadLimiter) record(now time.Time) {
+	l.reloads = append(l.reloads, now)
+	// Drop the reloads out of the flap window, keeping the last one for the minimum interval.
+	start := now.Add(-l.set.FlapWindow)
+	i := 0
+	for i < len(l.reloads)-1 && !l.reloads[i].After(start) {
+		i++
+	}
+	l.reloads = l.reloads[i:]
+}
+
+// countSince returns the number of reloads after start.
+func (l *reloadLimiter) countSince(start time.Time) int {
+	n := 0
+	for _, t := range l.reloads {
+		if t.After(start) {
+			n++
+		}
+	}
+	return n
+}
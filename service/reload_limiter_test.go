@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadLimiterDisabled(t *testing.T) {
+	l := newReloadLimiter(ReloadSettings{})
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		delay, held := l.delay(now)
+		assert.Zero(t, delay)
+		assert.False(t, held)
+		l.record(now)
+	}
+	assert.Len(t, l.reloads, 1)
+}
+
+func TestReloadLimiterMinInterval(t *testing.T) {
+	l := newReloadLimiter(ReloadSettings{MinInterval: 10 * time.Second})
+	now := time.Now()
+
+	// The first reload is never delayed.
+	delay, held := l.delay(now)
+	assert.Zero(t, delay)
+	assert.False(t, held)
+	l.record(now)
+
+	delay, held = l.delay(now.Add(4 * time.Second))
+	assert.Equal(t, 6*time.Second, delay)
+	assert.False(t, held)
+
+	delay, _ = l.delay(now.Add(10 * time.Second))
+	assert.Zero(t, delay)
+}
+
+func TestReloadLimiterFlapping(t *testing.T) {
+	l := newReloadLimiter(ReloadSettings{FlapThreshold: 3, FlapWindow: time.Minute})
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		at := now.Add(time.Duration(i) * 10 * time.Second)
+		delay, held := l.delay(at)
+		assert.Zero(t, delay)
+		assert.False(t, held)
+		l.record(at)
+	}
+
+	// Held until the window has elapsed since the last reload.
+	delay, held := l.delay(now.Add(25 * time.Second))
+	assert.Equal(t, 55*time.Second, delay)
+	assert.True(t, held)
+
+	// The held reload is recorded once applied, older reloads leave the window.
+	l.record(now.Add(80 * time.Second))
+	assert.Len(t, l.reloads, 1)
+	delay, held = l.delay(now.Add(81 * time.Second))
+	assert.Zero(t, delay)
+	assert.False(t, held)
+}
+
+func TestReloadLimiterDefaultFlapWindow(t *testing.T) {
+	l := newReloadLimiter(ReloadSettings{FlapThreshold: 1})
+	assert.Equal(t, defaultReloadFlapWindow, l.set.FlapWindow)
+	assert.Zero(t, newReloadLimiter(ReloadSettings{}).set.FlapWindow)
+}
@@ -24,7 +24,9 @@ import (
 	"go.uber.org/zap"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/extension/experimental/tap"
 	"go.opentelemetry.io/collector/service/extensions"
 	"go.opentelemetry.io/collector/service/internal"
 	"go.opentelemetry.io/collector/service/internal/pipelines"
@@ -58,6 +60,10 @@ func newService(set *settings) (*service, error) {
 			factories:         set.Factories,
 			buildInfo:         set.BuildInfo,
 			asyncErrorChannel: set.AsyncErrorChannel,
+			configHash:        set.ConfigHash,
+			configSources:     set.ConfigSources,
+			effectiveConfig:   set.EffectiveConfig,
+			taps:              tap.NewRegistry(pipelineIDs(set.Config.Service.Pipelines)),
 		},
 		telemetryInitializer: set.telemetry,
 	}
@@ -77,6 +83,7 @@ func newService(set *settings) (*service, error) {
 		BuildInfo: srv.buildInfo,
 		Configs:   srv.config.Extensions,
 		Factories: srv.host.factories.Extensions,
+		Timeouts:  srv.config.Service.Timeouts,
 	}
 	if srv.host.extensions, err = extensions.New(context.Background(), extensionsSettings, srv.config.Service.Extensions); err != nil {
 		return nil, fmt.Errorf("failed build extensions: %w", err)
@@ -92,6 +99,9 @@ func newService(set *settings) (*service, error) {
 		ExporterFactories:  srv.host.factories.Exporters,
 		ExporterConfigs:    srv.config.Exporters,
 		PipelineConfigs:    srv.config.Service.Pipelines,
+		Timeouts:           srv.config.Service.Timeouts,
+		Taps:               srv.host.taps,
+		Pauses:             set.pauses,
 	}
 	if srv.host.pipelines, err = pipelines.Build(context.Background(), pipelinesSettings); err != nil {
 		return nil, fmt.Errorf("cannot build pipelines: %w", err)
@@ -102,11 +112,22 @@ func newService(set *settings) (*service, error) {
 		if err = telemetry.RegisterProcessMetrics(srv.telemetryInitializer.ocRegistry, getBallastSize(srv.host)); err != nil {
 			return nil, fmt.Errorf("failed to register process metrics: %w", err)
 		}
+		if err = telemetry.RegisterConfigMetrics(srv.telemetryInitializer.ocRegistry, set.ConfigHash, set.reloads); err != nil {
+			return nil, fmt.Errorf("failed to register config metrics: %w", err)
+		}
 	}
 
 	return srv, nil
 }
 
+func pipelineIDs(pipelines map[config.ComponentID]*config.Pipeline) []config.ComponentID {
+	ids := make([]config.ComponentID, 0, len(pipelines))
+	for id := range pipelines {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 func (srv *service) Start(ctx context.Context) error {
 	if err := srv.host.extensions.Start(ctx, srv.host); err != nil {
 		return fmt.Errorf("failed to start extensions: %w", err)
@@ -16,6 +16,9 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"testing"
 
@@ -92,6 +95,43 @@ func TestServiceGetExporters(t *testing.T) {
 	assert.Contains(t, expMap[config.LogsDataType], config.NewComponentID("nop"))
 }
 
+func TestServiceConfigHash(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	srv := createExampleService(t, factories)
+	assert.Empty(t, srv.host.ConfigHash())
+
+	srv.host.configHash = "abc"
+	assert.Equal(t, "abc", srv.host.ConfigHash())
+}
+
+func TestServiceConfigzPage(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	srv := createExampleService(t, factories)
+	srv.host.configHash = "abc"
+	srv.host.configSources = []string{"file:config.yaml"}
+	srv.host.effectiveConfig = redactConf(map[string]interface{}{
+		"exporters": map[string]interface{}{"otlp": map[string]interface{}{"endpoint": "localhost:4317", "token": "secret"}},
+	}, nil)
+
+	rr := httptest.NewRecorder()
+	srv.host.handleConfigzRequest(rr, httptest.NewRequest(http.MethodGet, "/debug/configz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.NotContains(t, rr.Body.String(), "secret")
+
+	var resp configzResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, configzResponse{
+		ConfigHash: "abc",
+		Sources:    []string{"file:config.yaml"},
+		Config: map[string]interface{}{
+			"exporters": map[string]interface{}{"otlp": map[string]interface{}{"endpoint": "localhost:4317", "token": redactedValue}},
+		},
+	}, resp)
+}
+
 func createExampleService(t *testing.T, factories component.Factories) *service {
 	// Read yaml config from file
 	conf, err := confmaptest.LoadConf(filepath.Join("testdata", "otelcol-nop.yaml"))
@@ -18,6 +18,9 @@ import (
 	"go.uber.org/zap"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/service/internal/pipelines"
+	"go.opentelemetry.io/collector/service/internal/telemetry"
 )
 
 // settings holds configuration for building a new service.
@@ -31,6 +34,15 @@ type settings struct {
 	// Config represents the configuration of the service.
 	Config *Config
 
+	// ConfigHash is the hash of the effective configuration, empty if not available.
+	ConfigHash string
+
+	// ConfigSources are the URIs the configuration was retrieved from, nil if not available.
+	ConfigSources []string
+
+	// EffectiveConfig is the redacted effective configuration, nil if not available.
+	EffectiveConfig map[string]interface{}
+
 	// AsyncErrorChannel is the channel that is used to report fatal errors.
 	AsyncErrorChannel chan error
 
@@ -39,6 +51,12 @@ type settings struct {
 
 	// For testing purpose only.
 	telemetry *telemetryInitializer
+
+	// reloads counts the outcomes of the configuration reloads of the Collector, nil if not available.
+	reloads *telemetry.ReloadCounters
+
+	// pauses holds the paused pipelines of the Collector, nil if pausing the pipelines is not available.
+	pauses *pipelines.PauseRegistry
 }
 
 // CollectorSettings holds configuration for creating a new Collector.
@@ -59,6 +77,32 @@ type CollectorSettings struct {
 	// If the provider watches for configuration change, collector may reload the new configuration upon changes.
 	ConfigProvider ConfigProvider
 
+	// ConfigProviders are registered, in addition to the default "file", "env" and "yaml" ones, in the
+	// ConfigProvider created by NewCommand when ConfigProvider is not set. A provider with the same
+	// scheme as a default one replaces it.
+	ConfigProviders []confmap.Provider
+
+	// ConfigConverters are applied, after the default ones, by the ConfigProvider created by NewCommand
+	// when ConfigProvider is not set.
+	ConfigConverters []confmap.Converter
+
+	// Reload configures how the configuration changes notified by the ConfigProvider are paced.
+	// NewCommand sets it from the command line flags, unless it is set.
+	Reload ReloadSettings
+
+	// Admin configures the admin API of the Collector, disabled unless the endpoint is set.
+	// NewCommand sets it from the command line flags and environment, unless it is set.
+	Admin AdminSettings
+
+	// ConfigStatus configures reporting the outcome of every configuration load, disabled unless
+	// the URL is set. NewCommand sets it from the command line flags and environment, unless it is set.
+	ConfigStatus ConfigStatusSettings
+
+	// StateDumpFile is the path the runtime state is written to on SIGUSR2, as a JSON document.
+	// If empty, the runtime state is logged instead. NewCommand sets it from the command line flags,
+	// unless it is set.
+	StateDumpFile string
+
 	// LoggingOptions provides a way to change behavior of zap logging.
 	LoggingOptions []zap.Option
 
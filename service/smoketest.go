@@ -0,0 +1,484 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/service/internal/pipelines"
+)
+
+// smokeTestData is the telemetry replayed through the pipelines, or received by an exporter.
+type smokeTestData struct {
+	traces  ptrace.Traces
+	metrics pmetric.Metrics
+	logs    plog.Logs
+}
+
+func newSmokeTestData() *smokeTestData {
+	return &smokeTestData{traces: ptrace.NewTraces(), metrics: pmetric.NewMetrics(), logs: plog.NewLogs()}
+}
+
+// count returns the number of spans, data points or log records of the data type, with their unit.
+func (d *smokeTestData) count(dt config.DataType) (int, string) {
+	switch dt {
+	case config.TracesDataType:
+		return d.traces.SpanCount(), "spans"
+	case config.MetricsDataType:
+		return d.metrics.DataPointCount(), "data points"
+	}
+	return d.logs.LogRecordCount(), "log records"
+}
+
+// marshal returns the data of the data type as OTLP JSON.
+func (d *smokeTestData) marshal(dt config.DataType) ([]byte, error) {
+	switch dt {
+	case config.TracesDataType:
+		return ptrace.NewJSONMarshaler().MarshalTraces(d.traces)
+	case config.MetricsDataType:
+		return pmetric.NewJSONMarshaler().MarshalMetrics(d.metrics)
+	}
+	return plog.NewJSONMarshaler().MarshalLogs(d.logs)
+}
+
+// load appends the telemetry of the data type read from the OTLP file at path, encoded as JSON
+// if the file has the .json extension, as protobuf otherwise.
+func (d *smokeTestData) load(dt config.DataType, path string) error {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	json := strings.EqualFold(filepath.Ext(path), ".json")
+	switch dt {
+	case config.TracesDataType:
+		unmarshaler := ptrace.NewProtoUnmarshaler()
+		if json {
+			unmarshaler = ptrace.NewJSONUnmarshaler()
+		}
+		td, err := unmarshaler.UnmarshalTraces(content)
+		if err != nil {
+			return fmt.Errorf("cannot read traces from %q: %w", path, err)
+		}
+		td.ResourceSpans().MoveAndAppendTo(d.traces.ResourceSpans())
+	case config.MetricsDataType:
+		unmarshaler := pmetric.NewProtoUnmarshaler()
+		if json {
+			unmarshaler = pmetric.NewJSONUnmarshaler()
+		}
+		md, err := unmarshaler.UnmarshalMetrics(content)
+		if err != nil {
+			return fmt.Errorf("cannot read metrics from %q: %w", path, err)
+		}
+		md.ResourceMetrics().MoveAndAppendTo(d.metrics.ResourceMetrics())
+	default:
+		unmarshaler := plog.NewProtoUnmarshaler()
+		if json {
+			unmarshaler = plog.NewJSONUnmarshaler()
+		}
+		ld, err := unmarshaler.UnmarshalLogs(content)
+		if err != nil {
+			return fmt.Errorf("cannot read logs from %q: %w", path, err)
+		}
+		ld.ResourceLogs().MoveAndAppendTo(d.logs.ResourceLogs())
+	}
+	return nil
+}
+
+// smokeTestSink is the exporter replacing every exporter of the pipelines, keeping the telemetry it receives.
+type smokeTestSink struct {
+	component.StartFunc
+	component.ShutdownFunc
+
+	mu   sync.Mutex
+	data *smokeTestData
+}
+
+func (s *smokeTestSink) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (s *smokeTestSink) ConsumeTraces(_ context.Context, td ptrace.Traces) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	td.Clone().ResourceSpans().MoveAndAppendTo(s.data.traces.ResourceSpans())
+	return nil
+}
+
+func (s *smokeTestSink) ConsumeMetrics(_ context.Context, md pmetric.Metrics) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	md.Clone().ResourceMetrics().MoveAndAppendTo(s.data.metrics.ResourceMetrics())
+	return nil
+}
+
+func (s *smokeTestSink) ConsumeLogs(_ context.Context, ld plog.Logs) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ld.Clone().ResourceLogs().MoveAndAppendTo(s.data.logs.ResourceLogs())
+	return nil
+}
+
+// smokeTestReplayer is the receiver replacing every receiver of the pipelines, the telemetry being
+// replayed to the consumer it is created with.
+type smokeTestReplayer struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+// smokeTestRun runs a single pipeline with its receivers and exporters replaced.
+type smokeTestRun struct {
+	mu      sync.Mutex
+	traces  consumer.Traces
+	metrics consumer.Metrics
+	logs    consumer.Logs
+	sinks   map[config.ComponentID]*smokeTestSink
+}
+
+// receiverFactories returns factories with the default configs of factories, creating replayers
+// for the data types supported by the original factories.
+func (r *smokeTestRun) receiverFactories(factories map[config.Type]component.ReceiverFactory) map[config.Type]component.ReceiverFactory {
+	replaced := make(map[config.Type]component.ReceiverFactory, len(factories))
+	for typ, f := range factories {
+		var opts []component.ReceiverFactoryOption
+		if sl := f.TracesReceiverStability(); sl != component.StabilityLevelUndefined {
+			opts = append(opts, component.WithTracesReceiver(func(_ context.Context, _ component.ReceiverCreateSettings, _ config.Receiver, next consumer.Traces) (component.TracesReceiver, error) {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+				r.traces = next
+				return &smokeTestReplayer{}, nil
+			}, sl))
+		}
+		if sl := f.MetricsReceiverStability(); sl != component.StabilityLevelUndefined {
+			opts = append(opts, component.WithMetricsReceiver(func(_ context.Context, _ component.ReceiverCreateSettings, _ config.Receiver, next consumer.Metrics) (component.MetricsReceiver, error) {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+				r.metrics = next
+				return &smokeTestReplayer{}, nil
+			}, sl))
+		}
+		if sl := f.LogsReceiverStability(); sl != component.StabilityLevelUndefined {
+			opts = append(opts, component.WithLogsReceiver(func(_ context.Context, _ component.ReceiverCreateSettings, _ config.Receiver, next consumer.Logs) (component.LogsReceiver, error) {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+				r.logs = next
+				return &smokeTestReplayer{}, nil
+			}, sl))
+		}
+		replaced[typ] = component.NewReceiverFactory(typ, f.CreateDefaultConfig, opts...)
+	}
+	return replaced
+}
+
+// exporterFactories returns factories with the default configs of factories, creating sinks
+// for the data types supported by the original factories.
+func (r *smokeTestRun) exporterFactories(factories map[config.Type]component.ExporterFactory) map[config.Type]component.ExporterFactory {
+	replaced := make(map[config.Type]component.ExporterFactory, len(factories))
+	for typ, f := range factories {
+		var opts []component.ExporterFactoryOption
+		if sl := f.TracesExporterStability(); sl != component.StabilityLevelUndefined {
+			opts = append(opts, component.WithTracesExporter(func(_ context.Context, _ component.ExporterCreateSettings, cfg config.Exporter) (component.TracesExporter, error) {
+				return r.sink(cfg.ID()), nil
+			}, sl))
+		}
+		if sl := f.MetricsExporterStability(); sl != component.StabilityLevelUndefined {
+			opts = append(opts, component.WithMetricsExporter(func(_ context.Context, _ component.ExporterCreateSettings, cfg config.Exporter) (component.MetricsExporter, error) {
+				return r.sink(cfg.ID()), nil
+			}, sl))
+		}
+		if sl := f.LogsExporterStability(); sl != component.StabilityLevelUndefined {
+			opts = append(opts, component.WithLogsExporter(func(_ context.Context, _ component.ExporterCreateSettings, cfg config.Exporter) (component.LogsExporter, error) {
+				return r.sink(cfg.ID()), nil
+			}, sl))
+		}
+		replaced[typ] = component.NewExporterFactory(typ, f.CreateDefaultConfig, opts...)
+	}
+	return replaced
+}
+
+func (r *smokeTestRun) sink(id config.ComponentID) *smokeTestSink {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sink := &smokeTestSink{data: newSmokeTestData()}
+	r.sinks[id] = sink
+	return sink
+}
+
+// replay sends the input of the data type to the consumer of the receivers of the pipeline.
+func (r *smokeTestRun) replay(ctx context.Context, dt config.DataType, input *smokeTestData) error {
+	r.mu.Lock()
+	traces, metrics, logs := r.traces, r.metrics, r.logs
+	r.mu.Unlock()
+	switch dt {
+	case config.TracesDataType:
+		return traces.ConsumeTraces(ctx, input.traces.Clone())
+	case config.MetricsDataType:
+		return metrics.ConsumeMetrics(ctx, input.metrics.Clone())
+	}
+	return logs.ConsumeLogs(ctx, input.logs.Clone())
+}
+
+// smokeTestHost is the component.Host of the pipelines run by the smoke test, without extensions.
+type smokeTestHost struct {
+	factories component.Factories
+	pipelines *pipelines.Pipelines
+}
+
+func (h *smokeTestHost) ReportFatalError(error) {}
+
+func (h *smokeTestHost) GetFactory(kind component.Kind, componentType config.Type) component.Factory {
+	return (&serviceHost{factories: h.factories}).GetFactory(kind, componentType)
+}
+
+func (h *smokeTestHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return nil
+}
+
+func (h *smokeTestHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return h.pipelines.GetExporters()
+}
+
+// smokeTestResult is the outcome of the smoke test of a pipeline.
+type smokeTestResult struct {
+	pipeline config.ComponentID
+	input    string
+	output   string
+	result   string
+	failed   bool
+}
+
+// smokeTest replays the golden telemetry through the pipelines of a configuration.
+type smokeTest struct {
+	factories component.Factories
+	buildInfo component.BuildInfo
+	inputs    map[config.DataType]*smokeTestData
+
+	// goldenDir holds the expected outputs of the pipelines, not compared if empty.
+	goldenDir string
+	// updateGolden writes the outputs to goldenDir instead of comparing them.
+	updateGolden bool
+}
+
+// run runs every pipeline having input of its data type, one at a time, and returns their results.
+func (st *smokeTest) run(ctx context.Context, cfg *Config) []smokeTestResult {
+	ids := make([]config.ComponentID, 0, len(cfg.Service.Pipelines))
+	for id := range cfg.Service.Pipelines {
+		ids = append(ids, id)
+	}
+	var results []smokeTestResult
+	for _, id := range sortComponentIDs(ids) {
+		input := st.inputs[id.Type()]
+		if input == nil {
+			results = append(results, smokeTestResult{pipeline: id, input: "-", output: "-", result: "SKIP: no input"})
+			continue
+		}
+		results = append(results, st.runPipeline(ctx, cfg, id, input))
+	}
+	return results
+}
+
+func (st *smokeTest) runPipeline(ctx context.Context, cfg *Config, id config.ComponentID, input *smokeTestData) smokeTestResult {
+	count, unit := input.count(id.Type())
+	res := smokeTestResult{pipeline: id, input: fmt.Sprintf("%d %s", count, unit), output: "-"}
+	output, err := st.replay(ctx, cfg, id, input)
+	if err != nil {
+		res.result, res.failed = "FAIL: "+err.Error(), true
+		return res
+	}
+	count, unit = output.count(id.Type())
+	res.output = fmt.Sprintf("%d %s", count, unit)
+	res.result, res.failed = st.checkGolden(id, output)
+	return res
+}
+
+// replay runs the pipeline alone, with its receivers and exporters replaced, and returns the
+// telemetry received by its first exporter, all of them receiving the same telemetry.
+func (st *smokeTest) replay(ctx context.Context, cfg *Config, id config.ComponentID, input *smokeTestData) (*smokeTestData, error) {
+	pipe := cfg.Service.Pipelines[id]
+	r := &smokeTestRun{sinks: map[config.ComponentID]*smokeTestSink{}}
+	built, err := pipelines.Build(ctx, pipelines.Settings{
+		Telemetry: component.TelemetrySettings{
+			Logger:         zap.NewNop(),
+			TracerProvider: trace.NewNoopTracerProvider(),
+			MeterProvider:  metric.NewNoopMeterProvider(),
+			MetricsLevel:   configtelemetry.LevelNone,
+		},
+		BuildInfo:          st.buildInfo,
+		ReceiverFactories:  r.receiverFactories(st.factories.Receivers),
+		ReceiverConfigs:    cfg.Receivers,
+		ProcessorFactories: st.factories.Processors,
+		ProcessorConfigs:   cfg.Processors,
+		ExporterFactories:  r.exporterFactories(st.factories.Exporters),
+		ExporterConfigs:    cfg.Exporters,
+		PipelineConfigs:    map[config.ComponentID]*config.Pipeline{id: pipe},
+		Timeouts:           cfg.Service.Timeouts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot build the pipeline: %w", err)
+	}
+	if err = built.StartAll(ctx, &smokeTestHost{factories: st.factories, pipelines: built}); err != nil {
+		return nil, multierr.Append(fmt.Errorf("cannot start the pipeline: %w", err), built.ShutdownAll(ctx))
+	}
+	replayErr := r.replay(ctx, id.Type(), input)
+	if replayErr != nil {
+		replayErr = fmt.Errorf("the pipeline rejected the input: %w", replayErr)
+	}
+	// Shutting down the pipeline flushes the processors buffering the telemetry.
+	if err = multierr.Append(replayErr, built.ShutdownAll(ctx)); err != nil {
+		return nil, err
+	}
+	return r.sinks[pipe.Exporters[0]].data, nil
+}
+
+// checkGolden compares the output of the pipeline with its golden file, or updates the golden
+// file, and returns the result and whether it failed.
+func (st *smokeTest) checkGolden(id config.ComponentID, output *smokeTestData) (string, bool) {
+	if st.goldenDir == "" {
+		return "OK", false
+	}
+	path := filepath.Join(st.goldenDir, strings.ReplaceAll(id.String(), "/", "_")+".json")
+	actual, err := output.marshal(id.Type())
+	if err != nil {
+		return "FAIL: " + err.Error(), true
+	}
+	if st.updateGolden {
+		if err = os.WriteFile(path, append(actual, '\n'), 0600); err != nil {
+			return "FAIL: " + err.Error(), true
+		}
+		return "UPDATED: " + path, false
+	}
+	golden := newSmokeTestData()
+	if err = golden.load(id.Type(), path); err != nil {
+		return "FAIL: " + err.Error(), true
+	}
+	expected, err := golden.marshal(id.Type())
+	if err != nil {
+		return "FAIL: " + err.Error(), true
+	}
+	if !bytes.Equal(expected, actual) {
+		count, unit := golden.count(id.Type())
+		return fmt.Sprintf("FAIL: the output differs from %s, with %d %s", path, count, unit), true
+	}
+	return "OK: matches " + path, false
+}
+
+func writeSmokeTestReport(w io.Writer, results []smokeTestResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PIPELINE\tINPUT\tOUTPUT\tRESULT")
+	failed := 0
+	for _, res := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", res.pipeline, res.input, res.output, res.result)
+		if res.failed {
+			failed++
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d pipeline(s) failed the smoke test", failed)
+	}
+	return nil
+}
+
+// newSmokeTestCommand constructs the command replaying golden telemetry through the pipelines of
+// a configuration, to regression test the configuration changes before rolling them out.
+func newSmokeTestCommand(set CollectorSettings) *cobra.Command {
+	flagSet := flags()
+	st := &smokeTest{factories: set.Factories, buildInfo: set.BuildInfo}
+	inputs := map[config.DataType]*[]string{
+		config.TracesDataType:  new([]string),
+		config.MetricsDataType: new([]string),
+		config.LogsDataType:    new([]string),
+	}
+	cmd := &cobra.Command{
+		Use:   "smoke-test",
+		Short: "Replays golden telemetry through the pipelines of the configuration",
+		Long: "Runs every pipeline of the configuration alone, with its receivers and exporters replaced by in-memory " +
+			"ones, replays the telemetry of the OTLP files (JSON if the extension is .json, protobuf otherwise) of " +
+			"its data type through it, and reports the telemetry received by its exporters. With --golden-dir, the " +
+			"output of every pipeline is compared to the <pipeline>.json file of the directory, e.g. traces_backend.json " +
+			"for the traces/backend pipeline, or written to it with --update-golden. Fails if any pipeline fails.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if st.updateGolden && st.goldenDir == "" {
+				return errors.New("--update-golden requires --golden-dir")
+			}
+			st.inputs = map[config.DataType]*smokeTestData{}
+			for dt, paths := range inputs {
+				for _, path := range *paths {
+					if st.inputs[dt] == nil {
+						st.inputs[dt] = newSmokeTestData()
+					}
+					if err := st.inputs[dt].load(dt, path); err != nil {
+						return err
+					}
+				}
+			}
+			if len(st.inputs) == 0 {
+				return errors.New("no input, set at least one of --traces, --metrics or --logs")
+			}
+
+			cfgProvider := set.ConfigProvider
+			if cfgProvider == nil {
+				cfgSet, err := newConfigProviderSettingsFromFlags(set, flagSet)
+				if err != nil {
+					return err
+				}
+				// The actual config sources are tested, never the last known good configuration.
+				cfgSet.LastKnownGood = LastKnownGoodSettings{}
+				if cfgProvider, err = NewConfigProvider(cfgSet); err != nil {
+					return err
+				}
+			}
+			defer func() {
+				_ = cfgProvider.Shutdown(cmd.Context())
+			}()
+			cfg, err := cfgProvider.Get(cmd.Context(), set.Factories)
+			if err != nil {
+				return err
+			}
+			return writeSmokeTestReport(cmd.OutOrStdout(), st.run(cmd.Context(), cfg))
+		},
+	}
+	cmd.Flags().AddGoFlagSet(flagSet)
+	cmd.Flags().StringArrayVar(inputs[config.TracesDataType], "traces", nil, "OTLP file of the traces to replay, can be repeated.")
+	cmd.Flags().StringArrayVar(inputs[config.MetricsDataType], "metrics", nil, "OTLP file of the metrics to replay, can be repeated.")
+	cmd.Flags().StringArrayVar(inputs[config.LogsDataType], "logs", nil, "OTLP file of the logs to replay, can be repeated.")
+	cmd.Flags().StringVar(&st.goldenDir, "golden-dir", "", "Directory of the expected outputs of the pipelines.")
+	cmd.Flags().BoolVar(&st.updateGolden, "update-golden", false, "Write the outputs of the pipelines to --golden-dir instead of comparing them.")
+	return cmd
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestSmokeTestCommand(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	goldenDir := t.TempDir()
+	run := func(extraArgs ...string) (string, error) {
+		cmd := NewCommand(CollectorSettings{Factories: factories})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"smoke-test",
+			"--config", filepath.Join("testdata", "smoketest", "config.yaml"),
+			"--traces", filepath.Join("testdata", "smoketest", "traces.json"),
+			"--logs", filepath.Join("testdata", "smoketest", "logs.json"),
+			"--golden-dir", goldenDir,
+		}, extraArgs...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("--update-golden")
+	require.NoError(t, err)
+	assert.Regexp(t, `PIPELINE +INPUT +OUTPUT +RESULT\n`, out)
+	assert.Regexp(t, `logs +1 log records +1 log records +UPDATED: .*logs\.json\n`, out)
+	assert.Regexp(t, `metrics +- +- +SKIP: no input\n`, out)
+	assert.Regexp(t, `traces +2 spans +2 spans +UPDATED: .*traces\.json\n`, out)
+	assert.FileExists(t, filepath.Join(goldenDir, "traces.json"))
+
+	out, err = run()
+	require.NoError(t, err)
+	assert.Regexp(t, `traces +2 spans +2 spans +OK: matches .*traces\.json\n`, out)
+
+	// A golden file with a single span does not match anymore.
+	content, err := os.ReadFile(filepath.Join("testdata", "smoketest", "traces.json"))
+	require.NoError(t, err)
+	golden := bytes.Replace(content, []byte(`,{"name":"SELECT cart","kind":3,"startTimeUnixNano":"1660000000010000000","endTimeUnixNano":"1660000000050000000"}`), nil, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(goldenDir, "traces.json"), golden, 0600))
+	out, err = run()
+	assert.EqualError(t, err, "1 pipeline(s) failed the smoke test")
+	assert.Regexp(t, `traces +2 spans +2 spans +FAIL: the output differs from .*traces\.json, with 1 spans\n`, out)
+	assert.Regexp(t, `logs +1 log records +1 log records +OK: matches`, out)
+}
+
+func TestSmokeTestCommandWithoutGolden(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"smoke-test",
+		"--config", filepath.Join("testdata", "smoketest", "config.yaml"),
+		"--traces", filepath.Join("testdata", "smoketest", "traces.json"),
+		"--traces", filepath.Join("testdata", "smoketest", "traces.json")})
+	require.NoError(t, cmd.Execute())
+	assert.Regexp(t, `traces +4 spans +4 spans +OK\n`, out.String())
+}
+
+func TestSmokeTestCommandInvalidArgs(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "no_input", args: nil, err: "no input, set at least one of --traces, --metrics or --logs"},
+		{name: "update_without_dir", args: []string{"--update-golden"}, err: "--update-golden requires --golden-dir"},
+		{name: "missing_input", args: []string{"--metrics", filepath.Join("testdata", "smoketest", "missing.json")}},
+		{name: "invalid_input", args: []string{"--metrics", filepath.Join("testdata", "smoketest", "traces.json")}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewCommand(CollectorSettings{Factories: factories})
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{"smoke-test", "--config", filepath.Join("testdata", "otelcol-nop.yaml")}, tt.args...))
+			err := cmd.Execute()
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.Error(t, err)
+		})
+	}
+}
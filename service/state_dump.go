@@ -0,0 +1,183 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"go.opencensus.io/metric/metricproducer"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/featuregate"
+)
+
+const (
+	exporterQueueSizeMetric     = "exporter/queue_size"
+	exporterQueueCapacityMetric = "exporter/queue_capacity"
+)
+
+// runtimeState is a snapshot of the state of the running Collector, dumped on SIGUSR2 or
+// served by the admin API for incident triage.
+type runtimeState struct {
+	Time         time.Time       `json:"time"`
+	Version      string          `json:"version"`
+	State        string          `json:"state"`
+	ConfigHash   string          `json:"config_hash"`
+	Goroutines   int             `json:"goroutines"`
+	FeatureGates map[string]bool `json:"feature_gates"`
+	Extensions   []string        `json:"extensions"`
+	Pipelines    []pipelineState `json:"pipelines"`
+	Queues       []queueState    `json:"queues"`
+}
+
+// pipelineState describes an active pipeline in the runtimeState.
+type pipelineState struct {
+	Pipeline   string   `json:"pipeline"`
+	Paused     bool     `json:"paused"`
+	Receivers  []string `json:"receivers"`
+	Processors []string `json:"processors"`
+	Exporters  []string `json:"exporters"`
+}
+
+// queueState is the depth of the sending queue of an exporter in the runtimeState.
+type queueState struct {
+	Exporter string `json:"exporter"`
+	Size     int64  `json:"size"`
+	Capacity int64  `json:"capacity"`
+}
+
+// runtimeState returns a snapshot of the state of the Collector. It must be called from the
+// goroutine running the Collector, since the service is replaced on reloads.
+func (col *Collector) runtimeState() runtimeState {
+	state := runtimeState{
+		Time:         time.Now().UTC(),
+		Version:      col.set.BuildInfo.Version,
+		State:        col.GetState().String(),
+		ConfigHash:   col.configHash(),
+		Goroutines:   runtime.NumGoroutine(),
+		FeatureGates: map[string]bool{},
+		Queues:       readQueueStates(),
+	}
+	for _, g := range featuregate.GetRegistry().List() {
+		state.FeatureGates[g.ID] = g.Enabled
+	}
+	if col.service == nil {
+		return state
+	}
+	state.Extensions = componentIDStrings(col.service.config.Service.Extensions)
+	for id, pipe := range col.service.config.Service.Pipelines {
+		state.Pipelines = append(state.Pipelines, pipelineState{
+			Pipeline:   id.String(),
+			Paused:     col.pauses.Paused(id),
+			Receivers:  componentIDStrings(pipe.Receivers),
+			Processors: componentIDStrings(pipe.Processors),
+			Exporters:  componentIDStrings(pipe.Exporters),
+		})
+	}
+	sort.Slice(state.Pipelines, func(i, j int) bool { return state.Pipelines[i].Pipeline < state.Pipelines[j].Pipeline })
+	return state
+}
+
+// readQueueStates reads the depths of the sending queues of the exporters from their internal metrics.
+func readQueueStates() []queueState {
+	queues := map[string]*queueState{}
+	for _, p := range metricproducer.GlobalManager().GetAll() {
+		for _, m := range p.Read() {
+			if m.Descriptor.Name != exporterQueueSizeMetric && m.Descriptor.Name != exporterQueueCapacityMetric {
+				continue
+			}
+			for _, ts := range m.TimeSeries {
+				if len(ts.LabelValues) == 0 || len(ts.Points) == 0 {
+					continue
+				}
+				value, ok := ts.Points[len(ts.Points)-1].Value.(int64)
+				if !ok {
+					continue
+				}
+				exporter := ts.LabelValues[0].Value
+				if queues[exporter] == nil {
+					queues[exporter] = &queueState{Exporter: exporter}
+				}
+				if m.Descriptor.Name == exporterQueueSizeMetric {
+					queues[exporter].Size = value
+				} else {
+					queues[exporter].Capacity = value
+				}
+			}
+		}
+	}
+	states := make([]queueState, 0, len(queues))
+	for _, q := range queues {
+		states = append(states, *q)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Exporter < states[j].Exporter })
+	return states
+}
+
+func componentIDStrings(ids []config.ComponentID) []string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, id.String())
+	}
+	return strs
+}
+
+// dumpState writes the runtime state to the state dump file if set, or logs it otherwise.
+func (col *Collector) dumpState() {
+	logger := col.service.telemetrySettings.Logger
+	state := col.runtimeState()
+	if col.set.StateDumpFile == "" {
+		logger.Info("Runtime state",
+			zap.String("state", state.State),
+			zap.String("config_hash", state.ConfigHash),
+			zap.Int("goroutines", state.Goroutines),
+			zap.Any("feature_gates", state.FeatureGates),
+			zap.Strings("extensions", state.Extensions),
+			zap.Any("pipelines", state.Pipelines),
+			zap.Any("queues", state.Queues))
+		return
+	}
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err == nil {
+		err = os.WriteFile(col.set.StateDumpFile, append(content, '\n'), 0600)
+	}
+	if err != nil {
+		logger.Warn("Failed to write the runtime state", zap.String("path", col.set.StateDumpFile), zap.Error(err))
+		return
+	}
+	logger.Info("Runtime state written", zap.String("path", col.set.StateDumpFile))
+}
+
+// requestRuntimeState returns the runtime state, collected by the goroutine running the Collector.
+func (col *Collector) requestRuntimeState(ctx context.Context) (runtimeState, error) {
+	reply := make(chan runtimeState, 1)
+	select {
+	case col.stateRequests <- reply:
+	case <-ctx.Done():
+		return runtimeState{}, ctx.Err()
+	}
+	select {
+	case state := <-reply:
+		return state, nil
+	case <-ctx.Done():
+		return runtimeState{}, ctx.Err()
+	}
+}
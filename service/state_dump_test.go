@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/metric"
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/metric/metricproducer"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/service/featuregate"
+)
+
+func TestReadQueueStates(t *testing.T) {
+	registry := metric.NewRegistry()
+	size, err := registry.AddInt64DerivedGauge(exporterQueueSizeMetric, metric.WithLabelKeys("exporter"))
+	require.NoError(t, err)
+	capacity, err := registry.AddInt64DerivedGauge(exporterQueueCapacityMetric, metric.WithLabelKeys("exporter"))
+	require.NoError(t, err)
+	require.NoError(t, size.UpsertEntry(func() int64 { return 3 }, metricdata.NewLabelValue("otlp")))
+	require.NoError(t, capacity.UpsertEntry(func() int64 { return 100 }, metricdata.NewLabelValue("otlp")))
+	require.NoError(t, size.UpsertEntry(func() int64 { return 0 }, metricdata.NewLabelValue("jaeger")))
+	metricproducer.GlobalManager().AddProducer(registry)
+	defer metricproducer.GlobalManager().DeleteProducer(registry)
+
+	assert.Equal(t, []queueState{
+		{Exporter: "jaeger"},
+		{Exporter: "otlp", Size: 3, Capacity: 100},
+	}, readQueueStates())
+}
+
+func TestCollectorStateDump(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	cfgProvider, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{filepath.Join("testdata", "otelcol-nop.yaml")}))
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	col, err := New(CollectorSettings{
+		BuildInfo:      component.BuildInfo{Version: "1.2.3"},
+		Factories:      factories,
+		ConfigProvider: cfgProvider,
+		StateDumpFile:  path,
+		telemetry:      newColTelemetry(featuregate.NewRegistry()),
+	})
+	require.NoError(t, err)
+
+	wg := startCollector(context.Background(), t, col)
+	defer func() {
+		col.Shutdown()
+		wg.Wait()
+	}()
+	assert.Eventually(t, func() bool {
+		return Running == col.GetState()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Simulate a SIGUSR2 signal, not available on every platform.
+	col.stateDumpChannel <- os.Interrupt
+	var state runtimeState
+	assert.Eventually(t, func() bool {
+		content, readErr := os.ReadFile(path)
+		return readErr == nil && json.Unmarshal(content, &state) == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "1.2.3", state.Version)
+	assert.Equal(t, Running.String(), state.State)
+	assert.Equal(t, col.configHash(), state.ConfigHash)
+	assert.Positive(t, state.Goroutines)
+	assert.Equal(t, []string{"nop"}, state.Extensions)
+	require.Len(t, state.Pipelines, 3)
+	assert.Equal(t, "logs", state.Pipelines[0].Pipeline)
+	assert.False(t, state.Pipelines[0].Paused)
+	assert.Contains(t, state.FeatureGates, "confmap.expandEnabled")
+}
+
+func TestCollectorStateDumpToLog(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	cfgProvider, err := NewConfigProvider(newDefaultConfigProviderSettings([]string{filepath.Join("testdata", "otelcol-nop.yaml")}))
+	require.NoError(t, err)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	col, err := New(CollectorSettings{
+		BuildInfo:      component.NewDefaultBuildInfo(),
+		Factories:      factories,
+		ConfigProvider: cfgProvider,
+		LoggingOptions: []zap.Option{zap.WrapCore(func(c zapcore.Core) zapcore.Core { return zapcore.NewTee(c, core) })},
+		telemetry:      newColTelemetry(featuregate.NewRegistry()),
+	})
+	require.NoError(t, err)
+
+	wg := startCollector(context.Background(), t, col)
+	defer func() {
+		col.Shutdown()
+		wg.Wait()
+	}()
+	assert.Eventually(t, func() bool {
+		return Running == col.GetState()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	col.stateDumpChannel <- os.Interrupt
+	assert.Eventually(t, func() bool {
+		return logs.FilterMessage("Runtime state").Len() == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	fields := logs.FilterMessage("Runtime state").All()[0].ContextMap()
+	assert.Equal(t, col.configHash(), fields["config_hash"])
+	assert.Equal(t, Running.String(), fields["state"])
+}
@@ -37,6 +37,8 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/confmap/provider/httpprovider"
+	"go.opentelemetry.io/collector/extension/oidcclientauthextension"
 	"go.opentelemetry.io/collector/internal/obsreportconfig"
 	"go.opentelemetry.io/collector/processor/batchprocessor"
 	semconv "go.opentelemetry.io/collector/semconv/v1.5.0"
@@ -44,9 +46,6 @@ import (
 	"go.opentelemetry.io/collector/service/telemetry"
 )
 
-// collectorTelemetry is collector's own telemetrySettings.
-var collectorTelemetry = newColTelemetry(featuregate.GetRegistry())
-
 const (
 	zapKeyTelemetryAddress = "address"
 	zapKeyTelemetryLevel   = "level"
@@ -56,11 +55,64 @@ const (
 	useOtelForInternalMetricsfeatureGateID = "telemetry.useOtelForInternalMetrics"
 )
 
+var useOtelForInternalMetricsfeatureGate = featuregate.Gate{
+	ID:          useOtelForInternalMetricsfeatureGateID,
+	Description: "controls whether the collector to uses OpenTelemetry for internal metrics",
+	Enabled:     false,
+}
+
+func init() {
+	featuregate.GetRegistry().MustRegister(useOtelForInternalMetricsfeatureGate)
+}
+
+// OpenCensus views are registered process-wide, and their data is sent to all the registered
+// exporters, so the instances running in the same process share their internal metrics.
+// viewsRefCount only tracks how many instances use each view, so that shutting down one
+// instance does not remove the views of the others.
+var (
+	viewsMu       sync.Mutex
+	viewsRefCount = map[string]int{}
+)
+
+func registerViews(views []*view.View) error {
+	viewsMu.Lock()
+	defer viewsMu.Unlock()
+	// Only register views not yet in use, the view definitions are identical for all instances.
+	var unregistered []*view.View
+	for _, v := range views {
+		if viewsRefCount[v.Name] == 0 {
+			unregistered = append(unregistered, v)
+		}
+	}
+	if err := view.Register(unregistered...); err != nil {
+		return err
+	}
+	for _, v := range views {
+		viewsRefCount[v.Name]++
+	}
+	return nil
+}
+
+func unregisterViews(views []*view.View) {
+	viewsMu.Lock()
+	defer viewsMu.Unlock()
+	var unused []*view.View
+	for _, v := range views {
+		viewsRefCount[v.Name]--
+		if viewsRefCount[v.Name] <= 0 {
+			delete(viewsRefCount, v.Name)
+			unused = append(unused, v)
+		}
+	}
+	view.Unregister(unused...)
+}
+
 type telemetryInitializer struct {
 	registry *featuregate.Registry
 	views    []*view.View
 
 	ocRegistry *ocmetric.Registry
+	ocExporter view.Exporter
 
 	mp metric.MeterProvider
 
@@ -68,12 +120,8 @@ type telemetryInitializer struct {
 	doInitOnce sync.Once
 }
 
+// newColTelemetry returns the telemetryInitializer of a single Collector instance.
 func newColTelemetry(registry *featuregate.Registry) *telemetryInitializer {
-	registry.MustRegister(featuregate.Gate{
-		ID:          useOtelForInternalMetricsfeatureGateID,
-		Description: "controls whether the collector to uses OpenTelemetry for internal metrics",
-		Enabled:     false,
-	})
 	return &telemetryInitializer{
 		registry: registry,
 		mp:       metric.NewNoopMeterProvider(),
@@ -165,12 +213,14 @@ func (tel *telemetryInitializer) initOpenCensus(cfg telemetry.Config, telAttrs m
 	var views []*view.View
 	obsMetrics := obsreportconfig.Configure(cfg.Metrics.Level)
 	views = append(views, batchprocessor.MetricViews()...)
+	views = append(views, oidcclientauthextension.MetricViews()...)
+	views = append(views, httpprovider.MetricViews()...)
 	views = append(views, obsMetrics.Views...)
 
-	tel.views = views
-	if err := view.Register(views...); err != nil {
+	if err := registerViews(views); err != nil {
 		return nil, err
 	}
+	tel.views = views
 
 	// Until we can use a generic metrics exporter, default to Prometheus.
 	opts := prometheus.Options{
@@ -189,6 +239,7 @@ func (tel *telemetryInitializer) initOpenCensus(cfg telemetry.Config, telAttrs m
 	}
 
 	view.RegisterExporter(pe)
+	tel.ocExporter = pe
 	return pe, nil
 }
 
@@ -218,7 +269,12 @@ func (tel *telemetryInitializer) initOpenTelemetry() (http.Handler, error) {
 func (tel *telemetryInitializer) shutdown() error {
 	metricproducer.GlobalManager().DeleteProducer(tel.ocRegistry)
 
-	view.Unregister(tel.views...)
+	if tel.ocExporter != nil {
+		view.UnregisterExporter(tel.ocExporter)
+		tel.ocExporter = nil
+	}
+	unregisterViews(tel.views)
+	tel.views = nil
 
 	if tel.server != nil {
 		return tel.server.Close()
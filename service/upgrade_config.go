@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/converter/legacyconverter"
+)
+
+// newUpgradeConfigCommand constructs the command that rewrites configuration files into the layout
+// of the current version, applying the same migrations as the legacy converter.
+func newUpgradeConfigCommand() *cobra.Command {
+	inPlace := false
+	cmd := &cobra.Command{
+		Use:   "upgrade-config FILE...",
+		Short: "Rewrites configuration files for the current version",
+		Long: "Rewrites the configuration files written for older versions into the layout of the current version, " +
+			"applying the migrations the collector applies when loading them, and prints a summary of the changes. " +
+			"The upgraded configuration is printed, or written back to the files with --in-place. " +
+			"Files without changes are left untouched; rewritten files lose their comments and have their keys sorted.",
+		Example: "  upgrade-config config.yaml > upgraded.yaml\n  upgrade-config --in-place configs/*.yaml",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !inPlace && len(args) > 1 {
+				return errors.New("a single file can be upgraded without --in-place")
+			}
+			var errs []error
+			for _, path := range args {
+				if err := upgradeConfigFile(cmd, path, inPlace); err != nil {
+					cmd.PrintErrf("%s: %v\n", path, err)
+					errs = append(errs, err)
+				}
+			}
+			if len(errs) > 0 {
+				return fmt.Errorf("failed to upgrade %d of %d files", len(errs), len(args))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&inPlace, "in-place", false, "Write the upgraded configurations back to the files.")
+	return cmd
+}
+
+// upgradeConfigFile upgrades the configuration file at path, writes it to the output of the command or
+// back to the file, and prints the summary of the changes to the error output of the command.
+func upgradeConfigFile(cmd *cobra.Command, path string, inPlace bool) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	out, changes, err := upgradeConfig(content)
+	if err != nil {
+		return err
+	}
+
+	printUpgradeSummary(cmd.ErrOrStderr(), path, changes)
+	if !inPlace {
+		_, err = cmd.OutOrStdout().Write(out)
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, info.Mode().Perm())
+}
+
+// upgradeConfig returns the YAML configuration upgraded to the current layout, and the descriptions of
+// the changes. The configuration is returned as is when there are no changes.
+func upgradeConfig(content []byte) ([]byte, []string, error) {
+	var rawConf map[string]interface{}
+	if err := yaml.Unmarshal(content, &rawConf); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the configuration: %w", err)
+	}
+
+	var changes []string
+	conf := confmap.NewFromStringMap(rawConf)
+	if err := legacyconverter.New(func(msg string) { changes = append(changes, msg) }).Convert(context.Background(), conf); err != nil {
+		return nil, nil, err
+	}
+	if len(changes) == 0 {
+		return content, nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(conf.ToStringMap()); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), changes, nil
+}
+
+func printUpgradeSummary(w io.Writer, path string, changes []string) {
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "%s: already up to date\n", path)
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", path)
+	for _, change := range changes {
+		fmt.Fprintf(w, "  - %s\n", change)
+	}
+}
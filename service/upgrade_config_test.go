@@ -0,0 +1,172 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+const legacyConfig = `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        tls_settings:
+          cert_file: /etc/otelcol/cert.pem
+processors:
+  queued_retry:
+  batch:
+exporters:
+  otlp:
+    endpoint: ${BACKEND}
+    insecure: true
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [queued_retry, batch]
+      exporters: [otlp]
+`
+
+const currentConfig = `# Current layout.
+receivers:
+  otlp:
+    protocols:
+      grpc:
+exporters:
+  otlp:
+    endpoint: backend:4317
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlp]
+`
+
+func newUpgradeConfigTestCommand(t *testing.T, args ...string) (*bytes.Buffer, *bytes.Buffer, error) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	cmd := NewCommand(CollectorSettings{Factories: factories})
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetArgs(append([]string{"upgrade-config"}, args...))
+	return stdout, stderr, cmd.Execute()
+}
+
+func TestUpgradeConfigCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(legacyConfig), 0600))
+
+	stdout, stderr, err := newUpgradeConfigTestCommand(t, path)
+	require.NoError(t, err)
+
+	var raw map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(stdout.Bytes(), &raw))
+	assert.Equal(t, map[string]interface{}{
+		"receivers": map[string]interface{}{
+			"otlp": map[string]interface{}{
+				"protocols": map[string]interface{}{
+					"grpc": map[string]interface{}{
+						"tls": map[string]interface{}{"cert_file": "/etc/otelcol/cert.pem"},
+					},
+				},
+			},
+		},
+		"processors": map[string]interface{}{"batch": nil},
+		"exporters": map[string]interface{}{
+			"otlp": map[string]interface{}{
+				"endpoint": "${BACKEND}",
+				"tls":      map[string]interface{}{"insecure": true},
+			},
+		},
+		"service": map[string]interface{}{
+			"pipelines": map[string]interface{}{
+				"traces": map[string]interface{}{
+					"receivers":  []interface{}{"otlp"},
+					"processors": []interface{}{"batch"},
+					"exporters":  []interface{}{"otlp"},
+				},
+			},
+		},
+	}, raw)
+	assert.Equal(t, path+`:
+  - receivers::otlp::protocols::grpc::tls_settings is renamed to receivers::otlp::protocols::grpc::tls
+  - exporters::otlp::insecure is moved to exporters::otlp::tls::insecure
+  - processors::queued_retry is removed, use the sending_queue and retry_on_failure exporter settings instead
+  - queued_retry is removed from service::pipelines::traces::processors
+`, stderr.String())
+
+	// The file is left untouched without --in-place.
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, legacyConfig, string(content))
+}
+
+func TestUpgradeConfigCommandInPlace(t *testing.T) {
+	dir := t.TempDir()
+	legacyPath := filepath.Join(dir, "legacy.yaml")
+	currentPath := filepath.Join(dir, "current.yaml")
+	require.NoError(t, os.WriteFile(legacyPath, []byte(legacyConfig), 0600))
+	require.NoError(t, os.WriteFile(currentPath, []byte(currentConfig), 0600))
+
+	stdout, stderr, err := newUpgradeConfigTestCommand(t, "--in-place", legacyPath, currentPath)
+	require.NoError(t, err)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), legacyPath+":\n  - ")
+	assert.Contains(t, stderr.String(), currentPath+": already up to date\n")
+
+	upgraded, err := os.ReadFile(legacyPath)
+	require.NoError(t, err)
+	_, changes, err := upgradeConfig(upgraded)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// Files without changes keep their comments and layout.
+	content, err := os.ReadFile(currentPath)
+	require.NoError(t, err)
+	assert.Equal(t, currentConfig, string(content))
+}
+
+func TestUpgradeConfigCommandErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalidPath := filepath.Join(dir, "invalid.yaml")
+	legacyPath := filepath.Join(dir, "legacy.yaml")
+	require.NoError(t, os.WriteFile(invalidPath, []byte("receivers: ["), 0600))
+	require.NoError(t, os.WriteFile(legacyPath, []byte(legacyConfig), 0600))
+
+	_, _, err := newUpgradeConfigTestCommand(t)
+	assert.EqualError(t, err, "requires at least 1 arg(s), only received 0")
+
+	_, _, err = newUpgradeConfigTestCommand(t, legacyPath, invalidPath)
+	assert.EqualError(t, err, "a single file can be upgraded without --in-place")
+
+	// The other files are upgraded when one fails.
+	_, stderr, err := newUpgradeConfigTestCommand(t, "--in-place", invalidPath, filepath.Join(dir, "missing.yaml"), legacyPath)
+	assert.EqualError(t, err, "failed to upgrade 2 of 3 files")
+	assert.Contains(t, stderr.String(), invalidPath+": failed to parse the configuration: ")
+	content, err := os.ReadFile(legacyPath)
+	require.NoError(t, err)
+	assert.NotEqual(t, legacyConfig, string(content))
+}
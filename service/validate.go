@@ -0,0 +1,213 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	validateStageResolve   = "resolve"
+	validateStageSchema    = "schema"
+	validateStageUnmarshal = "unmarshal"
+	validateStageValidate  = "validate"
+)
+
+// errInvalidConfig is returned by the validate command, after reporting the errors.
+var errInvalidConfig = errors.New("the configuration is invalid")
+
+// validationError is an error of the configuration reported by the validate command.
+type validationError struct {
+	// Stage is the stage of the loading of the configuration that failed.
+	Stage string `json:"stage"`
+	// Component is the component the error refers to, e.g. "receiver otlp", if any.
+	Component string `json:"component,omitempty"`
+	// Key, URI, Line and Column locate the error in the configuration sources, if known.
+	Key     string `json:"key,omitempty"`
+	URI     string `json:"uri,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// validationReport is the JSON document written by the validate command.
+type validationReport struct {
+	Valid  bool              `json:"valid"`
+	Errors []validationError `json:"errors"`
+}
+
+func newValidationError(stage string, err error) validationError {
+	ve := validationError{Stage: stage, Message: err.Error()}
+	var posErr *confmap.PositionError
+	if errors.As(err, &posErr) {
+		ve.Key = posErr.Key
+		ve.URI = posErr.Position.URI
+		ve.Line = posErr.Position.Line
+		ve.Column = posErr.Position.Column
+		ve.Message = posErr.Err.Error()
+	}
+	var compErr *config.ComponentValidationError
+	if errors.As(err, &compErr) {
+		ve.Component = compErr.Kind + " " + compErr.ID.String()
+		ve.Message = compErr.Err.Error()
+	}
+	return ve
+}
+
+// validateConfig resolves, checks against the schemas, unmarshals and validates the configuration,
+// and returns all its errors, unlike ConfigProvider.Get, which returns the first one only.
+func validateConfig(ctx context.Context, cfgProvider unvalidatedConfigProvider, factories component.Factories) []validationError {
+	conf, cfg, err := cfgProvider.unvalidatedConfig(ctx, factories)
+	if conf == nil {
+		return []validationError{newValidationError(validateStageResolve, err)}
+	}
+	if schemaErr := validateConfigSchemas(conf, factories); schemaErr != nil {
+		// The values not matching their schema likely fail to be unmarshaled too, report them only.
+		errs := make([]validationError, 0, len(multierr.Errors(schemaErr)))
+		for _, e := range multierr.Errors(schemaErr) {
+			errs = append(errs, newValidationError(validateStageSchema, e))
+		}
+		return errs
+	}
+	if err != nil {
+		return []validationError{newValidationError(validateStageUnmarshal, err)}
+	}
+
+	var errs []validationError
+	add := func(kind string, id config.ComponentID, err error) {
+		for _, e := range multierr.Errors(err) {
+			e = withComponentPosition(conf, &config.ComponentValidationError{Kind: kind, ID: id, Err: e})
+			errs = append(errs, newValidationError(validateStageValidate, e))
+		}
+	}
+	stubs := *cfg
+	stubs.Receivers = make(map[config.ComponentID]config.Receiver, len(cfg.Receivers))
+	for id, comp := range cfg.Receivers {
+		add("receiver", id, comp.Validate())
+		stub := config.NewReceiverSettings(id)
+		stubs.Receivers[id] = &stub
+	}
+	stubs.Processors = make(map[config.ComponentID]config.Processor, len(cfg.Processors))
+	for id, comp := range cfg.Processors {
+		add("processor", id, comp.Validate())
+		stub := config.NewProcessorSettings(id)
+		stubs.Processors[id] = &stub
+	}
+	stubs.Exporters = make(map[config.ComponentID]config.Exporter, len(cfg.Exporters))
+	for id, comp := range cfg.Exporters {
+		add("exporter", id, comp.Validate())
+		stub := config.NewExporterSettings(id)
+		stubs.Exporters[id] = &stub
+	}
+	stubs.Extensions = make(map[config.ComponentID]config.Extension, len(cfg.Extensions))
+	for id, comp := range cfg.Extensions {
+		add("extension", id, comp.Validate())
+		stub := config.NewExtensionSettings(id)
+		stubs.Extensions[id] = &stub
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Component < errs[j].Component })
+
+	// The components replaced by stubs always being valid, validating the stubs reports the first
+	// error of the rest of the configuration: the missing components and the service section.
+	if err = stubs.Validate(); err != nil {
+		errs = append(errs, newValidationError(validateStageValidate, err))
+	}
+	return errs
+}
+
+func writeValidationReport(w io.Writer, errs []validationError, format string) error {
+	if format == "json" {
+		report := validationReport{Valid: len(errs) == 0, Errors: errs}
+		if report.Errors == nil {
+			report.Errors = []validationError{}
+		}
+		if err := writeJSON(w, report); err != nil {
+			return err
+		}
+	} else {
+		if len(errs) == 0 {
+			fmt.Fprintln(w, "The configuration is valid.")
+		}
+		for _, e := range errs {
+			fmt.Fprintf(w, "%s error: ", e.Stage)
+			if e.Component != "" {
+				fmt.Fprintf(w, "%s: ", e.Component)
+			}
+			fmt.Fprint(w, e.Message)
+			if e.URI != "" {
+				fmt.Fprintf(w, " (%s line %d column %d)", e.URI, e.Line, e.Column)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	if len(errs) > 0 {
+		return errInvalidConfig
+	}
+	return nil
+}
+
+// newValidateCommand constructs the command validating a configuration without starting the pipelines.
+func newValidateCommand(set CollectorSettings) *cobra.Command {
+	flagSet := flags()
+	format := "text"
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validates the configuration without running the collector",
+		Long: "Resolves the configuration from all the config sources, applies the converters, unmarshals and validates " +
+			"it as the collector does, then reports all its errors, not only the first one, with their position in the " +
+			"config sources when known. Fails if the configuration is invalid. No component is started.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("unsupported format %q, must be text or json", format)
+			}
+			cfgProvider := set.ConfigProvider
+			if cfgProvider == nil {
+				cfgSet, err := newConfigProviderSettingsFromFlags(set, flagSet)
+				if err != nil {
+					return err
+				}
+				// The actual config sources are validated, never the last known good configuration.
+				cfgSet.LastKnownGood = LastKnownGoodSettings{}
+				if cfgProvider, err = NewConfigProvider(cfgSet); err != nil {
+					return err
+				}
+				defer func() {
+					_ = cfgProvider.Shutdown(cmd.Context())
+				}()
+			}
+			up, ok := cfgProvider.(unvalidatedConfigProvider)
+			if !ok {
+				return errors.New("the config provider does not support validating the configuration")
+			}
+			return writeValidationReport(cmd.OutOrStdout(), validateConfig(cmd.Context(), up, set.Factories), format)
+		},
+	}
+	cmd.Flags().AddGoFlagSet(flagSet)
+	cmd.Flags().StringVar(&format, "format", format, "Format of the report, text or json.")
+	return cmd
+}
@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+)
+
+// strictReceiverConfig is a receiver config reporting several errors.
+type strictReceiverConfig struct {
+	config.ReceiverSettings `mapstructure:",squash"`
+	Endpoint                string `mapstructure:"endpoint"`
+}
+
+func (cfg *strictReceiverConfig) Validate() error {
+	var errs error
+	if cfg.Endpoint == "" {
+		errs = multierr.Append(errs, errors.New("endpoint must be set"))
+	}
+	if cfg.ID().Name() == "" {
+		errs = multierr.Append(errs, errors.New("name must be set"))
+	}
+	return errs
+}
+
+func newValidateTestFactories(t *testing.T) component.Factories {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	factories.Receivers["strict"] = component.NewReceiverFactory("strict", func() config.Receiver {
+		return &strictReceiverConfig{ReceiverSettings: config.NewReceiverSettings(config.NewComponentID("strict"))}
+	}, component.WithTracesReceiver(func(context.Context, component.ReceiverCreateSettings, config.Receiver, consumer.Traces) (component.TracesReceiver, error) {
+		return nil, errors.New("not started by the validate command")
+	}, component.StabilityLevelInDevelopment))
+	return factories
+}
+
+func runValidateCommand(t *testing.T, args ...string) (string, error) {
+	cmd := NewCommand(CollectorSettings{Factories: newValidateTestFactories(t)})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(append([]string{"validate"}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestValidateCommand(t *testing.T) {
+	out, err := runValidateCommand(t, "--config", filepath.Join("testdata", "otelcol-nop.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "The configuration is valid.\n", out)
+
+	uri := filepath.Join("testdata", "validate", "invalid.yaml")
+	out, err = runValidateCommand(t, "--config", uri)
+	assert.Equal(t, errInvalidConfig, err)
+	assert.Equal(t, "validate error: receiver strict: endpoint must be set (file:"+uri+" line 3 column 3)\n"+
+		"validate error: receiver strict: name must be set (file:"+uri+" line 3 column 3)\n"+
+		"validate error: pipeline \"traces\" references processor \"batch\" which does not exist\n", out)
+}
+
+func TestValidateCommandJSON(t *testing.T) {
+	uri := filepath.Join("testdata", "validate", "invalid.yaml")
+	out, err := runValidateCommand(t, "--config", uri, "--format", "json")
+	assert.Equal(t, errInvalidConfig, err)
+	var report validationReport
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	assert.False(t, report.Valid)
+	require.Len(t, report.Errors, 3)
+	assert.Equal(t, validationError{
+		Stage:     validateStageValidate,
+		Component: "receiver strict",
+		Key:       "receivers::strict",
+		URI:       "file:" + uri,
+		Line:      3,
+		Column:    3,
+		Message:   "endpoint must be set",
+	}, report.Errors[0])
+
+	out, err = runValidateCommand(t, "--config", filepath.Join("testdata", "otelcol-nop.yaml"), "--format", "json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"valid": true, "errors": []}`, out)
+}
+
+func TestValidateCommandStages(t *testing.T) {
+	out, err := runValidateCommand(t, "--config", filepath.Join("testdata", "validate", "missing.yaml"), "--format", "json")
+	assert.Equal(t, errInvalidConfig, err)
+	var report validationReport
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, validateStageResolve, report.Errors[0].Stage)
+
+	out, err = runValidateCommand(t, "--config", "yaml:receivers::unknown:", "--format", "json")
+	assert.Equal(t, errInvalidConfig, err)
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, validateStageUnmarshal, report.Errors[0].Stage)
+
+	_, err = runValidateCommand(t, "--config", filepath.Join("testdata", "otelcol-nop.yaml"), "--format", "xml")
+	assert.EqualError(t, err, `unsupported format "xml", must be text or json`)
+}
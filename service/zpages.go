@@ -15,6 +15,7 @@
 package service // import "go.opentelemetry.io/collector/service"
 
 import (
+	"encoding/json"
 	"net/http"
 	"path"
 
@@ -29,6 +30,7 @@ const (
 	pipelinezPath  = "pipelinez"
 	extensionzPath = "extensionz"
 	featurezPath   = "featurez"
+	configzPath    = "configz"
 )
 
 func (host *serviceHost) RegisterZPages(mux *http.ServeMux, pathPrefix string) {
@@ -36,6 +38,8 @@ func (host *serviceHost) RegisterZPages(mux *http.ServeMux, pathPrefix string) {
 	mux.HandleFunc(path.Join(pathPrefix, pipelinezPath), host.pipelines.HandleZPages)
 	mux.HandleFunc(path.Join(pathPrefix, extensionzPath), host.extensions.HandleZPages)
 	mux.HandleFunc(path.Join(pathPrefix, featurezPath), handleFeaturezRequest)
+	mux.HandleFunc(path.Join(pathPrefix, configzPath), host.handleConfigzRequest)
+	mux.HandleFunc(path.Join(pathPrefix, debugBundlezPath), host.handleDebugBundlezRequest)
 }
 
 func (host *serviceHost) zPagesRequest(w http.ResponseWriter, r *http.Request) {
@@ -43,6 +47,7 @@ func (host *serviceHost) zPagesRequest(w http.ResponseWriter, r *http.Request) {
 	zpages.WriteHTMLPageHeader(w, zpages.HeaderData{Title: "Service " + host.buildInfo.Command})
 	zpages.WriteHTMLPropertiesTable(w, zpages.PropertiesTableData{Name: "Build Info", Properties: getBuildInfoProperties(host.buildInfo)})
 	zpages.WriteHTMLPropertiesTable(w, zpages.PropertiesTableData{Name: "Runtime Info", Properties: runtimeinfo.Info()})
+	zpages.WriteHTMLPropertiesTable(w, zpages.PropertiesTableData{Name: "Configuration", Properties: [][2]string{{"Hash", host.configHash}}})
 	zpages.WriteHTMLComponentHeader(w, zpages.ComponentHeaderData{
 		Name:              "Pipelines",
 		ComponentEndpoint: pipelinezPath,
@@ -58,6 +63,16 @@ func (host *serviceHost) zPagesRequest(w http.ResponseWriter, r *http.Request) {
 		ComponentEndpoint: featurezPath,
 		Link:              true,
 	})
+	zpages.WriteHTMLComponentHeader(w, zpages.ComponentHeaderData{
+		Name:              "Effective Configuration",
+		ComponentEndpoint: configzPath,
+		Link:              true,
+	})
+	zpages.WriteHTMLComponentHeader(w, zpages.ComponentHeaderData{
+		Name:              "Debug Bundle",
+		ComponentEndpoint: debugBundlezPath,
+		Link:              true,
+	})
 	zpages.WriteHTMLPageFooter(w)
 }
 
@@ -68,6 +83,30 @@ func handleFeaturezRequest(w http.ResponseWriter, r *http.Request) {
 	zpages.WriteHTMLPageFooter(w)
 }
 
+// configzResponse is the JSON document served on the configz page.
+type configzResponse struct {
+	ConfigHash string                 `json:"config_hash"`
+	Sources    []string               `json:"sources"`
+	Config     map[string]interface{} `json:"config"`
+}
+
+// handleConfigzRequest serves the redacted effective configuration, its hash and the URIs it was
+// retrieved from. The page is only available when the zpages extension is enabled, which by
+// default listens on localhost only.
+func (host *serviceHost) handleConfigzRequest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(configzResponse{
+		ConfigHash: host.configHash,
+		Sources:    host.configSources,
+		Config:     host.effectiveConfig,
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func getFeaturesTableData() zpages.FeatureGateTableData {
 	data := zpages.FeatureGateTableData{}
 	for _, g := range featuregate.GetRegistry().List() {